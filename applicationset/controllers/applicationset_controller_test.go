@@ -1134,6 +1134,22 @@ func TestRemoveFinalizerOnInvalidDestination_DestinationTypes(t *testing.T) {
 			},
 			expectFinalizerRemoved: false,
 		},
+		{
+			name: "valid cluster by cluster selector",
+			destinationField: v1alpha1.ApplicationDestination{
+				Namespace:       "namespace",
+				ClusterSelector: "env=prod",
+			},
+			expectFinalizerRemoved: false,
+		},
+		{
+			name: "invalid cluster: no cluster matching the cluster selector",
+			destinationField: v1alpha1.ApplicationDestination{
+				Namespace:       "namespace",
+				ClusterSelector: "env=staging",
+			},
+			expectFinalizerRemoved: true,
+		},
 	} {
 
 		t.Run(c.name, func(t *testing.T) {
@@ -1173,6 +1189,7 @@ func TestRemoveFinalizerOnInvalidDestination_DestinationTypes(t *testing.T) {
 					Namespace: "namespace",
 					Labels: map[string]string{
 						generators.ArgoCDSecretTypeLabel: generators.ArgoCDSecretTypeCluster,
+						"env":                            "prod",
 					},
 				},
 				Data: map[string][]byte{
@@ -15,6 +15,7 @@ import (
 
 	"github.com/argoproj/argo-cd/v2/common"
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/argo"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
@@ -48,9 +49,23 @@ const (
 )
 
 // ValidateDestination checks:
-// if we used destination name we infer the server url
+// if we used destination name or cluster selector we infer the server url
 // if we used both name and server then we return an invalid spec error
 func ValidateDestination(ctx context.Context, dest *appv1.ApplicationDestination, clientset kubernetes.Interface, namespace string) error {
+	if dest.ClusterSelector != "" {
+		if dest.Name != "" {
+			return fmt.Errorf("application destination can't have both name and cluster selector defined: %s %s", dest.Name, dest.ClusterSelector)
+		}
+		if dest.Server != "" && !dest.IsServerInferred() {
+			return fmt.Errorf("application destination can't have both cluster selector and server defined: %s %s", dest.ClusterSelector, dest.Server)
+		}
+		server, err := getDestinationServerBySelector(ctx, dest.ClusterSelector, clientset, namespace)
+		if err != nil {
+			return fmt.Errorf("unable to find destination server: %v", err)
+		}
+		dest.SetInferredServer(server)
+		return nil
+	}
 	if dest.Name != "" {
 		if dest.Server == "" {
 			server, err := getDestinationServer(ctx, dest.Name, clientset, namespace)
@@ -92,6 +107,14 @@ func getDestinationServer(ctx context.Context, clusterName string, clientset kub
 	return servers[0], nil
 }
 
+func getDestinationServerBySelector(ctx context.Context, clusterSelector string, clientset kubernetes.Interface, namespace string) (string, error) {
+	clusterList, err := ListClusters(ctx, clientset, namespace)
+	if err != nil {
+		return "", err
+	}
+	return argo.GetClusterServerBySelector(clusterList.Items, clusterSelector)
+}
+
 func ListClusters(ctx context.Context, clientset kubernetes.Interface, namespace string) (*appv1.ClusterList, error) {
 
 	clusterSecretsList, err := clientset.CoreV1().Secrets(namespace).List(ctx,
@@ -183,6 +206,17 @@ func secretToCluster(s *corev1.Secret) (*appv1.Cluster, error) {
 			shard = pointer.Int64Ptr(int64(val))
 		}
 	}
+	// copy labels excluding the secret type one, so that cluster selectors can be matched against them
+	var clusterLabels map[string]string
+	for k, v := range s.Labels {
+		if k == common.LabelKeySecretType {
+			continue
+		}
+		if clusterLabels == nil {
+			clusterLabels = map[string]string{}
+		}
+		clusterLabels[k] = v
+	}
 	cluster := appv1.Cluster{
 		ID:                 string(s.UID),
 		Server:             strings.TrimRight(string(s.Data["server"]), "/"),
@@ -191,6 +225,7 @@ func secretToCluster(s *corev1.Secret) (*appv1.Cluster, error) {
 		Config:             config,
 		RefreshRequestedAt: refreshRequestedAt,
 		Shard:              shard,
+		Labels:             clusterLabels,
 	}
 	return &cluster, nil
 }
@@ -117,6 +117,37 @@ func TestValidateDestination(t *testing.T) {
 		assert.True(t, dest.IsServerInferred())
 	})
 
+	t.Run("Validate destination with cluster selector", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=prod",
+		}
+
+		prodSecret := createClusterSecret("prod-secret", "prod", "https://127.0.0.1:6443")
+		prodSecret.Labels["env"] = "prod"
+		stagingSecret := createClusterSecret("staging-secret", "staging", "https://127.0.0.1:7443")
+		stagingSecret.Labels["env"] = "staging"
+		kubeclientset := fake.NewSimpleClientset(prodSecret, stagingSecret)
+
+		appCond := ValidateDestination(context.Background(), &dest, kubeclientset, utils.ArgoCDNamespace)
+		assert.Nil(t, appCond)
+		assert.Equal(t, "https://127.0.0.1:6443", dest.Server)
+		assert.True(t, dest.IsServerInferred())
+	})
+
+	t.Run("No cluster matching the cluster selector", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=dev",
+		}
+
+		secret := createClusterSecret("prod-secret", "prod", "https://127.0.0.1:6443")
+		secret.Labels["env"] = "prod"
+		kubeclientset := fake.NewSimpleClientset(secret)
+
+		err := ValidateDestination(context.Background(), &dest, kubeclientset, utils.ArgoCDNamespace)
+		assert.Equal(t, `unable to find destination server: there are no clusters matching the selector "env=dev"`, err.Error())
+		assert.False(t, dest.IsServerInferred())
+	})
+
 	t.Run("Error when having both server url and name", func(t *testing.T) {
 		dest := argoappv1.ApplicationDestination{
 			Server:    "https://127.0.0.1:6443",
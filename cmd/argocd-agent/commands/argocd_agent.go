@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	cmdutil "github.com/argoproj/argo-cd/v2/cmd/util"
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/util/agent"
+	"github.com/argoproj/argo-cd/v2/util/cli"
+	"github.com/argoproj/argo-cd/v2/util/env"
+)
+
+const (
+	// CLIName is the name of the CLI
+	cliName = "argocd-agent"
+)
+
+// NewCommand returns a new instance of an argocd-agent command
+func NewCommand() *cobra.Command {
+	var (
+		serverURL     string
+		name          string
+		authToken     string
+		target        string
+		insecure      bool
+		retryInterval time.Duration
+	)
+	var command = cobra.Command{
+		Use:               cliName,
+		Short:             "Run the ArgoCD Agent",
+		Long:              "The ArgoCD Agent runs in a remote cluster and maintains an outbound connection to the ArgoCD API server, which allows ArgoCD to manage the cluster without inbound access to its Kubernetes API server. This command runs the Agent in the foreground.  It can be configured by following options.",
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+
+			vers := common.GetVersion()
+			vers.LogStartupInfo(
+				"ArgoCD Agent",
+				map[string]any{
+					"server": serverURL,
+					"name":   name,
+				},
+			)
+
+			cli.SetLogFormat(cmdutil.LogFormat)
+			cli.SetLogLevel(cmdutil.LogLevel)
+
+			if serverURL == "" || name == "" || authToken == "" {
+				return fmt.Errorf("--server, --name and --auth-token are required")
+			}
+
+			a := &agent.Agent{
+				ServerURL:     serverURL,
+				Name:          name,
+				Token:         authToken,
+				Target:        target,
+				Insecure:      insecure,
+				RetryInterval: retryInterval,
+			}
+			a.Run(ctx)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&serverURL, "server", env.StringFromEnv("ARGOCD_AGENT_SERVER", ""), "URL of the ArgoCD API server, e.g. https://argocd.example.com")
+	command.Flags().StringVar(&name, "name", env.StringFromEnv("ARGOCD_AGENT_NAME", ""), "Name of the cluster in ArgoCD which is managed by this agent")
+	command.Flags().StringVar(&authToken, "auth-token", env.StringFromEnv("ARGOCD_AGENT_AUTH_TOKEN", ""), "ArgoCD token used to authenticate the agent")
+	command.Flags().StringVar(&target, "target", env.StringFromEnv("ARGOCD_AGENT_TARGET", "kubernetes.default.svc:443"), "Address connections are tunneled to")
+	command.Flags().BoolVar(&insecure, "insecure", env.ParseBoolFromEnv("ARGOCD_AGENT_INSECURE", false), "Skip verification of the ArgoCD API server TLS certificate")
+	command.Flags().DurationVar(&retryInterval, "retry-interval", env.ParseDurationFromEnv("ARGOCD_AGENT_RETRY_INTERVAL", 5*time.Second, 0, time.Hour), "Time to wait before reconnecting to the ArgoCD API server")
+	command.Flags().StringVar(&cmdutil.LogFormat, "logformat", env.StringFromEnv("ARGOCD_AGENT_LOGFORMAT", "text"), "Set the logging format. One of: text|json")
+	command.Flags().StringVar(&cmdutil.LogLevel, "loglevel", env.StringFromEnv("ARGOCD_AGENT_LOGLEVEL", "info"), "Set the logging level. One of: debug|info|warn|error")
+	return &command
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
@@ -39,6 +40,8 @@ const (
 	defaultAppResyncPeriod = 180
 	// Default time in seconds for application hard resync period
 	defaultAppHardResyncPeriod = 0
+	// Default maximum time in seconds a resync of an application is delayed by to spread the load
+	defaultAppResyncJitter = 60
 )
 
 func NewCommand() *cobra.Command {
@@ -46,9 +49,15 @@ func NewCommand() *cobra.Command {
 		clientConfig             clientcmd.ClientConfig
 		appResyncPeriod          int64
 		appHardResyncPeriod      int64
+		appResyncJitter          int64
 		repoServerAddress        string
+		delegatedRepoServers     []string
 		repoServerTimeoutSeconds int
 		selfHealTimeoutSeconds   int
+		selfHealBackoffTimeout   int
+		selfHealBackoffFactor    int
+		selfHealBackoffCap       int
+		operationTimeoutSeconds  int
 		statusProcessors         int
 		operationProcessors      int
 		glogLevel                int
@@ -64,6 +73,8 @@ func NewCommand() *cobra.Command {
 		applicationNamespaces    []string
 		persistResourceHealth    bool
 		shardingAlgorithm        string
+		shardingLabel            string
+		dynamicDistribution      bool
 	)
 	var command = cobra.Command{
 		Use:               cliName,
@@ -136,24 +147,44 @@ func NewCommand() *cobra.Command {
 				appController.InvalidateProjectsCache()
 			}))
 			kubectl := kubeutil.NewKubectl()
-			clusterFilter := getClusterFilter(kubeClient, settingsMgr, shardingAlgorithm)
+			replicas := sharding.NewReplicas()
+			if dynamicDistribution {
+				current, err := sharding.GetControllerReplicas(ctx, kubeClient, namespace)
+				errors.CheckError(err)
+				replicas.Set(current)
+			}
+			var selfHealBackoff *wait.Backoff
+			if selfHealBackoffTimeout > 0 {
+				selfHealBackoff = &wait.Backoff{
+					Duration: time.Duration(selfHealBackoffTimeout) * time.Second,
+					Factor:   float64(selfHealBackoffFactor),
+					Cap:      time.Duration(selfHealBackoffCap) * time.Second,
+				}
+			}
+			clusterFilter, appFilter, shard := getShardFilters(kubeClient, settingsMgr, shardingAlgorithm, shardingLabel, dynamicDistribution, replicas)
 			appController, err = controller.NewApplicationController(
 				namespace,
 				settingsMgr,
 				kubeClient,
 				appClient,
 				repoClientset,
+				delegatedRepoServers,
 				cache,
 				kubectl,
 				resyncDuration,
 				hardResyncDuration,
+				time.Duration(appResyncJitter)*time.Second,
 				time.Duration(selfHealTimeoutSeconds)*time.Second,
+				selfHealBackoff,
+				time.Duration(operationTimeoutSeconds)*time.Second,
 				metricsPort,
 				metricsCacheExpiration,
 				metricsAplicationLabels,
 				kubectlParallelismLimit,
 				persistResourceHealth,
 				clusterFilter,
+				appFilter,
+				shard,
 				applicationNamespaces,
 			)
 			errors.CheckError(err)
@@ -172,6 +203,11 @@ func NewCommand() *cobra.Command {
 			}
 
 			go appController.Run(ctx, statusProcessors, operationProcessors)
+			if dynamicDistribution {
+				go sharding.WatchControllerReplicas(ctx, kubeClient, namespace, common.DefaultShardReplicasCheckInterval, replicas, func(_ int) {
+					appController.RebalanceShards()
+				})
+			}
 
 			// Wait forever
 			select {}
@@ -181,7 +217,9 @@ func NewCommand() *cobra.Command {
 	clientConfig = cli.AddKubectlFlagsToCmd(&command)
 	command.Flags().Int64Var(&appResyncPeriod, "app-resync", int64(env.ParseDurationFromEnv("ARGOCD_RECONCILIATION_TIMEOUT", defaultAppResyncPeriod*time.Second, 0, math.MaxInt64).Seconds()), "Time period in seconds for application resync.")
 	command.Flags().Int64Var(&appHardResyncPeriod, "app-hard-resync", int64(env.ParseDurationFromEnv("ARGOCD_HARD_RECONCILIATION_TIMEOUT", defaultAppHardResyncPeriod*time.Second, 0, math.MaxInt64).Seconds()), "Time period in seconds for application hard resync.")
-	command.Flags().StringVar(&repoServerAddress, "repo-server", env.StringFromEnv("ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER", common.DefaultRepoServerAddr), "Repo server address.")
+	command.Flags().Int64Var(&appResyncJitter, "app-resync-jitter", int64(env.ParseDurationFromEnv("ARGOCD_RECONCILIATION_JITTER", defaultAppResyncJitter*time.Second, 0, math.MaxInt64).Seconds()), "Maximum time period in seconds to add as a delay jitter for application resync.")
+	command.Flags().StringVar(&repoServerAddress, "repo-server", env.StringFromEnv("ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER", common.DefaultRepoServerAddr), "Repo server address. Use a comma-separated list of addresses to shard repositories across repo servers")
+	command.Flags().StringSliceVar(&delegatedRepoServers, "delegated-repo-servers", env.StringsFromEnv("ARGOCD_APPLICATION_CONTROLLER_DELEGATED_REPO_SERVERS", []string{}, ","), "List of addresses of repo servers which clusters are allowed to delegate manifest generation to. Addresses can be glob patterns")
 	command.Flags().IntVar(&repoServerTimeoutSeconds, "repo-server-timeout-seconds", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS", 60, 0, math.MaxInt64), "Repo server RPC call timeout seconds.")
 	command.Flags().IntVar(&statusProcessors, "status-processors", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS", 20, 0, math.MaxInt32), "Number of application status processors")
 	command.Flags().IntVar(&operationProcessors, "operation-processors", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS", 10, 0, math.MaxInt32), "Number of application operation processors")
@@ -191,6 +229,10 @@ func NewCommand() *cobra.Command {
 	command.Flags().IntVar(&metricsPort, "metrics-port", common.DefaultPortArgoCDMetrics, "Start metrics server on given port")
 	command.Flags().DurationVar(&metricsCacheExpiration, "metrics-cache-expiration", env.ParseDurationFromEnv("ARGOCD_APPLICATION_CONTROLLER_METRICS_CACHE_EXPIRATION", 0*time.Second, 0, math.MaxInt64), "Prometheus metrics cache expiration (disabled  by default. e.g. 24h0m0s)")
 	command.Flags().IntVar(&selfHealTimeoutSeconds, "self-heal-timeout-seconds", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_TIMEOUT_SECONDS", 5, 0, math.MaxInt32), "Specifies timeout between application self heal attempts")
+	command.Flags().IntVar(&selfHealBackoffTimeout, "self-heal-backoff-timeout-seconds", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_TIMEOUT_SECONDS", 0, 0, math.MaxInt32), "Specifies the initial timeout of the exponential backoff between application self heal attempts. Overrides --self-heal-timeout-seconds when greater than 0")
+	command.Flags().IntVar(&selfHealBackoffFactor, "self-heal-backoff-factor", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_FACTOR", 3, 1, math.MaxInt32), "Specifies the factor by which the self heal timeout is multiplied after each consecutive self heal attempt")
+	command.Flags().IntVar(&selfHealBackoffCap, "self-heal-backoff-cap-seconds", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_CAP_SECONDS", 300, 0, math.MaxInt32), "Specifies the maximum timeout between application self heal attempts when using the exponential backoff")
+	command.Flags().IntVar(&operationTimeoutSeconds, "operation-max-duration-seconds", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_OPERATION_MAX_DURATION_SECONDS", 0, 0, math.MaxInt32), "Specifies the maximum duration of application operations, after which running operations are considered stuck and failed. Any value less than 1 means no limit.")
 	command.Flags().Int64Var(&kubectlParallelismLimit, "kubectl-parallelism-limit", env.ParseInt64FromEnv("ARGOCD_APPLICATION_CONTROLLER_KUBECTL_PARALLELISM_LIMIT", 20, 0, math.MaxInt64), "Number of allowed concurrent kubectl fork/execs. Any value less than 1 means no limit.")
 	command.Flags().BoolVar(&repoServerPlaintext, "repo-server-plaintext", env.ParseBoolFromEnv("ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_PLAINTEXT", false), "Disable TLS on connections to repo server")
 	command.Flags().BoolVar(&repoServerStrictTLS, "repo-server-strict-tls", env.ParseBoolFromEnv("ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_STRICT_TLS", false), "Whether to use strict validation of the TLS cert presented by the repo server")
@@ -198,30 +240,42 @@ func NewCommand() *cobra.Command {
 	command.Flags().StringVar(&otlpAddress, "otlp-address", env.StringFromEnv("ARGOCD_APPLICATION_CONTROLLER_OTLP_ADDRESS", ""), "OpenTelemetry collector address to send traces to")
 	command.Flags().StringSliceVar(&applicationNamespaces, "application-namespaces", env.StringsFromEnv("ARGOCD_APPLICATION_NAMESPACES", []string{}, ","), "List of additional namespaces that applications are allowed to be reconciled from")
 	command.Flags().BoolVar(&persistResourceHealth, "persist-resource-health", env.ParseBoolFromEnv("ARGOCD_APPLICATION_CONTROLLER_PERSIST_RESOURCE_HEALTH", true), "Enables storing the managed resources health in the Application CRD")
-	command.Flags().StringVar(&shardingAlgorithm, "sharding-method", env.StringFromEnv(common.EnvControllerShardingAlgorithm, common.DefaultShardingAlgorithm), "Enables choice of sharding method. Supported sharding methods are : [legacy, round-robin] ")
+	command.Flags().StringVar(&shardingAlgorithm, "sharding-method", env.StringFromEnv(common.EnvControllerShardingAlgorithm, common.DefaultShardingAlgorithm), "Enables choice of sharding method. Supported sharding methods are : [legacy, round-robin, project, label] ")
+	command.Flags().BoolVar(&dynamicDistribution, "dynamic-cluster-distribution-enabled", env.ParseBoolFromEnv(common.EnvEnableDynamicClusterDistribution, false), "Enables rebalancing the clusters across shards when the number of application controller replicas changes")
+	command.Flags().StringVar(&shardingLabel, "sharding-label", env.StringFromEnv(common.EnvControllerShardingLabel, common.DefaultShardingLabel), "Label which assigns applications to shards when using the label sharding method")
 	cacheSrc = appstatecache.AddCacheFlagsToCmd(&command, func(client *redis.Client) {
 		redisClient = client
 	})
 	return &command
 }
 
-func getClusterFilter(kubeClient *kubernetes.Clientset, settingsMgr *settings.SettingsManager, shardingAlgorithm string) sharding.ClusterFilterFunction {
-	replicas := env.ParseNumFromEnv(common.EnvControllerReplicas, 0, 0, math.MaxInt32)
+// getShardFilters returns the filter of the clusters, or with the project and label sharding methods the filter of
+// the applications, which are processed by this controller shard, as well as the number of the shard processing the
+// clusters, or -1 if the clusters aren't distributed across shards. With the dynamic cluster distribution the filters
+// are created even for a single replica, so that clusters are distributed once replicas are added.
+func getShardFilters(kubeClient *kubernetes.Clientset, settingsMgr *settings.SettingsManager, shardingAlgorithm string, shardingLabel string, dynamicDistribution bool, replicas *sharding.Replicas) (sharding.ClusterFilterFunction, sharding.AppFilterFunction, int) {
 	shard := env.ParseNumFromEnv(common.EnvControllerShard, -1, -math.MaxInt32, math.MaxInt32)
 	var clusterFilter func(cluster *v1alpha1.Cluster) bool
-	if replicas > 1 {
+	var appFilter func(app *v1alpha1.Application) bool
+	if replicas.Get() > 1 || dynamicDistribution {
 		if shard < 0 {
 			var err error
 			shard, err = sharding.InferShard()
 			errors.CheckError(err)
 		}
+		if sharding.IsAppShardingAlgorithm(shardingAlgorithm) {
+			// applications of a shard may be deployed to any cluster, so all clusters are processed
+			log.Infof("Processing applications from shard %d using sharding method %s", shard, shardingAlgorithm)
+			return nil, sharding.GetAppFilter(shardingAlgorithm, shardingLabel, shard, replicas), -1
+		}
 		log.Infof("Processing clusters from shard %d", shard)
 		db := db.NewDB(settingsMgr.GetNamespace(), settingsMgr, kubeClient)
 		log.Infof("Using filter function:  %s", shardingAlgorithm)
-		distributionFunction := sharding.GetDistributionFunction(db, shardingAlgorithm)
-		clusterFilter = sharding.GetClusterFilter(distributionFunction, shard)
+		distributionFunction := sharding.GetDistributionFunction(db, shardingAlgorithm, replicas)
+		clusterFilter = sharding.GetClusterFilter(distributionFunction, shard, replicas)
 	} else {
 		log.Info("Processing all cluster shards")
+		shard = -1
 	}
-	return clusterFilter
+	return clusterFilter, appFilter, shard
 }
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/argoproj/pkg/stats"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/test/bufconn"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	cmdutil "github.com/argoproj/argo-cd/v2/cmd/util"
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/controller"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	appclientset "github.com/argoproj/argo-cd/v2/pkg/client/clientset/versioned"
+	"github.com/argoproj/argo-cd/v2/reposerver"
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/argoproj/argo-cd/v2/reposerver/askpass"
+	reposervercache "github.com/argoproj/argo-cd/v2/reposerver/cache"
+	"github.com/argoproj/argo-cd/v2/reposerver/metrics"
+	"github.com/argoproj/argo-cd/v2/reposerver/repository"
+	"github.com/argoproj/argo-cd/v2/server"
+	servercache "github.com/argoproj/argo-cd/v2/server/cache"
+	cacheutil "github.com/argoproj/argo-cd/v2/util/cache"
+	appstatecache "github.com/argoproj/argo-cd/v2/util/cache/appstate"
+	"github.com/argoproj/argo-cd/v2/util/cli"
+	"github.com/argoproj/argo-cd/v2/util/env"
+	"github.com/argoproj/argo-cd/v2/util/errors"
+	kubeutil "github.com/argoproj/argo-cd/v2/util/kube"
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+const (
+	// CLIName is the name of the CLI
+	cliName = "argocd-core"
+	// Default time in seconds for application resync period
+	defaultAppResyncPeriod = 180
+	// Size of the in-memory buffer used by the connection between the API server/controller and the repo server
+	repoServerBufferSize = 1024 * 1024
+)
+
+// NewCommand returns a new instance of an argocd-core command
+func NewCommand() *cobra.Command {
+	var (
+		clientConfig                      clientcmd.ClientConfig
+		insecure                          bool
+		listenHost                        string
+		listenPort                        int
+		metricsHost                       string
+		apiServerMetricsPort              int
+		controllerMetricsPort             int
+		repoServerMetricsPort             int
+		glogLevel                         int
+		staticAssetsDir                   string
+		disableAuth                       bool
+		applicationNamespaces             []string
+		appResyncPeriod                   int64
+		selfHealTimeoutSeconds            int
+		statusProcessors                  int
+		operationProcessors               int
+		kubectlParallelismLimit           int64
+		parallelismLimit                  int64
+		repoServerTimeoutSeconds          int
+		maxCombinedDirectoryManifestsSize string
+		cacheExpiration                   time.Duration
+	)
+	var command = cobra.Command{
+		Use:               cliName,
+		Short:             "Run the ArgoCD API server, repository server and application controller in a single process",
+		Long:              "ArgoCD core runs the API server, the repository server and the application controller in a single process. The components talk to each other using in-memory transports and share an in-memory cache, so neither Redis nor network connections between the components are required. It is intended for laptops, edge clusters and end-to-end tests. This command runs all components in the foreground.  It can be configured by following options.",
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx, cancel := context.WithCancel(c.Context())
+			defer cancel()
+
+			vers := common.GetVersion()
+			namespace, _, err := clientConfig.Namespace()
+			errors.CheckError(err)
+			vers.LogStartupInfo(
+				"ArgoCD Core",
+				map[string]any{
+					"namespace": namespace,
+					"port":      listenPort,
+				},
+			)
+
+			cli.SetLogFormat(cmdutil.LogFormat)
+			cli.SetLogLevel(cmdutil.LogLevel)
+			cli.SetGLogLevel(glogLevel)
+
+			config, err := clientConfig.ClientConfig()
+			errors.CheckError(err)
+			errors.CheckError(v1alpha1.SetK8SConfigDefaults(config))
+			config.UserAgent = fmt.Sprintf("argocd-core/%s (%s)", vers.Version, vers.Platform)
+
+			kubeClient := kubernetes.NewForConfigOrDie(config)
+			appClient := appclientset.NewForConfigOrDie(config)
+
+			// All components share a single in-memory cache instead of Redis
+			cache := cacheutil.NewCache(cacheutil.NewInMemoryCache(cacheExpiration))
+			appStateCache := appstatecache.NewCache(cache, cacheExpiration)
+
+			maxCombinedDirectoryManifestsQuantity, err := resource.ParseQuantity(maxCombinedDirectoryManifestsSize)
+			errors.CheckError(err)
+
+			askPassServer := askpass.NewServer()
+			repoMetricsServer := metrics.NewMetricsServer()
+			repoServer, err := reposerver.NewServer(repoMetricsServer, reposervercache.NewCache(cache, cacheExpiration, 3*time.Minute), nil, repository.RepoServerInitConstants{
+				ParallelismLimit:                  parallelismLimit,
+				SubmoduleEnabled:                  env.ParseBoolFromEnv(common.EnvGitSubmoduleEnabled, true),
+				MaxCombinedDirectoryManifestsSize: maxCombinedDirectoryManifestsQuantity,
+				StreamedManifestMaxExtractedSize:  1024 * 1024 * 1024,
+				StreamedManifestMaxTarSize:        100 * 1024 * 1024,
+			}, askPassServer)
+			errors.CheckError(err)
+			go func() { errors.CheckError(askPassServer.Run(askpass.SocketPath)) }()
+
+			// The repo server is served over an in-memory listener, so manifest generation requests never leave the process
+			repoServerListener := bufconn.Listen(repoServerBufferSize)
+			go func() { errors.CheckError(repoServer.CreateGRPC().Serve(repoServerListener)) }()
+			repoClientset := apiclient.NewInMemoryRepoServerClientset(func(ctx context.Context, _ string) (net.Conn, error) {
+				return repoServerListener.DialContext(ctx)
+			}, repoServerTimeoutSeconds)
+
+			repoMetricsMux := http.NewServeMux()
+			repoMetricsMux.Handle("/metrics", repoMetricsServer.GetHandler())
+			go func() {
+				errors.CheckError(http.ListenAndServe(fmt.Sprintf("%s:%d", metricsHost, repoServerMetricsPort), repoMetricsMux))
+			}()
+
+			var appController *controller.ApplicationController
+			settingsMgr := settings.NewSettingsManager(ctx, kubeClient, namespace, settings.WithRepoOrClusterChangedHandler(func() {
+				appController.InvalidateProjectsCache()
+			}))
+			appController, err = controller.NewApplicationController(
+				namespace,
+				settingsMgr,
+				kubeClient,
+				appClient,
+				repoClientset,
+				nil,
+				appStateCache,
+				kubeutil.NewKubectl(),
+				time.Duration(appResyncPeriod)*time.Second,
+				0,
+				0,
+				time.Duration(selfHealTimeoutSeconds)*time.Second,
+				nil,
+				0,
+				controllerMetricsPort,
+				0,
+				nil,
+				kubectlParallelismLimit,
+				true,
+				nil,
+				nil,
+				-1,
+				applicationNamespaces,
+			)
+			errors.CheckError(err)
+
+			// no Redis client is set, so the API server keeps revoked tokens in memory until they expire
+			argocd := server.NewServer(ctx, server.ArgoCDServerOpts{
+				Insecure:              insecure,
+				ListenPort:            listenPort,
+				ListenHost:            listenHost,
+				MetricsPort:           apiServerMetricsPort,
+				MetricsHost:           metricsHost,
+				Namespace:             namespace,
+				BaseHRef:              "/",
+				KubeClientset:         kubeClient,
+				AppClientset:          appClient,
+				RepoClientset:         repoClientset,
+				DisableAuth:           disableAuth,
+				EnableGZip:            true,
+				Cache:                 servercache.NewCache(appStateCache, time.Hour, 3*time.Minute, 24*time.Hour),
+				XFrameOptions:         "sameorigin",
+				ContentSecurityPolicy: "frame-ancestors 'self';",
+				StaticAssetsDir:       staticAssetsDir,
+				ApplicationNamespaces: applicationNamespaces,
+			})
+
+			stats.RegisterStackDumper()
+			stats.StartStatsTicker(10 * time.Minute)
+			stats.RegisterHeapDumper("memprofile")
+
+			go appController.Run(ctx, statusProcessors, operationProcessors)
+
+			argocd.Init(ctx)
+			lns, err := argocd.Listen()
+			errors.CheckError(err)
+			log.Infof("argocd-core is listening on %s:%d", listenHost, listenPort)
+			for {
+				ctx, cancel := context.WithCancel(ctx)
+				argocd.Run(ctx, lns)
+				cancel()
+			}
+		},
+	}
+
+	clientConfig = cli.AddKubectlFlagsToCmd(&command)
+	command.Flags().BoolVar(&insecure, "insecure", env.ParseBoolFromEnv("ARGOCD_SERVER_INSECURE", false), "Run server without TLS")
+	command.Flags().StringVar(&listenHost, "address", env.StringFromEnv("ARGOCD_SERVER_LISTEN_ADDRESS", common.DefaultAddressAPIServer), "Listen on given address")
+	command.Flags().IntVar(&listenPort, "port", common.DefaultPortAPIServer, "Listen on given port")
+	command.Flags().StringVar(&metricsHost, "metrics-address", env.StringFromEnv("ARGOCD_SERVER_METRICS_LISTEN_ADDRESS", common.DefaultAddressAPIServerMetrics), "Listen for metrics on given address")
+	command.Flags().IntVar(&apiServerMetricsPort, "metrics-port", common.DefaultPortArgoCDAPIServerMetrics, "Start API server metrics on given port")
+	command.Flags().IntVar(&controllerMetricsPort, "controller-metrics-port", common.DefaultPortArgoCDMetrics, "Start application controller metrics on given port")
+	command.Flags().IntVar(&repoServerMetricsPort, "repo-server-metrics-port", common.DefaultPortRepoServerMetrics, "Start repo server metrics on given port")
+	command.Flags().StringVar(&cmdutil.LogFormat, "logformat", env.StringFromEnv("ARGOCD_CORE_LOGFORMAT", "text"), "Set the logging format. One of: text|json")
+	command.Flags().StringVar(&cmdutil.LogLevel, "loglevel", env.StringFromEnv("ARGOCD_CORE_LOGLEVEL", "info"), "Set the logging level. One of: debug|info|warn|error")
+	command.Flags().IntVar(&glogLevel, "gloglevel", 0, "Set the glog logging level")
+	command.Flags().StringVar(&staticAssetsDir, "staticassets", env.StringFromEnv("ARGOCD_SERVER_STATIC_ASSETS", "/shared/app"), "Directory path that contains additional static assets")
+	command.Flags().BoolVar(&disableAuth, "disable-auth", env.ParseBoolFromEnv("ARGOCD_SERVER_DISABLE_AUTH", false), "Disable client authentication")
+	command.Flags().StringSliceVar(&applicationNamespaces, "application-namespaces", env.StringsFromEnv("ARGOCD_APPLICATION_NAMESPACES", []string{}, ","), "List of additional namespaces that applications are allowed to be reconciled from")
+	command.Flags().Int64Var(&appResyncPeriod, "app-resync", int64(env.ParseDurationFromEnv("ARGOCD_RECONCILIATION_TIMEOUT", defaultAppResyncPeriod*time.Second, 0, math.MaxInt64).Seconds()), "Time period in seconds for application resync.")
+	command.Flags().IntVar(&selfHealTimeoutSeconds, "self-heal-timeout-seconds", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_TIMEOUT_SECONDS", 5, 0, math.MaxInt32), "Specifies timeout between application self heal attempts")
+	command.Flags().IntVar(&statusProcessors, "status-processors", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS", 20, 0, math.MaxInt32), "Number of application status processors")
+	command.Flags().IntVar(&operationProcessors, "operation-processors", env.ParseNumFromEnv("ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS", 10, 0, math.MaxInt32), "Number of application operation processors")
+	command.Flags().Int64Var(&kubectlParallelismLimit, "kubectl-parallelism-limit", env.ParseInt64FromEnv("ARGOCD_APPLICATION_CONTROLLER_KUBECTL_PARALLELISM_LIMIT", 20, 0, math.MaxInt64), "Number of allowed concurrent kubectl fork/execs. Any value less than 1 means no limit.")
+	command.Flags().Int64Var(&parallelismLimit, "parallelismlimit", int64(env.ParseNumFromEnv("ARGOCD_REPO_SERVER_PARALLELISM_LIMIT", 0, 0, math.MaxInt32)), "Limit on number of concurrent manifests generate requests. Any value less the 1 means no limit.")
+	command.Flags().IntVar(&repoServerTimeoutSeconds, "repo-server-timeout-seconds", env.ParseNumFromEnv("ARGOCD_SERVER_REPO_SERVER_TIMEOUT_SECONDS", 60, 0, math.MaxInt64), "Repo server RPC call timeout seconds.")
+	command.Flags().StringVar(&maxCombinedDirectoryManifestsSize, "max-combined-directory-manifests-size", env.StringFromEnv("ARGOCD_REPO_SERVER_MAX_COMBINED_DIRECTORY_MANIFESTS_SIZE", "10M"), "Max combined size of manifest files in a directory-type Application")
+	command.Flags().DurationVar(&cacheExpiration, "cache-expiration", env.ParseDurationFromEnv("ARGOCD_CORE_CACHE_EXPIRATION", 24*time.Hour, 0, math.MaxInt64), "Expiration of the in-memory cache shared by all components")
+	return &command
+}
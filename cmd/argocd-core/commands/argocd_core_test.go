@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommand(t *testing.T) {
+	t.Setenv("ARGOCD_CORE_CACHE_EXPIRATION", "1h")
+	command := NewCommand()
+	assert.Equal(t, cliName, command.Use)
+
+	cacheExpiration, err := command.Flags().GetDuration("cache-expiration")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, cacheExpiration)
+
+	// all components share an in-memory cache, so there are no Redis flags
+	assert.Nil(t, command.Flags().Lookup("redis"))
+
+	require.NoError(t, command.Flags().Parse([]string{"--parallelismlimit", "5", "--application-namespaces", "team-a,team-b"}))
+	parallelismLimit, err := command.Flags().GetInt64("parallelismlimit")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), parallelismLimit)
+	applicationNamespaces, err := command.Flags().GetStringSlice("application-namespaces")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team-a", "team-b"}, applicationNamespaces)
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/argoproj/argo-cd/v2/util/errors"
+)
+
+const (
+	// defaultAzureServerID is the application ID of the Azure Kubernetes Service AAD Server, which is the audience of
+	// the tokens accepted by AKS clusters using Azure AD authentication
+	defaultAzureServerID = "6dae42f8-4368-4678-94ff-3960e28e3630"
+	// defaultAzureAuthorityHost is the Azure AD endpoint used when AZURE_AUTHORITY_HOST isn't set
+	defaultAzureAuthorityHost = "https://login.microsoftonline.com/"
+	azureClientAssertionType  = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// azureTokenRequestTimeout is the maximum duration of the Azure AD token request
+	azureTokenRequestTimeout = 30 * time.Second
+)
+
+// azureWorkloadIdentity is the configuration injected into pods by the Azure Workload Identity webhook
+type azureWorkloadIdentity struct {
+	AuthorityHost      string
+	TenantID           string
+	ClientID           string
+	FederatedTokenFile string
+}
+
+// newAzureCommand returns a new instance of an azure command that generates k8s auth token using Azure Workload Identity
+func newAzureCommand() *cobra.Command {
+	var (
+		serverID string
+	)
+	var command = &cobra.Command{
+		Use: "azure",
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			token, expiration, err := getAzureWorkloadIdentityToken(ctx, &http.Client{Timeout: azureTokenRequestTimeout}, azureWorkloadIdentityFromEnv(), serverID)
+			errors.CheckError(err)
+			_, _ = fmt.Fprint(os.Stdout, formatJSON(token, expiration))
+		},
+	}
+	command.Flags().StringVar(&serverID, "server-id", defaultAzureServerID, "Application ID of the Azure AD server application of the AKS cluster")
+	return command
+}
+
+func azureWorkloadIdentityFromEnv() azureWorkloadIdentity {
+	authorityHost := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authorityHost == "" {
+		authorityHost = defaultAzureAuthorityHost
+	}
+	return azureWorkloadIdentity{
+		AuthorityHost:      authorityHost,
+		TenantID:           os.Getenv("AZURE_TENANT_ID"),
+		ClientID:           os.Getenv("AZURE_CLIENT_ID"),
+		FederatedTokenFile: os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+	}
+}
+
+// getAzureWorkloadIdentityToken exchanges the federated service account token for an Azure AD access token of the
+// given server application, and returns the access token and its expiration time
+func getAzureWorkloadIdentityToken(ctx context.Context, client *http.Client, identity azureWorkloadIdentity, serverID string) (string, time.Time, error) {
+	if identity.TenantID == "" || identity.ClientID == "" || identity.FederatedTokenFile == "" {
+		return "", time.Time{}, fmt.Errorf("AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_FEDERATED_TOKEN_FILE must be set, make sure that Azure Workload Identity is enabled for the service account")
+	}
+	assertion, err := os.ReadFile(identity.FederatedTokenFile)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading the federated token file: %w", err)
+	}
+	form := url.Values{
+		"client_id":             {identity.ClientID},
+		"scope":                 {serverID + "/.default"},
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {azureClientAssertionType},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(identity.AuthorityHost, "/"), identity.TenantID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating the Azure AD token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error requesting the Azure AD token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading the Azure AD token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("error requesting the Azure AD token: %s: %s", resp.Status, string(body))
+	}
+	var res struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing the Azure AD token response: %w", err)
+	}
+	if res.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("the Azure AD token response doesn't contain an access token")
+	}
+	return res.AccessToken, time.Now().Add(time.Duration(res.ExpiresIn) * time.Second), nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAzureWorkloadIdentityToken(t *testing.T) {
+	ctx := context.Background()
+	tokenFile := filepath.Join(t.TempDir(), "azure-identity-token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated-token\n"), 0600))
+
+	t.Run("will exchange the federated token", func(t *testing.T) {
+		// given
+		var path string
+		var form url.Values
+		var formErr error
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			formErr = r.ParseForm()
+			form = r.PostForm
+			_, _ = w.Write([]byte(`{"token_type":"Bearer","expires_in":3600,"access_token":"token"}`))
+		}))
+		defer server.Close()
+		identity := azureWorkloadIdentity{AuthorityHost: server.URL + "/", TenantID: "tenant", ClientID: "client", FederatedTokenFile: tokenFile}
+
+		// when
+		token, expiration, err := getAzureWorkloadIdentityToken(ctx, server.Client(), identity, defaultAzureServerID)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, "/tenant/oauth2/v2.0/token", path)
+		require.NoError(t, formErr)
+		assert.Equal(t, "client", form.Get("client_id"))
+		assert.Equal(t, defaultAzureServerID+"/.default", form.Get("scope"))
+		assert.Equal(t, "client_credentials", form.Get("grant_type"))
+		assert.Equal(t, azureClientAssertionType, form.Get("client_assertion_type"))
+		assert.Equal(t, "federated-token", form.Get("client_assertion"))
+		assert.Equal(t, "token", token)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiration, time.Minute)
+	})
+	t.Run("will return the error of the token request", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+		}))
+		defer server.Close()
+		identity := azureWorkloadIdentity{AuthorityHost: server.URL, TenantID: "tenant", ClientID: "client", FederatedTokenFile: tokenFile}
+
+		// when
+		_, _, err := getAzureWorkloadIdentityToken(ctx, server.Client(), identity, defaultAzureServerID)
+
+		// then
+		assert.ErrorContains(t, err, "invalid_client")
+	})
+	t.Run("will fail without workload identity", func(t *testing.T) {
+		// when
+		_, _, err := getAzureWorkloadIdentityToken(ctx, http.DefaultClient, azureWorkloadIdentity{AuthorityHost: defaultAzureAuthorityHost}, defaultAzureServerID)
+
+		// then
+		assert.ErrorContains(t, err, "AZURE_FEDERATED_TOKEN_FILE")
+	})
+}
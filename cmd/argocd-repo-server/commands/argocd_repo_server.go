@@ -82,6 +82,12 @@ func NewCommand() *cobra.Command {
 		allowOutOfBoundsSymlinks          bool
 		streamedManifestMaxTarSize        string
 		streamedManifestMaxExtractedSize  string
+		enableGitSparseCheckout           bool
+		toolRateLimitQPS                  float64
+		toolRateLimitBurst                int
+		toolRateLimitPerRepoQPS           float64
+		toolRateLimitPerRepoBurst         int
+		gitReferenceCachePath             string
 	)
 	var command = cobra.Command{
 		Use:               cliName,
@@ -134,6 +140,12 @@ func NewCommand() *cobra.Command {
 				AllowOutOfBoundsSymlinks:                     allowOutOfBoundsSymlinks,
 				StreamedManifestMaxExtractedSize:             streamedManifestMaxExtractedSizeQuantity.ToDec().Value(),
 				StreamedManifestMaxTarSize:                   streamedManifestMaxTarSizeQuantity.ToDec().Value(),
+				SparseCheckoutEnabled:                        enableGitSparseCheckout,
+				ToolRateLimitQPS:                             toolRateLimitQPS,
+				ToolRateLimitBurst:                           toolRateLimitBurst,
+				ToolRateLimitPerRepoQPS:                      toolRateLimitPerRepoQPS,
+				ToolRateLimitPerRepoBurst:                    toolRateLimitPerRepoBurst,
+				GitReferenceCachePath:                        gitReferenceCachePath,
 			}, askPassServer)
 			errors.CheckError(err)
 
@@ -216,6 +228,12 @@ func NewCommand() *cobra.Command {
 	command.Flags().BoolVar(&allowOutOfBoundsSymlinks, "allow-oob-symlinks", env.ParseBoolFromEnv("ARGOCD_REPO_SERVER_ALLOW_OUT_OF_BOUNDS_SYMLINKS", false), "Allow out-of-bounds symlinks in repositories (not recommended)")
 	command.Flags().StringVar(&streamedManifestMaxTarSize, "streamed-manifest-max-tar-size", env.StringFromEnv("ARGOCD_REPO_SERVER_STREAMED_MANIFEST_MAX_TAR_SIZE", "100M"), "Maximum size of streamed manifest archives")
 	command.Flags().StringVar(&streamedManifestMaxExtractedSize, "streamed-manifest-max-extracted-size", env.StringFromEnv("ARGOCD_REPO_SERVER_STREAMED_MANIFEST_MAX_EXTRACTED_SIZE", "1G"), "Maximum size of streamed manifest archives when extracted")
+	command.Flags().BoolVar(&enableGitSparseCheckout, "enable-git-sparse-checkout", env.ParseBoolFromEnv("ARGOCD_REPO_SERVER_ENABLE_GIT_SPARSE_CHECKOUT", false), "Restrict the working tree of Git repositories to the paths used by the application when generating manifests")
+	command.Flags().Float64Var(&toolRateLimitQPS, "tool-rate-limit-qps", float64(env.ParseFloatFromEnv("ARGOCD_REPO_SERVER_TOOL_RATE_LIMIT_QPS", 0, 0, math.MaxFloat32)), "Maximum number of executions of git, helm, kustomize and config management plugins per second. Any value less than or equal to 0 means no limit.")
+	command.Flags().IntVar(&toolRateLimitBurst, "tool-rate-limit-burst", env.ParseNumFromEnv("ARGOCD_REPO_SERVER_TOOL_RATE_LIMIT_BURST", 0, 0, math.MaxInt32), "Maximum burst of executions of git, helm, kustomize and config management plugins. Any value less than 1 means the rate limit rounded up.")
+	command.Flags().Float64Var(&toolRateLimitPerRepoQPS, "tool-rate-limit-per-repo-qps", float64(env.ParseFloatFromEnv("ARGOCD_REPO_SERVER_TOOL_RATE_LIMIT_PER_REPO_QPS", 0, 0, math.MaxFloat32)), "Maximum number of executions of git, helm, kustomize and config management plugins per second and repository. Any value less than or equal to 0 means no limit.")
+	command.Flags().IntVar(&toolRateLimitPerRepoBurst, "tool-rate-limit-per-repo-burst", env.ParseNumFromEnv("ARGOCD_REPO_SERVER_TOOL_RATE_LIMIT_PER_REPO_BURST", 0, 0, math.MaxInt32), "Maximum burst of executions of git, helm, kustomize and config management plugins per repository. Any value less than 1 means the rate limit rounded up.")
+	command.Flags().StringVar(&gitReferenceCachePath, "git-reference-cache-path", env.StringFromEnv("ARGOCD_REPO_SERVER_GIT_REFERENCE_CACHE_PATH", ""), "Directory of the bare mirrors sharing their objects with the working copies of Git repositories. Disabled if empty.")
 	tlsConfigCustomizerSrc = tls.AddTLSFlagsToCmd(&command)
 	cacheSrc = reposervercache.AddCacheFlagsToCmd(&command, func(client *redis.Client) {
 		redisClient = client
@@ -74,6 +74,10 @@ func NewCommand() *cobra.Command {
 		staticAssetsDir          string
 		applicationNamespaces    []string
 		enableProxyExtension     bool
+		webhookCacheWarming      bool
+		enableAgentTunnel        bool
+		agentProxyListenHost     string
+		agentProxyPort           int
 	)
 	var command = &cobra.Command{
 		Use:               cliName,
@@ -190,6 +194,10 @@ func NewCommand() *cobra.Command {
 				StaticAssetsDir:       staticAssetsDir,
 				ApplicationNamespaces: applicationNamespaces,
 				EnableProxyExtension:  enableProxyExtension,
+				WebhookCacheWarming:   webhookCacheWarming,
+				EnableAgentTunnel:     enableAgentTunnel,
+				AgentProxyListenHost:  agentProxyListenHost,
+				AgentProxyPort:        agentProxyPort,
 			}
 
 			stats.RegisterStackDumper()
@@ -225,7 +233,7 @@ func NewCommand() *cobra.Command {
 	command.Flags().StringVar(&cmdutil.LogFormat, "logformat", env.StringFromEnv("ARGOCD_SERVER_LOGFORMAT", "text"), "Set the logging format. One of: text|json")
 	command.Flags().StringVar(&cmdutil.LogLevel, "loglevel", env.StringFromEnv("ARGOCD_SERVER_LOG_LEVEL", "info"), "Set the logging level. One of: debug|info|warn|error")
 	command.Flags().IntVar(&glogLevel, "gloglevel", 0, "Set the glog logging level")
-	command.Flags().StringVar(&repoServerAddress, "repo-server", env.StringFromEnv("ARGOCD_SERVER_REPO_SERVER", common.DefaultRepoServerAddr), "Repo server address")
+	command.Flags().StringVar(&repoServerAddress, "repo-server", env.StringFromEnv("ARGOCD_SERVER_REPO_SERVER", common.DefaultRepoServerAddr), "Repo server address. Use a comma-separated list of addresses to shard repositories across repo servers")
 	command.Flags().StringVar(&dexServerAddress, "dex-server", env.StringFromEnv("ARGOCD_SERVER_DEX_SERVER", common.DefaultDexServerAddr), "Dex server address")
 	command.Flags().BoolVar(&disableAuth, "disable-auth", env.ParseBoolFromEnv("ARGOCD_SERVER_DISABLE_AUTH", false), "Disable client authentication")
 	command.Flags().BoolVar(&enableGZip, "enable-gzip", env.ParseBoolFromEnv("ARGOCD_SERVER_ENABLE_GZIP", true), "Enable GZIP compression")
@@ -244,6 +252,10 @@ func NewCommand() *cobra.Command {
 	command.Flags().BoolVar(&dexServerStrictTLS, "dex-server-strict-tls", env.ParseBoolFromEnv("ARGOCD_SERVER_DEX_SERVER_STRICT_TLS", false), "Perform strict validation of TLS certificates when connecting to dex server")
 	command.Flags().StringSliceVar(&applicationNamespaces, "application-namespaces", env.StringsFromEnv("ARGOCD_APPLICATION_NAMESPACES", []string{}, ","), "List of additional namespaces where application resources can be managed in")
 	command.Flags().BoolVar(&enableProxyExtension, "enable-proxy-extension", env.ParseBoolFromEnv("ARGOCD_SERVER_ENABLE_PROXY_EXTENSION", false), "Enable Proxy Extension feature")
+	command.Flags().BoolVar(&webhookCacheWarming, "webhook-manifest-cache-warming", env.ParseBoolFromEnv("ARGOCD_SERVER_WEBHOOK_MANIFEST_CACHE_WARMING", false), "Generate the manifests of applications affected by a webhook push event for the new commit, so that the first refresh is served from the manifest cache")
+	command.Flags().BoolVar(&enableAgentTunnel, "enable-agent-tunnel", env.ParseBoolFromEnv("ARGOCD_SERVER_ENABLE_AGENT_TUNNEL", false), "Accept connections of agents running in remote clusters and proxy connections to those clusters through the agents")
+	command.Flags().StringVar(&agentProxyListenHost, "agent-proxy-address", env.StringFromEnv("ARGOCD_SERVER_AGENT_PROXY_LISTEN_ADDRESS", common.DefaultAddressAgentProxy), "Listen for connections to agent-managed clusters on given address")
+	command.Flags().IntVar(&agentProxyPort, "agent-proxy-port", common.DefaultPortAgentProxy, "Serve the HTTP proxy to agent-managed clusters on given port")
 	tlsConfigCustomizerSrc = tls.AddTLSFlagsToCmd(command)
 	cacheSrc = servercache.AddCacheFlagsToCmd(command, func(client *redis.Client) {
 		redisClient = client
@@ -108,6 +108,7 @@ func TestGetReconcileResults_Refresh(t *testing.T) {
 	liveStateCache.On("Init").Return(nil, nil)
 	liveStateCache.On("GetClusterCache", mock.Anything).Return(&clusterCache, nil)
 	liveStateCache.On("IsNamespaced", mock.Anything, mock.Anything).Return(true, nil)
+	liveStateCache.On("GetCluster", mock.Anything).Return(&v1alpha1.Cluster{Server: v1alpha1.KubernetesInternalAPIServerAddr}, nil)
 
 	result, err := reconcileApplications(ctx, kubeClientset, appClientset, "default", &repoServerClientset, "",
 		func(argoDB db.ArgoDB, appInformer cache.SharedIndexInformer, settingsMgr *settings.SettingsManager, server *metrics.MetricsServer) statecache.LiveStateCache {
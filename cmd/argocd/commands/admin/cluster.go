@@ -11,6 +11,9 @@ import (
 	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -31,6 +34,7 @@ import (
 	appstatecache "github.com/argoproj/argo-cd/v2/util/cache/appstate"
 	"github.com/argoproj/argo-cd/v2/util/cli"
 	"github.com/argoproj/argo-cd/v2/util/clusterauth"
+	"github.com/argoproj/argo-cd/v2/util/clusterdiscovery"
 	"github.com/argoproj/argo-cd/v2/util/db"
 	"github.com/argoproj/argo-cd/v2/util/errors"
 	"github.com/argoproj/argo-cd/v2/util/glob"
@@ -52,6 +56,9 @@ func NewClusterCommand(pathOpts *clientcmd.PathOptions) *cobra.Command {
 	command.AddCommand(NewGenClusterConfigCommand(pathOpts))
 	command.AddCommand(NewClusterStatsCommand())
 	command.AddCommand(NewClusterShardsCommand())
+	discoverCommand := NewClusterDiscoverCommand()
+	discoverCommand.AddCommand(NewClusterDiscoverEKSCommand())
+	command.AddCommand(discoverCommand)
 	namespacesCommand := NewClusterNamespacesCommand()
 	namespacesCommand.AddCommand(NewClusterEnableNamespacedMode())
 	namespacesCommand.AddCommand(NewClusterDisableNamespacedMode())
@@ -119,7 +126,9 @@ func loadClusters(ctx context.Context, kubeClient *kubernetes.Clientset, appClie
 			clusterShard := 0
 			cluster := batch[i]
 			if replicas > 0 {
-				distributionFunction := sharding.GetDistributionFunction(argoDB, common.DefaultShardingAlgorithm)
+				controllerReplicas := sharding.NewReplicas()
+				controllerReplicas.Set(replicas)
+				distributionFunction := sharding.GetDistributionFunction(argoDB, common.DefaultShardingAlgorithm, controllerReplicas)
 				distributionFunction(&cluster)
 				cluster.Shard = pointer.Int64Ptr(int64(clusterShard))
 				log.Infof("Cluster with uid: %s will be processed by shard %d", cluster.ID, clusterShard)
@@ -433,6 +442,81 @@ func NewClusterDisableNamespacedMode() *cobra.Command {
 	return &command
 }
 
+func NewClusterDiscoverCommand() *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "discover",
+		Short: "Create the cluster secrets of the clusters of a cloud provider, and delete the ones of clusters which don't exist anymore",
+		Long: `Create the cluster secrets of the clusters of a cloud provider, and delete the ones of clusters which don't exist anymore.
+
+The cluster secrets created by the discovery are labeled with argocd.argoproj.io/discovered-by, and annotated with the scope
+of the discovery, e.g. the region and the tags, in argocd.argoproj.io/discovery-scope. Only the cluster secrets of the same
+scope are deleted. Cluster secrets which weren't created by the discovery are never changed, and the ones of clusters which
+exist, but aren't available, are kept unchanged.
+Only EKS clusters are supported. The discovery doesn't run continuously, run it periodically, e.g. in a CronJob, to register
+ephemeral clusters.`,
+		Run: func(c *cobra.Command, args []string) {
+			c.HelpFunc()(c, args)
+		},
+	}
+	return command
+}
+
+func NewClusterDiscoverEKSCommand() *cobra.Command {
+	var (
+		clientConfig clientcmd.ClientConfig
+		region       string
+		tags         []string
+		roleARN      string
+		prune        bool
+		dryRun       bool
+	)
+	var command = cobra.Command{
+		Use:   "eks",
+		Short: "Discover the EKS clusters which have the given tags",
+		Example: `  # Register all EKS clusters of a region tagged with argocd=true and remove the ones which were deleted
+  argocd admin cluster discover eks --region eu-west-1 --tag argocd=true --prune`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			tagsMap, err := label.Parse(tags)
+			errors.CheckError(err)
+			sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+			errors.CheckError(err)
+			provider := clusterdiscovery.NewEKSProvider(eks.New(sess), aws.StringValue(sess.Config.Region), tagsMap, roleARN)
+
+			clientCfg, err := clientConfig.ClientConfig()
+			errors.CheckError(err)
+			namespace, _, err := clientConfig.Namespace()
+			errors.CheckError(err)
+			kubeClient := kubernetes.NewForConfigOrDie(clientCfg)
+			argoDB := db.NewDB(namespace, settings.NewSettingsManager(ctx, kubeClient, namespace), kubeClient)
+
+			result, err := clusterdiscovery.Reconcile(ctx, argoDB, provider, prune, dryRun)
+			errors.CheckError(err)
+			suffix := ""
+			if dryRun {
+				suffix = " (dry run)"
+			}
+			for _, server := range result.Created {
+				fmt.Printf("Cluster '%s' added%s\n", server, suffix)
+			}
+			for _, server := range result.Updated {
+				fmt.Printf("Cluster '%s' updated%s\n", server, suffix)
+			}
+			for _, server := range result.Deleted {
+				fmt.Printf("Cluster '%s' removed%s\n", server, suffix)
+			}
+		},
+	}
+	clientConfig = cli.AddKubectlFlagsToCmd(&command)
+	command.Flags().StringVar(&region, "region", os.Getenv("AWS_REGION"), "AWS region of the EKS clusters")
+	command.Flags().StringArrayVar(&tags, "tag", nil, "Only discover clusters with the given tag (e.g. --tag key=value)")
+	command.Flags().StringVar(&roleARN, "role-arn", "", "Optional AWS role arn. If set then AWS IAM Authenticator assumes a role to perform cluster operations instead of the default AWS credential provider chain.")
+	command.Flags().BoolVar(&prune, "prune", false, "Delete the cluster secrets of discovered clusters which don't exist anymore")
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "Print what will be performed")
+	return &command
+}
+
 func NewClusterStatsCommand() *cobra.Command {
 	var (
 		shard            int
@@ -506,7 +590,9 @@ func NewClusterConfig() *cobra.Command {
 
 			cluster, err := db.NewDB(namespace, settings.NewSettingsManager(ctx, kubeclientset, namespace), kubeclientset).GetCluster(ctx, serverUrl)
 			errors.CheckError(err)
-			err = kube.WriteKubeConfig(cluster.RawRestConfig(), namespace, output)
+			rawConfig, err := cluster.RawRestConfig()
+			errors.CheckError(err)
+			err = kube.WriteKubeConfig(rawConfig, namespace, output)
 			errors.CheckError(err)
 		},
 	}
@@ -599,6 +685,9 @@ func NewGenClusterConfigCommand(pathOpts *clientcmd.PathOptions) *cobra.Command
 			if clusterOpts.Shard >= 0 {
 				clst.Shard = &clusterOpts.Shard
 			}
+			clst.RepoServer = clusterOpts.RepoServer
+			errors.CheckError(clusterOpts.SetConnectionOptions(clst, conf))
+			clst.Server = clusterOpts.ServerURL(clst.Server)
 
 			settingsMgr := settings.NewSettingsManager(ctx, kubeClientset, ArgoCDNamespace)
 			argoDB := db.NewDB(ArgoCDNamespace, settingsMgr, kubeClientset)
@@ -1,17 +1,29 @@
 package admin
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	apiv1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
 
 	cmdutil "github.com/argoproj/argo-cd/v2/cmd/util"
 	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	appclientset "github.com/argoproj/argo-cd/v2/pkg/client/clientset/versioned"
 	"github.com/argoproj/argo-cd/v2/util/cli"
 	"github.com/argoproj/argo-cd/v2/util/db"
 	"github.com/argoproj/argo-cd/v2/util/errors"
@@ -33,6 +45,7 @@ func NewRepoCommand() *cobra.Command {
 		},
 	}
 	command.AddCommand(NewGenRepoSpecCommand())
+	command.AddCommand(NewRepoGCCommand())
 
 	return command
 }
@@ -126,6 +139,9 @@ func NewGenRepoSpecCommand() *cobra.Command {
 			repoOpts.Repo.Insecure = repoOpts.InsecureSkipServerVerification
 			repoOpts.Repo.EnableLFS = repoOpts.EnableLfs
 			repoOpts.Repo.EnableOCI = repoOpts.EnableOci
+			if c.Flags().Changed("enable-submodules") {
+				repoOpts.Repo.EnableSubmodules = &repoOpts.EnableSubmodules
+			}
 
 			if repoOpts.Repo.Type == "helm" && repoOpts.Repo.Name == "" {
 				errors.CheckError(fmt.Errorf("must specify --name for repos of type 'helm'"))
@@ -167,3 +183,416 @@ func NewGenRepoSpecCommand() *cobra.Command {
 	cmdutil.AddRepoFlags(command, &repoOpts)
 	return command
 }
+
+// defaultKnownHostsServers are the servers whose SSH known hosts entries are part of the default installation, see
+// hack/update-ssh-known-hosts.sh. They are never reported as unused.
+var defaultKnownHostsServers = map[string]bool{
+	"github.com":              true,
+	"[ssh.github.com]:443":    true,
+	"gitlab.com":              true,
+	"bitbucket.org":           true,
+	"ssh.dev.azure.com":       true,
+	"vs-ssh.visualstudio.com": true,
+}
+
+// unusedRepoConfig is a credential template or a certificate which is not used by any repository
+type unusedRepoConfig struct {
+	// Type is either "repo-creds", "https" or "ssh"
+	Type string
+	// Name is the URL of the credential template or the server name of the certificate
+	Name string
+	// SubType is the key type of SSH known hosts entries
+	SubType string
+	// SecretName is the name of the secret of a credential template
+	SecretName string
+	// UnusedSince is the time the credential template or certificate was first found unused
+	UnusedSince time.Time
+}
+
+// repoUsage contains the repositories and hosts which are used by Argo CD
+type repoUsage struct {
+	repoURLs map[string]bool
+	// hosts are the "<cert type>/<host name>" keys of the hosts which are used
+	hosts map[string]bool
+	// helm is set if any application has a Helm source. The dependencies of Helm charts can use any Helm credential
+	// template, but are only known when the charts are rendered.
+	helm bool
+}
+
+func (u *repoUsage) addRepo(repoURL string) {
+	// the repositories of templates which are only known once they are rendered are ignored
+	if repoURL == "" || strings.Contains(repoURL, "{{") {
+		return
+	}
+	u.repoURLs[repoURL] = true
+	host, isSSH := git.RepoHostName(repoURL)
+	u.hosts[certTypeOf(isSSH)+"/"+host] = true
+}
+
+func (u *repoUsage) addAPI(apiURL string) {
+	if apiURL == "" || strings.Contains(apiURL, "{{") {
+		return
+	}
+	host, _ := git.RepoHostName(apiURL)
+	u.hosts["https/"+host] = true
+}
+
+func (u *repoUsage) addSpec(spec *v1alpha1.ApplicationSpec) {
+	for _, source := range spec.GetSources() {
+		u.addRepo(source.RepoURL)
+		if source.IsHelm() {
+			u.helm = true
+		}
+	}
+}
+
+// addGenerator adds the repositories of the Git generators, the API hosts of the SCM provider and pull request
+// generators, and the repositories of the templates of the given generator and of the generators nested in it
+func (u *repoUsage) addGenerator(g *v1alpha1.ApplicationSetNestedGenerator) error {
+	if g.Git != nil {
+		u.addRepo(g.Git.RepoURL)
+		u.addSpec(&g.Git.Template.Spec)
+	}
+	if scm := g.SCMProvider; scm != nil {
+		if scm.Github != nil {
+			u.addAPI(scm.Github.API)
+		}
+		if scm.Gitlab != nil {
+			u.addAPI(scm.Gitlab.API)
+		}
+		if scm.Gitea != nil {
+			u.addAPI(scm.Gitea.API)
+		}
+		if scm.BitbucketServer != nil {
+			u.addAPI(scm.BitbucketServer.API)
+		}
+		if scm.AzureDevOps != nil {
+			u.addAPI(scm.AzureDevOps.API)
+		}
+		u.addSpec(&scm.Template.Spec)
+	}
+	if pr := g.PullRequest; pr != nil {
+		if pr.Github != nil {
+			u.addAPI(pr.Github.API)
+		}
+		if pr.GitLab != nil {
+			u.addAPI(pr.GitLab.API)
+		}
+		if pr.Gitea != nil {
+			u.addAPI(pr.Gitea.API)
+		}
+		if pr.BitbucketServer != nil {
+			u.addAPI(pr.BitbucketServer.API)
+		}
+		if pr.Bitbucket != nil {
+			u.addAPI(pr.Bitbucket.API)
+		}
+		u.addSpec(&pr.Template.Spec)
+	}
+	if g.List != nil {
+		u.addSpec(&g.List.Template.Spec)
+	}
+	if g.Clusters != nil {
+		u.addSpec(&g.Clusters.Template.Spec)
+	}
+	if g.ClusterDecisionResource != nil {
+		u.addSpec(&g.ClusterDecisionResource.Template.Spec)
+	}
+	if g.Plugin != nil {
+		u.addSpec(&g.Plugin.Template.Spec)
+	}
+	matrix, err := v1alpha1.ToNestedMatrixGenerator(g.Matrix)
+	if err != nil {
+		return err
+	}
+	merge, err := v1alpha1.ToNestedMergeGenerator(g.Merge)
+	if err != nil {
+		return err
+	}
+	var nested []v1alpha1.ApplicationSetNestedGenerator
+	if matrix != nil {
+		nested = append(nested, matrix.ToMatrixGenerator().Generators...)
+	}
+	if merge != nil {
+		nested = append(nested, merge.ToMergeGenerator().Generators...)
+	}
+	for i := range nested {
+		if err := u.addGenerator(&nested[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewRepoGCCommand() *cobra.Command {
+	var (
+		clientConfig clientcmd.ClientConfig
+		minAge       time.Duration
+		prune        bool
+	)
+	var command = &cobra.Command{
+		Use:   "gc",
+		Short: "Report or prune credential templates and certificates which are not used by any repository",
+		Long: `Report or prune credential templates and certificates which are not used by any repository.
+
+A credential template is used if it is the best matching template of a registered repository, of a source of an
+application or of an ApplicationSet template, or of the repository of an ApplicationSet Git generator. Helm credential
+templates are used by the dependencies of Helm charts, so they are considered used as long as any application has a
+Helm source. TLS certificates and SSH known hosts entries are used if their server name matches the host of such a
+repository, or the API of an ApplicationSet SCM provider or pull request generator. The SSH known hosts entries of the
+default installation are never reported.
+
+Every run records when a credential template or certificate was first found unused, in the
+argocd.argoproj.io/unused-since annotation of its Secret or ConfigMap. Credential templates and certificates are only
+reported once they were unused for at least --min-age. Run the command periodically to detect unused credential
+templates and certificates.`,
+		Example: `  # Report credential templates and certificates which are unused for more than 30 days
+  argocd admin repo gc
+
+  # Prune credential templates and certificates which are unused for more than 90 days
+  argocd admin repo gc --min-age 2160h --prune
+`,
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			config, err := clientConfig.ClientConfig()
+			errors.CheckError(err)
+			namespace, _, err := clientConfig.Namespace()
+			errors.CheckError(err)
+			kubeClient := kubernetes.NewForConfigOrDie(config)
+			appClient := appclientset.NewForConfigOrDie(config)
+			argoDB := db.NewDB(namespace, settings.NewSettingsManager(ctx, kubeClient, namespace), kubeClient)
+
+			unused, err := findUnusedRepoConfig(ctx, kubeClient, appClient, argoDB, namespace, minAge, time.Now())
+			errors.CheckError(err)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "TYPE\tNAME\tSUBTYPE\tUNUSED SINCE\n")
+			for _, item := range unused {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Type, item.Name, item.SubType, item.UnusedSince.Format(time.RFC3339))
+			}
+			_ = w.Flush()
+
+			if prune {
+				errors.CheckError(pruneUnusedRepoConfig(ctx, kubeClient, argoDB, namespace, unused))
+				fmt.Printf("Pruned %d credential templates and certificates\n", len(unused))
+			}
+		},
+	}
+	command.Flags().DurationVar(&minAge, "min-age", 30*24*time.Hour, "Minimum time credential templates and certificates must be unused for to be reported")
+	command.Flags().BoolVar(&prune, "prune", false, "Delete the reported credential templates and certificates")
+	clientConfig = cli.AddKubectlFlagsToCmd(command)
+	return command
+}
+
+// findRepoUsage returns the repositories and hosts used by the registered repositories, the applications and the
+// ApplicationSets
+func findRepoUsage(ctx context.Context, appClient appclientset.Interface, argoDB db.ArgoDB) (*repoUsage, error) {
+	usage := &repoUsage{repoURLs: map[string]bool{}, hosts: map[string]bool{}}
+	repos, err := argoDB.ListRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories: %w", err)
+	}
+	for _, repo := range repos {
+		usage.addRepo(repo.Repo)
+	}
+	apps, err := appClient.ArgoprojV1alpha1().Applications("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing applications: %w", err)
+	}
+	for i := range apps.Items {
+		usage.addSpec(&apps.Items[i].Spec)
+	}
+	appSets, err := appClient.ArgoprojV1alpha1().ApplicationSets("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing application sets: %w", err)
+	}
+	for _, appSet := range appSets.Items {
+		usage.addSpec(&appSet.Spec.Template.Spec)
+		for _, g := range appSet.Spec.Generators {
+			nested := v1alpha1.ApplicationSetNestedGenerator{
+				List:                    g.List,
+				Clusters:                g.Clusters,
+				Git:                     g.Git,
+				SCMProvider:             g.SCMProvider,
+				ClusterDecisionResource: g.ClusterDecisionResource,
+				PullRequest:             g.PullRequest,
+				Plugin:                  g.Plugin,
+			}
+			if err := usage.addGenerator(&nested); err != nil {
+				return nil, fmt.Errorf("error reading generators of application set %s: %w", appSet.Name, err)
+			}
+			var combined []v1alpha1.ApplicationSetNestedGenerator
+			if g.Matrix != nil {
+				combined = append(combined, g.Matrix.Generators...)
+				usage.addSpec(&g.Matrix.Template.Spec)
+			}
+			if g.Merge != nil {
+				combined = append(combined, g.Merge.Generators...)
+				usage.addSpec(&g.Merge.Template.Spec)
+			}
+			for i := range combined {
+				if err := usage.addGenerator(&combined[i]); err != nil {
+					return nil, fmt.Errorf("error reading generators of application set %s: %w", appSet.Name, err)
+				}
+			}
+		}
+	}
+	return usage, nil
+}
+
+// unusedSince returns the time recorded in the unused-since annotation of the object, and whether the annotation is set
+func unusedSince(annotations map[string]string) (time.Time, bool) {
+	since, err := time.Parse(time.RFC3339, annotations[common.AnnotationKeyUnusedSince])
+	return since, err == nil
+}
+
+// findUnusedRepoConfig returns the credential templates and certificates which are not used by any registered
+// repository, application or ApplicationSet for at least minAge, and records the time at which credential templates
+// and certificates were first found unused
+func findUnusedRepoConfig(ctx context.Context, kubeClient kubernetes.Interface, appClient appclientset.Interface, argoDB db.ArgoDB, namespace string, minAge time.Duration, now time.Time) ([]unusedRepoConfig, error) {
+	usage, err := findRepoUsage(ctx, appClient, argoDB)
+	if err != nil {
+		return nil, err
+	}
+	// the best matching credential template of a repository is looked up like the repo server does
+	usedCreds := map[string]bool{}
+	for repoURL := range usage.repoURLs {
+		creds, err := argoDB.GetRepositoryCredentials(ctx, repoURL)
+		if err != nil {
+			return nil, fmt.Errorf("error getting credentials of repository %s: %w", repoURL, err)
+		}
+		if creds != nil {
+			usedCreds[creds.URL] = true
+		}
+	}
+
+	credsSecrets, err := kubeClient.CoreV1().Secrets(namespace).List(ctx, v1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", common.LabelKeySecretType, common.LabelValueSecretTypeRepoCreds),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing credential templates: %w", err)
+	}
+	var unused []unusedRepoConfig
+	for i := range credsSecrets.Items {
+		secret := &credsSecrets.Items[i]
+		used := usedCreds[string(secret.Data["url"])] || (usage.helm && string(secret.Data["type"]) == "helm")
+		since, tracked := unusedSince(secret.Annotations)
+		if used == tracked {
+			// record that the credential template was found unused, or that it is used again
+			if used {
+				delete(secret.Annotations, common.AnnotationKeyUnusedSince)
+			} else {
+				since = now
+				if secret.Annotations == nil {
+					secret.Annotations = map[string]string{}
+				}
+				secret.Annotations[common.AnnotationKeyUnusedSince] = now.Format(time.RFC3339)
+			}
+			if _, err := kubeClient.CoreV1().Secrets(namespace).Update(ctx, secret, v1.UpdateOptions{}); err != nil {
+				return nil, fmt.Errorf("error updating credential template %s: %w", secret.Name, err)
+			}
+		}
+		if used {
+			continue
+		}
+		if now.Sub(since) < minAge {
+			continue
+		}
+		unused = append(unused, unusedRepoConfig{
+			Type:        common.LabelValueSecretTypeRepoCreds,
+			Name:        string(secret.Data["url"]),
+			SecretName:  secret.Name,
+			UnusedSince: since,
+		})
+	}
+
+	certs, err := argoDB.ListRepoCertificates(ctx, &db.CertificateListSelector{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing certificates: %w", err)
+	}
+	// certificates don't record when they were added, so the times they were first found unused are recorded in the
+	// annotations of their ConfigMaps, keyed by "<cert type>/<server name>/<sub type>"
+	unusedCerts := map[string]map[string]string{
+		common.ArgoCDTLSCertsConfigMapName:   {},
+		common.ArgoCDKnownHostsConfigMapName: {},
+	}
+	for _, cert := range certs.Items {
+		subType := ""
+		configMapName := common.ArgoCDTLSCertsConfigMapName
+		if cert.CertType == "ssh" {
+			subType = cert.CertSubType
+			configMapName = common.ArgoCDKnownHostsConfigMapName
+		}
+		if usage.hosts[cert.CertType+"/"+cert.ServerName] || (cert.CertType == "ssh" && defaultKnownHostsServers[cert.ServerName]) {
+			continue
+		}
+		// all TLS certificates of a server are reported and pruned together
+		unusedCerts[configMapName][cert.CertType+"/"+cert.ServerName+"/"+subType] = ""
+	}
+	for configMapName, keys := range unusedCerts {
+		cm, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, v1.GetOptions{})
+		if apierr.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting config map %s: %w", configMapName, err)
+		}
+		tracked := map[string]string{}
+		if data, ok := cm.Annotations[common.AnnotationKeyUnusedSince]; ok {
+			// entries which can't be read are recorded again
+			_ = json.Unmarshal([]byte(data), &tracked)
+		}
+		for key := range keys {
+			keys[key] = tracked[key]
+			if _, err := time.Parse(time.RFC3339, keys[key]); err != nil {
+				keys[key] = now.Format(time.RFC3339)
+			}
+		}
+		if !reflect.DeepEqual(tracked, keys) {
+			data, err := json.Marshal(keys)
+			if err != nil {
+				return nil, err
+			}
+			if cm.Annotations == nil {
+				cm.Annotations = map[string]string{}
+			}
+			cm.Annotations[common.AnnotationKeyUnusedSince] = string(data)
+			if _, err := kubeClient.CoreV1().ConfigMaps(namespace).Update(ctx, cm, v1.UpdateOptions{}); err != nil {
+				return nil, fmt.Errorf("error updating config map %s: %w", configMapName, err)
+			}
+		}
+		for key, value := range keys {
+			since, _ := time.Parse(time.RFC3339, value)
+			if now.Sub(since) < minAge {
+				continue
+			}
+			parts := strings.SplitN(key, "/", 3)
+			unused = append(unused, unusedRepoConfig{Type: parts[0], Name: parts[1], SubType: parts[2], UnusedSince: since})
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].Type+unused[i].Name+unused[i].SubType < unused[j].Type+unused[j].Name+unused[j].SubType
+	})
+	return unused, nil
+}
+
+func certTypeOf(isSSH bool) string {
+	if isSSH {
+		return "ssh"
+	}
+	return "https"
+}
+func pruneUnusedRepoConfig(ctx context.Context, kubeClient kubernetes.Interface, argoDB db.ArgoDB, namespace string, unused []unusedRepoConfig) error {
+	for _, config := range unused {
+		var err error
+		if config.SecretName != "" {
+			err = kubeClient.CoreV1().Secrets(namespace).Delete(ctx, config.SecretName, v1.DeleteOptions{})
+		} else {
+			_, err = argoDB.RemoveRepoCertificates(ctx, &db.CertificateListSelector{HostNamePattern: config.Name, CertType: config.Type, CertSubType: config.SubType})
+		}
+		if err != nil {
+			return fmt.Errorf("error pruning %s %s: %w", config.Type, config.Name, err)
+		}
+	}
+	return nil
+}
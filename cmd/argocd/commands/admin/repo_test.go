@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/pkg/client/clientset/versioned/fake"
+	"github.com/argoproj/argo-cd/v2/util/db"
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+const testKnownHosts = `github.com ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABgQCj7ndNxQowgcQnjshcLrqPEiiphnt+VTTvDP6mHBL9j1aNUkY4Ue1gvwnGLVlOhGeYrnZaMgRK6+PKCUXaDbC7qtbW8gIkhL7aGCsOr/C56SJMy/BCZfxd1nWzAOxSDPgVsmerOBYfNqltV9/hWCqBywINIR+5dIg6JTJ72pcEpEjcYgXkE2YEFXV1JHnsKgbLWNlhScqb2UmyRkQyytRLtL+38TGxkxCflmO+5Z8CSSNY7GidjMIZ7Q4zMjA2n1nGrlTDkzwDCsw+wqFPGQA179cnfGWOWRVruj16z6XyvxvjJwbz0wQZ75XK5tKSb7FNyeIEs4TT4jk+S4dhPeAUC5y+bDYirYgM4GC7uEnztnZyaVWQ7B381AK4Qdrwt51ZqExKbQpTUNn+EjqoTwvqNj4kqx5QUCI0ThS/YkOxJCXmPUWZbhjpCg56i+2aB6CmK2JGhn57K5mj0MNdBXA4/WnwH6XoPWJzK5Nyu2zB3nAZp+S5hpQs+p1vN1/wsjk=
+gitlab.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAfuCHKVTjquxvt6CM6tdG4SLp1Btn/nOeHHE5UOzRdf
+git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAfuCHKVTjquxvt6CM6tdG4SLp1Btn/nOeHHE5UOzRdf
+scm.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAfuCHKVTjquxvt6CM6tdG4SLp1Btn/nOeHHE5UOzRdf
+`
+
+func newCredsSecret(name string, url string, created time.Time) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            map[string]string{common.LabelKeySecretType: common.LabelValueSecretTypeRepoCreds},
+			CreationTimestamp: v1.NewTime(created),
+		},
+		Data: map[string][]byte{"url": []byte(url)},
+	}
+}
+
+func newHelmCredsSecret(name string, url string, created time.Time) *corev1.Secret {
+	secret := newCredsSecret(name, url, created)
+	secret.Data["type"] = []byte("helm")
+	return secret
+}
+
+func TestFindUnusedRepoConfig(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	old := now.Add(-60 * 24 * time.Hour)
+
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: common.ArgoCDConfigMapName, Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/part-of": "argocd"}},
+		},
+		&corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: common.ArgoCDSecretName, Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/part-of": "argocd"}},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: common.ArgoCDKnownHostsConfigMapName, Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/part-of": "argocd"}},
+			Data:       map[string]string{"ssh_known_hosts": testKnownHosts},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: common.ArgoCDTLSCertsConfigMapName, Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/part-of": "argocd"}},
+		},
+		newCredsSecret("used-by-app", "https://github.com/argoproj", old),
+		newCredsSecret("less-specific", "https://github.com", old),
+		newCredsSecret("used-by-app-set", "ssh://git@scm.example.com", old),
+		newHelmCredsSecret("used-by-helm-dependencies", "https://charts.example.com", old),
+		newCredsSecret("unused", "https://gitlab.example.com", old),
+		newCredsSecret("unused-path", "https://gitlab.example.com/new", now.Add(-time.Hour)),
+	)
+	appClient := fake.NewSimpleClientset(
+		&v1alpha1.Application{
+			ObjectMeta: v1.ObjectMeta{Name: "guestbook", Namespace: namespace},
+			Spec: v1alpha1.ApplicationSpec{
+				Source: &v1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps"},
+			},
+		},
+		&v1alpha1.Application{
+			ObjectMeta: v1.ObjectMeta{Name: "chart", Namespace: namespace},
+			Spec: v1alpha1.ApplicationSpec{
+				Source: &v1alpha1.ApplicationSource{RepoURL: "https://charts.bitnami.com/bitnami", Chart: "redis"},
+			},
+		},
+		&v1alpha1.ApplicationSet{
+			ObjectMeta: v1.ObjectMeta{Name: "apps", Namespace: namespace},
+			Spec: v1alpha1.ApplicationSetSpec{
+				Generators: []v1alpha1.ApplicationSetGenerator{{
+					Matrix: &v1alpha1.MatrixGenerator{Generators: []v1alpha1.ApplicationSetNestedGenerator{
+						{Git: &v1alpha1.GitGenerator{RepoURL: "ssh://git@scm.example.com/apps.git"}},
+						{List: &v1alpha1.ListGenerator{}},
+					}},
+				}},
+				Template: v1alpha1.ApplicationSetTemplate{Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{RepoURL: "{{url}}"},
+				}},
+			},
+		},
+	)
+	argoDB := db.NewDB(namespace, settings.NewSettingsManager(ctx, kubeClient, namespace), kubeClient)
+	_, err := argoDB.CreateRepository(ctx, &v1alpha1.Repository{Repo: "git@github.com:argoproj/argo-cd.git"})
+	require.NoError(t, err)
+
+	names := func(unused []unusedRepoConfig) []string {
+		var names []string
+		for _, config := range unused {
+			names = append(names, config.Type+" "+config.Name)
+		}
+		return names
+	}
+
+	// the first run only records when the credential templates and certificates were found unused
+	unused, err := findUnusedRepoConfig(ctx, kubeClient, appClient, argoDB, namespace, 30*24*time.Hour, now)
+	require.NoError(t, err)
+	assert.Empty(t, unused)
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, "unused", v1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, now.Format(time.RFC3339), secret.Annotations[common.AnnotationKeyUnusedSince])
+
+	later := now.Add(31 * 24 * time.Hour)
+	unused, err = findUnusedRepoConfig(ctx, kubeClient, appClient, argoDB, namespace, 30*24*time.Hour, later)
+	require.NoError(t, err)
+	// the SSH known hosts entries of the default installation are never reported
+	assert.ElementsMatch(t, []string{
+		"repo-creds https://github.com",
+		"repo-creds https://gitlab.example.com",
+		"repo-creds https://gitlab.example.com/new",
+		"ssh git.example.com",
+	}, names(unused))
+
+	require.NoError(t, pruneUnusedRepoConfig(ctx, kubeClient, argoDB, namespace, unused))
+	unused, err = findUnusedRepoConfig(ctx, kubeClient, appClient, argoDB, namespace, 30*24*time.Hour, later)
+	require.NoError(t, err)
+	assert.Empty(t, unused)
+}
@@ -22,6 +22,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/yaml"
 
+	cmdutil "github.com/argoproj/argo-cd/v2/cmd/util"
 	"github.com/argoproj/argo-cd/v2/common"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v2/util/argo/normalizers"
@@ -272,6 +273,14 @@ var validatorsByGroup = map[string]settingValidator{
 		if err != nil {
 			return "", err
 		}
+		for key, override := range overrides {
+			if override.HealthLua == "" {
+				continue
+			}
+			if err := lua.ValidateScript(override.HealthLua); err != nil {
+				return "", fmt.Errorf("invalid health.lua for %s: %w", key, err)
+			}
+		}
 		return fmt.Sprintf("%d resource overrides", len(overrides)), nil
 	},
 }
@@ -558,10 +567,7 @@ argocd admin settings resource-overrides action list /tmp/deploy.yaml --argocd-c
 				}
 
 				luaVM := lua.VM{ResourceOverrides: overrides}
-				discoveryScript, err := luaVM.GetResourceActionDiscovery(&res)
-				errors.CheckError(err)
-
-				availableActions, err := luaVM.ExecuteResourceActionDiscovery(&res, discoveryScript)
+				availableActions, err := luaVM.GetAvailableResourceActions(&res)
 				errors.CheckError(err)
 				sort.Slice(availableActions, func(i, j int) bool {
 					return availableActions[i].Name < availableActions[j].Name
@@ -580,6 +586,7 @@ argocd admin settings resource-overrides action list /tmp/deploy.yaml --argocd-c
 }
 
 func NewResourceActionRunCommand(cmdCtx commandContext) *cobra.Command {
+	var params []string
 	var command = &cobra.Command{
 		Use:     "run-action RESOURCE_YAML_PATH ACTION",
 		Aliases: []string{"action"},
@@ -607,7 +614,12 @@ argocd admin settings resource-overrides action run /tmp/deploy.yaml restart --a
 				action, err := luaVM.GetResourceAction(&res, action)
 				errors.CheckError(err)
 
-				modifiedRes, err := luaVM.ExecuteResourceAction(&res, action.ActionLua)
+				actionParams, err := cmdutil.ParseResourceActionParams(params)
+				errors.CheckError(err)
+				resolvedParams, err := lua.ResolveResourceActionParams(action.Params, actionParams)
+				errors.CheckError(err)
+
+				modifiedRes, err := luaVM.ExecuteResourceAction(&res, action.ActionLua, resolvedParams)
 				errors.CheckError(err)
 
 				for _, impactedResource := range modifiedRes {
@@ -633,5 +645,6 @@ argocd admin settings resource-overrides action run /tmp/deploy.yaml restart --a
 			})
 		},
 	}
+	command.Flags().StringArrayVar(&params, "param", []string{}, "Parameter of the action in the form name=value (can be repeated multiple times)")
 	return command
 }
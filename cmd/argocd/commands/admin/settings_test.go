@@ -193,6 +193,13 @@ admissionregistration.k8s.io/MutatingWebhookConfiguration:
 			},
 			containsSummary: "2 resource overrides",
 		},
+		"ResourceOverrides_InvalidHealthLua": {
+			validator: "resource-overrides",
+			data: map[string]string{
+				"resource.customizations.health.cert-manager.io_Certificate": `hs = {`,
+			},
+			containsError: "invalid health.lua for cert-manager.io/Certificate",
+		},
 	}
 	for name := range testCases {
 		tc := testCases[name]
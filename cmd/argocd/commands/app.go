@@ -26,6 +26,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
@@ -102,6 +103,10 @@ type watchOpts struct {
 	degraded  bool
 }
 
+func (w watchOpts) waitOptions() argo.WaitOptions {
+	return argo.WaitOptions{Sync: w.sync, Health: w.health, Operation: w.operation, Suspended: w.suspended, Degraded: w.degraded}
+}
+
 // NewApplicationCreateCommand returns a new instance of an `argocd app create` command
 func NewApplicationCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 	var (
@@ -113,6 +118,8 @@ func NewApplicationCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
 		annotations  []string
 		setFinalizer bool
 		appNamespace string
+		serverSide   bool
+		fieldManager string
 	)
 	var command = &cobra.Command{
 		Use:   "create APPNAME",
@@ -133,7 +140,10 @@ func NewApplicationCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
   argocd app create kustomize-guestbook --repo https://github.com/argoproj/argocd-example-apps.git --path kustomize-guestbook --dest-namespace default --dest-server https://kubernetes.default.svc --kustomize-image gcr.io/heptio-images/ks-guestbook-demo:0.1
 
   # Create a app using a custom tool:
-  argocd app create kasane --repo https://github.com/argoproj/argocd-example-apps.git --path plugins/kasane --dest-namespace default --dest-server https://kubernetes.default.svc --config-management-plugin kasane`,
+  argocd app create kasane --repo https://github.com/argoproj/argocd-example-apps.git --path plugins/kasane --dest-namespace default --dest-server https://kubernetes.default.svc --config-management-plugin kasane
+
+  # Create or update apps from a file using server-side apply, sharing ownership of the spec with other writers
+  argocd app create -f apps.yaml --server-side --field-manager my-team`,
 		Run: func(c *cobra.Command, args []string) {
 			ctx := c.Context()
 
@@ -156,9 +166,11 @@ func NewApplicationCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
 				conn, appIf := argocdClient.NewApplicationClientOrDie()
 				defer argoio.Close(conn)
 				appCreateRequest := application.ApplicationCreateRequest{
-					Application: app,
-					Upsert:      &upsert,
-					Validate:    &appOpts.Validate,
+					Application:     app,
+					Upsert:          &upsert,
+					Validate:        &appOpts.Validate,
+					ServerSideApply: &serverSide,
+					FieldManager:    &fieldManager,
 				}
 
 				// Get app before creating to see if it is being updated or no change
@@ -175,7 +187,7 @@ func NewApplicationCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
 				var action string
 				if existing == nil {
 					action = "created"
-				} else if !hasAppChanged(existing, created, upsert) {
+				} else if !hasAppChanged(existing, created, upsert || serverSide) {
 					action = "unchanged"
 				} else {
 					action = "updated"
@@ -197,6 +209,8 @@ func NewApplicationCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
 		log.Fatal(err)
 	}
 	command.Flags().StringVarP(&appNamespace, "app-namespace", "N", "", "Namespace where the application will be created in")
+	command.Flags().BoolVar(&serverSide, "server-side", false, "Use server-side apply to create or update the app, only taking ownership of the fields set in the supplied spec. Combine with --upsert to take over fields owned by other field managers")
+	command.Flags().StringVar(&fieldManager, "field-manager", "", "Name of the field manager used with --server-side (default \"argocd-server\")")
 	cmdutil.AddAppFlags(command, &appOpts)
 	return command
 }
@@ -228,6 +242,42 @@ func getRefreshType(refresh bool, hardRefresh bool) *string {
 	return nil
 }
 
+// validatePrunePropagationPolicy returns an error if the given prune propagation policy is not supported
+func validatePrunePropagationPolicy(prunePropagationPolicy string) error {
+	switch prunePropagationPolicy {
+	case "", "foreground", "background", "orphan":
+		return nil
+	default:
+		return fmt.Errorf("unknown prune propagation policy: '%s' (one of: foreground|background|orphan)", prunePropagationPolicy)
+	}
+}
+
+// getSyncOptions returns the sync options of the sync request matching the given flags, or nil if none is set
+func getSyncOptions(replace, serverSideApply, applyOutOfSyncOnly, pruneLast bool, prunePropagationPolicy string) *application.SyncOptions {
+	items := make([]string, 0)
+	if replace {
+		items = append(items, common.SyncOptionReplace)
+	}
+	if serverSideApply {
+		items = append(items, common.SyncOptionServerSideApply)
+	}
+	if applyOutOfSyncOnly {
+		items = append(items, "ApplyOutOfSyncOnly=true")
+	}
+	if pruneLast {
+		items = append(items, common.SyncOptionPruneLast)
+	}
+	if prunePropagationPolicy != "" {
+		items = append(items, fmt.Sprintf("PrunePropagationPolicy=%s", prunePropagationPolicy))
+	}
+
+	if len(items) == 0 {
+		// for prevent send even empty array if not need
+		return nil
+	}
+	return &application.SyncOptions{Items: items}
+}
+
 func hasAppChanged(appReq, appRes *argoappv1.Application, upsert bool) bool {
 	// upsert==false, no change occurred from create command
 	if !upsert {
@@ -303,7 +353,7 @@ func NewApplicationGetCommand(clientOpts *argocdclient.ClientOptions) *cobra.Com
 				errors.CheckError(err)
 			case "wide", "":
 				aURL := appURL(ctx, acdClient, app.Name)
-				printAppSummaryTable(app, aURL, windows)
+				printAppSummaryTable(app, aURL, windows, proj)
 
 				if len(app.Status.Conditions) > 0 {
 					fmt.Println()
@@ -348,6 +398,7 @@ func NewApplicationLogsCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 		follow       bool
 		tail         int64
 		sinceSeconds int64
+		sinceTime    string
 		untilTime    string
 		filter       string
 		container    string
@@ -368,6 +419,13 @@ func NewApplicationLogsCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 			defer argoio.Close(conn)
 			appName, appNs := argo.ParseAppQualifiedName(args[0], "")
 
+			var since *metav1.Time
+			if sinceTime != "" {
+				t, err := time.Parse(time.RFC3339, sinceTime)
+				errors.CheckError(err)
+				since = &metav1.Time{Time: t}
+			}
+
 			retry := true
 			for retry {
 				retry = false
@@ -380,6 +438,7 @@ func NewApplicationLogsCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 					Follow:       pointer.Bool(follow),
 					TailLines:    pointer.Int64(tail),
 					SinceSeconds: pointer.Int64(sinceSeconds),
+					SinceTime:    since,
 					UntilTime:    &untilTime,
 					Filter:       &filter,
 					Container:    pointer.String(container),
@@ -402,6 +461,7 @@ func NewApplicationLogsCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 						if st.Code() == codes.Unavailable && follow {
 							retry = true
 							sinceSeconds = 1
+							since = nil
 							break
 						}
 						log.Fatalf("stream read failed: %v", err)
@@ -423,15 +483,18 @@ func NewApplicationLogsCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 	command.Flags().BoolVar(&follow, "follow", false, "Specify if the logs should be streamed")
 	command.Flags().Int64Var(&tail, "tail", 0, "The number of lines from the end of the logs to show")
 	command.Flags().Int64Var(&sinceSeconds, "since-seconds", 0, "A relative time in seconds before the current time from which to show logs")
+	command.Flags().StringVar(&sinceTime, "since-time", "", "Show logs since this time (RFC3339)")
 	command.Flags().StringVar(&untilTime, "until-time", "", "Show logs until this time")
 	command.Flags().StringVar(&filter, "filter", "", "Show logs contain this string")
-	command.Flags().StringVar(&container, "container", "", "Optional container name")
+	command.Flags().StringVar(&container, "container", "", "Optional container name, or a glob pattern (e.g. '*') matching the names of the containers")
 	command.Flags().BoolVarP(&previous, "previous", "p", false, "Specify if the previously terminated container logs should be returned")
 
 	return command
 }
 
-func printAppSummaryTable(app *argoappv1.Application, appURL string, windows *argoappv1.SyncWindows) {
+// printAppSummaryTable prints the summary of the application. The sync policy is the effective one, i.e. the default sync
+// policy of the project if the application doesn't set a sync policy and the project is given.
+func printAppSummaryTable(app *argoappv1.Application, appURL string, windows *argoappv1.SyncWindows, proj *argoappv1.AppProject) {
 	source := app.Spec.GetSource()
 	fmt.Printf(printOpFmtStr, "Name:", app.QualifiedName())
 	fmt.Printf(printOpFmtStr, "Project:", app.Spec.GetProject())
@@ -483,14 +546,17 @@ func printAppSummaryTable(app *argoappv1.Application, appURL string, windows *ar
 	}
 
 	var syncPolicy string
-	if app.Spec.SyncPolicy != nil && app.Spec.SyncPolicy.Automated != nil {
+	if effective := proj.GetSyncPolicy(app); effective != nil && effective.Automated != nil {
 		syncPolicy = "Automated"
-		if app.Spec.SyncPolicy.Automated.Prune {
+		if effective.Automated.Prune {
 			syncPolicy += " (Prune)"
 		}
 	} else {
 		syncPolicy = "<none>"
 	}
+	if app.Spec.SyncPolicy == nil && proj != nil && proj.Spec.DefaultSyncPolicy != nil {
+		syncPolicy += " (project default)"
+	}
 	fmt.Printf(printOpFmtStr, "Sync Policy:", syncPolicy)
 	syncStatusStr := string(app.Status.Sync.Status)
 	switch app.Status.Sync.Status {
@@ -1241,8 +1307,9 @@ func printApplicationNames(apps []argoappv1.Application) {
 	}
 }
 
-// Print table of application data
-func printApplicationTable(apps []argoappv1.Application, output *string) {
+// Print table of application data. The sync policies are the effective ones, i.e. the default sync policy of the
+// project of an application which doesn't set a sync policy, if the project is in the given projects.
+func printApplicationTable(apps []argoappv1.Application, output *string, projects map[string]*argoappv1.AppProject) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	var fmtStr string
 	headers := []interface{}{"NAME", "CLUSTER", "NAMESPACE", "PROJECT", "STATUS", "HEALTH", "SYNCPOLICY", "CONDITIONS"}
@@ -1261,7 +1328,7 @@ func printApplicationTable(apps []argoappv1.Application, output *string) {
 			app.Spec.GetProject(),
 			app.Status.Sync.Status,
 			app.Status.Health.Status,
-			formatSyncPolicy(app),
+			formatSyncPolicy(app, projects[app.Spec.GetProject()]),
 			formatConditionsSummary(app),
 		}
 		if *output == "wide" {
@@ -1314,7 +1381,12 @@ func NewApplicationListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 				appList = argo.FilterByRepo(appList, repo)
 			}
 			if cluster != "" {
-				appList = argo.FilterByCluster(appList, cluster)
+				// the clusters are needed to match the applications which select their cluster by labels
+				clusterConn, clusterIf := headless.NewClientOrDie(clientOpts, c).NewClusterClientOrDie()
+				defer argoio.Close(clusterConn)
+				clusters, err := clusterIf.List(ctx, &clusterpkg.ClusterQuery{})
+				errors.CheckError(err)
+				appList = argo.FilterByCluster(appList, cluster, clusters.Items)
 			}
 
 			switch output {
@@ -1324,7 +1396,7 @@ func NewApplicationListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 			case "name":
 				printApplicationNames(appList)
 			case "wide", "":
-				printApplicationTable(appList, &output)
+				printApplicationTable(appList, &output, getProjectsByName(ctx, clientOpts, c))
 			default:
 				errors.CheckError(fmt.Errorf("unknown output format: %s", output))
 			}
@@ -1339,13 +1411,35 @@ func NewApplicationListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 	return command
 }
 
-func formatSyncPolicy(app argoappv1.Application) string {
-	if app.Spec.SyncPolicy == nil || app.Spec.SyncPolicy.Automated == nil {
-		return "<none>"
+// getProjectsByName returns the projects the user can read by their names. The sync policies of the applications are
+// printed without the defaults of the projects if the projects can't be listed.
+func getProjectsByName(ctx context.Context, clientOpts *argocdclient.ClientOptions, c *cobra.Command) map[string]*argoappv1.AppProject {
+	projects := make(map[string]*argoappv1.AppProject)
+	conn, projIf, err := headless.NewClientOrDie(clientOpts, c).NewProjectClient()
+	if err != nil {
+		return projects
+	}
+	defer argoio.Close(conn)
+	projList, err := projIf.List(ctx, &projectpkg.ProjectQuery{})
+	if err != nil {
+		return projects
+	}
+	for i := range projList.Items {
+		projects[projList.Items[i].Name] = &projList.Items[i]
+	}
+	return projects
+}
+
+func formatSyncPolicy(app argoappv1.Application, proj *argoappv1.AppProject) string {
+	policy := "<none>"
+	if effective := proj.GetSyncPolicy(&app); effective != nil && effective.Automated != nil {
+		policy = "Auto"
+		if effective.Automated.Prune {
+			policy = policy + "-Prune"
+		}
 	}
-	policy := "Auto"
-	if app.Spec.SyncPolicy.Automated.Prune {
-		policy = policy + "-Prune"
+	if app.Spec.SyncPolicy == nil && proj != nil && proj.Spec.DefaultSyncPolicy != nil {
+		policy += " (project default)"
 	}
 	return policy
 }
@@ -1528,6 +1622,7 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 		resources               []string
 		labels                  []string
 		selector                string
+		resourceSelector        string
 		prune                   bool
 		dryRun                  bool
 		timeout                 uint
@@ -1535,11 +1630,15 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 		force                   bool
 		replace                 bool
 		serverSideApply         bool
+		applyOutOfSyncOnly      bool
+		pruneLast               bool
+		prunePropagationPolicy  string
 		async                   bool
 		retryLimit              int64
 		retryBackoffDuration    time.Duration
 		retryBackoffMaxDuration time.Duration
 		retryBackoffFactor      int64
+		retryRefresh            bool
 		local                   string
 		localRepoRoot           string
 		infos                   []string
@@ -1571,7 +1670,10 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
   argocd app sync my-app --resource apps:Deployment:my-service --resource :Service:my-service
   argocd app sync my-app --resource '!*:Service:*'
   # Specify namespace if the application has resources with the same name in different namespaces
-  argocd app sync my-app --resource argoproj.io:Rollout:my-namespace/my-rollout`,
+  argocd app sync my-app --resource argoproj.io:Rollout:my-namespace/my-rollout
+
+  # Sync only the resources of an app which match a label selector
+  argocd app sync my-app --resource-selector 'tier in (frontend,cache)'`,
 		Run: func(c *cobra.Command, args []string) {
 			ctx := c.Context()
 
@@ -1585,6 +1687,8 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 				log.Fatal("Cannot use selector option when application name(s) passed as argument(s)")
 			}
 
+			errors.CheckError(validatePrunePropagationPolicy(prunePropagationPolicy))
+
 			acdClient := headless.NewClientOrDie(clientOpts, c)
 			conn, appIf := acdClient.NewApplicationClientOrDie()
 			defer argoio.Close(conn)
@@ -1698,34 +1802,17 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 					diffOption.cluster = cluster
 				}
 
-				syncOptionsFactory := func() *application.SyncOptions {
-					syncOptions := application.SyncOptions{}
-					items := make([]string, 0)
-					if replace {
-						items = append(items, common.SyncOptionReplace)
-					}
-					if serverSideApply {
-						items = append(items, common.SyncOptionServerSideApply)
-					}
-
-					if len(items) == 0 {
-						// for prevent send even empty array if not need
-						return nil
-					}
-					syncOptions.Items = items
-					return &syncOptions
-				}
-
 				syncReq := application.ApplicationSyncRequest{
-					Name:         &appName,
-					AppNamespace: &appNs,
-					DryRun:       &dryRun,
-					Revision:     &revision,
-					Resources:    filteredResources,
-					Prune:        &prune,
-					Manifests:    localObjsStrings,
-					Infos:        getInfos(infos),
-					SyncOptions:  syncOptionsFactory(),
+					Name:          &appName,
+					AppNamespace:  &appNs,
+					DryRun:        &dryRun,
+					Revision:      &revision,
+					Resources:     filteredResources,
+					LabelSelector: &resourceSelector,
+					Prune:         &prune,
+					Manifests:     localObjsStrings,
+					Infos:         getInfos(infos),
+					SyncOptions:   getSyncOptions(replace, serverSideApply, applyOutOfSyncOnly, pruneLast, prunePropagationPolicy),
 				}
 
 				switch strategy {
@@ -1746,6 +1833,7 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 							MaxDuration: retryBackoffMaxDuration.String(),
 							Factor:      pointer.Int64Ptr(retryBackoffFactor),
 						},
+						Refresh: retryRefresh,
 					}
 				}
 				if diffChanges {
@@ -1784,7 +1872,7 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 					if !dryRun {
 						if !opState.Phase.Successful() {
 							log.Fatalf("Operation has completed with phase: %s", opState.Phase)
-						} else if len(selectedResources) == 0 && app.Status.Sync.Status != argoappv1.SyncStatusCodeSynced {
+						} else if len(selectedResources) == 0 && resourceSelector == "" && app.Status.Sync.Status != argoappv1.SyncStatusCodeSynced {
 							// Only get resources to be pruned if sync was application-wide and final status is not synced
 							pruningRequired := opState.SyncResult.Resources.PruningRequired()
 							if pruningRequired > 0 {
@@ -1798,19 +1886,24 @@ func NewApplicationSyncCommand(clientOpts *argocdclient.ClientOptions) *cobra.Co
 	}
 	command.Flags().BoolVar(&dryRun, "dry-run", false, "Preview apply without affecting cluster")
 	command.Flags().BoolVar(&prune, "prune", false, "Allow deleting unexpected resources")
+	command.Flags().BoolVar(&pruneLast, "prune-last", false, "Prune resources only after all other resources were synced and are healthy")
+	command.Flags().StringVar(&prunePropagationPolicy, "prune-propagation-policy", "", "Deletion propagation policy of pruned resources (one of: foreground|background|orphan)")
 	command.Flags().StringVar(&revision, "revision", "", "Sync to a specific revision. Preserves parameter overrides")
 	command.Flags().StringArrayVar(&resources, "resource", []string{}, fmt.Sprintf("Sync only specific resources as GROUP%[1]sKIND%[1]sNAME or %[2]sGROUP%[1]sKIND%[1]sNAME. Fields may be blank and '*' can be used. This option may be specified repeatedly", resourceFieldDelimiter, resourceExcludeIndicator))
 	command.Flags().StringVarP(&selector, "selector", "l", "", "Sync apps that match this label. Supports '=', '==', '!=', in, notin, exists & not exists. Matching apps must satisfy all of the specified label constraints.")
 	command.Flags().StringArrayVar(&labels, "label", []string{}, "Sync only specific resources with a label. This option may be specified repeatedly.")
+	command.Flags().StringVar(&resourceSelector, "resource-selector", "", "Sync only the resources of the apps that match this label selector. Supports '=', '==', '!=', in, notin, exists & not exists. Resources left out of the sync remain OutOfSync.")
 	command.Flags().UintVar(&timeout, "timeout", defaultCheckTimeoutSeconds, "Time out after this many seconds")
 	command.Flags().Int64Var(&retryLimit, "retry-limit", 0, "Max number of allowed sync retries")
 	command.Flags().DurationVar(&retryBackoffDuration, "retry-backoff-duration", argoappv1.DefaultSyncRetryDuration, "Retry backoff base duration. Input needs to be a duration (e.g. 2m, 1h)")
 	command.Flags().DurationVar(&retryBackoffMaxDuration, "retry-backoff-max-duration", argoappv1.DefaultSyncRetryMaxDuration, "Max retry backoff duration. Input needs to be a duration (e.g. 2m, 1h)")
 	command.Flags().Int64Var(&retryBackoffFactor, "retry-backoff-factor", argoappv1.DefaultSyncRetryFactor, "Factor multiplies the base duration after each failed retry")
+	command.Flags().BoolVar(&retryRefresh, "retry-refresh", false, "Indicates if the latest revision should be used on retry instead of the initial one")
 	command.Flags().StringVar(&strategy, "strategy", "", "Sync strategy (one of: apply|hook)")
 	command.Flags().BoolVar(&force, "force", false, "Use a force apply")
 	command.Flags().BoolVar(&replace, "replace", false, "Use a kubectl create/replace instead apply")
 	command.Flags().BoolVar(&serverSideApply, "server-side", false, "Use server-side apply while syncing the application")
+	command.Flags().BoolVar(&applyOutOfSyncOnly, "apply-out-of-sync-only", false, "Sync only out-of-sync resources")
 	command.Flags().BoolVar(&async, "async", false, "Do not wait for application to sync before continuing")
 	command.Flags().StringVar(&local, "local", "", "Path to a local directory. When this flag is present no git queries will be made")
 	command.Flags().StringVar(&localRepoRoot, "local-repo-root", "/", "Path to the repository root. Used together with --local allows setting the repository root")
@@ -1969,33 +2062,7 @@ func groupResourceStates(app *argoappv1.Application, selectedResources []*argoap
 
 // check if resource health, sync and operation statuses matches watch options
 func checkResourceStatus(watch watchOpts, healthStatus string, syncStatus string, operationStatus *argoappv1.Operation) bool {
-	healthCheckPassed := true
-
-	if watch.suspended && watch.health && watch.degraded {
-		healthCheckPassed = healthStatus == string(health.HealthStatusHealthy) ||
-			healthStatus == string(health.HealthStatusSuspended) ||
-			healthStatus == string(health.HealthStatusDegraded)
-	} else if watch.suspended && watch.degraded {
-		healthCheckPassed = healthStatus == string(health.HealthStatusDegraded) ||
-			healthStatus == string(health.HealthStatusSuspended)
-	} else if watch.degraded && watch.health {
-		healthCheckPassed = healthStatus == string(health.HealthStatusHealthy) ||
-			healthStatus == string(health.HealthStatusDegraded)
-		//below are good
-	} else if watch.suspended && watch.health {
-		healthCheckPassed = healthStatus == string(health.HealthStatusHealthy) ||
-			healthStatus == string(health.HealthStatusSuspended)
-	} else if watch.suspended {
-		healthCheckPassed = healthStatus == string(health.HealthStatusSuspended)
-	} else if watch.health {
-		healthCheckPassed = healthStatus == string(health.HealthStatusHealthy)
-	} else if watch.degraded {
-		healthCheckPassed = healthStatus == string(health.HealthStatusDegraded)
-	}
-
-	synced := !watch.sync || syncStatus == string(argoappv1.SyncStatusCodeSynced)
-	operational := !watch.operation || operationStatus == nil
-	return synced && healthCheckPassed && operational
+	return watch.waitOptions().IsReached(healthStatus, syncStatus, operationStatus)
 }
 
 const waitFormatString = "%s\t%5s\t%10s\t%10s\t%20s\t%8s\t%7s\t%10s\t%s\n"
@@ -2028,8 +2095,15 @@ func waitOnApplicationStatus(ctx context.Context, acdClient argocdclient.Client,
 			_ = conn.Close()
 		}
 
+		var proj *argoappv1.AppProject
+		if conn, projIf, err := acdClient.NewProjectClient(); err == nil {
+			// the sync policy of the application is printed without the default of the project if it can't be read
+			proj, _ = projIf.Get(ctx, &projectpkg.ProjectQuery{Name: app.Spec.GetProject()})
+			_ = conn.Close()
+		}
+
 		fmt.Println()
-		printAppSummaryTable(app, appURL(ctx, acdClient, appName), nil)
+		printAppSummaryTable(app, appURL(ctx, acdClient, appName), nil, proj)
 		fmt.Println()
 		if watch.operation {
 			printOperationResult(app.Status.OperationState)
@@ -2075,22 +2149,9 @@ func waitOnApplicationStatus(ctx context.Context, acdClient argocdclient.Client,
 		app = &appEvent.Application
 
 		finalOperationState = app.Status.OperationState
-		operationInProgress := false
-		// consider the operation is in progress
-		if app.Operation != nil {
-			// if it just got requested
-			operationInProgress = true
-			if !app.Operation.DryRun() {
-				refresh = true
-			}
-		} else if app.Status.OperationState != nil {
-			if app.Status.OperationState.FinishedAt == nil {
-				// if it is not finished yet
-				operationInProgress = true
-			} else if !app.Status.OperationState.Operation.DryRun() && (app.Status.ReconciledAt == nil || app.Status.ReconciledAt.Before(app.Status.OperationState.FinishedAt)) {
-				// if it is just finished and we need to wait for controller to reconcile app once after syncing
-				operationInProgress = true
-			}
+		operationInProgress := argo.IsOperationInProgress(app)
+		if app.Operation != nil && !app.Operation.DryRun() {
+			refresh = true
 		}
 
 		var selectedResourcesAreReady bool
@@ -2252,12 +2313,21 @@ func findRevisionHistory(application *argoappv1.Application, historyId int64) (*
 // NewApplicationRollbackCommand returns a new instance of an `argocd app rollback` command
 func NewApplicationRollbackCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 	var (
-		prune   bool
-		timeout uint
+		prune    bool
+		timeout  uint
+		revision string
 	)
 	var command = &cobra.Command{
 		Use:   "rollback APPNAME [ID]",
 		Short: "Rollback application to a previous deployed version by History ID, omitted will Rollback to the previous version",
+		Example: `  # Rollback to the previous deployed version
+  argocd app rollback my-app
+
+  # Rollback to the deployment with history ID 3
+  argocd app rollback my-app 3
+
+  # Rollback or forward to a git revision or chart version which was not necessarily deployed before
+  argocd app rollback my-app --revision v1.2.0`,
 		Run: func(c *cobra.Command, args []string) {
 			ctx := c.Context()
 
@@ -2281,15 +2351,24 @@ func NewApplicationRollbackCommand(clientOpts *argocdclient.ClientOptions) *cobr
 			})
 			errors.CheckError(err)
 
-			depInfo, err := findRevisionHistory(app, int64(depID))
-			errors.CheckError(err)
-
-			_, err = appIf.Rollback(ctx, &application.ApplicationRollbackRequest{
+			rollbackReq := &application.ApplicationRollbackRequest{
 				Name:         &appName,
 				AppNamespace: &appNs,
-				Id:           pointer.Int64(depInfo.ID),
 				Prune:        pointer.Bool(prune),
-			})
+			}
+			if revision != "" {
+				if len(args) > 1 {
+					errors.CheckError(fmt.Errorf("an ID and a revision cannot be given at the same time"))
+				}
+				rollbackReq.Id = pointer.Int64(0)
+				rollbackReq.Revision = &revision
+			} else {
+				depInfo, err := findRevisionHistory(app, int64(depID))
+				errors.CheckError(err)
+				rollbackReq.Id = pointer.Int64(depInfo.ID)
+			}
+
+			_, err = appIf.Rollback(ctx, rollbackReq)
 			errors.CheckError(err)
 
 			_, _, err = waitOnApplicationStatus(ctx, acdClient, app.QualifiedName(), timeout, watchOpts{
@@ -2300,6 +2379,7 @@ func NewApplicationRollbackCommand(clientOpts *argocdclient.ClientOptions) *cobr
 	}
 	command.Flags().BoolVar(&prune, "prune", false, "Allow deleting unexpected resources")
 	command.Flags().UintVar(&timeout, "timeout", defaultCheckTimeoutSeconds, "Time out after this many seconds")
+	command.Flags().StringVar(&revision, "revision", "", "Rollback or forward to a git revision or chart version instead of a deployment from the history")
 	return command
 }
 
@@ -2509,7 +2589,10 @@ func NewApplicationPatchCommand(clientOpts *argocdclient.ClientOptions) *cobra.C
   argocd app patch myapplication --patch='[{"op": "replace", "path": "/spec/source/path", "value": "newPath"}]' --type json
 
   # Update an application's repository target revision using merge patch
-  argocd app patch myapplication --patch '{"spec": { "source": { "targetRevision": "master" } }}' --type merge`,
+  argocd app patch myapplication --patch '{"spec": { "source": { "targetRevision": "master" } }}' --type merge
+
+  # Update a single Helm parameter using strategic merge patch
+  argocd app patch myapplication --patch '{"spec": { "source": { "helm": { "parameters": [{"name": "image.tag", "value": "v2"}] } } }}' --type strategic`,
 		Run: func(c *cobra.Command, args []string) {
 			ctx := c.Context()
 
@@ -2537,6 +2620,6 @@ func NewApplicationPatchCommand(clientOpts *argocdclient.ClientOptions) *cobra.C
 	}
 
 	command.Flags().StringVar(&patch, "patch", "", "Patch body")
-	command.Flags().StringVar(&patchType, "type", "json", "The type of patch being provided; one of [json merge]")
+	command.Flags().StringVar(&patchType, "type", "json", "The type of patch being provided; one of [json merge strategic]")
 	return &command
 }
@@ -133,9 +133,15 @@ func NewApplicationResourceActionsRunCommand(clientOpts *argocdclient.ClientOpti
 	var kind string
 	var group string
 	var all bool
+	var params []string
 	var command = &cobra.Command{
 		Use:   "run APPNAME ACTION",
 		Short: "Runs an available action on resource(s)",
+		Example: `  # Restart a deployment
+  argocd app actions run my-app restart --kind Deployment --resource-name my-deployment
+
+  # Run an action which accepts parameters
+  argocd app actions run my-app scale --kind Deployment --resource-name my-deployment --param replicas=3`,
 	}
 
 	command.Flags().StringVar(&resourceName, "resource-name", "", "Name of resource")
@@ -144,6 +150,7 @@ func NewApplicationResourceActionsRunCommand(clientOpts *argocdclient.ClientOpti
 	command.Flags().StringVar(&group, "group", "", "Group")
 	errors.CheckError(command.MarkFlagRequired("kind"))
 	command.Flags().BoolVar(&all, "all", false, "Indicates whether to run the action on multiple matching resources")
+	command.Flags().StringArrayVar(&params, "param", []string{}, "Parameter of the action in the form name=value (can be repeated multiple times)")
 
 	command.Run = func(c *cobra.Command, args []string) {
 		ctx := c.Context()
@@ -154,6 +161,8 @@ func NewApplicationResourceActionsRunCommand(clientOpts *argocdclient.ClientOpti
 		}
 		appName, appNs := argo.ParseAppQualifiedName(args[0], "")
 		actionName := args[1]
+		actionParams, err := util.ParseResourceActionParams(params)
+		errors.CheckError(err)
 
 		conn, appIf := headless.NewClientOrDie(clientOpts, c).NewApplicationClientOrDie()
 		defer io.Close(conn)
@@ -181,6 +190,7 @@ func NewApplicationResourceActionsRunCommand(clientOpts *argocdclient.ClientOpti
 				Kind:         pointer.String(gvk.Kind),
 				Version:      pointer.String(gvk.GroupVersion().Version),
 				Action:       pointer.String(actionName),
+				Params:       actionParams,
 			})
 			errors.CheckError(err)
 		}
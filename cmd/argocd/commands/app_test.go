@@ -78,6 +78,43 @@ func Test_getRefreshType(t *testing.T) {
 	}
 }
 
+func Test_getSyncOptions(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		assert.Nil(t, getSyncOptions(false, false, false, false, ""))
+	})
+	t.Run("ApplyOutOfSyncOnly", func(t *testing.T) {
+		syncOptions := getSyncOptions(false, false, true, false, "")
+		assert.NotNil(t, syncOptions)
+		assert.Equal(t, []string{"ApplyOutOfSyncOnly=true"}, syncOptions.Items)
+	})
+	t.Run("PruneLast", func(t *testing.T) {
+		syncOptions := getSyncOptions(false, false, false, true, "")
+		assert.NotNil(t, syncOptions)
+		assert.Equal(t, []string{"PruneLast=true"}, syncOptions.Items)
+	})
+	t.Run("Combined", func(t *testing.T) {
+		syncOptions := getSyncOptions(true, true, true, true, "")
+		assert.NotNil(t, syncOptions)
+		assert.Equal(t, []string{"Replace=true", "ServerSideApply=true", "ApplyOutOfSyncOnly=true", "PruneLast=true"}, syncOptions.Items)
+	})
+	for _, policy := range []string{"foreground", "background", "orphan"} {
+		t.Run("PrunePropagationPolicy="+policy, func(t *testing.T) {
+			syncOptions := getSyncOptions(false, false, false, false, policy)
+			assert.NotNil(t, syncOptions)
+			assert.Equal(t, []string{"PrunePropagationPolicy=" + policy}, syncOptions.Items)
+		})
+	}
+}
+
+func Test_validatePrunePropagationPolicy(t *testing.T) {
+	for _, policy := range []string{"", "foreground", "background", "orphan"} {
+		assert.NoError(t, validatePrunePropagationPolicy(policy))
+	}
+	for _, policy := range []string{"Foreground", "delete", " orphan"} {
+		assert.Error(t, validatePrunePropagationPolicy(policy))
+	}
+}
+
 func TestFindRevisionHistoryWithoutPassedId(t *testing.T) {
 
 	histories := v1alpha1.RevisionHistories{}
@@ -320,7 +357,7 @@ func TestFormatSyncPolicy(t *testing.T) {
 	t.Run("Policy not defined", func(t *testing.T) {
 		app := v1alpha1.Application{}
 
-		policy := formatSyncPolicy(app)
+		policy := formatSyncPolicy(app, nil)
 
 		if policy != "<none>" {
 			t.Fatalf("Incorrect policy %q, should be <none>", policy)
@@ -336,7 +373,7 @@ func TestFormatSyncPolicy(t *testing.T) {
 			},
 		}
 
-		policy := formatSyncPolicy(app)
+		policy := formatSyncPolicy(app, nil)
 
 		if policy != "Auto" {
 			t.Fatalf("Incorrect policy %q, should be Auto", policy)
@@ -354,13 +391,29 @@ func TestFormatSyncPolicy(t *testing.T) {
 			},
 		}
 
-		policy := formatSyncPolicy(app)
+		policy := formatSyncPolicy(app, nil)
 
 		if policy != "Auto-Prune" {
 			t.Fatalf("Incorrect policy %q, should be Auto-Prune", policy)
 		}
 	})
 
+	t.Run("Project default policy", func(t *testing.T) {
+		app := v1alpha1.Application{}
+		proj := &v1alpha1.AppProject{
+			Spec: v1alpha1.AppProjectSpec{
+				DefaultSyncPolicy: &v1alpha1.SyncPolicy{
+					Automated: &v1alpha1.SyncPolicyAutomated{Prune: true},
+				},
+			},
+		}
+
+		assert.Equal(t, "Auto-Prune (project default)", formatSyncPolicy(app, proj))
+
+		// an empty sync policy opts out of the default sync policy of the project
+		app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{}
+		assert.Equal(t, "<none>", formatSyncPolicy(app, proj))
+	})
 }
 
 func TestFormatConditionSummary(t *testing.T) {
@@ -550,7 +603,7 @@ func TestPrintAppSummaryTable(t *testing.T) {
 			},
 		}
 
-		printAppSummaryTable(app, "url", windows)
+		printAppSummaryTable(app, "url", windows, nil)
 		return nil
 	})
 
@@ -573,6 +626,36 @@ Health Status:      Progressing (health-message)
 	assert.Equalf(t, expectation, output, "Incorrect print app summary output %q, should be %q", output, expectation)
 }
 
+func TestPrintAppSummaryTable_ProjectDefaultSyncPolicy(t *testing.T) {
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "argocd"},
+		Spec: v1alpha1.ApplicationSpec{
+			Project:     "default",
+			Destination: v1alpha1.ApplicationDestination{Server: "local", Namespace: "argocd"},
+			Source:      &v1alpha1.ApplicationSource{RepoURL: "test", TargetRevision: "master", Path: "/test"},
+		},
+	}
+	proj := &v1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.AppProjectSpec{
+			DefaultSyncPolicy: &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{Prune: true}},
+		},
+	}
+
+	output, _ := captureOutput(func() error {
+		printAppSummaryTable(app, "url", nil, proj)
+		return nil
+	})
+	assert.Contains(t, output, "Sync Policy:        Automated (Prune) (project default)\n")
+
+	app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{}
+	output, _ = captureOutput(func() error {
+		printAppSummaryTable(app, "url", nil, proj)
+		return nil
+	})
+	assert.Contains(t, output, "Sync Policy:        <none>\n")
+}
+
 func TestPrintAppConditions(t *testing.T) {
 	output, _ := captureOutput(func() error {
 		app := &v1alpha1.Application{
@@ -1217,7 +1300,7 @@ func TestPrintApplicationTableNotWide(t *testing.T) {
 			},
 		}
 		output := "table"
-		printApplicationTable([]v1alpha1.Application{*app, *app}, &output)
+		printApplicationTable([]v1alpha1.Application{*app, *app}, &output, nil)
 		return nil
 	})
 	assert.NoError(t, err)
@@ -1253,7 +1336,7 @@ func TestPrintApplicationTableWide(t *testing.T) {
 			},
 		}
 		output := "wide"
-		printApplicationTable([]v1alpha1.Application{*app, *app}, &output)
+		printApplicationTable([]v1alpha1.Application{*app, *app}, &output, nil)
 		return nil
 	})
 	assert.NoError(t, err)
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/mattn/go-isatty"
 	log "github.com/sirupsen/logrus"
@@ -66,6 +67,7 @@ func NewClusterCommand(clientOpts *argocdclient.ClientOptions, pathOpts *clientc
 
 	command.AddCommand(NewClusterAddCommand(clientOpts, pathOpts))
 	command.AddCommand(NewClusterGetCommand(clientOpts))
+	command.AddCommand(NewClusterInvalidateCacheCommand(clientOpts))
 	command.AddCommand(NewClusterListCommand(clientOpts))
 	command.AddCommand(NewClusterRemoveCommand(clientOpts, pathOpts))
 	command.AddCommand(NewClusterRotateAuthCommand(clientOpts))
@@ -170,6 +172,9 @@ func NewClusterAddCommand(clientOpts *argocdclient.ClientOptions, pathOpts *clie
 			if clusterOpts.Project != "" {
 				clst.Project = clusterOpts.Project
 			}
+			clst.RepoServer = clusterOpts.RepoServer
+			errors.CheckError(clusterOpts.SetConnectionOptions(clst, conf))
+			clst.Server = clusterOpts.ServerURL(clst.Server)
 			clstCreateReq := clusterpkg.ClusterCreateRequest{
 				Cluster: clst,
 				Upsert:  clusterOpts.Upsert,
@@ -344,6 +349,19 @@ func printClusterDetails(clusters []argoappv1.Cluster) {
 		fmt.Printf("  Server Name:           %s\n", strWithDefault(cluster.Name, "-"))
 		fmt.Printf("  Server Version:        %s\n", cluster.ServerVersion)
 		fmt.Printf("  Namespaces:        	 %s\n", formatNamespaces(cluster))
+		fmt.Printf("\nCluster cache\n\n")
+		fmt.Printf("  Connection status:     %s\n", strWithDefault(string(cluster.Info.ConnectionState.Status), "-"))
+		if cluster.Info.ConnectionState.Message != "" {
+			fmt.Printf("  Connection message:    %s\n", cluster.Info.ConnectionState.Message)
+		}
+		fmt.Printf("  Applications:          %d\n", cluster.Info.ApplicationsCount)
+		fmt.Printf("  APIs:                  %d\n", cluster.Info.CacheInfo.APIsCount)
+		fmt.Printf("  Resources:             %d\n", cluster.Info.CacheInfo.ResourcesCount)
+		lastCacheSyncTime := "-"
+		if cluster.Info.CacheInfo.LastCacheSyncTime != nil {
+			lastCacheSyncTime = cluster.Info.CacheInfo.LastCacheSyncTime.Format(time.RFC3339)
+		}
+		fmt.Printf("  Last cache sync:       %s\n", lastCacheSyncTime)
 		fmt.Printf("\nTLS configuration\n\n")
 		fmt.Printf("  Client cert:           %v\n", string(cluster.Config.TLSClientConfig.CertData) != "")
 		fmt.Printf("  Cert validation:       %v\n", !cluster.Config.TLSClientConfig.Insecure)
@@ -461,7 +479,8 @@ func printClusterServers(clusters []argoappv1.Cluster) {
 // NewClusterListCommand returns a new instance of an `argocd cluster rm` command
 func NewClusterListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 	var (
-		output string
+		output   string
+		selector string
 	)
 	var command = &cobra.Command{
 		Use:   "list",
@@ -471,7 +490,7 @@ func NewClusterListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Comman
 
 			conn, clusterIf := headless.NewClientOrDie(clientOpts, c).NewClusterClientOrDie()
 			defer io.Close(conn)
-			clusters, err := clusterIf.List(ctx, &clusterpkg.ClusterQuery{})
+			clusters, err := clusterIf.List(ctx, &clusterpkg.ClusterQuery{Selector: selector})
 			errors.CheckError(err)
 			switch output {
 			case "yaml", "json":
@@ -487,6 +506,35 @@ func NewClusterListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Comman
 		},
 	}
 	command.Flags().StringVarP(&output, "output", "o", "wide", "Output format. One of: json|yaml|wide|server")
+	command.Flags().StringVarP(&selector, "selector", "l", "", "List clusters by label. Supports '=', '==', '!=', in, notin, exists & not exists. Matching clusters must satisfy all of the specified label constraints.")
+	return command
+}
+
+// NewClusterInvalidateCacheCommand returns a new instance of an `argocd cluster invalidate-cache` command
+func NewClusterInvalidateCacheCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "invalidate-cache SERVER/NAME",
+		Short: "Invalidate the cluster cache of the application controller, which is then rebuilt",
+		Example: `argocd cluster invalidate-cache https://12.34.567.89
+argocd cluster invalidate-cache cluster-name`,
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			if len(args) != 1 {
+				c.HelpFunc()(c, args)
+				os.Exit(1)
+			}
+			conn, clusterIf := headless.NewClientOrDie(clientOpts, c).NewClusterClientOrDie()
+			defer io.Close(conn)
+
+			cluster := args[0]
+			clusterQuery := getQueryBySelector(cluster)
+			_, err := clusterIf.InvalidateCache(ctx, clusterQuery)
+			errors.CheckError(err)
+
+			fmt.Printf("Cluster '%s' cache invalidated\n", cluster)
+		},
+	}
 	return command
 }
 
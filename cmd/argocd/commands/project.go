@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	humanize "github.com/dustin/go-humanize"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
@@ -64,6 +66,9 @@ func NewProjectCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 	command.AddCommand(NewProjectWindowsCommand(clientOpts))
 	command.AddCommand(NewProjectAddOrphanedIgnoreCommand(clientOpts))
 	command.AddCommand(NewProjectRemoveOrphanedIgnoreCommand(clientOpts))
+	command.AddCommand(NewProjectDriftReportCommand(clientOpts))
+	command.AddCommand(NewProjectActivityCommand(clientOpts))
+	command.AddCommand(NewProjectValidateCommand(clientOpts))
 	return command
 }
 
@@ -844,6 +849,207 @@ func getProject(c *cobra.Command, clientOpts *argocdclient.ClientOptions, ctx co
 	return detailedProject
 }
 
+// NewProjectDriftReportCommand returns a new instance of an `argocd proj drift-report` command
+func NewProjectDriftReportCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
+	var (
+		output string
+	)
+	var command = &cobra.Command{
+		Use:   "drift-report PROJECT",
+		Short: "Report the drift of the applications of a project",
+		Example: `  # Print the drift of the applications of a project
+  argocd proj drift-report PROJECT
+
+  # Export the drift report of a project for a compliance review
+  argocd proj drift-report PROJECT -o csv > drift-report.csv`,
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			if len(args) != 1 {
+				c.HelpFunc()(c, args)
+				os.Exit(1)
+			}
+			conn, projIf := headless.NewClientOrDie(clientOpts, c).NewProjectClientOrDie()
+			defer argoio.Close(conn)
+			report, err := projIf.GetDriftReport(ctx, &projectpkg.ProjectQuery{Name: args[0]})
+			errors.CheckError(err)
+
+			switch output {
+			case "yaml", "json":
+				err := PrintResource(report, output)
+				errors.CheckError(err)
+			case "csv":
+				err := printDriftReportCSV(os.Stdout, report)
+				errors.CheckError(err)
+			case "wide", "":
+				printDriftReportTable(report)
+			default:
+				errors.CheckError(fmt.Errorf("unknown output format: %s", output))
+			}
+		},
+	}
+	command.Flags().StringVarP(&output, "output", "o", "wide", "Output format. One of: json|yaml|csv|wide")
+	return command
+}
+
+func formatLastSyncedAt(drift *projectpkg.ApplicationDrift) string {
+	if drift.LastSyncedAt == nil {
+		return ""
+	}
+	return drift.LastSyncedAt.UTC().Format(time.RFC3339)
+}
+
+func printDriftReportTable(report *projectpkg.DriftReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\tSYNC STATUS\tHEALTH STATUS\tMODIFIED RESOURCES\tLAST SYNCED\tREVISION\tSIGNATURE\n")
+	for _, drift := range report.Applications {
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%d\t%s\t%s\t%s\n", drift.Namespace, drift.Name, drift.SyncStatus, drift.HealthStatus,
+			len(drift.ModifiedResources), formatLastSyncedAt(drift), drift.LastSyncedRevision, drift.SignatureVerification)
+	}
+	_ = w.Flush()
+}
+
+func printDriftReportCSV(out io.Writer, report *projectpkg.DriftReport) error {
+	w := csv.NewWriter(out)
+	err := w.Write([]string{"project", "namespace", "name", "syncStatus", "healthStatus", "modifiedResources", "lastSyncedAt", "lastSyncedRevision", "signatureVerification"})
+	if err != nil {
+		return err
+	}
+	for _, drift := range report.Applications {
+		err = w.Write([]string{report.Project, drift.Namespace, drift.Name, drift.SyncStatus, drift.HealthStatus,
+			strings.Join(drift.ModifiedResources, " "), formatLastSyncedAt(drift), drift.LastSyncedRevision, drift.SignatureVerification})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// NewProjectActivityCommand returns a new instance of an `argocd proj activity` command
+func NewProjectActivityCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
+	var (
+		output string
+		limit  int64
+	)
+	var command = &cobra.Command{
+		Use:   "activity PROJECT",
+		Short: "List the recent activity of a project and of its applications",
+		Example: `  # List the recent activity of a project
+  argocd proj activity PROJECT
+
+  # List the last 10 events of a project
+  argocd proj activity PROJECT --limit 10`,
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			if len(args) != 1 {
+				c.HelpFunc()(c, args)
+				os.Exit(1)
+			}
+			conn, projIf := headless.NewClientOrDie(clientOpts, c).NewProjectClientOrDie()
+			defer argoio.Close(conn)
+			events, err := projIf.ListActivity(ctx, &projectpkg.ProjectActivityRequest{Name: args[0], Limit: limit})
+			errors.CheckError(err)
+
+			switch output {
+			case "yaml", "json":
+				err := PrintResourceList(events.Items, output, false)
+				errors.CheckError(err)
+			case "wide", "":
+				printProjectActivityTable(events.Items)
+			default:
+				errors.CheckError(fmt.Errorf("unknown output format: %s", output))
+			}
+		},
+	}
+	command.Flags().StringVarP(&output, "output", "o", "wide", "Output format. One of: json|yaml|wide")
+	command.Flags().Int64Var(&limit, "limit", 0, "Maximum number of events to list (default 100)")
+	return command
+}
+
+func printProjectActivityTable(events []corev1.Event) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "LAST SEEN\tOBJECT\tREASON\tUSER\tMESSAGE\n")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s/%s\t%s\t%s\t%s\n", e.LastTimestamp.UTC().Format(time.RFC3339), e.InvolvedObject.Kind, e.InvolvedObject.Name,
+			e.Reason, e.Annotations["user"], e.Message)
+	}
+	_ = w.Flush()
+}
+
+// NewProjectValidateCommand returns a new instance of an `argocd proj validate` command
+func NewProjectValidateCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
+	var (
+		fileURL string
+		output  string
+	)
+	var command = &cobra.Command{
+		Use:   "validate [PROJECT] -f FILE",
+		Short: "Validate a project spec without saving it, and list the applications which would become non-conformant",
+		Example: `  # Validate the changes of a project before applying them
+  argocd proj validate -f project.yaml
+
+  # Validate a project spec read from stdin
+  kubectl get appproject myproject -n argocd -o yaml | argocd proj validate -f -`,
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			if fileURL == "" {
+				c.HelpFunc()(c, args)
+				os.Exit(1)
+			}
+			proj, err := cmdutil.ConstructAppProj(fileURL, args, cmdutil.ProjectOpts{}, c)
+			errors.CheckError(err)
+
+			conn, projIf := headless.NewClientOrDie(clientOpts, c).NewProjectClientOrDie()
+			defer argoio.Close(conn)
+			res, err := projIf.ValidateSpec(ctx, &projectpkg.ProjectUpdateRequest{Project: proj})
+			errors.CheckError(err)
+
+			switch output {
+			case "yaml", "json":
+				err := PrintResource(res, output)
+				errors.CheckError(err)
+			case "wide", "":
+				printProjectValidation(res)
+			default:
+				errors.CheckError(fmt.Errorf("unknown output format: %s", output))
+			}
+			if len(res.Errors) > 0 || len(res.Applications) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	command.Flags().StringVarP(&fileURL, "file", "f", "", "Filename or URL to Kubernetes manifests for the project")
+	err := command.Flags().SetAnnotation("file", cobra.BashCompFilenameExt, []string{"json", "yaml", "yml"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	command.Flags().StringVarP(&output, "output", "o", "wide", "Output format. One of: json|yaml|wide")
+	return command
+}
+
+func printProjectValidation(res *projectpkg.ProjectValidationResponse) {
+	if len(res.Errors) == 0 && len(res.Applications) == 0 {
+		fmt.Println("Project spec is valid and all applications conform to it")
+		return
+	}
+	for _, e := range res.Errors {
+		fmt.Printf("Error: %s\n", e)
+	}
+	if len(res.Applications) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "NAME\tREASON\n")
+		for _, app := range res.Applications {
+			for _, reason := range app.Reasons {
+				fmt.Fprintf(w, "%s/%s\t%s\n", app.Namespace, app.Name, reason)
+			}
+		}
+		_ = w.Flush()
+	}
+}
+
 func NewProjectEditCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 	var command = &cobra.Command{
 		Use:   "edit PROJECT",
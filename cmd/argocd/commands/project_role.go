@@ -41,6 +41,7 @@ func NewProjectRoleCommand(clientOpts *argocdclient.ClientOptions) *cobra.Comman
 	roleCommand.AddCommand(NewProjectRoleCreateTokenCommand(clientOpts))
 	roleCommand.AddCommand(NewProjectRoleListTokensCommand(clientOpts))
 	roleCommand.AddCommand(NewProjectRoleDeleteTokenCommand(clientOpts))
+	roleCommand.AddCommand(NewProjectRoleSetDefaultTokenExpirationCommand(clientOpts))
 	roleCommand.AddCommand(NewProjectRoleAddPolicyCommand(clientOpts))
 	roleCommand.AddCommand(NewProjectRoleRemovePolicyCommand(clientOpts))
 	roleCommand.AddCommand(NewProjectRoleAddGroupCommand(clientOpts))
@@ -135,7 +136,8 @@ func NewProjectRoleRemovePolicyCommand(clientOpts *argocdclient.ClientOptions) *
 // NewProjectRoleCreateCommand returns a new instance of an `argocd proj role create` command
 func NewProjectRoleCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 	var (
-		description string
+		description            string
+		defaultTokenExpiration string
 	)
 	var command = &cobra.Command{
 		Use:   "create PROJECT ROLE-NAME",
@@ -160,7 +162,7 @@ func NewProjectRoleCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
 				fmt.Printf("Role '%s' already exists\n", roleName)
 				return
 			}
-			proj.Spec.Roles = append(proj.Spec.Roles, v1alpha1.ProjectRole{Name: roleName, Description: description})
+			proj.Spec.Roles = append(proj.Spec.Roles, v1alpha1.ProjectRole{Name: roleName, Description: description, DefaultTokenExpiration: defaultTokenExpiration})
 
 			_, err = projIf.Update(ctx, &projectpkg.ProjectUpdateRequest{Project: proj})
 			errors.CheckError(err)
@@ -168,6 +170,47 @@ func NewProjectRoleCreateCommand(clientOpts *argocdclient.ClientOptions) *cobra.
 		},
 	}
 	command.Flags().StringVarP(&description, "description", "", "", "Project description")
+	command.Flags().StringVar(&defaultTokenExpiration, "default-token-expiration", "", "Duration before the tokens of the role expire if no expiration is requested when they are created, e.g. \"12h\", \"7d\"")
+	return command
+}
+
+// NewProjectRoleSetDefaultTokenExpirationCommand returns a new instance of an `argocd proj role set-default-token-expiration` command
+func NewProjectRoleSetDefaultTokenExpirationCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "set-default-token-expiration PROJECT ROLE-NAME [DURATION]",
+		Short: "Set the duration before the tokens of a project role expire if no expiration is requested when they are created",
+		Example: `  # Let new tokens of the role expire after 30 days by default
+  argocd proj role set-default-token-expiration PROJECT ROLE-NAME 30d
+
+  # Don't let new tokens of the role expire by default
+  argocd proj role set-default-token-expiration PROJECT ROLE-NAME`,
+		Run: func(c *cobra.Command, args []string) {
+			ctx := c.Context()
+
+			if len(args) != 2 && len(args) != 3 {
+				c.HelpFunc()(c, args)
+				os.Exit(1)
+			}
+			projName := args[0]
+			roleName := args[1]
+			expiration := ""
+			if len(args) == 3 {
+				expiration = args[2]
+			}
+			conn, projIf := headless.NewClientOrDie(clientOpts, c).NewProjectClientOrDie()
+			defer io.Close(conn)
+
+			proj, err := projIf.Get(ctx, &projectpkg.ProjectQuery{Name: projName})
+			errors.CheckError(err)
+
+			_, roleIndex, err := proj.GetRoleByName(roleName)
+			errors.CheckError(err)
+
+			proj.Spec.Roles[roleIndex].DefaultTokenExpiration = expiration
+			_, err = projIf.Update(ctx, &projectpkg.ProjectUpdateRequest{Project: proj})
+			errors.CheckError(err)
+		},
+	}
 	return command
 }
 
@@ -275,7 +318,7 @@ func NewProjectRoleCreateTokenCommand(clientOpts *argocdclient.ClientOptions) *c
 		},
 	}
 	command.Flags().StringVarP(&expiresIn, "expires-in", "e", "",
-		"Duration before the token will expire, e.g. \"12h\", \"7d\". (Default: No expiration)",
+		"Duration before the token will expire, e.g. \"12h\", \"7d\". (Default: The default token expiration of the role, or no expiration)",
 	)
 	command.Flags().StringVarP(&tokenID, "id", "i", "", "Token unique identifier. (Default: Random UUID)")
 	command.Flags().BoolVarP(&outputTokenOnly, "token-only", "t", false, "Output token only - for use in scripts.")
@@ -304,12 +347,10 @@ func NewProjectRoleListTokensCommand(clientOpts *argocdclient.ClientOptions) *co
 			conn, projIf := headless.NewClientOrDie(clientOpts, c).NewProjectClientOrDie()
 			defer io.Close(conn)
 
-			proj, err := projIf.Get(ctx, &projectpkg.ProjectQuery{Name: projName})
-			errors.CheckError(err)
-			role, _, err := proj.GetRoleByName(roleName)
+			tokens, err := projIf.ListTokens(ctx, &projectpkg.ProjectTokenListRequest{Project: projName, Role: roleName})
 			errors.CheckError(err)
 
-			if len(role.JWTTokens) == 0 {
+			if len(tokens.Items) == 0 {
 				fmt.Printf("No tokens for %s.%s\n", projName, roleName)
 				return
 			}
@@ -319,7 +360,7 @@ func NewProjectRoleListTokensCommand(clientOpts *argocdclient.ClientOptions) *co
 			errors.CheckError(err)
 
 			tokenRowFormat := "%s\t%v\t%v\n"
-			for _, token := range role.JWTTokens {
+			for _, token := range tokens.Items {
 				if useUnixTime {
 					_, _ = fmt.Fprintf(writer, tokenRowFormat, token.ID, token.IssuedAt, token.ExpiresAt)
 				} else {
@@ -338,29 +379,43 @@ func NewProjectRoleListTokensCommand(clientOpts *argocdclient.ClientOptions) *co
 
 // NewProjectRoleDeleteTokenCommand returns a new instance of an `argocd proj role delete-token` command
 func NewProjectRoleDeleteTokenCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
+	var (
+		tokenID string
+	)
 	var command = &cobra.Command{
-		Use:     "delete-token PROJECT ROLE-NAME ISSUED-AT",
+		Use:     "delete-token PROJECT ROLE-NAME [ISSUED-AT]",
 		Short:   "Delete a project token",
 		Aliases: []string{"token-delete", "remove-token"},
+		Example: `  # Revoke a token by its issued-at time
+  argocd proj role delete-token PROJECT ROLE-NAME 1696763431
+
+  # Revoke a token by its ID
+  argocd proj role delete-token PROJECT ROLE-NAME --id 1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d`,
 		Run: func(c *cobra.Command, args []string) {
 			ctx := c.Context()
 
-			if len(args) != 3 {
+			if (tokenID == "" && len(args) != 3) || (tokenID != "" && len(args) != 2) {
 				c.HelpFunc()(c, args)
 				os.Exit(1)
 			}
 			projName := args[0]
 			roleName := args[1]
-			issuedAt, err := strconv.ParseInt(args[2], 10, 64)
-			errors.CheckError(err)
+			// -1 makes the token to be looked up only by its ID
+			issuedAt := int64(-1)
+			if len(args) == 3 {
+				var err error
+				issuedAt, err = strconv.ParseInt(args[2], 10, 64)
+				errors.CheckError(err)
+			}
 
 			conn, projIf := headless.NewClientOrDie(clientOpts, c).NewProjectClientOrDie()
 			defer io.Close(conn)
 
-			_, err = projIf.DeleteToken(ctx, &projectpkg.ProjectTokenDeleteRequest{Project: projName, Role: roleName, Iat: issuedAt})
+			_, err := projIf.DeleteToken(ctx, &projectpkg.ProjectTokenDeleteRequest{Project: projName, Role: roleName, Iat: issuedAt, Id: tokenID})
 			errors.CheckError(err)
 		},
 	}
+	command.Flags().StringVarP(&tokenID, "id", "i", "", "Unique identifier of the token to delete, instead of its issued-at time")
 	return command
 }
 
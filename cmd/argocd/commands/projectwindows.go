@@ -285,8 +285,8 @@ func NewProjectWindowsListCommand(clientOpts *argocdclient.ClientOptions) *cobra
 func printSyncWindows(proj *v1alpha1.AppProject) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	var fmtStr string
-	headers := []interface{}{"ID", "STATUS", "KIND", "SCHEDULE", "DURATION", "APPLICATIONS", "NAMESPACES", "CLUSTERS", "MANUALSYNC"}
-	fmtStr = "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
+	headers := []interface{}{"ID", "STATUS", "KIND", "SCHEDULE", "DURATION", "TIMEZONE", "APPLICATIONS", "NAMESPACES", "CLUSTERS", "MANUALSYNC"}
+	fmtStr = "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
 	fmt.Fprintf(w, fmtStr, headers...)
 	if proj.Spec.SyncWindows.HasWindows() {
 		for i, window := range proj.Spec.SyncWindows {
@@ -296,6 +296,7 @@ func printSyncWindows(proj *v1alpha1.AppProject) {
 				window.Kind,
 				window.Schedule,
 				window.Duration,
+				formatTimeZoneOutput(window.TimeZone),
 				formatListOutput(window.Applications),
 				formatListOutput(window.Namespaces),
 				formatListOutput(window.Clusters),
@@ -316,6 +317,12 @@ func formatListOutput(list []string) string {
 	}
 	return o
 }
+func formatTimeZoneOutput(timeZone string) string {
+	if timeZone == "" {
+		return "UTC"
+	}
+	return timeZone
+}
 func formatBoolOutput(active bool) string {
 	var o string
 	if active {
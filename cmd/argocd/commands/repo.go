@@ -74,6 +74,12 @@ func NewRepoAddCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 
   # Add a private Git repository on Google Cloud Sources via GCP service account credentials
   argocd repo add https://source.developers.google.com/p/my-google-cloud-project/r/my-repo --gcp-service-account-key-path service-account-key.json
+
+  # Add a large Git repository which is fetched with a shallow, blob-less partial clone
+  argocd repo add https://github.com/argoproj/argocd-example-apps --clone-depth 1 --clone-filter blob:none
+
+  # Add a private Git repository whose submodules are public, so they are initialized without its credentials
+  argocd repo add https://git.example.com/repos/repo --username git --password secret --enable-submodules --skip-submodule-creds
 `
 
 	var command = &cobra.Command{
@@ -162,6 +168,9 @@ func NewRepoAddCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 			repoOpts.Repo.GitHubAppEnterpriseBaseURL = repoOpts.GitHubAppEnterpriseBaseURL
 			repoOpts.Repo.Proxy = repoOpts.Proxy
 			repoOpts.Repo.ForceHttpBasicAuth = repoOpts.ForceHttpBasicAuth
+			if c.Flags().Changed("enable-submodules") {
+				repoOpts.Repo.EnableSubmodules = &repoOpts.EnableSubmodules
+			}
 
 			if repoOpts.Repo.Type == "helm" && repoOpts.Repo.Name == "" {
 				errors.CheckError(fmt.Errorf("Must specify --name for repos of type 'helm'"))
@@ -292,7 +301,8 @@ func NewRepoListCommand(clientOpts *argocdclient.ClientOptions) *cobra.Command {
 				err := fmt.Errorf("--refresh must be one of: 'hard'")
 				errors.CheckError(err)
 			}
-			repos, err := repoIf.ListRepositories(ctx, &repositorypkg.RepoQuery{ForceRefresh: forceRefresh})
+			// the URL output doesn't include the connection state, so there is no need to test the connections
+			repos, err := repoIf.ListRepositories(ctx, &repositorypkg.RepoQuery{ForceRefresh: forceRefresh, SkipConnectionState: output == "url"})
 			errors.CheckError(err)
 			switch output {
 			case "yaml", "json":
@@ -6,9 +6,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	argocdagent "github.com/argoproj/argo-cd/v2/cmd/argocd-agent/commands"
 	appcontroller "github.com/argoproj/argo-cd/v2/cmd/argocd-application-controller/commands"
 	applicationset "github.com/argoproj/argo-cd/v2/cmd/argocd-applicationset-controller/commands"
 	cmpserver "github.com/argoproj/argo-cd/v2/cmd/argocd-cmp-server/commands"
+	argocdcore "github.com/argoproj/argo-cd/v2/cmd/argocd-core/commands"
 	dex "github.com/argoproj/argo-cd/v2/cmd/argocd-dex/commands"
 	gitaskpass "github.com/argoproj/argo-cd/v2/cmd/argocd-git-ask-pass/commands"
 	k8sauth "github.com/argoproj/argo-cd/v2/cmd/argocd-k8s-auth/commands"
@@ -50,6 +52,10 @@ func main() {
 		command = applicationset.NewCommand()
 	case "argocd-k8s-auth":
 		command = k8sauth.NewCommand()
+	case "argocd-core":
+		command = argocdcore.NewCommand()
+	case "argocd-agent":
+		command = argocdagent.NewCommand()
 	default:
 		command = cli.NewCommand()
 	}
@@ -35,6 +35,7 @@ type AppOptions struct {
 	revision                        string
 	revisionHistoryLimit            int
 	destName                        string
+	destClusterSelector             string
 	destServer                      string
 	destNamespace                   string
 	Parameters                      []string
@@ -42,6 +43,7 @@ type AppOptions struct {
 	ignoreMissingValueFiles         bool
 	values                          string
 	releaseName                     string
+	helmNamespace                   string
 	helmSets                        []string
 	helmSetStrings                  []string
 	helmSetFiles                    []string
@@ -79,6 +81,7 @@ type AppOptions struct {
 	retryBackoffDuration            time.Duration
 	retryBackoffMaxDuration         time.Duration
 	retryBackoffFactor              int64
+	retryRefresh                    bool
 }
 
 func AddAppFlags(command *cobra.Command, opts *AppOptions) {
@@ -90,12 +93,14 @@ func AddAppFlags(command *cobra.Command, opts *AppOptions) {
 	command.Flags().IntVar(&opts.revisionHistoryLimit, "revision-history-limit", argoappv1.RevisionHistoryLimit, "How many items to keep in revision history")
 	command.Flags().StringVar(&opts.destServer, "dest-server", "", "K8s cluster URL (e.g. https://kubernetes.default.svc)")
 	command.Flags().StringVar(&opts.destName, "dest-name", "", "K8s cluster Name (e.g. minikube)")
+	command.Flags().StringVar(&opts.destClusterSelector, "dest-cluster-selector", "", "Label selector matching exactly one K8s cluster (e.g. env=staging)")
 	command.Flags().StringVar(&opts.destNamespace, "dest-namespace", "", "K8s target namespace")
 	command.Flags().StringArrayVarP(&opts.Parameters, "parameter", "p", []string{}, "set a parameter override (e.g. -p guestbook=image=example/guestbook:latest)")
 	command.Flags().StringArrayVar(&opts.valuesFiles, "values", []string{}, "Helm values file(s) to use")
 	command.Flags().BoolVar(&opts.ignoreMissingValueFiles, "ignore-missing-value-files", false, "Ignore locally missing valueFiles when setting helm template --values")
 	command.Flags().StringVar(&opts.values, "values-literal-file", "", "Filename or URL to import as a literal Helm values block")
 	command.Flags().StringVar(&opts.releaseName, "release-name", "", "Helm release-name")
+	command.Flags().StringVar(&opts.helmNamespace, "helm-namespace", "", "Helm namespace to template with (defaults to the destination namespace)")
 	command.Flags().StringVar(&opts.helmVersion, "helm-version", "", "Helm version")
 	command.Flags().BoolVar(&opts.helmPassCredentials, "helm-pass-credentials", false, "Pass credentials to all domain")
 	command.Flags().StringArrayVar(&opts.helmSets, "helm-set", []string{}, "Helm set values on the command line (can be repeated to set several values: --helm-set key1=val1 --helm-set key2=val2)")
@@ -103,7 +108,7 @@ func AddAppFlags(command *cobra.Command, opts *AppOptions) {
 	command.Flags().StringArrayVar(&opts.helmSetFiles, "helm-set-file", []string{}, "Helm set values from respective files specified via the command line (can be repeated to set several values: --helm-set-file key1=path1 --helm-set-file key2=path2)")
 	command.Flags().BoolVar(&opts.helmSkipCrds, "helm-skip-crds", false, "Skip helm crd installation step")
 	command.Flags().StringVar(&opts.project, "project", "", "Application project name")
-	command.Flags().StringVar(&opts.syncPolicy, "sync-policy", "", "Set the sync policy (one of: none, automated (aliases of automated: auto, automatic))")
+	command.Flags().StringVar(&opts.syncPolicy, "sync-policy", "", "Set the sync policy (one of: none, automated (aliases of automated: auto, automatic)). none disables automated sync, also if the project has a default sync policy")
 	command.Flags().StringArrayVar(&opts.syncOptions, "sync-option", []string{}, "Add or remove a sync option, e.g add `Prune=false`. Remove using `!` prefix, e.g. `!Prune=false`")
 	command.Flags().BoolVar(&opts.autoPrune, "auto-prune", false, "Set automatic pruning when sync is automated")
 	command.Flags().BoolVar(&opts.selfHeal, "self-heal", false, "Set self healing when sync is automated")
@@ -133,6 +138,7 @@ func AddAppFlags(command *cobra.Command, opts *AppOptions) {
 	command.Flags().DurationVar(&opts.retryBackoffDuration, "sync-retry-backoff-duration", argoappv1.DefaultSyncRetryDuration, "Sync retry backoff base duration. Input needs to be a duration (e.g. 2m, 1h)")
 	command.Flags().DurationVar(&opts.retryBackoffMaxDuration, "sync-retry-backoff-max-duration", argoappv1.DefaultSyncRetryMaxDuration, "Max sync retry backoff duration. Input needs to be a duration (e.g. 2m, 1h)")
 	command.Flags().Int64Var(&opts.retryBackoffFactor, "sync-retry-backoff-factor", argoappv1.DefaultSyncRetryFactor, "Factor multiplies the base duration after each failed sync retry")
+	command.Flags().BoolVar(&opts.retryRefresh, "sync-retry-refresh", false, "Indicates if the latest revision should be used on retry instead of the initial one")
 }
 
 func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, appOpts *AppOptions) int {
@@ -176,6 +182,8 @@ func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, ap
 			setHelmOpt(source, helmOpts{values: string(data)})
 		case "release-name":
 			setHelmOpt(source, helmOpts{releaseName: appOpts.releaseName})
+		case "helm-namespace":
+			setHelmOpt(source, helmOpts{namespace: appOpts.helmNamespace})
 		case "helm-version":
 			setHelmOpt(source, helmOpts{version: appOpts.helmVersion})
 		case "helm-pass-credentials":
@@ -210,6 +218,8 @@ func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, ap
 			source.Plugin = &argoappv1.ApplicationSourcePlugin{Name: appOpts.configManagementPlugin}
 		case "dest-name":
 			spec.Destination.Name = appOpts.destName
+		case "dest-cluster-selector":
+			spec.Destination.ClusterSelector = appOpts.destClusterSelector
 		case "dest-server":
 			spec.Destination.Server = appOpts.destServer
 		case "dest-namespace":
@@ -255,12 +265,11 @@ func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, ap
 		case "sync-policy":
 			switch appOpts.syncPolicy {
 			case "none":
-				if spec.SyncPolicy != nil {
-					spec.SyncPolicy.Automated = nil
-				}
-				if spec.SyncPolicy.IsZero() {
-					spec.SyncPolicy = nil
+				// an empty sync policy is kept, so that the default sync policy of the project doesn't apply
+				if spec.SyncPolicy == nil {
+					spec.SyncPolicy = &argoappv1.SyncPolicy{}
 				}
+				spec.SyncPolicy.Automated = nil
 			case "automated", "automatic", "auto":
 				if spec.SyncPolicy == nil {
 					spec.SyncPolicy = &argoappv1.SyncPolicy{}
@@ -270,7 +279,8 @@ func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, ap
 				log.Fatalf("Invalid sync-policy: %s", appOpts.syncPolicy)
 			}
 		case "sync-option":
-			if spec.SyncPolicy == nil {
+			inherited := spec.SyncPolicy == nil
+			if inherited {
 				spec.SyncPolicy = &argoappv1.SyncPolicy{}
 			}
 			for _, option := range appOpts.syncOptions {
@@ -282,7 +292,9 @@ func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, ap
 					spec.SyncPolicy.SyncOptions = spec.SyncPolicy.SyncOptions.AddOption(option)
 				}
 			}
-			if spec.SyncPolicy.IsZero() {
+			// an empty sync policy opts out of the default sync policy of the project, so it is only dropped if the
+			// application didn't have one
+			if inherited && spec.SyncPolicy.IsZero() {
 				spec.SyncPolicy = nil
 			}
 		case "sync-retry-limit":
@@ -297,16 +309,20 @@ func SetAppSpecOptions(flags *pflag.FlagSet, spec *argoappv1.ApplicationSpec, ap
 						MaxDuration: appOpts.retryBackoffMaxDuration.String(),
 						Factor:      pointer.Int64Ptr(appOpts.retryBackoffFactor),
 					},
+					Refresh: appOpts.retryRefresh,
 				}
 			} else if appOpts.retryLimit == 0 {
-				if spec.SyncPolicy.IsZero() {
-					spec.SyncPolicy = nil
-				} else {
+				if spec.SyncPolicy != nil {
 					spec.SyncPolicy.Retry = nil
 				}
 			} else {
 				log.Fatalf("Invalid sync-retry-limit [%d]", appOpts.retryLimit)
 			}
+		case "sync-retry-refresh":
+			if spec.SyncPolicy == nil || spec.SyncPolicy.Retry == nil {
+				log.Fatal("Cannot set --sync-retry-refresh: application not configured with sync retries")
+			}
+			spec.SyncPolicy.Retry.Refresh = appOpts.retryRefresh
 		}
 		spec.Source = source
 	})
@@ -408,6 +424,7 @@ type helmOpts struct {
 	ignoreMissingValueFiles bool
 	values                  string
 	releaseName             string
+	namespace               string
 	version                 string
 	helmSets                []string
 	helmSetStrings          []string
@@ -435,6 +452,9 @@ func setHelmOpt(src *argoappv1.ApplicationSource, opts helmOpts) {
 	if opts.releaseName != "" {
 		src.Helm.ReleaseName = opts.releaseName
 	}
+	if opts.namespace != "" {
+		src.Helm.Namespace = opts.namespace
+	}
 	if opts.version != "" {
 		src.Helm.Version = opts.version
 	}
@@ -544,6 +564,9 @@ func readApps(yml []byte, apps *[]*argoappv1.Application) error {
 		if err != nil {
 			return err
 		}
+		if strictErr := config.UnmarshalStrict([]byte(yml), &argoappv1.Application{}); strictErr != nil {
+			log.Warnf("Application %s contains fields which will be ignored: %v", app.Name, strictErr)
+		}
 	}
 
 	return err
@@ -734,3 +757,16 @@ func FilterResources(groupChanged bool, resources []*argoappv1.ResourceDiff, gro
 	}
 	return filteredObjects, nil
 }
+
+// ParseResourceActionParams parses resource action parameters given as name=value pairs
+func ParseResourceActionParams(params []string) ([]*argoappv1.ResourceActionParam, error) {
+	var res []*argoappv1.ResourceActionParam
+	for _, param := range params {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("expected parameter of the form name=value, got %q", param)
+		}
+		res = append(res, &argoappv1.ResourceActionParam{Name: parts[0], Value: parts[1]})
+	}
+	return res, nil
+}
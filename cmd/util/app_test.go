@@ -34,6 +34,11 @@ func Test_setHelmOpt(t *testing.T) {
 		setHelmOpt(&src, helmOpts{releaseName: "foo"})
 		assert.Equal(t, "foo", src.Helm.ReleaseName)
 	})
+	t.Run("Namespace", func(t *testing.T) {
+		src := v1alpha1.ApplicationSource{}
+		setHelmOpt(&src, helmOpts{namespace: "foo"})
+		assert.Equal(t, "foo", src.Helm.Namespace)
+	})
 	t.Run("HelmSets", func(t *testing.T) {
 		src := v1alpha1.ApplicationSource{}
 		setHelmOpt(&src, helmOpts{helmSets: []string{"foo=bar"}})
@@ -195,22 +200,36 @@ func Test_setAppSpecOptions(t *testing.T) {
 		assert.NoError(t, f.SetFlag("sync-policy", "auto"))
 		assert.NotNil(t, f.spec.SyncPolicy.Automated)
 
+		// an empty sync policy opts out of the default sync policy of the project
+		assert.NoError(t, f.SetFlag("sync-policy", "none"))
+		assert.NotNil(t, f.spec.SyncPolicy)
+		assert.Nil(t, f.spec.SyncPolicy.Automated)
+
+		f.spec.SyncPolicy = nil
 		assert.NoError(t, f.SetFlag("sync-policy", "none"))
-		assert.Nil(t, f.spec.SyncPolicy)
+		assert.NotNil(t, f.spec.SyncPolicy)
+		assert.Nil(t, f.spec.SyncPolicy.Automated)
 	})
 	t.Run("SyncOptions", func(t *testing.T) {
 		assert.NoError(t, f.SetFlag("sync-option", "a=1"))
 		assert.True(t, f.spec.SyncPolicy.SyncOptions.HasOption("a=1"))
 
-		// remove the options using !
+		// remove the options using !, which keeps the empty sync policy set by --sync-policy none
 		assert.NoError(t, f.SetFlag("sync-option", "!a=1"))
-		assert.Nil(t, f.spec.SyncPolicy)
+		assert.NotNil(t, f.spec.SyncPolicy)
+		assert.False(t, f.spec.SyncPolicy.SyncOptions.HasOption("a=1"))
+
+		// the sync policy stays inherited from the project if the application didn't have one
+		inherited := newAppOptionsFixture()
+		assert.NoError(t, inherited.SetFlag("sync-option", "!a=1"))
+		assert.Nil(t, inherited.spec.SyncPolicy)
 	})
 	t.Run("RetryLimit", func(t *testing.T) {
 		assert.NoError(t, f.SetFlag("sync-retry-limit", "5"))
 		assert.True(t, f.spec.SyncPolicy.Retry.Limit == 5)
 
 		assert.NoError(t, f.SetFlag("sync-retry-limit", "0"))
+		assert.NotNil(t, f.spec.SyncPolicy)
 		assert.Nil(t, f.spec.SyncPolicy.Retry)
 	})
 	t.Run("Kustomize", func(t *testing.T) {
@@ -421,3 +440,16 @@ func TestFilterResources(t *testing.T) {
 		assert.Nil(t, filteredResources)
 	})
 }
+
+func TestParseResourceActionParams(t *testing.T) {
+	params, err := ParseResourceActionParams([]string{"replicas=3", "selector=app=guestbook", "reason="})
+	assert.NoError(t, err)
+	assert.Equal(t, []*v1alpha1.ResourceActionParam{
+		{Name: "replicas", Value: "3"},
+		{Name: "selector", Value: "app=guestbook"},
+		{Name: "reason", Value: ""},
+	}, params)
+
+	_, err = ParseResourceActionParams([]string{"replicas"})
+	assert.ErrorContains(t, err, "expected parameter of the form name=value")
+}
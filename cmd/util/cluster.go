@@ -2,7 +2,10 @@ package util
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
@@ -157,6 +160,11 @@ type ClusterOptions struct {
 	ExecProviderAPIVersion  string
 	ExecProviderInstallHint string
 	ClusterEndpoint         string
+	RepoServer              string
+	ProxyUrl                string
+	KubeconfigProxy         bool
+	AdditionalCAFile        string
+	ServerAlias             string
 }
 
 // InClusterEndpoint returns true if ArgoCD should reference the in-cluster
@@ -165,6 +173,55 @@ func (o ClusterOptions) InClusterEndpoint() bool {
 	return o.InCluster || o.ClusterEndpoint == string(KubeInternalEndpoint)
 }
 
+// ServerURL returns the server URL of the cluster with the given API server URL. The alias of the options is appended
+// as fragment, which allows registering the same cluster several times.
+func (o ClusterOptions) ServerURL(server string) string {
+	if o.ServerAlias == "" {
+		return server
+	}
+	return server + "#" + o.ServerAlias
+}
+
+// SetConnectionOptions configures the cluster to connect through the proxy of the options, or through the proxy of the
+// kubeconfig cluster if requested, and to trust the certificate authorities of the additional CA file in addition to the
+// ones of the kubeconfig, or to the system ones of Argo CD if the kubeconfig cluster doesn't configure any.
+func (o ClusterOptions) SetConnectionOptions(clst *argoappv1.Cluster, conf *rest.Config) error {
+	if o.ProxyUrl != "" {
+		proxyURL, err := url.Parse(o.ProxyUrl)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL %s: %w", o.ProxyUrl, err)
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+			return fmt.Errorf("invalid proxy URL %s: scheme must be one of http, https or socks5", o.ProxyUrl)
+		}
+		clst.Config.ProxyUrl = o.ProxyUrl
+	} else if o.KubeconfigProxy && conf.Proxy != nil {
+		// the proxy of the kubeconfig cluster is set by its proxy-url field
+		hostURL, err := url.Parse(conf.Host)
+		if err != nil {
+			return fmt.Errorf("invalid server URL %s: %w", conf.Host, err)
+		}
+		proxyURL, err := conf.Proxy(&http.Request{URL: hostURL})
+		if err != nil {
+			return fmt.Errorf("error getting proxy of kubeconfig cluster: %w", err)
+		}
+		if proxyURL != nil {
+			clst.Config.ProxyUrl = proxyURL.String()
+		}
+	}
+	if o.AdditionalCAFile != "" {
+		data, err := os.ReadFile(o.AdditionalCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading additional CA file: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			return fmt.Errorf("additional CA file %s doesn't contain any PEM encoded certificate", o.AdditionalCAFile)
+		}
+		clst.Config.TLSClientConfig.AdditionalCAData = data
+	}
+	return nil
+}
+
 func AddClusterFlags(command *cobra.Command, opts *ClusterOptions) {
 	command.Flags().BoolVar(&opts.InCluster, "in-cluster", false, "Indicates Argo CD resides inside this cluster and should connect using the internal k8s hostname (kubernetes.default.svc)")
 	command.Flags().StringVar(&opts.AwsClusterName, "aws-cluster-name", "", "AWS Cluster name if set then aws cli eks token command will be used to access cluster")
@@ -180,4 +237,9 @@ func AddClusterFlags(command *cobra.Command, opts *ClusterOptions) {
 	command.Flags().StringVar(&opts.ExecProviderAPIVersion, "exec-command-api-version", "", "Preferred input version of the ExecInfo for the --exec-command executable")
 	command.Flags().StringVar(&opts.ExecProviderInstallHint, "exec-command-install-hint", "", "Text shown to the user when the --exec-command executable doesn't seem to be present")
 	command.Flags().StringVar(&opts.ClusterEndpoint, "cluster-endpoint", "", "Cluster endpoint to use. Can be one of the following: 'kubeconfig', 'kube-public', or 'internal'.")
+	command.Flags().StringVar(&opts.RepoServer, "repo-server", "", "Address of a repo server deployed close to the cluster which generates the manifests of applications deployed to the cluster")
+	command.Flags().StringVar(&opts.ProxyUrl, "proxy-url", "", "URL of the HTTP(S) or SOCKS5 proxy used to connect to the cluster")
+	command.Flags().BoolVar(&opts.KubeconfigProxy, "kubeconfig-proxy", false, "Connect to the cluster through the proxy-url of the kubeconfig cluster, unless --proxy-url is set")
+	command.Flags().StringVar(&opts.ServerAlias, "server-alias", "", "Alias which is appended as fragment to the server URL of the cluster. Allows registering the same cluster several times, e.g. restricted to different namespaces with different credentials")
+	command.Flags().StringVar(&opts.AdditionalCAFile, "additional-ca-file", "", "Path to a PEM encoded CA bundle which is trusted in addition to the certificate authority of the kubeconfig cluster, or to the system certificate authorities of Argo CD if it has none, e.g. a private CA of a proxy")
 }
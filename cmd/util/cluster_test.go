@@ -1,6 +1,9 @@
 package util
 
 import (
+	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"testing"
 
@@ -76,6 +79,67 @@ func Test_newCluster(t *testing.T) {
 	assert.Nil(t, clusterWithBearerToken.Annotations)
 }
 
+func Test_newCluster_Proxy(t *testing.T) {
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	assert.NoError(t, err)
+	conf := &rest.Config{
+		Host:  "https://test-endpoint.example.com",
+		Proxy: http.ProxyURL(proxyURL),
+	}
+
+	t.Run("KubeconfigProxyNotRequested", func(t *testing.T) {
+		cluster := NewCluster("test-cluster", nil, false, conf, "test-bearer-token", nil, nil, nil, nil)
+		err := ClusterOptions{}.SetConnectionOptions(cluster, conf)
+		assert.NoError(t, err)
+		assert.Empty(t, cluster.Config.ProxyUrl)
+	})
+
+	t.Run("KubeconfigProxy", func(t *testing.T) {
+		cluster := NewCluster("test-cluster", nil, false, conf, "test-bearer-token", nil, nil, nil, nil)
+		err := ClusterOptions{KubeconfigProxy: true}.SetConnectionOptions(cluster, conf)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://proxy.example.com:3128", cluster.Config.ProxyUrl)
+	})
+
+	t.Run("ProxyOverridesKubeconfigProxy", func(t *testing.T) {
+		cluster := NewCluster("test-cluster", nil, false, conf, "test-bearer-token", nil, nil, nil, nil)
+		err := ClusterOptions{KubeconfigProxy: true, ProxyUrl: "socks5://proxy.example.com:1080"}.SetConnectionOptions(cluster, conf)
+		assert.NoError(t, err)
+		assert.Equal(t, "socks5://proxy.example.com:1080", cluster.Config.ProxyUrl)
+	})
+}
+
+func TestClusterOptions_SetConnectionOptions(t *testing.T) {
+	caFile := "../../test/fixture/certs/argocd-test-ca.crt"
+	ca, err := os.ReadFile(caFile)
+	assert.NoError(t, err)
+	conf := &rest.Config{Host: "https://test-endpoint.example.com"}
+
+	t.Run("ProxyAndAdditionalCA", func(t *testing.T) {
+		cluster := &v1alpha1.Cluster{Config: v1alpha1.ClusterConfig{TLSClientConfig: v1alpha1.TLSClientConfig{CAData: []byte("kubeconfig-ca-data")}}}
+		err := ClusterOptions{ProxyUrl: "https://proxy.example.com", AdditionalCAFile: caFile}.SetConnectionOptions(cluster, conf)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://proxy.example.com", cluster.Config.ProxyUrl)
+		assert.Equal(t, "kubeconfig-ca-data", string(cluster.Config.CAData))
+		assert.Equal(t, ca, cluster.Config.AdditionalCAData)
+	})
+
+	t.Run("InvalidProxyScheme", func(t *testing.T) {
+		err := ClusterOptions{ProxyUrl: "ftp://proxy.example.com"}.SetConnectionOptions(&v1alpha1.Cluster{}, conf)
+		assert.ErrorContains(t, err, "scheme must be one of http, https or socks5")
+	})
+
+	t.Run("InvalidAdditionalCA", func(t *testing.T) {
+		err := ClusterOptions{AdditionalCAFile: "./testdata/test.key.pem"}.SetConnectionOptions(&v1alpha1.Cluster{}, conf)
+		assert.ErrorContains(t, err, "doesn't contain any PEM encoded certificate")
+	})
+}
+
+func TestClusterOptions_ServerURL(t *testing.T) {
+	assert.Equal(t, "https://mycluster.example.com", ClusterOptions{}.ServerURL("https://mycluster.example.com"))
+	assert.Equal(t, "https://mycluster.example.com#team-a", ClusterOptions{ServerAlias: "team-a"}.ServerURL("https://mycluster.example.com"))
+}
+
 func TestGetKubePublicEndpoint(t *testing.T) {
 	cases := []struct {
 		name             string
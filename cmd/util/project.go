@@ -3,6 +3,7 @@ package util
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
 	"os"
@@ -122,9 +123,23 @@ func GetOrphanedResourcesSettings(flagSet *pflag.FlagSet, opts ProjectOpts) *v1a
 	return nil
 }
 
+func readProj(data []byte, proj *v1alpha1.AppProject) error {
+	err := config.Unmarshal(data, &proj)
+	if err != nil {
+		return err
+	}
+	if strictErr := config.UnmarshalStrict(data, &v1alpha1.AppProject{}); strictErr != nil {
+		log.Printf("Warning: project %s contains fields which will be ignored: %v", proj.Name, strictErr)
+	}
+	return nil
+}
+
 func readProjFromStdin(proj *v1alpha1.AppProject) error {
 	reader := bufio.NewReader(os.Stdin)
-	err := config.UnmarshalReader(reader, &proj)
+	data, err := io.ReadAll(reader)
+	if err == nil {
+		err = readProj(data, proj)
+	}
 	if err != nil {
 		return fmt.Errorf("unable to read manifest from stdin: %v", err)
 	}
@@ -132,11 +147,15 @@ func readProjFromStdin(proj *v1alpha1.AppProject) error {
 }
 
 func readProjFromURI(fileURL string, proj *v1alpha1.AppProject) error {
+	var data []byte
 	parsedURL, err := url.ParseRequestURI(fileURL)
 	if err != nil || !(parsedURL.Scheme == "http" || parsedURL.Scheme == "https") {
-		err = config.UnmarshalLocalFile(fileURL, &proj)
+		data, err = os.ReadFile(fileURL)
 	} else {
-		err = config.UnmarshalRemoteFile(fileURL, &proj)
+		data, err = config.ReadRemoteFile(fileURL)
+	}
+	if err == nil {
+		err = readProj(data, proj)
 	}
 	if err != nil {
 		return fmt.Errorf("error reading proj from uri: %w", err)
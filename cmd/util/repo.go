@@ -24,6 +24,7 @@ type RepoOptions struct {
 	Proxy                          string
 	GCPServiceAccountKeyPath       string
 	ForceHttpBasicAuth             bool
+	EnableSubmodules               bool
 }
 
 func AddRepoFlags(command *cobra.Command, opts *RepoOptions) {
@@ -46,4 +47,8 @@ func AddRepoFlags(command *cobra.Command, opts *RepoOptions) {
 	command.Flags().StringVar(&opts.Proxy, "proxy", "", "use proxy to access repository")
 	command.Flags().StringVar(&opts.GCPServiceAccountKeyPath, "gcp-service-account-key-path", "", "service account key for the Google Cloud Platform")
 	command.Flags().BoolVar(&opts.ForceHttpBasicAuth, "force-http-basic-auth", false, "whether to force use of basic auth when connecting repository via HTTP")
+	command.Flags().Int64Var(&opts.Repo.CloneDepth, "clone-depth", 0, "limit the fetched history of the repository to the given number of commits, 0 fetches the full history")
+	command.Flags().StringVar(&opts.Repo.CloneFilter, "clone-filter", "", "partial clone filter used when fetching the repository (e.g. blob:none)")
+	command.Flags().BoolVar(&opts.EnableSubmodules, "enable-submodules", false, "whether to initialize the submodules of the repository on checkout, defaults to the setting of the repo server if not specified")
+	command.Flags().BoolVar(&opts.Repo.SkipSubmoduleCreds, "skip-submodule-creds", false, "fetch the submodules of the repository without the credentials of the repository")
 }
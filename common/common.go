@@ -49,11 +49,13 @@ const (
 	DefaultPortArgoCDMetrics          = 8082
 	DefaultPortArgoCDAPIServerMetrics = 8083
 	DefaultPortRepoServerMetrics      = 8084
+	DefaultPortAgentProxy             = 8085
 )
 
 // DefaultAddressAPIServer for ArgoCD components
 const (
 	DefaultAddressAdminDashboard    = "localhost"
+	DefaultAddressAgentProxy        = "localhost"
 	DefaultAddressAPIServer         = "0.0.0.0"
 	DefaultAddressAPIServerMetrics  = "0.0.0.0"
 	DefaultAddressRepoServer        = "0.0.0.0"
@@ -108,7 +110,19 @@ const (
 	LegacyShardingAlgorithm = "legacy"
 	// RoundRobinShardingAlgorithm is a flag value that can be opted for Sharding Algorithm it uses an equal distribution accross all shards
 	RoundRobinShardingAlgorithm = "round-robin"
-	DefaultShardingAlgorithm    = LegacyShardingAlgorithm
+	// ProjectShardingAlgorithm is a flag value that can be opted for Sharding Algorithm it distributes applications across shards by their project
+	ProjectShardingAlgorithm = "project"
+	// LabelShardingAlgorithm is a flag value that can be opted for Sharding Algorithm it distributes applications across shards by the value of a label
+	LabelShardingAlgorithm   = "label"
+	DefaultShardingAlgorithm = LegacyShardingAlgorithm
+	// DefaultShardingLabel is the default label which assigns applications to shards when using the label Sharding Algorithm
+	DefaultShardingLabel = "argocd.argoproj.io/controller-shard"
+	// ApplicationControllerName is the name of the application controller StatefulSet, whose replicas are counted by the
+	// dynamic cluster distribution
+	ApplicationControllerName = "argocd-application-controller"
+	// DefaultShardReplicasCheckInterval is the default interval in which the dynamic cluster distribution checks the
+	// number of application controller replicas
+	DefaultShardReplicasCheckInterval = 30 * time.Second
 )
 
 // Dex related constants
@@ -148,6 +162,13 @@ const (
 	LabelValueSecretTypeRepository = "repository"
 	// LabelValueSecretTypeRepoCreds indicates a secret type of repository credentials
 	LabelValueSecretTypeRepoCreds = "repo-creds"
+	// LabelValueSecretTypeRenderedManifests indicates a secret type of manifests rendered outside of Argo CD
+	LabelValueSecretTypeRenderedManifests = "rendered-manifests"
+	// LabelKeyClusterDiscoveredBy contains the name of the cloud provider a cluster secret was discovered from, e.g. 'eks'
+	LabelKeyClusterDiscoveredBy = "argocd.argoproj.io/discovered-by"
+	// AnnotationKeyClusterDiscoveryScope contains the scope of the discovery which created a cluster secret, e.g. the region
+	// and the tags of the discovered EKS clusters
+	AnnotationKeyClusterDiscoveryScope = "argocd.argoproj.io/discovery-scope"
 
 	// AnnotationKeyAppInstance is the Argo CD application name is used as the instance name
 	AnnotationKeyAppInstance = "argocd.argoproj.io/tracking-id"
@@ -173,6 +194,14 @@ const (
 	// AnnotationKeyAppSkipReconcile tells the Application to skip the Application controller reconcile.
 	// Skip reconcile when the value is "true" or any other string values that can be strconv.ParseBool() to be true.
 	AnnotationKeyAppSkipReconcile = "argocd.argoproj.io/skip-reconcile"
+
+	// AnnotationKeyUnusedSince records when a credential template or certificate was first found unused by `argocd admin repo gc`
+	AnnotationKeyUnusedSince = "argocd.argoproj.io/unused-since"
+
+	// AnnotationKeyExternalManifests tells the application controller to use the manifests submitted for the Application
+	// by an external system, e.g. a CI pipeline, instead of generating them.
+	// The manifests are used when the value is "true" or any other string values that can be strconv.ParseBool() to be true.
+	AnnotationKeyExternalManifests = "argocd.argoproj.io/external-manifests"
 )
 
 // Environment variables for tuning and debugging Argo CD
@@ -209,14 +238,23 @@ const (
 	EnvControllerReplicas = "ARGOCD_CONTROLLER_REPLICAS"
 	// EnvControllerShard is the shard number that should be handled by controller
 	EnvControllerShard = "ARGOCD_CONTROLLER_SHARD"
-	// EnvControllerShardingAlgorithm is the distribution sharding algorithm to be used: legacy or round-robin
+	// EnvControllerShardingAlgorithm is the distribution sharding algorithm to be used: legacy, round-robin, project or label
 	EnvControllerShardingAlgorithm = "ARGOCD_CONTROLLER_SHARDING_ALGORITHM"
+	// EnvControllerShardingLabel is the label which assigns applications to shards when using the label sharding algorithm
+	EnvControllerShardingLabel = "ARGOCD_CONTROLLER_SHARDING_LABEL"
+	// EnvEnableDynamicClusterDistribution enables rebalancing the clusters across shards when the number of application
+	// controller replicas changes
+	EnvEnableDynamicClusterDistribution = "ARGOCD_ENABLE_DYNAMIC_CLUSTER_DISTRIBUTION"
 	// EnvEnableGRPCTimeHistogramEnv enables gRPC metrics collection
 	EnvEnableGRPCTimeHistogramEnv = "ARGOCD_ENABLE_GRPC_TIME_HISTOGRAM"
 	// EnvGithubAppCredsExpirationDuration controls the caching of Github app credentials. This value is in minutes (default: 60)
 	EnvGithubAppCredsExpirationDuration = "ARGOCD_GITHUB_APP_CREDS_EXPIRATION_DURATION"
 	// EnvHelmIndexCacheDuration controls how the helm repository index file is cached for (default: 0)
 	EnvHelmIndexCacheDuration = "ARGOCD_HELM_INDEX_CACHE_DURATION"
+	// EnvHelmV2BinaryPath is the path of the Helm 2 binary used for applications which request Helm version v2 (default: helm2)
+	EnvHelmV2BinaryPath = "ARGOCD_HELM_V2_BINARY_PATH"
+	// EnvHelmV3BinaryPath is the path of the Helm 3 binary used for applications which request Helm version v3 (default: helm)
+	EnvHelmV3BinaryPath = "ARGOCD_HELM_V3_BINARY_PATH"
 	// EnvAppConfigPath allows to override the configuration path for repo server
 	EnvAppConfigPath = "ARGOCD_APP_CONF_PATH"
 	// EnvLogFormat log format that is defined by `--logformat` option
@@ -233,6 +271,24 @@ const (
 	EnvCMPWorkDir = "ARGOCD_CMP_WORKDIR"
 	// EnvGPGDataPath overrides the location where GPG keyring for signature verification is stored
 	EnvGPGDataPath = "ARGOCD_GPG_DATA_PATH"
+	// EnvRepoConnectionTestTimeout is the maximum duration of a single repository connection test run by the API server (default: 30s)
+	EnvRepoConnectionTestTimeout = "ARGOCD_SERVER_REPO_CONNECTION_TEST_TIMEOUT"
+	// EnvRepoConnectionTestParallelism is the maximum number of repository connection tests run concurrently by the API server (default: 10)
+	EnvRepoConnectionTestParallelism = "ARGOCD_SERVER_REPO_CONNECTION_TEST_PARALLELISM"
+	// EnvRepoCredentialsRevalidationInterval is the interval in which the API server revalidates the credentials of all repositories, or 0 to disable it (default: 1h)
+	EnvRepoCredentialsRevalidationInterval = "ARGOCD_SERVER_REPO_CREDENTIALS_REVALIDATION_INTERVAL"
+	// EnvRepoCredentialsRevalidationHostQPS is the maximum number of credential revalidations per second against a single repository host (default: 1)
+	EnvRepoCredentialsRevalidationHostQPS = "ARGOCD_SERVER_REPO_CREDENTIALS_REVALIDATION_HOST_QPS"
+	// EnvRepoCredentialsExpiryWarning is how long before the expiry of repository credentials warnings are emitted (default: 168h)
+	EnvRepoCredentialsExpiryWarning = "ARGOCD_SERVER_REPO_CREDENTIALS_EXPIRY_WARNING"
+	// EnvFaultInjectionRepoServerDelay is an artificial delay added to every repo server call, for testing only (default: 0)
+	EnvFaultInjectionRepoServerDelay = "ARGOCD_FAULT_INJECTION_REPO_SERVER_DELAY"
+	// EnvFaultInjectionRepoServerFailureRate is the share of repo server calls which fail with an injected error, for testing only (default: 0)
+	EnvFaultInjectionRepoServerFailureRate = "ARGOCD_FAULT_INJECTION_REPO_SERVER_FAILURE_RATE"
+	// EnvFaultInjectionCacheDelay is an artificial delay added to every cache operation, for testing only (default: 0)
+	EnvFaultInjectionCacheDelay = "ARGOCD_FAULT_INJECTION_CACHE_DELAY"
+	// EnvFaultInjectionCacheFailureRate is the share of cache operations which fail with an injected error, for testing only (default: 0)
+	EnvFaultInjectionCacheFailureRate = "ARGOCD_FAULT_INJECTION_CACHE_FAILURE_RATE"
 )
 
 // Config Management Plugin related constants
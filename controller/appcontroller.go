@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"math/rand"
 	"net/http"
 	"reflect"
 	"runtime/debug"
@@ -109,7 +110,12 @@ type ApplicationController struct {
 	stateCache                    statecache.LiveStateCache
 	statusRefreshTimeout          time.Duration
 	statusHardRefreshTimeout      time.Duration
+	statusRefreshJitter           time.Duration
 	selfHealTimeout               time.Duration
+	selfHealBackoff               *wait.Backoff
+	selfHealAttempts              map[string]int
+	selfHealAttemptsMutex         *sync.Mutex
+	operationMaxDuration          time.Duration
 	repoClientset                 apiclient.Clientset
 	db                            db.ArgoDB
 	settingsMgr                   *settings_util.SettingsManager
@@ -118,6 +124,8 @@ type ApplicationController struct {
 	metricsServer                 *metrics.MetricsServer
 	kubectlSemaphore              *semaphore.Weighted
 	clusterFilter                 func(cluster *appv1.Cluster) bool
+	appFilter                     func(app *appv1.Application) bool
+	shard                         int
 	projByNameCache               sync.Map
 	applicationNamespaces         []string
 }
@@ -129,20 +137,26 @@ func NewApplicationController(
 	kubeClientset kubernetes.Interface,
 	applicationClientset appclientset.Interface,
 	repoClientset apiclient.Clientset,
+	delegatedRepoServers []string,
 	argoCache *appstatecache.Cache,
 	kubectl kube.Kubectl,
 	appResyncPeriod time.Duration,
 	appHardResyncPeriod time.Duration,
+	appResyncJitter time.Duration,
 	selfHealTimeout time.Duration,
+	selfHealBackoff *wait.Backoff,
+	operationMaxDuration time.Duration,
 	metricsPort int,
 	metricsCacheExpiration time.Duration,
 	metricsApplicationLabels []string,
 	kubectlParallelismLimit int64,
 	persistResourceHealth bool,
 	clusterFilter func(cluster *appv1.Cluster) bool,
+	appFilter func(app *appv1.Application) bool,
+	shard int,
 	applicationNamespaces []string,
 ) (*ApplicationController, error) {
-	log.Infof("appResyncPeriod=%v, appHardResyncPeriod=%v", appResyncPeriod, appHardResyncPeriod)
+	log.Infof("appResyncPeriod=%v, appHardResyncPeriod=%v, appResyncJitter=%v", appResyncPeriod, appHardResyncPeriod, appResyncJitter)
 	db := db.NewDB(namespace, settingsMgr, kubeClientset)
 	ctrl := ApplicationController{
 		cache:                         argoCache,
@@ -158,12 +172,19 @@ func NewApplicationController(
 		db:                            db,
 		statusRefreshTimeout:          appResyncPeriod,
 		statusHardRefreshTimeout:      appHardResyncPeriod,
+		statusRefreshJitter:           appResyncJitter,
 		refreshRequestedApps:          make(map[string]CompareWith),
 		refreshRequestedAppsMutex:     &sync.Mutex{},
 		auditLogger:                   argo.NewAuditLogger(namespace, kubeClientset, "argocd-application-controller"),
 		settingsMgr:                   settingsMgr,
 		selfHealTimeout:               selfHealTimeout,
+		selfHealBackoff:               selfHealBackoff,
+		selfHealAttempts:              make(map[string]int),
+		selfHealAttemptsMutex:         &sync.Mutex{},
+		operationMaxDuration:          operationMaxDuration,
 		clusterFilter:                 clusterFilter,
+		appFilter:                     appFilter,
+		shard:                         shard,
 		projByNameCache:               sync.Map{},
 		applicationNamespaces:         applicationNamespaces,
 	}
@@ -191,6 +212,11 @@ func NewApplicationController(
 					ctrl.InvalidateProjectsCache(projMeta.GetName())
 				}
 			}
+			oldProj, oldOK := old.(*appv1.AppProject)
+			newProj, newOK := new.(*appv1.AppProject)
+			if oldOK && newOK && automatedSyncEnabled(oldProj.Spec.DefaultSyncPolicy, newProj.Spec.DefaultSyncPolicy) {
+				ctrl.refreshAppsWithDefaultSyncPolicy(newProj)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
@@ -216,7 +242,7 @@ func NewApplicationController(
 		}
 	}
 	stateCache := statecache.NewLiveStateCache(db, appInformer, ctrl.settingsMgr, kubectl, ctrl.metricsServer, ctrl.handleObjectUpdated, clusterFilter, argo.NewResourceTracking())
-	appStateManager := NewAppStateManager(db, applicationClientset, repoClientset, namespace, kubectl, ctrl.settingsMgr, stateCache, projInformer, ctrl.metricsServer, argoCache, ctrl.statusRefreshTimeout, argo.NewResourceTracking(), persistResourceHealth)
+	appStateManager := NewAppStateManager(db, applicationClientset, repoClientset, namespace, kubectl, ctrl.settingsMgr, stateCache, projInformer, ctrl.metricsServer, argoCache, ctrl.statusRefreshTimeout, argo.NewResourceTracking(), persistResourceHealth, delegatedRepoServers)
 	ctrl.appInformer = appInformer
 	ctrl.appLister = appLister
 	ctrl.projInformer = projInformer
@@ -316,6 +342,18 @@ func (ctrl *ApplicationController) getAppProj(app *appv1.Application) (*appv1.Ap
 	return proj, nil
 }
 
+// getAppSyncPolicy returns the sync policy of the application, which defaults to the sync policy of its project
+func (ctrl *ApplicationController) getAppSyncPolicy(app *appv1.Application) *appv1.SyncPolicy {
+	if app.Spec.SyncPolicy != nil {
+		return app.Spec.SyncPolicy
+	}
+	proj, err := ctrl.getAppProj(app)
+	if err != nil {
+		return nil
+	}
+	return proj.GetSyncPolicy(app)
+}
+
 func (ctrl *ApplicationController) handleObjectUpdated(managedByApp map[string]bool, ref v1.ObjectReference) {
 	// if namespaced resource is not managed by any app it might be orphaned resource of some other apps
 	if len(managedByApp) == 0 && ref.Namespace != "" {
@@ -431,12 +469,18 @@ func (ctrl *ApplicationController) getResourceTree(a *appv1.Application, managed
 
 	orphanedNodesMap := make(map[kube.ResourceKey]appv1.ResourceNode)
 	warnOrphaned := true
+	errorOrphaned := false
 	if proj.Spec.OrphanedResources != nil {
-		orphanedNodesMap, err = ctrl.stateCache.GetNamespaceTopLevelResources(a.Spec.Destination.Server, a.Spec.Destination.Namespace)
+		destinationNamespace, err := argo.GetDestinationNamespace(context.Background(), &a.Spec, ctrl.db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve destination namespace: %w", err)
+		}
+		orphanedNodesMap, err = ctrl.stateCache.GetNamespaceTopLevelResources(a.Spec.Destination.Server, destinationNamespace)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get namespace top-level resources: %w", err)
 		}
 		warnOrphaned = proj.Spec.OrphanedResources.IsWarn()
+		errorOrphaned = proj.Spec.OrphanedResources.IsError()
 	}
 	for i := range managedResources {
 		managedResource := managedResources[i]
@@ -514,13 +558,21 @@ func (ctrl *ApplicationController) getResourceTree(a *appv1.Application, managed
 		}
 	}
 	var conditions []appv1.ApplicationCondition
-	if len(orphanedNodes) > 0 && warnOrphaned {
+	if len(orphanedNodes) > 0 && errorOrphaned {
+		conditions = []appv1.ApplicationCondition{{
+			Type:    appv1.ApplicationConditionOrphanedResourceError,
+			Message: fmt.Sprintf("Application has %d orphaned resources", len(orphanedNodes)),
+		}}
+	} else if len(orphanedNodes) > 0 && warnOrphaned {
 		conditions = []appv1.ApplicationCondition{{
 			Type:    appv1.ApplicationConditionOrphanedResourceWarning,
 			Message: fmt.Sprintf("Application has %d orphaned resources", len(orphanedNodes)),
 		}}
 	}
-	a.Status.SetConditions(conditions, map[appv1.ApplicationConditionType]bool{appv1.ApplicationConditionOrphanedResourceWarning: true})
+	a.Status.SetConditions(conditions, map[appv1.ApplicationConditionType]bool{
+		appv1.ApplicationConditionOrphanedResourceWarning: true,
+		appv1.ApplicationConditionOrphanedResourceError:   true,
+	})
 	sort.Slice(orphanedNodes, func(i, j int) bool {
 		return orphanedNodes[i].ResourceRef.String() < orphanedNodes[j].ResourceRef.String()
 	})
@@ -621,6 +673,10 @@ func (ctrl *ApplicationController) getAppHosts(a *appv1.Application, appNodes []
 }
 
 func (ctrl *ApplicationController) hideSecretData(app *appv1.Application, comparisonResult *comparisonResult) ([]*appv1.ResourceDiff, error) {
+	maskingRules, err := ctrl.settingsMgr.GetMaskingRules()
+	if err != nil {
+		return nil, fmt.Errorf("error getting masking rules: %s", err)
+	}
 	items := make([]*appv1.ResourceDiff, len(comparisonResult.managedResources))
 	for i := range comparisonResult.managedResources {
 		res := comparisonResult.managedResources[i]
@@ -636,11 +692,18 @@ func (ctrl *ApplicationController) hideSecretData(app *appv1.Application, compar
 		target := res.Target
 		live := res.Live
 		resDiff := res.Diff
-		if res.Kind == kube.SecretKind && res.Group == "" {
+		isSecret := res.Kind == kube.SecretKind && res.Group == ""
+		if isSecret || len(argo.MaskingRulesFor(maskingRules, res.Group, res.Kind)) > 0 {
 			var err error
-			target, live, err = diff.HideSecretData(res.Target, res.Live)
+			if isSecret {
+				target, live, err = diff.HideSecretData(res.Target, res.Live)
+				if err != nil {
+					return nil, fmt.Errorf("error hiding secret data: %s", err)
+				}
+			}
+			target, live, err = argo.MaskResourceData(maskingRules, target, live)
 			if err != nil {
-				return nil, fmt.Errorf("error hiding secret data: %s", err)
+				return nil, fmt.Errorf("error masking resource data: %s", err)
 			}
 			compareOptions, err := ctrl.settingsMgr.GetResourceCompareOptions()
 			if err != nil {
@@ -1033,7 +1096,19 @@ func (ctrl *ApplicationController) finalizeApplicationDeletion(app *appv1.Applic
 			}
 		}
 
-		config := metrics.AddMetricsTransportWrapper(ctrl.metricsServer, app, cluster.RESTConfig())
+		clusterRESTConfig, err := cluster.RESTConfig()
+		if err != nil {
+			return nil, err
+		}
+		config := metrics.AddMetricsTransportWrapper(ctrl.metricsServer, app, clusterRESTConfig)
+		// the resources are deleted with the permissions which they were synced with
+		destinationNamespace, err := argo.GetDestinationNamespace(context.Background(), &app.Spec, ctrl.db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve destination namespace: %w", err)
+		}
+		if err := argo.ImpersonateServiceAccount(config, proj, app.Spec.Destination.Server, destinationNamespace); err != nil {
+			return nil, err
+		}
 
 		filteredObjs := FilterObjectsForDeletion(objs)
 
@@ -1147,6 +1222,7 @@ func (ctrl *ApplicationController) processRequestedAppOperation(app *appv1.Appli
 		}
 	}()
 	terminating := false
+	stuck := false
 	if isOperationInProgress(app) {
 		state = app.Status.OperationState.DeepCopy()
 		terminating = state.Phase == synccommon.OperationTerminating
@@ -1168,9 +1244,28 @@ func (ctrl *ApplicationController) processRequestedAppOperation(app *appv1.Appli
 				// retrying operation. remove previous failure time in app since it is used as a trigger
 				// that previous failed and operation should be retried
 				state.FinishedAt = nil
+				// the maximum operation duration applies to the retried attempt from its start
+				state.StartedAt = metav1.Now()
 				ctrl.setOperationState(app, state)
 				// Get rid of sync results and null out previous operation completion time
 				state.SyncResult = nil
+				if state.Operation.Retry.Refresh && state.Operation.Sync != nil {
+					useLatestRevisions(app, state.Operation.Sync)
+				}
+			}
+		} else if ctrl.isOperationStuck(state) {
+			// the operation won't make progress anymore, e.g. because a hook it waits for was deleted out-of-band, so
+			// it is failed instead of being resumed, which also allows it to be retried
+			stuck = true
+			state.Phase = synccommon.OperationFailed
+			state.Message = stuckOperationMessage(state, ctrl.operationMaxDuration)
+			logCtx.Warnf("Failing stuck operation: %s", state.Message)
+			// running hooks are deleted so that a retry does not run alongside or collide with them
+			if err := ctrl.deleteRunningHooks(app, state); err != nil {
+				logCtx.Errorf("Failed to delete running hooks of stuck operation: %v", err)
+				state.Message = fmt.Sprintf("%s (failed to delete running hooks: %v)", state.Message, err)
+				// don't retry while the hooks of the stuck attempt may still be running
+				terminating = true
 			}
 		} else {
 			logCtx.Infof("Resuming in-progress operation. phase: %s, message: %s", state.Phase, state.Message)
@@ -1181,11 +1276,13 @@ func (ctrl *ApplicationController) processRequestedAppOperation(app *appv1.Appli
 		logCtx.Infof("Initialized new operation: %v", *app.Operation)
 	}
 
-	if err := argo.ValidateDestination(context.Background(), &app.Spec.Destination, ctrl.db); err != nil {
-		state.Phase = synccommon.OperationFailed
-		state.Message = err.Error()
-	} else {
-		ctrl.appStateManager.SyncAppState(app, state)
+	if !stuck {
+		if err := argo.ValidateDestination(context.Background(), &app.Spec.Destination, ctrl.db); err != nil {
+			state.Phase = synccommon.OperationFailed
+			state.Message = err.Error()
+		} else {
+			ctrl.appStateManager.SyncAppState(app, state)
+		}
 	}
 
 	// Check whether application is allowed to use project
@@ -1245,6 +1342,104 @@ func (ctrl *ApplicationController) processRequestedAppOperation(app *appv1.Appli
 	}
 }
 
+// useLatestRevisions makes a retried sync operation use the revisions the application was compared against most
+// recently, instead of the revisions resolved for the initial attempt
+func useLatestRevisions(app *appv1.Application, syncOp *appv1.SyncOperation) {
+	if app.Spec.HasMultipleSources() {
+		if len(app.Status.Sync.Revisions) > 0 {
+			syncOp.Revisions = app.Status.Sync.Revisions
+		}
+	} else if app.Status.Sync.Revision != "" {
+		syncOp.Revision = app.Status.Sync.Revision
+	}
+}
+
+// isOperationStuck returns whether the operation is running or terminating for longer than the maximum operation
+// duration
+func (ctrl *ApplicationController) isOperationStuck(state *appv1.OperationState) bool {
+	if ctrl.operationMaxDuration <= 0 || time.Since(state.StartedAt.Time) <= ctrl.operationMaxDuration {
+		return false
+	}
+	return state.Phase == synccommon.OperationRunning || state.Phase == synccommon.OperationTerminating
+}
+
+// deleteRunningHooks deletes the hooks of the operation which are still running, e.g. jobs waiting for pods which
+// never start
+func (ctrl *ApplicationController) deleteRunningHooks(app *appv1.Application, state *appv1.OperationState) error {
+	var hooks []*appv1.ResourceResult
+	if state.SyncResult != nil {
+		for _, res := range state.SyncResult.Resources {
+			if res.HookType != "" && res.HookPhase.Running() {
+				hooks = append(hooks, res)
+			}
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	proj, err := ctrl.getAppProj(app)
+	if err != nil {
+		return fmt.Errorf("error getting project: %w", err)
+	}
+	destination := app.Spec.Destination
+	if err := argo.ValidateDestination(context.Background(), &destination, ctrl.db); err != nil {
+		return err
+	}
+	cluster, err := ctrl.db.GetCluster(context.Background(), destination.Server)
+	if err != nil {
+		return fmt.Errorf("error getting cluster: %w", err)
+	}
+	clusterRESTConfig, err := cluster.RESTConfig()
+	if err != nil {
+		return err
+	}
+	config := metrics.AddMetricsTransportWrapper(ctrl.metricsServer, app, clusterRESTConfig)
+	// the hooks are deleted with the permissions which they were created with
+	destinationNamespace, err := argo.GetDestinationNamespace(context.Background(), &app.Spec, ctrl.db)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination namespace: %w", err)
+	}
+	if err := argo.ImpersonateServiceAccount(config, proj, destination.Server, destinationNamespace); err != nil {
+		return err
+	}
+	// the hooks are removed right away, leaving the cleanup of e.g. the pods of jobs to the garbage collector
+	propagationPolicy := metav1.DeletePropagationBackground
+	return kube.RunAllAsync(len(hooks), func(i int) error {
+		hook := hooks[i]
+		err := ctrl.kubectl.DeleteResource(context.Background(), config, hook.GroupVersionKind(), hook.Name, hook.Namespace, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		if err != nil && !apierr.IsNotFound(err) {
+			return fmt.Errorf("error deleting %s hook %s/%s: %w", hook.HookType, hook.Kind, hook.Name, err)
+		}
+		return nil
+	})
+}
+
+// stuckOperationMessage describes why an operation is considered stuck, including the resources and hooks which are
+// still running
+func stuckOperationMessage(state *appv1.OperationState, maxDuration time.Duration) string {
+	message := fmt.Sprintf("Operation was running for %s, exceeding the maximum operation duration of %s", time.Since(state.StartedAt.Time).Round(time.Second), maxDuration)
+	var running []string
+	if state.SyncResult != nil {
+		for _, res := range state.SyncResult.Resources {
+			if !res.HookPhase.Running() {
+				continue
+			}
+			name := fmt.Sprintf("%s/%s", res.Kind, res.Name)
+			if res.Namespace != "" {
+				name = fmt.Sprintf("%s/%s/%s", res.Kind, res.Namespace, res.Name)
+			}
+			if res.HookType != "" {
+				name = fmt.Sprintf("%s hook %s", res.HookType, name)
+			}
+			running = append(running, name)
+		}
+	}
+	if len(running) > 0 {
+		message = fmt.Sprintf("%s. Still running: %s", message, strings.Join(running, ", "))
+	}
+	return message
+}
+
 func (ctrl *ApplicationController) setOperationState(app *appv1.Application, state *appv1.OperationState) {
 	kube.RetryUntilSucceed(context.Background(), updateOperationStateTimeout, "Update application operation state", logutils.NewLogrusLogger(logutils.NewWithCurrentConfig()), func() error {
 		if state.Phase == "" {
@@ -1293,7 +1488,7 @@ func (ctrl *ApplicationController) setOperationState(app *appv1.Application, sta
 		if state.Phase.Completed() {
 			eventInfo := argo.EventInfo{Reason: argo.EventReasonOperationCompleted}
 			var messages []string
-			if state.Operation.Sync != nil && len(state.Operation.Sync.Resources) > 0 {
+			if state.Operation.Sync != nil && state.Operation.Sync.IsSelective() {
 				messages = []string{"Partial sync operation"}
 			} else {
 				messages = []string{"Sync operation"}
@@ -1630,7 +1825,8 @@ func (ctrl *ApplicationController) persistAppStatus(orig *appv1.Application, new
 
 // autoSync will initiate a sync operation for an application configured with automated sync
 func (ctrl *ApplicationController) autoSync(app *appv1.Application, syncStatus *appv1.SyncStatus, resources []appv1.ResourceStatus) *appv1.ApplicationCondition {
-	if app.Spec.SyncPolicy == nil || app.Spec.SyncPolicy.Automated == nil {
+	syncPolicy := ctrl.getAppSyncPolicy(app)
+	if syncPolicy == nil || syncPolicy.Automated == nil {
 		return nil
 	}
 	logCtx := log.WithFields(log.Fields{"application": app.QualifiedName()})
@@ -1647,11 +1843,14 @@ func (ctrl *ApplicationController) autoSync(app *appv1.Application, syncStatus *
 	// Only perform auto-sync if we detect OutOfSync status. This is to prevent us from attempting
 	// a sync when application is already in a Synced or Unknown state
 	if syncStatus.Status != appv1.SyncStatusCodeOutOfSync {
+		if syncStatus.Status == appv1.SyncStatusCodeSynced {
+			ctrl.resetSelfHealAttemptsIfSteady(app)
+		}
 		logCtx.Infof("Skipping auto-sync: application status is %s", syncStatus.Status)
 		return nil
 	}
 
-	if !app.Spec.SyncPolicy.Automated.Prune {
+	if !syncPolicy.Automated.Prune {
 		requirePruneOnly := true
 		for _, r := range resources {
 			if r.Status != appv1.SyncStatusCodeSynced && !r.RequiresPruning {
@@ -1668,19 +1867,23 @@ func (ctrl *ApplicationController) autoSync(app *appv1.Application, syncStatus *
 	desiredCommitSHA := syncStatus.Revision
 	desiredCommitSHAsMS := syncStatus.Revisions
 	alreadyAttempted, attemptPhase := alreadyAttemptedSync(app, desiredCommitSHA, desiredCommitSHAsMS, app.Spec.HasMultipleSources())
-	selfHeal := app.Spec.SyncPolicy.Automated.SelfHeal
+	selfHeal := syncPolicy.Automated.SelfHeal
+	if !alreadyAttempted {
+		// the backoff of self-heal attempts starts over for each new revision
+		ctrl.resetSelfHealAttempts(app.QualifiedName())
+	}
 	op := appv1.Operation{
 		Sync: &appv1.SyncOperation{
 			Revision:    desiredCommitSHA,
-			Prune:       app.Spec.SyncPolicy.Automated.Prune,
-			SyncOptions: app.Spec.SyncPolicy.SyncOptions,
+			Prune:       syncPolicy.Automated.Prune,
+			SyncOptions: syncPolicy.SyncOptions,
 			Revisions:   desiredCommitSHAsMS,
 		},
 		InitiatedBy: appv1.OperationInitiator{Automated: true},
 		Retry:       appv1.RetryStrategy{Limit: 5},
 	}
-	if app.Spec.SyncPolicy.Retry != nil {
-		op.Retry = *app.Spec.SyncPolicy.Retry
+	if syncPolicy.Retry != nil {
+		op.Retry = *syncPolicy.Retry
 	}
 	// It is possible for manifests to remain OutOfSync even after a sync/kubectl apply (e.g.
 	// auto-sync with pruning disabled). We need to ensure that we do not keep Syncing an
@@ -1696,6 +1899,7 @@ func (ctrl *ApplicationController) autoSync(app *appv1.Application, syncStatus *
 		return nil
 	} else if alreadyAttempted && selfHeal {
 		if shouldSelfHeal, retryAfter := ctrl.shouldSelfHeal(app); shouldSelfHeal {
+			ctrl.incSelfHealAttempts(app.QualifiedName())
 			for _, resource := range resources {
 				if resource.Status != appv1.SyncStatusCodeSynced {
 					op.Sync.Resources = append(op.Sync.Resources, appv1.SyncOperationResource{
@@ -1706,14 +1910,14 @@ func (ctrl *ApplicationController) autoSync(app *appv1.Application, syncStatus *
 				}
 			}
 		} else {
-			logCtx.Infof("Skipping auto-sync: already attempted sync to %s with timeout %v (retrying in %v)", desiredCommitSHA, ctrl.selfHealTimeout, retryAfter)
+			logCtx.Infof("Skipping auto-sync: already attempted sync to %s with timeout %v (retrying in %v)", desiredCommitSHA, ctrl.getAppSelfHealTimeout(app), retryAfter)
 			ctrl.requestAppRefresh(app.QualifiedName(), CompareWithLatest.Pointer(), &retryAfter)
 			return nil
 		}
 
 	}
 
-	if app.Spec.SyncPolicy.Automated.Prune && !app.Spec.SyncPolicy.Automated.AllowEmpty {
+	if syncPolicy.Automated.Prune && !syncPolicy.Automated.AllowEmpty {
 		bAllNeedPrune := true
 		for _, r := range resources {
 			if !r.RequiresPruning {
@@ -1782,15 +1986,83 @@ func (ctrl *ApplicationController) shouldSelfHeal(app *appv1.Application) (bool,
 		return true, time.Duration(0)
 	}
 
+	timeout := ctrl.getAppSelfHealTimeout(app)
 	var retryAfter time.Duration
 	if app.Status.OperationState.FinishedAt == nil {
-		retryAfter = ctrl.selfHealTimeout
+		retryAfter = timeout
 	} else {
-		retryAfter = ctrl.selfHealTimeout - time.Since(app.Status.OperationState.FinishedAt.Time)
+		retryAfter = timeout - time.Since(app.Status.OperationState.FinishedAt.Time)
 	}
 	return retryAfter <= 0, retryAfter
 }
 
+// resetSelfHealAttemptsIfSteady resets the self-heal backoff of a synced application once its last sync succeeded and
+// it stayed synced since for at least the backoff cap, or the current timeout if there is no cap. An application which
+// becomes synced by self-healing and drifts again shortly after keeps backing off.
+func (ctrl *ApplicationController) resetSelfHealAttemptsIfSteady(app *appv1.Application) {
+	if ctrl.selfHealBackoff == nil || ctrl.getSelfHealAttempts(app.QualifiedName()) == 0 {
+		return
+	}
+	state := app.Status.OperationState
+	if state == nil || state.Phase != synccommon.OperationSucceeded || state.FinishedAt == nil {
+		return
+	}
+	steadyFor := ctrl.selfHealBackoff.Cap
+	if steadyFor <= 0 {
+		steadyFor = ctrl.getAppSelfHealTimeout(app)
+	}
+	if time.Since(state.FinishedAt.Time) > steadyFor {
+		ctrl.resetSelfHealAttempts(app.QualifiedName())
+	}
+}
+
+// getAppSelfHealTimeout returns the time to wait after the previous sync before self-healing the application again. It
+// can be raised per application with the SelfHealTimeout sync option, but not lowered below the timeout configured for
+// the controller. If a self-heal backoff is configured, the timeout is multiplied by the backoff factor for each
+// consecutive self-heal attempt, up to the backoff cap.
+func (ctrl *ApplicationController) getAppSelfHealTimeout(app *appv1.Application) time.Duration {
+	timeout := ctrl.selfHealTimeout
+	if ctrl.selfHealBackoff != nil {
+		timeout = ctrl.selfHealBackoff.Duration
+	}
+	if syncPolicy := ctrl.getAppSyncPolicy(app); syncPolicy != nil {
+		if value, ok := syncOptionValue(syncPolicy.SyncOptions, syncOptionSelfHealTimeout); ok {
+			if d, err := time.ParseDuration(value); err == nil && d > timeout {
+				timeout = d
+			}
+		}
+	}
+	if ctrl.selfHealBackoff == nil {
+		return timeout
+	}
+	backoff := float64(timeout) * math.Pow(ctrl.selfHealBackoff.Factor, float64(ctrl.getSelfHealAttempts(app.QualifiedName())))
+	if maxTimeout := ctrl.selfHealBackoff.Cap; maxTimeout > 0 && backoff > float64(maxTimeout) {
+		return maxTimeout
+	}
+	if backoff > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(backoff)
+}
+
+func (ctrl *ApplicationController) getSelfHealAttempts(appName string) int {
+	ctrl.selfHealAttemptsMutex.Lock()
+	defer ctrl.selfHealAttemptsMutex.Unlock()
+	return ctrl.selfHealAttempts[appName]
+}
+
+func (ctrl *ApplicationController) incSelfHealAttempts(appName string) {
+	ctrl.selfHealAttemptsMutex.Lock()
+	defer ctrl.selfHealAttemptsMutex.Unlock()
+	ctrl.selfHealAttempts[appName]++
+}
+
+func (ctrl *ApplicationController) resetSelfHealAttempts(appName string) {
+	ctrl.selfHealAttemptsMutex.Lock()
+	defer ctrl.selfHealAttemptsMutex.Unlock()
+	delete(ctrl.selfHealAttempts, appName)
+}
+
 func (ctrl *ApplicationController) canProcessApp(obj interface{}) bool {
 	app, ok := obj.(*appv1.Application)
 	if !ok {
@@ -1817,6 +2089,10 @@ func (ctrl *ApplicationController) canProcessApp(obj interface{}) bool {
 		}
 	}
 
+	if ctrl.appFilter != nil {
+		return ctrl.appFilter(app)
+	}
+
 	if ctrl.clusterFilter != nil {
 		cluster, err := ctrl.db.GetCluster(context.Background(), app.Spec.Destination.Server)
 		if err != nil {
@@ -1897,7 +2173,11 @@ func (ctrl *ApplicationController) newApplicationInformerAndLister() (cache.Shar
 					return nil, nil
 				}
 				if proj.Spec.OrphanedResources != nil {
-					return []string{app.Spec.Destination.Namespace}, nil
+					destinationNamespace, err := argo.GetDestinationNamespace(context.Background(), &app.Spec, ctrl.db)
+					if err != nil {
+						return nil, nil
+					}
+					return []string{destinationNamespace}, nil
 				}
 				return nil, nil
 			},
@@ -1926,31 +2206,51 @@ func (ctrl *ApplicationController) newApplicationInformerAndLister() (cache.Shar
 					return
 				}
 				var compareWith *CompareWith
+				var delay *time.Duration
 				oldApp, oldOK := old.(*appv1.Application)
 				newApp, newOK := new.(*appv1.Application)
-				if oldOK && newOK && automatedSyncEnabled(oldApp, newApp) {
-					log.WithField("application", newApp.QualifiedName()).Info("Enabled automated sync")
-					compareWith = CompareWithLatest.Pointer()
+				if oldOK && newOK {
+					// the sync policy of the application defaults to the one of its project
+					proj, _ := ctrl.getAppProj(newApp)
+					if automatedSyncEnabled(proj.GetSyncPolicy(oldApp), proj.GetSyncPolicy(newApp)) {
+						log.WithField("application", newApp.QualifiedName()).Info("Enabled automated sync")
+						compareWith = CompareWithLatest.Pointer()
+					}
+					delay = ctrl.resyncJitter(oldApp, newApp)
 				}
-				ctrl.requestAppRefresh(newApp.QualifiedName(), compareWith, nil)
+				ctrl.requestAppRefresh(newApp.QualifiedName(), compareWith, delay)
 				ctrl.appOperationQueue.Add(key)
 			},
 			DeleteFunc: func(obj interface{}) {
-				if !ctrl.canProcessApp(obj) {
-					return
-				}
 				// IndexerInformer uses a delta queue, therefore for deletes we have to use this
 				// key function.
 				key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
-				if err == nil {
-					ctrl.appRefreshQueue.Add(key)
+				if err != nil {
+					return
+				}
+				// the keys of the applications are their qualified names, also if the deletion was missed
+				ctrl.resetSelfHealAttempts(key)
+				if !ctrl.canProcessApp(obj) {
+					return
 				}
+				ctrl.appRefreshQueue.Add(key)
 			},
 		},
 	)
 	return informer, lister
 }
 
+// resyncJitter returns a random delay for refreshing an application which was requeued by the periodic resync of the
+// informer, so that the refreshes of all applications are spread over the jitter instead of hitting the repo-server at
+// once. Updates of the application are processed without delay.
+func (ctrl *ApplicationController) resyncJitter(oldApp, newApp *appv1.Application) *time.Duration {
+	if ctrl.statusRefreshJitter <= 0 || oldApp.ResourceVersion != newApp.ResourceVersion {
+		return nil
+	}
+	jitter := time.Duration(rand.Int63n(int64(ctrl.statusRefreshJitter)))
+	return &jitter
+}
+
 func (ctrl *ApplicationController) projectErrorToCondition(err error, app *appv1.Application) appv1.ApplicationCondition {
 	var condition appv1.ApplicationCondition
 	if apierr.IsNotFound(err) {
@@ -1965,29 +2265,54 @@ func (ctrl *ApplicationController) projectErrorToCondition(err error, app *appv1
 }
 
 func (ctrl *ApplicationController) RegisterClusterSecretUpdater(ctx context.Context) {
-	updater := NewClusterInfoUpdater(ctrl.stateCache, ctrl.db, ctrl.appLister.Applications(""), ctrl.cache, ctrl.clusterFilter, ctrl.getAppProj, ctrl.namespace)
+	updater := NewClusterInfoUpdater(ctrl.stateCache, ctrl.db, ctrl.appLister.Applications(""), ctrl.cache, ctrl.clusterFilter, ctrl.shard, ctrl.getAppProj, ctrl.namespace)
 	go updater.Run(ctx)
 }
 
+// RebalanceShards processes the clusters and applications assigned to this shard after the number of application
+// controller replicas changed: the caches of the clusters no longer assigned to the shard are invalidated, and all
+// applications are refreshed so that the ones newly assigned to the shard are reconciled.
+func (ctrl *ApplicationController) RebalanceShards() {
+	ctrl.stateCache.InvalidateUnhandledClusters()
+	for _, obj := range ctrl.appInformer.GetIndexer().List() {
+		if app, ok := obj.(*appv1.Application); ok && ctrl.canProcessApp(app) {
+			ctrl.requestAppRefresh(app.QualifiedName(), CompareWithLatest.Pointer(), nil)
+		}
+	}
+}
+
 func isOperationInProgress(app *appv1.Application) bool {
 	return app.Status.OperationState != nil && !app.Status.OperationState.Phase.Completed()
 }
 
-// automatedSyncEnabled tests if an app went from auto-sync disabled to enabled.
+// refreshAppsWithDefaultSyncPolicy refreshes the applications of the project which don't set their own sync policy,
+// after automated sync or self-heal was enabled in the default sync policy of the project
+func (ctrl *ApplicationController) refreshAppsWithDefaultSyncPolicy(proj *appv1.AppProject) {
+	for _, obj := range ctrl.appInformer.GetIndexer().List() {
+		app, ok := obj.(*appv1.Application)
+		if !ok || app.Spec.SyncPolicy != nil || app.Spec.GetProject() != proj.Name || !ctrl.canProcessApp(app) {
+			continue
+		}
+		log.WithField("application", app.QualifiedName()).Info("Enabled automated sync in the default sync policy of the project")
+		ctrl.requestAppRefresh(app.QualifiedName(), CompareWithLatest.Pointer(), nil)
+	}
+}
+
+// automatedSyncEnabled tests if a sync policy went from auto-sync disabled to enabled.
 // if it was toggled to be enabled, the informer handler will force a refresh
-func automatedSyncEnabled(oldApp *appv1.Application, newApp *appv1.Application) bool {
+func automatedSyncEnabled(oldPolicy *appv1.SyncPolicy, newPolicy *appv1.SyncPolicy) bool {
 	oldEnabled := false
 	oldSelfHealEnabled := false
-	if oldApp.Spec.SyncPolicy != nil && oldApp.Spec.SyncPolicy.Automated != nil {
+	if oldPolicy != nil && oldPolicy.Automated != nil {
 		oldEnabled = true
-		oldSelfHealEnabled = oldApp.Spec.SyncPolicy.Automated.SelfHeal
+		oldSelfHealEnabled = oldPolicy.Automated.SelfHeal
 	}
 
 	newEnabled := false
 	newSelfHealEnabled := false
-	if newApp.Spec.SyncPolicy != nil && newApp.Spec.SyncPolicy.Automated != nil {
+	if newPolicy != nil && newPolicy.Automated != nil {
 		newEnabled = true
-		newSelfHealEnabled = newApp.Spec.SyncPolicy.Automated.SelfHeal
+		newSelfHealEnabled = newPolicy.Automated.SelfHeal
 	}
 	if !oldEnabled && newEnabled {
 		return true
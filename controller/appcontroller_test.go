@@ -3,6 +3,9 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,13 +22,16 @@ import (
 	"github.com/argoproj/gitops-engine/pkg/utils/kube/kubetest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 	kubetesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/yaml"
@@ -55,6 +61,10 @@ type fakeData struct {
 	configMapData          map[string]string
 	metricsCacheExpiration time.Duration
 	applicationNamespaces  []string
+	chartDetails           *v1alpha1.ChartDetails
+	operationMaxDuration   time.Duration
+	// isNamespacedErr is returned by the live state cache when looking up the scope of a resource
+	isNamespacedErr error
 }
 
 func newFakeController(data *fakeData) *ApplicationController {
@@ -74,6 +84,9 @@ func newFakeController(data *fakeData) *ApplicationController {
 	} else {
 		mockRepoClient.On("GenerateManifest", mock.Anything, mock.Anything).Return(data.manifestResponse, nil)
 	}
+	if data.chartDetails != nil {
+		mockRepoClient.On("GetRevisionChartDetails", mock.Anything, mock.Anything).Return(data.chartDetails, nil)
+	}
 
 	mockRepoClientset := mockrepoclient.Clientset{RepoServerServiceClient: &mockRepoClient}
 
@@ -106,6 +119,7 @@ func newFakeController(data *fakeData) *ApplicationController {
 		kubeClient,
 		appclientset.NewSimpleClientset(data.apps...),
 		&mockRepoClientset,
+		nil,
 		appstatecache.NewCache(
 			cacheutil.NewCache(cacheutil.NewInMemoryCache(1*time.Minute)),
 			1*time.Minute,
@@ -113,13 +127,18 @@ func newFakeController(data *fakeData) *ApplicationController {
 		kubectl,
 		time.Minute,
 		time.Hour,
+		0,
 		time.Minute,
+		nil,
+		data.operationMaxDuration,
 		common.DefaultPortArgoCDMetrics,
 		data.metricsCacheExpiration,
 		[]string{},
 		0,
 		true,
 		nil,
+		nil,
+		-1,
 		data.applicationNamespaces,
 	)
 	if err != nil {
@@ -137,7 +156,7 @@ func newFakeController(data *fakeData) *ApplicationController {
 	mockStateCache := mockstatecache.LiveStateCache{}
 	ctrl.appStateManager.(*appStateManager).liveStateCache = &mockStateCache
 	ctrl.stateCache = &mockStateCache
-	mockStateCache.On("IsNamespaced", mock.Anything, mock.Anything).Return(true, nil)
+	mockStateCache.On("IsNamespaced", mock.Anything, mock.Anything).Return(data.isNamespacedErr == nil, data.isNamespacedErr)
 	mockStateCache.On("GetManagedLiveObjs", mock.Anything, mock.Anything).Return(data.managedLiveObjs, nil)
 	mockStateCache.On("GetVersionsInfo", mock.Anything).Return("v1.2.3", nil, nil)
 	response := make(map[kube.ResourceKey]v1alpha1.ResourceNode)
@@ -147,6 +166,9 @@ func newFakeController(data *fakeData) *ApplicationController {
 	mockStateCache.On("GetNamespaceTopLevelResources", mock.Anything, mock.Anything).Return(response, nil)
 	mockStateCache.On("IterateResources", mock.Anything, mock.Anything).Return(nil)
 	mockStateCache.On("GetClusterCache", mock.Anything).Return(&clusterCacheMock, nil)
+	mockStateCache.On("GetCluster", mock.Anything).Return(func(server string) *v1alpha1.Cluster {
+		return &v1alpha1.Cluster{Server: server}
+	}, nil)
 	mockStateCache.On("IterateHierarchy", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 		key := args[1].(kube.ResourceKey)
 		action := args[2].(func(child v1alpha1.ResourceNode, appName string) bool)
@@ -375,6 +397,90 @@ func TestAutoSync(t *testing.T) {
 	assert.False(t, app.Operation.Sync.Prune)
 }
 
+func TestAutoSyncWithProjectDefaultSyncPolicy(t *testing.T) {
+	defaultProj := &v1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: test.FakeArgoCDNamespace,
+		},
+		Spec: v1alpha1.AppProjectSpec{
+			SourceRepos:  []string{"*"},
+			Destinations: []v1alpha1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+			DefaultSyncPolicy: &v1alpha1.SyncPolicy{
+				Automated:   &v1alpha1.SyncPolicyAutomated{Prune: true},
+				SyncOptions: v1alpha1.SyncOptions{"CreateNamespace=true"},
+			},
+		},
+	}
+	syncStatus := v1alpha1.SyncStatus{
+		Status:   v1alpha1.SyncStatusCodeOutOfSync,
+		Revision: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+	}
+	resources := []v1alpha1.ResourceStatus{{Name: "guestbook", Kind: kube.DeploymentKind, Status: v1alpha1.SyncStatusCodeOutOfSync}}
+
+	t.Run("Inherited", func(t *testing.T) {
+		app := newFakeApp()
+		app.Spec.SyncPolicy = nil
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, defaultProj}})
+		cond := ctrl.autoSync(app, &syncStatus, resources)
+		assert.Nil(t, cond)
+		app, err := ctrl.applicationClientset.ArgoprojV1alpha1().Applications(test.FakeArgoCDNamespace).Get(context.Background(), "my-app", metav1.GetOptions{})
+		assert.NoError(t, err)
+		require.NotNil(t, app.Operation)
+		assert.True(t, app.Operation.Sync.Prune)
+		assert.Equal(t, v1alpha1.SyncOptions{"CreateNamespace=true"}, app.Operation.Sync.SyncOptions)
+		assert.Nil(t, app.Spec.SyncPolicy)
+	})
+
+	t.Run("OverriddenByApplication", func(t *testing.T) {
+		app := newFakeApp()
+		app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{}
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, defaultProj}})
+		cond := ctrl.autoSync(app, &syncStatus, resources)
+		assert.Nil(t, cond)
+		app, err := ctrl.applicationClientset.ArgoprojV1alpha1().Applications(test.FakeArgoCDNamespace).Get(context.Background(), "my-app", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Nil(t, app.Operation)
+	})
+}
+
+func TestAutomatedSyncEnabled(t *testing.T) {
+	manual := &v1alpha1.SyncPolicy{}
+	automated := &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{}}
+	selfHeal := &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true}}
+	assert.True(t, automatedSyncEnabled(nil, automated))
+	assert.True(t, automatedSyncEnabled(manual, automated))
+	assert.True(t, automatedSyncEnabled(automated, selfHeal))
+	assert.False(t, automatedSyncEnabled(automated, automated))
+	assert.False(t, automatedSyncEnabled(selfHeal, manual))
+}
+
+func TestRefreshAppsWithDefaultSyncPolicy(t *testing.T) {
+	inherited := newFakeApp()
+	inherited.Name = "inherited"
+	inherited.Spec.SyncPolicy = nil
+	own := newFakeApp()
+	own.Name = "own"
+	own.Spec.SyncPolicy = &v1alpha1.SyncPolicy{}
+	otherProject := newFakeApp()
+	otherProject.Name = "other-project"
+	otherProject.Spec.SyncPolicy = nil
+	otherProject.Spec.Project = "other"
+	ctrl := newFakeController(&fakeData{apps: []runtime.Object{inherited, own, otherProject, &defaultProj}})
+
+	proj := defaultProj.DeepCopy()
+	proj.Spec.DefaultSyncPolicy = &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true}}
+	ctrl.refreshAppsWithDefaultSyncPolicy(proj)
+
+	isRequested, level := ctrl.isRefreshRequested(inherited.QualifiedName())
+	assert.True(t, isRequested)
+	assert.Equal(t, CompareWithLatest, level)
+	isRequested, _ = ctrl.isRefreshRequested(own.QualifiedName())
+	assert.False(t, isRequested)
+	isRequested, _ = ctrl.isRefreshRequested(otherProject.QualifiedName())
+	assert.False(t, isRequested)
+}
+
 func TestAutoSyncNotAllowEmpty(t *testing.T) {
 	app := newFakeApp()
 	app.Spec.SyncPolicy.Automated.Prune = true
@@ -631,6 +737,23 @@ func TestFinalizeAppDeletion(t *testing.T) {
 		assert.True(t, patched)
 	})
 
+	// Ensure resources are not deleted if the service account to impersonate can't be determined
+	t.Run("CascadingDeleteWithoutServiceAccount", func(t *testing.T) {
+		impersonatedProj := defaultProj.DeepCopy()
+		impersonatedProj.Spec.DestinationServiceAccounts = []v1alpha1.ApplicationDestinationServiceAccount{{Server: "*", Namespace: "other", DefaultServiceAccount: "deployer"}}
+		app := newFakeApp()
+		app.Spec.Destination.Namespace = test.FakeArgoCDNamespace
+		appObj := kube.MustToUnstructured(&app)
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, impersonatedProj}, managedLiveObjs: map[kube.ResourceKey]*unstructured.Unstructured{
+			kube.GetResourceKey(appObj): appObj,
+		}})
+
+		_, err := ctrl.finalizeApplicationDeletion(app, func(project string) ([]*v1alpha1.Cluster, error) {
+			return []*v1alpha1.Cluster{}, nil
+		})
+		assert.ErrorContains(t, err, "no service account to impersonate is configured in project 'default'")
+	})
+
 	// Ensure any stray resources irregularly labeled with instance label of app are not deleted upon deleting,
 	// when app project restriction is in place
 	t.Run("ProjectRestrictionEnforced", func(*testing.T) {
@@ -913,6 +1036,39 @@ func TestGetResourceTree_HasOrphanedResources(t *testing.T) {
 	assert.Equal(t, tree.OrphanedNodes, []v1alpha1.ResourceNode{orphanedDeploy1, orphanedDeploy2})
 }
 
+func TestGetResourceTree_OrphanedResourcesError(t *testing.T) {
+	app := newFakeApp()
+	proj := defaultProj.DeepCopy()
+	reportAsError := true
+	proj.Spec.OrphanedResources = &v1alpha1.OrphanedResourcesMonitorSettings{
+		Error:  &reportAsError,
+		Ignore: []v1alpha1.OrphanedResourceKey{{Group: "apps", Kind: "Deploy*", Name: "generated-*"}},
+	}
+
+	orphanedDeploy := v1alpha1.ResourceNode{
+		ResourceRef: v1alpha1.ResourceRef{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "deploy1"},
+	}
+	ignoredDeploy := v1alpha1.ResourceNode{
+		ResourceRef: v1alpha1.ResourceRef{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "generated-deploy"},
+	}
+
+	ctrl := newFakeController(&fakeData{
+		apps: []runtime.Object{app, proj},
+		namespacedResources: map[kube.ResourceKey]namespacedResource{
+			kube.NewResourceKey("apps", "Deployment", "default", "deploy1"):          {ResourceNode: orphanedDeploy},
+			kube.NewResourceKey("apps", "Deployment", "default", "generated-deploy"): {ResourceNode: ignoredDeploy},
+		},
+	})
+	tree, err := ctrl.getResourceTree(app, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []v1alpha1.ResourceNode{orphanedDeploy}, tree.OrphanedNodes)
+	if assert.Len(t, app.Status.Conditions, 1) {
+		assert.Equal(t, v1alpha1.ApplicationConditionOrphanedResourceError, app.Status.Conditions[0].Type)
+		assert.Equal(t, "Application has 1 orphaned resources", app.Status.Conditions[0].Message)
+	}
+}
+
 func TestSetOperationStateOnDeletedApp(t *testing.T) {
 	ctrl := newFakeController(&fakeData{apps: []runtime.Object{}})
 	fakeAppCs := ctrl.applicationClientset.(*appclientset.Clientset)
@@ -1405,6 +1561,247 @@ func TestProcessRequestedAppOperation_RunningPreviouslyFailed(t *testing.T) {
 	assert.Equal(t, string(synccommon.OperationSucceeded), phase)
 }
 
+func TestResyncJitter(t *testing.T) {
+	ctrl := newFakeController(&fakeData{})
+	oldApp := newFakeApp()
+	oldApp.ResourceVersion = "1"
+	newApp := oldApp.DeepCopy()
+	assert.Nil(t, ctrl.resyncJitter(oldApp, newApp))
+
+	ctrl.statusRefreshJitter = time.Minute
+	delay := ctrl.resyncJitter(oldApp, newApp)
+	if assert.NotNil(t, delay) {
+		assert.GreaterOrEqual(t, *delay, time.Duration(0))
+		assert.Less(t, *delay, time.Minute)
+	}
+
+	// updates of the application are not delayed
+	newApp.ResourceVersion = "2"
+	assert.Nil(t, ctrl.resyncJitter(oldApp, newApp))
+}
+
+func TestGetAppSelfHealTimeout(t *testing.T) {
+	ctrl := newFakeController(&fakeData{})
+	app := newFakeApp()
+	app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true}}
+	assert.Equal(t, time.Minute, ctrl.getAppSelfHealTimeout(app))
+
+	app.Spec.SyncPolicy.SyncOptions = v1alpha1.SyncOptions{"SelfHealTimeout=2m"}
+	assert.Equal(t, 2*time.Minute, ctrl.getAppSelfHealTimeout(app))
+
+	// the timeout can't be lowered per application
+	app.Spec.SyncPolicy.SyncOptions = v1alpha1.SyncOptions{"SelfHealTimeout=0s"}
+	assert.Equal(t, time.Minute, ctrl.getAppSelfHealTimeout(app))
+
+	t.Run("Backoff", func(t *testing.T) {
+		ctrl := newFakeController(&fakeData{})
+		ctrl.selfHealBackoff = &wait.Backoff{Duration: 2 * time.Second, Factor: 3, Cap: 10 * time.Second}
+		app := newFakeApp()
+		app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true}}
+		assert.Equal(t, 2*time.Second, ctrl.getAppSelfHealTimeout(app))
+
+		ctrl.incSelfHealAttempts(app.QualifiedName())
+		assert.Equal(t, 6*time.Second, ctrl.getAppSelfHealTimeout(app))
+
+		ctrl.incSelfHealAttempts(app.QualifiedName())
+		assert.Equal(t, 10*time.Second, ctrl.getAppSelfHealTimeout(app))
+
+		ctrl.resetSelfHealAttempts(app.QualifiedName())
+		app.Spec.SyncPolicy.SyncOptions = v1alpha1.SyncOptions{"SelfHealTimeout=4s"}
+		assert.Equal(t, 4*time.Second, ctrl.getAppSelfHealTimeout(app))
+		app.Spec.SyncPolicy.SyncOptions = v1alpha1.SyncOptions{"SelfHealTimeout=1s"}
+		assert.Equal(t, 2*time.Second, ctrl.getAppSelfHealTimeout(app))
+	})
+}
+
+func TestResetSelfHealAttemptsIfSteady(t *testing.T) {
+	newSyncedApp := func(finishedAgo time.Duration, phase synccommon.OperationPhase) *v1alpha1.Application {
+		app := newFakeApp()
+		app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true}}
+		app.Status.OperationState.Phase = phase
+		app.Status.OperationState.FinishedAt = &metav1.Time{Time: time.Now().Add(-finishedAgo)}
+		return app
+	}
+	syncStatus := &v1alpha1.SyncStatus{Status: v1alpha1.SyncStatusCodeSynced}
+
+	t.Run("SyncedShortly", func(t *testing.T) {
+		app := newSyncedApp(time.Second, synccommon.OperationSucceeded)
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}})
+		ctrl.selfHealBackoff = &wait.Backoff{Duration: 2 * time.Second, Factor: 3, Cap: time.Minute}
+		ctrl.incSelfHealAttempts(app.QualifiedName())
+		ctrl.autoSync(app, syncStatus, nil)
+		assert.Equal(t, 1, ctrl.getSelfHealAttempts(app.QualifiedName()))
+	})
+
+	t.Run("SteadyForLongerThanCap", func(t *testing.T) {
+		app := newSyncedApp(2*time.Minute, synccommon.OperationSucceeded)
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}})
+		ctrl.selfHealBackoff = &wait.Backoff{Duration: 2 * time.Second, Factor: 3, Cap: time.Minute}
+		ctrl.incSelfHealAttempts(app.QualifiedName())
+		ctrl.autoSync(app, syncStatus, nil)
+		assert.Equal(t, 0, ctrl.getSelfHealAttempts(app.QualifiedName()))
+	})
+
+	t.Run("OutOfSyncForLongerThanCap", func(t *testing.T) {
+		app := newSyncedApp(2*time.Minute, synccommon.OperationSucceeded)
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}})
+		ctrl.selfHealBackoff = &wait.Backoff{Duration: 2 * time.Second, Factor: 3, Cap: time.Minute}
+		ctrl.incSelfHealAttempts(app.QualifiedName())
+		shouldSelfHeal, _ := ctrl.shouldSelfHeal(app)
+		assert.True(t, shouldSelfHeal)
+		assert.Equal(t, 1, ctrl.getSelfHealAttempts(app.QualifiedName()))
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		app := newSyncedApp(2*time.Minute, synccommon.OperationFailed)
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}})
+		ctrl.selfHealBackoff = &wait.Backoff{Duration: 2 * time.Second, Factor: 3, Cap: time.Minute}
+		ctrl.incSelfHealAttempts(app.QualifiedName())
+		ctrl.autoSync(app, syncStatus, nil)
+		assert.Equal(t, 1, ctrl.getSelfHealAttempts(app.QualifiedName()))
+	})
+}
+
+func TestUseLatestRevisions(t *testing.T) {
+	t.Run("SingleSource", func(t *testing.T) {
+		app := newFakeApp()
+		app.Status.Sync.Revision = "def456"
+		syncOp := &v1alpha1.SyncOperation{Revision: "abc123"}
+		useLatestRevisions(app, syncOp)
+		assert.Equal(t, "def456", syncOp.Revision)
+	})
+	t.Run("SingleSourceNotCompared", func(t *testing.T) {
+		app := newFakeApp()
+		app.Status.Sync.Revision = ""
+		syncOp := &v1alpha1.SyncOperation{Revision: "abc123"}
+		useLatestRevisions(app, syncOp)
+		assert.Equal(t, "abc123", syncOp.Revision)
+	})
+	t.Run("MultipleSources", func(t *testing.T) {
+		app := newFakeMultiSourceApp()
+		app.Status.Sync.Revisions = []string{"def456", "ghi789"}
+		syncOp := &v1alpha1.SyncOperation{Revisions: []string{"abc123", "ghi789"}}
+		useLatestRevisions(app, syncOp)
+		assert.Equal(t, []string{"def456", "ghi789"}, syncOp.Revisions)
+	})
+}
+
+func TestProcessRequestedAppOperation_Stuck(t *testing.T) {
+	newStuckApp := func(retry v1alpha1.RetryStrategy) *v1alpha1.Application {
+		app := newFakeApp()
+		app.Operation = &v1alpha1.Operation{
+			Sync:  &v1alpha1.SyncOperation{},
+			Retry: retry,
+		}
+		app.Status.OperationState.Operation = *app.Operation.DeepCopy()
+		app.Status.OperationState.Phase = synccommon.OperationRunning
+		app.Status.OperationState.StartedAt = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		app.Status.OperationState.FinishedAt = nil
+		app.Status.OperationState.SyncResult.Resources = []*v1alpha1.ResourceResult{{
+			Name:      "db-migrate",
+			Kind:      "Job",
+			Group:     "batch",
+			Namespace: "default",
+			HookType:  synccommon.HookTypePreSync,
+			HookPhase: synccommon.OperationRunning,
+		}}
+		return app
+	}
+	processOperation := func(app *v1alpha1.Application, deleteErr error) (map[string]interface{}, []string) {
+		ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}, operationMaxDuration: time.Hour})
+		kubectl := &deleteRecordingKubectl{err: deleteErr}
+		ctrl.kubectl = kubectl
+		fakeAppCs := ctrl.applicationClientset.(*appclientset.Clientset)
+		receivedPatch := map[string]interface{}{}
+		fakeAppCs.PrependReactor("patch", "*", func(action kubetesting.Action) (handled bool, ret runtime.Object, err error) {
+			if patchAction, ok := action.(kubetesting.PatchAction); ok {
+				assert.NoError(t, json.Unmarshal(patchAction.GetPatch(), &receivedPatch))
+			}
+			return true, nil, nil
+		})
+		ctrl.processRequestedAppOperation(app)
+		return receivedPatch, kubectl.deleted
+	}
+
+	t.Run("NoRetries", func(t *testing.T) {
+		receivedPatch, deleted := processOperation(newStuckApp(v1alpha1.RetryStrategy{}), nil)
+		phase, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "phase")
+		assert.Equal(t, string(synccommon.OperationFailed), phase)
+		message, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "message")
+		assert.Contains(t, message, "exceeding the maximum operation duration of 1h0m0s")
+		assert.Contains(t, message, "Still running: PreSync hook Job/default/db-migrate")
+		assert.Equal(t, []string{"Job/default/db-migrate"}, deleted)
+	})
+	t.Run("HasRetries", func(t *testing.T) {
+		receivedPatch, deleted := processOperation(newStuckApp(v1alpha1.RetryStrategy{Limit: 1}), nil)
+		phase, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "phase")
+		assert.Equal(t, string(synccommon.OperationRunning), phase)
+		message, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "message")
+		assert.Contains(t, message, "Retrying attempt #1")
+		assert.Equal(t, []string{"Job/default/db-migrate"}, deleted)
+	})
+	t.Run("RetriedAttemptIsNotStuck", func(t *testing.T) {
+		// the previous attempt failed long after the operation started, so the retried attempt is measured from its own start
+		app := newStuckApp(v1alpha1.RetryStrategy{Limit: 2})
+		finishedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+		app.Status.OperationState.FinishedAt = &finishedAt
+		app.Status.OperationState.RetryCount = 1
+		receivedPatch, deleted := processOperation(app, nil)
+		message, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "message")
+		assert.NotContains(t, message, "exceeding the maximum operation duration")
+		startedAt, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "startedAt")
+		started, err := time.Parse(time.RFC3339, startedAt)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), started, time.Minute)
+		assert.Empty(t, deleted)
+	})
+	t.Run("HookAlreadyDeleted", func(t *testing.T) {
+		notFound := apierr.NewNotFound(schema.GroupResource{Group: "batch", Resource: "jobs"}, "db-migrate")
+		receivedPatch, _ := processOperation(newStuckApp(v1alpha1.RetryStrategy{Limit: 1}), notFound)
+		phase, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "phase")
+		assert.Equal(t, string(synccommon.OperationRunning), phase)
+		message, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "message")
+		assert.Contains(t, message, "Retrying attempt #1")
+	})
+	t.Run("DeleteHookFailed", func(t *testing.T) {
+		receivedPatch, _ := processOperation(newStuckApp(v1alpha1.RetryStrategy{Limit: 1}), errors.New("forbidden"))
+		phase, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "phase")
+		assert.Equal(t, string(synccommon.OperationFailed), phase)
+		message, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "message")
+		assert.Contains(t, message, "failed to delete running hooks")
+		assert.NotContains(t, message, "Retrying attempt")
+	})
+	t.Run("Terminating", func(t *testing.T) {
+		app := newStuckApp(v1alpha1.RetryStrategy{Limit: 1})
+		app.Status.OperationState.Phase = synccommon.OperationTerminating
+		receivedPatch, deleted := processOperation(app, nil)
+		phase, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "phase")
+		assert.Equal(t, string(synccommon.OperationFailed), phase)
+		message, _, _ := unstructured.NestedString(receivedPatch, "status", "operationState", "message")
+		assert.Contains(t, message, "exceeding the maximum operation duration of 1h0m0s")
+		assert.NotContains(t, message, "Retrying attempt")
+		assert.Equal(t, []string{"Job/default/db-migrate"}, deleted)
+	})
+}
+
+// deleteRecordingKubectl records the deleted resources and fails their deletion with err, if set
+type deleteRecordingKubectl struct {
+	kubetest.MockKubectlCmd
+	err     error
+	lock    sync.Mutex
+	deleted []string
+}
+
+func (k *deleteRecordingKubectl) DeleteResource(_ context.Context, _ *rest.Config, gvk schema.GroupVersionKind, name string, namespace string, _ metav1.DeleteOptions) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.err != nil {
+		return k.err
+	}
+	k.deleted = append(k.deleted, fmt.Sprintf("%s/%s/%s", gvk.Kind, namespace, name))
+	return nil
+}
+
 func TestProcessRequestedAppOperation_HasRetriesTerminated(t *testing.T) {
 	app := newFakeApp()
 	app.Operation = &v1alpha1.Operation{
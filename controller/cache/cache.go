@@ -109,6 +109,8 @@ type LiveStateCache interface {
 	IsNamespaced(server string, gk schema.GroupKind) (bool, error)
 	// Returns synced cluster cache
 	GetClusterCache(server string) (clustercache.ClusterCache, error)
+	// Returns the cluster of the given server as of the last update of its cache
+	GetCluster(server string) (*appv1.Cluster, error)
 	// Executes give callback against resource specified by the key and all its children
 	IterateHierarchy(server string, key kube.ResourceKey, action func(child appv1.ResourceNode, appName string) bool) error
 	// Returns state of live nodes which correspond for target nodes of specified application.
@@ -123,6 +125,8 @@ type LiveStateCache interface {
 	GetClustersInfo() []clustercache.ClusterInfo
 	// Init must be executed before cache can be used
 	Init() error
+	// Invalidates the caches of the clusters which are no longer handled by this controller shard
+	InvalidateUnhandledClusters()
 }
 
 type ObjectUpdatedHandler = func(managedByApp map[string]bool, ref v1.ObjectReference)
@@ -168,6 +172,7 @@ func NewLiveStateCache(
 		appInformer:      appInformer,
 		db:               db,
 		clusters:         make(map[string]clustercache.ClusterCache),
+		clusterSpecs:     make(map[string]*appv1.Cluster),
 		onObjectUpdated:  onObjectUpdated,
 		kubectl:          kubectl,
 		settingsMgr:      settingsMgr,
@@ -199,6 +204,7 @@ type liveStateCache struct {
 	resourceTracking argo.ResourceTracking
 
 	clusters      map[string]clustercache.ClusterCache
+	clusterSpecs  map[string]*appv1.Cluster
 	cacheSettings cacheSettings
 	lock          sync.RWMutex
 }
@@ -232,6 +238,21 @@ func (c *liveStateCache) loadCacheSettings() (*cacheSettings, error) {
 	return &cacheSettings{clusterSettings, appInstanceLabelKey, argo.GetTrackingMethod(c.settingsMgr), resourceUpdatesOverrides, ignoreResourceUpdatesEnabled}, nil
 }
 
+// getClusterSettings returns the cluster cache settings of the given cluster, which use the resource inclusions and
+// exclusions of the cluster secret instead of the ones of argocd-cm if they are set
+func getClusterSettings(clusterSettings clustercache.Settings, cluster *appv1.Cluster) (clustercache.Settings, error) {
+	resourcesFilter, ok := clusterSettings.ResourcesFilter.(*settings.ResourcesFilter)
+	if !ok {
+		return clusterSettings, nil
+	}
+	clusterResourcesFilter, err := settings.GetClusterResourcesFilter(resourcesFilter, cluster)
+	if err != nil {
+		return clusterSettings, err
+	}
+	clusterSettings.ResourcesFilter = clusterResourcesFilter
+	return clusterSettings, nil
+}
+
 func asResourceNode(r *clustercache.Resource) appv1.ResourceNode {
 	gv, err := schema.ParseGroupVersion(r.Ref.APIVersion)
 	if err != nil {
@@ -433,7 +454,15 @@ func (c *liveStateCache) getCluster(server string) (clustercache.ClusterCache, e
 		return nil, fmt.Errorf("error getting custom label: %w", err)
 	}
 
-	clusterCacheConfig := cluster.RESTConfig()
+	clusterSettings, err := getClusterSettings(cacheSettings.clusterSettings, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster settings: %w", err)
+	}
+
+	clusterCacheConfig, err := cluster.RESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster REST config: %w", err)
+	}
 	// Controller dynamically fetches all resource types available on the cluster
 	// using a discovery API that may contain deprecated APIs.
 	// This causes log flooding when managing a large number of clusters.
@@ -453,7 +482,7 @@ func (c *liveStateCache) getCluster(server string) (clustercache.ClusterCache, e
 		clustercache.SetWatchResyncTimeout(clusterCacheWatchResyncDuration),
 		clustercache.SetClusterSyncRetryTimeout(clusterSyncRetryTimeoutDuration),
 		clustercache.SetResyncTimeout(clusterCacheResyncDuration),
-		clustercache.SetSettings(cacheSettings.clusterSettings),
+		clustercache.SetSettings(clusterSettings),
 		clustercache.SetNamespaces(cluster.Namespaces),
 		clustercache.SetClusterResources(cluster.ClusterResources),
 		clustercache.SetPopulateResourceInfoHandler(func(un *unstructured.Unstructured, isRoot bool) (interface{}, bool) {
@@ -542,10 +571,30 @@ func (c *liveStateCache) getCluster(server string) (clustercache.ClusterCache, e
 	})
 
 	c.clusters[server] = clusterCache
+	c.clusterSpecs[server] = cluster
 
 	return clusterCache, nil
 }
 
+func (c *liveStateCache) GetCluster(server string) (*appv1.Cluster, error) {
+	c.lock.RLock()
+	cluster, ok := c.clusterSpecs[server]
+	c.lock.RUnlock()
+	if ok {
+		return cluster, nil
+	}
+	if _, err := c.getCluster(server); err != nil {
+		return nil, err
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	cluster, ok = c.clusterSpecs[server]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s was removed", server)
+	}
+	return cluster, nil
+}
+
 func (c *liveStateCache) getSyncedCluster(server string) (clustercache.ClusterCache, error) {
 	clusterCache, err := c.getCluster(server)
 	if err != nil {
@@ -565,8 +614,14 @@ func (c *liveStateCache) invalidate(cacheSettings cacheSettings) {
 	clusters := c.clusters
 	c.lock.Unlock()
 
-	for _, clust := range clusters {
-		clust.Invalidate(clustercache.SetSettings(cacheSettings.clusterSettings))
+	for server, clust := range clusters {
+		clusterSettings := cacheSettings.clusterSettings
+		if cluster, err := c.db.GetCluster(context.Background(), server); err != nil {
+			log.Warnf("Failed to get cluster %s, using the global resource inclusions and exclusions: %v", server, err)
+		} else if clusterSettings, err = getClusterSettings(cacheSettings.clusterSettings, cluster); err != nil {
+			log.Warnf("Failed to get settings of cluster %s, using the global resource inclusions and exclusions: %v", server, err)
+		}
+		clust.Invalidate(clustercache.SetSettings(clusterSettings))
 	}
 	log.Info("live state cache invalidated")
 }
@@ -641,6 +696,13 @@ func (c *liveStateCache) isClusterHasApps(apps []interface{}, cluster *appv1.Clu
 		if !ok {
 			continue
 		}
+		if app.Spec.Destination.ClusterSelector != "" {
+			// matching the selector against the labels of this cluster avoids listing all clusters for every application
+			if argo.ClusterSelectorMatches(app.Spec.Destination.ClusterSelector, cluster.Labels) {
+				return true
+			}
+			continue
+		}
 		err := argo.ValidateDestination(context.Background(), &app.Spec.Destination, c.db)
 		if err != nil {
 			continue
@@ -733,7 +795,29 @@ func (c *liveStateCache) handleAddEvent(cluster *appv1.Cluster) {
 	}
 }
 
+// refreshAppsSelectingCluster requests a refresh of the applications whose destination cluster selector matches either
+// the old or the new labels of a cluster, so that their destination server gets resolved again.
+func (c *liveStateCache) refreshAppsSelectingCluster(oldCluster *appv1.Cluster, newCluster *appv1.Cluster) {
+	toNotify := make(map[string]bool)
+	for _, obj := range c.appInformer.GetStore().List() {
+		app, ok := obj.(*appv1.Application)
+		if !ok || app.Spec.Destination.ClusterSelector == "" {
+			continue
+		}
+		if argo.ClusterSelectorMatches(app.Spec.Destination.ClusterSelector, oldCluster.Labels) ||
+			argo.ClusterSelectorMatches(app.Spec.Destination.ClusterSelector, newCluster.Labels) {
+			toNotify[app.InstanceName(c.settingsMgr.GetNamespace())] = true
+		}
+	}
+	if len(toNotify) > 0 {
+		c.onObjectUpdated(toNotify, v1.ObjectReference{})
+	}
+}
+
 func (c *liveStateCache) handleModEvent(oldCluster *appv1.Cluster, newCluster *appv1.Cluster) {
+	if !reflect.DeepEqual(oldCluster.Labels, newCluster.Labels) {
+		c.refreshAppsSelectingCluster(oldCluster, newCluster)
+	}
 	c.lock.Lock()
 	cluster, ok := c.clusters[newCluster.Server]
 	c.lock.Unlock()
@@ -742,13 +826,22 @@ func (c *liveStateCache) handleModEvent(oldCluster *appv1.Cluster, newCluster *a
 			cluster.Invalidate()
 			c.lock.Lock()
 			delete(c.clusters, newCluster.Server)
+			delete(c.clusterSpecs, newCluster.Server)
 			c.lock.Unlock()
 			return
 		}
+		c.lock.Lock()
+		c.clusterSpecs[newCluster.Server] = newCluster
+		c.lock.Unlock()
 
 		var updateSettings []clustercache.UpdateSettingsFunc
 		if !reflect.DeepEqual(oldCluster.Config, newCluster.Config) {
-			updateSettings = append(updateSettings, clustercache.SetConfig(newCluster.RESTConfig()))
+			newClusterRESTConfig, err := newCluster.RESTConfig()
+			if err == nil {
+				updateSettings = append(updateSettings, clustercache.SetConfig(newClusterRESTConfig))
+			} else {
+				log.Errorf("error getting cluster REST config: %v", err)
+			}
 		}
 		if !reflect.DeepEqual(oldCluster.Namespaces, newCluster.Namespaces) {
 			updateSettings = append(updateSettings, clustercache.SetNamespaces(newCluster.Namespaces))
@@ -756,6 +849,16 @@ func (c *liveStateCache) handleModEvent(oldCluster *appv1.Cluster, newCluster *a
 		if !reflect.DeepEqual(oldCluster.ClusterResources, newCluster.ClusterResources) {
 			updateSettings = append(updateSettings, clustercache.SetClusterResources(newCluster.ClusterResources))
 		}
+		if oldCluster.ResourceInclusions != newCluster.ResourceInclusions || oldCluster.ResourceExclusions != newCluster.ResourceExclusions {
+			c.lock.RLock()
+			cacheSettings := c.cacheSettings
+			c.lock.RUnlock()
+			clusterSettings, err := getClusterSettings(cacheSettings.clusterSettings, newCluster)
+			if err != nil {
+				log.Warnf("Failed to get settings of cluster %s, using the global resource inclusions and exclusions: %v", newCluster.Server, err)
+			}
+			updateSettings = append(updateSettings, clustercache.SetSettings(clusterSettings))
+		}
 		forceInvalidate := false
 		if newCluster.RefreshRequestedAt != nil &&
 			cluster.GetClusterInfo().LastCacheSyncTime != nil &&
@@ -774,6 +877,28 @@ func (c *liveStateCache) handleModEvent(oldCluster *appv1.Cluster, newCluster *a
 
 }
 
+func (c *liveStateCache) InvalidateUnhandledClusters() {
+	clusters, err := c.db.ListClusters(context.Background())
+	if err != nil {
+		log.Warnf("Failed to list clusters: %v", err)
+		return
+	}
+	for i := range clusters.Items {
+		if c.canHandleCluster(&clusters.Items[i]) {
+			continue
+		}
+		c.lock.Lock()
+		cluster, ok := c.clusters[clusters.Items[i].Server]
+		delete(c.clusters, clusters.Items[i].Server)
+		delete(c.clusterSpecs, clusters.Items[i].Server)
+		c.lock.Unlock()
+		if ok {
+			log.Infof("Cluster %s is no longer handled by this shard", clusters.Items[i].Server)
+			cluster.Invalidate()
+		}
+	}
+}
+
 func (c *liveStateCache) handleDeleteEvent(clusterServer string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -781,6 +906,7 @@ func (c *liveStateCache) handleDeleteEvent(clusterServer string) {
 	if ok {
 		cluster.Invalidate()
 		delete(c.clusters, clusterServer)
+		delete(c.clusterSpecs, clusterServer)
 	}
 }
 
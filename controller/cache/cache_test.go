@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"net"
 	"net/url"
@@ -11,6 +12,8 @@ import (
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8scache "k8s.io/client-go/tools/cache"
 
 	"github.com/argoproj/gitops-engine/pkg/cache"
 	"github.com/argoproj/gitops-engine/pkg/cache/mocks"
@@ -18,6 +21,8 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	dbmocks "github.com/argoproj/argo-cd/v2/util/db/mocks"
+	"github.com/argoproj/argo-cd/v2/util/settings"
 )
 
 type netError string
@@ -32,6 +37,7 @@ func TestHandleModEvent_HasChanges(t *testing.T) {
 	clusterCache.On("EnsureSynced").Return(nil).Once()
 
 	clustersCache := liveStateCache{
+		clusterSpecs: map[string]*appv1.Cluster{},
 		clusters: map[string]cache.ClusterCache{
 			"https://mycluster": clusterCache,
 		},
@@ -53,6 +59,7 @@ func TestHandleModEvent_ClusterExcluded(t *testing.T) {
 	clusterCache.On("EnsureSynced").Return(nil).Once()
 
 	clustersCache := liveStateCache{
+		clusterSpecs: map[string]*appv1.Cluster{},
 		clusters: map[string]cache.ClusterCache{
 			"https://mycluster": clusterCache,
 		},
@@ -73,12 +80,92 @@ func TestHandleModEvent_ClusterExcluded(t *testing.T) {
 	assert.Len(t, clustersCache.clusters, 0)
 }
 
+func newAppInformerWithApps(t *testing.T, apps ...*appv1.Application) k8scache.SharedIndexInformer {
+	informer := k8scache.NewSharedIndexInformer(&k8scache.ListWatch{}, &appv1.Application{}, 0, k8scache.Indexers{})
+	for _, app := range apps {
+		assert.NoError(t, informer.GetStore().Add(app))
+	}
+	return informer
+}
+
+func TestHandleModEvent_LabelsChanged(t *testing.T) {
+	selectingApp := &appv1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "selecting", Namespace: "argocd"},
+		Spec:       appv1.ApplicationSpec{Destination: appv1.ApplicationDestination{ClusterSelector: "env=prod"}},
+	}
+	otherApp := &appv1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "argocd"},
+		Spec:       appv1.ApplicationSpec{Destination: appv1.ApplicationDestination{ClusterSelector: "env=staging"}},
+	}
+	var notified map[string]bool
+	clustersCache := liveStateCache{
+		clusterSpecs: map[string]*appv1.Cluster{},
+		clusters:     map[string]cache.ClusterCache{},
+		appInformer:  newAppInformerWithApps(t, selectingApp, otherApp),
+		settingsMgr:  settings.NewSettingsManager(context.Background(), fake.NewSimpleClientset(), "argocd"),
+		onObjectUpdated: func(managedByApp map[string]bool, ref v1.ObjectReference) {
+			notified = managedByApp
+		},
+	}
+
+	clustersCache.handleModEvent(&appv1.Cluster{
+		Server: "https://mycluster",
+		Labels: map[string]string{"env": "prod"},
+	}, &appv1.Cluster{
+		Server: "https://mycluster",
+		Labels: map[string]string{"env": "dev"},
+	})
+
+	assert.Equal(t, map[string]bool{"selecting": true}, notified)
+}
+
+func TestIsClusterHasApps_ClusterSelector(t *testing.T) {
+	clustersCache := liveStateCache{}
+	apps := []interface{}{&appv1.Application{
+		Spec: appv1.ApplicationSpec{Destination: appv1.ApplicationDestination{ClusterSelector: "env=prod"}},
+	}}
+
+	assert.True(t, clustersCache.isClusterHasApps(apps, &appv1.Cluster{Server: "https://prod", Labels: map[string]string{"env": "prod"}}))
+	assert.False(t, clustersCache.isClusterHasApps(apps, &appv1.Cluster{Server: "https://staging", Labels: map[string]string{"env": "staging"}}))
+}
+
+func TestInvalidateUnhandledClusters(t *testing.T) {
+	handledCluster := &mocks.ClusterCache{}
+	handledCluster.On("Invalidate", mock.Anything).Panic("should not invalidate")
+	unhandledCluster := &mocks.ClusterCache{}
+	unhandledCluster.On("Invalidate").Return(nil).Once()
+
+	db := &dbmocks.ArgoDB{}
+	db.On("ListClusters", mock.Anything).Return(&appv1.ClusterList{Items: []appv1.Cluster{
+		{Server: "https://handled"},
+		{Server: "https://unhandled"},
+	}}, nil)
+	clustersCache := liveStateCache{
+		db:           db,
+		clusterSpecs: map[string]*appv1.Cluster{},
+		clusters: map[string]cache.ClusterCache{
+			"https://handled":   handledCluster,
+			"https://unhandled": unhandledCluster,
+		},
+		clusterFilter: func(cluster *appv1.Cluster) bool {
+			return cluster.Server == "https://handled"
+		},
+	}
+
+	clustersCache.InvalidateUnhandledClusters()
+
+	assert.Len(t, clustersCache.clusters, 1)
+	assert.Contains(t, clustersCache.clusters, "https://handled")
+	unhandledCluster.AssertExpectations(t)
+}
+
 func TestHandleModEvent_NoChanges(t *testing.T) {
 	clusterCache := &mocks.ClusterCache{}
 	clusterCache.On("Invalidate", mock.Anything).Panic("should not invalidate")
 	clusterCache.On("EnsureSynced").Return(nil).Panic("should not re-sync")
 
 	clustersCache := liveStateCache{
+		clusterSpecs: map[string]*appv1.Cluster{},
 		clusters: map[string]cache.ClusterCache{
 			"https://mycluster": clusterCache,
 		},
@@ -93,9 +180,55 @@ func TestHandleModEvent_NoChanges(t *testing.T) {
 	})
 }
 
+func TestHandleModEvent_ResourceExclusionsChanged(t *testing.T) {
+	clusterCache := &mocks.ClusterCache{}
+	clusterCache.On("Invalidate", mock.Anything).Return(nil).Once()
+	clusterCache.On("EnsureSynced").Return(nil).Maybe()
+
+	clustersCache := liveStateCache{
+		clusterSpecs: map[string]*appv1.Cluster{},
+		clusters: map[string]cache.ClusterCache{
+			"https://mycluster": clusterCache,
+		},
+		cacheSettings: cacheSettings{clusterSettings: cache.Settings{ResourcesFilter: &settings.ResourcesFilter{}}},
+	}
+
+	clustersCache.handleModEvent(&appv1.Cluster{
+		Server: "https://mycluster",
+	}, &appv1.Cluster{
+		Server:             "https://mycluster",
+		ResourceExclusions: "- apiGroups: [metrics.k8s.io]",
+	})
+
+	clusterCache.AssertExpectations(t)
+}
+
+func TestGetClusterSettings(t *testing.T) {
+	globalSettings := cache.Settings{ResourcesFilter: &settings.ResourcesFilter{
+		ResourceExclusions: []settings.FilteredResource{{APIGroups: []string{"group1"}}},
+	}}
+
+	clusterSettings, err := getClusterSettings(globalSettings, &appv1.Cluster{Server: "https://mycluster"})
+	assert.NoError(t, err)
+	assert.True(t, clusterSettings.ResourcesFilter.IsExcludedResource("group1", "Kind1", "https://mycluster"))
+
+	clusterSettings, err = getClusterSettings(globalSettings, &appv1.Cluster{
+		Server:             "https://mycluster",
+		ResourceExclusions: "- apiGroups: [metrics.k8s.io]",
+	})
+	assert.NoError(t, err)
+	assert.False(t, clusterSettings.ResourcesFilter.IsExcludedResource("group1", "Kind1", "https://mycluster"))
+	assert.True(t, clusterSettings.ResourcesFilter.IsExcludedResource("metrics.k8s.io", "PodMetrics", "https://mycluster"))
+	assert.True(t, globalSettings.ResourcesFilter.IsExcludedResource("group1", "Kind1", "https://mycluster"))
+
+	_, err = getClusterSettings(globalSettings, &appv1.Cluster{Server: "https://mycluster", ResourceInclusions: "invalid"})
+	assert.Error(t, err)
+}
+
 func TestHandleAddEvent_ClusterExcluded(t *testing.T) {
 	clustersCache := liveStateCache{
-		clusters: map[string]cache.ClusterCache{},
+		clusterSpecs: map[string]*appv1.Cluster{},
+		clusters:     map[string]cache.ClusterCache{},
 		clusterFilter: func(cluster *appv1.Cluster) bool {
 			return false
 		},
@@ -25,6 +25,29 @@ type LiveStateCache struct {
 	mock.Mock
 }
 
+// GetCluster provides a mock function with given fields: server
+func (_m *LiveStateCache) GetCluster(server string) (*v1alpha1.Cluster, error) {
+	ret := _m.Called(server)
+
+	var r0 *v1alpha1.Cluster
+	if rf, ok := ret.Get(0).(func(string) *v1alpha1.Cluster); ok {
+		r0 = rf(server)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.Cluster)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(server)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetClusterCache provides a mock function with given fields: server
 func (_m *LiveStateCache) GetClusterCache(server string) (cache.ClusterCache, error) {
 	ret := _m.Called(server)
@@ -154,6 +177,11 @@ func (_m *LiveStateCache) Init() error {
 	return r0
 }
 
+// InvalidateUnhandledClusters provides a mock function with given fields:
+func (_m *LiveStateCache) InvalidateUnhandledClusters() {
+	_m.Called()
+}
+
 // IsNamespaced provides a mock function with given fields: server, gk
 func (_m *LiveStateCache) IsNamespaced(server string, gk schema.GroupKind) (bool, error) {
 	ret := _m.Called(server, gk)
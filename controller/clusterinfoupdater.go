@@ -28,6 +28,7 @@ type clusterInfoUpdater struct {
 	appLister     v1alpha1.ApplicationNamespaceLister
 	cache         *appstatecache.Cache
 	clusterFilter func(cluster *appv1.Cluster) bool
+	shard         int
 	projGetter    func(app *appv1.Application) (*appv1.AppProject, error)
 	namespace     string
 }
@@ -38,10 +39,11 @@ func NewClusterInfoUpdater(
 	appLister v1alpha1.ApplicationNamespaceLister,
 	cache *appstatecache.Cache,
 	clusterFilter func(cluster *appv1.Cluster) bool,
+	shard int,
 	projGetter func(app *appv1.Application) (*appv1.AppProject, error),
 	namespace string) *clusterInfoUpdater {
 
-	return &clusterInfoUpdater{infoSource, db, appLister, cache, clusterFilter, projGetter, namespace}
+	return &clusterInfoUpdater{infoSource, db, appLister, cache, clusterFilter, shard, projGetter, namespace}
 }
 
 func (c *clusterInfoUpdater) Run(ctx context.Context) {
@@ -115,20 +117,28 @@ func (c *clusterInfoUpdater) updateClusterInfo(cluster appv1.Cluster, info *cach
 		ConnectionState:   appv1.ConnectionState{ModifiedAt: &now},
 		ApplicationsCount: appCount,
 	}
+	if c.shard >= 0 {
+		shard := int64(c.shard)
+		clusterInfo.Shard = &shard
+	}
 	if info != nil {
 		clusterInfo.ServerVersion = info.K8SVersion
 		clusterInfo.APIVersions = argo.APIResourcesToStrings(info.APIResources, true)
 		if info.LastCacheSyncTime == nil {
 			clusterInfo.ConnectionState.Status = appv1.ConnectionStatusUnknown
-		} else if info.SyncError == nil {
-			clusterInfo.ConnectionState.Status = appv1.ConnectionStatusSuccessful
+		} else {
+			// the statistics of the cache are reported even if the last synchronization failed, since they describe
+			// the resources which are still cached and help to diagnose the failure
 			syncTime := metav1.NewTime(*info.LastCacheSyncTime)
 			clusterInfo.CacheInfo.LastCacheSyncTime = &syncTime
 			clusterInfo.CacheInfo.APIsCount = int64(info.APIsCount)
 			clusterInfo.CacheInfo.ResourcesCount = int64(info.ResourcesCount)
-		} else {
-			clusterInfo.ConnectionState.Status = appv1.ConnectionStatusFailed
-			clusterInfo.ConnectionState.Message = info.SyncError.Error()
+			if info.SyncError == nil {
+				clusterInfo.ConnectionState.Status = appv1.ConnectionStatusSuccessful
+			} else {
+				clusterInfo.ConnectionState.Status = appv1.ConnectionStatusFailed
+				clusterInfo.ConnectionState.Message = info.SyncError.Error()
+			}
 		}
 	} else {
 		clusterInfo.ConnectionState.Status = appv1.ConnectionStatusUnknown
@@ -83,10 +83,12 @@ func TestClusterSecretUpdater(t *testing.T) {
 			K8SVersion:        updatedK8sVersion,
 			LastCacheSyncTime: test.LastCacheSyncTime,
 			SyncError:         test.SyncError,
+			ResourcesCount:    10,
+			APIsCount:         2,
 		}
 
 		lister := applisters.NewApplicationLister(appInformer.GetIndexer()).Applications(fakeNamespace)
-		updater := NewClusterInfoUpdater(nil, argoDB, lister, appCache, nil, nil, fakeNamespace)
+		updater := NewClusterInfoUpdater(nil, argoDB, lister, appCache, nil, -1, nil, fakeNamespace)
 
 		err = updater.updateClusterInfo(*cluster, info)
 		assert.NoError(t, err, "Invoking updateClusterInfo failed.")
@@ -96,5 +98,28 @@ func TestClusterSecretUpdater(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, updatedK8sVersion, clusterInfo.ServerVersion)
 		assert.Equal(t, test.ExpectedStatus, clusterInfo.ConnectionState.Status)
+		assert.Nil(t, clusterInfo.Shard)
+		if test.LastCacheSyncTime == nil {
+			assert.Nil(t, clusterInfo.CacheInfo.LastCacheSyncTime)
+		} else {
+			assert.NotNil(t, clusterInfo.CacheInfo.LastCacheSyncTime)
+			assert.Equal(t, int64(10), clusterInfo.CacheInfo.ResourcesCount)
+			assert.Equal(t, int64(2), clusterInfo.CacheInfo.APIsCount)
+		}
 	}
+
+	t.Run("Shard", func(t *testing.T) {
+		lister := applisters.NewApplicationLister(appInformer.GetIndexer()).Applications(fakeNamespace)
+		updater := NewClusterInfoUpdater(nil, argoDB, lister, appCache, nil, 1, nil, fakeNamespace)
+
+		err = updater.updateClusterInfo(*cluster, nil)
+		assert.NoError(t, err, "Invoking updateClusterInfo failed.")
+
+		var clusterInfo v1alpha1.ClusterInfo
+		err = appCache.GetClusterInfo(cluster.Server, &clusterInfo)
+		assert.NoError(t, err)
+		if assert.NotNil(t, clusterInfo.Shard) {
+			assert.Equal(t, int64(1), *clusterInfo.Shard)
+		}
+	})
 }
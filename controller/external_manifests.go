@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/argoproj/argo-cd/v2/util/argo"
+)
+
+// getExternalManifests returns the externally rendered manifests which were submitted for the given revision of the
+// application. The revision is resolved by the repo server and the signature of the manifests is verified again, so
+// that manifests which were tampered with after their submission or became untrusted are never deployed.
+func (m *appStateManager) getExternalManifests(ctx context.Context, repoClient apiclient.RepoServerServiceClient, repo *v1alpha1.Repository, app *v1alpha1.Application, revision string, appLabelKey string) (*apiclient.ManifestResponse, error) {
+	res, err := repoClient.ResolveRevision(ctx, &apiclient.ResolveRevisionRequest{
+		Repo:              repo,
+		App:               app,
+		AmbiguousRevision: revision,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error resolving revision %s: %w", revision, err)
+	}
+	return argo.GetExternalManifestsResponse(ctx, m.db, m.settingsMgr, app, res.Revision, appLabelKey, app.InstanceName(m.namespace))
+}
@@ -19,6 +19,7 @@ import (
 
 	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	applister "github.com/argoproj/argo-cd/v2/pkg/client/listers/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/clusterauth"
 	"github.com/argoproj/argo-cd/v2/util/git"
 	"github.com/argoproj/argo-cd/v2/util/healthz"
 	"github.com/argoproj/argo-cd/v2/util/profile"
@@ -59,6 +60,12 @@ var (
 		append(descAppDefaultLabels, "repo", "dest_server", "dest_namespace", "sync_status", "health_status", "operation"),
 		nil,
 	)
+	descAppOperationsPending = prometheus.NewDesc(
+		"argocd_app_operations_pending",
+		"Number of applications with a requested or running operation.",
+		nil,
+		nil,
+	)
 	// DEPRECATED
 	descAppCreated = prometheus.NewDesc(
 		"argocd_app_created_time",
@@ -177,6 +184,7 @@ func NewMetricsServer(addr string, appLister applister.ApplicationLister, appFil
 	registry.MustRegister(clusterEventsCounter)
 	registry.MustRegister(redisRequestCounter)
 	registry.MustRegister(redisRequestHistogram)
+	registry.MustRegister(clusterauth.ExecCredentialCollectors()...)
 
 	return &MetricsServer{
 		registry: registry,
@@ -331,6 +339,7 @@ func (c *appCollector) Describe(ch chan<- *prometheus.Desc) {
 		ch <- descAppLabels
 	}
 	ch <- descAppInfo
+	ch <- descAppOperationsPending
 	ch <- descAppSyncStatusCode
 	ch <- descAppHealthStatus
 }
@@ -342,11 +351,16 @@ func (c *appCollector) Collect(ch chan<- prometheus.Metric) {
 		log.Warnf("Failed to collect applications: %v", err)
 		return
 	}
+	operationsPending := 0
 	for _, app := range apps {
 		if c.appFilter(app) {
 			c.collectApps(ch, app)
+			if app.Operation != nil {
+				operationsPending++
+			}
 		}
 	}
+	ch <- prometheus.MustNewConstMetric(descAppOperationsPending, prometheus.GaugeValue, float64(operationsPending))
 }
 
 func boolFloat64(b bool) float64 {
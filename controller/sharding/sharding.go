@@ -9,6 +9,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/argoproj/argo-cd/v2/common"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -16,6 +18,8 @@ import (
 	"github.com/argoproj/argo-cd/v2/util/db"
 	"github.com/argoproj/argo-cd/v2/util/env"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // Make it overridable for testing
@@ -23,14 +27,42 @@ var osHostnameFunction = os.Hostname
 
 type DistributionFunction func(c *v1alpha1.Cluster) int
 type ClusterFilterFunction func(c *v1alpha1.Cluster) bool
+type AppFilterFunction func(app *v1alpha1.Application) bool
+
+// Replicas is the number of application controller replicas which clusters and applications are distributed across. It
+// is the number determined by the dynamic cluster distribution if it was set, otherwise the one configured using the
+// ARGOCD_CONTROLLER_REPLICAS environment variable. A nil Replicas always uses the environment variable.
+type Replicas struct {
+	dynamic int32
+}
+
+// NewReplicas returns a number of replicas which is configured using the environment variable until it is set
+func NewReplicas() *Replicas {
+	return &Replicas{}
+}
+
+// Get returns the number of application controller replicas
+func (r *Replicas) Get() int {
+	if r != nil {
+		if replicas := atomic.LoadInt32(&r.dynamic); replicas > 0 {
+			return int(replicas)
+		}
+	}
+	return env.ParseNumFromEnv(common.EnvControllerReplicas, 0, 0, math.MaxInt32)
+}
+
+// Set overrides the number of application controller replicas, and returns whether it changed
+func (r *Replicas) Set(replicas int) bool {
+	return atomic.SwapInt32(&r.dynamic, int32(replicas)) != int32(replicas)
+}
 
 // GetClusterFilter returns a ClusterFilterFunction which is a function taking a cluster as a parameter
 // and returns wheter or not the cluster should be processed by a given shard. It calls the distributionFunction
 // to determine which shard will process the cluster, and if the given shard is equal to the calculated shard
 // the function will return true.
-func GetClusterFilter(distributionFunction DistributionFunction, shard int) ClusterFilterFunction {
-	replicas := env.ParseNumFromEnv(common.EnvControllerReplicas, 0, 0, math.MaxInt32)
+func GetClusterFilter(distributionFunction DistributionFunction, shard int, replicas *Replicas) ClusterFilterFunction {
 	return func(c *v1alpha1.Cluster) bool {
+		replicas := replicas.Get()
 		clusterShard := 0
 		if c != nil && c.Shard != nil {
 			requestedShard := int(*c.Shard)
@@ -46,16 +78,53 @@ func GetClusterFilter(distributionFunction DistributionFunction, shard int) Clus
 	}
 }
 
+// IsAppShardingAlgorithm returns whether the sharding algorithm distributes applications instead of clusters across shards
+func IsAppShardingAlgorithm(shardingAlgorithm string) bool {
+	return shardingAlgorithm == common.ProjectShardingAlgorithm || shardingAlgorithm == common.LabelShardingAlgorithm
+}
+
+// GetAppFilter returns an AppFilterFunction which returns whether or not the application should be processed by the
+// given shard. With the project algorithm applications are distributed by their project. With the label algorithm
+// applications are distributed by the value of the given label: a numeric value lower than the number of replicas
+// assigns the application to that shard, any other value is distributed by its hash, and applications without the
+// label are distributed by their project.
+func GetAppFilter(shardingAlgorithm string, shardingLabel string, shard int, replicas *Replicas) AppFilterFunction {
+	return func(app *v1alpha1.Application) bool {
+		return getAppShard(app, shardingAlgorithm, shardingLabel, replicas.Get()) == shard
+	}
+}
+
+func getAppShard(app *v1alpha1.Application, shardingAlgorithm string, shardingLabel string, replicas int) int {
+	if replicas == 0 {
+		return -1
+	}
+	key := app.Spec.GetProject()
+	if shardingAlgorithm == common.LabelShardingAlgorithm {
+		if value, ok := app.GetLabels()[shardingLabel]; ok {
+			if requestedShard, err := strconv.Atoi(value); err == nil {
+				if requestedShard >= 0 && requestedShard < replicas {
+					return requestedShard
+				}
+				log.Warnf("Specified shard (%d) for application %s is greater than the number of available shards. Assigning automatically.", requestedShard, app.QualifiedName())
+			}
+			key = value
+		}
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(replicas))
+}
+
 // GetDistributionFunction returns which DistributionFunction should be used based on the passed algorithm and
 // the current datas.
-func GetDistributionFunction(db db.ArgoDB, shardingAlgorithm string) DistributionFunction {
+func GetDistributionFunction(db db.ArgoDB, shardingAlgorithm string, replicas *Replicas) DistributionFunction {
 	log.Infof("Using filter function:  %s", shardingAlgorithm)
-	distributionFunction := LegacyDistributionFunction()
+	distributionFunction := LegacyDistributionFunction(replicas)
 	switch shardingAlgorithm {
 	case common.RoundRobinShardingAlgorithm:
-		distributionFunction = RoundRobinDistributionFunction(db)
+		distributionFunction = RoundRobinDistributionFunction(db, replicas)
 	case common.LegacyShardingAlgorithm:
-		distributionFunction = LegacyDistributionFunction()
+		distributionFunction = LegacyDistributionFunction(replicas)
 	default:
 		log.Warnf("distribution type %s is not supported, defaulting to %s", shardingAlgorithm, common.DefaultShardingAlgorithm)
 	}
@@ -67,9 +136,9 @@ func GetDistributionFunction(db db.ArgoDB, shardingAlgorithm string) Distributio
 // is lightweight and can be distributed easily, however, it does not ensure an homogenous distribution as
 // some shards may get assigned more clusters than others. It is the legacy function distribution that is
 // kept for compatibility reasons
-func LegacyDistributionFunction() DistributionFunction {
-	replicas := env.ParseNumFromEnv(common.EnvControllerReplicas, 0, 0, math.MaxInt32)
+func LegacyDistributionFunction(replicas *Replicas) DistributionFunction {
 	return func(c *v1alpha1.Cluster) int {
+		replicas := replicas.Get()
 		if replicas == 0 {
 			return -1
 		}
@@ -96,9 +165,9 @@ func LegacyDistributionFunction() DistributionFunction {
 // This function ensures an homogenous distribution: each shards got assigned the same number of
 // clusters +/-1 , but with the drawback of a reshuffling of clusters accross shards in case of some changes
 // in the cluster list
-func RoundRobinDistributionFunction(db db.ArgoDB) DistributionFunction {
-	replicas := env.ParseNumFromEnv(common.EnvControllerReplicas, 0, 0, math.MaxInt32)
+func RoundRobinDistributionFunction(db db.ArgoDB, replicas *Replicas) DistributionFunction {
 	return func(c *v1alpha1.Cluster) int {
+		replicas := replicas.Get()
 		if replicas > 0 {
 			if c == nil { // in-cluster does not necessarly have a secret assigned. So we are receiving a nil cluster here.
 				return 0
@@ -119,6 +188,40 @@ func RoundRobinDistributionFunction(db db.ArgoDB) DistributionFunction {
 	}
 }
 
+// GetControllerReplicas returns the number of replicas of the application controller StatefulSet in the given namespace
+func GetControllerReplicas(ctx context.Context, kubeClient kubernetes.Interface, namespace string) (int, error) {
+	statefulSet, err := kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, common.ApplicationControllerName, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error getting application controller statefulset: %w", err)
+	}
+	if statefulSet.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return int(*statefulSet.Spec.Replicas), nil
+}
+
+// WatchControllerReplicas periodically checks the number of replicas of the application controller StatefulSet until
+// the context is done. If the number changed, the given replicas are set to it and onChange is called, so that the shard
+// can rebalance the clusters and applications it processes.
+func WatchControllerReplicas(ctx context.Context, kubeClient kubernetes.Interface, namespace string, interval time.Duration, replicas *Replicas, onChange func(replicas int)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		current, err := GetControllerReplicas(ctx, kubeClient, namespace)
+		if err != nil {
+			log.Warnf("Failed to check the number of application controller replicas: %v", err)
+		} else if current > 0 && replicas.Set(current) {
+			log.Infof("Number of application controller replicas changed to %d, rebalancing shards", current)
+			onChange(current)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // InferShard extracts the shard index based on its hostname.
 func InferShard() (int, error) {
 	hostname, err := osHostnameFunction()
@@ -1,44 +1,49 @@
 package sharding
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/argoproj/argo-cd/v2/common"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	dbmocks "github.com/argoproj/argo-cd/v2/util/db/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 )
 
 func TestGetShardByID_NotEmptyID(t *testing.T) {
 	os.Setenv(common.EnvControllerReplicas, "1")
-	assert.Equal(t, 0, LegacyDistributionFunction()(&v1alpha1.Cluster{ID: "1"}))
-	assert.Equal(t, 0, LegacyDistributionFunction()(&v1alpha1.Cluster{ID: "2"}))
-	assert.Equal(t, 0, LegacyDistributionFunction()(&v1alpha1.Cluster{ID: "3"}))
-	assert.Equal(t, 0, LegacyDistributionFunction()(&v1alpha1.Cluster{ID: "4"}))
+	assert.Equal(t, 0, LegacyDistributionFunction(nil)(&v1alpha1.Cluster{ID: "1"}))
+	assert.Equal(t, 0, LegacyDistributionFunction(nil)(&v1alpha1.Cluster{ID: "2"}))
+	assert.Equal(t, 0, LegacyDistributionFunction(nil)(&v1alpha1.Cluster{ID: "3"}))
+	assert.Equal(t, 0, LegacyDistributionFunction(nil)(&v1alpha1.Cluster{ID: "4"}))
 }
 
 func TestGetShardByID_EmptyID(t *testing.T) {
 	os.Setenv(common.EnvControllerReplicas, "1")
 	distributionFunction := LegacyDistributionFunction
-	shard := distributionFunction()(&v1alpha1.Cluster{})
+	shard := distributionFunction(nil)(&v1alpha1.Cluster{})
 	assert.Equal(t, 0, shard)
 }
 
 func TestGetShardByID_NoReplicas(t *testing.T) {
 	os.Setenv(common.EnvControllerReplicas, "0")
 	distributionFunction := LegacyDistributionFunction
-	shard := distributionFunction()(&v1alpha1.Cluster{})
+	shard := distributionFunction(nil)(&v1alpha1.Cluster{})
 	assert.Equal(t, -1, shard)
 }
 
 func TestGetShardByID_NoReplicasUsingHashDistributionFunction(t *testing.T) {
 	os.Setenv(common.EnvControllerReplicas, "0")
 	distributionFunction := LegacyDistributionFunction
-	shard := distributionFunction()(&v1alpha1.Cluster{})
+	shard := distributionFunction(nil)(&v1alpha1.Cluster{})
 	assert.Equal(t, -1, shard)
 }
 
@@ -47,7 +52,7 @@ func TestGetShardByID_NoReplicasUsingHashDistributionFunctionWithClusters(t *tes
 	// Test with replicas set to 0
 	os.Setenv(common.EnvControllerReplicas, "0")
 	os.Setenv(common.EnvControllerShardingAlgorithm, common.RoundRobinShardingAlgorithm)
-	distributionFunction := RoundRobinDistributionFunction(db)
+	distributionFunction := RoundRobinDistributionFunction(db, nil)
 	assert.Equal(t, -1, distributionFunction(nil))
 	assert.Equal(t, -1, distributionFunction(&cluster1))
 	assert.Equal(t, -1, distributionFunction(&cluster2))
@@ -61,7 +66,7 @@ func TestGetClusterFilterDefault(t *testing.T) {
 	shardIndex := 1 // ensuring that a shard with index 1 will process all the clusters with an "even" id (2,4,6,...)
 	os.Unsetenv(common.EnvControllerShardingAlgorithm)
 	os.Setenv(common.EnvControllerReplicas, "2")
-	filter := GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm), shardIndex)
+	filter := GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "1"}))
 	assert.True(t, filter(&v1alpha1.Cluster{ID: "2"}))
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "3"}))
@@ -72,7 +77,7 @@ func TestGetClusterFilterLegacy(t *testing.T) {
 	shardIndex := 1 // ensuring that a shard with index 1 will process all the clusters with an "even" id (2,4,6,...)
 	os.Setenv(common.EnvControllerReplicas, "2")
 	os.Setenv(common.EnvControllerShardingAlgorithm, common.LegacyShardingAlgorithm)
-	filter := GetClusterFilter(GetDistributionFunction(nil, common.LegacyShardingAlgorithm), shardIndex)
+	filter := GetClusterFilter(GetDistributionFunction(nil, common.LegacyShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "1"}))
 	assert.True(t, filter(&v1alpha1.Cluster{ID: "2"}))
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "3"}))
@@ -83,7 +88,7 @@ func TestGetClusterFilterUnknown(t *testing.T) {
 	shardIndex := 1 // ensuring that a shard with index 1 will process all the clusters with an "even" id (2,4,6,...)
 	os.Setenv(common.EnvControllerReplicas, "2")
 	os.Setenv(common.EnvControllerShardingAlgorithm, "unknown")
-	filter := GetClusterFilter(GetDistributionFunction(nil, "unknown"), shardIndex)
+	filter := GetClusterFilter(GetDistributionFunction(nil, "unknown", nil), shardIndex, nil)
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "1"}))
 	assert.True(t, filter(&v1alpha1.Cluster{ID: "2"}))
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "3"}))
@@ -93,7 +98,7 @@ func TestGetClusterFilterUnknown(t *testing.T) {
 func TestLegacyGetClusterFilterWithFixedShard(t *testing.T) {
 	shardIndex := 1 // ensuring that a shard with index 1 will process all the clusters with an "even" id (2,4,6,...)
 	os.Setenv(common.EnvControllerReplicas, "2")
-	filter := GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm), shardIndex)
+	filter := GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, filter(nil))
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "1"}))
 	assert.True(t, filter(&v1alpha1.Cluster{ID: "2"}))
@@ -101,11 +106,11 @@ func TestLegacyGetClusterFilterWithFixedShard(t *testing.T) {
 	assert.True(t, filter(&v1alpha1.Cluster{ID: "4"}))
 
 	var fixedShard int64 = 4
-	filter = GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm), int(fixedShard))
+	filter = GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm, nil), int(fixedShard), nil)
 	assert.False(t, filter(&v1alpha1.Cluster{ID: "4", Shard: &fixedShard}))
 
 	fixedShard = 1
-	filter = GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm), int(fixedShard))
+	filter = GetClusterFilter(GetDistributionFunction(nil, common.DefaultShardingAlgorithm, nil), int(fixedShard), nil)
 	assert.True(t, filter(&v1alpha1.Cluster{Name: "cluster4", ID: "4", Shard: &fixedShard}))
 
 }
@@ -115,7 +120,7 @@ func TestRoundRobinGetClusterFilterWithFixedShard(t *testing.T) {
 	os.Setenv(common.EnvControllerReplicas, "2")
 	db, cluster1, cluster2, cluster3, cluster4, _ := createTestClusters()
 
-	filter := GetClusterFilter(GetDistributionFunction(db, common.RoundRobinShardingAlgorithm), shardIndex)
+	filter := GetClusterFilter(GetDistributionFunction(db, common.RoundRobinShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, filter(nil))
 	assert.False(t, filter(&cluster1))
 	assert.True(t, filter(&cluster2))
@@ -125,11 +130,11 @@ func TestRoundRobinGetClusterFilterWithFixedShard(t *testing.T) {
 	// a cluster with a fixed shard should be processed by the specified exact
 	// same shard unless the specified shard index is greater than the number of replicas.
 	var fixedShard int64 = 4
-	filter = GetClusterFilter(GetDistributionFunction(db, common.RoundRobinShardingAlgorithm), int(fixedShard))
+	filter = GetClusterFilter(GetDistributionFunction(db, common.RoundRobinShardingAlgorithm, nil), int(fixedShard), nil)
 	assert.False(t, filter(&v1alpha1.Cluster{Name: "cluster4", ID: "4", Shard: &fixedShard}))
 
 	fixedShard = 1
-	filter = GetClusterFilter(GetDistributionFunction(db, common.RoundRobinShardingAlgorithm), int(fixedShard))
+	filter = GetClusterFilter(GetDistributionFunction(db, common.RoundRobinShardingAlgorithm, nil), int(fixedShard), nil)
 	assert.True(t, filter(&v1alpha1.Cluster{Name: "cluster4", ID: "4", Shard: &fixedShard}))
 }
 
@@ -138,7 +143,7 @@ func TestGetClusterFilterLegacyHash(t *testing.T) {
 	os.Setenv(common.EnvControllerReplicas, "2")
 	os.Setenv(common.EnvControllerShardingAlgorithm, "hash")
 	db, cluster1, cluster2, cluster3, cluster4, _ := createTestClusters()
-	filter := GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm), shardIndex)
+	filter := GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, filter(&cluster1))
 	assert.True(t, filter(&cluster2))
 	assert.False(t, filter(&cluster3))
@@ -147,11 +152,11 @@ func TestGetClusterFilterLegacyHash(t *testing.T) {
 	// a cluster with a fixed shard should be processed by the specified exact
 	// same shard unless the specified shard index is greater than the number of replicas.
 	var fixedShard int64 = 4
-	filter = GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm), int(fixedShard))
+	filter = GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm, nil), int(fixedShard), nil)
 	assert.False(t, filter(&v1alpha1.Cluster{Name: "cluster4", ID: "4", Shard: &fixedShard}))
 
 	fixedShard = 1
-	filter = GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm), int(fixedShard))
+	filter = GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm, nil), int(fixedShard), nil)
 	assert.True(t, filter(&v1alpha1.Cluster{Name: "cluster4", ID: "4", Shard: &fixedShard}))
 }
 
@@ -160,7 +165,7 @@ func TestGetClusterFilterWithEnvControllerShardingAlgorithms(t *testing.T) {
 	shardIndex := 1
 	os.Setenv(common.EnvControllerReplicas, "2")
 	os.Setenv(common.EnvControllerShardingAlgorithm, common.LegacyShardingAlgorithm)
-	shardShouldProcessCluster := GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm), shardIndex)
+	shardShouldProcessCluster := GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, shardShouldProcessCluster(&cluster1))
 	assert.True(t, shardShouldProcessCluster(&cluster2))
 	assert.False(t, shardShouldProcessCluster(&cluster3))
@@ -168,7 +173,7 @@ func TestGetClusterFilterWithEnvControllerShardingAlgorithms(t *testing.T) {
 	assert.False(t, shardShouldProcessCluster(nil))
 
 	os.Setenv(common.EnvControllerShardingAlgorithm, common.RoundRobinShardingAlgorithm)
-	shardShouldProcessCluster = GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm), shardIndex)
+	shardShouldProcessCluster = GetClusterFilter(GetDistributionFunction(db, common.LegacyShardingAlgorithm, nil), shardIndex, nil)
 	assert.False(t, shardShouldProcessCluster(&cluster1))
 	assert.True(t, shardShouldProcessCluster(&cluster2))
 	assert.False(t, shardShouldProcessCluster(&cluster3))
@@ -180,7 +185,7 @@ func TestGetShardByIndexModuloReplicasCountDistributionFunction2(t *testing.T) {
 	db, cluster1, cluster2, cluster3, cluster4, cluster5 := createTestClusters()
 	// Test with replicas set to 1
 	os.Setenv(common.EnvControllerReplicas, "1")
-	distributionFunction := RoundRobinDistributionFunction(db)
+	distributionFunction := RoundRobinDistributionFunction(db, nil)
 	assert.Equal(t, 0, distributionFunction(nil))
 	assert.Equal(t, 0, distributionFunction(&cluster1))
 	assert.Equal(t, 0, distributionFunction(&cluster2))
@@ -190,7 +195,7 @@ func TestGetShardByIndexModuloReplicasCountDistributionFunction2(t *testing.T) {
 
 	// Test with replicas set to 2
 	os.Setenv(common.EnvControllerReplicas, "2")
-	distributionFunction = RoundRobinDistributionFunction(db)
+	distributionFunction = RoundRobinDistributionFunction(db, nil)
 	assert.Equal(t, 0, distributionFunction(nil))
 	assert.Equal(t, 0, distributionFunction(&cluster1))
 	assert.Equal(t, 1, distributionFunction(&cluster2))
@@ -200,7 +205,7 @@ func TestGetShardByIndexModuloReplicasCountDistributionFunction2(t *testing.T) {
 
 	// // Test with replicas set to 3
 	os.Setenv(common.EnvControllerReplicas, "3")
-	distributionFunction = RoundRobinDistributionFunction(db)
+	distributionFunction = RoundRobinDistributionFunction(db, nil)
 	assert.Equal(t, 0, distributionFunction(nil))
 	assert.Equal(t, 0, distributionFunction(&cluster1))
 	assert.Equal(t, 1, distributionFunction(&cluster2))
@@ -223,7 +228,7 @@ func TestGetShardByIndexModuloReplicasCountDistributionFunctionWhenClusterNumber
 	}
 	db.On("ListClusters", mock.Anything).Return(clusterList, nil)
 	os.Setenv(common.EnvControllerReplicas, "2")
-	distributionFunction := RoundRobinDistributionFunction(&db)
+	distributionFunction := RoundRobinDistributionFunction(&db, nil)
 	for i, c := range clusterList.Items {
 		assert.Equal(t, i%2, distributionFunction(&c))
 	}
@@ -243,7 +248,7 @@ func TestGetShardByIndexModuloReplicasCountDistributionFunctionWhenClusterIsAdde
 
 	// Test with replicas set to 2
 	os.Setenv(common.EnvControllerReplicas, "2")
-	distributionFunction := RoundRobinDistributionFunction(&db)
+	distributionFunction := RoundRobinDistributionFunction(&db, nil)
 	assert.Equal(t, 0, distributionFunction(nil))
 	assert.Equal(t, 0, distributionFunction(&cluster1))
 	assert.Equal(t, 1, distributionFunction(&cluster2))
@@ -265,7 +270,7 @@ func TestGetShardByIndexModuloReplicasCountDistributionFunctionWhenClusterIsAdde
 func TestGetShardByIndexModuloReplicasCountDistributionFunction(t *testing.T) {
 	db, cluster1, cluster2, _, _, _ := createTestClusters()
 	os.Setenv(common.EnvControllerReplicas, "2")
-	distributionFunction := RoundRobinDistributionFunction(db)
+	distributionFunction := RoundRobinDistributionFunction(db, nil)
 
 	// Test that the function returns the correct shard for cluster1 and cluster2
 	expectedShardForCluster1 := 0
@@ -328,3 +333,114 @@ func createCluster(name string, id string) v1alpha1.Cluster {
 	}
 	return cluster
 }
+
+func TestGetAppFilter_Project(t *testing.T) {
+	os.Setenv(common.EnvControllerReplicas, "3")
+	defer os.Unsetenv(common.EnvControllerReplicas)
+	app := func(project string) *v1alpha1.Application {
+		return &v1alpha1.Application{Spec: v1alpha1.ApplicationSpec{Project: project}}
+	}
+	for _, project := range []string{"default", "team-a", "team-b", "team-c"} {
+		shard := getAppShard(app(project), common.ProjectShardingAlgorithm, "", 3)
+		assert.True(t, shard >= 0 && shard < 3)
+		// all applications of a project are processed by the same shard
+		assert.True(t, GetAppFilter(common.ProjectShardingAlgorithm, "", shard, nil)(app(project)))
+		assert.False(t, GetAppFilter(common.ProjectShardingAlgorithm, "", (shard+1)%3, nil)(app(project)))
+	}
+	// applications without project belong to the default project
+	assert.Equal(t, getAppShard(app("default"), common.ProjectShardingAlgorithm, "", 3), getAppShard(app(""), common.ProjectShardingAlgorithm, "", 3))
+}
+
+func TestGetAppFilter_Label(t *testing.T) {
+	os.Setenv(common.EnvControllerReplicas, "3")
+	defer os.Unsetenv(common.EnvControllerReplicas)
+	app := func(labels map[string]string) *v1alpha1.Application {
+		return &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: labels}, Spec: v1alpha1.ApplicationSpec{Project: "team-a"}}
+	}
+	label := common.DefaultShardingLabel
+
+	// numeric values assign the application to the shard
+	assert.True(t, GetAppFilter(common.LabelShardingAlgorithm, label, 2, nil)(app(map[string]string{label: "2"})))
+	assert.False(t, GetAppFilter(common.LabelShardingAlgorithm, label, 1, nil)(app(map[string]string{label: "2"})))
+
+	// shards greater than the number of replicas and other values are distributed by their hash
+	byProject := func(project string) int {
+		return getAppShard(&v1alpha1.Application{Spec: v1alpha1.ApplicationSpec{Project: project}}, common.ProjectShardingAlgorithm, "", 3)
+	}
+	assert.Equal(t, byProject("5"), getAppShard(app(map[string]string{label: "5"}), common.LabelShardingAlgorithm, label, 3))
+	assert.Equal(t, byProject("team-b"), getAppShard(app(map[string]string{label: "team-b"}), common.LabelShardingAlgorithm, label, 3))
+
+	// applications without the label are distributed by their project
+	assert.Equal(t, byProject("team-a"), getAppShard(app(nil), common.LabelShardingAlgorithm, label, 3))
+}
+
+func TestGetAppFilter_NoReplicas(t *testing.T) {
+	os.Setenv(common.EnvControllerReplicas, "0")
+	assert.Equal(t, -1, getAppShard(&v1alpha1.Application{}, common.ProjectShardingAlgorithm, "", 0))
+	assert.False(t, GetAppFilter(common.ProjectShardingAlgorithm, "", 0, nil)(&v1alpha1.Application{}))
+}
+
+func TestIsAppShardingAlgorithm(t *testing.T) {
+	assert.True(t, IsAppShardingAlgorithm(common.ProjectShardingAlgorithm))
+	assert.True(t, IsAppShardingAlgorithm(common.LabelShardingAlgorithm))
+	assert.False(t, IsAppShardingAlgorithm(common.LegacyShardingAlgorithm))
+	assert.False(t, IsAppShardingAlgorithm(common.RoundRobinShardingAlgorithm))
+}
+
+func TestSetReplicas(t *testing.T) {
+	t.Setenv(common.EnvControllerReplicas, "1")
+	replicas := NewReplicas()
+	filter := GetClusterFilter(LegacyDistributionFunction(replicas), 1, replicas)
+	assert.False(t, filter(&v1alpha1.Cluster{ID: "2"}))
+
+	assert.True(t, replicas.Set(2))
+	assert.False(t, replicas.Set(2))
+	assert.Equal(t, 2, replicas.Get())
+	assert.True(t, filter(&v1alpha1.Cluster{ID: "2"}))
+
+	// other filters are not affected
+	assert.Equal(t, 1, NewReplicas().Get())
+	var envReplicas *Replicas
+	assert.Equal(t, 1, envReplicas.Get())
+
+	replicas.Set(0)
+	assert.Equal(t, 1, replicas.Get())
+}
+
+func TestGetControllerReplicas(t *testing.T) {
+	replicas := int32(3)
+	kubeClient := kubefake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: common.ApplicationControllerName, Namespace: "argocd"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	})
+	actual, err := GetControllerReplicas(context.Background(), kubeClient, "argocd")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, actual)
+
+	_, err = GetControllerReplicas(context.Background(), kubeClient, "other")
+	assert.Error(t, err)
+}
+
+func TestWatchControllerReplicas(t *testing.T) {
+	controllerReplicas := NewReplicas()
+	controllerReplicas.Set(1)
+	replicas := int32(2)
+	kubeClient := kubefake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: common.ApplicationControllerName, Namespace: "argocd"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan int, 1)
+	go WatchControllerReplicas(ctx, kubeClient, "argocd", time.Hour, controllerReplicas, func(replicas int) {
+		changed <- replicas
+	})
+
+	select {
+	case replicas := <-changed:
+		assert.Equal(t, 2, replicas)
+		assert.Equal(t, 2, controllerReplicas.Get())
+	case <-time.After(10 * time.Second):
+		t.Fatal("number of replicas wasn't updated")
+	}
+}
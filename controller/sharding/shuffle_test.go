@@ -25,7 +25,7 @@ func TestLargeShuffle(t *testing.T) {
 	db.On("ListClusters", mock.Anything).Return(clusterList, nil)
 	// Test with replicas set to 256
 	os.Setenv(common.EnvControllerReplicas, "256")
-	distributionFunction := RoundRobinDistributionFunction(&db)
+	distributionFunction := RoundRobinDistributionFunction(&db, nil)
 	for i, c := range clusterList.Items {
 		assert.Equal(t, i%2567, distributionFunction(&c))
 	}
@@ -48,7 +48,7 @@ func TestShuffle(t *testing.T) {
 
 	// Test with replicas set to 3
 	os.Setenv(common.EnvControllerReplicas, "3")
-	distributionFunction := RoundRobinDistributionFunction(&db)
+	distributionFunction := RoundRobinDistributionFunction(&db, nil)
 	assert.Equal(t, 0, distributionFunction(nil))
 	assert.Equal(t, 0, distributionFunction(&cluster1))
 	assert.Equal(t, 1, distributionFunction(&cluster2))
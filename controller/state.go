@@ -17,6 +17,8 @@ import (
 	"github.com/argoproj/gitops-engine/pkg/sync/syncwaves"
 	kubeutil "github.com/argoproj/gitops-engine/pkg/utils/kube"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -33,6 +35,7 @@ import (
 	argodiff "github.com/argoproj/argo-cd/v2/util/argo/diff"
 	appstatecache "github.com/argoproj/argo-cd/v2/util/cache/appstate"
 	"github.com/argoproj/argo-cd/v2/util/db"
+	"github.com/argoproj/argo-cd/v2/util/glob"
 	"github.com/argoproj/argo-cd/v2/util/gpg"
 	"github.com/argoproj/argo-cd/v2/util/io"
 	"github.com/argoproj/argo-cd/v2/util/settings"
@@ -79,6 +82,8 @@ type comparisonResult struct {
 	// timings maps phases of comparison to the duration it took to complete (for statistical purposes)
 	timings        map[string]time.Duration
 	diffResultList *diff.DiffResultList
+	// destinationNamespace is the destination namespace of the application with all variables resolved
+	destinationNamespace string
 }
 
 func (res *comparisonResult) GetSyncStatus() *v1alpha1.SyncStatus {
@@ -98,6 +103,7 @@ type appStateManager struct {
 	projInformer          cache.SharedIndexInformer
 	kubectl               kubeutil.Kubectl
 	repoClientset         apiclient.Clientset
+	delegatedRepoServers  []string
 	liveStateCache        statecache.LiveStateCache
 	cache                 *appstatecache.Cache
 	namespace             string
@@ -144,12 +150,21 @@ func (m *appStateManager) getRepoObjs(app *v1alpha1.Application, sources []v1alp
 		return nil, nil, err
 	}
 
+	repoURLPolicy, err := m.settingsMgr.GetRepositoryURLPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ts.AddCheckpoint("build_options_ms")
 	serverVersion, apiResources, err := m.liveStateCache.GetVersionsInfo(app.Spec.Destination.Server)
 	if err != nil {
 		return nil, nil, err
 	}
-	conn, repoClient, err := m.repoClientset.NewRepoServerClient()
+	repoClientset, err := m.getRepoClientset(app.Spec.Destination)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, repoClient, err := repoClientset.NewRepoServerClient()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -169,6 +184,11 @@ func (m *appStateManager) getRepoObjs(app *v1alpha1.Application, sources []v1alp
 			revisions[i] = source.TargetRevision
 		}
 		ts.AddCheckpoint("helm_ms")
+		// the repository policy is checked here as well, so that repositories configured before the policy was changed
+		// or applications referencing unconfigured repositories can't bypass it
+		if !repoURLPolicy.IsPermitted(source.RepoURL) {
+			return nil, nil, fmt.Errorf("repository %s is not permitted by the repository policy", source.RepoURL)
+		}
 		repo, err := m.db.GetRepository(context.Background(), source.RepoURL)
 		if err != nil {
 			return nil, nil, err
@@ -180,29 +200,41 @@ func (m *appStateManager) getRepoObjs(app *v1alpha1.Application, sources []v1alp
 
 		ts.AddCheckpoint("version_ms")
 		log.Debugf("Generating Manifest for source %s revision %s", source, revisions[i])
-		manifestInfo, err := repoClient.GenerateManifest(context.Background(), &apiclient.ManifestRequest{
-			Repo:               repo,
-			Repos:              permittedHelmRepos,
-			Revision:           revisions[i],
-			NoCache:            noCache,
-			NoRevisionCache:    noRevisionCache,
-			AppLabelKey:        appLabelKey,
-			AppName:            app.InstanceName(m.namespace),
-			Namespace:          app.Spec.Destination.Namespace,
-			ApplicationSource:  &source,
-			KustomizeOptions:   kustomizeOptions,
-			KubeVersion:        serverVersion,
-			ApiVersions:        argo.APIResourcesToStrings(apiResources, true),
-			VerifySignature:    verifySignature,
-			HelmRepoCreds:      permittedHelmCredentials,
-			TrackingMethod:     string(argo.GetTrackingMethod(m.settingsMgr)),
-			EnabledSourceTypes: enabledSourceTypes,
-			HelmOptions:        helmOptions,
-			HasMultipleSources: app.Spec.HasMultipleSources(),
-			RefSources:         refSources,
-			ProjectName:        proj.Name,
-			ProjectSourceRepos: proj.Spec.SourceRepos,
-		})
+		ctx, cancel, err := argo.WithManifestGenerateTimeout(context.Background(), app)
+		if err != nil {
+			return nil, nil, err
+		}
+		var manifestInfo *apiclient.ManifestResponse
+		if argo.UsesExternalManifests(app) {
+			manifestInfo, err = m.getExternalManifests(ctx, repoClient, repo, app, revisions[i], appLabelKey)
+		} else {
+			manifestInfo, err = repoClient.GenerateManifest(ctx, &apiclient.ManifestRequest{
+				Repo:                repo,
+				Repos:               permittedHelmRepos,
+				Revision:            revisions[i],
+				NoCache:             noCache,
+				NoRevisionCache:     noRevisionCache,
+				AppLabelKey:         appLabelKey,
+				AppName:             app.InstanceName(m.namespace),
+				Namespace:           app.Spec.Destination.Namespace,
+				ApplicationSource:   &source,
+				KustomizeOptions:    kustomizeOptions,
+				KubeVersion:         serverVersion,
+				ApiVersions:         argo.APIResourcesToStrings(apiResources, true),
+				VerifySignature:     verifySignature,
+				HelmRepoCreds:       permittedHelmCredentials,
+				TrackingMethod:      string(argo.GetTrackingMethod(m.settingsMgr)),
+				EnabledSourceTypes:  enabledSourceTypes,
+				HelmOptions:         helmOptions,
+				HasMultipleSources:  app.Spec.HasMultipleSources(),
+				RefSources:          refSources,
+				ProjectName:         proj.Name,
+				ProjectSourceRepos:  proj.Spec.SourceRepos,
+				RepositoryAllowList: repoURLPolicy.Allow,
+				RepositoryDenyList:  repoURLPolicy.Deny,
+			})
+		}
+		cancel()
 		if err != nil {
 			return nil, nil, err
 		}
@@ -227,6 +259,73 @@ func (m *appStateManager) getRepoObjs(app *v1alpha1.Application, sources []v1alp
 	return targetObjs, manifestInfos, nil
 }
 
+// resolveSpecVariables returns copies of the application and of the sources in which the variables of the spec are
+// resolved, or the application and the sources themselves if the spec uses no variables
+func (m *appStateManager) resolveSpecVariables(app *v1alpha1.Application, sources []v1alpha1.ApplicationSource) (*v1alpha1.Application, []v1alpha1.ApplicationSource, error) {
+	if !argo.HasSpecVariables(&app.Spec) {
+		return app, sources, nil
+	}
+	variables, err := argo.GetSpecVariables(context.Background(), &app.Spec, m.db)
+	if err != nil {
+		return app, sources, err
+	}
+	resolvedApp := app.DeepCopy()
+	resolvedApp.Spec = *argo.ResolveSpecVariables(&app.Spec, variables)
+	resolvedSources := make([]v1alpha1.ApplicationSource, len(sources))
+	for i := range sources {
+		resolvedSources[i] = argo.ResolveSourceVariables(sources[i], variables)
+	}
+	return resolvedApp, resolvedSources, nil
+}
+
+// getRepoClientset returns the Clientset of the repo server which generates the manifests of applications deployed
+// to the given destination. Clusters can delegate manifest generation to a repo server deployed close to the cluster,
+// if the address of that repo server is allowed by the controller.
+func (m *appStateManager) getRepoClientset(destination v1alpha1.ApplicationDestination) (apiclient.Clientset, error) {
+	if destination.Server == "" {
+		if err := argo.ValidateDestination(context.Background(), &destination, m.db); err != nil {
+			// invalid destinations are reported when the destination is validated
+			return m.repoClientset, nil
+		}
+	}
+	cluster, err := m.liveStateCache.GetCluster(destination.Server)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			// unknown destinations are reported when the destination is validated
+			return m.repoClientset, nil
+		}
+		return nil, fmt.Errorf("error getting cluster %s: %w", destination.Server, err)
+	}
+	if cluster.RepoServer == "" {
+		return m.repoClientset, nil
+	}
+	if !glob.MatchStringInList(m.delegatedRepoServers, cluster.RepoServer, false) {
+		return nil, fmt.Errorf("cluster %s delegates manifest generation to repo server %s, which is not in the list of delegated repo servers allowed by the application controller", destination.Server, cluster.RepoServer)
+	}
+	remoteClientset, ok := m.repoClientset.(apiclient.RemoteClientset)
+	if !ok {
+		return nil, fmt.Errorf("cluster %s delegates manifest generation to repo server %s, which is not supported by the repo server client", destination.Server, cluster.RepoServer)
+	}
+	return remoteClientset.ForAddress(cluster.RepoServer), nil
+}
+
+// getClusterResourcesFilter returns the resources filter of the given cluster, which uses the resource inclusions and
+// exclusions of the cluster secret if they are set. The given filter is returned if the cluster doesn't override it.
+func (m *appStateManager) getClusterResourcesFilter(resFilter *settings.ResourcesFilter, server string) (*settings.ResourcesFilter, error) {
+	cluster, err := m.db.GetCluster(context.Background(), server)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return resFilter, nil
+		}
+		return resFilter, fmt.Errorf("error getting cluster %s: %w", server, err)
+	}
+	clusterFilter, err := settings.GetClusterResourcesFilter(resFilter, cluster)
+	if err != nil {
+		return resFilter, err
+	}
+	return clusterFilter, nil
+}
+
 func unmarshalManifests(manifests []string) ([]*unstructured.Unstructured, error) {
 	targetObjs := make([]*unstructured.Unstructured, 0)
 	for _, manifest := range manifests {
@@ -321,6 +420,10 @@ func verifyGnuPGSignature(revision string, project *v1alpha1.AppProject, manifes
 					verifyResult.Cipher, verifyResult.KeyID)
 				conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: msg, LastTransitionTime: &now})
 			}
+		case gpg.VerifyResultBad:
+			msg := fmt.Sprintf("Found bad signature made with %s key %s on revision '%s', the commit may have been tampered with",
+				verifyResult.Cipher, verifyResult.KeyID, revision)
+			conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: msg, LastTransitionTime: &now})
 		case gpg.VerifyResultInvalid:
 			msg := fmt.Sprintf("Found signature made with %s key %s, but verification result was invalid: '%s'",
 				verifyResult.Cipher, verifyResult.KeyID, verifyResult.Message)
@@ -337,6 +440,15 @@ func verifyGnuPGSignature(revision string, project *v1alpha1.AppProject, manifes
 	return conditions
 }
 
+// appSyncOptions returns the sync options of the sync policy of the application, or of the default sync policy of the
+// project if the application has none
+func appSyncOptions(app *v1alpha1.Application, project *v1alpha1.AppProject) v1alpha1.SyncOptions {
+	if syncPolicy := project.GetSyncPolicy(app); syncPolicy != nil {
+		return syncPolicy.SyncOptions
+	}
+	return nil
+}
+
 // CompareAppState compares application git state to the live app state, using the specified
 // revision and supplied source. If revision or overrides are empty, then compares against
 // revision and overrides in the app spec.
@@ -385,6 +497,13 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 	var targetObjs []*unstructured.Unstructured
 	now := metav1.Now()
 
+	// variables of the spec are resolved for the comparison only, the sync status refers to the spec as written
+	resolvedApp, resolvedSources, err := m.resolveSpecVariables(app, sources)
+	if err != nil {
+		conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
+	}
+	destinationNamespace := resolvedApp.Spec.Destination.Namespace
+
 	var manifestInfos []*apiclient.ManifestResponse
 
 	if len(localManifests) == 0 {
@@ -397,7 +516,7 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 			}
 		}
 
-		targetObjs, manifestInfos, err = m.getRepoObjs(app, sources, appLabelKey, revisions, noCache, noRevisionCache, verifySignature, project)
+		targetObjs, manifestInfos, err = m.getRepoObjs(resolvedApp, resolvedSources, appLabelKey, revisions, noCache, noRevisionCache, verifySignature, project)
 		if err != nil {
 			targetObjs = make([]*unstructured.Unstructured, 0)
 			conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
@@ -429,11 +548,15 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 	if err != nil {
 		infoProvider = &resourceInfoProviderStub{}
 	}
-	targetObjs, dedupConditions, err := DeduplicateTargetObjects(app.Spec.Destination.Namespace, targetObjs, infoProvider)
+	targetObjs, dedupConditions, err := DeduplicateTargetObjects(destinationNamespace, targetObjs, infoProvider)
 	if err != nil {
 		conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
 	}
 	conditions = append(conditions, dedupConditions...)
+	resFilter, err = m.getClusterResourcesFilter(resFilter, app.Spec.Destination.Server)
+	if err != nil {
+		conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
+	}
 	for i := len(targetObjs) - 1; i >= 0; i-- {
 		targetObj := targetObjs[i]
 		gvk := targetObj.GroupVersionKind()
@@ -490,7 +613,7 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 		}
 	}
 
-	reconciliation := sync.Reconcile(targetObjs, liveObjByKey, app.Spec.Destination.Namespace, infoProvider)
+	reconciliation := sync.Reconcile(targetObjs, liveObjByKey, destinationNamespace, infoProvider)
 	ts.AddCheckpoint("live_ms")
 
 	compareOptions, err := m.settingsMgr.GetResourceCompareOptions()
@@ -526,10 +649,15 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 		conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionUnknownError, Message: err.Error(), LastTransitionTime: &now})
 	}
 	diffConfigBuilder.WithGVKParser(gvkParser)
-	diffConfigBuilder.WithManager(common.ArgoCDSSAManager)
 
 	// enable structured merge diff if application syncs with server-side apply
-	if app.Spec.SyncPolicy != nil && app.Spec.SyncPolicy.SyncOptions.HasOption("ServerSideApply=true") {
+	serverSideApplySync, err := m.settingsMgr.IsFeatureEnabled(project, settings.FeatureFlagServerSideApplySync)
+	if err != nil {
+		conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionUnknownError, Message: err.Error(), LastTransitionTime: &now})
+	}
+	syncOptions := appSyncOptions(app, project)
+	diffConfigBuilder.WithManager(syncOptions.ServerSideApplyManager())
+	if syncOptions.SyncsWithServerSideApply(serverSideApplySync) {
 		diffConfigBuilder.WithStructuredMergeDiff(true)
 	}
 
@@ -548,6 +676,7 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 	syncCode := v1alpha1.SyncStatusCodeSynced
 	managedResources := make([]managedResource, len(reconciliation.Target))
 	resourceSummaries := make([]v1alpha1.ResourceStatus, len(reconciliation.Target))
+	notPermittedGroupKinds := map[string]bool{}
 	for i, targetObj := range reconciliation.Target {
 		liveObj := reconciliation.Live[i]
 		obj := liveObj
@@ -601,8 +730,15 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 		}
 		// set unknown status to all resource that are not permitted in the app project
 		isNamespaced, err := m.liveStateCache.IsNamespaced(app.Spec.Destination.Server, gvk.GroupKind())
-		if !project.IsGroupKindPermitted(gvk.GroupKind(), isNamespaced && err == nil) {
+		if permittedErr := project.ValidateGroupKind(gvk.GroupKind(), isNamespaced && err == nil); permittedErr != nil {
 			resState.Status = v1alpha1.SyncStatusCodeUnknown
+			// report every group/kind only once, since an application usually has several resources of the same kind.
+			// If the scope of the group/kind is unknown, e.g. because its CRD is not installed yet, the project cannot
+			// be validated reliably and no warning is reported.
+			if err == nil && !notPermittedGroupKinds[gvk.GroupKind().String()] {
+				notPermittedGroupKinds[gvk.GroupKind().String()] = true
+				conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionResourceNotPermittedWarning, Message: permittedErr.Error(), LastTransitionTime: &now})
+			}
 		}
 
 		if isNamespaced && obj.GetNamespace() == "" {
@@ -674,10 +810,19 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 	// Git has already performed the signature verification via its GPG interface, and the result is available
 	// in the manifest info received from the repository server. We now need to form our opinion about the result
 	// and stop processing if we do not agree about the outcome.
-	for _, manifestInfo := range manifestInfos {
-		if gpg.IsGPGEnabled() && verifySignature && manifestInfo != nil {
-			conditions = append(conditions, verifyGnuPGSignature(manifestInfo.Revision, project, manifestInfo)...)
+	if gpg.IsGPGEnabled() && verifySignature && len(manifestInfos) > 0 {
+		var signatureConditions []v1alpha1.ApplicationCondition
+		for _, manifestInfo := range manifestInfos {
+			if manifestInfo != nil {
+				signatureConditions = append(signatureConditions, verifyGnuPGSignature(manifestInfo.Revision, project, manifestInfo)...)
+			}
 		}
+		if len(signatureConditions) > 0 {
+			syncStatus.SignatureVerification = v1alpha1.SignatureVerificationFailed
+		} else {
+			syncStatus.SignatureVerification = v1alpha1.SignatureVerificationVerified
+		}
+		conditions = append(conditions, signatureConditions...)
 	}
 
 	compRes := comparisonResult{
@@ -688,6 +833,7 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 		reconciliationResult: reconciliation,
 		diffConfig:           diffConfig,
 		diffResultList:       diffResults,
+		destinationNamespace: destinationNamespace,
 	}
 
 	if hasMultipleSources {
@@ -702,17 +848,18 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *v1
 	}
 
 	app.Status.SetConditions(conditions, map[v1alpha1.ApplicationConditionType]bool{
-		v1alpha1.ApplicationConditionComparisonError:         true,
-		v1alpha1.ApplicationConditionSharedResourceWarning:   true,
-		v1alpha1.ApplicationConditionRepeatedResourceWarning: true,
-		v1alpha1.ApplicationConditionExcludedResourceWarning: true,
+		v1alpha1.ApplicationConditionComparisonError:             true,
+		v1alpha1.ApplicationConditionSharedResourceWarning:       true,
+		v1alpha1.ApplicationConditionRepeatedResourceWarning:     true,
+		v1alpha1.ApplicationConditionExcludedResourceWarning:     true,
+		v1alpha1.ApplicationConditionResourceNotPermittedWarning: true,
 	})
 	ts.AddCheckpoint("health_ms")
 	compRes.timings = ts.Timings()
 	return &compRes
 }
 
-func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revision string, source v1alpha1.ApplicationSource, revisions []string, sources []v1alpha1.ApplicationSource, hasMultipleSources bool, startedAt metav1.Time) error {
+func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revision string, source v1alpha1.ApplicationSource, revisions []string, sources []v1alpha1.ApplicationSource, hasMultipleSources bool, startedAt metav1.Time, manualRevision bool) error {
 	var nextID int64
 	if len(app.Status.History) > 0 {
 		nextID = app.Status.History.LastRevisionHistory().ID + 1
@@ -725,6 +872,8 @@ func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revi
 			ID:              nextID,
 			Sources:         sources,
 			Revisions:       revisions,
+			Charts:          m.getChartDetails(app, sources, revisions),
+			ManualRevision:  manualRevision,
 		})
 	} else {
 		app.Status.History = append(app.Status.History, v1alpha1.RevisionHistory{
@@ -733,6 +882,8 @@ func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revi
 			DeployStartedAt: &startedAt,
 			ID:              nextID,
 			Source:          source,
+			Charts:          m.getChartDetails(app, []v1alpha1.ApplicationSource{source}, []string{revision}),
+			ManualRevision:  manualRevision,
 		})
 	}
 
@@ -750,6 +901,55 @@ func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revi
 	return err
 }
 
+// chartDetailsTimeout is the maximum duration of getting the metadata of the charts recorded in the revision history
+const chartDetailsTimeout = 30 * time.Second
+
+// getChartDetails returns the metadata of the charts of the given sources which are Helm repositories. The metadata
+// is informational only, so errors are logged and the respective charts are skipped. The metadata of the charts which
+// can't be retrieved within the chart details timeout is skipped as well, so that the sync isn't delayed by it.
+func (m *appStateManager) getChartDetails(app *v1alpha1.Application, sources []v1alpha1.ApplicationSource, revisions []string) []v1alpha1.ChartDetails {
+	logCtx := log.WithField("application", app.QualifiedName())
+	ctx, cancel := context.WithTimeout(context.Background(), chartDetailsTimeout)
+	defer cancel()
+	var charts []v1alpha1.ChartDetails
+	var repoClient apiclient.RepoServerServiceClient
+	for i, source := range sources {
+		if source.Chart == "" || i >= len(revisions) || revisions[i] == "" {
+			continue
+		}
+		if repoClient == nil {
+			repoClientset, err := m.getRepoClientset(app.Spec.Destination)
+			if err != nil {
+				logCtx.Warnf("Failed to get repo server client to get chart details: %v", err)
+				return charts
+			}
+			conn, client, err := repoClientset.NewRepoServerClient()
+			if err != nil {
+				logCtx.Warnf("Failed to create repo server client to get chart details: %v", err)
+				return charts
+			}
+			defer io.Close(conn)
+			repoClient = client
+		}
+		repo, err := m.db.GetRepository(ctx, source.RepoURL)
+		if err != nil {
+			logCtx.Warnf("Failed to get repository %s to get details of chart %s: %v", source.RepoURL, source.Chart, err)
+			continue
+		}
+		details, err := repoClient.GetRevisionChartDetails(ctx, &apiclient.RepoServerRevisionChartDetailsRequest{
+			Repo:     repo,
+			Name:     source.Chart,
+			Revision: revisions[i],
+		})
+		if err != nil {
+			logCtx.Warnf("Failed to get details of chart %s %s: %v", source.Chart, revisions[i], err)
+			continue
+		}
+		charts = append(charts, *details)
+	}
+	return charts
+}
+
 // NewAppStateManager creates new instance of AppStateManager
 func NewAppStateManager(
 	db db.ArgoDB,
@@ -765,6 +965,7 @@ func NewAppStateManager(
 	statusRefreshTimeout time.Duration,
 	resourceTracking argo.ResourceTracking,
 	persistResourceHealth bool,
+	delegatedRepoServers []string,
 ) AppStateManager {
 	return &appStateManager{
 		liveStateCache:        liveStateCache,
@@ -773,6 +974,7 @@ func NewAppStateManager(
 		appclientset:          appclientset,
 		kubectl:               kubectl,
 		repoClientset:         repoClientset,
+		delegatedRepoServers:  delegatedRepoServers,
 		namespace:             namespace,
 		settingsMgr:           settingsMgr,
 		projInformer:          projInformer,
@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -11,6 +13,9 @@ import (
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	. "github.com/argoproj/gitops-engine/pkg/utils/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -19,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/argoproj/argo-cd/v2/common"
+	mockstatecache "github.com/argoproj/argo-cd/v2/controller/cache/mocks"
 	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
 	"github.com/argoproj/argo-cd/v2/test"
@@ -51,6 +57,27 @@ func TestCompareAppStateEmpty(t *testing.T) {
 	assert.Len(t, app.Status.Conditions, 0)
 }
 
+// TestCompareAppStateSpecVariables tests that the variables of the spec are resolved for the comparison only
+func TestCompareAppStateSpecVariables(t *testing.T) {
+	app := newFakeApp()
+	app.Spec.Destination.Namespace = "{{cluster.name}}-{{project}}"
+	data := fakeData{
+		manifestResponse: &apiclient.ManifestResponse{
+			Manifests: []string{},
+			Namespace: test.FakeDestNamespace,
+			Server:    test.FakeClusterURL,
+			Revision:  "abc123",
+		},
+		managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+	}
+	ctrl := newFakeController(&data)
+	sources := []argoappv1.ApplicationSource{app.Spec.GetSource()}
+	compRes := ctrl.appStateManager.CompareAppState(app, &defaultProj, []string{""}, sources, false, false, nil, false)
+	assert.Equal(t, "minikube-default", compRes.destinationNamespace)
+	assert.Equal(t, "{{cluster.name}}-{{project}}", compRes.syncStatus.ComparedTo.Destination.Namespace)
+	assert.Equal(t, "{{cluster.name}}-{{project}}", app.Spec.Destination.Namespace)
+}
+
 // TestCompareAppStateNamespaceMetadataDiffers tests comparison when managed namespace metadata differs
 func TestCompareAppStateNamespaceMetadataDiffers(t *testing.T) {
 	app := newFakeApp()
@@ -318,6 +345,55 @@ func TestCompareAppStateExtraHook(t *testing.T) {
 	assert.Equal(t, 0, len(app.Status.Conditions))
 }
 
+// TestCompareAppStateResourceNotPermitted checks that resources denied by the project are reported once per kind
+func TestCompareAppStateResourceNotPermitted(t *testing.T) {
+	pod1 := NewPod()
+	pod1.SetName("pod-1")
+	pod1.SetNamespace(test.FakeDestNamespace)
+	pod2 := NewPod()
+	pod2.SetName("pod-2")
+	pod2.SetNamespace(test.FakeDestNamespace)
+	data := fakeData{
+		manifestResponse: &apiclient.ManifestResponse{
+			Manifests: []string{toJSON(t, pod1), toJSON(t, pod2)},
+			Namespace: test.FakeDestNamespace,
+			Server:    test.FakeClusterURL,
+			Revision:  "abc123",
+		},
+		managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+	}
+	ctrl := newFakeController(&data)
+	proj := defaultProj.DeepCopy()
+	proj.Spec.NamespaceResourceBlacklist = []metav1.GroupKind{{Group: "", Kind: "Pod"}}
+
+	app := newFakeApp()
+	revisions := make([]string, 0)
+	revisions = append(revisions, "")
+	compRes := ctrl.appStateManager.CompareAppState(app, proj, revisions, app.Spec.GetSources(), false, false, nil, app.Spec.HasMultipleSources())
+	assert.NotNil(t, compRes)
+	assert.Len(t, compRes.resources, 2)
+	for _, res := range compRes.resources {
+		assert.Equal(t, argoappv1.SyncStatusCodeUnknown, res.Status)
+	}
+	require.Len(t, app.Status.Conditions, 1)
+	assert.Equal(t, []argoappv1.ApplicationCondition{{
+		Type:               argoappv1.ApplicationConditionResourceNotPermittedWarning,
+		Message:            "namespaced resource :Pod is not permitted in project 'default': it is in the namespace resource blacklist",
+		LastTransitionTime: app.Status.Conditions[0].LastTransitionTime,
+	}}, app.Status.Conditions)
+
+	t.Run("UnknownScope", func(t *testing.T) {
+		data.isNamespacedErr = errors.New("the server could not find the requested resource")
+		ctrl := newFakeController(&data)
+		app := newFakeApp()
+		compRes := ctrl.appStateManager.CompareAppState(app, proj, revisions, app.Spec.GetSources(), false, false, nil, app.Spec.HasMultipleSources())
+		assert.NotNil(t, compRes)
+		for _, cond := range app.Status.Conditions {
+			assert.NotEqual(t, argoappv1.ApplicationConditionResourceNotPermittedWarning, cond.Type)
+		}
+	})
+}
+
 // TestAppRevisions tests that revisions are properly propagated for a single source app
 func TestAppRevisionsSingleSource(t *testing.T) {
 	obj1 := NewPod()
@@ -635,7 +711,7 @@ func Test_appStateManager_persistRevisionHistory(t *testing.T) {
 		app.Spec.RevisionHistoryLimit = &i
 	}
 	addHistory := func() {
-		err := manager.persistRevisionHistory(app, "my-revision", argoappv1.ApplicationSource{}, []string{}, []argoappv1.ApplicationSource{}, false, metav1.Time{})
+		err := manager.persistRevisionHistory(app, "my-revision", argoappv1.ApplicationSource{}, []string{}, []argoappv1.ApplicationSource{}, false, metav1.Time{}, false)
 		assert.NoError(t, err)
 	}
 	addHistory()
@@ -671,9 +747,14 @@ func Test_appStateManager_persistRevisionHistory(t *testing.T) {
 	assert.Len(t, app.Status.History, 9)
 
 	metav1NowTime := metav1.NewTime(time.Now())
-	err := manager.persistRevisionHistory(app, "my-revision", argoappv1.ApplicationSource{}, []string{}, []argoappv1.ApplicationSource{}, false, metav1NowTime)
+	err := manager.persistRevisionHistory(app, "my-revision", argoappv1.ApplicationSource{}, []string{}, []argoappv1.ApplicationSource{}, false, metav1NowTime, false)
 	assert.NoError(t, err)
 	assert.Equal(t, app.Status.History.LastRevisionHistory().DeployStartedAt, &metav1NowTime)
+	assert.False(t, app.Status.History.LastRevisionHistory().ManualRevision)
+
+	err = manager.persistRevisionHistory(app, "my-revision", argoappv1.ApplicationSource{}, []string{}, []argoappv1.ApplicationSource{}, false, metav1NowTime, true)
+	assert.NoError(t, err)
+	assert.True(t, app.Status.History.LastRevisionHistory().ManualRevision)
 }
 
 // helper function to read contents of a file to string
@@ -795,6 +876,7 @@ func TestSignedResponseSignatureRequired(t *testing.T) {
 		assert.Len(t, compRes.resources, 0)
 		assert.Len(t, compRes.managedResources, 0)
 		assert.Len(t, app.Status.Conditions, 0)
+		assert.Equal(t, argoappv1.SignatureVerificationVerified, compRes.syncStatus.SignatureVerification)
 	}
 	// We have a bad signature response and signing is required - do not sync
 	{
@@ -821,6 +903,31 @@ func TestSignedResponseSignatureRequired(t *testing.T) {
 		assert.Len(t, compRes.resources, 0)
 		assert.Len(t, compRes.managedResources, 0)
 		assert.Len(t, app.Status.Conditions, 1)
+		assert.Equal(t, argoappv1.SignatureVerificationFailed, compRes.syncStatus.SignatureVerification)
+	}
+	// We have a good signature made with an expired key and signing is required - do not sync
+	{
+		app := newFakeApp()
+		data := fakeData{
+			manifestResponse: &apiclient.ManifestResponse{
+				Manifests:    []string{},
+				Namespace:    test.FakeDestNamespace,
+				Server:       test.FakeClusterURL,
+				Revision:     "abc123",
+				VerifyResult: mustReadFile("../util/gpg/testdata/good_signature_expired_key.txt"),
+			},
+			managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+		}
+		ctrl := newFakeController(&data)
+		sources := make([]argoappv1.ApplicationSource, 0)
+		sources = append(sources, app.Spec.GetSource())
+		revisions := make([]string, 0)
+		revisions = append(revisions, "abc123")
+		compRes := ctrl.appStateManager.CompareAppState(app, &signedProj, revisions, sources, false, false, nil, false)
+		assert.NotNil(t, compRes)
+		if assert.Len(t, app.Status.Conditions, 1) {
+			assert.Contains(t, app.Status.Conditions[0].Message, "The key the commit was signed with has expired.")
+		}
 	}
 	// We have a malformed signature response and signing is required - do not sync
 	{
@@ -1187,3 +1294,54 @@ func TestIsLiveResourceManaged(t *testing.T) {
 		assert.True(t, manager.isSelfReferencedObj(managedWrongAPIGroup, config, appName, common.AnnotationKeyAppInstance, argo.TrackingMethodAnnotation))
 	})
 }
+
+func TestGetRepoClientset(t *testing.T) {
+	ctrl := newFakeController(&fakeData{})
+	manager := ctrl.appStateManager.(*appStateManager)
+	stateCache := &mockstatecache.LiveStateCache{}
+	stateCache.On("GetCluster", "https://localhost:6443").Return(&argoappv1.Cluster{Server: "https://localhost:6443"}, nil)
+	stateCache.On("GetCluster", "https://edge-1").Return(&argoappv1.Cluster{Server: "https://edge-1", Name: "edge-1", RepoServer: "argocd-repo-server.edge-1:8081"}, nil)
+	stateCache.On("GetCluster", "https://unknown").Return(nil, status.Error(codes.NotFound, "cluster not found"))
+	manager.liveStateCache = stateCache
+
+	t.Run("Default", func(t *testing.T) {
+		repoClientset, err := manager.getRepoClientset(argoappv1.ApplicationDestination{Server: "https://localhost:6443"})
+		require.NoError(t, err)
+		assert.Same(t, manager.repoClientset, repoClientset)
+	})
+
+	t.Run("UnknownCluster", func(t *testing.T) {
+		repoClientset, err := manager.getRepoClientset(argoappv1.ApplicationDestination{Server: "https://unknown"})
+		require.NoError(t, err)
+		assert.Same(t, manager.repoClientset, repoClientset)
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		_, err := manager.getRepoClientset(argoappv1.ApplicationDestination{Server: "https://edge-1"})
+		assert.ErrorContains(t, err, "not in the list of delegated repo servers")
+	})
+
+	manager.delegatedRepoServers = []string{"argocd-repo-server.edge-*:8081"}
+
+	t.Run("NotSupported", func(t *testing.T) {
+		_, err := manager.getRepoClientset(argoappv1.ApplicationDestination{Server: "https://edge-1"})
+		assert.ErrorContains(t, err, "not supported by the repo server client")
+	})
+
+	manager.repoClientset = apiclient.NewRepoServerClientset("argocd-repo-server:8081", 60, apiclient.TLSConfiguration{})
+
+	t.Run("RemoteRepoServer", func(t *testing.T) {
+		repoClientset, err := manager.getRepoClientset(argoappv1.ApplicationDestination{Server: "https://edge-1"})
+		require.NoError(t, err)
+		assert.NotSame(t, manager.repoClientset, repoClientset)
+	})
+
+	t.Run("DestinationName", func(t *testing.T) {
+		_, err := manager.db.CreateCluster(context.Background(), &argoappv1.Cluster{Server: "https://edge-1", Name: "edge-1"})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			repoClientset, err := manager.getRepoClientset(argoappv1.ApplicationDestination{Name: "edge-1"})
+			return err == nil && repoClientset != manager.repoClientset
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -4,22 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"os"
 	"strconv"
-	"sync/atomic"
+	"strings"
 	"time"
 
-	cdcommon "github.com/argoproj/argo-cd/v2/common"
-
 	"github.com/argoproj/gitops-engine/pkg/sync"
 	"github.com/argoproj/gitops-engine/pkg/sync/common"
+	resourceutil "github.com/argoproj/gitops-engine/pkg/sync/resource"
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	jsonpatch "github.com/evanphx/json-patch"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/client-go/rest"
 	"k8s.io/kubectl/pkg/util/openapi"
 
 	"github.com/argoproj/argo-cd/v2/controller/metrics"
@@ -27,17 +31,36 @@ import (
 	listersv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/client/listers/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v2/util/argo"
 	"github.com/argoproj/argo-cd/v2/util/argo/diff"
+	"github.com/argoproj/argo-cd/v2/util/env"
 	logutils "github.com/argoproj/argo-cd/v2/util/log"
 	"github.com/argoproj/argo-cd/v2/util/lua"
-	"github.com/argoproj/argo-cd/v2/util/rand"
+	"github.com/argoproj/argo-cd/v2/util/settings"
 )
 
-var syncIdPrefix uint64 = 0
-
 const (
-	// EnvVarSyncWaveDelay is an environment variable which controls the delay in seconds between
+	// EnvVarSyncWaveDelay is an environment variable which controls the default delay in seconds between
 	// each sync-wave
 	EnvVarSyncWaveDelay = "ARGOCD_SYNC_WAVE_DELAY"
+	// EnvVarSyncWaveMaxDelay is an environment variable which controls the maximum delay in seconds between sync waves
+	// which applications can configure with the SyncWaveDelay sync option
+	EnvVarSyncWaveMaxDelay = "ARGOCD_SYNC_WAVE_MAX_DELAY"
+	// syncOptionSyncWaveDelay overrides the delay between sync waves of an application, e.g. SyncWaveDelay=10s
+	syncOptionSyncWaveDelay = "SyncWaveDelay"
+	// defaultSyncWaveDelay is the delay between sync waves if it is configured neither for the controller nor for the
+	// application
+	defaultSyncWaveDelay = 2 * time.Second
+	// defaultSyncWaveMaxDelay is the maximum delay between sync waves which applications can configure, unless the
+	// controller configures another one
+	defaultSyncWaveMaxDelay = time.Minute
+	// syncWaveTerminationCheckInterval is the interval in which the delay between sync waves checks whether the
+	// operation was terminated
+	syncWaveTerminationCheckInterval = time.Second
+	// syncOptionRespectIgnoreDifferences makes the sync apply the live values of ignored fields. It can be set for
+	// the whole application or for a single resource using the argocd.argoproj.io/sync-options annotation
+	syncOptionRespectIgnoreDifferences = "RespectIgnoreDifferences=true"
+	// syncOptionSelfHealTimeout overrides the time to wait between self-heal attempts of an application, e.g.
+	// SelfHealTimeout=30s
+	syncOptionSelfHealTimeout = "SelfHealTimeout"
 )
 
 func (m *appStateManager) getOpenAPISchema(server string) (openapi.Resources, error) {
@@ -169,6 +192,14 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 		return
 	}
 
+	if state.Phase != common.OperationTerminating {
+		if err := proj.ValidateResourcesQuota(countTargetResources(compareResult.reconciliationResult.Target)); err != nil {
+			state.Phase = common.OperationFailed
+			state.Message = err.Error()
+			return
+		}
+	}
+
 	clst, err := m.db.GetCluster(context.Background(), app.Spec.Destination.Server)
 	if err != nil {
 		state.Phase = common.OperationError
@@ -176,26 +207,44 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 		return
 	}
 
-	rawConfig := clst.RawRestConfig()
-	restConfig := metrics.AddMetricsTransportWrapper(m.metricsServer, app, clst.RESTConfig())
+	rawConfig, err := clst.RawRestConfig()
+	if err != nil {
+		state.Phase = common.OperationError
+		state.Message = err.Error()
+		return
+	}
+	clusterRESTConfig, err := clst.RESTConfig()
+	if err != nil {
+		state.Phase = common.OperationError
+		state.Message = err.Error()
+		return
+	}
+	restConfig := metrics.AddMetricsTransportWrapper(m.metricsServer, app, clusterRESTConfig)
 
-	resourceOverrides, err := m.settingsMgr.GetResourceOverrides()
+	impersonatedUser, err := argo.ServiceAccountToImpersonate(proj, app.Spec.Destination.Server, compareResult.destinationNamespace)
 	if err != nil {
 		state.Phase = common.OperationError
-		state.Message = fmt.Sprintf("Failed to load resource overrides: %v", err)
+		state.Message = err.Error()
 		return
 	}
+	if impersonatedUser != "" {
+		rawConfig.Impersonate = rest.ImpersonationConfig{UserName: impersonatedUser}
+		restConfig.Impersonate = rest.ImpersonationConfig{UserName: impersonatedUser}
+	}
 
-	atomic.AddUint64(&syncIdPrefix, 1)
-	randSuffix, err := rand.String(5)
+	resourceOverrides, err := m.settingsMgr.GetResourceOverrides()
 	if err != nil {
 		state.Phase = common.OperationError
-		state.Message = fmt.Sprintf("Failed generate random sync ID: %v", err)
+		state.Message = fmt.Sprintf("Failed to load resource overrides: %v", err)
 		return
 	}
-	syncId := fmt.Sprintf("%05d-%s", syncIdPrefix, randSuffix)
+
+	syncId := operationSyncId(app, state)
 
 	logEntry := log.WithFields(log.Fields{"application": app.QualifiedName(), "syncId": syncId})
+	if len(syncRes.Resources) > 0 {
+		logEntry.Infof("Resuming sync from %d resource results of previous waves and hooks", len(syncRes.Resources))
+	}
 	initialResourcesRes := make([]common.ResourceSyncResult, 0)
 	for i, res := range syncRes.Resources {
 		key := kube.ResourceKey{Group: res.Group, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name}
@@ -221,6 +270,13 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 		prunePropagationPolicy = v1.DeletePropagationOrphan
 	}
 
+	serverSideApplySync, err := m.settingsMgr.IsFeatureEnabled(proj, settings.FeatureFlagServerSideApplySync)
+	if err != nil {
+		state.Phase = common.OperationError
+		state.Message = fmt.Sprintf("Failed to load feature flags: %v", err)
+		return
+	}
+
 	openAPISchema, err := m.getOpenAPISchema(clst.Server)
 	if err != nil {
 		state.Phase = common.OperationError
@@ -232,9 +288,11 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 
 	// if RespectIgnoreDifferences is enabled, it should normalize the target
 	// resources which in this case applies the live values in the configured
-	// ignore differences fields.
-	if syncOp.SyncOptions.HasOption("RespectIgnoreDifferences=true") {
-		patchedTargets, err := normalizeTargetResources(compareResult)
+	// ignore differences fields. The option is honored for the whole application
+	// or only for the resources annotated with it.
+	respectIgnoreDifferences := syncOp.SyncOptions.HasOption(syncOptionRespectIgnoreDifferences)
+	if respectIgnoreDifferences || anyRespectsIgnoreDifferences(reconciliationResult.Target) {
+		patchedTargets, err := normalizeTargetResources(compareResult, respectIgnoreDifferences)
 		if err != nil {
 			state.Phase = common.OperationError
 			state.Message = fmt.Sprintf("Failed to normalize target resources: %s", err)
@@ -250,12 +308,71 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 	}
 	trackingMethod := argo.GetTrackingMethod(m.settingsMgr)
 
+	selector := labels.Everything()
+	if syncOp.LabelSelector != "" {
+		selector, err = labels.Parse(syncOp.LabelSelector)
+		if err != nil {
+			state.Phase = common.OperationError
+			state.Message = fmt.Sprintf("invalid label selector: %v", err)
+			return
+		}
+	}
+
+	resourcesFilter := func(key kube.ResourceKey, target *unstructured.Unstructured, live *unstructured.Unstructured) bool {
+		return (len(syncOp.Resources) == 0 ||
+			argo.ContainsSyncResource(key.Name, key.Namespace, schema.GroupVersionKind{Kind: key.Kind, Group: key.Group}, syncOp.Resources)) &&
+			matchesLabelSelector(selector, target, live) &&
+			m.isSelfReferencedObj(live, target, app.GetName(), appLabelKey, trackingMethod)
+	}
+
+	// applications managing Argo CD itself must not prune its components by accident, and must update the application
+	// controller last, since the controller restarts when it is updated
+	syncControllerLast := false
+	if clst.Server == v1alpha1.KubernetesInternalAPIServerAddr && !syncOp.DryRun && state.Phase != common.OperationTerminating {
+		if syncOp.Prune && !syncOp.SyncOptions.HasOption(syncOptionPruneArgoCDComponents) {
+			if keys := argoCDComponentsToPrune(reconciliationResult, m.namespace, resourcesFilter); len(keys) > 0 {
+				state.Phase = common.OperationFailed
+				state.Message = fmt.Sprintf("refusing to prune Argo CD components %v without the %s sync option", keys, syncOptionPruneArgoCDComponents)
+				return
+			}
+		}
+		syncControllerLast = state.Message != syncControllerLastMessage &&
+			hasModifiedApplicationController(reconciliationResult, compareResult.diffResultList, m.namespace)
+	}
+
+	if strict, schemasVersion := manifestValidationSettings(syncOp.SyncOptions, proj); strict && state.Phase != common.OperationTerminating {
+		schemas, schemasSource := openAPISchema, "the destination cluster"
+		if schemasVersion != "" {
+			schemas, err = bundledOpenAPISchemas.load(schemasVersion)
+			if err != nil {
+				state.Phase = common.OperationError
+				state.Message = fmt.Sprintf("failed to load OpenAPI schemas: %v", err)
+				return
+			}
+			schemasSource = "Kubernetes " + schemasVersion
+		}
+		var targets []*unstructured.Unstructured
+		for i, target := range reconciliationResult.Target {
+			if target != nil && resourcesFilter(kube.GetResourceKey(target), target, reconciliationResult.Live[i]) {
+				targets = append(targets, target)
+			}
+		}
+		if violations := validateManifests(targets, schemas); len(violations) > 0 {
+			state.Phase = common.OperationFailed
+			state.Message = fmt.Sprintf("%d manifest validation errors against the OpenAPI schemas of %s: %s", len(violations), schemasSource, strings.Join(violations, "; "))
+			return
+		}
+	}
+
+	// the sync context is referenced by the sync wave hook, which is only called once the sync context is created
+	var syncCtx sync.SyncContext
+	var cleanup func()
 	opts := []sync.SyncOpt{
 		sync.WithLogr(logutils.NewLogrusLogger(logEntry)),
 		sync.WithHealthOverride(lua.ResourceHealthOverrides(resourceOverrides)),
 		sync.WithPermissionValidator(func(un *unstructured.Unstructured, res *v1.APIResource) error {
-			if !proj.IsGroupKindPermitted(un.GroupVersionKind().GroupKind(), res.Namespaced) {
-				return fmt.Errorf("resource %s:%s is not permitted in project %s", un.GroupVersionKind().Group, un.GroupVersionKind().Kind, proj.Name)
+			if err := proj.ValidateGroupKind(un.GroupVersionKind().GroupKind(), res.Namespaced); err != nil {
+				return err
 			}
 			if res.Namespaced {
 				permitted, err := proj.IsDestinationPermitted(v1alpha1.ApplicationDestination{Namespace: un.GetNamespace(), Server: app.Spec.Destination.Server, Name: app.Spec.Destination.Name}, func(project string) ([]*v1alpha1.Cluster, error) {
@@ -272,34 +389,41 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 			}
 			return nil
 		}),
-		sync.WithOperationSettings(syncOp.DryRun, syncOp.Prune, syncOp.SyncStrategy.Force(), syncOp.IsApplyStrategy() || len(syncOp.Resources) > 0),
+		sync.WithOperationSettings(syncOp.DryRun, syncOp.Prune, syncOp.SyncStrategy.Force(), syncOp.IsApplyStrategy() || syncOp.IsSelective()),
 		sync.WithInitialState(state.Phase, state.Message, initialResourcesRes, state.StartedAt),
 		sync.WithResourcesFilter(func(key kube.ResourceKey, target *unstructured.Unstructured, live *unstructured.Unstructured) bool {
-			return (len(syncOp.Resources) == 0 ||
-				argo.ContainsSyncResource(key.Name, key.Namespace, schema.GroupVersionKind{Kind: key.Kind, Group: key.Group}, syncOp.Resources)) &&
-				m.isSelfReferencedObj(live, target, app.GetName(), appLabelKey, trackingMethod)
+			if syncControllerLast && isApplicationControllerWorkload(target, m.namespace) {
+				return false
+			}
+			return resourcesFilter(key, target, live)
 		}),
 		sync.WithManifestValidation(!syncOp.SyncOptions.HasOption(common.SyncOptionsDisableValidation)),
-		sync.WithSyncWaveHook(delayBetweenSyncWaves),
+		sync.WithSyncWaveHook(checkpointSyncWaves(func() {
+			m.persistSyncProgress(app, state, syncCtx, logEntry)
+		}, delayBetweenSyncWaves(syncWaveDelay(syncOp.SyncOptions, logEntry), func() bool {
+			return m.isOperationTerminating(app)
+		}))),
 		sync.WithPruneLast(syncOp.SyncOptions.HasOption(common.SyncOptionPruneLast)),
 		sync.WithResourceModificationChecker(syncOp.SyncOptions.HasOption("ApplyOutOfSyncOnly=true"), compareResult.diffResultList),
 		sync.WithPrunePropagationPolicy(&prunePropagationPolicy),
 		sync.WithReplace(syncOp.SyncOptions.HasOption(common.SyncOptionReplace)),
-		sync.WithServerSideApply(syncOp.SyncOptions.HasOption(common.SyncOptionServerSideApply)),
-		sync.WithServerSideApplyManager(cdcommon.ArgoCDSSAManager),
+		sync.WithServerSideApply(syncOp.SyncOptions.SyncsWithServerSideApply(serverSideApplySync)),
+		// the manager is taken from the sync policy of the application, like when diffing, so that the fields applied by
+		// the sync are the ones considered Argo CD managed by the diff
+		sync.WithServerSideApplyManager(appSyncOptions(app, proj).ServerSideApplyManager()),
 	}
 
 	if syncOp.SyncOptions.HasOption("CreateNamespace=true") {
-		opts = append(opts, sync.WithNamespaceModifier(syncNamespace(m.resourceTracking, appLabelKey, trackingMethod, app.Name, app.Spec.SyncPolicy)))
+		opts = append(opts, sync.WithNamespaceModifier(syncNamespace(m.resourceTracking, appLabelKey, trackingMethod, app.Name, proj.GetSyncPolicy(app))))
 	}
 
-	syncCtx, cleanup, err := sync.NewSyncContext(
+	syncCtx, cleanup, err = sync.NewSyncContext(
 		compareResult.syncStatus.Revision,
 		reconciliationResult,
 		restConfig,
 		rawConfig,
 		m.kubectl,
-		app.Spec.Destination.Namespace,
+		compareResult.destinationNamespace,
 		openAPISchema,
 		opts...,
 	)
@@ -322,9 +446,14 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 	var resState []common.ResourceSyncResult
 	state.Phase, state.Message, resState = syncCtx.GetState()
 	state.SyncResult.Resources = nil
+	if syncControllerLast && state.Phase == common.OperationSucceeded {
+		// the operation continues with the application controller
+		state.Phase = common.OperationRunning
+		state.Message = syncControllerLastMessage
+	}
 
-	if app.Spec.SyncPolicy != nil {
-		state.SyncResult.ManagedNamespaceMetadata = app.Spec.SyncPolicy.ManagedNamespaceMetadata
+	if syncPolicy := proj.GetSyncPolicy(app); syncPolicy != nil {
+		state.SyncResult.ManagedNamespaceMetadata = syncPolicy.ManagedNamespaceMetadata
 	}
 
 	var apiVersion []kube.APIResourceInfo
@@ -345,24 +474,13 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 			res.Message = augmentedMsg
 		}
 
-		state.SyncResult.Resources = append(state.SyncResult.Resources, &v1alpha1.ResourceResult{
-			HookType:  res.HookType,
-			Group:     res.ResourceKey.Group,
-			Kind:      res.ResourceKey.Kind,
-			Namespace: res.ResourceKey.Namespace,
-			Name:      res.ResourceKey.Name,
-			Version:   res.Version,
-			SyncPhase: res.SyncPhase,
-			HookPhase: res.HookPhase,
-			Status:    res.Status,
-			Message:   res.Message,
-		})
+		state.SyncResult.Resources = append(state.SyncResult.Resources, newResourceResult(res))
 	}
 
 	logEntry.WithField("duration", time.Since(start)).Info("sync/terminate complete")
 
-	if !syncOp.DryRun && len(syncOp.Resources) == 0 && state.Phase.Successful() {
-		err := m.persistRevisionHistory(app, compareResult.syncStatus.Revision, source, compareResult.syncStatus.Revisions, compareResult.syncStatus.ComparedTo.Sources, app.Spec.HasMultipleSources(), state.StartedAt)
+	if !syncOp.DryRun && !syncOp.IsSelective() && state.Phase.Successful() {
+		err := m.persistRevisionHistory(app, compareResult.syncStatus.Revision, source, compareResult.syncStatus.Revisions, compareResult.syncStatus.ComparedTo.Sources, app.Spec.HasMultipleSources(), state.StartedAt, syncOp.ManualRevision)
 		if err != nil {
 			state.Phase = common.OperationError
 			state.Message = fmt.Sprintf("failed to record sync to history: %v", err)
@@ -370,12 +488,105 @@ func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha
 	}
 }
 
+// newResourceResult converts the result of a resource of a sync into the result recorded in the operation state
+func newResourceResult(res common.ResourceSyncResult) *v1alpha1.ResourceResult {
+	return &v1alpha1.ResourceResult{
+		HookType:  res.HookType,
+		Group:     res.ResourceKey.Group,
+		Kind:      res.ResourceKey.Kind,
+		Namespace: res.ResourceKey.Namespace,
+		Name:      res.ResourceKey.Name,
+		Version:   res.Version,
+		SyncPhase: res.SyncPhase,
+		HookPhase: res.HookPhase,
+		Status:    res.Status,
+		Message:   res.Message,
+	}
+}
+
+// checkpointSyncWaves returns a sync wave hook which checkpoints the progress of the sync once the resources and hooks
+// of a wave were applied, before calling the given sync wave hook
+func checkpointSyncWaves(checkpoint func(), next common.SyncWaveHook) common.SyncWaveHook {
+	return func(phase common.SyncPhase, wave int, finalWave bool) error {
+		checkpoint()
+		return next(phase, wave, finalWave)
+	}
+}
+
+// persistSyncProgress persists the results of the resources and hooks which were applied by the sync so far in the
+// operation state of the application. The results are the checkpoint from which a sync is resumed after a restart of
+// the controller: resources and hooks with results are not applied again, so waves which were applied are skipped,
+// and running hooks are waited for instead of being created again. Only the sync result is patched, so that the
+// phase of the operation, e.g. when it is terminated meanwhile, is kept.
+func (m *appStateManager) persistSyncProgress(app *v1alpha1.Application, state *v1alpha1.OperationState, syncCtx sync.SyncContext, logEntry *log.Entry) {
+	if state.SyncResult == nil {
+		return
+	}
+	_, _, resState := syncCtx.GetState()
+	syncRes := state.SyncResult.DeepCopy()
+	syncRes.Resources = nil
+	for _, res := range resState {
+		syncRes.Resources = append(syncRes.Resources, newResourceResult(res))
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"operationState": map[string]interface{}{
+				"syncResult": syncRes,
+			},
+		},
+	})
+	if err == nil {
+		_, err = m.appclientset.ArgoprojV1alpha1().Applications(app.Namespace).Patch(context.Background(), app.Name, types.MergePatchType, patch, v1.PatchOptions{})
+	}
+	if err != nil {
+		// the sync continues, if the controller restarts before the progress is persisted at the end of the sync
+		// step, the resources of the wave are applied again
+		logEntry.Warnf("Failed to persist sync progress: %v", err)
+	}
+}
+
+// operationSyncId returns the ID of the sync operation, which is used to correlate the log entries of an operation. It
+// is derived from the application and the start time of the operation, which is persisted in the application status,
+// so that an operation which is resumed, e.g. after a restart of the controller, keeps its ID.
+func operationSyncId(app *v1alpha1.Application, state *v1alpha1.OperationState) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(app.QualifiedName()))
+	_, _ = h.Write([]byte(app.UID))
+	return fmt.Sprintf("%d-%08x", state.StartedAt.Unix(), h.Sum32())
+}
+
+// matchesLabelSelector returns true if the labels of the target resource match the selector. The labels of the live
+// resource are used for resources which are pruned.
+func matchesLabelSelector(selector labels.Selector, target *unstructured.Unstructured, live *unstructured.Unstructured) bool {
+	if selector.Empty() {
+		return true
+	}
+	obj := target
+	if obj == nil {
+		obj = live
+	}
+	return obj != nil && selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// anyRespectsIgnoreDifferences returns true if any of the given target resources enables the
+// RespectIgnoreDifferences sync option using the sync-options annotation
+func anyRespectsIgnoreDifferences(targets []*unstructured.Unstructured) bool {
+	for _, target := range targets {
+		if target != nil && resourceutil.HasAnnotationOption(target, common.AnnotationSyncOptions, syncOptionRespectIgnoreDifferences) {
+			return true
+		}
+	}
+	return false
+}
+
 // normalizeTargetResources will apply the diff normalization in all live and target resources.
 // Then it calculates the merge patch between the normalized live and the current live resources.
 // Finally it applies the merge patch in the normalized target resources. This is done to ensure
 // that target resources have the same ignored diff fields values from live ones to avoid them to
-// be applied in the cluster. Returns the list of normalized target resources.
-func normalizeTargetResources(cr *comparisonResult) ([]*unstructured.Unstructured, error) {
+// be applied in the cluster. If all is false, only the target resources which enable
+// RespectIgnoreDifferences using the sync-options annotation are patched. Returns the list of
+// normalized target resources.
+func normalizeTargetResources(cr *comparisonResult, all bool) ([]*unstructured.Unstructured, error) {
 	// normalize live and target resources
 	normalized, err := diff.Normalize(cr.reconciliationResult.Live, cr.reconciliationResult.Target, cr.diffConfig)
 	if err != nil {
@@ -389,7 +600,7 @@ func normalizeTargetResources(cr *comparisonResult) ([]*unstructured.Unstructure
 			continue
 		}
 		originalTarget := cr.reconciliationResult.Target[idx]
-		if live == nil {
+		if live == nil || (!all && !resourceutil.HasAnnotationOption(originalTarget, common.AnnotationSyncOptions, syncOptionRespectIgnoreDifferences)) {
 			patchedTargets = append(patchedTargets, originalTarget)
 			continue
 		}
@@ -523,7 +734,7 @@ func hasSharedResourceCondition(app *v1alpha1.Application) (bool, string) {
 	return false, ""
 }
 
-// delayBetweenSyncWaves is a gitops-engine SyncWaveHook which introduces an artificial delay
+// delayBetweenSyncWaves returns a gitops-engine SyncWaveHook which introduces an artificial delay
 // between each sync wave. We introduce an artificial delay in order give other controllers a
 // _chance_ to react to the spec change that we just applied. This is important because without
 // this, Argo CD will likely assess resource health too quickly (against the stale object), causing
@@ -531,16 +742,72 @@ func hasSharedResourceCondition(app *v1alpha1.Application) (bool, string) {
 // Note, this is not foolproof, since a proper fix would require the CRD record
 // status.observedGeneration coupled with a health.lua that verifies
 // status.observedGeneration == metadata.generation
-func delayBetweenSyncWaves(phase common.SyncPhase, wave int, finalWave bool) error {
-	if !finalWave {
-		delaySec := 2
-		if delaySecStr := os.Getenv(EnvVarSyncWaveDelay); delaySecStr != "" {
-			if val, err := strconv.Atoi(delaySecStr); err == nil {
-				delaySec = val
+// The delay ends early if the operation is terminated in the meantime, so that the termination doesn't have to wait for
+// it.
+func delayBetweenSyncWaves(delay time.Duration, terminating func() bool) common.SyncWaveHook {
+	return func(phase common.SyncPhase, wave int, finalWave bool) error {
+		if finalWave || delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		ticker := time.NewTicker(syncWaveTerminationCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-timer.C:
+				return nil
+			case <-ticker.C:
+				if terminating() {
+					return nil
+				}
 			}
 		}
-		duration := time.Duration(delaySec) * time.Second
-		time.Sleep(duration)
 	}
-	return nil
+}
+
+// isOperationTerminating returns whether the operation of the given application was requested to terminate
+func (m *appStateManager) isOperationTerminating(app *v1alpha1.Application) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), syncWaveTerminationCheckInterval)
+	defer cancel()
+	latest, err := m.appclientset.ArgoprojV1alpha1().Applications(app.Namespace).Get(ctx, app.Name, v1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return latest.Status.OperationState != nil && latest.Status.OperationState.Phase == common.OperationTerminating
+}
+
+// syncWaveDelay returns the delay between sync waves, which is set by the SyncWaveDelay sync option of the application,
+// or else by the ARGOCD_SYNC_WAVE_DELAY environment variable of the controller. The delay of the application is limited
+// to the ARGOCD_SYNC_WAVE_MAX_DELAY environment variable of the controller.
+func syncWaveDelay(options v1alpha1.SyncOptions, logEntry *log.Entry) time.Duration {
+	if value, ok := syncOptionValue(options, syncOptionSyncWaveDelay); ok {
+		delay, err := time.ParseDuration(value)
+		if err == nil && delay >= 0 {
+			maxDelay := time.Duration(env.ParseNumFromEnv(EnvVarSyncWaveMaxDelay, int(defaultSyncWaveMaxDelay.Seconds()), 0, math.MaxInt32)) * time.Second
+			if delay > maxDelay {
+				logEntry.Warnf("Limiting sync option %s=%s to the maximum delay of %s", syncOptionSyncWaveDelay, value, maxDelay)
+				return maxDelay
+			}
+			return delay
+		}
+		logEntry.Warnf("Ignoring invalid sync option %s=%s", syncOptionSyncWaveDelay, value)
+	}
+	if delaySecStr := os.Getenv(EnvVarSyncWaveDelay); delaySecStr != "" {
+		if val, err := strconv.Atoi(delaySecStr); err == nil {
+			return time.Duration(val) * time.Second
+		}
+	}
+	return defaultSyncWaveDelay
+}
+
+// countTargetResources returns the number of resources which are managed by an application, given its target resources
+func countTargetResources(targets []*unstructured.Unstructured) int {
+	count := 0
+	for _, target := range targets {
+		if target != nil {
+			count++
+		}
+	}
+	return count
 }
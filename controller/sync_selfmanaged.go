@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"github.com/argoproj/gitops-engine/pkg/diff"
+	"github.com/argoproj/gitops-engine/pkg/sync"
+	"github.com/argoproj/gitops-engine/pkg/sync/common"
+	resourceutil "github.com/argoproj/gitops-engine/pkg/sync/resource"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// syncOptionPruneArgoCDComponents confirms that applications managing Argo CD itself may prune Argo CD components
+	syncOptionPruneArgoCDComponents = "PruneArgoCDComponents=true"
+	// syncControllerLastMessage is the message of the sync operations of applications managing Argo CD itself, which
+	// synced all resources but the application controller. The application controller is synced last, because it
+	// restarts when it is updated.
+	syncControllerLastMessage = "successfully synced all resources except the application controller, which is synced last"
+
+	labelKeyPartOf                  = "app.kubernetes.io/part-of"
+	labelKeyComponent               = "app.kubernetes.io/component"
+	labelValueArgoCD                = "argocd"
+	labelValueApplicationController = "application-controller"
+)
+
+// isArgoCDComponent returns whether the given object is a component of the Argo CD installation in the given namespace,
+// or the namespace itself
+func isArgoCDComponent(obj *unstructured.Unstructured, namespace string) bool {
+	if obj == nil {
+		return false
+	}
+	if obj.GetKind() == kube.NamespaceKind && obj.GroupVersionKind().Group == "" {
+		return obj.GetName() == namespace
+	}
+	return obj.GetNamespace() == namespace && obj.GetLabels()[labelKeyPartOf] == labelValueArgoCD
+}
+
+// isApplicationControllerWorkload returns whether the given object is the workload of the application controller of
+// the Argo CD installation in the given namespace
+func isApplicationControllerWorkload(obj *unstructured.Unstructured, namespace string) bool {
+	if !isArgoCDComponent(obj, namespace) || obj.GetLabels()[labelKeyComponent] != labelValueApplicationController {
+		return false
+	}
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "apps" && (gvk.Kind == kube.StatefulSetKind || gvk.Kind == kube.DeploymentKind)
+}
+
+// argoCDComponentsToPrune returns the keys of the components of the Argo CD installation in the given namespace which
+// a sync of the given reconciliation result would prune
+func argoCDComponentsToPrune(reconciliationResult sync.ReconciliationResult, namespace string, filter func(key kube.ResourceKey, target *unstructured.Unstructured, live *unstructured.Unstructured) bool) []kube.ResourceKey {
+	var keys []kube.ResourceKey
+	for i, live := range reconciliationResult.Live {
+		target := reconciliationResult.Target[i]
+		if target != nil || !isArgoCDComponent(live, namespace) {
+			continue
+		}
+		if resourceutil.HasAnnotationOption(live, common.AnnotationSyncOptions, common.SyncOptionDisablePrune) {
+			continue
+		}
+		key := kube.GetResourceKey(live)
+		if filter(key, target, live) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// hasModifiedApplicationController returns whether a sync of the given reconciliation result would update the
+// application controller of the Argo CD installation in the given namespace
+func hasModifiedApplicationController(reconciliationResult sync.ReconciliationResult, diffResultList *diff.DiffResultList, namespace string) bool {
+	if diffResultList == nil {
+		return false
+	}
+	for i, target := range reconciliationResult.Target {
+		if !isApplicationControllerWorkload(target, namespace) {
+			continue
+		}
+		if reconciliationResult.Live[i] == nil || (i < len(diffResultList.Diffs) && diffResultList.Diffs[i].Modified) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/diff"
+	"github.com/argoproj/gitops-engine/pkg/sync"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	. "github.com/argoproj/gitops-engine/pkg/utils/testing"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/argoproj/argo-cd/v2/test"
+)
+
+func newArgoCDComponent(apiVersion, kind, name, component string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(test.FakeArgoCDNamespace)
+	obj.SetLabels(map[string]string{labelKeyPartOf: labelValueArgoCD, labelKeyComponent: component})
+	return obj
+}
+
+func TestIsArgoCDComponent(t *testing.T) {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind(kube.NamespaceKind)
+	ns.SetName(test.FakeArgoCDNamespace)
+	assert.True(t, isArgoCDComponent(ns, test.FakeArgoCDNamespace))
+	assert.False(t, isArgoCDComponent(ns, "other"))
+
+	server := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-server", "server")
+	assert.True(t, isArgoCDComponent(server, test.FakeArgoCDNamespace))
+	assert.False(t, isArgoCDComponent(server, "other"))
+
+	server.SetLabels(nil)
+	assert.False(t, isArgoCDComponent(server, test.FakeArgoCDNamespace))
+	assert.False(t, isArgoCDComponent(nil, test.FakeArgoCDNamespace))
+}
+
+func TestIsApplicationControllerWorkload(t *testing.T) {
+	controller := newArgoCDComponent("apps/v1", kube.StatefulSetKind, "argocd-application-controller", labelValueApplicationController)
+	assert.True(t, isApplicationControllerWorkload(controller, test.FakeArgoCDNamespace))
+	assert.False(t, isApplicationControllerWorkload(controller, "other"))
+
+	deployment := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-application-controller", labelValueApplicationController)
+	assert.True(t, isApplicationControllerWorkload(deployment, test.FakeArgoCDNamespace))
+
+	service := newArgoCDComponent("v1", kube.ServiceKind, "argocd-application-controller", labelValueApplicationController)
+	assert.False(t, isApplicationControllerWorkload(service, test.FakeArgoCDNamespace))
+
+	server := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-server", "server")
+	assert.False(t, isApplicationControllerWorkload(server, test.FakeArgoCDNamespace))
+}
+
+func TestArgoCDComponentsToPrune(t *testing.T) {
+	server := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-server", "server")
+	repoServer := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-repo-server", "repo-server")
+	dex := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-dex-server", "dex-server")
+	dex.SetAnnotations(map[string]string{"argocd.argoproj.io/sync-options": "Prune=false"})
+	pod := NewPod()
+
+	result := sync.ReconciliationResult{
+		Live:   []*unstructured.Unstructured{server, repoServer, dex, pod},
+		Target: []*unstructured.Unstructured{server, nil, nil, nil},
+	}
+	all := func(_ kube.ResourceKey, _ *unstructured.Unstructured, _ *unstructured.Unstructured) bool {
+		return true
+	}
+	none := func(_ kube.ResourceKey, _ *unstructured.Unstructured, _ *unstructured.Unstructured) bool {
+		return false
+	}
+
+	assert.Equal(t, []kube.ResourceKey{kube.GetResourceKey(repoServer)}, argoCDComponentsToPrune(result, test.FakeArgoCDNamespace, all))
+	assert.Empty(t, argoCDComponentsToPrune(result, test.FakeArgoCDNamespace, none))
+	assert.Empty(t, argoCDComponentsToPrune(result, "other", all))
+}
+
+func TestHasModifiedApplicationController(t *testing.T) {
+	controller := newArgoCDComponent("apps/v1", kube.StatefulSetKind, "argocd-application-controller", labelValueApplicationController)
+	server := newArgoCDComponent("apps/v1", kube.DeploymentKind, "argocd-server", "server")
+
+	t.Run("Modified", func(t *testing.T) {
+		result := sync.ReconciliationResult{
+			Live:   []*unstructured.Unstructured{server, controller},
+			Target: []*unstructured.Unstructured{server, controller},
+		}
+		diffs := &diff.DiffResultList{Diffs: []diff.DiffResult{{Modified: false}, {Modified: true}}, Modified: true}
+		assert.True(t, hasModifiedApplicationController(result, diffs, test.FakeArgoCDNamespace))
+		assert.False(t, hasModifiedApplicationController(result, diffs, "other"))
+	})
+	t.Run("Unmodified", func(t *testing.T) {
+		result := sync.ReconciliationResult{
+			Live:   []*unstructured.Unstructured{server, controller},
+			Target: []*unstructured.Unstructured{server, controller},
+		}
+		diffs := &diff.DiffResultList{Diffs: []diff.DiffResult{{Modified: true}, {Modified: false}}, Modified: true}
+		assert.False(t, hasModifiedApplicationController(result, diffs, test.FakeArgoCDNamespace))
+	})
+	t.Run("Created", func(t *testing.T) {
+		result := sync.ReconciliationResult{
+			Live:   []*unstructured.Unstructured{nil},
+			Target: []*unstructured.Unstructured{controller},
+		}
+		diffs := &diff.DiffResultList{Diffs: []diff.DiffResult{{Modified: true}}, Modified: true}
+		assert.True(t, hasModifiedApplicationController(result, diffs, test.FakeArgoCDNamespace))
+	})
+	t.Run("NoDiff", func(t *testing.T) {
+		result := sync.ReconciliationResult{
+			Live:   []*unstructured.Unstructured{controller},
+			Target: []*unstructured.Unstructured{controller},
+		}
+		assert.False(t, hasModifiedApplicationController(result, nil, test.FakeArgoCDNamespace))
+	})
+}
@@ -3,15 +3,20 @@ package controller
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/sync"
 	"github.com/argoproj/gitops-engine/pkg/sync/common"
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	. "github.com/argoproj/gitops-engine/pkg/utils/testing"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/argoproj/argo-cd/v2/controller/testdata"
@@ -59,6 +64,44 @@ func TestPersistRevisionHistory(t *testing.T) {
 	assert.Equal(t, "abc123", updatedApp.Status.History[0].Revision)
 }
 
+func TestPersistRevisionHistoryChartDetails(t *testing.T) {
+	app := newFakeApp()
+	app.Status.OperationState = nil
+	app.Status.History = nil
+	app.Spec.Source = &v1alpha1.ApplicationSource{RepoURL: "https://charts.example.com", Chart: "my-chart", TargetRevision: "1.*"}
+
+	defaultProject := &v1alpha1.AppProject{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: test.FakeArgoCDNamespace,
+			Name:      "default",
+		},
+	}
+	chartDetails := &v1alpha1.ChartDetails{AppVersion: "2.0.0", Digest: "sha256:abc123", RepoURL: "https://charts.example.com"}
+	data := fakeData{
+		apps: []runtime.Object{app, defaultProject},
+		manifestResponse: &apiclient.ManifestResponse{
+			Manifests: []string{},
+			Namespace: test.FakeDestNamespace,
+			Server:    test.FakeClusterURL,
+			Revision:  "1.2.0",
+		},
+		managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+		chartDetails:    chartDetails,
+	}
+	ctrl := newFakeController(&data)
+
+	opState := &v1alpha1.OperationState{Operation: v1alpha1.Operation{
+		Sync: &v1alpha1.SyncOperation{},
+	}}
+	ctrl.appStateManager.SyncAppState(app, opState)
+
+	updatedApp, err := ctrl.applicationClientset.ArgoprojV1alpha1().Applications(app.Namespace).Get(context.Background(), app.Name, v1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, updatedApp.Status.History, 1)
+	assert.Equal(t, "1.2.0", updatedApp.Status.History[0].Revision)
+	assert.Equal(t, []v1alpha1.ChartDetails{*chartDetails}, updatedApp.Status.History[0].Charts)
+}
+
 func TestPersistManagedNamespaceMetadataState(t *testing.T) {
 	app := newFakeApp()
 	app.Status.OperationState = nil
@@ -254,6 +297,336 @@ func TestAppStateManager_SyncAppState(t *testing.T) {
 		assert.Equal(t, common.OperationFailed, opState.Phase)
 		assert.Contains(t, opState.Message, syncErrorMsg)
 	})
+
+	t.Run("will fail the sync if strictly validated manifests are invalid", func(t *testing.T) {
+		// given
+		loader := bundledOpenAPISchemas
+		defer func() { bundledOpenAPISchemas = loader }()
+		bundledOpenAPISchemas = newTestOpenAPISchemasLoader(t)
+
+		app := newFakeApp()
+		app.Status.OperationState = nil
+		app.Status.History = nil
+		project := &v1alpha1.AppProject{
+			ObjectMeta: v1.ObjectMeta{Namespace: test.FakeArgoCDNamespace, Name: "default"},
+			Spec:       v1alpha1.AppProjectSpec{ManifestValidation: &v1alpha1.ManifestValidation{Strict: true, Schemas: "v1.27"}},
+		}
+		invalid := newConfigMap("invalid", map[string]interface{}{"foo": []interface{}{"bar"}})
+		invalid.Object["spec"] = map[string]interface{}{}
+		ctrl := newFakeController(&fakeData{
+			apps: []runtime.Object{app, project},
+			manifestResponse: &apiclient.ManifestResponse{
+				Manifests: []string{toJSON(t, invalid)},
+				Namespace: test.FakeDestNamespace,
+				Server:    test.FakeClusterURL,
+				Revision:  "abc123",
+			},
+			managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+		})
+		opState := &v1alpha1.OperationState{Operation: v1alpha1.Operation{
+			Sync: &v1alpha1.SyncOperation{Source: &v1alpha1.ApplicationSource{}},
+		}}
+
+		// when
+		ctrl.appStateManager.SyncAppState(app, opState)
+
+		// then
+		assert.Equal(t, common.OperationFailed, opState.Phase)
+		assert.Contains(t, opState.Message, "2 manifest validation errors against the OpenAPI schemas of Kubernetes v1.27")
+		assert.Contains(t, opState.Message, "ConfigMap.data.foo")
+		assert.Contains(t, opState.Message, `unknown field "spec"`)
+	})
+
+	t.Run("will keep the persisted results when resuming the sync", func(t *testing.T) {
+		// given
+		app := newFakeApp()
+		app.Status.OperationState = nil
+		app.Status.History = nil
+		project := &v1alpha1.AppProject{
+			ObjectMeta: v1.ObjectMeta{Namespace: test.FakeArgoCDNamespace, Name: "default"},
+		}
+		ctrl := newFakeController(&fakeData{
+			apps: []runtime.Object{app, project},
+			manifestResponse: &apiclient.ManifestResponse{
+				Manifests: []string{},
+				Namespace: test.FakeDestNamespace,
+				Server:    test.FakeClusterURL,
+				Revision:  "abc123",
+			},
+			managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+		})
+		hookResult := &v1alpha1.ResourceResult{
+			Kind:      "Pod",
+			Namespace: test.FakeDestNamespace,
+			Name:      "pre-sync-hook",
+			HookType:  common.HookTypePreSync,
+			HookPhase: common.OperationSucceeded,
+			SyncPhase: common.SyncPhasePreSync,
+			Status:    common.ResultCodeSynced,
+		}
+		opState := &v1alpha1.OperationState{
+			Operation: v1alpha1.Operation{
+				Sync: &v1alpha1.SyncOperation{Source: &v1alpha1.ApplicationSource{}},
+			},
+			Phase:      common.OperationRunning,
+			StartedAt:  v1.NewTime(time.Now().Add(-time.Minute)),
+			SyncResult: &v1alpha1.SyncOperationResult{Revision: "abc123", Resources: v1alpha1.ResourceResults{hookResult}},
+		}
+
+		// when
+		ctrl.appStateManager.SyncAppState(app, opState)
+
+		// then
+		assert.Equal(t, "abc123", opState.SyncResult.Revision)
+		if assert.Len(t, opState.SyncResult.Resources, 1) {
+			assert.Equal(t, "pre-sync-hook", opState.SyncResult.Resources[0].Name)
+			assert.Equal(t, common.OperationSucceeded, opState.SyncResult.Resources[0].HookPhase)
+		}
+	})
+}
+
+func TestPersistSyncProgress(t *testing.T) {
+	app := newFakeApp()
+	app.Status.OperationState = &v1alpha1.OperationState{
+		Operation:  v1alpha1.Operation{Sync: &v1alpha1.SyncOperation{}},
+		Phase:      common.OperationRunning,
+		StartedAt:  v1.NewTime(time.Now().Add(-time.Minute)),
+		SyncResult: &v1alpha1.SyncOperationResult{Revision: "abc123"},
+	}
+	state := app.Status.OperationState.DeepCopy()
+	// the operation is terminated while the wave is applied
+	app.Status.OperationState.Phase = common.OperationTerminating
+	ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}})
+	syncCtx := &resultsSyncContext{results: []common.ResourceSyncResult{{
+		ResourceKey: kube.ResourceKey{Kind: "ConfigMap", Namespace: test.FakeDestNamespace, Name: "wave-0"},
+		Version:     "v1",
+		Status:      common.ResultCodeSynced,
+		SyncPhase:   common.SyncPhaseSync,
+	}}}
+
+	var waves []int
+	hook := checkpointSyncWaves(func() {
+		ctrl.appStateManager.(*appStateManager).persistSyncProgress(app, state, syncCtx, log.WithField("application", app.Name))
+	}, func(_ common.SyncPhase, wave int, _ bool) error {
+		waves = append(waves, wave)
+		return nil
+	})
+	require.NoError(t, hook(common.SyncPhaseSync, 0, false))
+	assert.Equal(t, []int{0}, waves)
+
+	app, err := ctrl.applicationClientset.ArgoprojV1alpha1().Applications(app.Namespace).Get(context.Background(), app.Name, v1.GetOptions{})
+	require.NoError(t, err)
+	checkpoint := app.Status.OperationState
+	require.NotNil(t, checkpoint.SyncResult)
+	assert.Equal(t, "abc123", checkpoint.SyncResult.Revision)
+	if assert.Len(t, checkpoint.SyncResult.Resources, 1) {
+		assert.Equal(t, "wave-0", checkpoint.SyncResult.Resources[0].Name)
+		assert.Equal(t, common.ResultCodeSynced, checkpoint.SyncResult.Resources[0].Status)
+	}
+	// only the sync result is persisted, keeping the phase of the operation
+	assert.Equal(t, common.OperationTerminating, checkpoint.Phase)
+}
+
+// resultsSyncContext is a sync context which returns the given resource results
+type resultsSyncContext struct {
+	results []common.ResourceSyncResult
+}
+
+func (c *resultsSyncContext) Terminate() {}
+
+func (c *resultsSyncContext) Sync() {}
+
+func (c *resultsSyncContext) GetState() (common.OperationPhase, string, []common.ResourceSyncResult) {
+	return common.OperationRunning, "", c.results
+}
+
+func TestOperationSyncId(t *testing.T) {
+	app := newFakeApp()
+	state := &v1alpha1.OperationState{StartedAt: v1.NewTime(time.Unix(1700000000, 0))}
+	syncId := operationSyncId(app, state)
+	assert.True(t, strings.HasPrefix(syncId, "1700000000-"))
+
+	// a resumed operation keeps the ID
+	assert.Equal(t, syncId, operationSyncId(app.DeepCopy(), state.DeepCopy()))
+
+	other := newFakeApp()
+	other.Name = "other"
+	assert.NotEqual(t, syncId, operationSyncId(other, state))
+	assert.NotEqual(t, syncId, operationSyncId(app, &v1alpha1.OperationState{StartedAt: v1.NewTime(time.Unix(1700000001, 0))}))
+}
+
+func TestSyncAppStateLabelSelector(t *testing.T) {
+	setup := func() (*v1alpha1.Application, *ApplicationController) {
+		app := newFakeApp()
+		app.Status.OperationState = nil
+		app.Status.History = nil
+		defaultProject := &v1alpha1.AppProject{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: test.FakeArgoCDNamespace,
+				Name:      "default",
+			},
+		}
+		ctrl := newFakeController(&fakeData{
+			apps: []runtime.Object{app, defaultProject},
+			manifestResponse: &apiclient.ManifestResponse{
+				Manifests: []string{},
+				Namespace: test.FakeDestNamespace,
+				Server:    test.FakeClusterURL,
+				Revision:  "abc123",
+			},
+			managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+		})
+		return app, ctrl
+	}
+
+	t.Run("InvalidSelector", func(t *testing.T) {
+		app, ctrl := setup()
+		opState := &v1alpha1.OperationState{Operation: v1alpha1.Operation{
+			Sync: &v1alpha1.SyncOperation{LabelSelector: "tier in (frontend"},
+		}}
+		ctrl.appStateManager.SyncAppState(app, opState)
+		assert.Equal(t, common.OperationError, opState.Phase)
+		assert.Contains(t, opState.Message, "invalid label selector")
+	})
+
+	t.Run("NotRecordedInHistory", func(t *testing.T) {
+		app, ctrl := setup()
+		opState := &v1alpha1.OperationState{Operation: v1alpha1.Operation{
+			Sync: &v1alpha1.SyncOperation{LabelSelector: "tier=frontend"},
+		}}
+		ctrl.appStateManager.SyncAppState(app, opState)
+		assert.Equal(t, common.OperationSucceeded, opState.Phase)
+
+		updatedApp, err := ctrl.applicationClientset.ArgoprojV1alpha1().Applications(app.Namespace).Get(context.Background(), app.Name, v1.GetOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, updatedApp.Status.History)
+	})
+}
+
+func TestSyncAppStateResourcesQuota(t *testing.T) {
+	pod1 := NewPod()
+	pod1.SetName("pod-1")
+	pod1.SetNamespace(test.FakeDestNamespace)
+	pod2 := NewPod()
+	pod2.SetName("pod-2")
+	pod2.SetNamespace(test.FakeDestNamespace)
+	setup := func(maxResources int64) (*v1alpha1.Application, *ApplicationController) {
+		app := newFakeApp()
+		app.Status.OperationState = nil
+		app.Status.History = nil
+		defaultProject := &v1alpha1.AppProject{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: test.FakeArgoCDNamespace,
+				Name:      "default",
+			},
+			Spec: v1alpha1.AppProjectSpec{
+				SourceRepos:  []string{"*"},
+				Destinations: []v1alpha1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+				Quotas:       &v1alpha1.ProjectQuotas{MaxResourcesPerApplication: maxResources},
+			},
+		}
+		ctrl := newFakeController(&fakeData{
+			apps: []runtime.Object{app, defaultProject},
+			manifestResponse: &apiclient.ManifestResponse{
+				Manifests: []string{toJSON(t, pod1), toJSON(t, pod2)},
+				Namespace: test.FakeDestNamespace,
+				Server:    test.FakeClusterURL,
+				Revision:  "abc123",
+			},
+			managedLiveObjs: make(map[kube.ResourceKey]*unstructured.Unstructured),
+		})
+		return app, ctrl
+	}
+
+	t.Run("QuotaExceeded", func(t *testing.T) {
+		app, ctrl := setup(1)
+		opState := &v1alpha1.OperationState{Operation: v1alpha1.Operation{
+			Sync: &v1alpha1.SyncOperation{},
+		}}
+		ctrl.appStateManager.SyncAppState(app, opState)
+		assert.Equal(t, common.OperationFailed, opState.Phase)
+		assert.Contains(t, opState.Message, "application manages 2 resources, which exceeds the quota of 1 resources per application of project 'default'")
+	})
+
+	t.Run("WithinQuota", func(t *testing.T) {
+		app, ctrl := setup(2)
+		opState := &v1alpha1.OperationState{Operation: v1alpha1.Operation{
+			Sync: &v1alpha1.SyncOperation{},
+		}}
+		ctrl.appStateManager.SyncAppState(app, opState)
+		// the resources are synced, which fails without a cluster to sync them to
+		assert.NotContains(t, opState.Message, "exceeds the quota")
+		assert.Len(t, opState.SyncResult.Resources, 2)
+	})
+}
+
+func TestMatchesLabelSelector(t *testing.T) {
+	frontend := newConfigMap("frontend", nil)
+	frontend.SetLabels(map[string]string{"tier": "frontend"})
+	backend := newConfigMap("backend", nil)
+	backend.SetLabels(map[string]string{"tier": "backend"})
+	selector, err := labels.Parse("tier=frontend")
+	require.NoError(t, err)
+
+	assert.True(t, matchesLabelSelector(labels.Everything(), backend, nil))
+	assert.True(t, matchesLabelSelector(selector, frontend, nil))
+	assert.False(t, matchesLabelSelector(selector, backend, nil))
+	// the target labels take precedence over the live labels
+	assert.False(t, matchesLabelSelector(selector, backend, frontend))
+	// resources which are pruned are matched by their live labels
+	assert.True(t, matchesLabelSelector(selector, nil, frontend))
+	assert.False(t, matchesLabelSelector(selector, nil, nil))
+}
+
+func TestSyncWaveDelay(t *testing.T) {
+	logEntry := log.WithField("application", "guestbook")
+	t.Setenv(EnvVarSyncWaveDelay, "")
+	assert.Equal(t, 2*time.Second, syncWaveDelay(nil, logEntry))
+	assert.Equal(t, 10*time.Second, syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=10s"}, logEntry))
+	assert.Equal(t, time.Duration(0), syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=0s"}, logEntry))
+	assert.Equal(t, 2*time.Second, syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=later"}, logEntry))
+
+	t.Setenv(EnvVarSyncWaveDelay, "5")
+	assert.Equal(t, 5*time.Second, syncWaveDelay(nil, logEntry))
+	assert.Equal(t, time.Second, syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=1s"}, logEntry))
+
+	// the delay of applications is limited to the maximum delay of the controller
+	assert.Equal(t, time.Minute, syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=1h"}, logEntry))
+	t.Setenv(EnvVarSyncWaveMaxDelay, "30")
+	assert.Equal(t, 30*time.Second, syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=1h"}, logEntry))
+	assert.Equal(t, 10*time.Second, syncWaveDelay(v1alpha1.SyncOptions{"SyncWaveDelay=10s"}, logEntry))
+}
+
+func TestDelayBetweenSyncWaves(t *testing.T) {
+	t.Run("FinalWave", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, delayBetweenSyncWaves(time.Hour, func() bool { return false })(common.SyncPhaseSync, 0, true))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, delayBetweenSyncWaves(100*time.Millisecond, func() bool { return false })(common.SyncPhaseSync, 0, false))
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("Terminating", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, delayBetweenSyncWaves(time.Hour, func() bool { return true })(common.SyncPhaseSync, 0, false))
+		assert.Less(t, time.Since(start), 2*syncWaveTerminationCheckInterval)
+	})
+}
+
+func TestIsOperationTerminating(t *testing.T) {
+	app := newFakeApp()
+	app.Status.OperationState = &v1alpha1.OperationState{Phase: common.OperationRunning}
+	ctrl := newFakeController(&fakeData{apps: []runtime.Object{app, &defaultProj}})
+	assert.False(t, ctrl.appStateManager.(*appStateManager).isOperationTerminating(app))
+
+	app.Status.OperationState.Phase = common.OperationTerminating
+	_, err := ctrl.applicationClientset.ArgoprojV1alpha1().Applications(app.Namespace).Update(context.Background(), app, v1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.True(t, ctrl.appStateManager.(*appStateManager).isOperationTerminating(app))
 }
 
 func TestNormalizeTargetResources(t *testing.T) {
@@ -290,7 +663,7 @@ func TestNormalizeTargetResources(t *testing.T) {
 		f := setup(t, ignores)
 
 		// when
-		targets, err := normalizeTargetResources(f.comparisonResult)
+		targets, err := normalizeTargetResources(f.comparisonResult, true)
 
 		// then
 		require.NoError(t, err)
@@ -303,7 +676,7 @@ func TestNormalizeTargetResources(t *testing.T) {
 		f := setup(t, []v1alpha1.ResourceIgnoreDifferences{})
 
 		// when
-		targets, err := normalizeTargetResources(f.comparisonResult)
+		targets, err := normalizeTargetResources(f.comparisonResult, true)
 
 		// then
 		require.NoError(t, err)
@@ -323,7 +696,7 @@ func TestNormalizeTargetResources(t *testing.T) {
 		unstructured.RemoveNestedField(live.Object, "metadata", "annotations", "iksm-version")
 
 		// when
-		targets, err := normalizeTargetResources(f.comparisonResult)
+		targets, err := normalizeTargetResources(f.comparisonResult, true)
 
 		// then
 		require.NoError(t, err)
@@ -348,7 +721,7 @@ func TestNormalizeTargetResources(t *testing.T) {
 		f := setup(t, ignores)
 
 		// when
-		targets, err := normalizeTargetResources(f.comparisonResult)
+		targets, err := normalizeTargetResources(f.comparisonResult, true)
 
 		// then
 		require.NoError(t, err)
@@ -362,6 +735,37 @@ func TestNormalizeTargetResources(t *testing.T) {
 		require.True(t, ok)
 		assert.Equal(t, int64(4), replicas)
 	})
+	t.Run("will only modify annotated target resources if not enabled for the application", func(t *testing.T) {
+		// given
+		ignore := v1alpha1.ResourceIgnoreDifferences{
+			Group:                 "*",
+			Kind:                  "*",
+			ManagedFieldsManagers: []string{"janitor"},
+		}
+		f := setup(t, []v1alpha1.ResourceIgnoreDifferences{ignore})
+
+		// when
+		targets, err := normalizeTargetResources(f.comparisonResult, false)
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, len(targets))
+		assert.Equal(t, "1.0", targets[0].GetAnnotations()["iksm-version"])
+
+		// given
+		target := f.comparisonResult.reconciliationResult.Target[0]
+		annotations := target.GetAnnotations()
+		annotations[common.AnnotationSyncOptions] = "RespectIgnoreDifferences=true"
+		target.SetAnnotations(annotations)
+
+		// when
+		targets, err = normalizeTargetResources(f.comparisonResult, false)
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, len(targets))
+		assert.Equal(t, "2.0", targets[0].GetAnnotations()["iksm-version"])
+	})
 	t.Run("will keep new array entries not found in live state if not ignored", func(t *testing.T) {
 		t.Skip("limitation in the current implementation")
 		// given
@@ -377,7 +781,7 @@ func TestNormalizeTargetResources(t *testing.T) {
 		f.comparisonResult.reconciliationResult.Target = []*unstructured.Unstructured{target}
 
 		// when
-		targets, err := normalizeTargetResources(f.comparisonResult)
+		targets, err := normalizeTargetResources(f.comparisonResult, true)
 
 		// then
 		require.NoError(t, err)
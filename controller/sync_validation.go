@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubectl/pkg/util/openapi"
+	"k8s.io/kubectl/pkg/util/openapi/validation"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/env"
+)
+
+const (
+	// EnvVarOpenAPISchemasDir is an environment variable which controls the directory of the bundled OpenAPI schemas,
+	// which contains a swagger document named after each Kubernetes version, e.g. v1.27.json
+	EnvVarOpenAPISchemasDir = "ARGOCD_OPENAPI_SCHEMAS_DIR"
+
+	// syncOptionStrictValidation enables or disables the strict validation of the manifests before syncing
+	syncOptionStrictValidation = "StrictValidation"
+	// syncOptionStrictValidationSchemas selects the Kubernetes version of the bundled OpenAPI schemas to validate against
+	syncOptionStrictValidationSchemas = "StrictValidationSchemas"
+)
+
+var bundledOpenAPISchemas = newOpenAPISchemasLoader(env.StringFromEnv(EnvVarOpenAPISchemasDir, "/app/config/openapi-schemas"))
+
+// openAPISchemasLoader loads the bundled OpenAPI schemas of Kubernetes versions from a directory and caches them
+type openAPISchemasLoader struct {
+	dir string
+
+	lock    sync.Mutex
+	schemas map[string]openapi.Resources
+}
+
+func newOpenAPISchemasLoader(dir string) *openAPISchemasLoader {
+	return &openAPISchemasLoader{dir: dir, schemas: map[string]openapi.Resources{}}
+}
+
+// load returns the bundled OpenAPI schemas of the given Kubernetes version
+func (l *openAPISchemasLoader) load(version string) (openapi.Resources, error) {
+	if version == "" || version != filepath.Base(version) {
+		return nil, fmt.Errorf("invalid Kubernetes version '%s'", version)
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if schemas, ok := l.schemas[version]; ok {
+		return schemas, nil
+	}
+	data, err := os.ReadFile(filepath.Join(l.dir, version+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no OpenAPI schemas are bundled for Kubernetes version %s", version)
+		}
+		return nil, fmt.Errorf("error reading the OpenAPI schemas of Kubernetes version %s: %w", version, err)
+	}
+	doc, err := openapi_v2.ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the OpenAPI schemas of Kubernetes version %s: %w", version, err)
+	}
+	schemas, err := openapi.NewOpenAPIData(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the OpenAPI schemas of Kubernetes version %s: %w", version, err)
+	}
+	l.schemas[version] = schemas
+	return schemas, nil
+}
+
+// syncOptionValue returns the value of the sync option with the given key, if any
+func syncOptionValue(options v1alpha1.SyncOptions, key string) (string, bool) {
+	for _, option := range options {
+		if k, v, ok := strings.Cut(option, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// manifestValidationSettings returns whether the manifests must be validated strictly before syncing, and the
+// Kubernetes version of the bundled OpenAPI schemas to validate against. An empty version selects the OpenAPI schemas of
+// the destination cluster. The sync options of the operation can enable strict validation and select the schemas, but
+// cannot disable strict validation which is required by the project.
+func manifestValidationSettings(options v1alpha1.SyncOptions, proj *v1alpha1.AppProject) (bool, string) {
+	strict, schemas := false, ""
+	if proj != nil && proj.Spec.ManifestValidation != nil {
+		strict, schemas = proj.Spec.ManifestValidation.Strict, proj.Spec.ManifestValidation.Schemas
+	}
+	if value, ok := syncOptionValue(options, syncOptionStrictValidation); ok && value == "true" {
+		strict = true
+	}
+	if value, ok := syncOptionValue(options, syncOptionStrictValidationSchemas); ok {
+		schemas = value
+	}
+	return strict, schemas
+}
+
+// validateManifests validates the given objects against the given OpenAPI schemas and returns the violations of all
+// objects. Objects of kinds which are missing from the schemas, e.g. custom resources, are not validated.
+func validateManifests(objs []*unstructured.Unstructured, schemas openapi.Resources) []string {
+	validator := validation.NewSchemaValidation(schemas)
+	var violations []string
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		key := kube.GetResourceKey(obj)
+		data, err := obj.MarshalJSON()
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: %v", key.String(), err))
+			continue
+		}
+		err = validator.ValidateBytes(data)
+		if err == nil {
+			continue
+		}
+		errs := []error{err}
+		if agg, ok := err.(utilerrors.Aggregate); ok {
+			errs = utilerrors.Flatten(agg).Errors()
+		}
+		for _, err := range errs {
+			violations = append(violations, fmt.Sprintf("%s: %v", key.String(), err))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const testOpenAPISchemas = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.27.0"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.api.core.v1.ConfigMap": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+        "data": {"type": "object", "additionalProperties": {"type": "string"}}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "ConfigMap", "version": "v1"}]
+    },
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "namespace": {"type": "string"}
+      }
+    }
+  }
+}`
+
+func newTestOpenAPISchemasLoader(t *testing.T) *openAPISchemasLoader {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v1.27.json"), []byte(testOpenAPISchemas), 0644))
+	return newOpenAPISchemasLoader(dir)
+}
+
+func newConfigMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"data":       data,
+	}}
+}
+
+func TestOpenAPISchemasLoader(t *testing.T) {
+	loader := newTestOpenAPISchemasLoader(t)
+
+	schemas, err := loader.load("v1.27")
+	require.NoError(t, err)
+	assert.NotNil(t, schemas)
+	cached, err := loader.load("v1.27")
+	require.NoError(t, err)
+	assert.Same(t, schemas, cached)
+
+	_, err = loader.load("v1.20")
+	assert.ErrorContains(t, err, "no OpenAPI schemas are bundled for Kubernetes version v1.20")
+	_, err = loader.load("../v1.27")
+	assert.ErrorContains(t, err, "invalid Kubernetes version")
+}
+
+func TestManifestValidationSettings(t *testing.T) {
+	proj := &v1alpha1.AppProject{}
+	strict, schemas := manifestValidationSettings(nil, proj)
+	assert.False(t, strict)
+	assert.Empty(t, schemas)
+
+	strict, schemas = manifestValidationSettings(v1alpha1.SyncOptions{"StrictValidation=true"}, proj)
+	assert.True(t, strict)
+	assert.Empty(t, schemas)
+
+	proj.Spec.ManifestValidation = &v1alpha1.ManifestValidation{Strict: true, Schemas: "v1.26"}
+	strict, schemas = manifestValidationSettings(nil, proj)
+	assert.True(t, strict)
+	assert.Equal(t, "v1.26", schemas)
+
+	strict, schemas = manifestValidationSettings(v1alpha1.SyncOptions{"StrictValidationSchemas=v1.27"}, proj)
+	assert.True(t, strict)
+	assert.Equal(t, "v1.27", schemas)
+
+	// the project requires strict validation, which applications cannot disable
+	strict, _ = manifestValidationSettings(v1alpha1.SyncOptions{"StrictValidation=false"}, proj)
+	assert.True(t, strict)
+
+	proj.Spec.ManifestValidation.Strict = false
+	strict, _ = manifestValidationSettings(v1alpha1.SyncOptions{"StrictValidation=false"}, proj)
+	assert.False(t, strict)
+}
+
+func TestValidateManifests(t *testing.T) {
+	schemas, err := newTestOpenAPISchemasLoader(t).load("v1.27")
+	require.NoError(t, err)
+
+	valid := newConfigMap("valid", map[string]interface{}{"foo": "bar"})
+	invalid := newConfigMap("invalid", map[string]interface{}{"foo": []interface{}{"bar"}, "bar": map[string]interface{}{}})
+	unknownField := newConfigMap("unknown-field", nil)
+	unknownField.Object["spec"] = map[string]interface{}{}
+	custom := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Custom",
+		"metadata":   map[string]interface{}{"name": "custom"},
+		"spec":       map[string]interface{}{"foo": "bar"},
+	}}
+
+	assert.Empty(t, validateManifests([]*unstructured.Unstructured{valid, nil, custom}, schemas))
+
+	violations := validateManifests([]*unstructured.Unstructured{valid, invalid, unknownField}, schemas)
+	require.Len(t, violations, 3)
+	assert.Contains(t, violations[0], "/ConfigMap/default/invalid")
+	assert.Contains(t, violations[1], "/ConfigMap/default/invalid")
+	assert.Contains(t, violations[2], "/ConfigMap/default/unknown-field")
+	assert.Contains(t, violations[2], `unknown field "spec"`)
+}
@@ -0,0 +1,10 @@
+#!/usr/bin/env sh
+
+# Usage: ./add-openapi-schemas-checksums.sh 1.28.2  # use the desired Kubernetes version
+
+set -e
+
+# the swagger documents aren't released with checksums, so the checksum of the downloaded document is recorded
+wget "https://raw.githubusercontent.com/kubernetes/kubernetes/v$1/api/openapi-spec/swagger.json" -O "kubernetes-swagger-v$1.json"
+shasum -a 256 "kubernetes-swagger-v$1.json" > "kubernetes-swagger-v$1.json.sha256"
+rm "kubernetes-swagger-v$1.json"
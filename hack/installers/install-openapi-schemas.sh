@@ -0,0 +1,17 @@
+#!/bin/bash
+set -eux -o pipefail
+
+. $(dirname $0)/../tool-versions.sh
+
+# The OpenAPI schemas are installed as swagger documents named after the minor Kubernetes versions, e.g. v1.27.json,
+# which the application controller validates manifests against when the StrictValidationSchemas sync option is set.
+# The versions must match BundledOpenAPISchemasVersions in pkg/apis/application/v1alpha1/types.go.
+INSTALL_PATH="${INSTALL_PATH:-/app/config/openapi-schemas}"
+[ -d $INSTALL_PATH ] || mkdir -p $INSTALL_PATH
+
+for version in ${openapi_schemas_versions}; do
+  export TARGET_FILE=kubernetes-swagger-v${version}.json
+  [ -e ${DOWNLOADS}/${TARGET_FILE} ] || curl -sLf --retry 3 -o ${DOWNLOADS}/${TARGET_FILE} https://raw.githubusercontent.com/kubernetes/kubernetes/v${version}/api/openapi-spec/swagger.json
+  $(dirname $0)/compare-chksum.sh
+  install -m 0644 ${DOWNLOADS}/${TARGET_FILE} $INSTALL_PATH/v${version%.*}.json
+done
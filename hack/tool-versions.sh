@@ -8,11 +8,15 @@
 # downloaded binary with a ".sha256" suffix appended, containing the proper
 # SHA256 sum of the binary.
 #
-# Use ./hack/installers/checksums/add-helm-checksums.sh and
-# add-kustomize-checksums.sh to help download checksums.
+# Use ./hack/installers/checksums/add-helm-checksums.sh,
+# add-kustomize-checksums.sh and add-openapi-schemas-checksums.sh to help
+# download checksums.
 ###############################################################################
 helm3_version=3.12.1
 kubectl_version=1.17.8
 kubectx_version=0.6.3
 kustomize5_version=5.1.0
+# patch releases of the Kubernetes versions whose OpenAPI schemas are bundled in the image, which must match
+# BundledOpenAPISchemasVersions in pkg/apis/application/v1alpha1/types.go
+openapi_schemas_versions="1.24.17 1.25.14 1.26.9 1.27.6 1.28.2"
 protoc_version=3.17.3
@@ -278,7 +278,9 @@ type RepoQuery struct {
 	// Repo URL for query
 	Repo string `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
 	// Whether to force a cache refresh on repo's connection state
-	ForceRefresh         bool     `protobuf:"varint,2,opt,name=forceRefresh,proto3" json:"forceRefresh,omitempty"`
+	ForceRefresh bool `protobuf:"varint,2,opt,name=forceRefresh,proto3" json:"forceRefresh,omitempty"`
+	// Whether to omit the connection state of the repositories, e.g. when only the inventory is needed
+	SkipConnectionState  bool     `protobuf:"varint,3,opt,name=skipConnectionState,proto3" json:"skipConnectionState,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -331,6 +333,13 @@ func (m *RepoQuery) GetForceRefresh() bool {
 	return false
 }
 
+func (m *RepoQuery) GetSkipConnectionState() bool {
+	if m != nil {
+		return m.SkipConnectionState
+	}
+	return false
+}
+
 // RepoAccessQuery is a query for checking access to a repo
 type RepoAccessQuery struct {
 	// The URL to the repo
@@ -573,6 +582,142 @@ func (m *RepoResponse) XXX_DiscardUnknown() {
 var xxx_messageInfo_RepoResponse proto.InternalMessageInfo
 
 // RepoCreateRequest is a request for creating repository config
+
+type RepoAccessExplanation struct {
+	// Repo is the URL of the repository
+	Repo string `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
+	// Configured is whether the repository is configured explicitly, rather than only matching a credential template
+	Configured bool `protobuf:"varint,2,opt,name=configured,proto3" json:"configured,omitempty"`
+	// CredentialSource is where the credentials come from: inline if the repository has its own credentials, template if they are inherited from a credential template, or none
+	CredentialSource string `protobuf:"bytes,3,opt,name=credentialSource,proto3" json:"credentialSource,omitempty"`
+	// CredentialTemplate is the URL prefix of the credential template the credentials are inherited from
+	CredentialTemplate string `protobuf:"bytes,4,opt,name=credentialTemplate,proto3" json:"credentialTemplate,omitempty"`
+	// AuthMethods are the authentication methods configured by the credentials, e.g. basic-auth or ssh-private-key
+	AuthMethods []string `protobuf:"bytes,5,rep,name=authMethods,proto3" json:"authMethods,omitempty"`
+	// Username is the username of the credentials, if any
+	Username string `protobuf:"bytes,6,opt,name=username,proto3" json:"username,omitempty"`
+	// Insecure is whether the verification of TLS certificates or SSH host keys is skipped
+	Insecure bool `protobuf:"varint,7,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	// TlsCertificates describes the TLS certificates configured for the host of the repository
+	TlsCertificates []string `protobuf:"bytes,8,rep,name=tlsCertificates,proto3" json:"tlsCertificates,omitempty"`
+	// SshKnownHosts describes the SSH known host keys configured for the host of the repository
+	SshKnownHosts []string `protobuf:"bytes,9,rep,name=sshKnownHosts,proto3" json:"sshKnownHosts,omitempty"`
+	// Proxy is the URL of the proxy used to access the repository, without credentials
+	Proxy string `protobuf:"bytes,10,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	// ProxySource is where the proxy is configured: repository, api-server-environment (the proxy environment variables of the API server, which may differ from the ones of the repo-server), or none
+	ProxySource          string   `protobuf:"bytes,11,opt,name=proxySource,proto3" json:"proxySource,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RepoAccessExplanation) Reset()         { *m = RepoAccessExplanation{} }
+func (m *RepoAccessExplanation) String() string { return proto.CompactTextString(m) }
+func (*RepoAccessExplanation) ProtoMessage()    {}
+func (m *RepoAccessExplanation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RepoAccessExplanation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RepoAccessExplanation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RepoAccessExplanation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RepoAccessExplanation.Merge(m, src)
+}
+func (m *RepoAccessExplanation) XXX_Size() int {
+	return m.Size()
+}
+func (m *RepoAccessExplanation) XXX_DiscardUnknown() {
+	xxx_messageInfo_RepoAccessExplanation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RepoAccessExplanation proto.InternalMessageInfo
+
+func (m *RepoAccessExplanation) GetRepo() string {
+	if m != nil {
+		return m.Repo
+	}
+	return ""
+}
+
+func (m *RepoAccessExplanation) GetConfigured() bool {
+	if m != nil {
+		return m.Configured
+	}
+	return false
+}
+
+func (m *RepoAccessExplanation) GetCredentialSource() string {
+	if m != nil {
+		return m.CredentialSource
+	}
+	return ""
+}
+
+func (m *RepoAccessExplanation) GetCredentialTemplate() string {
+	if m != nil {
+		return m.CredentialTemplate
+	}
+	return ""
+}
+
+func (m *RepoAccessExplanation) GetAuthMethods() []string {
+	if m != nil {
+		return m.AuthMethods
+	}
+	return nil
+}
+
+func (m *RepoAccessExplanation) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *RepoAccessExplanation) GetInsecure() bool {
+	if m != nil {
+		return m.Insecure
+	}
+	return false
+}
+
+func (m *RepoAccessExplanation) GetTlsCertificates() []string {
+	if m != nil {
+		return m.TlsCertificates
+	}
+	return nil
+}
+
+func (m *RepoAccessExplanation) GetSshKnownHosts() []string {
+	if m != nil {
+		return m.SshKnownHosts
+	}
+	return nil
+}
+
+func (m *RepoAccessExplanation) GetProxy() string {
+	if m != nil {
+		return m.Proxy
+	}
+	return ""
+}
+
+func (m *RepoAccessExplanation) GetProxySource() string {
+	if m != nil {
+		return m.ProxySource
+	}
+	return ""
+}
+
 type RepoCreateRequest struct {
 	// Repository definition
 	Repo *v1alpha1.Repository `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
@@ -694,6 +839,7 @@ func init() {
 	proto.RegisterType((*RepoQuery)(nil), "repository.RepoQuery")
 	proto.RegisterType((*RepoAccessQuery)(nil), "repository.RepoAccessQuery")
 	proto.RegisterType((*RepoResponse)(nil), "repository.RepoResponse")
+	proto.RegisterType((*RepoAccessExplanation)(nil), "repository.RepoAccessExplanation")
 	proto.RegisterType((*RepoCreateRequest)(nil), "repository.RepoCreateRequest")
 	proto.RegisterType((*RepoUpdateRequest)(nil), "repository.RepoUpdateRequest")
 }
@@ -817,6 +963,7 @@ type RepositoryServiceClient interface {
 	DeleteRepository(ctx context.Context, in *RepoQuery, opts ...grpc.CallOption) (*RepoResponse, error)
 	// ValidateAccess validates access to a repository with given parameters
 	ValidateAccess(ctx context.Context, in *RepoAccessQuery, opts ...grpc.CallOption) (*RepoResponse, error)
+	ExplainAccess(ctx context.Context, in *RepoQuery, opts ...grpc.CallOption) (*RepoAccessExplanation, error)
 }
 
 type repositoryServiceClient struct {
@@ -864,6 +1011,15 @@ func (c *repositoryServiceClient) ListRefs(ctx context.Context, in *RepoQuery, o
 	return out, nil
 }
 
+func (c *repositoryServiceClient) ExplainAccess(ctx context.Context, in *RepoQuery, opts ...grpc.CallOption) (*RepoAccessExplanation, error) {
+	out := new(RepoAccessExplanation)
+	err := c.cc.Invoke(ctx, "/repository.RepositoryService/ExplainAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *repositoryServiceClient) ListApps(ctx context.Context, in *RepoAppsQuery, opts ...grpc.CallOption) (*RepoAppsResponse, error) {
 	out := new(RepoAppsResponse)
 	err := c.cc.Invoke(ctx, "/repository.RepositoryService/ListApps", in, out, opts...)
@@ -986,6 +1142,7 @@ type RepositoryServiceServer interface {
 	DeleteRepository(context.Context, *RepoQuery) (*RepoResponse, error)
 	// ValidateAccess validates access to a repository with given parameters
 	ValidateAccess(context.Context, *RepoAccessQuery) (*RepoResponse, error)
+	ExplainAccess(context.Context, *RepoQuery) (*RepoAccessExplanation, error)
 }
 
 // UnimplementedRepositoryServiceServer can be embedded to have forward compatible implementations.
@@ -1004,6 +1161,9 @@ func (*UnimplementedRepositoryServiceServer) ListRepositories(ctx context.Contex
 func (*UnimplementedRepositoryServiceServer) ListRefs(ctx context.Context, req *RepoQuery) (*apiclient.Refs, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListRefs not implemented")
 }
+func (*UnimplementedRepositoryServiceServer) ExplainAccess(ctx context.Context, req *RepoQuery) (*RepoAccessExplanation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainAccess not implemented")
+}
 func (*UnimplementedRepositoryServiceServer) ListApps(ctx context.Context, req *RepoAppsQuery) (*RepoAppsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListApps not implemented")
 }
@@ -1111,6 +1271,24 @@ func _RepositoryService_ListRefs_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RepositoryService_ExplainAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepoQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).ExplainAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/repository.RepositoryService/ExplainAccess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).ExplainAccess(ctx, req.(*RepoQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RepositoryService_ListApps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RepoAppsQuery)
 	if err := dec(in); err != nil {
@@ -1311,6 +1489,10 @@ var _RepositoryService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListRefs",
 			Handler:    _RepositoryService_ListRefs_Handler,
 		},
+		{
+			MethodName: "ExplainAccess",
+			Handler:    _RepositoryService_ExplainAccess_Handler,
+		},
 		{
 			MethodName: "ListApps",
 			Handler:    _RepositoryService_ListApps_Handler,
@@ -1570,6 +1752,16 @@ func (m *RepoQuery) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.SkipConnectionState {
+		i--
+		if m.SkipConnectionState {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
 	if m.ForceRefresh {
 		i--
 		if m.ForceRefresh {
@@ -1783,6 +1975,122 @@ func (m *RepoResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RepoAccessExplanation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RepoAccessExplanation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RepoAccessExplanation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.ProxySource) > 0 {
+		i -= len(m.ProxySource)
+		copy(dAtA[i:], m.ProxySource)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.ProxySource)))
+		i--
+		dAtA[i] = 0x5a
+	}
+	if len(m.Proxy) > 0 {
+		i -= len(m.Proxy)
+		copy(dAtA[i:], m.Proxy)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.Proxy)))
+		i--
+		dAtA[i] = 0x52
+	}
+	if len(m.SshKnownHosts) > 0 {
+		for iNdEx := len(m.SshKnownHosts) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.SshKnownHosts[iNdEx])
+			copy(dAtA[i:], m.SshKnownHosts[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.SshKnownHosts[iNdEx])))
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
+	if len(m.TlsCertificates) > 0 {
+		for iNdEx := len(m.TlsCertificates) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TlsCertificates[iNdEx])
+			copy(dAtA[i:], m.TlsCertificates[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.TlsCertificates[iNdEx])))
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if m.Insecure {
+		i--
+		if m.Insecure {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.Username) > 0 {
+		i -= len(m.Username)
+		copy(dAtA[i:], m.Username)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.Username)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.AuthMethods) > 0 {
+		for iNdEx := len(m.AuthMethods) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.AuthMethods[iNdEx])
+			copy(dAtA[i:], m.AuthMethods[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.AuthMethods[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.CredentialTemplate) > 0 {
+		i -= len(m.CredentialTemplate)
+		copy(dAtA[i:], m.CredentialTemplate)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.CredentialTemplate)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.CredentialSource) > 0 {
+		i -= len(m.CredentialSource)
+		copy(dAtA[i:], m.CredentialSource)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.CredentialSource)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Configured {
+		i--
+		if m.Configured {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Repo) > 0 {
+		i -= len(m.Repo)
+		copy(dAtA[i:], m.Repo)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.Repo)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *RepoCreateRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1995,6 +2303,9 @@ func (m *RepoQuery) Size() (n int) {
 	if m.ForceRefresh {
 		n += 2
 	}
+	if m.SkipConnectionState {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2092,6 +2403,66 @@ func (m *RepoResponse) Size() (n int) {
 	return n
 }
 
+func (m *RepoAccessExplanation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Repo)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if m.Configured {
+		n += 2
+	}
+	l = len(m.CredentialSource)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	l = len(m.CredentialTemplate)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if len(m.AuthMethods) > 0 {
+		for _, s := range m.AuthMethods {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if m.Insecure {
+		n += 2
+	}
+	if len(m.TlsCertificates) > 0 {
+		for _, s := range m.TlsCertificates {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	if len(m.SshKnownHosts) > 0 {
+		for _, s := range m.SshKnownHosts {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	l = len(m.Proxy)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	l = len(m.ProxySource)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *RepoCreateRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2747,14 +3118,34 @@ func (m *RepoQuery) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.ForceRefresh = bool(v != 0)
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRepository(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SkipConnectionState", wireType)
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthRepository
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SkipConnectionState = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRepository(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRepository
 			}
 			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
@@ -3385,6 +3776,385 @@ func (m *RepoResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *RepoAccessExplanation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRepository
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RepoAccessExplanation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RepoAccessExplanation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Repo", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Repo = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Configured", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Configured = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CredentialSource", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CredentialSource = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CredentialTemplate", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CredentialTemplate = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthMethods", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AuthMethods = append(m.AuthMethods, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Insecure", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Insecure = bool(v != 0)
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TlsCertificates", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TlsCertificates = append(m.TlsCertificates, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SshKnownHosts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SshKnownHosts = append(m.SshKnownHosts, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Proxy", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Proxy = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProxySource", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProxySource = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRepository(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *RepoCreateRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
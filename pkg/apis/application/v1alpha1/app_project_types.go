@@ -5,10 +5,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/argoproj/argo-cd/v2/util/git"
 	"github.com/argoproj/argo-cd/v2/util/glob"
 
+	timeutil "github.com/argoproj/pkg/time"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -58,6 +60,19 @@ func (p *AppProject) GetRoleByName(name string) (*ProjectRole, int, error) {
 	return nil, -1, fmt.Errorf("role '%s' does not exist in project '%s'", name, p.Name)
 }
 
+// GetDefaultTokenExpiration returns the duration after which the tokens of the role expire if no expiration is
+// requested when they are created. Zero means that the tokens don't expire.
+func (r *ProjectRole) GetDefaultTokenExpiration() (time.Duration, error) {
+	if r.DefaultTokenExpiration == "" {
+		return 0, nil
+	}
+	duration, err := timeutil.ParseDuration(r.DefaultTokenExpiration)
+	if err != nil {
+		return 0, err
+	}
+	return *duration, nil
+}
+
 // GetJWTTokenFromSpec looks up the index of a JWTToken in a project by id (new token), if not then by the issue at time (old token)
 func (p *AppProject) GetJWTTokenFromSpec(roleName string, issuedAt int64, id string) (*JWTToken, int, error) {
 	// This is for backward compatibility. In the oder version, JWTTokens are stored under spec.role
@@ -225,6 +240,9 @@ func (p *AppProject) ValidateProject() error {
 			}
 			existingGroups[group] = true
 		}
+		if _, err := role.GetDefaultTokenExpiration(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid default token expiration '%s' for role '%s': %v", role.DefaultTokenExpiration, role.Name, err)
+		}
 		roleNames[role.Name] = true
 	}
 
@@ -248,9 +266,126 @@ func (p *AppProject) ValidateProject() error {
 		}
 	}
 
+	if p.Spec.ManifestValidation != nil {
+		if err := validateSchemasVersion(p.Spec.ManifestValidation.Schemas); err != nil {
+			return status.Errorf(codes.InvalidArgument, "manifest validation schemas are invalid: %v", err)
+		}
+	}
+
+	featureFlags := make(map[string]bool)
+	for _, flag := range p.Spec.FeatureFlags {
+		if flag.Name == "" {
+			return status.Errorf(codes.InvalidArgument, "feature flag name cannot be empty")
+		}
+		if _, ok := featureFlags[flag.Name]; ok {
+			return status.Errorf(codes.InvalidArgument, "feature flag '%s' already added", flag.Name)
+		}
+		featureFlags[flag.Name] = true
+	}
+
+	if p.Spec.Quotas != nil {
+		if p.Spec.Quotas.MaxApplications < 0 {
+			return status.Errorf(codes.InvalidArgument, "maximum number of applications cannot be negative")
+		}
+		if p.Spec.Quotas.MaxResourcesPerApplication < 0 {
+			return status.Errorf(codes.InvalidArgument, "maximum number of resources per application cannot be negative")
+		}
+	}
+
+	if err := p.Spec.DefaultSyncPolicy.Validate(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "default sync policy is invalid: %v", err)
+	}
+
+	for _, sa := range p.Spec.DestinationServiceAccounts {
+		if sa.Server == "" {
+			return status.Errorf(codes.InvalidArgument, "destination service account server cannot be empty")
+		}
+		namespace, name := sa.GetServiceAccount("default")
+		if namespace == "" || name == "" || strings.ContainsAny(name, ": \t*") || strings.ContainsAny(namespace, " \t*") {
+			return status.Errorf(codes.InvalidArgument, "destination service account '%s' of server '%s' is invalid: must be of the form 'name' or 'namespace:name'", sa.DefaultServiceAccount, sa.Server)
+		}
+	}
+
 	return nil
 }
 
+// ValidateRolePolicies returns the errors of all the invalid policies of the roles of the project. Unlike
+// ValidateProject, which stops at the first error, it allows to report every invalid policy at once.
+func (p *AppProject) ValidateRolePolicies() []error {
+	var errs []error
+	for _, role := range p.Spec.Roles {
+		for _, policy := range role.Policies {
+			if err := validatePolicy(p.Name, role.Name, policy); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// GetSyncPolicy returns the sync policy of the given application of the project, which is the default sync policy of
+// the project unless the application sets its own sync policy
+func (p *AppProject) GetSyncPolicy(app *Application) *SyncPolicy {
+	if app.Spec.SyncPolicy != nil || p == nil {
+		return app.Spec.SyncPolicy
+	}
+	return p.Spec.DefaultSyncPolicy
+}
+
+// GetDestinationServiceAccount returns the first service account of the project which matches the given destination
+// server and namespace, and whether there is one
+func (p *AppProject) GetDestinationServiceAccount(server string, namespace string) (ApplicationDestinationServiceAccount, bool) {
+	for _, sa := range p.Spec.DestinationServiceAccounts {
+		if globMatch(sa.Server, server, false) && globMatch(sa.Namespace, namespace, false) {
+			return sa, true
+		}
+	}
+	return ApplicationDestinationServiceAccount{}, false
+}
+
+// GetServiceAccount returns the namespace and the name of the service account, which is in the given namespace unless
+// the service account is prefixed with its namespace
+func (sa ApplicationDestinationServiceAccount) GetServiceAccount(defaultNamespace string) (namespace string, name string) {
+	if namespace, name, ok := strings.Cut(sa.DefaultServiceAccount, ":"); ok {
+		return namespace, name
+	}
+	return defaultNamespace, sa.DefaultServiceAccount
+}
+
+// ValidateApplicationsQuota returns an error if the project can't have another application, given the number of
+// applications which it already has
+func (p *AppProject) ValidateApplicationsQuota(count int) error {
+	if p.Spec.Quotas == nil || p.Spec.Quotas.MaxApplications <= 0 {
+		return nil
+	}
+	if int64(count) >= p.Spec.Quotas.MaxApplications {
+		return fmt.Errorf("project '%s' has reached its quota of %d applications", p.Name, p.Spec.Quotas.MaxApplications)
+	}
+	return nil
+}
+
+// ValidateResourcesQuota returns an error if an application of the project manages more resources than the quota of
+// the project permits
+func (p *AppProject) ValidateResourcesQuota(count int) error {
+	if p.Spec.Quotas == nil || p.Spec.Quotas.MaxResourcesPerApplication <= 0 {
+		return nil
+	}
+	if int64(count) > p.Spec.Quotas.MaxResourcesPerApplication {
+		return fmt.Errorf("application manages %d resources, which exceeds the quota of %d resources per application of project '%s'", count, p.Spec.Quotas.MaxResourcesPerApplication, p.Name)
+	}
+	return nil
+}
+
+// GetFeatureFlag returns the state of the given feature flag configured for the project, and whether it is configured
+func (p *AppProject) GetFeatureFlag(name string) (enabled bool, ok bool) {
+	for _, flag := range p.Spec.FeatureFlags {
+		if flag.Name == name {
+			return flag.Enabled, true
+		}
+	}
+	return false, false
+}
+
 // AddGroupToRole adds an OIDC group to a role
 func (p *AppProject) AddGroupToRole(roleName, group string) (bool, error) {
 	role, roleIndex, err := p.GetRoleByName(roleName)
@@ -323,24 +458,37 @@ func (proj *AppProject) ProjectPoliciesString() string {
 
 // IsGroupKindPermitted validates if the given resource group/kind is permitted to be deployed in the project
 func (proj AppProject) IsGroupKindPermitted(gk schema.GroupKind, namespaced bool) bool {
-	var isWhiteListed, isBlackListed bool
+	return proj.ValidateGroupKind(gk, namespaced) == nil
+}
+
+// ValidateGroupKind returns an error explaining which of the namespace or cluster resource allow and deny lists of the
+// project doesn't permit the given resource group/kind, or nil if it is permitted
+func (proj AppProject) ValidateGroupKind(gk schema.GroupKind, namespaced bool) error {
 	res := metav1.GroupKind{Group: gk.Group, Kind: gk.Kind}
 
 	if namespaced {
 		namespaceWhitelist := proj.Spec.NamespaceResourceWhitelist
 		namespaceBlacklist := proj.Spec.NamespaceResourceBlacklist
 
-		isWhiteListed = namespaceWhitelist == nil || len(namespaceWhitelist) != 0 && isResourceInList(res, namespaceWhitelist)
-		isBlackListed = len(namespaceBlacklist) != 0 && isResourceInList(res, namespaceBlacklist)
-		return isWhiteListed && !isBlackListed
+		if namespaceWhitelist != nil && (len(namespaceWhitelist) == 0 || !isResourceInList(res, namespaceWhitelist)) {
+			return fmt.Errorf("namespaced resource %s:%s is not permitted in project '%s': it is not in the namespace resource whitelist", gk.Group, gk.Kind, proj.Name)
+		}
+		if len(namespaceBlacklist) != 0 && isResourceInList(res, namespaceBlacklist) {
+			return fmt.Errorf("namespaced resource %s:%s is not permitted in project '%s': it is in the namespace resource blacklist", gk.Group, gk.Kind, proj.Name)
+		}
+		return nil
 	}
 
 	clusterWhitelist := proj.Spec.ClusterResourceWhitelist
 	clusterBlacklist := proj.Spec.ClusterResourceBlacklist
 
-	isWhiteListed = len(clusterWhitelist) != 0 && isResourceInList(res, clusterWhitelist)
-	isBlackListed = len(clusterBlacklist) != 0 && isResourceInList(res, clusterBlacklist)
-	return isWhiteListed && !isBlackListed
+	if len(clusterWhitelist) == 0 || !isResourceInList(res, clusterWhitelist) {
+		return fmt.Errorf("cluster-scoped resource %s:%s is not permitted in project '%s': it is not in the cluster resource whitelist", gk.Group, gk.Kind, proj.Name)
+	}
+	if len(clusterBlacklist) != 0 && isResourceInList(res, clusterBlacklist) {
+		return fmt.Errorf("cluster-scoped resource %s:%s is not permitted in project '%s': it is in the cluster resource blacklist", gk.Group, gk.Kind, proj.Name)
+	}
+	return nil
 }
 
 // IsLiveResourcePermitted returns whether a live resource found in the cluster is permitted by an AppProject
@@ -10,4 +10,10 @@ const (
 	// absolute path means an absolute path within the repository and the relative path is relative to the application
 	// source path within the repository.
 	AnnotationKeyManifestGeneratePaths = "argocd.argoproj.io/manifest-generate-paths"
+
+	// AnnotationKeyManifestGenerateTimeout is an annotation that contains the time the repo server is given to generate
+	// the manifests of the application, in the Go duration format (e.g. "3m"). Config management tools which run longer
+	// are killed. Overrides the repo server timeout configured for the API server and the application controller, but
+	// tools are never given more than the exec timeout of the repo server.
+	AnnotationKeyManifestGenerateTimeout = "argocd.argoproj.io/manifest-generate-timeout"
 )
@@ -33,6 +33,9 @@ const (
 
 	// EnvK8sTCPIdleConnTimeout is the duration when idle TCP connection to the K8s API servers should timeout
 	EnvK8sTCPIdleConnTimeout = "ARGOCD_K8S_TCP_IDLE_TIMEOUT"
+
+	// EnvClusterCredentialsProactiveRefresh enables the proactive refresh of cluster credentials obtained from exec plugins (default: false)
+	EnvClusterCredentialsProactiveRefresh = "ARGOCD_CLUSTER_CREDENTIALS_PROACTIVE_REFRESH"
 )
 
 // Configuration variables associated with the Cluster API
@@ -61,4 +64,7 @@ var (
 
 	// K8sServerSideTimeout defines which server side timeout to send with each API request
 	K8sServerSideTimeout = env.ParseDurationFromEnv(EnvK8sTCPTimeout, 0, 0, math.MaxInt32*time.Second)
+
+	// ClusterCredentialsProactiveRefresh controls whether the credentials of exec plugins are cached and refreshed before they expire
+	ClusterCredentialsProactiveRefresh = env.ParseBoolFromEnv(EnvClusterCredentialsProactiveRefresh, false)
 )
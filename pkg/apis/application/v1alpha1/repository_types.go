@@ -93,6 +93,16 @@ type Repository struct {
 	GCPServiceAccountKey string `json:"gcpServiceAccountKey,omitempty" protobuf:"bytes,21,opt,name=gcpServiceAccountKey"`
 	// ForceHttpBasicAuth specifies whether Argo CD should attempt to force basic auth for HTTP connections
 	ForceHttpBasicAuth bool `json:"forceHttpBasicAuth,omitempty" protobuf:"bytes,22,opt,name=forceHttpBasicAuth"`
+	// CloneDepth limits the history fetched from the repository to the given number of commits. Only valid for Git repositories.
+	CloneDepth int64 `json:"cloneDepth,omitempty" protobuf:"bytes,23,opt,name=cloneDepth"`
+	// CloneFilter specifies a partial clone filter (e.g. blob:none) used when fetching from the repository. Only valid for Git repositories.
+	CloneFilter string `json:"cloneFilter,omitempty" protobuf:"bytes,24,opt,name=cloneFilter"`
+	// EnableSubmodules specifies whether the submodules of the repository are initialized on checkout. The setting of the repo server is used if unset. Only valid for Git repositories.
+	EnableSubmodules *bool `json:"enableSubmodules,omitempty" protobuf:"bytes,25,opt,name=enableSubmodules"`
+	// SkipSubmoduleCreds specifies whether the submodules of the repository are fetched without inheriting the credentials of the repository. Only valid for Git repositories.
+	SkipSubmoduleCreds bool `json:"skipSubmoduleCreds,omitempty" protobuf:"bytes,26,opt,name=skipSubmoduleCreds"`
+	// CredentialState contains the result of the last periodic revalidation of the credentials of the repository
+	CredentialState *ConnectionState `json:"credentialState,omitempty" protobuf:"bytes,27,opt,name=credentialState"`
 }
 
 // IsInsecure returns true if the repository has been configured to skip server verification
@@ -105,6 +115,15 @@ func (repo *Repository) IsLFSEnabled() bool {
 	return repo.EnableLFS
 }
 
+// IsSubmoduleEnabled returns true if the submodules of the repository are initialized on checkout. The given default
+// of the repo server applies unless submodules are explicitly enabled or disabled for the repository.
+func (repo *Repository) IsSubmoduleEnabled(defaultEnabled bool) bool {
+	if repo == nil || repo.EnableSubmodules == nil {
+		return defaultEnabled
+	}
+	return *repo.EnableSubmodules
+}
+
 // HasCredentials returns true when the repository has been configured with any credentials
 func (m *Repository) HasCredentials() bool {
 	return m.Username != "" || m.Password != "" || m.SSHPrivateKey != "" || m.TLSClientCertData != "" || m.GithubAppPrivateKey != ""
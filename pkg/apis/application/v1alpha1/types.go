@@ -1,11 +1,14 @@
 package v1alpha1
 
 import (
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"math"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -24,12 +27,15 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -37,6 +43,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/util/clusterauth"
 	"github.com/argoproj/argo-cd/v2/util/collections"
 	"github.com/argoproj/argo-cd/v2/util/helm"
 	"github.com/argoproj/argo-cd/v2/util/security"
@@ -297,7 +304,9 @@ type ApplicationSourceHelm struct {
 	// ValuesFiles is a list of Helm value files to use when generating a template
 	ValueFiles []string `json:"valueFiles,omitempty" protobuf:"bytes,1,opt,name=valueFiles"`
 	// Parameters is a list of Helm parameters which are passed to the helm template command upon manifest generation
-	Parameters []HelmParameter `json:"parameters,omitempty" protobuf:"bytes,2,opt,name=parameters"`
+	// +patchStrategy=merge
+	// +patchMergeKey=name
+	Parameters []HelmParameter `json:"parameters,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,2,opt,name=parameters"`
 	// ReleaseName is the Helm release name to use. If omitted it will use the application name
 	ReleaseName string `json:"releaseName,omitempty" protobuf:"bytes,3,opt,name=releaseName"`
 	// Values specifies Helm values to be passed to helm template, typically defined as a block. ValuesObject takes precedence over Values, so use one or the other.
@@ -305,7 +314,7 @@ type ApplicationSourceHelm struct {
 	Values string `json:"values,omitempty" patchStrategy:"replace" protobuf:"bytes,4,opt,name=values"`
 	// FileParameters are file parameters to the helm template
 	FileParameters []HelmFileParameter `json:"fileParameters,omitempty" protobuf:"bytes,5,opt,name=fileParameters"`
-	// Version is the Helm version to use for templating ("3")
+	// Version is the Helm version to use for templating ("2" or "3")
 	Version string `json:"version,omitempty" protobuf:"bytes,6,opt,name=version"`
 	// PassCredentials pass credentials to all domains (Helm's --pass-credentials)
 	PassCredentials bool `json:"passCredentials,omitempty" protobuf:"bytes,7,opt,name=passCredentials"`
@@ -316,6 +325,8 @@ type ApplicationSourceHelm struct {
 	// ValuesObject specifies Helm values to be passed to helm template, defined as a map. This takes precedence over Values.
 	// +kubebuilder:pruning:PreserveUnknownFields
 	ValuesObject *runtime.RawExtension `json:"valuesObject,omitempty" protobuf:"bytes,10,opt,name=valuesObject"`
+	// Namespace is the namespace the chart is templated with (Helm's .Release.Namespace). If omitted it will use the destination namespace of the application
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,11,opt,name=namespace"`
 }
 
 // HelmParameter is a parameter that's passed to helm template during manifest generation
@@ -397,7 +408,7 @@ func (in *ApplicationSourceHelm) AddFileParameter(p HelmFileParameter) {
 
 // IsZero Returns true if the Helm options in an application source are considered zero
 func (h *ApplicationSourceHelm) IsZero() bool {
-	return h == nil || (h.Version == "") && (h.ReleaseName == "") && len(h.ValueFiles) == 0 && len(h.Parameters) == 0 && len(h.FileParameters) == 0 && h.ValuesIsEmpty() && !h.PassCredentials && !h.IgnoreMissingValueFiles && !h.SkipCrds
+	return h == nil || (h.Version == "") && (h.ReleaseName == "") && (h.Namespace == "") && len(h.ValueFiles) == 0 && len(h.Parameters) == 0 && len(h.FileParameters) == 0 && h.ValuesIsEmpty() && !h.PassCredentials && !h.IgnoreMissingValueFiles && !h.SkipCrds
 }
 
 // KustomizeImage represents a Kustomize image definition in the format [old_image_name=]<image_name>:<image_tag>
@@ -458,6 +469,34 @@ type ApplicationSourceKustomize struct {
 	CommonAnnotationsEnvsubst bool `json:"commonAnnotationsEnvsubst,omitempty" protobuf:"bytes,10,opt,name=commonAnnotationsEnvsubst"`
 	// Replicas is a list of Kustomize Replicas override specifications
 	Replicas KustomizeReplicas `json:"replicas,omitempty" protobuf:"bytes,11,opt,name=replicas"`
+	// Patches is a list of Kustomize patches which are added to the kustomization before building
+	Patches KustomizePatches `json:"patches,omitempty" protobuf:"bytes,12,opt,name=patches"`
+	// Components is a list of Kustomize components which are added to the kustomization before building
+	Components []string `json:"components,omitempty" protobuf:"bytes,13,rep,name=components"`
+}
+
+type KustomizePatches []KustomizePatch
+
+// KustomizePatch is an inline strategic merge or JSON 6902 patch, or a path to a patch file, together with an
+// optional selector of the resources it is applied to
+type KustomizePatch struct {
+	// Path is the path of a file containing the patch, relative to the kustomization
+	Path string `json:"path,omitempty" yaml:"path,omitempty" protobuf:"bytes,1,opt,name=path"`
+	// Patch is an inline strategic merge or JSON 6902 patch
+	Patch string `json:"patch,omitempty" yaml:"patch,omitempty" protobuf:"bytes,2,opt,name=patch"`
+	// Target selects the resources the patch is applied to
+	Target *KustomizeSelector `json:"target,omitempty" yaml:"target,omitempty" protobuf:"bytes,3,opt,name=target"`
+}
+
+// KustomizeSelector selects the resources a Kustomize patch is applied to
+type KustomizeSelector struct {
+	Group              string `json:"group,omitempty" yaml:"group,omitempty" protobuf:"bytes,1,opt,name=group"`
+	Version            string `json:"version,omitempty" yaml:"version,omitempty" protobuf:"bytes,2,opt,name=version"`
+	Kind               string `json:"kind,omitempty" yaml:"kind,omitempty" protobuf:"bytes,3,opt,name=kind"`
+	Name               string `json:"name,omitempty" yaml:"name,omitempty" protobuf:"bytes,4,opt,name=name"`
+	Namespace          string `json:"namespace,omitempty" yaml:"namespace,omitempty" protobuf:"bytes,5,opt,name=namespace"`
+	AnnotationSelector string `json:"annotationSelector,omitempty" yaml:"annotationSelector,omitempty" protobuf:"bytes,6,opt,name=annotationSelector"`
+	LabelSelector      string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty" protobuf:"bytes,7,opt,name=labelSelector"`
 }
 
 type KustomizeReplica struct {
@@ -522,7 +561,9 @@ func (k *ApplicationSourceKustomize) IsZero() bool {
 			len(k.Images) == 0 &&
 			len(k.Replicas) == 0 &&
 			len(k.CommonLabels) == 0 &&
-			len(k.CommonAnnotations) == 0
+			len(k.CommonAnnotations) == 0 &&
+			len(k.Patches) == 0 &&
+			len(k.Components) == 0
 }
 
 // MergeImage merges a new Kustomize image identifier in to a list of images
@@ -860,6 +901,8 @@ type ApplicationDestination struct {
 	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
 	// Name is an alternate way of specifying the target cluster by its symbolic name
 	Name string `json:"name,omitempty" protobuf:"bytes,3,opt,name=name"`
+	// ClusterSelector is an alternate way of specifying the target cluster by a label selector, which must match the labels of exactly one cluster
+	ClusterSelector string `json:"clusterSelector,omitempty" protobuf:"bytes,4,opt,name=clusterSelector"`
 
 	// nolint:govet
 	isServerInferred bool `json:"-"`
@@ -1008,6 +1051,12 @@ type SyncOperation struct {
 	// Revisions is the list of revision (Git) or chart version (Helm) which to sync each source in sources field for the application to
 	// If omitted, will use the revision specified in app spec.
 	Revisions []string `json:"revisions,omitempty" protobuf:"bytes,11,opt,name=revisions"`
+	// LabelSelector selects the resources which shall be part of the sync by their labels, e.g. "tier=frontend".
+	// If resources are specified as well, only the resources matching both are synced.
+	LabelSelector string `json:"labelSelector,omitempty" protobuf:"bytes,12,opt,name=labelSelector"`
+	// ManualRevision marks a rollback to a revision which was not taken from the deployment history, so that the
+	// resulting history entry can be told apart from regular syncs
+	ManualRevision bool `json:"manualRevision,omitempty" protobuf:"bytes,13,opt,name=manualRevision"`
 }
 
 // IsApplyStrategy returns true if the sync strategy is "apply"
@@ -1015,6 +1064,11 @@ func (o *SyncOperation) IsApplyStrategy() bool {
 	return o.SyncStrategy != nil && o.SyncStrategy.Apply != nil
 }
 
+// IsSelective returns true if only a subset of the resources of the application shall be synced
+func (o *SyncOperation) IsSelective() bool {
+	return len(o.Resources) > 0 || o.LabelSelector != ""
+}
+
 // OperationState contains information about state of a running operation
 type OperationState struct {
 	// Operation is the original requested operation
@@ -1072,11 +1126,43 @@ func (o SyncOptions) HasOption(option string) bool {
 	return false
 }
 
+// ServerSideApplyManager returns the field manager which owns the fields applied with server-side apply. It defaults to
+// argocd-controller and can be overridden with the ServerSideApplyManager sync option.
+func (o SyncOptions) ServerSideApplyManager() string {
+	for _, option := range o {
+		if key, value, ok := strings.Cut(option, "="); ok && key == "ServerSideApplyManager" && value != "" {
+			return value
+		}
+	}
+	return common.ArgoCDSSAManager
+}
+
+// SyncsWithServerSideApply returns true if resources are synced using server-side apply, either because the sync option
+// ServerSideApply=true is set or because the serverSideApplySync feature flag is enabled and server-side apply isn't
+// disabled explicitly
+func (o SyncOptions) SyncsWithServerSideApply(serverSideApplySync bool) bool {
+	if o.HasOption(synccommon.SyncOptionServerSideApply) {
+		return true
+	}
+	return serverSideApplySync && !o.HasOption("ServerSideApply=false")
+}
+
 type ManagedNamespaceMetadata struct {
 	Labels      map[string]string `json:"labels,omitempty" protobuf:"bytes,1,opt,name=labels"`
 	Annotations map[string]string `json:"annotations,omitempty" protobuf:"bytes,2,opt,name=annotations"`
 }
 
+// Validate checks that the labels and annotations can be set on the managed namespace
+func (m *ManagedNamespaceMetadata) Validate() error {
+	fldPath := field.NewPath("spec", "syncPolicy", "managedNamespaceMetadata")
+	errs := metav1validation.ValidateLabels(m.Labels, fldPath.Child("labels"))
+	errs = append(errs, apimachineryvalidation.ValidateAnnotations(m.Annotations, fldPath.Child("annotations"))...)
+	if len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
+
 // SyncPolicy controls when a sync will be performed in response to updates in git
 type SyncPolicy struct {
 	// Automated will keep an application synced to the target revision
@@ -1094,12 +1180,133 @@ func (p *SyncPolicy) IsZero() bool {
 	return p == nil || (p.Automated == nil && len(p.SyncOptions) == 0 && p.Retry == nil)
 }
 
+// Validate checks the sync policy for malformed or conflicting settings which would otherwise be
+// silently ignored by the controller at reconcile time
+func (p *SyncPolicy) Validate() error {
+	return p.ValidateChanges(nil)
+}
+
+// ValidateChanges checks the settings of the sync policy which differ from the given previous sync policy, e.g. the one
+// of an application before an update, so that an application whose sync policy was stored before it was validated can
+// still be updated as long as its invalid settings are not changed. All settings are checked if previous is nil.
+func (p *SyncPolicy) ValidateChanges(previous *SyncPolicy) error {
+	if p == nil {
+		return nil
+	}
+	all := previous == nil
+	if all {
+		previous = &SyncPolicy{}
+	}
+	optionsChanged := all || !reflect.DeepEqual(p.SyncOptions, previous.SyncOptions)
+	if optionsChanged {
+		if err := p.SyncOptions.Validate(); err != nil {
+			return err
+		}
+	}
+	metadataChanged := all || !reflect.DeepEqual(p.ManagedNamespaceMetadata, previous.ManagedNamespaceMetadata)
+	if p.ManagedNamespaceMetadata != nil && (optionsChanged || metadataChanged) {
+		if !p.SyncOptions.HasOption("CreateNamespace=true") {
+			return fmt.Errorf("managedNamespaceMetadata requires the sync option 'CreateNamespace=true'")
+		}
+		if err := p.ManagedNamespaceMetadata.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Retry != nil && (all || !reflect.DeepEqual(p.Retry, previous.Retry)) {
+		if err := p.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that every sync option is of the form 'key=value' and that no options conflict
+func (o SyncOptions) Validate() error {
+	values := make(map[string]string)
+	for _, option := range o {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok || key == "" || value == "" {
+			return fmt.Errorf("sync option '%s' is invalid: must be of the form 'key=value'", option)
+		}
+		if existing, ok := values[key]; ok && existing != value {
+			return fmt.Errorf("sync options '%s=%s' and '%s' conflict", key, existing, option)
+		}
+		values[key] = value
+	}
+	if o.HasOption(synccommon.SyncOptionReplace) && o.HasOption(synccommon.SyncOptionServerSideApply) {
+		return fmt.Errorf("sync options '%s' and '%s' conflict", synccommon.SyncOptionReplace, synccommon.SyncOptionServerSideApply)
+	}
+	if policy, ok := values["PrunePropagationPolicy"]; ok {
+		switch policy {
+		case "foreground", "background", "orphan":
+		default:
+			return fmt.Errorf("sync option 'PrunePropagationPolicy=%s' is invalid: must be one of foreground, background or orphan", policy)
+		}
+	}
+	if strict, ok := values["StrictValidation"]; ok && strict != "true" && strict != "false" {
+		return fmt.Errorf("sync option 'StrictValidation=%s' is invalid: must be true or false", strict)
+	}
+	if version, ok := values["StrictValidationSchemas"]; ok {
+		if err := validateSchemasVersion(version); err != nil {
+			return fmt.Errorf("sync option 'StrictValidationSchemas=%s' is invalid: %w", version, err)
+		}
+	}
+	if timeout, ok := values["SelfHealTimeout"]; ok {
+		if d, err := time.ParseDuration(timeout); err != nil || d < 0 {
+			return fmt.Errorf("sync option 'SelfHealTimeout=%s' is invalid: must be a non-negative duration, e.g. 30s", timeout)
+		}
+	}
+	if manager, ok := values["ServerSideApplyManager"]; ok && len(manager) > 128 {
+		return fmt.Errorf("sync option 'ServerSideApplyManager=%s' is invalid: must be at most 128 characters", manager)
+	}
+	if delay, ok := values["SyncWaveDelay"]; ok {
+		if d, err := time.ParseDuration(delay); err != nil || d < 0 {
+			return fmt.Errorf("sync option 'SyncWaveDelay=%s' is invalid: must be a non-negative duration, e.g. 10s", delay)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the retry strategy contains parsable and consistent backoff settings
+func (r *RetryStrategy) Validate() error {
+	if r.Backoff == nil {
+		return nil
+	}
+	var duration, maxDuration time.Duration
+	var err error
+	if r.Backoff.Duration != "" {
+		if duration, err = parseStringToDuration(r.Backoff.Duration); err != nil {
+			return fmt.Errorf("retry backoff duration is invalid: %w", err)
+		}
+		if duration < 0 {
+			return fmt.Errorf("retry backoff duration '%s' must not be negative", r.Backoff.Duration)
+		}
+	}
+	if r.Backoff.MaxDuration != "" {
+		if maxDuration, err = parseStringToDuration(r.Backoff.MaxDuration); err != nil {
+			return fmt.Errorf("retry backoff maxDuration is invalid: %w", err)
+		}
+		if maxDuration < 0 {
+			return fmt.Errorf("retry backoff maxDuration '%s' must not be negative", r.Backoff.MaxDuration)
+		}
+	}
+	if duration > 0 && maxDuration > 0 && duration > maxDuration {
+		return fmt.Errorf("retry backoff duration '%s' must not exceed maxDuration '%s'", r.Backoff.Duration, r.Backoff.MaxDuration)
+	}
+	if r.Backoff.Factor != nil && *r.Backoff.Factor < 1 {
+		return fmt.Errorf("retry backoff factor must be at least 1, got %d", *r.Backoff.Factor)
+	}
+	return nil
+}
+
 // RetryStrategy contains information about the strategy to apply when a sync failed
 type RetryStrategy struct {
 	// Limit is the maximum number of attempts for retrying a failed sync. If set to 0, no retries will be performed.
 	Limit int64 `json:"limit,omitempty" protobuf:"bytes,1,opt,name=limit"`
 	// Backoff controls how to backoff on subsequent retries of failed syncs
 	Backoff *Backoff `json:"backoff,omitempty" protobuf:"bytes,2,opt,name=backoff,casttype=Backoff"`
+	// Refresh indicates if the latest revision should be used on retry instead of the initial one
+	Refresh bool `json:"refresh,omitempty" protobuf:"bytes,3,opt,name=refresh"`
 }
 
 func parseStringToDuration(durationString string) (time.Duration, error) {
@@ -1228,6 +1435,12 @@ type ChartDetails struct {
 	Home string `json:"home,omitempty" protobuf:"bytes,2,opt,name=home"`
 	// List of maintainer details, name and email, e.g. ["John Doe <john_doe@my-company.com>"]
 	Maintainers []string `json:"maintainers,omitempty" protobuf:"bytes,3,opt,name=maintainers"`
+	// AppVersion is the version of the application packaged by the chart, e.g. "1.16.0"
+	AppVersion string `json:"appVersion,omitempty" protobuf:"bytes,4,opt,name=appVersion"`
+	// Digest is the digest of the chart package as published in the Helm repository index, e.g. "sha256:..."
+	Digest string `json:"digest,omitempty" protobuf:"bytes,5,opt,name=digest"`
+	// RepoURL is the URL of the Helm repository the chart is fetched from
+	RepoURL string `json:"repoURL,omitempty" protobuf:"bytes,6,opt,name=repoURL"`
 }
 
 // SyncOperationResult represent result of sync operation
@@ -1319,6 +1532,10 @@ type RevisionHistory struct {
 	Sources ApplicationSources `json:"sources,omitempty" protobuf:"bytes,8,opt,name=sources"`
 	// Revisions holds the revision of each source in sources field the sync was performed against
 	Revisions []string `json:"revisions,omitempty" protobuf:"bytes,9,opt,name=revisions"`
+	// Charts holds the metadata of the Helm charts deployed by the sync, for sources which are Helm repositories
+	Charts []ChartDetails `json:"charts,omitempty" protobuf:"bytes,10,rep,name=charts"`
+	// ManualRevision is true if the revision was chosen explicitly in a rollback rather than taken from the deployment history
+	ManualRevision bool `json:"manualRevision,omitempty" protobuf:"bytes,11,opt,name=manualRevision"`
 }
 
 // ApplicationWatchEvent contains information about application change.
@@ -1386,6 +1603,10 @@ const (
 	ApplicationConditionExcludedResourceWarning = "ExcludedResourceWarning"
 	// ApplicationConditionOrphanedResourceWarning indicates that application has orphaned resources
 	ApplicationConditionOrphanedResourceWarning = "OrphanedResourceWarning"
+	// ApplicationConditionOrphanedResourceError indicates that application has orphaned resources, and its project reports them as errors
+	ApplicationConditionOrphanedResourceError = "OrphanedResourceError"
+	// ApplicationConditionResourceNotPermittedWarning indicates that application has resources which aren't permitted by the resource allow and deny lists of its project
+	ApplicationConditionResourceNotPermittedWarning = "ResourceNotPermittedWarning"
 )
 
 // ApplicationCondition contains details about an application condition, which is usually an error or warning
@@ -1418,8 +1639,20 @@ type SyncStatus struct {
 	Revision string `json:"revision,omitempty" protobuf:"bytes,3,opt,name=revision"`
 	// Revisions contains information about the revisions of multiple sources the comparison has been performed to
 	Revisions []string `json:"revisions,omitempty" protobuf:"bytes,4,opt,name=revisions"`
+	// SignatureVerification is the result of the verification of the GnuPG signatures of the compared revisions, if the project of the application requires signed revisions
+	SignatureVerification SignatureVerificationResult `json:"signatureVerification,omitempty" protobuf:"bytes,5,opt,name=signatureVerification"`
 }
 
+// SignatureVerificationResult is the result of the verification of the GnuPG signatures of the revisions of an application
+type SignatureVerificationResult string
+
+const (
+	// SignatureVerificationVerified indicates that all the compared revisions are signed by a key allowed in the project
+	SignatureVerificationVerified SignatureVerificationResult = "Verified"
+	// SignatureVerificationFailed indicates that a compared revision isn't signed, or not by a key allowed in the project
+	SignatureVerificationFailed SignatureVerificationResult = "Failed"
+)
+
 // HealthStatus contains information about the currently observed health state of an application or resource
 type HealthStatus struct {
 	// Status holds the status code of the application or resource
@@ -1685,6 +1918,12 @@ type Cluster struct {
 	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,12,opt,name=labels"`
 	// Annotations for cluster secret metadata
 	Annotations map[string]string `json:"annotations,omitempty" protobuf:"bytes,13,opt,name=annotations"`
+	// RepoServer is the address of a repo server deployed close to the cluster, which generates the manifests of the applications deployed to the cluster
+	RepoServer string `json:"repoServer,omitempty" protobuf:"bytes,14,opt,name=repoServer"`
+	// ResourceInclusions overrides the resource.inclusions setting for the cluster. It holds the API groups and kinds which Argo CD watches on the cluster, in the same format as the setting
+	ResourceInclusions string `json:"resourceInclusions,omitempty" protobuf:"bytes,15,opt,name=resourceInclusions"`
+	// ResourceExclusions overrides the resource.exclusions setting for the cluster. It holds the API groups and kinds which Argo CD doesn't watch on the cluster, in the same format as the setting
+	ResourceExclusions string `json:"resourceExclusions,omitempty" protobuf:"bytes,16,opt,name=resourceExclusions"`
 }
 
 // Equals returns true if two cluster objects are considered to be equal
@@ -1737,6 +1976,9 @@ type ClusterInfo struct {
 	ApplicationsCount int64 `json:"applicationsCount" protobuf:"bytes,4,opt,name=applicationsCount"`
 	// APIVersions contains list of API versions supported by the cluster
 	APIVersions []string `json:"apiVersions,omitempty" protobuf:"bytes,5,opt,name=apiVersions"`
+	// Shard is the number of the application controller shard processing the cluster, if the clusters are distributed
+	// across several application controller replicas
+	Shard *int64 `json:"shard,omitempty" protobuf:"bytes,6,opt,name=shard"`
 }
 
 func (c *ClusterInfo) GetKubeVersion() string {
@@ -1811,6 +2053,9 @@ type ClusterConfig struct {
 
 	// ExecProviderConfig contains configuration for an exec provider
 	ExecProviderConfig *ExecProviderConfig `json:"execProviderConfig,omitempty" protobuf:"bytes,6,opt,name=execProviderConfig"`
+
+	// ProxyUrl is the URL of the HTTP proxy used to connect to the cluster, e.g. the agent proxy of the Argo CD API server
+	ProxyUrl string `json:"proxyUrl,omitempty" protobuf:"bytes,7,opt,name=proxyUrl"`
 }
 
 // TLSClientConfig contains settings to enable transport layer security
@@ -1830,6 +2075,9 @@ type TLSClientConfig struct {
 	// CAData holds PEM-encoded bytes (typically read from a root certificates bundle).
 	// CAData takes precedence over CAFile
 	CAData []byte `json:"caData,omitempty" protobuf:"bytes,5,opt,name=caData"`
+	// AdditionalCAData holds PEM-encoded certificate authorities which are trusted in addition to the ones of CAData,
+	// or to the system certificate authorities if CAData is empty
+	AdditionalCAData []byte `json:"additionalCAData,omitempty" protobuf:"bytes,6,opt,name=additionalCAData"`
 }
 
 // KnownTypeField contains mapping between CRD field and known Kubernetes type.
@@ -1929,6 +2177,9 @@ type ResourceActions struct {
 type ResourceActionDefinition struct {
 	Name      string `json:"name" protobuf:"bytes,1,opt,name=name"`
 	ActionLua string `json:"action.lua" yaml:"action.lua" protobuf:"bytes,2,opt,name=actionLua"`
+	// Params are the parameters the action accepts. They are validated before the action is run and passed to the
+	// action script in the actionParams table. Parameters without a default value are required.
+	Params []ResourceActionParam `json:"params,omitempty" protobuf:"bytes,3,rep,name=params"`
 }
 
 // TODO: describe this type
@@ -2064,6 +2315,8 @@ type OrphanedResourcesMonitorSettings struct {
 	Warn *bool `json:"warn,omitempty" protobuf:"bytes,1,name=warn"`
 	// Ignore contains a list of resources that are to be excluded from orphaned resources monitoring
 	Ignore []OrphanedResourceKey `json:"ignore,omitempty" protobuf:"bytes,2,opt,name=ignore"`
+	// Error indicates if an error condition should be created for apps which have orphaned resources, instead of a warning condition
+	Error *bool `json:"error,omitempty" protobuf:"bytes,3,name=error"`
 }
 
 // OrphanedResourceKey is a reference to a resource to be ignored from
@@ -2078,6 +2331,11 @@ func (s *OrphanedResourcesMonitorSettings) IsWarn() bool {
 	return s.Warn != nil && *s.Warn
 }
 
+// IsError returns true if orphaned resources are reported as errors instead of warnings
+func (s *OrphanedResourcesMonitorSettings) IsError() bool {
+	return s.Error != nil && *s.Error
+}
+
 // SignatureKey is the specification of a key required to verify commit signatures with
 type SignatureKey struct {
 	// The ID of the key in hexadecimal notation
@@ -2112,6 +2370,81 @@ type AppProjectSpec struct {
 	SourceNamespaces []string `json:"sourceNamespaces,omitempty" protobuf:"bytes,12,opt,name=sourceNamespaces"`
 	// PermitOnlyProjectScopedClusters determines whether destinations can only reference clusters which are project-scoped
 	PermitOnlyProjectScopedClusters bool `json:"permitOnlyProjectScopedClusters,omitempty" protobuf:"bytes,13,opt,name=permitOnlyProjectScopedClusters"`
+	// DisableLogs prevents members of this project from viewing pod logs of the project's applications
+	DisableLogs bool `json:"disableLogs,omitempty" protobuf:"bytes,14,opt,name=disableLogs"`
+	// DisableExec prevents members of this project from executing commands in pods of the project's applications
+	DisableExec bool `json:"disableExec,omitempty" protobuf:"bytes,15,opt,name=disableExec"`
+	// RedactSecretData removes the contents of Secrets from diffs and manifests of the project's applications
+	RedactSecretData bool `json:"redactSecretData,omitempty" protobuf:"bytes,16,opt,name=redactSecretData"`
+	// ManifestValidation controls the validation of the manifests of the project's applications before they are synced
+	ManifestValidation *ManifestValidation `json:"manifestValidation,omitempty" protobuf:"bytes,17,opt,name=manifestValidation"`
+	// FeatureFlags enables or disables feature flags for the project's applications, overriding the state of the flags
+	// configured for the Argo CD instance
+	FeatureFlags []FeatureFlag `json:"featureFlags,omitempty" protobuf:"bytes,18,opt,name=featureFlags"`
+	// Quotas limits the number of applications of the project and the number of resources managed by each of them
+	Quotas *ProjectQuotas `json:"quotas,omitempty" protobuf:"bytes,19,opt,name=quotas"`
+	// DefaultSyncPolicy is the sync policy of the project's applications which don't set a sync policy
+	DefaultSyncPolicy *SyncPolicy `json:"defaultSyncPolicy,omitempty" protobuf:"bytes,20,opt,name=defaultSyncPolicy"`
+	// DestinationServiceAccounts maps destinations to the service accounts which are impersonated when the project's
+	// applications are synced to them
+	DestinationServiceAccounts []ApplicationDestinationServiceAccount `json:"destinationServiceAccounts,omitempty" protobuf:"bytes,21,opt,name=destinationServiceAccounts"`
+}
+
+// ApplicationDestinationServiceAccount is the service account which is impersonated when applications are synced to a
+// destination
+type ApplicationDestinationServiceAccount struct {
+	// Server is the URL of the destination cluster, which may be a glob pattern
+	Server string `json:"server" protobuf:"bytes,1,opt,name=server"`
+	// Namespace is the destination namespace, which may be a glob pattern
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
+	// DefaultServiceAccount is the name of the service account to impersonate, optionally prefixed with its namespace
+	// as 'namespace:name'. The service account is looked up in the destination namespace of the application if no
+	// namespace is given.
+	DefaultServiceAccount string `json:"defaultServiceAccount" protobuf:"bytes,3,opt,name=defaultServiceAccount"`
+}
+
+// FeatureFlag is the state of a flag guarding a large behavioral change which is rolled out gradually
+type FeatureFlag struct {
+	// Name is the name of the feature flag
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Enabled is whether the feature guarded by the flag is enabled
+	Enabled bool `json:"enabled" protobuf:"bytes,2,opt,name=enabled"`
+}
+
+// ManifestValidation controls the validation of manifests against OpenAPI schemas before they are synced
+type ManifestValidation struct {
+	// Strict validates the manifests against OpenAPI schemas before syncing and fails the sync if any of them is invalid
+	Strict bool `json:"strict,omitempty" protobuf:"bytes,1,opt,name=strict"`
+	// Schemas is the Kubernetes version of the bundled OpenAPI schemas to validate against, e.g. v1.27. The OpenAPI
+	// schemas of the destination cluster are used if empty.
+	Schemas string `json:"schemas,omitempty" protobuf:"bytes,2,opt,name=schemas"`
+}
+
+// BundledOpenAPISchemasVersions are the Kubernetes versions whose OpenAPI schemas are bundled in the image. They must
+// match the openapi_schemas_versions installed by hack/installers/install-openapi-schemas.sh.
+var BundledOpenAPISchemasVersions = []string{"v1.24", "v1.25", "v1.26", "v1.27", "v1.28"}
+
+// validateSchemasVersion checks that the given Kubernetes version of bundled OpenAPI schemas is empty, which selects
+// the schemas of the destination cluster, or one of the bundled versions
+func validateSchemasVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	for _, v := range BundledOpenAPISchemasVersions {
+		if version == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not a Kubernetes version of the bundled OpenAPI schemas, must be one of %s", version, strings.Join(BundledOpenAPISchemasVersions, ", "))
+}
+
+// ProjectQuotas limits the capacity of the Argo CD instance which can be used by the applications of a project. Zero
+// means that there is no limit.
+type ProjectQuotas struct {
+	// MaxApplications is the maximum number of applications in the project
+	MaxApplications int64 `json:"maxApplications,omitempty" protobuf:"bytes,1,opt,name=maxApplications"`
+	// MaxResourcesPerApplication is the maximum number of resources managed by each application of the project
+	MaxResourcesPerApplication int64 `json:"maxResourcesPerApplication,omitempty" protobuf:"bytes,2,opt,name=maxResourcesPerApplication"`
 }
 
 // SyncWindows is a collection of sync windows in this project
@@ -2148,22 +2481,10 @@ func (s *SyncWindows) Active() *SyncWindows {
 }
 
 func (s *SyncWindows) active(currentTime time.Time) *SyncWindows {
-
-	// If SyncWindows.Active() is called outside of a UTC locale, it should be
-	// first converted to UTC before we scan through the SyncWindows.
-	currentTime = currentTime.In(time.UTC)
-
 	if s.HasWindows() {
 		var active SyncWindows
-		specParser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 		for _, w := range *s {
-			schedule, _ := specParser.Parse(w.Schedule)
-			duration, _ := time.ParseDuration(w.Duration)
-
-			// Offset the nextWindow time to consider the timeZone of the sync window
-			timeZoneOffsetDuration := w.scheduleOffsetByTimeZone()
-			nextWindow := schedule.Next(currentTime.Add(timeZoneOffsetDuration - duration))
-			if nextWindow.Before(currentTime.Add(timeZoneOffsetDuration)) {
+			if w.active(currentTime) {
 				active = append(active, w)
 			}
 		}
@@ -2182,23 +2503,14 @@ func (s *SyncWindows) InactiveAllows() *SyncWindows {
 }
 
 func (s *SyncWindows) inactiveAllows(currentTime time.Time) *SyncWindows {
-
-	// If SyncWindows.InactiveAllows() is called outside of a UTC locale, it should be
-	// first converted to UTC before we scan through the SyncWindows.
-	currentTime = currentTime.In(time.UTC)
-
 	if s.HasWindows() {
 		var inactive SyncWindows
 		specParser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 		for _, w := range *s {
 			if w.Kind == "allow" {
-				schedule, sErr := specParser.Parse(w.Schedule)
-				duration, dErr := time.ParseDuration(w.Duration)
-				// Offset the nextWindow time to consider the timeZone of the sync window
-				timeZoneOffsetDuration := w.scheduleOffsetByTimeZone()
-				nextWindow := schedule.Next(currentTime.Add(timeZoneOffsetDuration - duration))
-
-				if !nextWindow.Before(currentTime.Add(timeZoneOffsetDuration)) && sErr == nil && dErr == nil {
+				_, sErr := specParser.Parse(w.Schedule)
+				_, dErr := time.ParseDuration(w.Duration)
+				if sErr == nil && dErr == nil && !w.active(currentTime) {
 					inactive = append(inactive, w)
 				}
 			}
@@ -2210,14 +2522,14 @@ func (s *SyncWindows) inactiveAllows(currentTime time.Time) *SyncWindows {
 	return nil
 }
 
-func (w *SyncWindow) scheduleOffsetByTimeZone() time.Duration {
+// location returns the location of the time zone of the sync window, which defaults to UTC
+func (w *SyncWindow) location() *time.Location {
 	loc, err := time.LoadLocation(w.TimeZone)
 	if err != nil {
 		log.Warnf("Invalid time zone %s specified. Using UTC as default time zone", w.TimeZone)
-		loc = time.Now().UTC().Location()
+		return time.UTC
 	}
-	_, tzOffset := time.Now().In(loc).Zone()
-	return time.Duration(tzOffset) * time.Second
+	return loc
 }
 
 // AddWindow adds a sync window with the given parameters to the AppProject
@@ -2404,20 +2716,16 @@ func (w SyncWindow) Active() bool {
 }
 
 func (w SyncWindow) active(currentTime time.Time) bool {
-
-	// If SyncWindow.Active() is called outside of a UTC locale, it should be
-	// first converted to UTC before search
-	currentTime = currentTime.UTC()
-
 	specParser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 	schedule, _ := specParser.Parse(w.Schedule)
 	duration, _ := time.ParseDuration(w.Duration)
 
-	// Offset the nextWindow time to consider the timeZone of the sync window
-	timeZoneOffsetDuration := w.scheduleOffsetByTimeZone()
-	nextWindow := schedule.Next(currentTime.Add(timeZoneOffsetDuration - duration))
+	// The schedule is evaluated in the time zone of the sync window rather than with a fixed offset, so that
+	// the window follows the daylight saving time changes of the time zone
+	currentTime = currentTime.In(w.location())
+	nextWindow := schedule.Next(currentTime.Add(-duration))
 
-	return nextWindow.Before(currentTime.Add(timeZoneOffsetDuration))
+	return nextWindow.Before(currentTime)
 }
 
 // Update updates a sync window's settings with the given parameter
@@ -2471,10 +2779,13 @@ func (w *SyncWindow) Validate() error {
 	if err != nil {
 		return fmt.Errorf("cannot parse schedule '%s': %s", w.Schedule, err)
 	}
-	_, err = time.ParseDuration(w.Duration)
+	duration, err := time.ParseDuration(w.Duration)
 	if err != nil {
 		return fmt.Errorf("cannot parse duration '%s': %s", w.Duration, err)
 	}
+	if duration <= 0 {
+		return fmt.Errorf("duration '%s' must be positive", w.Duration)
+	}
 	return nil
 }
 
@@ -2501,6 +2812,8 @@ type ProjectRole struct {
 	JWTTokens []JWTToken `json:"jwtTokens,omitempty" protobuf:"bytes,4,rep,name=jwtTokens"`
 	// Groups are a list of OIDC group claims bound to this role
 	Groups []string `json:"groups,omitempty" protobuf:"bytes,5,rep,name=groups"`
+	// DefaultTokenExpiration is the duration after which the tokens of this role expire if no expiration is requested when they are created, e.g. "24h" or "7d"
+	DefaultTokenExpiration string `json:"defaultTokenExpiration,omitempty" protobuf:"bytes,6,opt,name=defaultTokenExpiration"`
 }
 
 // JWTToken holds the issuedAt and expiresAt values of a token
@@ -2565,6 +2878,23 @@ func (app *Application) IsRefreshRequested() (RefreshType, bool) {
 	return refreshType, true
 }
 
+// GetManifestGenerateTimeout returns the manifest generation timeout set by the manifest-generate-timeout annotation, or
+// zero if the annotation is not set
+func (app *Application) GetManifestGenerateTimeout() (time.Duration, error) {
+	val, ok := app.GetAnnotations()[AnnotationKeyManifestGenerateTimeout]
+	if !ok || val == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %w", AnnotationKeyManifestGenerateTimeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation: timeout must be positive", AnnotationKeyManifestGenerateTimeout)
+	}
+	return timeout, nil
+}
+
 // SetCascadedDeletion will enable cascaded deletion by setting the propagation policy finalizer
 func (app *Application) SetCascadedDeletion(finalizer string) {
 	setFinalizer(&app.ObjectMeta, finalizer, true)
@@ -2803,19 +3133,35 @@ func setFinalizer(meta *metav1.ObjectMeta, name string, exist bool) {
 
 // SetK8SConfigDefaults sets Kubernetes REST config default settings
 func SetK8SConfigDefaults(config *rest.Config) error {
+	return setK8SConfigDefaults(config, nil)
+}
+
+// setK8SConfigDefaults sets the defaults of SetK8SConfigDefaults, and authenticates using the given exec credentials
+// unless they are nil
+func setK8SConfigDefaults(config *rest.Config, credentials *clusterauth.ExecCredentialSource) error {
 	config.QPS = K8sClientConfigQPS
 	config.Burst = K8sClientConfigBurst
 	tlsConfig, err := rest.TLSConfigFor(config)
 	if err != nil {
 		return err
 	}
+	if credentials != nil {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.GetClientCertificate = credentials.GetClientCertificate
+	}
 
 	dial := (&net.Dialer{
 		Timeout:   K8sTCPTimeout,
 		KeepAlive: K8sTCPKeepAlive,
 	}).DialContext
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != nil {
+		proxy = config.Proxy
+	}
 	transport := utilnet.SetTransportDefaults(&http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
+		Proxy:               proxy,
 		TLSHandshakeTimeout: K8sTLSHandshakeTimeout,
 		TLSClientConfig:     tlsConfig,
 		MaxIdleConns:        K8sMaxIdleConnections,
@@ -2829,6 +3175,9 @@ func SetK8SConfigDefaults(config *rest.Config) error {
 	if err != nil {
 		return err
 	}
+	if credentials != nil {
+		tr = credentials.WrapTransport(tr)
+	}
 
 	// set default tls config and remove auth/exec provides since we use it in a custom transport
 	config.TLSClientConfig = rest.TLSClientConfig{}
@@ -2842,8 +3191,65 @@ func SetK8SConfigDefaults(config *rest.Config) error {
 	return nil
 }
 
+// APIServerURL returns the URL of the API server of the cluster. The server URL of a cluster can end with a fragment
+// (e.g. https://mycluster.example.com#team-a), which allows registering the same API server several times, e.g.
+// restricted to different namespaces with different credentials. The fragment is removed to connect to the API server.
+func (c *Cluster) APIServerURL() string {
+	server, _, _ := strings.Cut(c.Server, "#")
+	return server
+}
+
+// systemCertificateFiles are the locations of the bundle of system certificate authorities on the common distributions
+var systemCertificateFiles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/tls/cacert.pem",
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+	"/etc/ssl/cert.pem",
+}
+
+// systemCertificates returns the PEM encoded bundle of system certificate authorities, which is looked up like the Go
+// runtime does
+func systemCertificates() ([]byte, error) {
+	files := systemCertificateFiles
+	if file := os.Getenv("SSL_CERT_FILE"); file != "" {
+		files = []string{file}
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("no bundle of system certificate authorities found in %s", strings.Join(files, ", "))
+}
+
+// trustedCAData returns the PEM encoded certificate authorities which are trusted to connect to the cluster. The
+// additional certificate authorities are appended to the ones of CAData, or to the current system ones if CAData is
+// empty, so that they are never stored in the cluster secret.
+func (c *TLSClientConfig) trustedCAData() ([]byte, error) {
+	if len(c.AdditionalCAData) == 0 {
+		return c.CAData, nil
+	}
+	caData := c.CAData
+	if len(caData) == 0 {
+		var err error
+		caData, err = systemCertificates()
+		if err != nil {
+			return nil, err
+		}
+	}
+	trusted := make([]byte, 0, len(caData)+len(c.AdditionalCAData)+1)
+	trusted = append(trusted, caData...)
+	if !bytes.HasSuffix(trusted, []byte("\n")) {
+		trusted = append(trusted, '\n')
+	}
+	return append(trusted, c.AdditionalCAData...), nil
+}
+
 // RawRestConfig returns a go-client REST config from cluster that might be serialized into the file using kube.WriteKubeConfig method.
-func (c *Cluster) RawRestConfig() *rest.Config {
+func (c *Cluster) RawRestConfig() (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 	if c.Server == KubernetesInternalAPIServerAddr && env.ParseBoolFromEnv(EnvVarFakeInClusterConfig, false) {
@@ -2864,12 +3270,16 @@ func (c *Cluster) RawRestConfig() *rest.Config {
 			config.BearerTokenFile = ""
 		}
 	} else {
+		caData, err := c.Config.TLSClientConfig.trustedCAData()
+		if err != nil {
+			return nil, fmt.Errorf("Unable to create K8s REST config: %w", err)
+		}
 		tlsClientConfig := rest.TLSClientConfig{
 			Insecure:   c.Config.TLSClientConfig.Insecure,
 			ServerName: c.Config.TLSClientConfig.ServerName,
 			CertData:   c.Config.TLSClientConfig.CertData,
 			KeyData:    c.Config.TLSClientConfig.KeyData,
-			CAData:     c.Config.TLSClientConfig.CAData,
+			CAData:     caData,
 		}
 		if c.Config.AWSAuthConfig != nil {
 			args := []string{"aws", "--cluster-name", c.Config.AWSAuthConfig.ClusterName}
@@ -2877,7 +3287,7 @@ func (c *Cluster) RawRestConfig() *rest.Config {
 				args = append(args, "--role-arn", c.Config.AWSAuthConfig.RoleARN)
 			}
 			config = &rest.Config{
-				Host:            c.Server,
+				Host:            c.APIServerURL(),
 				TLSClientConfig: tlsClientConfig,
 				ExecProvider: &api.ExecConfig{
 					APIVersion:      "client.authentication.k8s.io/v1beta1",
@@ -2897,7 +3307,7 @@ func (c *Cluster) RawRestConfig() *rest.Config {
 				}
 			}
 			config = &rest.Config{
-				Host:            c.Server,
+				Host:            c.APIServerURL(),
 				TLSClientConfig: tlsClientConfig,
 				ExecProvider: &api.ExecConfig{
 					APIVersion:      c.Config.ExecProviderConfig.APIVersion,
@@ -2910,7 +3320,7 @@ func (c *Cluster) RawRestConfig() *rest.Config {
 			}
 		} else {
 			config = &rest.Config{
-				Host:            c.Server,
+				Host:            c.APIServerURL(),
 				Username:        c.Config.Username,
 				Password:        c.Config.Password,
 				BearerToken:     c.Config.BearerToken,
@@ -2919,22 +3329,38 @@ func (c *Cluster) RawRestConfig() *rest.Config {
 		}
 	}
 	if err != nil {
-		panic(fmt.Sprintf("Unable to create K8s REST config: %v", err))
+		return nil, fmt.Errorf("Unable to create K8s REST config: %w", err)
+	}
+	if c.Config.ProxyUrl != "" {
+		proxyURL, err := url.Parse(c.Config.ProxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse proxy URL of the cluster: %w", err)
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
 	}
 	config.Timeout = K8sServerSideTimeout
 	config.QPS = K8sClientConfigQPS
 	config.Burst = K8sClientConfigBurst
-	return config
+	return config, nil
 }
 
 // RESTConfig returns a go-client REST config from cluster with tuned throttling and HTTP client settings.
-func (c *Cluster) RESTConfig() *rest.Config {
-	config := c.RawRestConfig()
-	err := SetK8SConfigDefaults(config)
+func (c *Cluster) RESTConfig() (*rest.Config, error) {
+	config, err := c.RawRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	// if enabled, the credentials of exec plugins are provided by a shared source, which refreshes them before they expire
+	var credentials *clusterauth.ExecCredentialSource
+	if config.ExecProvider != nil && ClusterCredentialsProactiveRefresh {
+		credentials = clusterauth.GetExecCredentialSource(config.Host, config.ExecProvider)
+		config.ExecProvider = nil
+	}
+	err = setK8SConfigDefaults(config, credentials)
 	if err != nil {
-		panic(fmt.Sprintf("Unable to apply K8s REST config defaults: %v", err))
+		return nil, fmt.Errorf("Unable to apply K8s REST config defaults: %w", err)
 	}
-	return config
+	return config, nil
 }
 
 // UnmarshalToUnstructured unmarshals a resource representation in JSON to unstructured data
@@ -2968,10 +3394,10 @@ func (d *ApplicationDestination) SetInferredServer(server string) {
 }
 
 // An ApplicationDestination has an 'inferred server' if the ApplicationDestination
-// contains a Name, but not a Server URL. In this case it is necessary to retrieve
-// the Server URL by looking up the cluster name.
+// contains a Name or a ClusterSelector, but not a Server URL. In this case it is
+// necessary to retrieve the Server URL by looking up the cluster name or labels.
 //
-// As of this writing, looking up the cluster name, and setting the URL, is
+// As of this writing, looking up the cluster, and setting the URL, is
 // performed by 'utils.ValidateDestination(...)', which then calls SetInferredServer.
 func (d *ApplicationDestination) IsServerInferred() bool {
 	return d.isServerInferred
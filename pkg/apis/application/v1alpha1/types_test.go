@@ -483,6 +483,31 @@ func TestAppProject_IsGroupKindPermitted(t *testing.T) {
 	assert.True(t, proj6.IsGroupKindPermitted(schema.GroupKind{Group: "apps", Kind: "Action"}, true))
 }
 
+func TestAppProject_ValidateGroupKind(t *testing.T) {
+	proj := AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-proj"},
+		Spec: AppProjectSpec{
+			ClusterResourceWhitelist:   []metav1.GroupKind{{Group: "*", Kind: "*"}},
+			ClusterResourceBlacklist:   []metav1.GroupKind{{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole*"}},
+			NamespaceResourceWhitelist: []metav1.GroupKind{{Group: "rbac.authorization.k8s.io", Kind: "*"}},
+		},
+	}
+	assert.NoError(t, proj.ValidateGroupKind(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "Role"}, true))
+	assert.NoError(t, proj.ValidateGroupKind(schema.GroupKind{Group: "", Kind: "Namespace"}, false))
+	assert.EqualError(t, proj.ValidateGroupKind(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}, false),
+		"cluster-scoped resource rbac.authorization.k8s.io:ClusterRole is not permitted in project 'my-proj': it is in the cluster resource blacklist")
+	assert.EqualError(t, proj.ValidateGroupKind(schema.GroupKind{Group: "apps", Kind: "Deployment"}, true),
+		"namespaced resource apps:Deployment is not permitted in project 'my-proj': it is not in the namespace resource whitelist")
+
+	proj.Spec.ClusterResourceWhitelist = nil
+	proj.Spec.NamespaceResourceWhitelist = nil
+	proj.Spec.NamespaceResourceBlacklist = []metav1.GroupKind{{Group: "", Kind: "Secret"}}
+	assert.EqualError(t, proj.ValidateGroupKind(schema.GroupKind{Group: "", Kind: "Namespace"}, false),
+		"cluster-scoped resource :Namespace is not permitted in project 'my-proj': it is not in the cluster resource whitelist")
+	assert.EqualError(t, proj.ValidateGroupKind(schema.GroupKind{Group: "", Kind: "Secret"}, true),
+		"namespaced resource :Secret is not permitted in project 'my-proj': it is in the namespace resource blacklist")
+}
+
 func TestAppProject_GetRoleByName(t *testing.T) {
 	t.Run("NotExists", func(t *testing.T) {
 		p := &AppProject{}
@@ -753,6 +778,151 @@ func TestAppProject_ValidateSyncWindowList(t *testing.T) {
 	})
 }
 
+func TestAppProject_ValidateManifestValidation(t *testing.T) {
+	p := newTestProject()
+	p.Spec.ManifestValidation = &ManifestValidation{Strict: true}
+	assert.NoError(t, p.ValidateProject())
+	p.Spec.ManifestValidation.Schemas = "v1.27"
+	assert.NoError(t, p.ValidateProject())
+	// the schemas are bundled for minor versions only
+	p.Spec.ManifestValidation.Schemas = "v1.27.3"
+	assert.ErrorContains(t, p.ValidateProject(), "manifest validation schemas are invalid")
+	p.Spec.ManifestValidation.Schemas = "v1.12"
+	assert.ErrorContains(t, p.ValidateProject(), "manifest validation schemas are invalid")
+	p.Spec.ManifestValidation.Schemas = "1.27"
+	assert.ErrorContains(t, p.ValidateProject(), "manifest validation schemas are invalid")
+	p.Spec.ManifestValidation.Schemas = "../../etc/passwd"
+	assert.ErrorContains(t, p.ValidateProject(), "manifest validation schemas are invalid")
+}
+
+func TestBundledOpenAPISchemasVersions(t *testing.T) {
+	data, err := os.ReadFile("../../../../hack/tool-versions.sh")
+	require.NoError(t, err)
+	var installed []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "openapi_schemas_versions="); ok {
+			for _, version := range strings.Fields(strings.Trim(value, `"`)) {
+				parts := strings.Split(version, ".")
+				require.Len(t, parts, 3)
+				installed = append(installed, "v"+parts[0]+"."+parts[1])
+			}
+		}
+	}
+	assert.Equal(t, installed, BundledOpenAPISchemasVersions)
+}
+
+func TestAppProject_ValidateFeatureFlags(t *testing.T) {
+	p := newTestProject()
+	p.Spec.FeatureFlags = []FeatureFlag{{Name: "serverSideApplySync", Enabled: true}}
+	assert.NoError(t, p.ValidateProject())
+	enabled, ok := p.GetFeatureFlag("serverSideApplySync")
+	assert.True(t, ok)
+	assert.True(t, enabled)
+	_, ok = p.GetFeatureFlag("unknown")
+	assert.False(t, ok)
+
+	p.Spec.FeatureFlags = append(p.Spec.FeatureFlags, FeatureFlag{Name: "serverSideApplySync"})
+	assert.ErrorContains(t, p.ValidateProject(), "feature flag 'serverSideApplySync' already added")
+	p.Spec.FeatureFlags = []FeatureFlag{{Enabled: true}}
+	assert.ErrorContains(t, p.ValidateProject(), "feature flag name cannot be empty")
+}
+
+func TestAppProject_ValidateQuotas(t *testing.T) {
+	p := newTestProject()
+	p.Spec.Quotas = &ProjectQuotas{MaxApplications: 2, MaxResourcesPerApplication: 10}
+	assert.NoError(t, p.ValidateProject())
+
+	assert.NoError(t, p.ValidateApplicationsQuota(1))
+	assert.EqualError(t, p.ValidateApplicationsQuota(2), "project 'my-proj' has reached its quota of 2 applications")
+	assert.NoError(t, p.ValidateResourcesQuota(10))
+	assert.EqualError(t, p.ValidateResourcesQuota(11), "application manages 11 resources, which exceeds the quota of 10 resources per application of project 'my-proj'")
+
+	p.Spec.Quotas = &ProjectQuotas{}
+	assert.NoError(t, p.ValidateApplicationsQuota(100))
+	assert.NoError(t, p.ValidateResourcesQuota(100))
+
+	p.Spec.Quotas = &ProjectQuotas{MaxApplications: -1}
+	assert.ErrorContains(t, p.ValidateProject(), "maximum number of applications cannot be negative")
+	p.Spec.Quotas = &ProjectQuotas{MaxResourcesPerApplication: -1}
+	assert.ErrorContains(t, p.ValidateProject(), "maximum number of resources per application cannot be negative")
+}
+
+func TestAppProject_ValidateRolePolicies(t *testing.T) {
+	p := newTestProject()
+	p.Spec.Roles[0].Policies = []string{
+		"p, proj:my-proj:my-role, applications, get, my-proj/*, allow",
+		"p, proj:my-proj:my-role, applications",
+		"p, proj:other-proj:my-role, applications, get, my-proj/*, allow",
+	}
+	errs := p.ValidateRolePolicies()
+	assert.Len(t, errs, 2)
+	assert.Empty(t, newTestProject().ValidateRolePolicies())
+}
+
+func TestAppProject_GetSyncPolicy(t *testing.T) {
+	p := newTestProject()
+	p.Spec.DefaultSyncPolicy = &SyncPolicy{Automated: &SyncPolicyAutomated{SelfHeal: true}}
+	assert.NoError(t, p.ValidateProject())
+
+	app := newTestApp()
+	assert.Equal(t, p.Spec.DefaultSyncPolicy, p.GetSyncPolicy(app))
+	app.Spec.SyncPolicy = &SyncPolicy{}
+	assert.Equal(t, app.Spec.SyncPolicy, p.GetSyncPolicy(app))
+	var nilProject *AppProject
+	assert.Equal(t, app.Spec.SyncPolicy, nilProject.GetSyncPolicy(app))
+
+	p.Spec.DefaultSyncPolicy.SyncOptions = SyncOptions{"Replace"}
+	assert.ErrorContains(t, p.ValidateProject(), "default sync policy is invalid")
+}
+
+func TestAppProject_ValidateDestinationServiceAccounts(t *testing.T) {
+	p := newTestProject()
+	for _, sa := range []string{"deployer", "tenant:deployer"} {
+		p.Spec.DestinationServiceAccounts = []ApplicationDestinationServiceAccount{{Server: "*", Namespace: "*", DefaultServiceAccount: sa}}
+		assert.NoError(t, p.ValidateProject())
+	}
+	for _, sa := range []string{"", ":deployer", "tenant:", "a:b:c", "deploy er", "*"} {
+		p.Spec.DestinationServiceAccounts = []ApplicationDestinationServiceAccount{{Server: "*", Namespace: "*", DefaultServiceAccount: sa}}
+		assert.ErrorContains(t, p.ValidateProject(), "is invalid", sa)
+	}
+	p.Spec.DestinationServiceAccounts = []ApplicationDestinationServiceAccount{{Namespace: "*", DefaultServiceAccount: "deployer"}}
+	assert.ErrorContains(t, p.ValidateProject(), "destination service account server cannot be empty")
+
+	p.Spec.DestinationServiceAccounts = []ApplicationDestinationServiceAccount{
+		{Server: "https://kubernetes.default.svc", Namespace: "guestbook", DefaultServiceAccount: "guestbook-deployer"},
+		{Server: "https://kubernetes.default.svc", Namespace: "*", DefaultServiceAccount: "argocd:deployer"},
+	}
+	sa, ok := p.GetDestinationServiceAccount("https://kubernetes.default.svc", "guestbook")
+	assert.True(t, ok)
+	namespace, name := sa.GetServiceAccount("guestbook")
+	assert.Equal(t, "guestbook", namespace)
+	assert.Equal(t, "guestbook-deployer", name)
+	sa, ok = p.GetDestinationServiceAccount("https://kubernetes.default.svc", "other")
+	assert.True(t, ok)
+	namespace, name = sa.GetServiceAccount("other")
+	assert.Equal(t, "argocd", namespace)
+	assert.Equal(t, "deployer", name)
+	_, ok = p.GetDestinationServiceAccount("https://other-cluster", "guestbook")
+	assert.False(t, ok)
+}
+
+func TestAppProject_ValidateDefaultTokenExpiration(t *testing.T) {
+	p := newTestProject()
+	for _, expiration := range []string{"", "12h", "7d"} {
+		p.Spec.Roles[0].DefaultTokenExpiration = expiration
+		assert.NoError(t, p.ValidateProject())
+	}
+	for _, expiration := range []string{"forever", "-1h"} {
+		p.Spec.Roles[0].DefaultTokenExpiration = expiration
+		assert.Error(t, p.ValidateProject())
+	}
+
+	p.Spec.Roles[0].DefaultTokenExpiration = "7d"
+	duration, err := p.Spec.Roles[0].GetDefaultTokenExpiration()
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, duration)
+}
+
 // TestInvalidPolicyRules checks various errors in policy rules
 func TestAppProject_InvalidPolicyRules(t *testing.T) {
 	p := newTestProject()
@@ -896,6 +1066,51 @@ func TestAppDestinationEquality_InferredServerURL(t *testing.T) {
 	assert.True(t, right.Equals(left))
 }
 
+func TestCluster_APIServerURL(t *testing.T) {
+	cluster := &Cluster{Server: "https://mycluster.example.com#team-a", Config: ClusterConfig{BearerToken: "team-a-token"}}
+	assert.Equal(t, "https://mycluster.example.com", cluster.APIServerURL())
+	config, err := cluster.RawRestConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://mycluster.example.com", config.Host)
+
+	cluster = &Cluster{Server: "https://mycluster.example.com"}
+	assert.Equal(t, "https://mycluster.example.com", cluster.APIServerURL())
+}
+
+func TestCluster_RawRestConfig_AdditionalCAData(t *testing.T) {
+	t.Run("AppendedToCAData", func(t *testing.T) {
+		cluster := &Cluster{Server: "https://mycluster.example.com", Config: ClusterConfig{TLSClientConfig: TLSClientConfig{
+			CAData:           []byte("cluster-ca-data"),
+			AdditionalCAData: []byte("additional-ca-data"),
+		}}}
+		config, err := cluster.RawRestConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "cluster-ca-data\nadditional-ca-data", string(config.CAData))
+	})
+
+	t.Run("AppendedToSystemCAs", func(t *testing.T) {
+		systemCAFile := path.Join(t.TempDir(), "ca-certificates.crt")
+		require.NoError(t, os.WriteFile(systemCAFile, []byte("system-ca-data\n"), 0600))
+		t.Setenv("SSL_CERT_FILE", systemCAFile)
+		cluster := &Cluster{Server: "https://mycluster.example.com", Config: ClusterConfig{TLSClientConfig: TLSClientConfig{
+			AdditionalCAData: []byte("additional-ca-data"),
+		}}}
+		config, err := cluster.RawRestConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "system-ca-data\nadditional-ca-data", string(config.CAData))
+		assert.Empty(t, cluster.Config.CAData)
+	})
+
+	t.Run("NoSystemCAs", func(t *testing.T) {
+		t.Setenv("SSL_CERT_FILE", path.Join(t.TempDir(), "missing.crt"))
+		cluster := &Cluster{Server: "https://mycluster.example.com", Config: ClusterConfig{TLSClientConfig: TLSClientConfig{
+			AdditionalCAData: []byte("additional-ca-data"),
+		}}}
+		_, err := cluster.RawRestConfig()
+		assert.ErrorContains(t, err, "no bundle of system certificate authorities found")
+	})
+}
+
 func TestAppProjectSpec_DestinationClusters(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1052,6 +1267,19 @@ func TestRepository_IsLFSEnabled(t *testing.T) {
 	}
 }
 
+func TestRepository_IsSubmoduleEnabled(t *testing.T) {
+	enabled, disabled := true, false
+	var repo *Repository
+	assert.True(t, repo.IsSubmoduleEnabled(true))
+	repo = &Repository{}
+	assert.True(t, repo.IsSubmoduleEnabled(true))
+	assert.False(t, repo.IsSubmoduleEnabled(false))
+	repo.EnableSubmodules = &enabled
+	assert.True(t, repo.IsSubmoduleEnabled(false))
+	repo.EnableSubmodules = &disabled
+	assert.False(t, repo.IsSubmoduleEnabled(true))
+}
+
 func TestRepository_CopyCredentialsFromRepo(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -1774,6 +2002,24 @@ func TestSyncWindows_Active(t *testing.T) {
 
 }
 
+func TestSyncWindow_Active_DaylightSavingTime(t *testing.T) {
+	window := SyncWindow{Kind: "allow", Schedule: "0 9 * * *", Duration: "1h", TimeZone: "America/New_York"}
+
+	// 9:30 AM in New York is 14:30 UTC in winter (EST) and 13:30 UTC in summer (EDT)
+	assert.True(t, window.active(time.Date(2023, time.January, 16, 14, 30, 0, 0, time.UTC)))
+	assert.False(t, window.active(time.Date(2023, time.January, 16, 13, 30, 0, 0, time.UTC)))
+	assert.True(t, window.active(time.Date(2023, time.July, 17, 13, 30, 0, 0, time.UTC)))
+	assert.False(t, window.active(time.Date(2023, time.July, 17, 14, 30, 0, 0, time.UTC)))
+
+	// the first window after the change to daylight saving time on March 12, 2023
+	assert.True(t, window.active(time.Date(2023, time.March, 13, 13, 30, 0, 0, time.UTC)))
+	assert.False(t, window.active(time.Date(2023, time.March, 13, 14, 30, 0, 0, time.UTC)))
+
+	windows := SyncWindows{&window}
+	assert.Nil(t, windows.inactiveAllows(time.Date(2023, time.July, 17, 13, 30, 0, 0, time.UTC)))
+	assert.NotNil(t, windows.inactiveAllows(time.Date(2023, time.July, 17, 14, 30, 0, 0, time.UTC)))
+}
+
 func TestSyncWindows_InactiveAllows(t *testing.T) {
 	t.Run("WithTestProject", func(t *testing.T) {
 		proj := newTestProjectWithSyncWindows()
@@ -2556,6 +2802,12 @@ func TestSyncWindow_Validate(t *testing.T) {
 		window.Duration = "1000days"
 		assert.Error(t, window.Validate())
 	})
+	t.Run("NonPositiveDuration", func(t *testing.T) {
+		window.Duration = "-1h"
+		assert.Error(t, window.Validate())
+		window.Duration = "0s"
+		assert.Error(t, window.Validate())
+	})
 }
 
 func TestApplicationStatus_GetConditions(t *testing.T) {
@@ -2840,6 +3092,127 @@ func TestSyncPolicy_IsZero(t *testing.T) {
 	assert.False(t, (&SyncPolicy{Retry: &RetryStrategy{}}).IsZero())
 }
 
+func TestSyncPolicy_Validate(t *testing.T) {
+	t.Run("Nil", func(t *testing.T) {
+		var p *SyncPolicy
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("Valid", func(t *testing.T) {
+		p := &SyncPolicy{
+			SyncOptions:              SyncOptions{"CreateNamespace=true", "PrunePropagationPolicy=background"},
+			ManagedNamespaceMetadata: &ManagedNamespaceMetadata{Labels: map[string]string{"foo": "bar"}},
+			Retry:                    &RetryStrategy{Limit: 5, Backoff: &Backoff{Duration: "5s", MaxDuration: "3m", Factor: pointer.Int64(2)}},
+		}
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("MalformedOption", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"CreateNamespace"}}
+		assert.ErrorContains(t, p.Validate(), "must be of the form 'key=value'")
+	})
+	t.Run("ConflictingValues", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"Prune=false", "Prune=true"}}
+		assert.ErrorContains(t, p.Validate(), "conflict")
+	})
+	t.Run("ReplaceAndServerSideApply", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"Replace=true", "ServerSideApply=true"}}
+		assert.ErrorContains(t, p.Validate(), "conflict")
+	})
+	t.Run("InvalidPrunePropagationPolicy", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"PrunePropagationPolicy=sideways"}}
+		assert.ErrorContains(t, p.Validate(), "PrunePropagationPolicy")
+	})
+	t.Run("InvalidStrictValidation", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"StrictValidation=yes"}}
+		assert.ErrorContains(t, p.Validate(), "StrictValidation")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"StrictValidation=true", "StrictValidationSchemas=../v1.27"}}
+		assert.ErrorContains(t, p.Validate(), "StrictValidationSchemas")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"StrictValidation=true", "StrictValidationSchemas=v1.27"}}
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("InvalidServerSideApplyManager", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"ServerSideApplyManager=" + strings.Repeat("a", 129)}}
+		assert.ErrorContains(t, p.Validate(), "ServerSideApplyManager")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"ServerSideApplyManager=my-manager"}}
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("InvalidSyncWaveDelay", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"SyncWaveDelay=10"}}
+		assert.ErrorContains(t, p.Validate(), "SyncWaveDelay")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"SyncWaveDelay=-1s"}}
+		assert.ErrorContains(t, p.Validate(), "SyncWaveDelay")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"SyncWaveDelay=10s"}}
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("ManagedNamespaceMetadataWithoutCreateNamespace", func(t *testing.T) {
+		p := &SyncPolicy{ManagedNamespaceMetadata: &ManagedNamespaceMetadata{}}
+		assert.ErrorContains(t, p.Validate(), "CreateNamespace=true")
+	})
+	t.Run("InvalidSelfHealTimeout", func(t *testing.T) {
+		p := &SyncPolicy{SyncOptions: SyncOptions{"SelfHealTimeout=abc"}}
+		assert.ErrorContains(t, p.Validate(), "SelfHealTimeout")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"SelfHealTimeout=-1s"}}
+		assert.ErrorContains(t, p.Validate(), "SelfHealTimeout")
+		p = &SyncPolicy{SyncOptions: SyncOptions{"SelfHealTimeout=30s"}}
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("InvalidManagedNamespaceMetadata", func(t *testing.T) {
+		p := &SyncPolicy{
+			SyncOptions:              SyncOptions{"CreateNamespace=true"},
+			ManagedNamespaceMetadata: &ManagedNamespaceMetadata{Labels: map[string]string{"pod-security.kubernetes.io/enforce": "not valid"}},
+		}
+		assert.ErrorContains(t, p.Validate(), "spec.syncPolicy.managedNamespaceMetadata.labels")
+		p.ManagedNamespaceMetadata = &ManagedNamespaceMetadata{Annotations: map[string]string{"not/a/valid/key": "value"}}
+		assert.ErrorContains(t, p.Validate(), "spec.syncPolicy.managedNamespaceMetadata.annotations")
+		p.ManagedNamespaceMetadata = &ManagedNamespaceMetadata{
+			Labels:      map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+			Annotations: map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "env=prod"},
+		}
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("InvalidBackoffDuration", func(t *testing.T) {
+		p := &SyncPolicy{Retry: &RetryStrategy{Backoff: &Backoff{Duration: "five seconds"}}}
+		assert.ErrorContains(t, p.Validate(), "duration is invalid")
+	})
+	t.Run("BackoffDurationExceedsMaxDuration", func(t *testing.T) {
+		p := &SyncPolicy{Retry: &RetryStrategy{Backoff: &Backoff{Duration: "5m", MaxDuration: "1m"}}}
+		assert.ErrorContains(t, p.Validate(), "must not exceed maxDuration")
+	})
+	t.Run("InvalidBackoffFactor", func(t *testing.T) {
+		p := &SyncPolicy{Retry: &RetryStrategy{Backoff: &Backoff{Factor: pointer.Int64(0)}}}
+		assert.ErrorContains(t, p.Validate(), "factor must be at least 1")
+	})
+}
+
+func TestSyncPolicy_ValidateChanges(t *testing.T) {
+	invalid := &SyncPolicy{
+		SyncOptions: SyncOptions{"PrunePropagationPolicy=sideways"},
+		Retry:       &RetryStrategy{Backoff: &Backoff{Duration: "five seconds"}},
+	}
+	t.Run("NoPreviousSyncPolicy", func(t *testing.T) {
+		assert.ErrorContains(t, invalid.ValidateChanges(nil), "PrunePropagationPolicy")
+	})
+	t.Run("UnchangedInvalidSettings", func(t *testing.T) {
+		p := invalid.DeepCopy()
+		p.Automated = &SyncPolicyAutomated{Prune: true}
+		assert.NoError(t, p.ValidateChanges(invalid))
+	})
+	t.Run("ChangedSyncOptions", func(t *testing.T) {
+		p := invalid.DeepCopy()
+		p.SyncOptions = append(p.SyncOptions, "CreateNamespace=true")
+		assert.ErrorContains(t, p.ValidateChanges(invalid), "PrunePropagationPolicy")
+	})
+	t.Run("ChangedRetryStrategy", func(t *testing.T) {
+		p := invalid.DeepCopy()
+		p.Retry.Limit = 3
+		assert.ErrorContains(t, p.ValidateChanges(invalid), "retry backoff duration")
+	})
+	t.Run("AddedManagedNamespaceMetadata", func(t *testing.T) {
+		p := invalid.DeepCopy()
+		p.ManagedNamespaceMetadata = &ManagedNamespaceMetadata{}
+		assert.ErrorContains(t, p.ValidateChanges(invalid), "CreateNamespace=true")
+	})
+}
+
 func TestSyncOptions_HasOption(t *testing.T) {
 	var nilOptions SyncOptions
 	assert.False(t, nilOptions.HasOption("a=1"))
@@ -2859,6 +3232,19 @@ func TestSyncOptions_RemoveOption(t *testing.T) {
 	assert.Len(t, options.RemoveOption("a=1").RemoveOption("a=1"), 0)
 }
 
+func TestSyncOptions_ServerSideApplyManager(t *testing.T) {
+	assert.Equal(t, "argocd-controller", SyncOptions(nil).ServerSideApplyManager())
+	assert.Equal(t, "argocd-controller", SyncOptions{"ServerSideApplyManager="}.ServerSideApplyManager())
+	assert.Equal(t, "my-manager", SyncOptions{"ServerSideApply=true", "ServerSideApplyManager=my-manager"}.ServerSideApplyManager())
+}
+
+func TestSyncOptions_SyncsWithServerSideApply(t *testing.T) {
+	assert.False(t, SyncOptions(nil).SyncsWithServerSideApply(false))
+	assert.True(t, SyncOptions{"ServerSideApply=true"}.SyncsWithServerSideApply(false))
+	assert.True(t, SyncOptions(nil).SyncsWithServerSideApply(true))
+	assert.False(t, SyncOptions{"ServerSideApply=false"}.SyncsWithServerSideApply(true))
+}
+
 func TestRevisionHistories_Trunc(t *testing.T) {
 	assert.Len(t, RevisionHistories{}.Trunc(1), 0)
 	assert.Len(t, RevisionHistories{{}}.Trunc(1), 1)
@@ -2875,6 +3261,26 @@ func TestApplicationSpec_GetRevisionHistoryLimit(t *testing.T) {
 	assert.Equal(t, 11, ApplicationSpec{RevisionHistoryLimit: &n}.GetRevisionHistoryLimit())
 }
 
+func TestApplication_GetManifestGenerateTimeout(t *testing.T) {
+	newApp := func(timeout string) *Application {
+		return &Application{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationKeyManifestGenerateTimeout: timeout}}}
+	}
+
+	timeout, err := (&Application{}).GetManifestGenerateTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	timeout, err = newApp("3m").GetManifestGenerateTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, timeout)
+
+	_, err = newApp("3 minutes").GetManifestGenerateTimeout()
+	assert.Error(t, err)
+
+	_, err = newApp("-1s").GetManifestGenerateTimeout()
+	assert.Error(t, err)
+}
+
 func TestProjectNormalize(t *testing.T) {
 	issuedAt := int64(1)
 	secondIssuedAt := issuedAt + 1
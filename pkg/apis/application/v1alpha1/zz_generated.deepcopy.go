@@ -156,6 +156,31 @@ func (in *AppProjectSpec) DeepCopyInto(out *AppProjectSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ManifestValidation != nil {
+		in, out := &in.ManifestValidation, &out.ManifestValidation
+		*out = new(ManifestValidation)
+		**out = **in
+	}
+	if in.FeatureFlags != nil {
+		in, out := &in.FeatureFlags, &out.FeatureFlags
+		*out = make([]FeatureFlag, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(ProjectQuotas)
+		**out = **in
+	}
+	if in.DefaultSyncPolicy != nil {
+		in, out := &in.DefaultSyncPolicy, &out.DefaultSyncPolicy
+		*out = new(SyncPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestinationServiceAccounts != nil {
+		in, out := &in.DestinationServiceAccounts, &out.DestinationServiceAccounts
+		*out = make([]ApplicationDestinationServiceAccount, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -261,6 +286,22 @@ func (in *ApplicationDestination) DeepCopy() *ApplicationDestination {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationDestinationServiceAccount) DeepCopyInto(out *ApplicationDestinationServiceAccount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationDestinationServiceAccount.
+func (in *ApplicationDestinationServiceAccount) DeepCopy() *ApplicationDestinationServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationDestinationServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationList) DeepCopyInto(out *ApplicationList) {
 	*out = *in
@@ -1036,6 +1077,18 @@ func (in *ApplicationSourceKustomize) DeepCopyInto(out *ApplicationSourceKustomi
 		*out = make(KustomizeReplicas, len(*in))
 		copy(*out, *in)
 	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make(KustomizePatches, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1560,6 +1613,11 @@ func (in *ClusterInfo) DeepCopyInto(out *ClusterInfo) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Shard != nil {
+		in, out := &in.Shard, &out.Shard
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -1806,6 +1864,22 @@ func (in *ExecProviderConfig) DeepCopy() *ExecProviderConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureFlag) DeepCopyInto(out *FeatureFlag) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureFlag.
+func (in *FeatureFlag) DeepCopy() *FeatureFlag {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureFlag)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitDirectoryGeneratorItem) DeepCopyInto(out *GitDirectoryGeneratorItem) {
 	*out = *in
@@ -2159,6 +2233,49 @@ func (in *KustomizeOptions) DeepCopy() *KustomizeOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizePatch) DeepCopyInto(out *KustomizePatch) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(KustomizeSelector)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizePatch.
+func (in *KustomizePatch) DeepCopy() *KustomizePatch {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizePatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in KustomizePatches) DeepCopyInto(out *KustomizePatches) {
+	{
+		in := &in
+		*out = make(KustomizePatches, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizePatches.
+func (in KustomizePatches) DeepCopy() KustomizePatches {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizePatches)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KustomizeReplica) DeepCopyInto(out *KustomizeReplica) {
 	*out = *in
@@ -2196,6 +2313,22 @@ func (in KustomizeReplicas) DeepCopy() KustomizeReplicas {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizeSelector) DeepCopyInto(out *KustomizeSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizeSelector.
+func (in *KustomizeSelector) DeepCopy() *KustomizeSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizeSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ListGenerator) DeepCopyInto(out *ListGenerator) {
 	*out = *in
@@ -2250,6 +2383,22 @@ func (in *ManagedNamespaceMetadata) DeepCopy() *ManagedNamespaceMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestValidation) DeepCopyInto(out *ManifestValidation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestValidation.
+func (in *ManifestValidation) DeepCopy() *ManifestValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MatrixGenerator) DeepCopyInto(out *MatrixGenerator) {
 	*out = *in
@@ -2504,6 +2653,11 @@ func (in *OrphanedResourcesMonitorSettings) DeepCopyInto(out *OrphanedResourcesM
 		*out = make([]OrphanedResourceKey, len(*in))
 		copy(*out, *in)
 	}
+	if in.Error != nil {
+		in, out := &in.Error, &out.Error
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -2640,6 +2794,22 @@ func (in PluginParameters) DeepCopy() PluginParameters {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectQuotas) DeepCopyInto(out *ProjectQuotas) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectQuotas.
+func (in *ProjectQuotas) DeepCopy() *ProjectQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectRole) DeepCopyInto(out *ProjectRole) {
 	*out = *in
@@ -2986,6 +3156,16 @@ func (in Repositories) DeepCopy() Repositories {
 func (in *Repository) DeepCopyInto(out *Repository) {
 	*out = *in
 	in.ConnectionState.DeepCopyInto(&out.ConnectionState)
+	if in.EnableSubmodules != nil {
+		in, out := &in.EnableSubmodules, &out.EnableSubmodules
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CredentialState != nil {
+		in, out := &in.CredentialState, &out.CredentialState
+		*out = new(ConnectionState)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3096,6 +3276,11 @@ func (in *ResourceAction) DeepCopy() *ResourceAction {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceActionDefinition) DeepCopyInto(out *ResourceActionDefinition) {
 	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]ResourceActionParam, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3131,7 +3316,9 @@ func (in *ResourceActions) DeepCopyInto(out *ResourceActions) {
 	if in.Definitions != nil {
 		in, out := &in.Definitions, &out.Definitions
 		*out = make([]ResourceActionDefinition, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -3452,6 +3639,13 @@ func (in *RevisionHistory) DeepCopyInto(out *RevisionHistory) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Charts != nil {
+		in, out := &in.Charts, &out.Charts
+		*out = make([]ChartDetails, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -4126,6 +4320,11 @@ func (in *TLSClientConfig) DeepCopyInto(out *TLSClientConfig) {
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.AdditionalCAData != nil {
+		in, out := &in.AdditionalCAData, &out.AdditionalCAData
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
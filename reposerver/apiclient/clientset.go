@@ -1,8 +1,12 @@
 package apiclient
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"net"
+	"strings"
+	"sync"
 	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
@@ -10,9 +14,13 @@ import (
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/util/faultinjection"
 	argogrpc "github.com/argoproj/argo-cd/v2/util/grpc"
 	"github.com/argoproj/argo-cd/v2/util/io"
 )
@@ -24,6 +32,12 @@ const (
 	MaxGRPCMessageSize = 100 * 1024 * 1024
 )
 
+var (
+	// repoServerFaults are the faults injected into repo server calls, for testing only
+	repoServerFaults       = faultinjection.ConfigFromEnv(common.EnvFaultInjectionRepoServerDelay, common.EnvFaultInjectionRepoServerFailureRate)
+	warnFaultInjectionOnce sync.Once
+)
+
 // TLSConfiguration describes parameters for TLS configuration to be used by a repo server API client
 type TLSConfiguration struct {
 	// Whether to disable TLS for connections
@@ -39,21 +53,36 @@ type Clientset interface {
 	NewRepoServerClient() (io.Closer, RepoServerServiceClient, error)
 }
 
+// RemoteClientset is implemented by Clientsets which can also connect to repo servers at other addresses, e.g. a repo
+// server deployed close to a destination cluster
+type RemoteClientset interface {
+	Clientset
+	// ForAddress returns a Clientset connecting to the repo server at the given address
+	ForAddress(address string) Clientset
+}
+
 type clientSet struct {
 	address        string
 	timeoutSeconds int
 	tlsConfig      TLSConfiguration
+	dialOpts       []grpc.DialOption
+	// remoteTLSConfig is used for connections to repo servers at other addresses
+	remoteTLSConfig TLSConfiguration
 }
 
 func (c *clientSet) NewRepoServerClient() (io.Closer, RepoServerServiceClient, error) {
-	conn, err := NewConnection(c.address, c.timeoutSeconds, &c.tlsConfig)
+	conn, err := NewConnection(c.address, c.timeoutSeconds, &c.tlsConfig, c.dialOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
 	return conn, NewRepoServerServiceClient(conn), nil
 }
 
-func NewConnection(address string, timeoutSeconds int, tlsConfig *TLSConfiguration) (*grpc.ClientConn, error) {
+func (c *clientSet) ForAddress(address string) Clientset {
+	return &clientSet{address: address, timeoutSeconds: c.timeoutSeconds, tlsConfig: c.remoteTLSConfig, remoteTLSConfig: c.remoteTLSConfig}
+}
+
+func NewConnection(address string, timeoutSeconds int, tlsConfig *TLSConfiguration, dialOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	retryOpts := []grpc_retry.CallOption{
 		grpc_retry.WithMax(3),
 		grpc_retry.WithBackoff(grpc_retry.BackoffLinear(1000 * time.Millisecond)),
@@ -62,6 +91,12 @@ func NewConnection(address string, timeoutSeconds int, tlsConfig *TLSConfigurati
 	if timeoutSeconds > 0 {
 		unaryInterceptors = append(unaryInterceptors, argogrpc.WithTimeout(time.Duration(timeoutSeconds)*time.Second))
 	}
+	// faults are injected behind the retry interceptor, so that injected failures are retried like real ones
+	faults := repoServerFaults
+	if faults.Enabled() {
+		warnFaultInjectionOnce.Do(func() { faults.WarnIfEnabled("repo server calls") })
+		unaryInterceptors = append(unaryInterceptors, faultInjectionUnaryInterceptor(faults))
+	}
 	opts := []grpc.DialOption{
 		grpc.WithStreamInterceptor(grpc_retry.StreamClientInterceptor(retryOpts...)),
 		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(unaryInterceptors...)),
@@ -81,6 +116,10 @@ func NewConnection(address string, timeoutSeconds int, tlsConfig *TLSConfigurati
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
+	if faults.Enabled() {
+		opts = append(opts, grpc.WithChainStreamInterceptor(faultInjectionStreamInterceptor(faults)))
+	}
+	opts = append(opts, dialOpts...)
 
 	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
@@ -90,7 +129,58 @@ func NewConnection(address string, timeoutSeconds int, tlsConfig *TLSConfigurati
 	return conn, nil
 }
 
-// NewRepoServerClientset creates new instance of repo server Clientset
+// faultInjectionUnaryInterceptor delays and fails repo server calls as configured. Injected failures are reported as
+// unavailable, which is how the loss of a repo server replica surfaces to clients.
+func faultInjectionUnaryInterceptor(faults faultinjection.Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := faults.Inject(ctx); err != nil {
+			return faultInjectionError(err)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// faultInjectionStreamInterceptor delays and fails the creation of repo server streams as configured
+func faultInjectionStreamInterceptor(faults faultinjection.Config) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := faults.Inject(ctx); err != nil {
+			return nil, faultInjectionError(err)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func faultInjectionError(err error) error {
+	if err == faultinjection.ErrInjected {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.FromContextError(err).Err()
+}
+
+// NewRepoServerClientset creates new instance of repo server Clientset. The address can be a comma-separated list of
+// repo server addresses, in which case repositories are sharded across the repo servers.
 func NewRepoServerClientset(address string, timeoutSeconds int, tlsConfig TLSConfiguration) Clientset {
-	return &clientSet{address: address, timeoutSeconds: timeoutSeconds, tlsConfig: tlsConfig}
+	var addresses []string
+	for _, a := range strings.Split(address, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addresses = append(addresses, a)
+		}
+	}
+	if len(addresses) > 1 {
+		return NewShardedRepoServerClientset(addresses, timeoutSeconds, tlsConfig)
+	}
+	return &clientSet{address: address, timeoutSeconds: timeoutSeconds, tlsConfig: tlsConfig, remoteTLSConfig: tlsConfig}
+}
+
+// NewInMemoryRepoServerClientset creates new instance of repo server Clientset which connects to a repo server running
+// in the same process using the given dialer instead of the network. Connections to repo servers at other addresses
+// use TLS and validate the certificates of the repo servers against the system roots.
+func NewInMemoryRepoServerClientset(dialer func(ctx context.Context, address string) (net.Conn, error), timeoutSeconds int) Clientset {
+	return &clientSet{
+		address:         "passthrough:///argocd-repo-server",
+		timeoutSeconds:  timeoutSeconds,
+		tlsConfig:       TLSConfiguration{DisableTLS: true},
+		dialOpts:        []grpc.DialOption{grpc.WithContextDialer(dialer)},
+		remoteTLSConfig: TLSConfiguration{StrictValidation: true},
+	}
 }
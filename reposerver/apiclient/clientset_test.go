@@ -0,0 +1,41 @@
+package apiclient
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type loadReportServer struct {
+	UnimplementedRepoServerServiceServer
+}
+
+func (s *loadReportServer) GetLoadReport(context.Context, *emptypb.Empty) (*LoadReport, error) {
+	return &LoadReport{ActiveGenerations: 2, ParallelismLimit: 10}, nil
+}
+
+func TestNewInMemoryRepoServerClientset(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterRepoServerServiceServer(server, &loadReportServer{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	clientset := NewInMemoryRepoServerClientset(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}, 10)
+	closer, client, err := clientset.NewRepoServerClient()
+	require.NoError(t, err)
+	defer closer.Close()
+
+	report, err := client.GetLoadReport(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), report.ActiveGenerations)
+	assert.Equal(t, int64(10), report.ParallelismLimit)
+}
@@ -219,6 +219,39 @@ func (_m *RepoServerServiceClient) GetHelmCharts(ctx context.Context, in *apicli
 	return r0, r1
 }
 
+// GetLoadReport provides a mock function with given fields: ctx, in, opts
+func (_m *RepoServerServiceClient) GetLoadReport(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*apiclient.LoadReport, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *apiclient.LoadReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *emptypb.Empty, ...grpc.CallOption) (*apiclient.LoadReport, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *emptypb.Empty, ...grpc.CallOption) *apiclient.LoadReport); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apiclient.LoadReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *emptypb.Empty, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRevisionChartDetails provides a mock function with given fields: ctx, in, opts
 func (_m *RepoServerServiceClient) GetRevisionChartDetails(ctx context.Context, in *apiclient.RepoServerRevisionChartDetailsRequest, opts ...grpc.CallOption) (*v1alpha1.ChartDetails, error) {
 	_va := make([]interface{}, len(opts))
@@ -315,6 +348,39 @@ func (_m *RepoServerServiceClient) ListApps(ctx context.Context, in *apiclient.L
 	return r0, r1
 }
 
+// ListGitPaths provides a mock function with given fields: ctx, in, opts
+func (_m *RepoServerServiceClient) ListGitPaths(ctx context.Context, in *apiclient.ListGitPathsRequest, opts ...grpc.CallOption) (apiclient.RepoServerService_ListGitPathsClient, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 apiclient.RepoServerService_ListGitPathsClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *apiclient.ListGitPathsRequest, ...grpc.CallOption) (apiclient.RepoServerService_ListGitPathsClient, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *apiclient.ListGitPathsRequest, ...grpc.CallOption) apiclient.RepoServerService_ListGitPathsClient); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(apiclient.RepoServerService_ListGitPathsClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *apiclient.ListGitPathsRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListPlugins provides a mock function with given fields: ctx, in, opts
 func (_m *RepoServerServiceClient) ListPlugins(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*apiclient.PluginList, error) {
 	_va := make([]interface{}, len(opts))
@@ -57,7 +57,11 @@ type ManifestRequest struct {
 	// This is used to surface "source not permitted" errors for Helm repositories
 	ProjectSourceRepos []string `protobuf:"bytes,24,rep,name=projectSourceRepos,proto3" json:"projectSourceRepos,omitempty"`
 	// This is used to surface "source not permitted" errors for Helm repositories
-	ProjectName          string   `protobuf:"bytes,25,opt,name=projectName,proto3" json:"projectName,omitempty"`
+	ProjectName string `protobuf:"bytes,25,opt,name=projectName,proto3" json:"projectName,omitempty"`
+	// the URL patterns of the only repositories which are permitted by the instance-wide repository policy
+	RepositoryAllowList []string `protobuf:"bytes,26,rep,name=repositoryAllowList,proto3" json:"repositoryAllowList,omitempty"`
+	// the URL patterns of the repositories which are denied by the instance-wide repository policy
+	RepositoryDenyList   []string `protobuf:"bytes,27,rep,name=repositoryDenyList,proto3" json:"repositoryDenyList,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -250,6 +254,20 @@ func (m *ManifestRequest) GetProjectName() string {
 	return ""
 }
 
+func (m *ManifestRequest) GetRepositoryAllowList() []string {
+	if m != nil {
+		return m.RepositoryAllowList
+	}
+	return nil
+}
+
+func (m *ManifestRequest) GetRepositoryDenyList() []string {
+	if m != nil {
+		return m.RepositoryDenyList
+	}
+	return nil
+}
+
 type ManifestRequestWithFiles struct {
 	// Types that are valid to be assigned to Part:
 	//	*ManifestRequestWithFiles_Request
@@ -557,6 +575,7 @@ type ResolveRevisionRequest struct {
 	Repo                 *v1alpha1.Repository  `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
 	App                  *v1alpha1.Application `protobuf:"bytes,2,opt,name=app,proto3" json:"app,omitempty"`
 	AmbiguousRevision    string                `protobuf:"bytes,3,opt,name=ambiguousRevision,proto3" json:"ambiguousRevision,omitempty"`
+	SourceIndex          int64                 `protobuf:"varint,4,opt,name=sourceIndex,proto3" json:"sourceIndex,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
 	XXX_sizecache        int32                 `json:"-"`
@@ -616,6 +635,13 @@ func (m *ResolveRevisionRequest) GetAmbiguousRevision() string {
 	return ""
 }
 
+func (m *ResolveRevisionRequest) GetSourceIndex() int64 {
+	if m != nil {
+		return m.SourceIndex
+	}
+	return 0
+}
+
 // ResolveRevisionResponse
 type ResolveRevisionResponse struct {
 	// returns the resolved revision
@@ -1074,20 +1100,24 @@ func (m *PluginList) GetItems() []*PluginInfo {
 
 // RepoServerAppDetailsQuery contains query information for app details request
 type RepoServerAppDetailsQuery struct {
-	Repo                 *v1alpha1.Repository           `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
-	Source               *v1alpha1.ApplicationSource    `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
-	Repos                []*v1alpha1.Repository         `protobuf:"bytes,3,rep,name=repos,proto3" json:"repos,omitempty"`
-	KustomizeOptions     *v1alpha1.KustomizeOptions     `protobuf:"bytes,4,opt,name=kustomizeOptions,proto3" json:"kustomizeOptions,omitempty"`
-	AppName              string                         `protobuf:"bytes,5,opt,name=appName,proto3" json:"appName,omitempty"`
-	NoCache              bool                           `protobuf:"varint,6,opt,name=noCache,proto3" json:"noCache,omitempty"`
-	NoRevisionCache      bool                           `protobuf:"varint,7,opt,name=noRevisionCache,proto3" json:"noRevisionCache,omitempty"`
-	TrackingMethod       string                         `protobuf:"bytes,8,opt,name=trackingMethod,proto3" json:"trackingMethod,omitempty"`
-	EnabledSourceTypes   map[string]bool                `protobuf:"bytes,9,rep,name=enabledSourceTypes,proto3" json:"enabledSourceTypes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
-	HelmOptions          *v1alpha1.HelmOptions          `protobuf:"bytes,10,opt,name=helmOptions,proto3" json:"helmOptions,omitempty"`
-	RefSources           map[string]*v1alpha1.RefTarget `protobuf:"bytes,11,rep,name=refSources,proto3" json:"refSources,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}                       `json:"-"`
-	XXX_unrecognized     []byte                         `json:"-"`
-	XXX_sizecache        int32                          `json:"-"`
+	Repo               *v1alpha1.Repository           `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
+	Source             *v1alpha1.ApplicationSource    `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Repos              []*v1alpha1.Repository         `protobuf:"bytes,3,rep,name=repos,proto3" json:"repos,omitempty"`
+	KustomizeOptions   *v1alpha1.KustomizeOptions     `protobuf:"bytes,4,opt,name=kustomizeOptions,proto3" json:"kustomizeOptions,omitempty"`
+	AppName            string                         `protobuf:"bytes,5,opt,name=appName,proto3" json:"appName,omitempty"`
+	NoCache            bool                           `protobuf:"varint,6,opt,name=noCache,proto3" json:"noCache,omitempty"`
+	NoRevisionCache    bool                           `protobuf:"varint,7,opt,name=noRevisionCache,proto3" json:"noRevisionCache,omitempty"`
+	TrackingMethod     string                         `protobuf:"bytes,8,opt,name=trackingMethod,proto3" json:"trackingMethod,omitempty"`
+	EnabledSourceTypes map[string]bool                `protobuf:"bytes,9,rep,name=enabledSourceTypes,proto3" json:"enabledSourceTypes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	HelmOptions        *v1alpha1.HelmOptions          `protobuf:"bytes,10,opt,name=helmOptions,proto3" json:"helmOptions,omitempty"`
+	RefSources         map[string]*v1alpha1.RefTarget `protobuf:"bytes,11,rep,name=refSources,proto3" json:"refSources,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// the URL patterns of the only repositories which are permitted by the instance-wide repository policy
+	RepositoryAllowList []string `protobuf:"bytes,12,rep,name=repositoryAllowList,proto3" json:"repositoryAllowList,omitempty"`
+	// the URL patterns of the repositories which are denied by the instance-wide repository policy
+	RepositoryDenyList   []string `protobuf:"bytes,13,rep,name=repositoryDenyList,proto3" json:"repositoryDenyList,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *RepoServerAppDetailsQuery) Reset()         { *m = RepoServerAppDetailsQuery{} }
@@ -1200,6 +1230,20 @@ func (m *RepoServerAppDetailsQuery) GetRefSources() map[string]*v1alpha1.RefTarg
 	return nil
 }
 
+func (m *RepoServerAppDetailsQuery) GetRepositoryAllowList() []string {
+	if m != nil {
+		return m.RepositoryAllowList
+	}
+	return nil
+}
+
+func (m *RepoServerAppDetailsQuery) GetRepositoryDenyList() []string {
+	if m != nil {
+		return m.RepositoryDenyList
+	}
+	return nil
+}
+
 // RepoAppDetailsResponse application details
 type RepoAppDetailsResponse struct {
 	Type                 string            `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
@@ -1286,7 +1330,11 @@ type RepoServerRevisionMetadataRequest struct {
 	// the revision within the repo
 	Revision string `protobuf:"bytes,2,opt,name=revision,proto3" json:"revision,omitempty"`
 	// whether to check signature on revision
-	CheckSignature       bool     `protobuf:"varint,3,opt,name=checkSignature,proto3" json:"checkSignature,omitempty"`
+	CheckSignature bool `protobuf:"varint,3,opt,name=checkSignature,proto3" json:"checkSignature,omitempty"`
+	// the URL patterns of the only repositories which are permitted by the instance-wide repository policy
+	RepositoryAllowList []string `protobuf:"bytes,4,rep,name=repositoryAllowList,proto3" json:"repositoryAllowList,omitempty"`
+	// the URL patterns of the repositories which are denied by the instance-wide repository policy
+	RepositoryDenyList   []string `protobuf:"bytes,5,rep,name=repositoryDenyList,proto3" json:"repositoryDenyList,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1346,6 +1394,20 @@ func (m *RepoServerRevisionMetadataRequest) GetCheckSignature() bool {
 	return false
 }
 
+func (m *RepoServerRevisionMetadataRequest) GetRepositoryAllowList() []string {
+	if m != nil {
+		return m.RepositoryAllowList
+	}
+	return nil
+}
+
+func (m *RepoServerRevisionMetadataRequest) GetRepositoryDenyList() []string {
+	if m != nil {
+		return m.RepositoryDenyList
+	}
+	return nil
+}
+
 type RepoServerRevisionChartDetailsRequest struct {
 	// the repo
 	Repo *v1alpha1.Repository `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
@@ -1421,10 +1483,12 @@ type HelmAppSpec struct {
 	// the contents of values.yaml
 	Values string `protobuf:"bytes,5,opt,name=values,proto3" json:"values,omitempty"`
 	// helm file parameters
-	FileParameters       []*v1alpha1.HelmFileParameter `protobuf:"bytes,6,rep,name=fileParameters,proto3" json:"fileParameters,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
-	XXX_unrecognized     []byte                        `json:"-"`
-	XXX_sizecache        int32                         `json:"-"`
+	FileParameters []*v1alpha1.HelmFileParameter `protobuf:"bytes,6,rep,name=fileParameters,proto3" json:"fileParameters,omitempty"`
+	// the contents of values.schema.json, if the chart has one
+	ValuesSchema         string   `protobuf:"bytes,7,opt,name=valuesSchema,proto3" json:"valuesSchema,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *HelmAppSpec) Reset()         { *m = HelmAppSpec{} }
@@ -1495,6 +1559,13 @@ func (m *HelmAppSpec) GetFileParameters() []*v1alpha1.HelmFileParameter {
 	return nil
 }
 
+func (m *HelmAppSpec) GetValuesSchema() string {
+	if m != nil {
+		return m.ValuesSchema
+	}
+	return ""
+}
+
 // KustomizeAppSpec contains kustomize images
 type KustomizeAppSpec struct {
 	// images is a list of available images.
@@ -2142,6 +2213,247 @@ func (m *GitDirectoriesResponse) GetPaths() []string {
 	return nil
 }
 
+type ListGitPathsRequest struct {
+	Repo             *v1alpha1.Repository `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
+	SubmoduleEnabled bool                 `protobuf:"varint,2,opt,name=submoduleEnabled,proto3" json:"submoduleEnabled,omitempty"`
+	Revision         string               `protobuf:"bytes,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	// Globs restricting the listed paths to the ones matching any of them, e.g. "apps/**/config.json". All paths are listed if empty
+	Globs []string `protobuf:"bytes,4,rep,name=globs,proto3" json:"globs,omitempty"`
+	// Lists the directory paths instead of the file paths of the repo
+	Directories bool `protobuf:"varint,5,opt,name=directories,proto3" json:"directories,omitempty"`
+	// The maximum number of listed paths. Unlimited if 0
+	MaxResults int64 `protobuf:"varint,6,opt,name=maxResults,proto3" json:"maxResults,omitempty"`
+	// Matches the globs against the paths regardless of their case
+	CaseInsensitive bool `protobuf:"varint,7,opt,name=caseInsensitive,proto3" json:"caseInsensitive,omitempty"`
+	// Omits all symlinks from the listed paths. Symlinks resolving outside of the repository are never listed
+	SkipSymlinks         bool     `protobuf:"varint,8,opt,name=skipSymlinks,proto3" json:"skipSymlinks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListGitPathsRequest) Reset()         { *m = ListGitPathsRequest{} }
+func (m *ListGitPathsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListGitPathsRequest) ProtoMessage()    {}
+func (m *ListGitPathsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListGitPathsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListGitPathsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListGitPathsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListGitPathsRequest.Merge(m, src)
+}
+func (m *ListGitPathsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListGitPathsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListGitPathsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListGitPathsRequest proto.InternalMessageInfo
+
+func (m *ListGitPathsRequest) GetRepo() *v1alpha1.Repository {
+	if m != nil {
+		return m.Repo
+	}
+	return nil
+}
+
+func (m *ListGitPathsRequest) GetSubmoduleEnabled() bool {
+	if m != nil {
+		return m.SubmoduleEnabled
+	}
+	return false
+}
+
+func (m *ListGitPathsRequest) GetRevision() string {
+	if m != nil {
+		return m.Revision
+	}
+	return ""
+}
+
+func (m *ListGitPathsRequest) GetGlobs() []string {
+	if m != nil {
+		return m.Globs
+	}
+	return nil
+}
+
+func (m *ListGitPathsRequest) GetDirectories() bool {
+	if m != nil {
+		return m.Directories
+	}
+	return false
+}
+
+func (m *ListGitPathsRequest) GetMaxResults() int64 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+func (m *ListGitPathsRequest) GetCaseInsensitive() bool {
+	if m != nil {
+		return m.CaseInsensitive
+	}
+	return false
+}
+
+func (m *ListGitPathsRequest) GetSkipSymlinks() bool {
+	if m != nil {
+		return m.SkipSymlinks
+	}
+	return false
+}
+
+// GitPathsResponse is a chunk of the paths streamed by ListGitPaths
+type GitPathsResponse struct {
+	Paths []string `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`
+	// Whether paths were omitted because of the maxResults limit. Only set on the last chunk
+	Truncated            bool     `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GitPathsResponse) Reset()         { *m = GitPathsResponse{} }
+func (m *GitPathsResponse) String() string { return proto.CompactTextString(m) }
+func (*GitPathsResponse) ProtoMessage()    {}
+func (m *GitPathsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GitPathsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GitPathsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GitPathsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GitPathsResponse.Merge(m, src)
+}
+func (m *GitPathsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GitPathsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GitPathsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GitPathsResponse proto.InternalMessageInfo
+
+func (m *GitPathsResponse) GetPaths() []string {
+	if m != nil {
+		return m.Paths
+	}
+	return nil
+}
+
+func (m *GitPathsResponse) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+// LoadReport describes the current load of a repo-server instance
+type LoadReport struct {
+	// The number of repository operations which are currently running
+	ActiveGenerations int64 `protobuf:"varint,1,opt,name=activeGenerations,proto3" json:"activeGenerations,omitempty"`
+	// The number of repository operations which are waiting for the parallelism limit
+	QueueDepth int64 `protobuf:"varint,2,opt,name=queueDepth,proto3" json:"queueDepth,omitempty"`
+	// The maximum number of concurrent manifest generations, or 0 if unlimited
+	ParallelismLimit int64 `protobuf:"varint,3,opt,name=parallelismLimit,proto3" json:"parallelismLimit,omitempty"`
+	// The disk space used by checked out repositories and downloaded charts
+	DiskUsageBytes int64 `protobuf:"varint,4,opt,name=diskUsageBytes,proto3" json:"diskUsageBytes,omitempty"`
+	// The name of the repo-server replica which reported its load
+	Replica              string   `protobuf:"bytes,5,opt,name=replica,proto3" json:"replica,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LoadReport) Reset()         { *m = LoadReport{} }
+func (m *LoadReport) String() string { return proto.CompactTextString(m) }
+func (*LoadReport) ProtoMessage()    {}
+func (m *LoadReport) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LoadReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LoadReport.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LoadReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoadReport.Merge(m, src)
+}
+func (m *LoadReport) XXX_Size() int {
+	return m.Size()
+}
+func (m *LoadReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoadReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoadReport proto.InternalMessageInfo
+
+func (m *LoadReport) GetActiveGenerations() int64 {
+	if m != nil {
+		return m.ActiveGenerations
+	}
+	return 0
+}
+
+func (m *LoadReport) GetQueueDepth() int64 {
+	if m != nil {
+		return m.QueueDepth
+	}
+	return 0
+}
+
+func (m *LoadReport) GetParallelismLimit() int64 {
+	if m != nil {
+		return m.ParallelismLimit
+	}
+	return 0
+}
+
+func (m *LoadReport) GetDiskUsageBytes() int64 {
+	if m != nil {
+		return m.DiskUsageBytes
+	}
+	return 0
+}
+
+func (m *LoadReport) GetReplica() string {
+	if m != nil {
+		return m.Replica
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ManifestRequest)(nil), "repository.ManifestRequest")
 	proto.RegisterMapType((map[string]bool)(nil), "repository.ManifestRequest.EnabledSourceTypesEntry")
@@ -2182,6 +2494,9 @@ func init() {
 	proto.RegisterMapType((map[string][]byte)(nil), "repository.GitFilesResponse.MapEntry")
 	proto.RegisterType((*GitDirectoriesRequest)(nil), "repository.GitDirectoriesRequest")
 	proto.RegisterType((*GitDirectoriesResponse)(nil), "repository.GitDirectoriesResponse")
+	proto.RegisterType((*ListGitPathsRequest)(nil), "repository.ListGitPathsRequest")
+	proto.RegisterType((*GitPathsResponse)(nil), "repository.GitPathsResponse")
+	proto.RegisterType((*LoadReport)(nil), "repository.LoadReport")
 }
 
 func init() {
@@ -2363,6 +2678,11 @@ type RepoServerServiceClient interface {
 	GetGitFiles(ctx context.Context, in *GitFilesRequest, opts ...grpc.CallOption) (*GitFilesResponse, error)
 	// GetGitDirectories returns a set of directory paths for the given repo
 	GetGitDirectories(ctx context.Context, in *GitDirectoriesRequest, opts ...grpc.CallOption) (*GitDirectoriesResponse, error)
+	// ListGitPaths streams the file or directory paths of the given repo in chunks, which unlike GetGitFiles and
+	// GetGitDirectories does not exceed the gRPC message size limits for large repos
+	ListGitPaths(ctx context.Context, in *ListGitPathsRequest, opts ...grpc.CallOption) (RepoServerService_ListGitPathsClient, error)
+	// GetLoadReport returns the current load of the repo-server instance
+	GetLoadReport(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LoadReport, error)
 }
 
 type repoServerServiceClient struct {
@@ -2515,6 +2835,47 @@ func (c *repoServerServiceClient) GetGitDirectories(ctx context.Context, in *Git
 	return out, nil
 }
 
+func (c *repoServerServiceClient) ListGitPaths(ctx context.Context, in *ListGitPathsRequest, opts ...grpc.CallOption) (RepoServerService_ListGitPathsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RepoServerService_serviceDesc.Streams[1], "/repository.RepoServerService/ListGitPaths", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &repoServerServiceListGitPathsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RepoServerService_ListGitPathsClient interface {
+	Recv() (*GitPathsResponse, error)
+	grpc.ClientStream
+}
+
+type repoServerServiceListGitPathsClient struct {
+	grpc.ClientStream
+}
+
+func (x *repoServerServiceListGitPathsClient) Recv() (*GitPathsResponse, error) {
+	m := new(GitPathsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *repoServerServiceClient) GetLoadReport(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LoadReport, error) {
+	out := new(LoadReport)
+	err := c.cc.Invoke(ctx, "/repository.RepoServerService/GetLoadReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RepoServerServiceServer is the server API for RepoServerService service.
 type RepoServerServiceServer interface {
 	// GenerateManifest generates manifest for application in specified repo name and revision
@@ -2543,6 +2904,11 @@ type RepoServerServiceServer interface {
 	GetGitFiles(context.Context, *GitFilesRequest) (*GitFilesResponse, error)
 	// GetGitDirectories returns a set of directory paths for the given repo
 	GetGitDirectories(context.Context, *GitDirectoriesRequest) (*GitDirectoriesResponse, error)
+	// ListGitPaths streams the file or directory paths of the given repo in chunks, which unlike GetGitFiles and
+	// GetGitDirectories does not exceed the gRPC message size limits for large repos
+	ListGitPaths(*ListGitPathsRequest, RepoServerService_ListGitPathsServer) error
+	// GetLoadReport returns the current load of the repo-server instance
+	GetLoadReport(context.Context, *emptypb.Empty) (*LoadReport, error)
 }
 
 // UnimplementedRepoServerServiceServer can be embedded to have forward compatible implementations.
@@ -2588,6 +2954,12 @@ func (*UnimplementedRepoServerServiceServer) GetGitFiles(ctx context.Context, re
 func (*UnimplementedRepoServerServiceServer) GetGitDirectories(ctx context.Context, req *GitDirectoriesRequest) (*GitDirectoriesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetGitDirectories not implemented")
 }
+func (*UnimplementedRepoServerServiceServer) ListGitPaths(req *ListGitPathsRequest, srv RepoServerService_ListGitPathsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListGitPaths not implemented")
+}
+func (*UnimplementedRepoServerServiceServer) GetLoadReport(ctx context.Context, req *emptypb.Empty) (*LoadReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoadReport not implemented")
+}
 
 func RegisterRepoServerServiceServer(s *grpc.Server, srv RepoServerServiceServer) {
 	s.RegisterService(&_RepoServerService_serviceDesc, srv)
@@ -2835,6 +3207,45 @@ func _RepoServerService_GetGitDirectories_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RepoServerService_ListGitPaths_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListGitPathsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RepoServerServiceServer).ListGitPaths(m, &repoServerServiceListGitPathsServer{stream})
+}
+
+type RepoServerService_ListGitPathsServer interface {
+	Send(*GitPathsResponse) error
+	grpc.ServerStream
+}
+
+type repoServerServiceListGitPathsServer struct {
+	grpc.ServerStream
+}
+
+func (x *repoServerServiceListGitPathsServer) Send(m *GitPathsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _RepoServerService_GetLoadReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepoServerServiceServer).GetLoadReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/repository.RepoServerService/GetLoadReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepoServerServiceServer).GetLoadReport(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RepoServerService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "repository.RepoServerService",
 	HandlerType: (*RepoServerServiceServer)(nil),
@@ -2887,6 +3298,10 @@ var _RepoServerService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetGitDirectories",
 			Handler:    _RepoServerService_GetGitDirectories_Handler,
 		},
+		{
+			MethodName: "GetLoadReport",
+			Handler:    _RepoServerService_GetLoadReport_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2894,6 +3309,11 @@ var _RepoServerService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _RepoServerService_GenerateManifestWithFiles_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ListGitPaths",
+			Handler:       _RepoServerService_ListGitPaths_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "reposerver/repository/repository.proto",
 }
@@ -2922,7 +3342,29 @@ func (m *ManifestRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if len(m.ProjectName) > 0 {
+	if len(m.RepositoryDenyList) > 0 {
+		for iNdEx := len(m.RepositoryDenyList) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RepositoryDenyList[iNdEx])
+			copy(dAtA[i:], m.RepositoryDenyList[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.RepositoryDenyList[iNdEx])))
+			i--
+			dAtA[i] = 0x1
+			i--
+			dAtA[i] = 0xda
+		}
+	}
+	if len(m.RepositoryAllowList) > 0 {
+		for iNdEx := len(m.RepositoryAllowList) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RepositoryAllowList[iNdEx])
+			copy(dAtA[i:], m.RepositoryAllowList[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.RepositoryAllowList[iNdEx])))
+			i--
+			dAtA[i] = 0x1
+			i--
+			dAtA[i] = 0xd2
+		}
+	}
+	if len(m.ProjectName) > 0 {
 		i -= len(m.ProjectName)
 		copy(dAtA[i:], m.ProjectName)
 		i = encodeVarintRepository(dAtA, i, uint64(len(m.ProjectName)))
@@ -3462,6 +3904,11 @@ func (m *ResolveRevisionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error)
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.SourceIndex != 0 {
+		i = encodeVarintRepository(dAtA, i, uint64(m.SourceIndex))
+		i--
+		dAtA[i] = 0x20
+	}
 	if len(m.AmbiguousRevision) > 0 {
 		i -= len(m.AmbiguousRevision)
 		copy(dAtA[i:], m.AmbiguousRevision)
@@ -3905,6 +4352,24 @@ func (m *RepoServerAppDetailsQuery) MarshalToSizedBuffer(dAtA []byte) (int, erro
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.RepositoryDenyList) > 0 {
+		for iNdEx := len(m.RepositoryDenyList) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RepositoryDenyList[iNdEx])
+			copy(dAtA[i:], m.RepositoryDenyList[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.RepositoryDenyList[iNdEx])))
+			i--
+			dAtA[i] = 0x6a
+		}
+	}
+	if len(m.RepositoryAllowList) > 0 {
+		for iNdEx := len(m.RepositoryAllowList) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RepositoryAllowList[iNdEx])
+			copy(dAtA[i:], m.RepositoryAllowList[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.RepositoryAllowList[iNdEx])))
+			i--
+			dAtA[i] = 0x62
+		}
+	}
 	if len(m.RefSources) > 0 {
 		for k := range m.RefSources {
 			v := m.RefSources[k]
@@ -4158,6 +4623,24 @@ func (m *RepoServerRevisionMetadataRequest) MarshalToSizedBuffer(dAtA []byte) (i
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.RepositoryDenyList) > 0 {
+		for iNdEx := len(m.RepositoryDenyList) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RepositoryDenyList[iNdEx])
+			copy(dAtA[i:], m.RepositoryDenyList[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.RepositoryDenyList[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.RepositoryAllowList) > 0 {
+		for iNdEx := len(m.RepositoryAllowList) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RepositoryAllowList[iNdEx])
+			copy(dAtA[i:], m.RepositoryAllowList[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.RepositoryAllowList[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.CheckSignature {
 		i--
 		if m.CheckSignature {
@@ -4267,6 +4750,13 @@ func (m *HelmAppSpec) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.ValuesSchema) > 0 {
+		i -= len(m.ValuesSchema)
+		copy(dAtA[i:], m.ValuesSchema)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.ValuesSchema)))
+		i--
+		dAtA[i] = 0x3a
+	}
 	if len(m.FileParameters) > 0 {
 		for iNdEx := len(m.FileParameters) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4868,6 +5358,206 @@ func (m *GitDirectoriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	return len(dAtA) - i, nil
 }
 
+func (m *ListGitPathsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListGitPathsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListGitPathsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.SkipSymlinks {
+		i--
+		if m.SkipSymlinks {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.CaseInsensitive {
+		i--
+		if m.CaseInsensitive {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.MaxResults != 0 {
+		i = encodeVarintRepository(dAtA, i, uint64(m.MaxResults))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Directories {
+		i--
+		if m.Directories {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Globs) > 0 {
+		for iNdEx := len(m.Globs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Globs[iNdEx])
+			copy(dAtA[i:], m.Globs[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.Globs[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Revision) > 0 {
+		i -= len(m.Revision)
+		copy(dAtA[i:], m.Revision)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.Revision)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.SubmoduleEnabled {
+		i--
+		if m.SubmoduleEnabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Repo != nil {
+		{
+			size, err := m.Repo.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRepository(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GitPathsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GitPathsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GitPathsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Truncated {
+		i--
+		if m.Truncated {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Paths) > 0 {
+		for iNdEx := len(m.Paths) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Paths[iNdEx])
+			copy(dAtA[i:], m.Paths[iNdEx])
+			i = encodeVarintRepository(dAtA, i, uint64(len(m.Paths[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *LoadReport) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LoadReport) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LoadReport) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Replica) > 0 {
+		i -= len(m.Replica)
+		copy(dAtA[i:], m.Replica)
+		i = encodeVarintRepository(dAtA, i, uint64(len(m.Replica)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.DiskUsageBytes != 0 {
+		i = encodeVarintRepository(dAtA, i, uint64(m.DiskUsageBytes))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.ParallelismLimit != 0 {
+		i = encodeVarintRepository(dAtA, i, uint64(m.ParallelismLimit))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.QueueDepth != 0 {
+		i = encodeVarintRepository(dAtA, i, uint64(m.QueueDepth))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.ActiveGenerations != 0 {
+		i = encodeVarintRepository(dAtA, i, uint64(m.ActiveGenerations))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintRepository(dAtA []byte, offset int, v uint64) int {
 	offset -= sovRepository(v)
 	base := offset
@@ -4992,6 +5682,18 @@ func (m *ManifestRequest) Size() (n int) {
 	if l > 0 {
 		n += 2 + l + sovRepository(uint64(l))
 	}
+	if len(m.RepositoryAllowList) > 0 {
+		for _, s := range m.RepositoryAllowList {
+			l = len(s)
+			n += 2 + l + sovRepository(uint64(l))
+		}
+	}
+	if len(m.RepositoryDenyList) > 0 {
+		for _, s := range m.RepositoryDenyList {
+			l = len(s)
+			n += 2 + l + sovRepository(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5133,6 +5835,9 @@ func (m *ResolveRevisionRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRepository(uint64(l))
 	}
+	if m.SourceIndex != 0 {
+		n += 1 + sovRepository(uint64(m.SourceIndex))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5382,6 +6087,18 @@ func (m *RepoServerAppDetailsQuery) Size() (n int) {
 			n += mapEntrySize + 1 + sovRepository(uint64(mapEntrySize))
 		}
 	}
+	if len(m.RepositoryAllowList) > 0 {
+		for _, s := range m.RepositoryAllowList {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	if len(m.RepositoryDenyList) > 0 {
+		for _, s := range m.RepositoryDenyList {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5437,6 +6154,18 @@ func (m *RepoServerRevisionMetadataRequest) Size() (n int) {
 	if m.CheckSignature {
 		n += 2
 	}
+	if len(m.RepositoryAllowList) > 0 {
+		for _, s := range m.RepositoryAllowList {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	if len(m.RepositoryDenyList) > 0 {
+		for _, s := range m.RepositoryDenyList {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5499,6 +6228,10 @@ func (m *HelmAppSpec) Size() (n int) {
 			n += 1 + l + sovRepository(uint64(l))
 		}
 	}
+	l = len(m.ValuesSchema)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5757,10 +6490,100 @@ func (m *GitDirectoriesResponse) Size() (n int) {
 	return n
 }
 
-func sovRepository(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
+func (m *ListGitPathsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Repo != nil {
+		l = m.Repo.Size()
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if m.SubmoduleEnabled {
+		n += 2
+	}
+	l = len(m.Revision)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if len(m.Globs) > 0 {
+		for _, s := range m.Globs {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	if m.Directories {
+		n += 2
+	}
+	if m.MaxResults != 0 {
+		n += 1 + sovRepository(uint64(m.MaxResults))
+	}
+	if m.CaseInsensitive {
+		n += 2
+	}
+	if m.SkipSymlinks {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
 }
-func sozRepository(x uint64) (n int) {
+
+func (m *GitPathsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Paths) > 0 {
+		for _, s := range m.Paths {
+			l = len(s)
+			n += 1 + l + sovRepository(uint64(l))
+		}
+	}
+	if m.Truncated {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LoadReport) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ActiveGenerations != 0 {
+		n += 1 + sovRepository(uint64(m.ActiveGenerations))
+	}
+	if m.QueueDepth != 0 {
+		n += 1 + sovRepository(uint64(m.QueueDepth))
+	}
+	if m.ParallelismLimit != 0 {
+		n += 1 + sovRepository(uint64(m.ParallelismLimit))
+	}
+	if m.DiskUsageBytes != 0 {
+		n += 1 + sovRepository(uint64(m.DiskUsageBytes))
+	}
+	l = len(m.Replica)
+	if l > 0 {
+		n += 1 + l + sovRepository(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovRepository(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozRepository(x uint64) (n int) {
 	return sovRepository(uint64((x << 1) ^ uint64((int64(x) >> 63))))
 }
 func (m *ManifestRequest) Unmarshal(dAtA []byte) error {
@@ -6650,6 +7473,70 @@ func (m *ManifestRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.ProjectName = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 26:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RepositoryAllowList", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RepositoryAllowList = append(m.RepositoryAllowList, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 27:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RepositoryDenyList", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RepositoryDenyList = append(m.RepositoryDenyList, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRepository(dAtA[iNdEx:])
@@ -7306,6 +8193,25 @@ func (m *ResolveRevisionRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.AmbiguousRevision = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceIndex", wireType)
+			}
+			m.SourceIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SourceIndex |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRepository(dAtA[iNdEx:])
@@ -9023,6 +9929,70 @@ func (m *RepoServerAppDetailsQuery) Unmarshal(dAtA []byte) error {
 			}
 			m.RefSources[mapkey] = mapvalue
 			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RepositoryAllowList", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RepositoryAllowList = append(m.RepositoryAllowList, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RepositoryDenyList", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RepositoryDenyList = append(m.RepositoryDenyList, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRepository(dAtA[iNdEx:])
@@ -9389,6 +10359,70 @@ func (m *RepoServerRevisionMetadataRequest) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.CheckSignature = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RepositoryAllowList", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RepositoryAllowList = append(m.RepositoryAllowList, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RepositoryDenyList", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RepositoryDenyList = append(m.RepositoryDenyList, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRepository(dAtA[iNdEx:])
@@ -9755,25 +10789,57 @@ func (m *HelmAppSpec) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRepository(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthRepository
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValuesSchema", wireType)
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ValuesSchema = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRepository(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
 	return nil
 }
@@ -11297,6 +12363,518 @@ func (m *GitDirectoriesResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ListGitPathsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRepository
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListGitPathsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListGitPathsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Repo", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Repo == nil {
+				m.Repo = &v1alpha1.Repository{}
+			}
+			if err := m.Repo.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SubmoduleEnabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SubmoduleEnabled = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Revision", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Revision = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Globs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Globs = append(m.Globs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Directories", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Directories = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxResults", wireType)
+			}
+			m.MaxResults = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxResults |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CaseInsensitive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CaseInsensitive = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SkipSymlinks", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SkipSymlinks = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRepository(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GitPathsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRepository
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GitPathsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GitPathsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Paths", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Paths = append(m.Paths, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Truncated", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Truncated = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRepository(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LoadReport) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRepository
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LoadReport: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LoadReport: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ActiveGenerations", wireType)
+			}
+			m.ActiveGenerations = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ActiveGenerations |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueueDepth", wireType)
+			}
+			m.QueueDepth = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.QueueDepth |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ParallelismLimit", wireType)
+			}
+			m.ParallelismLimit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ParallelismLimit |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DiskUsageBytes", wireType)
+			}
+			m.DiskUsageBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DiskUsageBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replica", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRepository
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRepository
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Replica = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRepository(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRepository
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRepository(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
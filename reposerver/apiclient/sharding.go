@@ -0,0 +1,282 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/git"
+	"github.com/argoproj/argo-cd/v2/util/io"
+)
+
+// ShardForRepo returns the index of the address of the repo server which owns the given repository. Repositories are
+// assigned using rendezvous hashing, so adding or removing a repo server only moves the repositories of that repo server.
+func ShardForRepo(repoURL string, addresses []string) int {
+	key := git.NormalizeGitURL(repoURL)
+	if key == "" {
+		key = repoURL
+	}
+	return shardForKey(key, addresses)
+}
+
+// shardForKey returns the index of the address with the highest rendezvous hashing score for the given key
+func shardForKey(key string, addresses []string) int {
+	shard := 0
+	var maxScore uint64
+	for i, address := range addresses {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(address))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key))
+		if score := mix(h.Sum64()); i == 0 || score > maxScore {
+			shard = i
+			maxScore = score
+		}
+	}
+	return shard
+}
+
+// mix applies the MurmurHash3 finalizer, which spreads the small differences of FNV hashes of similar inputs over all bits
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+type shardedClientset struct {
+	addresses []string
+	shards    []*clientSet
+	// next is used to distribute requests which are not related to a repository
+	next uint32
+}
+
+// NewShardedRepoServerClientset creates new instance of repo server Clientset which distributes repositories across the
+// repo servers at the given addresses, so that each repository is always handled by the same repo server
+func NewShardedRepoServerClientset(addresses []string, timeoutSeconds int, tlsConfig TLSConfiguration) Clientset {
+	c := &shardedClientset{addresses: addresses}
+	for _, address := range addresses {
+		c.shards = append(c.shards, &clientSet{address: address, timeoutSeconds: timeoutSeconds, tlsConfig: tlsConfig, remoteTLSConfig: tlsConfig})
+	}
+	return c
+}
+
+func (c *shardedClientset) NewRepoServerClient() (io.Closer, RepoServerServiceClient, error) {
+	client := &shardedClient{clientset: c, clients: map[int]RepoServerServiceClient{}}
+	return client, client, nil
+}
+
+// ForAddress returns the shard at the given address if it is one of the sharded repo servers. Otherwise, it returns a
+// Clientset for the given address derived from the shard which owns the address, so that the same shard is used for
+// each address.
+func (c *shardedClientset) ForAddress(address string) Clientset {
+	for i, a := range c.addresses {
+		if a == address {
+			return c.shards[i]
+		}
+	}
+	return c.shards[shardForKey(address, c.addresses)].ForAddress(address)
+}
+
+// shardedClient lazily connects to the repo servers which own the repositories of the requests
+type shardedClient struct {
+	clientset *shardedClientset
+	lock      sync.Mutex
+	clients   map[int]RepoServerServiceClient
+	closers   []io.Closer
+}
+
+func (c *shardedClient) shard(i int) (RepoServerServiceClient, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if client, ok := c.clients[i]; ok {
+		return client, nil
+	}
+	closer, client, err := c.clientset.shards[i].NewRepoServerClient()
+	if err != nil {
+		return nil, err
+	}
+	c.clients[i] = client
+	c.closers = append(c.closers, closer)
+	return client, nil
+}
+
+func (c *shardedClient) forRepo(repo *v1alpha1.Repository) (RepoServerServiceClient, error) {
+	if repo == nil {
+		return c.any()
+	}
+	return c.shard(ShardForRepo(repo.Repo, c.clientset.addresses))
+}
+
+func (c *shardedClient) any() (RepoServerServiceClient, error) {
+	next := atomic.AddUint32(&c.clientset.next, 1)
+	return c.shard(int(next % uint32(len(c.clientset.shards))))
+}
+
+func (c *shardedClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var errs []string
+	for _, closer := range c.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	c.closers = nil
+	c.clients = map[int]RepoServerServiceClient{}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close repo server connections: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (c *shardedClient) GenerateManifest(ctx context.Context, in *ManifestRequest, opts ...grpc.CallOption) (*ManifestResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GenerateManifest(ctx, in, opts...)
+}
+
+// GenerateManifestWithFiles generates manifests of files sent by the client, which don't depend on the repository cache
+// of a repo server, so the request is handled by any repo server.
+func (c *shardedClient) GenerateManifestWithFiles(ctx context.Context, opts ...grpc.CallOption) (RepoServerService_GenerateManifestWithFilesClient, error) {
+	client, err := c.any()
+	if err != nil {
+		return nil, err
+	}
+	return client.GenerateManifestWithFiles(ctx, opts...)
+}
+
+func (c *shardedClient) TestRepository(ctx context.Context, in *TestRepositoryRequest, opts ...grpc.CallOption) (*TestRepositoryResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.TestRepository(ctx, in, opts...)
+}
+
+func (c *shardedClient) ResolveRevision(ctx context.Context, in *ResolveRevisionRequest, opts ...grpc.CallOption) (*ResolveRevisionResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.ResolveRevision(ctx, in, opts...)
+}
+
+func (c *shardedClient) ListRefs(ctx context.Context, in *ListRefsRequest, opts ...grpc.CallOption) (*Refs, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.ListRefs(ctx, in, opts...)
+}
+
+func (c *shardedClient) ListApps(ctx context.Context, in *ListAppsRequest, opts ...grpc.CallOption) (*AppList, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.ListApps(ctx, in, opts...)
+}
+
+func (c *shardedClient) ListPlugins(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PluginList, error) {
+	client, err := c.any()
+	if err != nil {
+		return nil, err
+	}
+	return client.ListPlugins(ctx, in, opts...)
+}
+
+func (c *shardedClient) GetAppDetails(ctx context.Context, in *RepoServerAppDetailsQuery, opts ...grpc.CallOption) (*RepoAppDetailsResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GetAppDetails(ctx, in, opts...)
+}
+
+func (c *shardedClient) GetRevisionMetadata(ctx context.Context, in *RepoServerRevisionMetadataRequest, opts ...grpc.CallOption) (*v1alpha1.RevisionMetadata, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GetRevisionMetadata(ctx, in, opts...)
+}
+
+func (c *shardedClient) GetRevisionChartDetails(ctx context.Context, in *RepoServerRevisionChartDetailsRequest, opts ...grpc.CallOption) (*v1alpha1.ChartDetails, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GetRevisionChartDetails(ctx, in, opts...)
+}
+
+func (c *shardedClient) GetHelmCharts(ctx context.Context, in *HelmChartsRequest, opts ...grpc.CallOption) (*HelmChartsResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GetHelmCharts(ctx, in, opts...)
+}
+
+func (c *shardedClient) GetGitFiles(ctx context.Context, in *GitFilesRequest, opts ...grpc.CallOption) (*GitFilesResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GetGitFiles(ctx, in, opts...)
+}
+
+func (c *shardedClient) GetGitDirectories(ctx context.Context, in *GitDirectoriesRequest, opts ...grpc.CallOption) (*GitDirectoriesResponse, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.GetGitDirectories(ctx, in, opts...)
+}
+
+func (c *shardedClient) ListGitPaths(ctx context.Context, in *ListGitPathsRequest, opts ...grpc.CallOption) (RepoServerService_ListGitPathsClient, error) {
+	client, err := c.forRepo(in.GetRepo())
+	if err != nil {
+		return nil, err
+	}
+	return client.ListGitPaths(ctx, in, opts...)
+}
+
+// GetLoadReport returns the sum of the load reports of all repo servers
+func (c *shardedClient) GetLoadReport(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LoadReport, error) {
+	res := &LoadReport{}
+	unlimited := false
+	for i := range c.clientset.shards {
+		client, err := c.shard(i)
+		if err != nil {
+			return nil, err
+		}
+		report, err := client.GetLoadReport(ctx, in, opts...)
+		if err != nil {
+			return nil, status.Errorf(status.Code(err), "failed to get load report of repo server %s: %v", c.clientset.addresses[i], err)
+		}
+		res.ActiveGenerations += report.ActiveGenerations
+		res.QueueDepth += report.QueueDepth
+		res.DiskUsageBytes += report.DiskUsageBytes
+		if report.ParallelismLimit == 0 {
+			unlimited = true
+		}
+		res.ParallelismLimit += report.ParallelismLimit
+	}
+	if unlimited {
+		res.ParallelismLimit = 0
+	}
+	return res, nil
+}
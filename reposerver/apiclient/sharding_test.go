@@ -0,0 +1,80 @@
+package apiclient
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardForRepo(t *testing.T) {
+	addresses := []string{"argocd-repo-server-0:8081", "argocd-repo-server-1:8081", "argocd-repo-server-2:8081"}
+
+	t.Run("NormalizedURL", func(t *testing.T) {
+		assert.Equal(t,
+			ShardForRepo("https://github.com/argoproj/argocd-example-apps", addresses),
+			ShardForRepo("https://GitHub.com/argoproj/argocd-example-apps.git", addresses))
+	})
+
+	t.Run("Distribution", func(t *testing.T) {
+		counts := make([]int, len(addresses))
+		for i := 0; i < 300; i++ {
+			counts[ShardForRepo(fmt.Sprintf("https://github.com/org/repo-%d", i), addresses)]++
+		}
+		for _, count := range counts {
+			assert.Greater(t, count, 50)
+		}
+	})
+
+	t.Run("RemovedRepoServer", func(t *testing.T) {
+		remaining := addresses[:2]
+		for i := 0; i < 100; i++ {
+			repo := fmt.Sprintf("https://github.com/org/repo-%d", i)
+			// only the repositories of the removed repo server are moved
+			if shard := ShardForRepo(repo, addresses); shard < 2 {
+				assert.Equal(t, shard, ShardForRepo(repo, remaining))
+			}
+		}
+	})
+}
+
+func TestNewRepoServerClientset(t *testing.T) {
+	assert.IsType(t, &clientSet{}, NewRepoServerClientset("argocd-repo-server:8081", 60, TLSConfiguration{}))
+	assert.IsType(t, &shardedClientset{}, NewRepoServerClientset("argocd-repo-server-0:8081,argocd-repo-server-1:8081", 60, TLSConfiguration{}))
+}
+
+func TestForAddress(t *testing.T) {
+	tlsConfig := TLSConfiguration{StrictValidation: true}
+
+	t.Run("Single", func(t *testing.T) {
+		clientset := NewRepoServerClientset("argocd-repo-server:8081", 60, tlsConfig).(RemoteClientset).ForAddress("argocd-repo-server.edge-1:8081")
+		assert.Equal(t, &clientSet{address: "argocd-repo-server.edge-1:8081", timeoutSeconds: 60, tlsConfig: tlsConfig, remoteTLSConfig: tlsConfig}, clientset)
+	})
+
+	t.Run("Sharded", func(t *testing.T) {
+		clientset := NewRepoServerClientset("argocd-repo-server-0:8081,argocd-repo-server-1:8081", 60, tlsConfig).(RemoteClientset).ForAddress("argocd-repo-server.edge-1:8081")
+		assert.Equal(t, &clientSet{address: "argocd-repo-server.edge-1:8081", timeoutSeconds: 60, tlsConfig: tlsConfig, remoteTLSConfig: tlsConfig}, clientset)
+	})
+
+	t.Run("ShardedOwnAddress", func(t *testing.T) {
+		sharded := NewRepoServerClientset("argocd-repo-server-0:8081,argocd-repo-server-1:8081", 60, tlsConfig).(*shardedClientset)
+		assert.Same(t, sharded.shards[1], sharded.ForAddress("argocd-repo-server-1:8081"))
+	})
+
+	t.Run("ShardedConsistentShard", func(t *testing.T) {
+		addresses := []string{"argocd-repo-server-0:8081", "argocd-repo-server-1:8081", "argocd-repo-server-2:8081"}
+		sharded := NewShardedRepoServerClientset(addresses, 60, tlsConfig).(*shardedClientset)
+		for i := 0; i < 10; i++ {
+			address := fmt.Sprintf("argocd-repo-server.edge-%d:8081", i)
+			shard := shardForKey(address, addresses)
+			sharded.shards[shard].timeoutSeconds = 60 + shard
+			assert.Equal(t, 60+shard, sharded.ForAddress(address).(*clientSet).timeoutSeconds)
+			sharded.shards[shard].timeoutSeconds = 60
+		}
+	})
+
+	t.Run("InMemory", func(t *testing.T) {
+		clientset := NewInMemoryRepoServerClientset(nil, 60).(RemoteClientset).ForAddress("argocd-repo-server.edge-1:8081")
+		assert.Equal(t, TLSConfiguration{StrictValidation: true}, clientset.(*clientSet).tlsConfig)
+	})
+}
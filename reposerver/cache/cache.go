@@ -304,8 +304,12 @@ func (c *Cache) SetRevisionMetadata(repoURL, revision string, item *appv1.Revisi
 	return c.cache.SetItem(revisionMetadataKey(repoURL, revision), item, c.repoCacheExpiration, false)
 }
 
+// revisionChartDetailsKeyVersion is the version of the cached chart details, which must be incremented whenever fields
+// are added to them, so that chart details cached without the fields aren't used
+const revisionChartDetailsKeyVersion = 2
+
 func revisionChartDetailsKey(repoURL, chart, revision string) string {
-	return fmt.Sprintf("chartdetails|%s|%s|%s", repoURL, chart, revision)
+	return fmt.Sprintf("chartdetails|v%d|%s|%s|%s", revisionChartDetailsKeyVersion, repoURL, chart, revision)
 }
 
 func (c *Cache) GetRevisionChartDetails(repoURL, chart, revision string) (*appv1.ChartDetails, error) {
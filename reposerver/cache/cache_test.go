@@ -47,6 +47,21 @@ func TestCache_GetRevisionMetadata(t *testing.T) {
 	assert.Equal(t, &RevisionMetadata{Message: "my-message"}, value)
 }
 
+func TestCache_GetRevisionChartDetails(t *testing.T) {
+	cache := newFixtures().Cache
+	// chart details cached by a previous version don't have all fields
+	err := cache.cache.SetItem("chartdetails|my-repo-url|my-chart|1.0.0", &ChartDetails{Description: "old"}, time.Minute, false)
+	assert.NoError(t, err)
+	_, err = cache.GetRevisionChartDetails("my-repo-url", "my-chart", "1.0.0")
+	assert.Equal(t, ErrCacheMiss, err)
+	// populate cache
+	err = cache.SetRevisionChartDetails("my-repo-url", "my-chart", "1.0.0", &ChartDetails{Description: "new", Digest: "sha256:abc"})
+	assert.NoError(t, err)
+	value, err := cache.GetRevisionChartDetails("my-repo-url", "my-chart", "1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &ChartDetails{Description: "new", Digest: "sha256:abc"}, value)
+}
+
 func TestCache_ListApps(t *testing.T) {
 	cache := newFixtures().Cache
 	// cache miss
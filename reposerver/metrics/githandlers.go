@@ -23,5 +23,12 @@ func NewGitClientEventHandlers(metricsServer *MetricsServer) git.EventHandlers {
 				metricsServer.ObserveGitRequestDuration(repo, GitRequestTypeLsRemote, time.Since(startTime))
 			}
 		},
+		OnCheckout: func(repo string) func() {
+			startTime := time.Now()
+			metricsServer.IncGitRequest(repo, GitRequestTypeCheckout)
+			return func() {
+				metricsServer.ObserveGitRequestDuration(repo, GitRequestTypeCheckout, time.Since(startTime))
+			}
+		},
 	}
 }
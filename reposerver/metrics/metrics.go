@@ -15,6 +15,14 @@ type MetricsServer struct {
 	gitRequestCounter        *prometheus.CounterVec
 	gitRequestHistogram      *prometheus.HistogramVec
 	repoPendingRequestsGauge *prometheus.GaugeVec
+	activeOperationsGauge    prometheus.Gauge
+	queuedOperationsGauge    prometheus.Gauge
+	parallelismLimitGauge    prometheus.Gauge
+	toolRateLimitedCounter   *prometheus.CounterVec
+	toolRateLimitHistogram   *prometheus.HistogramVec
+	toolExecutionCounter     *prometheus.CounterVec
+	toolExecutionHistogram   *prometheus.HistogramVec
+	cacheRequestCounter      *prometheus.CounterVec
 	redisRequestCounter      *prometheus.CounterVec
 	redisRequestHistogram    *prometheus.HistogramVec
 }
@@ -24,6 +32,15 @@ type GitRequestType string
 const (
 	GitRequestTypeLsRemote = "ls-remote"
 	GitRequestTypeFetch    = "fetch"
+	GitRequestTypeCheckout = "checkout"
+)
+
+// Types of the cached repo-server responses
+const (
+	CacheTypeManifests        = "manifests"
+	CacheTypeAppDetails       = "app-details"
+	CacheTypeRevisionMetadata = "revision-metadata"
+	CacheTypeApps             = "apps"
 )
 
 // NewMetricsServer returns a new prometheus server which collects application metrics.
@@ -60,6 +77,77 @@ func NewMetricsServer() *MetricsServer {
 	)
 	registry.MustRegister(repoPendingRequestsGauge)
 
+	activeOperationsGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "argocd_repo_active_operations",
+			Help: "Number of repository operations which are currently running",
+		},
+	)
+	registry.MustRegister(activeOperationsGauge)
+
+	queuedOperationsGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "argocd_repo_queued_operations",
+			Help: "Number of repository operations which are waiting for the parallelism limit",
+		},
+	)
+	registry.MustRegister(queuedOperationsGauge)
+
+	parallelismLimitGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "argocd_repo_parallelism_limit",
+			Help: "Maximum number of concurrent manifest generations, or 0 if unlimited",
+		},
+	)
+	registry.MustRegister(parallelismLimitGauge)
+
+	toolRateLimitedCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "argocd_repo_tool_rate_limited_total",
+			Help: "Number of executions of external tools which were delayed by the repository or the global rate limit",
+		},
+		[]string{"repo", "tool", "limit"},
+	)
+	registry.MustRegister(toolRateLimitedCounter)
+
+	toolRateLimitHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "argocd_repo_tool_rate_limit_wait_duration_seconds",
+			Help:    "Time executions of external tools waited for the rate limits.",
+			Buckets: []float64{0.1, 0.25, .5, 1, 2, 4, 10, 20},
+		},
+		[]string{"tool"},
+	)
+	registry.MustRegister(toolRateLimitHistogram)
+
+	toolExecutionCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "argocd_repo_tool_execution_total",
+			Help: "Number of manifest generations by helm, kustomize or config management plugins",
+		},
+		[]string{"repo", "tool", "failed"},
+	)
+	registry.MustRegister(toolExecutionCounter)
+
+	toolExecutionHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "argocd_repo_tool_execution_duration_seconds",
+			Help:    "Manifest generation duration seconds of helm, kustomize or config management plugins.",
+			Buckets: []float64{0.1, 0.25, .5, 1, 2, 4, 10, 20, 60},
+		},
+		[]string{"repo", "tool"},
+	)
+	registry.MustRegister(toolExecutionHistogram)
+
+	cacheRequestCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "argocd_repo_cache_request_total",
+			Help: "Number of lookups of cached repo server responses",
+		},
+		[]string{"repo", "cache_type", "hit"},
+	)
+	registry.MustRegister(cacheRequestCounter)
+
 	redisRequestCounter := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "argocd_redis_request_total",
@@ -84,6 +172,14 @@ func NewMetricsServer() *MetricsServer {
 		gitRequestCounter:        gitRequestCounter,
 		gitRequestHistogram:      gitRequestHistogram,
 		repoPendingRequestsGauge: repoPendingRequestsGauge,
+		activeOperationsGauge:    activeOperationsGauge,
+		queuedOperationsGauge:    queuedOperationsGauge,
+		parallelismLimitGauge:    parallelismLimitGauge,
+		toolRateLimitedCounter:   toolRateLimitedCounter,
+		toolRateLimitHistogram:   toolRateLimitHistogram,
+		toolExecutionCounter:     toolExecutionCounter,
+		toolExecutionHistogram:   toolExecutionHistogram,
+		cacheRequestCounter:      cacheRequestCounter,
 		redisRequestCounter:      redisRequestCounter,
 		redisRequestHistogram:    redisRequestHistogram,
 	}
@@ -110,6 +206,50 @@ func (m *MetricsServer) DecPendingRepoRequest(repo string) {
 	m.repoPendingRequestsGauge.WithLabelValues(repo).Dec()
 }
 
+func (m *MetricsServer) IncActiveOperations() {
+	m.activeOperationsGauge.Inc()
+}
+
+func (m *MetricsServer) DecActiveOperations() {
+	m.activeOperationsGauge.Dec()
+}
+
+func (m *MetricsServer) IncQueuedOperations() {
+	m.queuedOperationsGauge.Inc()
+}
+
+func (m *MetricsServer) DecQueuedOperations() {
+	m.queuedOperationsGauge.Dec()
+}
+
+// SetParallelismLimit sets the maximum number of concurrent manifest generations
+func (m *MetricsServer) SetParallelismLimit(limit int64) {
+	m.parallelismLimitGauge.Set(float64(limit))
+}
+
+// IncToolRateLimited increments the number of executions of the given tool for the given repository which were delayed
+// by the given rate limit
+func (m *MetricsServer) IncToolRateLimited(repo string, tool string, limit string) {
+	m.toolRateLimitedCounter.WithLabelValues(repo, tool, limit).Inc()
+}
+
+// ObserveToolRateLimitWaitDuration observes the time an execution of the given tool waited for the rate limits
+func (m *MetricsServer) ObserveToolRateLimitWaitDuration(tool string, duration time.Duration) {
+	m.toolRateLimitHistogram.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// ObserveToolExecution counts an execution of the given manifest generation tool for the given repository and observes
+// its duration
+func (m *MetricsServer) ObserveToolExecution(repo string, tool string, failed bool, duration time.Duration) {
+	m.toolExecutionCounter.WithLabelValues(repo, tool, strconv.FormatBool(failed)).Inc()
+	m.toolExecutionHistogram.WithLabelValues(repo, tool).Observe(duration.Seconds())
+}
+
+// IncCacheRequest increments the number of lookups of the given cache type for the given repository
+func (m *MetricsServer) IncCacheRequest(repo string, cacheType string, hit bool) {
+	m.cacheRequestCounter.WithLabelValues(repo, cacheType, strconv.FormatBool(hit)).Inc()
+}
+
 func (m *MetricsServer) IncRedisRequest(failed bool) {
 	m.redisRequestCounter.WithLabelValues("argocd-repo-server", strconv.FormatBool(failed)).Inc()
 }
@@ -13,6 +13,7 @@ func getChartDetails(chartYAML string) (*v1alpha1.ChartDetails, error) {
 	var chart struct {
 		Description string `yaml:"description,omitempty"`
 		Home        string `yaml:"home,omitempty"`
+		AppVersion  string `yaml:"appVersion,omitempty"`
 		Maintainers []struct {
 			Name  string `yaml:"name,omitempty"`
 			Email string `yaml:"email,omitempty"`
@@ -35,5 +36,6 @@ func getChartDetails(chartYAML string) (*v1alpha1.ChartDetails, error) {
 		Description: chart.Description,
 		Maintainers: maintainers,
 		Home:        chart.Home,
+		AppVersion:  chart.AppVersion,
 	}, nil
 }
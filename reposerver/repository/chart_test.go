@@ -16,6 +16,7 @@ version: 0.0.0`
 	assert.Equal(t, cd.Description, "")
 	assert.Equal(t, cd.Maintainers, []string(nil))
 	assert.Equal(t, cd.Home, "")
+	assert.Equal(t, cd.AppVersion, "")
 }
 
 func Test_getChartDetailsSet(t *testing.T) {
@@ -24,6 +25,7 @@ name: mychart
 version: 0.0.0
 description: a good chart
 home: https://example.com
+appVersion: 1.16.0
 maintainers:
 - name: alex
   email: example@example.com
@@ -34,6 +36,7 @@ maintainers:
 	assert.Equal(t, cd.Description, "a good chart")
 	assert.Equal(t, cd.Maintainers, []string{"alex <example@example.com>"})
 	assert.Equal(t, cd.Home, "https://example.com")
+	assert.Equal(t, cd.AppVersion, "1.16.0")
 
 	chart1 = `apiVersion: v3
 name: mychart
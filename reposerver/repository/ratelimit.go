@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/reposerver/metrics"
+	"github.com/argoproj/argo-cd/v2/util/git"
+)
+
+const (
+	toolGit       = "git"
+	toolHelm      = "helm"
+	toolKustomize = "kustomize"
+	toolPlugin    = "plugin"
+
+	rateLimitRepo   = "repo"
+	rateLimitGlobal = "global"
+)
+
+// repoLimiterEvictionInterval is the minimum interval between the evictions of the rate limiters of repositories which
+// have their full budget available again
+const repoLimiterEvictionInterval = time.Minute
+
+// ToolRateLimiter limits the rate of the executions of external tools, i.e. git, helm, kustomize and config management
+// plugins, with a token bucket per repository and a global token bucket. A repository which exhausts its own budget is
+// delayed without consuming the global budget, so that it can't starve the manifest generation of other repositories.
+type ToolRateLimiter struct {
+	global        *rate.Limiter
+	repoLimit     rate.Limit
+	repoBurst     int
+	metricsServer *metrics.MetricsServer
+
+	lock         sync.Mutex
+	repos        map[string]*rate.Limiter
+	lastEviction time.Time
+}
+
+// NewToolRateLimiter returns a rate limiter for the executions of external tools. A rate of 0 or less disables the
+// respective limit, and a burst of less than 1 defaults to the rate rounded up.
+func NewToolRateLimiter(metricsServer *metrics.MetricsServer, globalQPS float64, globalBurst int, repoQPS float64, repoBurst int) *ToolRateLimiter {
+	l := &ToolRateLimiter{metricsServer: metricsServer, repos: map[string]*rate.Limiter{}, lastEviction: time.Now()}
+	if globalQPS > 0 {
+		l.global = rate.NewLimiter(rate.Limit(globalQPS), defaultBurst(globalQPS, globalBurst))
+	}
+	if repoQPS > 0 {
+		l.repoLimit = rate.Limit(repoQPS)
+		l.repoBurst = defaultBurst(repoQPS, repoBurst)
+	}
+	return l
+}
+
+func defaultBurst(qps float64, burst int) int {
+	if burst > 0 {
+		return burst
+	}
+	if qps < 1 {
+		return 1
+	}
+	return int(qps + 0.999999)
+}
+
+func (l *ToolRateLimiter) repoLimiter(repo string) *rate.Limiter {
+	if l.repoLimit == 0 {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.evictRepoLimiters(time.Now())
+	limiter, ok := l.repos[repo]
+	if !ok {
+		limiter = rate.NewLimiter(l.repoLimit, l.repoBurst)
+		l.repos[repo] = limiter
+	}
+	return limiter
+}
+
+// evictRepoLimiters removes the rate limiters of the repositories which have their full budget available again, since
+// they behave like new ones, so that the limiters of repositories which are no longer used don't accumulate. The lock
+// must be held.
+func (l *ToolRateLimiter) evictRepoLimiters(now time.Time) {
+	if now.Sub(l.lastEviction) < repoLimiterEvictionInterval {
+		return
+	}
+	for repo, limiter := range l.repos {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(l.repos, repo)
+		}
+	}
+	l.lastEviction = now
+}
+
+// waitLimiter waits until the given limiter permits an event and returns the time it waited
+func waitLimiter(ctx context.Context, limiter *rate.Limiter) (time.Duration, error) {
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return 0, fmt.Errorf("rate limit burst of %d exceeded", limiter.Burst())
+	}
+	delay := reservation.Delay()
+	if delay == 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return 0, ctx.Err()
+	}
+}
+
+// Wait blocks until both the budget of the given repository and the global budget permit an execution of the given tool
+func (l *ToolRateLimiter) Wait(ctx context.Context, repo string, tool string) error {
+	if l == nil {
+		return nil
+	}
+	var waited time.Duration
+	for _, limit := range []struct {
+		name    string
+		limiter *rate.Limiter
+	}{
+		{rateLimitRepo, l.repoLimiter(repo)},
+		{rateLimitGlobal, l.global},
+	} {
+		if limit.limiter == nil {
+			continue
+		}
+		delay, err := waitLimiter(ctx, limit.limiter)
+		if err != nil {
+			return fmt.Errorf("error waiting for the %s rate limit of %s executions: %w", limit.name, tool, err)
+		}
+		if delay > 0 {
+			waited += delay
+			l.metricsServer.IncToolRateLimited(repo, tool, limit.name)
+		}
+	}
+	if waited > 0 {
+		l.metricsServer.ObserveToolRateLimitWaitDuration(tool, waited)
+	}
+	return nil
+}
+
+// operationTool returns the external tool which is executed by a repository operation for the given source, i.e. helm
+// for Helm charts and for git repositories the explicit type of the source, or git if its type is detected from the
+// files of the repository
+func operationTool(source *v1alpha1.ApplicationSource) string {
+	if source.IsHelm() {
+		return toolHelm
+	}
+	if appType, err := source.ExplicitType(); err == nil && appType != nil {
+		switch *appType {
+		case v1alpha1.ApplicationSourceTypeHelm:
+			return toolHelm
+		case v1alpha1.ApplicationSourceTypeKustomize:
+			return toolKustomize
+		case v1alpha1.ApplicationSourceTypePlugin:
+			return toolPlugin
+		}
+	}
+	return toolGit
+}
+
+type fetchRateLimitedKey struct{}
+
+// withFetchRateLimited returns a context which marks the fetches of the git clients created with it as already rate
+// limited by the repository operation, which waits for the rate limits before acquiring a parallelism limit slot
+func withFetchRateLimited(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fetchRateLimitedKey{}, true)
+}
+
+func isFetchRateLimited(ctx context.Context) bool {
+	limited, _ := ctx.Value(fetchRateLimitedKey{}).(bool)
+	return limited
+}
+
+// gitEventHandlers returns the given git client event handlers, extended to wait for the rate limits before fetching
+// from or listing the references of a repository. The git client doesn't pass a context to its event handlers, so the
+// waits use the context of the request the client is created for. The handlers can't fail the git command, so it is
+// executed without further delay if the request is canceled while waiting.
+func (l *ToolRateLimiter) gitEventHandlers(ctx context.Context, handlers git.EventHandlers) git.EventHandlers {
+	if l == nil {
+		return handlers
+	}
+	wrap := func(handler func(repo string) func(), limited bool) func(repo string) func() {
+		return func(repo string) func() {
+			if limited {
+				if err := l.Wait(ctx, repo, toolGit); err != nil {
+					log.Warnf("Failed to wait for the rate limits of %s: %v", repo, err)
+				}
+			}
+			if handler == nil {
+				return func() {}
+			}
+			return handler(repo)
+		}
+	}
+	handlers.OnLsRemote = wrap(handlers.OnLsRemote, true)
+	handlers.OnFetch = wrap(handlers.OnFetch, !isFetchRateLimited(ctx))
+	return handlers
+}
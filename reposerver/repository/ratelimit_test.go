@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/reposerver/metrics"
+	"github.com/argoproj/argo-cd/v2/util/git"
+)
+
+func TestToolRateLimiter(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		var limiter *ToolRateLimiter
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolHelm))
+
+		limiter = NewToolRateLimiter(metrics.NewMetricsServer(), 0, 0, 0, 0)
+		start := time.Now()
+		for i := 0; i < 100; i++ {
+			require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolHelm))
+		}
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("PerRepo", func(t *testing.T) {
+		limiter := NewToolRateLimiter(metrics.NewMetricsServer(), 0, 0, 10, 1)
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolHelm))
+
+		// another repository has its own budget
+		start := time.Now()
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argocd-example-apps", toolHelm))
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+		start = time.Now()
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolHelm))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+	t.Run("Global", func(t *testing.T) {
+		limiter := NewToolRateLimiter(metrics.NewMetricsServer(), 10, 1, 0, 0)
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolKustomize))
+
+		start := time.Now()
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argocd-example-apps", toolKustomize))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		limiter := NewToolRateLimiter(metrics.NewMetricsServer(), 0, 0, 0.1, 1)
+		require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolPlugin))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := limiter.Wait(ctx, "https://github.com/argoproj/argo-cd", toolPlugin)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestToolRateLimiter_DefaultBurst(t *testing.T) {
+	assert.Equal(t, 5, defaultBurst(2, 5))
+	assert.Equal(t, 1, defaultBurst(0.5, 0))
+	assert.Equal(t, 3, defaultBurst(2.5, 0))
+	assert.Equal(t, 2, defaultBurst(2, 0))
+}
+
+func TestToolRateLimiter_GitEventHandlers(t *testing.T) {
+	var fetched, checkedOut []string
+	handlers := git.EventHandlers{
+		OnFetch: func(repo string) func() {
+			fetched = append(fetched, repo)
+			return func() {}
+		},
+		OnCheckout: func(repo string) func() {
+			checkedOut = append(checkedOut, repo)
+			return func() {}
+		},
+	}
+
+	var limiter *ToolRateLimiter
+	assert.NotNil(t, limiter.gitEventHandlers(context.Background(), handlers).OnFetch)
+
+	limiter = NewToolRateLimiter(metrics.NewMetricsServer(), 0, 0, 10, 1)
+	fetchLimitedHandlers := limiter.gitEventHandlers(withFetchRateLimited(context.Background()), handlers)
+	handlers = limiter.gitEventHandlers(context.Background(), handlers)
+	handlers.OnFetch("https://github.com/argoproj/argo-cd")()
+	handlers.OnLsRemote("https://github.com/argoproj/argo-cd")()
+
+	start := time.Now()
+	handlers.OnFetch("https://github.com/argoproj/argo-cd")()
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []string{"https://github.com/argoproj/argo-cd", "https://github.com/argoproj/argo-cd"}, fetched)
+
+	// checkouts are not rate limited
+	start = time.Now()
+	handlers.OnCheckout("https://github.com/argoproj/argo-cd")()
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []string{"https://github.com/argoproj/argo-cd"}, checkedOut)
+
+	// fetches which were rate limited by the repository operation don't wait again
+	start = time.Now()
+	fetchLimitedHandlers.OnFetch("https://github.com/argoproj/argo-cd")()
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestToolRateLimiter_EvictRepoLimiters(t *testing.T) {
+	limiter := NewToolRateLimiter(metrics.NewMetricsServer(), 0, 0, 10, 1)
+	require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argo-cd", toolGit))
+	require.NoError(t, limiter.Wait(context.Background(), "https://github.com/argoproj/argocd-example-apps", toolGit))
+	assert.Len(t, limiter.repos, 2)
+
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	// the budgets are not evicted more often than the eviction interval
+	limiter.evictRepoLimiters(time.Now())
+	assert.Len(t, limiter.repos, 2)
+
+	// the budgets which are full again are evicted
+	limiter.evictRepoLimiters(time.Now().Add(repoLimiterEvictionInterval))
+	assert.Empty(t, limiter.repos)
+}
+
+func TestOperationTool(t *testing.T) {
+	assert.Equal(t, toolHelm, operationTool(&v1alpha1.ApplicationSource{Chart: "my-chart"}))
+	assert.Equal(t, toolHelm, operationTool(&v1alpha1.ApplicationSource{Path: "my-chart", Helm: &v1alpha1.ApplicationSourceHelm{}}))
+	assert.Equal(t, toolKustomize, operationTool(&v1alpha1.ApplicationSource{Path: "overlay", Kustomize: &v1alpha1.ApplicationSourceKustomize{}}))
+	assert.Equal(t, toolPlugin, operationTool(&v1alpha1.ApplicationSource{Path: "app", Plugin: &v1alpha1.ApplicationSourcePlugin{}}))
+	assert.Equal(t, toolGit, operationTool(&v1alpha1.ApplicationSource{Path: "app"}))
+}
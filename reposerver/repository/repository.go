@@ -14,6 +14,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	gosync "sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
@@ -31,6 +33,7 @@ import (
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	textutils "github.com/argoproj/gitops-engine/pkg/utils/text"
 	"github.com/argoproj/pkg/sync"
+	"github.com/bmatcuk/doublestar/v4"
 	jsonpatch "github.com/evanphx/json-patch"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/google/go-jsonnet"
@@ -55,6 +58,7 @@ import (
 	argopath "github.com/argoproj/argo-cd/v2/util/app/path"
 	"github.com/argoproj/argo-cd/v2/util/argo"
 	"github.com/argoproj/argo-cd/v2/util/cmp"
+	executil "github.com/argoproj/argo-cd/v2/util/exec"
 	"github.com/argoproj/argo-cd/v2/util/git"
 	"github.com/argoproj/argo-cd/v2/util/glob"
 	"github.com/argoproj/argo-cd/v2/util/gpg"
@@ -63,6 +67,7 @@ import (
 	"github.com/argoproj/argo-cd/v2/util/io"
 	pathutil "github.com/argoproj/argo-cd/v2/util/io/path"
 	"github.com/argoproj/argo-cd/v2/util/kustomize"
+	"github.com/argoproj/argo-cd/v2/util/settings"
 	"github.com/argoproj/argo-cd/v2/util/text"
 )
 
@@ -73,6 +78,10 @@ const (
 	repoSourceFile                 = ".argocd-source.yaml"
 	appSourceFile                  = ".argocd-source-%s.yaml"
 	ociPrefix                      = "oci://"
+	// diskUsageRefreshInterval is the interval in which the disk usage of load reports is recomputed
+	diskUsageRefreshInterval = time.Minute
+	// maxRemoteValueFileSize is the maximum size of a remote value file downloaded by the repo server
+	maxRemoteValueFileSize = 10 * 1024 * 1024
 )
 
 var ErrExceededMaxCombinedManifestFileSize = errors.New("exceeded max combined manifest file size")
@@ -87,13 +96,29 @@ type Service struct {
 	repoLock                  *repositoryLock
 	cache                     *reposervercache.Cache
 	parallelismLimitSemaphore *semaphore.Weighted
+	toolRateLimiter           *ToolRateLimiter
 	metricsServer             *metrics.MetricsServer
 	resourceTracking          argo.ResourceTracking
 	newGitClient              func(rawRepoURL string, root string, creds git.Creds, insecure bool, enableLfs bool, proxy string, opts ...git.ClientOpts) (git.Client, error)
 	newHelmClient             func(repoURL string, creds helm.Creds, enableOci bool, proxy string, opts ...helm.ClientOpts) helm.Client
 	initConstants             RepoServerInitConstants
+	// activeOperations is the number of repository operations which are currently running
+	activeOperations int64
+	// queuedOperations is the number of repository operations which are waiting for the parallelism limit
+	queuedOperations int64
 	// now is usually just time.Now, but may be replaced by unit tests for testing purposes
 	now func() time.Time
+	// diskUsage is the disk usage reported by load reports, which is recomputed in the background at most once per
+	// disk usage refresh interval, since it walks all checked out repositories and downloaded charts
+	diskUsage diskUsage
+}
+
+// diskUsage is the disk space used by checked out repositories and downloaded charts when it was last computed
+type diskUsage struct {
+	lock       gosync.Mutex
+	bytes      int64
+	computedAt time.Time
+	refreshing bool
 }
 
 type RepoServerInitConstants struct {
@@ -107,6 +132,16 @@ type RepoServerInitConstants struct {
 	AllowOutOfBoundsSymlinks                     bool
 	StreamedManifestMaxExtractedSize             int64
 	StreamedManifestMaxTarSize                   int64
+	SparseCheckoutEnabled                        bool
+	// ToolRateLimitQPS is the global rate of executions of external tools, or 0 if unlimited
+	ToolRateLimitQPS   float64
+	ToolRateLimitBurst int
+	// ToolRateLimitPerRepoQPS is the rate of executions of external tools per repository, or 0 if unlimited
+	ToolRateLimitPerRepoQPS   float64
+	ToolRateLimitPerRepoBurst int
+	// GitReferenceCachePath is the directory of the bare mirrors sharing their objects with the working copies of the
+	// repositories, or empty if disabled
+	GitReferenceCachePath string
 }
 
 // NewService returns a new instance of the Manifest service
@@ -115,11 +150,13 @@ func NewService(metricsServer *metrics.MetricsServer, cache *reposervercache.Cac
 	if initConstants.ParallelismLimit > 0 {
 		parallelismLimitSemaphore = semaphore.NewWeighted(initConstants.ParallelismLimit)
 	}
+	metricsServer.SetParallelismLimit(initConstants.ParallelismLimit)
 	repoLock := NewRepositoryLock()
 	gitRandomizedPaths := io.NewRandomizedTempPaths(rootDir)
 	helmRandomizedPaths := io.NewRandomizedTempPaths(rootDir)
 	return &Service{
 		parallelismLimitSemaphore: parallelismLimitSemaphore,
+		toolRateLimiter:           NewToolRateLimiter(metricsServer, initConstants.ToolRateLimitQPS, initConstants.ToolRateLimitBurst, initConstants.ToolRateLimitPerRepoQPS, initConstants.ToolRateLimitPerRepoBurst),
 		repoLock:                  repoLock,
 		cache:                     cache,
 		metricsServer:             metricsServer,
@@ -175,7 +212,7 @@ func (s *Service) Init() error {
 
 // List a subset of the refs (currently, branches and tags) of a git repo
 func (s *Service) ListRefs(ctx context.Context, q *apiclient.ListRefsRequest) (*apiclient.Refs, error) {
-	gitClient, err := s.newClient(q.Repo)
+	gitClient, err := s.newClient(ctx, q.Repo)
 	if err != nil {
 		return nil, fmt.Errorf("error creating git client: %w", err)
 	}
@@ -198,20 +235,22 @@ func (s *Service) ListRefs(ctx context.Context, q *apiclient.ListRefsRequest) (*
 
 // ListApps lists the contents of a GitHub repo
 func (s *Service) ListApps(ctx context.Context, q *apiclient.ListAppsRequest) (*apiclient.AppList, error) {
-	gitClient, commitSHA, err := s.newClientResolveRevision(q.Repo, q.Revision)
+	gitClient, commitSHA, err := s.newClientResolveRevision(ctx, q.Repo, q.Revision)
 	if err != nil {
 		return nil, err
 	}
 	if apps, err := s.cache.ListApps(q.Repo.Repo, commitSHA); err == nil {
 		log.Infof("cache hit: %s/%s", q.Repo.Repo, q.Revision)
+		s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeApps, true)
 		return &apiclient.AppList{Apps: apps}, nil
 	}
+	s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeApps, false)
 
 	s.metricsServer.IncPendingRepoRequest(q.Repo.Repo)
 	defer s.metricsServer.DecPendingRepoRequest(q.Repo.Repo)
 
 	closer, err := s.repoLock.Lock(gitClient.Root(), commitSHA, true, func() (goio.Closer, error) {
-		return s.checkoutRevision(gitClient, commitSHA, s.initConstants.SubmoduleEnabled)
+		return s.checkoutRevision(gitClient, commitSHA, q.Repo.IsSubmoduleEnabled(s.initConstants.SubmoduleEnabled))
 	})
 
 	if err != nil {
@@ -251,6 +290,101 @@ func (s *Service) ListPlugins(ctx context.Context, _ *empty.Empty) (*apiclient.P
 	return &res, nil
 }
 
+// GetLoadReport returns the number of running and queued repository operations as well as the disk space used by
+// checked out repositories and downloaded charts
+func (s *Service) GetLoadReport(ctx context.Context, _ *empty.Empty) (*apiclient.LoadReport, error) {
+	diskUsage, err := s.getDiskUsage()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to compute disk usage: %v", err)
+	}
+	// the hostname is the name of the pod of the replica
+	replica, err := os.Hostname()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get hostname: %v", err)
+	}
+	return &apiclient.LoadReport{
+		ActiveGenerations: atomic.LoadInt64(&s.activeOperations),
+		QueueDepth:        atomic.LoadInt64(&s.queuedOperations),
+		ParallelismLimit:  s.initConstants.ParallelismLimit,
+		DiskUsageBytes:    diskUsage,
+		Replica:           replica,
+	}, nil
+}
+
+// getDiskUsage returns the disk usage which was computed last. It is computed when it is requested for the first time,
+// and recomputed in the background once it is older than the disk usage refresh interval, so that load reports
+// neither walk all checked out repositories on every poll nor wait for it.
+func (s *Service) getDiskUsage() (int64, error) {
+	s.diskUsage.lock.Lock()
+	defer s.diskUsage.lock.Unlock()
+	if s.diskUsage.computedAt.IsZero() {
+		bytes, err := s.computeDiskUsage()
+		if err != nil {
+			return 0, err
+		}
+		s.diskUsage.bytes = bytes
+		s.diskUsage.computedAt = s.now()
+	} else if s.now().Sub(s.diskUsage.computedAt) > diskUsageRefreshInterval && !s.diskUsage.refreshing {
+		s.diskUsage.refreshing = true
+		go func() {
+			bytes, err := s.computeDiskUsage()
+			s.diskUsage.lock.Lock()
+			defer s.diskUsage.lock.Unlock()
+			if err != nil {
+				log.Warnf("Failed to compute disk usage: %v", err)
+			} else {
+				s.diskUsage.bytes = bytes
+			}
+			// failures are retried after the refresh interval as well, so that they aren't retried on every poll
+			s.diskUsage.computedAt = s.now()
+			s.diskUsage.refreshing = false
+		}()
+	}
+	return s.diskUsage.bytes, nil
+}
+
+// computeDiskUsage returns the disk space used by checked out repositories and downloaded charts
+func (s *Service) computeDiskUsage() (int64, error) {
+	var diskUsage int64
+	for _, paths := range []io.TempPaths{s.gitRepoPaths, s.chartPaths} {
+		for _, dir := range paths.GetPaths() {
+			size, err := directorySize(dir)
+			if err != nil {
+				return 0, err
+			}
+			diskUsage += size
+		}
+	}
+	return diskUsage, nil
+}
+
+// directorySize returns the total size of the regular files in the given directory. Files which are removed while
+// the directory is being walked are ignored.
+func directorySize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
 type operationSettings struct {
 	sem             *semaphore.Weighted
 	noCache         bool
@@ -298,6 +432,7 @@ func (s *Service) runRepoOperation(
 
 	var gitClient git.Client
 	var helmClient helm.Client
+	var sparsePaths []string
 	var err error
 	revision = textutils.FirstNonEmpty(revision, source.TargetRevision)
 	unresolvedRevision := revision
@@ -307,13 +442,19 @@ func (s *Service) runRepoOperation(
 			return err
 		}
 	} else {
-		gitClient, revision, err = s.newClientResolveRevision(repo, revision, git.WithCache(s.cache, !settings.noRevisionCache && !settings.noCache))
+		gitOpts := []git.ClientOpts{git.WithCache(s.cache, !settings.noRevisionCache && !settings.noCache)}
+		if s.initConstants.SparseCheckoutEnabled {
+			sparsePaths = getSparseCheckoutPaths(source, refSources)
+			gitOpts = append(gitOpts, git.WithSparsePaths(sparsePaths), git.WithSparsePathDependencies(getSparseCheckoutDependencies))
+		}
+		// the repository is fetched by every operation, which waits for the rate limits before acquiring a slot
+		gitClient, revision, err = s.newClientResolveRevision(withFetchRateLimited(ctx), repo, revision, gitOpts...)
 		if err != nil {
 			return err
 		}
 	}
 
-	repoRefs, err := resolveReferencedSources(hasMultipleSources, source.Helm, refSources, s.newClientResolveRevision)
+	repoRefs, err := resolveReferencedSources(ctx, hasMultipleSources, source.Helm, refSources, s.newClientResolveRevision)
 	if err != nil {
 		return err
 	}
@@ -327,13 +468,28 @@ func (s *Service) runRepoOperation(
 	s.metricsServer.IncPendingRepoRequest(repo.Repo)
 	defer s.metricsServer.DecPendingRepoRequest(repo.Repo)
 
+	// wait for the rate limits before acquiring a slot, so that a rate limited repository doesn't keep the workers busy
+	if err := s.toolRateLimiter.Wait(ctx, repo.Repo, operationTool(source)); err != nil {
+		return err
+	}
+
 	if settings.sem != nil {
+		atomic.AddInt64(&s.queuedOperations, 1)
+		s.metricsServer.IncQueuedOperations()
 		err = settings.sem.Acquire(ctx, 1)
+		atomic.AddInt64(&s.queuedOperations, -1)
+		s.metricsServer.DecQueuedOperations()
 		if err != nil {
 			return err
 		}
 		defer settings.sem.Release(1)
 	}
+	atomic.AddInt64(&s.activeOperations, 1)
+	s.metricsServer.IncActiveOperations()
+	defer func() {
+		atomic.AddInt64(&s.activeOperations, -1)
+		s.metricsServer.DecActiveOperations()
+	}()
 
 	if source.IsHelm() {
 		if settings.noCache {
@@ -372,8 +528,14 @@ func (s *Service) runRepoOperation(
 			return &operationContext{chartPath, ""}, nil
 		})
 	} else {
-		closer, err := s.repoLock.Lock(gitClient.Root(), revision, settings.allowConcurrent, func() (goio.Closer, error) {
-			return s.checkoutRevision(gitClient, revision, s.initConstants.SubmoduleEnabled)
+		// the working tree of a sparse checkout only contains the paths of this source, so it can only be shared with
+		// operations requiring the same paths
+		lockKey := revision
+		if len(sparsePaths) > 0 {
+			lockKey = revision + ":" + strings.Join(sparsePaths, ":")
+		}
+		closer, err := s.repoLock.Lock(gitClient.Root(), lockKey, settings.allowConcurrent, func() (goio.Closer, error) {
+			return s.checkoutRevision(gitClient, revision, repo.IsSubmoduleEnabled(s.initConstants.SubmoduleEnabled))
 		})
 
 		if err != nil {
@@ -440,14 +602,14 @@ func getRepoSanitizerRegex(rootDir string) *regexp.Regexp {
 	return regexp.MustCompile(regexp.QuoteMeta(rootDir) + `/[^ /]*`)
 }
 
-type gitClientGetter func(repo *v1alpha1.Repository, revision string, opts ...git.ClientOpts) (git.Client, string, error)
+type gitClientGetter func(ctx context.Context, repo *v1alpha1.Repository, revision string, opts ...git.ClientOpts) (git.Client, string, error)
 
 // resolveReferencedSources resolves the revisions for the given referenced sources. This lets us invalidate the cached
 // when one or more referenced sources change.
 //
 // Much of this logic is duplicated in runManifestGenAsync. If making changes here, check whether runManifestGenAsync
 // should be updated.
-func resolveReferencedSources(hasMultipleSources bool, source *v1alpha1.ApplicationSourceHelm, refSources map[string]*v1alpha1.RefTarget, newClientResolveRevision gitClientGetter) (map[string]string, error) {
+func resolveReferencedSources(ctx context.Context, hasMultipleSources bool, source *v1alpha1.ApplicationSourceHelm, refSources map[string]*v1alpha1.RefTarget, newClientResolveRevision gitClientGetter) (map[string]string, error) {
 	repoRefs := make(map[string]string)
 	if hasMultipleSources {
 		if source != nil {
@@ -472,7 +634,7 @@ func resolveReferencedSources(hasMultipleSources bool, source *v1alpha1.Applicat
 					normalizedRepoURL := git.NormalizeGitURL(refSourceMapping.Repo.Repo)
 					_, ok = repoRefs[normalizedRepoURL]
 					if !ok {
-						_, referencedCommitSHA, err := newClientResolveRevision(&refSourceMapping.Repo, refSourceMapping.TargetRevision)
+						_, referencedCommitSHA, err := newClientResolveRevision(ctx, &refSourceMapping.Repo, refSourceMapping.TargetRevision)
 						if err != nil {
 							log.Errorf("Failed to get git client for repo %s: %v", refSourceMapping.Repo.Repo, err)
 							return nil, fmt.Errorf("failed to get git client for repo %s", refSourceMapping.Repo.Repo)
@@ -488,6 +650,10 @@ func resolveReferencedSources(hasMultipleSources bool, source *v1alpha1.Applicat
 }
 
 func (s *Service) GenerateManifest(ctx context.Context, q *apiclient.ManifestRequest) (*apiclient.ManifestResponse, error) {
+	if err := checkRepoURLPolicy(q.RepositoryAllowList, q.RepositoryDenyList, refSourceRepoURLs(q.Repo, q.RefSources)...); err != nil {
+		return nil, err
+	}
+
 	var res *apiclient.ManifestResponse
 	var err error
 	cacheFn := func(cacheKey string, refSourceCommitSHAs cache.ResolvedRevisions, firstInvocation bool) (bool, error) {
@@ -511,6 +677,10 @@ func (s *Service) GenerateManifest(ctx context.Context, q *apiclient.ManifestReq
 			return nil
 		}
 
+		// the cache is looked up twice before the manifests are generated, so a miss is only counted once they are
+		if !q.NoCache {
+			s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeManifests, false)
+		}
 		promise = s.runManifestGen(ctx, repoRoot, commitSHA, cacheKey, ctxSrc, q)
 		// The fist channel to send the message will resume this operation.
 		// The main purpose for using channels here is to be able to unlock
@@ -712,7 +882,7 @@ func (s *Service) runManifestGenAsync(ctx context.Context, repoRoot, commitSHA,
 								return
 							}
 						} else {
-							gitClient, referencedCommitSHA, err := s.newClientResolveRevision(&refSourceMapping.Repo, refSourceMapping.TargetRevision)
+							gitClient, referencedCommitSHA, err := s.newClientResolveRevision(ctx, &refSourceMapping.Repo, refSourceMapping.TargetRevision)
 							if err != nil {
 								log.Errorf("Failed to get git client for repo %s: %v", refSourceMapping.Repo.Repo, err)
 								ch.errCh <- fmt.Errorf("failed to get git client for repo %s", refSourceMapping.Repo.Repo)
@@ -724,7 +894,7 @@ func (s *Service) runManifestGenAsync(ctx context.Context, repoRoot, commitSHA,
 								return
 							}
 							closer, err := s.repoLock.Lock(gitClient.Root(), referencedCommitSHA, true, func() (goio.Closer, error) {
-								return s.checkoutRevision(gitClient, referencedCommitSHA, s.initConstants.SubmoduleEnabled)
+								return s.checkoutRevision(gitClient, referencedCommitSHA, refSourceMapping.Repo.IsSubmoduleEnabled(s.initConstants.SubmoduleEnabled))
 							})
 							if err != nil {
 								log.Errorf("failed to acquire lock for referenced source %s", normalizedRepoURL)
@@ -766,7 +936,7 @@ func (s *Service) runManifestGenAsync(ctx context.Context, repoRoot, commitSHA,
 			}
 		}
 
-		manifestGenResult, err = GenerateManifests(ctx, opContext.appPath, repoRoot, commitSHA, q, false, s.gitCredsStore, s.initConstants.MaxCombinedDirectoryManifestsSize, s.gitRepoPaths, WithCMPTarDoneChannel(ch.tarDoneCh), WithCMPTarExcludedGlobs(s.initConstants.CMPTarExcludedGlobs))
+		manifestGenResult, err = GenerateManifests(ctx, opContext.appPath, repoRoot, commitSHA, q, false, s.gitCredsStore, s.initConstants.MaxCombinedDirectoryManifestsSize, s.gitRepoPaths, WithCMPTarDoneChannel(ch.tarDoneCh), WithCMPTarExcludedGlobs(s.initConstants.CMPTarExcludedGlobs), WithMetricsServer(s.metricsServer))
 	}
 	refSourceCommitSHAs := make(map[string]string)
 	if len(repoRefs) > 0 {
@@ -915,6 +1085,7 @@ func (s *Service) getManifestCacheEntry(cacheKey string, q *apiclient.ManifestRe
 		}
 
 		log.Infof("manifest cache hit: %s/%s", q.ApplicationSource.String(), cacheKey)
+		s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeManifests, true)
 		return true, res.ManifestResponse, nil
 	}
 
@@ -927,6 +1098,9 @@ func (s *Service) getManifestCacheEntry(cacheKey string, q *apiclient.ManifestRe
 	return false, nil, nil
 }
 
+// getHelmRepos returns the repositories of the dependencies of the chart in the given path. Dependencies which match a
+// configured repository by URL or name use its credentials, falling back to the credentials of the matching credential
+// template, so that charts with dependencies in private repositories don't require the repositories to be configured.
 func getHelmRepos(appPath string, repositories []*v1alpha1.Repository, helmRepoCreds []*v1alpha1.RepoCreds) ([]helm.HelmRepository, error) {
 	dependencies, err := getHelmDependencyRepos(appPath)
 	if err != nil {
@@ -935,7 +1109,7 @@ func getHelmRepos(appPath string, repositories []*v1alpha1.Repository, helmRepoC
 	reposByName := make(map[string]*v1alpha1.Repository)
 	reposByUrl := make(map[string]*v1alpha1.Repository)
 	for _, repo := range repositories {
-		reposByUrl[repo.Repo] = repo
+		reposByUrl[normalizeHelmRepoURL(repo.Repo)] = repo
 		if repo.Name != "" {
 			reposByName[repo.Name] = repo
 		}
@@ -943,19 +1117,24 @@ func getHelmRepos(appPath string, repositories []*v1alpha1.Repository, helmRepoC
 
 	repos := make([]helm.HelmRepository, 0)
 	for _, dep := range dependencies {
-		repo, ok := reposByUrl[dep.Repo]
+		repo, ok := reposByUrl[normalizeHelmRepoURL(dep.Repo)]
 		if !ok && dep.Name != "" {
 			repo, ok = reposByName[dep.Name]
 		}
-		if !ok {
+		if ok {
+			repo = repo.DeepCopy()
+			if repo.Name == "" {
+				// repositories configured without a name are added under the name derived from the dependency
+				repo.Name = dep.Name
+			}
+			if !repo.HasCredentials() {
+				repo.CopyCredentialsFrom(getRepoCredential(helmRepoCreds, repo.Repo))
+			}
+		} else {
 			repo = &v1alpha1.Repository{Repo: dep.Repo, Name: dep.Name, EnableOCI: dep.EnableOCI}
 			if repositoryCredential := getRepoCredential(helmRepoCreds, dep.Repo); repositoryCredential != nil {
 				repo.EnableOCI = repositoryCredential.EnableOCI
-				repo.Password = repositoryCredential.Password
-				repo.Username = repositoryCredential.Username
-				repo.SSHPrivateKey = repositoryCredential.SSHPrivateKey
-				repo.TLSClientCertData = repositoryCredential.TLSClientCertData
-				repo.TLSClientCertKey = repositoryCredential.TLSClientCertKey
+				repo.CopyCredentialsFrom(repositoryCredential)
 			}
 		}
 		repos = append(repos, helm.HelmRepository{Name: repo.Name, Repo: repo.Repo, Creds: repo.GetHelmCreds(), EnableOci: repo.EnableOCI})
@@ -963,6 +1142,88 @@ func getHelmRepos(appPath string, repositories []*v1alpha1.Repository, helmRepoC
 	return repos, nil
 }
 
+// normalizeHelmRepoURL returns the given Helm repository URL without trailing slashes, so that dependencies match the
+// configured repositories regardless of how their URLs are spelled
+func normalizeHelmRepoURL(repoURL string) string {
+	return strings.TrimRight(repoURL, "/")
+}
+
+// getRemoteValueFileRepo returns the configured repository, if any, which the given remote value file URL belongs to
+func getRemoteValueFileRepo(valueFileURL string, repositories []*v1alpha1.Repository, helmRepoCreds []*v1alpha1.RepoCreds) *v1alpha1.Repository {
+	for _, repo := range repositories {
+		if strings.HasPrefix(valueFileURL, strings.TrimSuffix(repo.Repo, "/")+"/") {
+			return repo
+		}
+	}
+	if repositoryCredential := getRepoCredential(helmRepoCreds, valueFileURL); repositoryCredential != nil {
+		// credential templates apply to any URL they prefix, so the file URL itself is treated as the repository
+		return &v1alpha1.Repository{
+			Repo:              valueFileURL,
+			Username:          repositoryCredential.Username,
+			Password:          repositoryCredential.Password,
+			TLSClientCertData: repositoryCredential.TLSClientCertData,
+			TLSClientCertKey:  repositoryCredential.TLSClientCertKey,
+		}
+	}
+	return nil
+}
+
+// downloadRemoteValueFiles downloads remote value files which belong to a configured repository permitted by the
+// project, using the credentials of that repository. All other value files are returned unchanged and are left to
+// helm to resolve. The downloads are bound by the exec timeout of the repo server. The returned function removes the
+// downloaded files.
+func downloadRemoteValueFiles(ctx context.Context, valueFiles []pathutil.ResolvedFilePath, q *apiclient.ManifestRequest) ([]pathutil.ResolvedFilePath, func(), error) {
+	var downloaded []string
+	cleanup := func() {
+		for _, p := range downloaded {
+			_ = os.Remove(p)
+		}
+	}
+	resolved := make([]pathutil.ResolvedFilePath, 0, len(valueFiles))
+	ctx, cancel := context.WithTimeout(ctx, executil.TimeoutFromContext(ctx))
+	defer cancel()
+	for _, valueFile := range valueFiles {
+		valueFileURL := string(valueFile)
+		if !strings.HasPrefix(valueFileURL, "https://") && !strings.HasPrefix(valueFileURL, "http://") {
+			resolved = append(resolved, valueFile)
+			continue
+		}
+		repo := getRemoteValueFileRepo(valueFileURL, q.Repos, q.HelmRepoCreds)
+		// value files which are left to helm are checked against the repository policy as well, using their own URL
+		policyURL := valueFileURL
+		if repo != nil {
+			policyURL = repo.Repo
+		}
+		if err := checkRepoURLPolicy(q.RepositoryAllowList, q.RepositoryDenyList, policyURL); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		if repo == nil || !isSourcePermitted(repo.Repo, q.ProjectSourceRepos) {
+			resolved = append(resolved, valueFile)
+			continue
+		}
+		data, err := helm.GetFile(ctx, valueFileURL, repo.GetHelmCreds(), repo.Proxy, maxRemoteValueFileSize)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("error downloading value file %s: %w", valueFileURL, err)
+		}
+		f, err := os.CreateTemp("", "values-*.yaml")
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		downloaded = append(downloaded, f.Name())
+		_, err = f.Write(data)
+		_ = f.Close()
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		resolved = append(resolved, pathutil.ResolvedFilePath(f.Name()))
+	}
+	return resolved, cleanup, nil
+}
+
 type dependencies struct {
 	Dependencies []repositories `yaml:"dependencies"`
 }
@@ -971,6 +1232,61 @@ type repositories struct {
 	Repository string `yaml:"repository"`
 }
 
+// kustomizationReferences is the subset of a kustomization which references files and directories
+type kustomizationReferences struct {
+	Resources             []string `json:"resources"`
+	Bases                 []string `json:"bases"`
+	Components            []string `json:"components"`
+	Crds                  []string `json:"crds"`
+	PatchesStrategicMerge []string `json:"patchesStrategicMerge"`
+	Patches               []struct {
+		Path string `json:"path"`
+	} `json:"patches"`
+	PatchesJson6902 []struct {
+		Path string `json:"path"`
+	} `json:"patchesJson6902"`
+	ConfigMapGenerator []kustomizationGenerator `json:"configMapGenerator"`
+	SecretGenerator    []kustomizationGenerator `json:"secretGenerator"`
+}
+
+type kustomizationGenerator struct {
+	Files []string `json:"files"`
+	Envs  []string `json:"envs"`
+	Env   string   `json:"env"`
+}
+
+func (k *kustomizationReferences) paths() []string {
+	var paths []string
+	paths = append(paths, k.Resources...)
+	paths = append(paths, k.Bases...)
+	paths = append(paths, k.Components...)
+	paths = append(paths, k.Crds...)
+	for _, patch := range k.PatchesStrategicMerge {
+		if !strings.Contains(patch, "\n") {
+			// patches may also be inlined
+			paths = append(paths, patch)
+		}
+	}
+	for _, patch := range k.Patches {
+		paths = append(paths, patch.Path)
+	}
+	for _, patch := range k.PatchesJson6902 {
+		paths = append(paths, patch.Path)
+	}
+	for _, generator := range append(k.ConfigMapGenerator, k.SecretGenerator...) {
+		for _, file := range generator.Files {
+			// files may be prefixed with the key they are stored at
+			if _, p, ok := strings.Cut(file, "="); ok {
+				file = p
+			}
+			paths = append(paths, file)
+		}
+		paths = append(paths, generator.Envs...)
+		paths = append(paths, generator.Env)
+	}
+	return paths
+}
+
 func getHelmDependencyRepos(appPath string) ([]*v1alpha1.Repository, error) {
 	repos := make([]*v1alpha1.Repository, 0)
 	f, err := os.ReadFile(filepath.Join(appPath, "Chart.yaml"))
@@ -1002,6 +1318,83 @@ func getHelmDependencyRepos(appPath string) ([]*v1alpha1.Repository, error) {
 	return repos, nil
 }
 
+// getSparseCheckoutDependencies returns the repository paths outside of the given paths which the kustomizations and
+// Helm charts within them reference, i.e. kustomize bases, resources, components, patches and generator files and
+// Helm dependencies using file:// repositories. complete is true if a reference is outside of the repository.
+func getSparseCheckoutDependencies(root string, paths []string) ([]string, bool, error) {
+	var deps []string
+	complete := false
+	addReference := func(dir string, ref string) {
+		if ref == "" || strings.Contains(ref, "://") || strings.HasPrefix(ref, "git@") {
+			// remote reference, not part of the repository
+			return
+		}
+		p := ref
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			// the reference is the repository root or outside of it
+			complete = true
+			return
+		}
+		deps = append(deps, filepath.ToSlash(rel))
+	}
+	for _, sparsePath := range paths {
+		err := filepath.WalkDir(filepath.Join(root, sparsePath), func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					// the path does not exist in the checked out revision
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				if entry.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			dir := filepath.Dir(p)
+			switch {
+			case kustomize.IsKustomization(entry.Name()):
+				data, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				var k kustomizationReferences
+				if err := yaml.Unmarshal(data, &k); err != nil {
+					// the error is reported when the manifests are generated
+					return nil
+				}
+				for _, ref := range k.paths() {
+					addReference(dir, ref)
+				}
+			case entry.Name() == "Chart.yaml":
+				data, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				var d dependencies
+				if err := yaml.Unmarshal(data, &d); err != nil {
+					return nil
+				}
+				for _, r := range d.Dependencies {
+					if strings.HasPrefix(r.Repository, "file://") {
+						addReference(dir, strings.TrimPrefix(r.Repository, "file://"))
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return deps, complete, nil
+}
+
 func sanitizeRepoName(repoName string) string {
 	return strings.ReplaceAll(repoName, "/", "-")
 }
@@ -1046,7 +1439,54 @@ func isSourcePermitted(url string, repos []string) bool {
 	return p.IsSourcePermitted(v1alpha1.ApplicationSource{RepoURL: url})
 }
 
-func helmTemplate(appPath string, repoRoot string, env *v1alpha1.Env, q *apiclient.ManifestRequest, isLocal bool, gitRepoPaths io.TempPaths) ([]*unstructured.Unstructured, error) {
+// checkRepoURLPolicy returns a PermissionDenied error if one of the given repository URLs is not permitted by the
+// instance-wide repository policy sent with the request. The policy is enforced by the repo server as well, so that
+// manifests are never generated from denied repositories, including the ones referenced by the sources themselves.
+func checkRepoURLPolicy(allowList []string, denyList []string, repoURLs ...string) error {
+	policy := settings.RepositoryURLPolicy{Allow: allowList, Deny: denyList}
+	for _, repoURL := range repoURLs {
+		if repoURL != "" && !policy.IsPermitted(repoURL) {
+			return status.Errorf(codes.PermissionDenied, "repository '%s' is not permitted by the repository policy", repoURL)
+		}
+	}
+	return nil
+}
+
+// checkKustomizeRepoURLPolicy checks the remote repositories referenced by the kustomization in the given path against
+// the instance-wide repository policy before kustomize fetches them
+func checkKustomizeRepoURLPolicy(appPath string, allowList []string, denyList []string) error {
+	if len(allowList) == 0 && len(denyList) == 0 {
+		return nil
+	}
+	repoURLs, err := kustomize.RemoteRepositories(appPath)
+	if err != nil {
+		return err
+	}
+	return checkRepoURLPolicy(allowList, denyList, repoURLs...)
+}
+
+// refSourceRepoURLs returns the URLs of the given repository and of the repositories of the referenced sources
+func refSourceRepoURLs(repo *v1alpha1.Repository, refSources map[string]*v1alpha1.RefTarget) []string {
+	var repoURLs []string
+	if repo != nil {
+		repoURLs = append(repoURLs, repo.Repo)
+	}
+	for _, refSource := range refSources {
+		repoURLs = append(repoURLs, refSource.Repo.Repo)
+	}
+	return repoURLs
+}
+
+// helmRepoURLs returns the URLs of the given Helm repositories
+func helmRepoURLs(helmRepos []helm.HelmRepository) []string {
+	repoURLs := make([]string, 0, len(helmRepos))
+	for _, repo := range helmRepos {
+		repoURLs = append(repoURLs, repo.Repo)
+	}
+	return repoURLs
+}
+
+func helmTemplate(ctx context.Context, appPath string, repoRoot string, env *v1alpha1.Env, q *apiclient.ManifestRequest, isLocal bool, gitRepoPaths io.TempPaths) ([]*unstructured.Unstructured, error) {
 	concurrencyAllowed := isConcurrencyAllowed(appPath)
 	if !concurrencyAllowed {
 		manifestGenerateLock.Lock(appPath)
@@ -1077,7 +1517,10 @@ func helmTemplate(appPath string, repoRoot string, env *v1alpha1.Env, q *apiclie
 			version = appHelm.Version
 		}
 		if appHelm.ReleaseName != "" {
-			templateOpts.Name = appHelm.ReleaseName
+			templateOpts.Name = env.Envsubst(appHelm.ReleaseName)
+		}
+		if appHelm.Namespace != "" {
+			templateOpts.Namespace = env.Envsubst(appHelm.Namespace)
 		}
 
 		resolvedValueFiles, err := getResolvedValueFiles(appPath, repoRoot, env, q.GetValuesFileSchemes(), appHelm.ValueFiles, q.RefSources, gitRepoPaths, appHelm.IgnoreMissingValueFiles)
@@ -1085,6 +1528,12 @@ func helmTemplate(appPath string, repoRoot string, env *v1alpha1.Env, q *apiclie
 			return nil, err
 		}
 
+		resolvedValueFiles, cleanupValueFiles, err := downloadRemoteValueFiles(ctx, resolvedValueFiles, q)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanupValueFiles()
+
 		templateOpts.Values = resolvedValueFiles
 
 		if !appHelm.ValuesIsEmpty() {
@@ -1155,8 +1604,11 @@ func helmTemplate(appPath string, repoRoot string, env *v1alpha1.Env, q *apiclie
 	if len(reposNotPermitted) > 0 {
 		return nil, status.Errorf(codes.PermissionDenied, "helm repos %s are not permitted in project '%s'", strings.Join(reposNotPermitted, ", "), q.ProjectName)
 	}
+	if err := checkRepoURLPolicy(q.RepositoryAllowList, q.RepositoryDenyList, helmRepoURLs(helmRepos)...); err != nil {
+		return nil, err
+	}
 
-	h, err := helm.NewHelmApp(appPath, helmRepos, isLocal, version, proxy, passCredentials)
+	h, err := helm.NewHelmApp(appPath, helmRepos, isLocal, version, proxy, passCredentials, executil.TimeoutFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1283,6 +1735,7 @@ type GenerateManifestOpt func(*generateManifestOpt)
 type generateManifestOpt struct {
 	cmpTarDoneCh        chan<- bool
 	cmpTarExcludedGlobs []string
+	metricsServer       *metrics.MetricsServer
 }
 
 func newGenerateManifestOpt(opts ...GenerateManifestOpt) *generateManifestOpt {
@@ -1310,6 +1763,14 @@ func WithCMPTarExcludedGlobs(excludedGlobs []string) GenerateManifestOpt {
 	}
 }
 
+// WithMetricsServer defines the metrics server observing the executions of helm, kustomize or a config management
+// plugin.
+func WithMetricsServer(metricsServer *metrics.MetricsServer) GenerateManifestOpt {
+	return func(o *generateManifestOpt) {
+		o.metricsServer = metricsServer
+	}
+}
+
 // GenerateManifests generates manifests from a path. Overrides are applied as a side effect on the given ApplicationSource.
 func GenerateManifests(ctx context.Context, appPath, repoRoot, revision string, q *apiclient.ManifestRequest, isLocal bool, gitCredsStore git.CredsStore, maxCombinedManifestQuantity resource.Quantity, gitRepoPaths io.TempPaths, opts ...GenerateManifestOpt) (*apiclient.ManifestResponse, error) {
 	opt := newGenerateManifestOpt(opts...)
@@ -1327,15 +1788,30 @@ func GenerateManifests(ctx context.Context, appPath, repoRoot, revision string,
 	}
 	env := newEnv(q, revision)
 
+	var tool string
+	switch appSourceType {
+	case v1alpha1.ApplicationSourceTypeHelm:
+		tool = toolHelm
+	case v1alpha1.ApplicationSourceTypeKustomize:
+		tool = toolKustomize
+	case v1alpha1.ApplicationSourceTypePlugin:
+		tool = toolPlugin
+	}
+	startTime := time.Now()
+
 	switch appSourceType {
 	case v1alpha1.ApplicationSourceTypeHelm:
-		targetObjs, err = helmTemplate(appPath, repoRoot, env, q, isLocal, gitRepoPaths)
+		targetObjs, err = helmTemplate(ctx, appPath, repoRoot, env, q, isLocal, gitRepoPaths)
 	case v1alpha1.ApplicationSourceTypeKustomize:
 		kustomizeBinary := ""
 		if q.KustomizeOptions != nil {
 			kustomizeBinary = q.KustomizeOptions.BinaryPath
 		}
-		k := kustomize.NewKustomizeApp(appPath, q.Repo.GetGitCreds(gitCredsStore), repoURL, kustomizeBinary)
+		err = checkKustomizeRepoURLPolicy(appPath, q.RepositoryAllowList, q.RepositoryDenyList)
+		if err != nil {
+			return nil, err
+		}
+		k := kustomize.NewKustomizeApp(appPath, q.Repo.GetGitCreds(gitCredsStore), repoURL, kustomizeBinary, executil.TimeoutFromContext(ctx))
 		targetObjs, _, err = k.Build(q.ApplicationSource.Kustomize, q.KustomizeOptions, env)
 	case v1alpha1.ApplicationSourceTypePlugin:
 		pluginName := ""
@@ -1355,6 +1831,9 @@ func GenerateManifests(ctx context.Context, appPath, repoRoot, revision string,
 		logCtx := log.WithField("application", q.AppName)
 		targetObjs, err = findManifests(logCtx, appPath, repoRoot, env, *directory, q.EnabledSourceTypes, maxCombinedManifestQuantity)
 	}
+	if tool != "" && opt.metricsServer != nil {
+		opt.metricsServer.ObserveToolExecution(repoURL, tool, err != nil, time.Since(startTime))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1854,6 +2333,10 @@ func runConfigManagementPluginSidecars(ctx context.Context, appPath, repoPath, p
 		return nil, err
 	}
 
+	// like other config management tools, plugins are given at most the exec timeout of the repo server
+	ctx, cancel := context.WithTimeout(ctx, executil.TimeoutFromContext(ctx))
+	defer cancel()
+
 	// detect config management plugin server
 	conn, cmpClient, err := discovery.DetectConfigManagementPlugin(ctx, appPath, repoPath, pluginName, env, tarExcludedGlobs)
 	if err != nil {
@@ -1903,10 +2386,18 @@ func generateManifestsCMP(ctx context.Context, appPath, repoPath string, env []s
 }
 
 func (s *Service) GetAppDetails(ctx context.Context, q *apiclient.RepoServerAppDetailsQuery) (*apiclient.RepoAppDetailsResponse, error) {
+	if err := checkRepoURLPolicy(q.RepositoryAllowList, q.RepositoryDenyList, refSourceRepoURLs(q.Repo, q.RefSources)...); err != nil {
+		return nil, err
+	}
+
 	res := &apiclient.RepoAppDetailsResponse{}
 
 	cacheFn := s.createGetAppDetailsCacheHandler(res, q)
 	operation := func(repoRoot, commitSHA, revision string, ctxSrc operationContextSrc) error {
+		// the cache is looked up twice before the details are generated, so a miss is only counted once they are
+		if !q.NoCache {
+			s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeAppDetails, false)
+		}
 		opContext, err := ctxSrc()
 		if err != nil {
 			return err
@@ -1921,11 +2412,11 @@ func (s *Service) GetAppDetails(ctx context.Context, q *apiclient.RepoServerAppD
 
 		switch appSourceType {
 		case v1alpha1.ApplicationSourceTypeHelm:
-			if err := populateHelmAppDetails(res, opContext.appPath, repoRoot, q, s.gitRepoPaths); err != nil {
+			if err := populateHelmAppDetails(ctx, res, opContext.appPath, repoRoot, q, s.gitRepoPaths); err != nil {
 				return err
 			}
 		case v1alpha1.ApplicationSourceTypeKustomize:
-			if err := populateKustomizeAppDetails(res, q, opContext.appPath, commitSHA, s.gitCredsStore); err != nil {
+			if err := populateKustomizeAppDetails(ctx, res, q, opContext.appPath, commitSHA, s.gitCredsStore); err != nil {
 				return err
 			}
 		case v1alpha1.ApplicationSourceTypePlugin:
@@ -1948,6 +2439,7 @@ func (s *Service) createGetAppDetailsCacheHandler(res *apiclient.RepoAppDetailsR
 		err := s.cache.GetAppDetails(revision, q.Source, q.RefSources, res, v1alpha1.TrackingMethod(q.TrackingMethod), nil)
 		if err == nil {
 			log.Infof("app details cache hit: %s/%s", revision, q.Source.Path)
+			s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeAppDetails, true)
 			return true, nil
 		}
 
@@ -1960,7 +2452,7 @@ func (s *Service) createGetAppDetailsCacheHandler(res *apiclient.RepoAppDetailsR
 	}
 }
 
-func populateHelmAppDetails(res *apiclient.RepoAppDetailsResponse, appPath string, repoRoot string, q *apiclient.RepoServerAppDetailsQuery, gitRepoPaths io.TempPaths) error {
+func populateHelmAppDetails(ctx context.Context, res *apiclient.RepoAppDetailsResponse, appPath string, repoRoot string, q *apiclient.RepoServerAppDetailsQuery, gitRepoPaths io.TempPaths) error {
 	var selectedValueFiles []string
 
 	if q.Source.Helm != nil {
@@ -1985,7 +2477,10 @@ func populateHelmAppDetails(res *apiclient.RepoAppDetailsResponse, appPath strin
 	if err != nil {
 		return err
 	}
-	h, err := helm.NewHelmApp(appPath, helmRepos, false, version, q.Repo.Proxy, passCredentials)
+	if err := checkRepoURLPolicy(q.RepositoryAllowList, q.RepositoryDenyList, helmRepoURLs(helmRepos)...); err != nil {
+		return err
+	}
+	h, err := helm.NewHelmApp(appPath, helmRepos, false, version, q.Repo.Proxy, passCredentials, executil.TimeoutFromContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -2002,6 +2497,13 @@ func populateHelmAppDetails(res *apiclient.RepoAppDetailsResponse, appPath strin
 	} else {
 		log.Warnf("Values file %s is not allowed: %v", filepath.Join(appPath, "values.yaml"), err)
 	}
+	if resolvedSchemaPath, _, err := pathutil.ResolveValueFilePathOrUrl(appPath, repoRoot, "values.schema.json", []string{}); err == nil {
+		if err := loadFileIntoIfExists(resolvedSchemaPath, &res.Helm.ValuesSchema); err != nil {
+			return err
+		}
+	} else {
+		log.Warnf("Values schema file %s is not allowed: %v", filepath.Join(appPath, "values.schema.json"), err)
+	}
 	ignoreMissingValueFiles := false
 	if q.Source.Helm != nil {
 		ignoreMissingValueFiles = q.Source.Helm.IgnoreMissingValueFiles
@@ -2066,13 +2568,16 @@ func findHelmValueFilesInPath(path string) ([]string, error) {
 	return result, nil
 }
 
-func populateKustomizeAppDetails(res *apiclient.RepoAppDetailsResponse, q *apiclient.RepoServerAppDetailsQuery, appPath string, reversion string, credsStore git.CredsStore) error {
+func populateKustomizeAppDetails(ctx context.Context, res *apiclient.RepoAppDetailsResponse, q *apiclient.RepoServerAppDetailsQuery, appPath string, reversion string, credsStore git.CredsStore) error {
 	res.Kustomize = &apiclient.KustomizeAppSpec{}
 	kustomizeBinary := ""
 	if q.KustomizeOptions != nil {
 		kustomizeBinary = q.KustomizeOptions.BinaryPath
 	}
-	k := kustomize.NewKustomizeApp(appPath, q.Repo.GetGitCreds(credsStore), q.Repo.Repo, kustomizeBinary)
+	if err := checkKustomizeRepoURLPolicy(appPath, q.RepositoryAllowList, q.RepositoryDenyList); err != nil {
+		return err
+	}
+	k := kustomize.NewKustomizeApp(appPath, q.Repo.GetGitCreds(credsStore), q.Repo.Repo, kustomizeBinary, executil.TimeoutFromContext(ctx))
 	fakeManifestRequest := apiclient.ManifestRequest{
 		AppName:           q.AppName,
 		Namespace:         "", // FIXME: omit it for now
@@ -2141,6 +2646,9 @@ func (s *Service) GetRevisionMetadata(ctx context.Context, q *apiclient.RepoServ
 	if !(git.IsCommitSHA(q.Revision) || git.IsTruncatedCommitSHA(q.Revision)) {
 		return nil, fmt.Errorf("revision %s must be resolved", q.Revision)
 	}
+	if err := checkRepoURLPolicy(q.RepositoryAllowList, q.RepositoryDenyList, q.Repo.Repo); err != nil {
+		return nil, err
+	}
 	metadata, err := s.cache.GetRevisionMetadata(q.Repo.Repo, q.Revision)
 	if err == nil {
 		// The logic here is that if a signature check on metadata is requested,
@@ -2152,6 +2660,7 @@ func (s *Service) GetRevisionMetadata(ctx context.Context, q *apiclient.RepoServ
 			log.Infof("revision metadata cache hit, but need to regenerate due to missing signature info: %s/%s", q.Repo.Repo, q.Revision)
 		} else {
 			log.Infof("revision metadata cache hit: %s/%s", q.Repo.Repo, q.Revision)
+			s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeRevisionMetadata, true)
 			if !q.CheckSignature {
 				metadata.SignatureInfo = ""
 			}
@@ -2162,10 +2671,11 @@ func (s *Service) GetRevisionMetadata(ctx context.Context, q *apiclient.RepoServ
 			log.Warnf("revision metadata cache error %s/%s: %v", q.Repo.Repo, q.Revision, err)
 		} else {
 			log.Infof("revision metadata cache miss: %s/%s", q.Repo.Repo, q.Revision)
+			s.metricsServer.IncCacheRequest(q.Repo.Repo, metrics.CacheTypeRevisionMetadata, false)
 		}
 	}
 
-	gitClient, _, err := s.newClientResolveRevision(q.Repo, q.Revision)
+	gitClient, _, err := s.newClientResolveRevision(ctx, q.Repo, q.Revision)
 	if err != nil {
 		return nil, err
 	}
@@ -2174,7 +2684,7 @@ func (s *Service) GetRevisionMetadata(ctx context.Context, q *apiclient.RepoServ
 	defer s.metricsServer.DecPendingRepoRequest(q.Repo.Repo)
 
 	closer, err := s.repoLock.Lock(gitClient.Root(), q.Revision, true, func() (goio.Closer, error) {
-		return s.checkoutRevision(gitClient, q.Revision, s.initConstants.SubmoduleEnabled)
+		return s.checkoutRevision(gitClient, q.Revision, q.Repo.IsSubmoduleEnabled(s.initConstants.SubmoduleEnabled))
 	})
 
 	if err != nil {
@@ -2249,6 +2759,17 @@ func (s *Service) GetRevisionChartDetails(ctx context.Context, q *apiclient.Repo
 	if err != nil {
 		return nil, fmt.Errorf("error getting chart details: %v", err)
 	}
+	details.RepoURL = q.Repo.Repo
+	if !q.Repo.EnableOCI && !helm.IsHelmOciRepo(q.Repo.Repo) {
+		// OCI registries have no index, so the digest is only known for chart repositories
+		if index, err := helmClient.GetIndex(false); err != nil {
+			log.Warnf("failed to get index of %s to get the digest of chart %s: %v", q.Repo.Repo, q.Name, err)
+		} else if entries, err := index.GetEntries(q.Name); err == nil {
+			if entry, ok := entries.Find(revision); ok {
+				details.Digest = entry.Digest
+			}
+		}
+	}
 	_ = s.cache.SetRevisionChartDetails(q.Repo.Repo, q.Name, q.Revision, details)
 	return details, nil
 }
@@ -2260,19 +2781,26 @@ func fileParameters(q *apiclient.RepoServerAppDetailsQuery) []v1alpha1.HelmFileP
 	return q.Source.Helm.FileParameters
 }
 
-func (s *Service) newClient(repo *v1alpha1.Repository, opts ...git.ClientOpts) (git.Client, error) {
+func (s *Service) newClient(ctx context.Context, repo *v1alpha1.Repository, opts ...git.ClientOpts) (git.Client, error) {
 	repoPath, err := s.gitRepoPaths.GetPath(git.NormalizeGitURL(repo.Repo))
 	if err != nil {
 		return nil, err
 	}
-	opts = append(opts, git.WithEventHandlers(metrics.NewGitClientEventHandlers(s.metricsServer)))
+	opts = append(opts, git.WithEventHandlers(s.toolRateLimiter.gitEventHandlers(ctx, metrics.NewGitClientEventHandlers(s.metricsServer))), git.WithDepth(repo.CloneDepth), git.WithFilter(repo.CloneFilter), git.WithSkipSubmoduleCreds(repo.SkipSubmoduleCreds))
+	if s.initConstants.GitReferenceCachePath != "" {
+		opts = append(opts, git.WithReferenceRepo(git.ReferenceRepoPath(s.initConstants.GitReferenceCachePath, repo.Repo)))
+	}
+	// fetches and checkouts are bound by the deadline of the request, e.g. the manifest generation timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		opts = append(opts, git.WithDeadline(deadline))
+	}
 	return s.newGitClient(repo.Repo, repoPath, repo.GetGitCreds(s.gitCredsStore), repo.IsInsecure(), repo.EnableLFS, repo.Proxy, opts...)
 }
 
 // newClientResolveRevision is a helper to perform the common task of instantiating a git client
 // and resolving a revision to a commit SHA
-func (s *Service) newClientResolveRevision(repo *v1alpha1.Repository, revision string, opts ...git.ClientOpts) (git.Client, string, error) {
-	gitClient, err := s.newClient(repo, opts...)
+func (s *Service) newClientResolveRevision(ctx context.Context, repo *v1alpha1.Repository, revision string, opts ...git.ClientOpts) (git.Client, string, error) {
+	gitClient, err := s.newClient(ctx, repo, opts...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -2384,6 +2912,65 @@ func checkoutRevision(gitClient git.Client, revision string, submoduleEnabled bo
 	return err
 }
 
+// getSparseCheckoutPaths returns the repository paths required to generate the manifests of the given source: the
+// source path plus the local Helm value and parameter files and Kustomize patch and component paths. It returns nil
+// if the complete repository is required, e.g. if the source path is the repository root, the repository is also
+// referenced by another source or a file path cannot be determined before generating the manifests.
+func getSparseCheckoutPaths(source *v1alpha1.ApplicationSource, refSources map[string]*v1alpha1.RefTarget) []string {
+	appPath := path.Clean(source.Path)
+	if appPath == "." || appPath == "/" {
+		return nil
+	}
+	for _, refSource := range refSources {
+		if git.NormalizeGitURL(refSource.Repo.Repo) == git.NormalizeGitURL(source.RepoURL) {
+			// the working tree is shared with the referenced source, which requires the complete repository
+			return nil
+		}
+	}
+	var relPaths []string
+	if source.Helm != nil {
+		for _, valueFile := range source.Helm.ValueFiles {
+			if getReferencedSource(valueFile, refSources) != nil {
+				// value file of another source
+				continue
+			}
+			relPaths = append(relPaths, valueFile)
+		}
+		for _, p := range source.Helm.FileParameters {
+			relPaths = append(relPaths, p.Path)
+		}
+	}
+	if source.Kustomize != nil {
+		for _, patch := range source.Kustomize.Patches {
+			if patch.Path != "" {
+				relPaths = append(relPaths, patch.Path)
+			}
+		}
+		relPaths = append(relPaths, source.Kustomize.Components...)
+	}
+
+	paths := []string{appPath}
+	for _, p := range relPaths {
+		if strings.Contains(p, "://") {
+			// remote file, not part of the repository
+			continue
+		}
+		if strings.Contains(p, "$") {
+			// path uses environment variables which are only known when generating the manifests
+			return nil
+		}
+		if !path.IsAbs(p) {
+			p = path.Join(appPath, p)
+		}
+		p = path.Clean(p)
+		if p == "/" || p == ".." || strings.HasPrefix(p, "../") {
+			return nil
+		}
+		paths = append(paths, strings.TrimPrefix(p, "/"))
+	}
+	return paths
+}
+
 func (s *Service) GetHelmCharts(ctx context.Context, q *apiclient.HelmChartsRequest) (*apiclient.HelmChartsResponse, error) {
 	index, err := s.newHelmClient(q.Repo.Repo, q.Repo.GetHelmCreds(), q.Repo.EnableOCI, q.Repo.Proxy, helm.WithChartPaths(s.chartPaths)).GetIndex(true)
 	if err != nil {
@@ -2442,6 +3029,13 @@ func (s *Service) ResolveRevision(ctx context.Context, q *apiclient.ResolveRevis
 	ambiguousRevision := q.AmbiguousRevision
 	var revision string
 	var source = app.Spec.GetSource()
+	if app.Spec.HasMultipleSources() {
+		sources := app.Spec.GetSources()
+		if q.SourceIndex < 0 || q.SourceIndex >= int64(len(sources)) {
+			return &apiclient.ResolveRevisionResponse{Revision: "", AmbiguousRevision: ""}, status.Errorf(codes.InvalidArgument, "source index %d is out of range", q.SourceIndex)
+		}
+		source = sources[q.SourceIndex]
+	}
 	if source.IsHelm() {
 		_, revision, err := s.newHelmClientResolveRevision(repo, ambiguousRevision, source.Chart, true)
 
@@ -2468,7 +3062,7 @@ func (s *Service) ResolveRevision(ctx context.Context, q *apiclient.ResolveRevis
 	}
 }
 
-func (s *Service) GetGitFiles(_ context.Context, request *apiclient.GitFilesRequest) (*apiclient.GitFilesResponse, error) {
+func (s *Service) GetGitFiles(ctx context.Context, request *apiclient.GitFilesRequest) (*apiclient.GitFilesResponse, error) {
 	repo := request.GetRepo()
 	revision := request.GetRevision()
 	gitPath := request.GetPath()
@@ -2481,7 +3075,7 @@ func (s *Service) GetGitFiles(_ context.Context, request *apiclient.GitFilesRequ
 		return nil, status.Error(codes.InvalidArgument, "must pass a valid repo")
 	}
 
-	gitClient, revision, err := s.newClientResolveRevision(repo, revision, git.WithCache(s.cache, true))
+	gitClient, revision, err := s.newClientResolveRevision(ctx, repo, revision, git.WithCache(s.cache, true))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to resolve git revision %s: %v", revision, err)
 	}
@@ -2499,7 +3093,7 @@ func (s *Service) GetGitFiles(_ context.Context, request *apiclient.GitFilesRequ
 
 	// cache miss, generate the results
 	closer, err := s.repoLock.Lock(gitClient.Root(), revision, true, func() (goio.Closer, error) {
-		return s.checkoutRevision(gitClient, revision, request.GetSubmoduleEnabled())
+		return s.checkoutRevision(gitClient, revision, repo.IsSubmoduleEnabled(request.GetSubmoduleEnabled()))
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to checkout git repo %s with revision %s pattern %s: %v", repo.Repo, revision, gitPath, err)
@@ -2511,6 +3105,11 @@ func (s *Service) GetGitFiles(_ context.Context, request *apiclient.GitFilesRequ
 		return nil, status.Errorf(codes.Internal, "unable to list files. repo %s with revision %s pattern %s: %v", repo.Repo, revision, gitPath, err)
 	}
 	log.Debugf("listed %d git files from %s under %s", len(gitFiles), repo.Repo, gitPath)
+	// the contents of the files are returned, so symlinks must not expose files outside of the repository
+	gitFiles, err = filterSymlinks(gitClient.Root(), gitFiles, false)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to list files. repo %s with revision %s pattern %s: %v", repo.Repo, revision, gitPath, err)
+	}
 
 	res := make(map[string][]byte)
 	for _, filePath := range gitFiles {
@@ -2531,7 +3130,7 @@ func (s *Service) GetGitFiles(_ context.Context, request *apiclient.GitFilesRequ
 	}, nil
 }
 
-func (s *Service) GetGitDirectories(_ context.Context, request *apiclient.GitDirectoriesRequest) (*apiclient.GitDirectoriesResponse, error) {
+func (s *Service) GetGitDirectories(ctx context.Context, request *apiclient.GitDirectoriesRequest) (*apiclient.GitDirectoriesResponse, error) {
 	repo := request.GetRepo()
 	revision := request.GetRevision()
 
@@ -2539,7 +3138,7 @@ func (s *Service) GetGitDirectories(_ context.Context, request *apiclient.GitDir
 		return nil, status.Error(codes.InvalidArgument, "must pass a valid repo")
 	}
 
-	gitClient, revision, err := s.newClientResolveRevision(repo, revision, git.WithCache(s.cache, true))
+	gitClient, revision, err := s.newClientResolveRevision(ctx, repo, revision, git.WithCache(s.cache, true))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to resolve git revision %s: %v", revision, err)
 	}
@@ -2557,14 +3156,32 @@ func (s *Service) GetGitDirectories(_ context.Context, request *apiclient.GitDir
 
 	// cache miss, generate the results
 	closer, err := s.repoLock.Lock(gitClient.Root(), revision, true, func() (goio.Closer, error) {
-		return s.checkoutRevision(gitClient, revision, request.GetSubmoduleEnabled())
+		return s.checkoutRevision(gitClient, revision, repo.IsSubmoduleEnabled(request.GetSubmoduleEnabled()))
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to checkout git repo %s with revision %s: %v", repo.Repo, revision, err)
 	}
 	defer io.Close(closer)
 
-	repoRoot := gitClient.Root()
+	paths, err := listDirectories(gitClient.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("found %d git paths from %s", len(paths), repo.Repo)
+	err = s.cache.SetGitDirectories(repo.Repo, revision, paths)
+	if err != nil {
+		log.Warnf("error caching git directories for repo %s with revision %s: %v", repo.Repo, revision, err)
+	}
+
+	return &apiclient.GitDirectoriesResponse{
+		Paths: paths,
+	}, nil
+}
+
+// listDirectories returns the paths of the directories under the given repo root, relative to the root. Directories
+// starting with "." are skipped.
+func listDirectories(repoRoot string) ([]string, error) {
 	var paths []string
 	if err := filepath.WalkDir(repoRoot, func(path string, entry fs.DirEntry, fnErr error) error {
 		if fnErr != nil {
@@ -2594,14 +3211,132 @@ func (s *Service) GetGitDirectories(_ context.Context, request *apiclient.GitDir
 	}); err != nil {
 		return nil, err
 	}
+	return paths, nil
+}
 
-	log.Debugf("found %d git paths from %s", len(paths), repo.Repo)
-	err = s.cache.SetGitDirectories(repo.Repo, revision, paths)
+// gitPathsChunkSize is the maximum number of paths sent in a single message of a ListGitPaths stream
+const gitPathsChunkSize = 1000
+
+// ListGitPaths streams the file or directory paths of the given repo which match any of the requested globs in chunks
+func (s *Service) ListGitPaths(request *apiclient.ListGitPathsRequest, stream apiclient.RepoServerService_ListGitPathsServer) error {
+	repo := request.GetRepo()
+	revision := request.GetRevision()
+	maxResults := request.GetMaxResults()
+
+	if repo == nil {
+		return status.Error(codes.InvalidArgument, "must pass a valid repo")
+	}
+	if maxResults < 0 {
+		return status.Errorf(codes.InvalidArgument, "max results must not be negative: %d", maxResults)
+	}
+	for _, pattern := range request.GetGlobs() {
+		if !doublestar.ValidatePattern(pattern) {
+			return status.Errorf(codes.InvalidArgument, "invalid glob %q", pattern)
+		}
+	}
+
+	gitClient, revision, err := s.newClientResolveRevision(stream.Context(), repo, revision, git.WithCache(s.cache, true))
 	if err != nil {
-		log.Warnf("error caching git directories for repo %s with revision %s: %v", repo.Repo, revision, err)
+		return status.Errorf(codes.Internal, "unable to resolve git revision %s: %v", revision, err)
 	}
 
-	return &apiclient.GitDirectoriesResponse{
-		Paths: paths,
-	}, nil
+	s.metricsServer.IncPendingRepoRequest(repo.Repo)
+	defer s.metricsServer.DecPendingRepoRequest(repo.Repo)
+
+	closer, err := s.repoLock.Lock(gitClient.Root(), revision, true, func() (goio.Closer, error) {
+		return s.checkoutRevision(gitClient, revision, repo.IsSubmoduleEnabled(request.GetSubmoduleEnabled()))
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to checkout git repo %s with revision %s: %v", repo.Repo, revision, err)
+	}
+	defer io.Close(closer)
+
+	var paths []string
+	if request.GetDirectories() {
+		paths, err = listDirectories(gitClient.Root())
+	} else {
+		paths, err = gitClient.LsFiles(".", false)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to list paths. repo %s with revision %s: %v", repo.Repo, revision, err)
+	}
+	paths, err = filterSymlinks(gitClient.Root(), paths, request.GetSkipSymlinks())
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to inspect symlinks. repo %s with revision %s: %v", repo.Repo, revision, err)
+	}
+
+	res := &apiclient.GitPathsResponse{}
+	var count int64
+	for _, path := range paths {
+		if !matchesAnyGlob(path, request.GetGlobs(), request.GetCaseInsensitive()) {
+			continue
+		}
+		if maxResults > 0 && count == maxResults {
+			res.Truncated = true
+			break
+		}
+		res.Paths = append(res.Paths, path)
+		count++
+		if len(res.Paths) == gitPathsChunkSize {
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+			res = &apiclient.GitPathsResponse{}
+		}
+	}
+	log.Debugf("listed %d git paths from %s", count, repo.Repo)
+	// the last chunk is always sent, so that the client learns whether the paths were truncated
+	return stream.Send(res)
+}
+
+// matchesAnyGlob returns whether the given path matches any of the given globs, or true if there are no globs
+func matchesAnyGlob(path string, globs []string, caseInsensitive bool) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	if caseInsensitive {
+		path = strings.ToLower(path)
+	}
+	for _, pattern := range globs {
+		if caseInsensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterSymlinks removes the paths of symlinks which do not resolve to a location inside the repo root, or of all
+// symlinks if skipSymlinks is set. The paths are relative to the repo root
+func filterSymlinks(repoRoot string, paths []string, skipSymlinks bool) ([]string, error) {
+	absRoot, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving repo root: %w", err)
+	}
+	absRoot, err = filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving repo root: %w", err)
+	}
+	filtered := make([]string, 0, len(paths))
+	for _, path := range paths {
+		fullPath := filepath.Join(absRoot, path)
+		fi, err := os.Lstat(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if files.IsSymlink(fi) {
+			if skipSymlinks {
+				continue
+			}
+			target, err := filepath.EvalSymlinks(fullPath)
+			if err != nil || !files.Inbound(target, absRoot) {
+				log.Warnf("symlink %s does not resolve to a path inside of the repository, removing it", path)
+				continue
+			}
+		}
+		filtered = append(filtered, path)
+	}
+	return filtered, nil
 }
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	goio "io"
 	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path"
@@ -17,13 +19,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/yaml"
@@ -42,6 +49,7 @@ import (
 	helmmocks "github.com/argoproj/argo-cd/v2/util/helm/mocks"
 	"github.com/argoproj/argo-cd/v2/util/io"
 	iomocks "github.com/argoproj/argo-cd/v2/util/io/mocks"
+	pathutil "github.com/argoproj/argo-cd/v2/util/io/path"
 )
 
 const testSignature = `gpg: Signature made Wed Feb 26 23:22:34 2020 CET
@@ -804,6 +812,40 @@ func TestManifestGenErrorCacheRespectsNoCache(t *testing.T) {
 
 }
 
+func TestGenerateHelmWithReleaseMetadata(t *testing.T) {
+	service := newService("./testdata/release-metadata")
+
+	generate := func(helm *argoappv1.ApplicationSourceHelm) map[string]interface{} {
+		res, err := service.GenerateManifest(context.Background(), &apiclient.ManifestRequest{
+			Repo:               &argoappv1.Repository{},
+			AppName:            "test",
+			Namespace:          "destination",
+			ApplicationSource:  &argoappv1.ApplicationSource{Path: ".", Helm: helm},
+			ProjectName:        "something",
+			ProjectSourceRepos: []string{"*"},
+			NoCache:            true,
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Manifests, 1)
+		obj := unstructured.Unstructured{}
+		require.NoError(t, json.Unmarshal([]byte(res.Manifests[0]), &obj))
+		data, _, err := unstructured.NestedMap(obj.Object, "data")
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		data := generate(nil)
+		assert.Equal(t, "test", data["name"])
+		assert.Equal(t, "destination", data["namespace"])
+	})
+	t.Run("Overrides", func(t *testing.T) {
+		data := generate(&argoappv1.ApplicationSourceHelm{ReleaseName: "$ARGOCD_APP_NAME-release", Namespace: "simulated"})
+		assert.Equal(t, "test-release", data["name"])
+		assert.Equal(t, "simulated", data["namespace"])
+	})
+}
+
 func TestGenerateHelmWithValues(t *testing.T) {
 	service := newService("../../util/helm/testdata/redis")
 
@@ -1027,6 +1069,84 @@ func TestGenerateHelmWithURL(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_downloadRemoteValueFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/shared/large.yaml" {
+			_, _ = w.Write(make([]byte, maxRemoteValueFileSize+1))
+			return
+		}
+		_, _ = w.Write([]byte("replicaCount: 3"))
+	}))
+	defer server.Close()
+
+	valueFiles := []pathutil.ResolvedFilePath{
+		"/tmp/local-values.yaml",
+		pathutil.ResolvedFilePath(server.URL + "/shared/values.yaml"),
+		"https://example.com/other/values.yaml",
+	}
+
+	t.Run("Repository credentials", func(t *testing.T) {
+		q := &apiclient.ManifestRequest{
+			Repos:              []*argoappv1.Repository{{Repo: server.URL + "/shared", Username: "user", Password: "pass"}},
+			ProjectSourceRepos: []string{"*"},
+		}
+		resolved, cleanup, err := downloadRemoteValueFiles(context.Background(), valueFiles, q)
+		require.NoError(t, err)
+		defer cleanup()
+		require.Len(t, resolved, 3)
+		assert.Equal(t, valueFiles[0], resolved[0])
+		assert.Equal(t, valueFiles[2], resolved[2])
+		data, err := os.ReadFile(string(resolved[1]))
+		require.NoError(t, err)
+		assert.Equal(t, "replicaCount: 3", string(data))
+	})
+
+	t.Run("Credential template", func(t *testing.T) {
+		q := &apiclient.ManifestRequest{
+			HelmRepoCreds:      []*argoappv1.RepoCreds{{URL: server.URL, Username: "user", Password: "pass"}},
+			ProjectSourceRepos: []string{"*"},
+		}
+		resolved, cleanup, err := downloadRemoteValueFiles(context.Background(), valueFiles, q)
+		require.NoError(t, err)
+		defer cleanup()
+		assert.NotEqual(t, valueFiles[1], resolved[1])
+	})
+
+	t.Run("Repository not permitted", func(t *testing.T) {
+		q := &apiclient.ManifestRequest{
+			Repos:              []*argoappv1.Repository{{Repo: server.URL + "/shared", Username: "user", Password: "pass"}},
+			ProjectSourceRepos: []string{"https://github.com/*"},
+		}
+		resolved, cleanup, err := downloadRemoteValueFiles(context.Background(), valueFiles, q)
+		require.NoError(t, err)
+		defer cleanup()
+		assert.Equal(t, valueFiles, resolved)
+	})
+
+	t.Run("Wrong credentials", func(t *testing.T) {
+		q := &apiclient.ManifestRequest{
+			Repos:              []*argoappv1.Repository{{Repo: server.URL + "/shared", Username: "user", Password: "wrong"}},
+			ProjectSourceRepos: []string{"*"},
+		}
+		_, _, err := downloadRemoteValueFiles(context.Background(), valueFiles, q)
+		assert.ErrorContains(t, err, "401 Unauthorized")
+	})
+
+	t.Run("File too large", func(t *testing.T) {
+		q := &apiclient.ManifestRequest{
+			Repos:              []*argoappv1.Repository{{Repo: server.URL + "/shared", Username: "user", Password: "pass"}},
+			ProjectSourceRepos: []string{"*"},
+		}
+		_, _, err := downloadRemoteValueFiles(context.Background(), []pathutil.ResolvedFilePath{pathutil.ResolvedFilePath(server.URL + "/shared/large.yaml")}, q)
+		assert.ErrorContains(t, err, "exceeds the maximum size")
+	})
+}
+
 // The requested value file (`../minio/values.yaml`) is outside the repo directory
 // (`~/go/src/github.com/argoproj/argo-cd/util/helm/testdata/redis`), so it is blocked
 func TestGenerateHelmWithValuesDirectoryTraversalOutsideRepo(t *testing.T) {
@@ -1377,6 +1497,49 @@ func TestGetHelmCharts(t *testing.T) {
 	assert.EqualValues(t, []string{"1.0.0", "1.1.0"}, item2.Versions)
 }
 
+func TestGetLoadReport(t *testing.T) {
+	root := t.TempDir()
+	service := newService(root)
+	repoPaths := io.NewRandomizedTempPaths(root)
+	repoPath, err := repoPaths.GetPath("https://github.com/argoproj/argocd-example-apps")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "guestbook"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "guestbook", "deployment.yaml"), make([]byte, 100), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), make([]byte, 20), 0600))
+	service.gitRepoPaths = repoPaths
+	service.activeOperations = 2
+	service.queuedOperations = 3
+
+	report, err := service.GetLoadReport(context.Background(), &empty.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), report.ActiveGenerations)
+	assert.Equal(t, int64(3), report.QueueDepth)
+	assert.Equal(t, int64(1), report.ParallelismLimit)
+	assert.Equal(t, int64(120), report.DiskUsageBytes)
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	assert.Equal(t, hostname, report.Replica)
+
+	t.Run("DiskUsageIsCachedWithinRefreshInterval", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "values.yaml"), make([]byte, 30), 0600))
+		report, err := service.GetLoadReport(context.Background(), &empty.Empty{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(120), report.DiskUsageBytes)
+	})
+
+	t.Run("DiskUsageIsRecomputedInBackgroundAfterRefreshInterval", func(t *testing.T) {
+		now := time.Now().Add(2 * diskUsageRefreshInterval)
+		service.now = func() time.Time { return now }
+		report, err := service.GetLoadReport(context.Background(), &empty.Empty{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(120), report.DiskUsageBytes)
+		assert.Eventually(t, func() bool {
+			report, err := service.GetLoadReport(context.Background(), &empty.Empty{})
+			return err == nil && report.DiskUsageBytes == 150
+		}, 10*time.Second, 10*time.Millisecond)
+	})
+}
+
 func TestGetRevisionMetadata(t *testing.T) {
 	service, gitClient := newServiceWithMocks("../..", false)
 	now := time.Now()
@@ -1531,6 +1694,29 @@ func TestService_newHelmClientResolveRevision(t *testing.T) {
 	})
 }
 
+func Test_mergeSourceParameters_AppInstanceName(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, ".argocd-source-guestbook.yaml"), []byte("kustomize:\n  namePrefix: control-plane-\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, ".argocd-source-team-a_guestbook.yaml"), []byte("kustomize:\n  namePrefix: team-a-\n"), 0644))
+
+	namePrefix := func(t *testing.T, app *argoappv1.Application) string {
+		source := &argoappv1.ApplicationSource{Path: "."}
+		// the API server and the application controller request the manifests of an app by its instance name
+		require.NoError(t, mergeSourceParameters(source, path, app.InstanceName("argocd")))
+		require.NotNil(t, source.Kustomize)
+		return source.Kustomize.NamePrefix
+	}
+
+	t.Run("App in the control plane namespace", func(t *testing.T) {
+		app := &argoappv1.Application{ObjectMeta: metav1.ObjectMeta{Name: "guestbook", Namespace: "argocd"}}
+		assert.Equal(t, "control-plane-", namePrefix(t, app))
+	})
+	t.Run("App outside the control plane namespace", func(t *testing.T) {
+		app := &argoappv1.Application{ObjectMeta: metav1.ObjectMeta{Name: "guestbook", Namespace: "team-a"}}
+		assert.Equal(t, "team-a-", namePrefix(t, app))
+	})
+}
+
 func TestGetAppDetailsWithAppParameterFile(t *testing.T) {
 	t.Run("No app name set and app specific file exists", func(t *testing.T) {
 		service := newService(".")
@@ -2490,6 +2676,22 @@ func TestResolveRevisionNegativeScenarios(t *testing.T) {
 
 }
 
+func TestResolveRevisionSourceIndexOutOfRange(t *testing.T) {
+	service := newService(".")
+	repo := &argoappv1.Repository{Repo: "https://github.com/argoproj/argo-cd"}
+	app := &argoappv1.Application{Spec: argoappv1.ApplicationSpec{Sources: argoappv1.ApplicationSources{
+		{RepoURL: "https://github.com/argoproj/argo-cd"},
+		{RepoURL: "https://argoproj.github.io/argo-helm", Chart: "argo-cd"},
+	}}}
+	_, err := service.ResolveRevision(context.Background(), &apiclient.ResolveRevisionRequest{
+		Repo:              repo,
+		App:               app,
+		AmbiguousRevision: "v2.2.2",
+		SourceIndex:       2,
+	})
+	assert.ErrorContains(t, err, "source index 2 is out of range")
+}
+
 func TestDirectoryPermissionInitializer(t *testing.T) {
 	dir := t.TempDir()
 
@@ -2625,18 +2827,38 @@ func Test_populateHelmAppDetails(t *testing.T) {
 	}
 	appPath, err := filepath.Abs("./testdata/values-files/")
 	require.NoError(t, err)
-	err = populateHelmAppDetails(&res, appPath, appPath, &q, emptyTempPaths)
+	err = populateHelmAppDetails(context.Background(), &res, appPath, appPath, &q, emptyTempPaths)
 	require.NoError(t, err)
 	assert.Len(t, res.Helm.Parameters, 3)
 	assert.Len(t, res.Helm.ValueFiles, 4)
 }
 
+func Test_populateHelmAppDetails_valuesSchema(t *testing.T) {
+	var emptyTempPaths = io.NewRandomizedTempPaths(t.TempDir())
+	res := apiclient.RepoAppDetailsResponse{}
+	q := apiclient.RepoServerAppDetailsQuery{Repo: &argoappv1.Repository{}, Source: &argoappv1.ApplicationSource{}}
+	appPath, err := filepath.Abs("./testdata/values-schema/")
+	require.NoError(t, err)
+	err = populateHelmAppDetails(context.Background(), &res, appPath, appPath, &q, emptyTempPaths)
+	require.NoError(t, err)
+	schema, err := os.ReadFile(filepath.Join(appPath, "values.schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(schema), res.Helm.ValuesSchema)
+
+	res = apiclient.RepoAppDetailsResponse{}
+	appPath, err = filepath.Abs("./testdata/values-files/")
+	require.NoError(t, err)
+	err = populateHelmAppDetails(context.Background(), &res, appPath, appPath, &q, emptyTempPaths)
+	require.NoError(t, err)
+	assert.Empty(t, res.Helm.ValuesSchema)
+}
+
 func Test_populateHelmAppDetails_values_symlinks(t *testing.T) {
 	var emptyTempPaths = io.NewRandomizedTempPaths(t.TempDir())
 	t.Run("inbound", func(t *testing.T) {
 		res := apiclient.RepoAppDetailsResponse{}
 		q := apiclient.RepoServerAppDetailsQuery{Repo: &argoappv1.Repository{}, Source: &argoappv1.ApplicationSource{}}
-		err := populateHelmAppDetails(&res, "./testdata/in-bounds-values-file-link/", "./testdata/in-bounds-values-file-link/", &q, emptyTempPaths)
+		err := populateHelmAppDetails(context.Background(), &res, "./testdata/in-bounds-values-file-link/", "./testdata/in-bounds-values-file-link/", &q, emptyTempPaths)
 		require.NoError(t, err)
 		assert.NotEmpty(t, res.Helm.Values)
 		assert.NotEmpty(t, res.Helm.Parameters)
@@ -2645,7 +2867,7 @@ func Test_populateHelmAppDetails_values_symlinks(t *testing.T) {
 	t.Run("out of bounds", func(t *testing.T) {
 		res := apiclient.RepoAppDetailsResponse{}
 		q := apiclient.RepoServerAppDetailsQuery{Repo: &argoappv1.Repository{}, Source: &argoappv1.ApplicationSource{}}
-		err := populateHelmAppDetails(&res, "./testdata/out-of-bounds-values-file-link/", "./testdata/out-of-bounds-values-file-link/", &q, emptyTempPaths)
+		err := populateHelmAppDetails(context.Background(), &res, "./testdata/out-of-bounds-values-file-link/", "./testdata/out-of-bounds-values-file-link/", &q, emptyTempPaths)
 		require.NoError(t, err)
 		assert.Empty(t, res.Helm.Values)
 		assert.Empty(t, res.Helm.Parameters)
@@ -2683,6 +2905,45 @@ func TestGetHelmRepo_NamedRepos(t *testing.T) {
 	assert.Equal(t, helmRepos[0].Repo, "https://example.com")
 }
 
+func TestGetHelmRepos_CredentialTemplates(t *testing.T) {
+	t.Run("UnconfiguredRepo", func(t *testing.T) {
+		helmRepos, err := getHelmRepos("../../util/helm/testdata/dependency", nil, []*argoappv1.RepoCreds{
+			{URL: "https://charts.bitnami.com", Username: "test", Password: "secret"},
+		})
+		require.NoError(t, err)
+		require.Len(t, helmRepos, 2)
+		assert.Equal(t, "https://charts.bitnami.com/bitnami", helmRepos[0].Repo)
+		assert.Equal(t, "test", helmRepos[0].Username)
+		assert.Equal(t, "secret", helmRepos[0].Password)
+		assert.Empty(t, helmRepos[1].Username)
+	})
+	t.Run("ConfiguredRepoWithoutCredentials", func(t *testing.T) {
+		repos := []*argoappv1.Repository{{Repo: "https://charts.bitnami.com/bitnami/"}}
+		helmRepos, err := getHelmRepos("../../util/helm/testdata/dependency", repos, []*argoappv1.RepoCreds{
+			{URL: "https://charts.bitnami.com", Username: "test", Password: "secret"},
+		})
+		require.NoError(t, err)
+		require.Len(t, helmRepos, 2)
+		assert.Equal(t, "https://charts.bitnami.com/bitnami/", helmRepos[0].Repo)
+		assert.Equal(t, "https:--charts.bitnami.com-bitnami", helmRepos[0].Name)
+		assert.Equal(t, "test", helmRepos[0].Username)
+		// the configured repository is not modified
+		assert.Empty(t, repos[0].Username)
+		assert.Empty(t, repos[0].Name)
+	})
+	t.Run("ConfiguredRepoWithCredentials", func(t *testing.T) {
+		repos := []*argoappv1.Repository{{Name: "bitnami", Repo: "https://charts.bitnami.com/bitnami", Username: "configured", Password: "configured"}}
+		helmRepos, err := getHelmRepos("../../util/helm/testdata/dependency", repos, []*argoappv1.RepoCreds{
+			{URL: "https://charts.bitnami.com", Username: "test", Password: "secret"},
+		})
+		require.NoError(t, err)
+		require.Len(t, helmRepos, 2)
+		assert.Equal(t, "bitnami", helmRepos[0].Name)
+		assert.Equal(t, "configured", helmRepos[0].Username)
+		assert.Equal(t, "configured", helmRepos[0].Password)
+	})
+}
+
 func Test_getResolvedValueFiles(t *testing.T) {
 	tempDir := t.TempDir()
 	paths := io.NewRandomizedTempPaths(tempDir)
@@ -2918,6 +3179,107 @@ func TestGetGitDirectories(t *testing.T) {
 	assert.ElementsMatch(t, []string{"app", "app/bar", "app/foo/bar", "somedir", "app/foo"}, directories.GetPaths())
 }
 
+type fakeListGitPathsServer struct {
+	grpc.ServerStream
+	responses []*apiclient.GitPathsResponse
+}
+
+func (f *fakeListGitPathsServer) Send(res *apiclient.GitPathsResponse) error {
+	f.responses = append(f.responses, res)
+	return nil
+}
+
+func (f *fakeListGitPathsServer) Context() context.Context {
+	return context.TODO()
+}
+
+func TestListGitPaths(t *testing.T) {
+	root := "./testdata/git-files-dirs"
+	s, _ := newServiceWithOpt(func(gitClient *gitmocks.Client, helmClient *helmmocks.Client, paths *iomocks.TempPaths) {
+		gitClient.On("Init").Return(nil)
+		gitClient.On("Fetch", mock.Anything).Return(nil)
+		gitClient.On("Checkout", mock.Anything, mock.Anything).Return(nil)
+		gitClient.On("LsRemote", "HEAD").Return("632039659e542ed7de0c170a4fcc1c571b288fc0", nil)
+		gitClient.On("LsFiles", ".").Return([]string{"config.yaml", "app/foo/config.yaml", "app/foo/bar/config.yaml", "somedir/config.yaml"}, nil)
+		gitClient.On("Root").Return(root)
+		paths.On("GetPath", mock.Anything).Return(root, nil)
+		paths.On("GetPathIfExists", mock.Anything).Return(root, nil)
+	}, root)
+	repo := &argoappv1.Repository{Repo: "a-url.com"}
+
+	t.Run("Files", func(t *testing.T) {
+		stream := &fakeListGitPathsServer{}
+		err := s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", Globs: []string{"app/**"}}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.responses, 1)
+		assert.ElementsMatch(t, []string{"app/foo/config.yaml", "app/foo/bar/config.yaml"}, stream.responses[0].GetPaths())
+		assert.False(t, stream.responses[0].GetTruncated())
+	})
+
+	t.Run("Directories", func(t *testing.T) {
+		stream := &fakeListGitPathsServer{}
+		err := s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", Directories: true}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.responses, 1)
+		assert.ElementsMatch(t, []string{"app", "app/bar", "app/foo/bar", "somedir", "app/foo"}, stream.responses[0].GetPaths())
+	})
+
+	t.Run("MaxResults", func(t *testing.T) {
+		stream := &fakeListGitPathsServer{}
+		err := s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", Directories: true, MaxResults: 2}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.responses, 1)
+		assert.Len(t, stream.responses[0].GetPaths(), 2)
+		assert.True(t, stream.responses[0].GetTruncated())
+	})
+
+	t.Run("CaseInsensitive", func(t *testing.T) {
+		stream := &fakeListGitPathsServer{}
+		err := s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", Globs: []string{"APP/**/Config.YAML"}, CaseInsensitive: true}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.responses, 1)
+		assert.ElementsMatch(t, []string{"app/foo/config.yaml", "app/foo/bar/config.yaml"}, stream.responses[0].GetPaths())
+
+		stream = &fakeListGitPathsServer{}
+		err = s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", Globs: []string{"APP/**/Config.YAML"}}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.responses, 1)
+		assert.Empty(t, stream.responses[0].GetPaths())
+	})
+
+	t.Run("InvalidArguments", func(t *testing.T) {
+		err := s.ListGitPaths(&apiclient.ListGitPathsRequest{Revision: "HEAD"}, &fakeListGitPathsServer{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		err = s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", Globs: []string{"app/["}}, &fakeListGitPathsServer{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		err = s.ListGitPaths(&apiclient.ListGitPathsRequest{Repo: repo, Revision: "HEAD", MaxResults: -1}, &fakeListGitPathsServer{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestFilterSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config.yaml"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.yaml"), []byte("{}"), 0644))
+	require.NoError(t, os.Symlink("config.yaml", filepath.Join(root, "inbound.yaml")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(root, "outbound.yaml")))
+	require.NoError(t, os.Symlink("missing.yaml", filepath.Join(root, "broken.yaml")))
+	paths := []string{"config.yaml", "inbound.yaml", "outbound.yaml", "broken.yaml"}
+
+	t.Run("OutOfBoundsSymlinks", func(t *testing.T) {
+		filtered, err := filterSymlinks(root, paths, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"config.yaml", "inbound.yaml"}, filtered)
+	})
+
+	t.Run("SkipSymlinks", func(t *testing.T) {
+		filtered, err := filterSymlinks(root, paths, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"config.yaml"}, filtered)
+	})
+}
+
 func TestErrorGetGitFiles(t *testing.T) {
 	type fields struct {
 		service *Service
@@ -3010,6 +3372,32 @@ func TestGetGitFiles(t *testing.T) {
 	assert.Equal(t, expected, fileResponse.GetMap())
 }
 
+func TestGetGitFiles_OutOfBoundsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config.json"), []byte(`{"cluster":"a"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.json"), []byte(`{"password":"s3cr3t"}`), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.json"), filepath.Join(root, "secret.json")))
+	s, _ := newServiceWithOpt(func(gitClient *gitmocks.Client, helmClient *helmmocks.Client, paths *iomocks.TempPaths) {
+		gitClient.On("Init").Return(nil)
+		gitClient.On("Fetch", mock.Anything).Return(nil)
+		gitClient.On("Checkout", mock.Anything, mock.Anything).Return(nil)
+		gitClient.On("LsRemote", "HEAD").Return("632039659e542ed7de0c170a4fcc1c571b288fc0", nil)
+		gitClient.On("Root").Return(root)
+		gitClient.On("LsFiles", mock.Anything, mock.Anything).Return([]string{"config.json", "secret.json"}, nil)
+		paths.On("GetPath", mock.Anything).Return(root, nil)
+		paths.On("GetPathIfExists", mock.Anything).Return(root, nil)
+	}, root)
+
+	fileResponse, err := s.GetGitFiles(context.TODO(), &apiclient.GitFilesRequest{
+		Repo:     &argoappv1.Repository{Repo: "a-url.com"},
+		Revision: "HEAD",
+		Path:     "*.json",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"config.json": []byte(`{"cluster":"a"}`)}, fileResponse.GetMap())
+}
+
 func Test_getRepoSanitizerRegex(t *testing.T) {
 	r := getRepoSanitizerRegex("/tmp/_argocd-repo")
 	msg := r.ReplaceAllString("error message containing /tmp/_argocd-repo/SENSITIVE and other stuff", "<path to cached source>")
@@ -3017,3 +3405,191 @@ func Test_getRepoSanitizerRegex(t *testing.T) {
 	msg = r.ReplaceAllString("error message containing /tmp/_argocd-repo/SENSITIVE/with/trailing/path and other stuff", "<path to cached source>")
 	assert.Equal(t, "error message containing <path to cached source>/with/trailing/path and other stuff", msg)
 }
+
+func Test_getSparseCheckoutPaths(t *testing.T) {
+	t.Run("Directory", func(t *testing.T) {
+		paths := getSparseCheckoutPaths(&argoappv1.ApplicationSource{Path: "apps/foo/"}, nil)
+		assert.Equal(t, []string{"apps/foo"}, paths)
+	})
+	t.Run("RepositoryRoot", func(t *testing.T) {
+		assert.Nil(t, getSparseCheckoutPaths(&argoappv1.ApplicationSource{Path: "."}, nil))
+		assert.Nil(t, getSparseCheckoutPaths(&argoappv1.ApplicationSource{Path: ""}, nil))
+	})
+	t.Run("HelmValueFiles", func(t *testing.T) {
+		paths := getSparseCheckoutPaths(&argoappv1.ApplicationSource{
+			RepoURL: "https://github.com/argoproj/argocd-example-apps",
+			Path:    "apps/foo",
+			Helm: &argoappv1.ApplicationSourceHelm{
+				ValueFiles:     []string{"values.yaml", "../../values/foo.yaml", "/common/values.yaml", "https://example.com/values.yaml", "$values/foo.yaml"},
+				FileParameters: []argoappv1.HelmFileParameter{{Name: "config", Path: "../../config/foo.json"}},
+			},
+		}, map[string]*argoappv1.RefTarget{"$values": {Repo: argoappv1.Repository{Repo: "https://github.com/argoproj/values"}}})
+		assert.Equal(t, []string{"apps/foo", "apps/foo/values.yaml", "values/foo.yaml", "common/values.yaml", "config/foo.json"}, paths)
+	})
+	t.Run("HelmValueFilesWithEnvironmentVariables", func(t *testing.T) {
+		assert.Nil(t, getSparseCheckoutPaths(&argoappv1.ApplicationSource{
+			Path: "apps/foo",
+			Helm: &argoappv1.ApplicationSourceHelm{ValueFiles: []string{"values-$ARGOCD_APP_NAME.yaml"}},
+		}, nil))
+	})
+	t.Run("HelmValueFilesOutOfBounds", func(t *testing.T) {
+		assert.Nil(t, getSparseCheckoutPaths(&argoappv1.ApplicationSource{
+			Path: "apps/foo",
+			Helm: &argoappv1.ApplicationSourceHelm{ValueFiles: []string{"../../../values.yaml"}},
+		}, nil))
+	})
+	t.Run("KustomizePatchesAndComponents", func(t *testing.T) {
+		paths := getSparseCheckoutPaths(&argoappv1.ApplicationSource{
+			Path: "overlays/prod",
+			Kustomize: &argoappv1.ApplicationSourceKustomize{
+				Patches:    argoappv1.KustomizePatches{{Path: "../../patches/replicas.yaml"}, {Patch: "inline"}},
+				Components: []string{"../../components/monitoring"},
+			},
+		}, nil)
+		assert.Equal(t, []string{"overlays/prod", "patches/replicas.yaml", "components/monitoring"}, paths)
+	})
+	t.Run("SameRepositoryReferenced", func(t *testing.T) {
+		assert.Nil(t, getSparseCheckoutPaths(&argoappv1.ApplicationSource{
+			RepoURL: "https://github.com/argoproj/argocd-example-apps",
+			Path:    "apps/foo",
+		}, map[string]*argoappv1.RefTarget{"$values": {Repo: argoappv1.Repository{Repo: "https://github.com/argoproj/argocd-example-apps.git"}}}))
+	})
+}
+
+func Test_getSparseCheckoutDependencies(t *testing.T) {
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		root := t.TempDir()
+		for p, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(p)), 0700))
+			require.NoError(t, os.WriteFile(filepath.Join(root, p), []byte(content), 0600))
+		}
+		return root
+	}
+	t.Run("Kustomization", func(t *testing.T) {
+		root := writeFiles(t, map[string]string{
+			"overlays/prod/kustomization.yaml": `resources:
+- ../../base
+- deployment.yaml
+- https://github.com/argoproj/argo-cd//manifests/cluster-install?ref=v2.7.0
+components:
+- ../../components/monitoring
+patches:
+- path: ../../patches/replicas.yaml
+configMapGenerator:
+- name: config
+  files:
+  - config.json=../../config/prod.json
+`,
+		})
+		deps, complete, err := getSparseCheckoutDependencies(root, []string{"overlays/prod"})
+		require.NoError(t, err)
+		assert.False(t, complete)
+		assert.ElementsMatch(t, []string{"base", "overlays/prod/deployment.yaml", "components/monitoring", "patches/replicas.yaml", "config/prod.json"}, deps)
+	})
+	t.Run("HelmDependencies", func(t *testing.T) {
+		root := writeFiles(t, map[string]string{
+			"charts/app/Chart.yaml": `dependencies:
+- name: common
+  repository: file://../common
+- name: redis
+  repository: https://charts.bitnami.com/bitnami
+`,
+		})
+		deps, complete, err := getSparseCheckoutDependencies(root, []string{"charts/app"})
+		require.NoError(t, err)
+		assert.False(t, complete)
+		assert.Equal(t, []string{"charts/common"}, deps)
+	})
+	t.Run("ReferenceOutsideOfRepository", func(t *testing.T) {
+		root := writeFiles(t, map[string]string{
+			"apps/foo/kustomization.yaml": "resources: [../../../base]",
+		})
+		_, complete, err := getSparseCheckoutDependencies(root, []string{"apps/foo"})
+		require.NoError(t, err)
+		assert.True(t, complete)
+	})
+	t.Run("MissingPath", func(t *testing.T) {
+		deps, complete, err := getSparseCheckoutDependencies(t.TempDir(), []string{"apps/foo", "values/foo.yaml"})
+		require.NoError(t, err)
+		assert.False(t, complete)
+		assert.Empty(t, deps)
+	})
+}
+
+func TestRepoURLPolicy(t *testing.T) {
+	denyList := []string{"https://denied.example.com/*", "https://github.com/denied/*"}
+
+	t.Run("Source repository", func(t *testing.T) {
+		service := newService(".")
+		_, err := service.GenerateManifest(context.Background(), &apiclient.ManifestRequest{
+			Repo:               &argoappv1.Repository{Repo: "https://denied.example.com/repo"},
+			ApplicationSource:  &argoappv1.ApplicationSource{Path: "."},
+			RepositoryDenyList: denyList,
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.ErrorContains(t, err, "repository 'https://denied.example.com/repo' is not permitted by the repository policy")
+	})
+
+	t.Run("Referenced source repository", func(t *testing.T) {
+		service := newService(".")
+		_, err := service.GenerateManifest(context.Background(), &apiclient.ManifestRequest{
+			Repo:              &argoappv1.Repository{Repo: "https://allowed.example.com/repo"},
+			ApplicationSource: &argoappv1.ApplicationSource{Path: "."},
+			RefSources: map[string]*argoappv1.RefTarget{
+				"$values": {Repo: argoappv1.Repository{Repo: "https://denied.example.com/values"}},
+			},
+			RepositoryDenyList: denyList,
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Helm dependency", func(t *testing.T) {
+		q := &apiclient.ManifestRequest{
+			Repo:              &argoappv1.Repository{},
+			ApplicationSource: &argoappv1.ApplicationSource{Path: "."},
+			Repos: []*argoappv1.Repository{{
+				Name: "custom-repo",
+				Repo: "https://denied.example.com/charts",
+			}},
+			ProjectSourceRepos: []string{"*"},
+			RepositoryDenyList: denyList,
+		}
+		_, err := GenerateManifests(context.Background(), "./testdata/helm-with-dependencies", "/", "", q, false, &git.NoopCredsStore{}, resource.MustParse("0"), nil)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.ErrorContains(t, err, "https://denied.example.com/charts")
+	})
+
+	t.Run("Kustomize remote base", func(t *testing.T) {
+		appPath := t.TempDir()
+		err := os.WriteFile(filepath.Join(appPath, "kustomization.yaml"), []byte("resources:\n- https://github.com/denied/base//deploy?ref=v1.0.0\n"), 0644)
+		require.NoError(t, err)
+		q := &apiclient.ManifestRequest{
+			Repo:               &argoappv1.Repository{},
+			ApplicationSource:  &argoappv1.ApplicationSource{Path: "."},
+			RepositoryDenyList: denyList,
+		}
+		_, err = GenerateManifests(context.Background(), appPath, appPath, "", q, false, &git.NoopCredsStore{}, resource.MustParse("0"), nil)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.ErrorContains(t, err, "https://github.com/denied/base")
+	})
+
+	t.Run("Revision metadata", func(t *testing.T) {
+		service := newService(".")
+		_, err := service.GetRevisionMetadata(context.Background(), &apiclient.RepoServerRevisionMetadataRequest{
+			Repo:               &argoappv1.Repository{Repo: "https://denied.example.com/repo"},
+			Revision:           "c0b400fc458875d925171398f9ba9eabd5529923",
+			RepositoryDenyList: denyList,
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("App details", func(t *testing.T) {
+		service := newService(".")
+		_, err := service.GetAppDetails(context.Background(), &apiclient.RepoServerAppDetailsQuery{
+			Repo:                &argoappv1.Repository{Repo: "https://other.example.com/repo"},
+			Source:              &argoappv1.ApplicationSource{Path: "."},
+			RepositoryAllowList: []string{"https://allowed.example.com/*"},
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -1,10 +1,12 @@
 package reposerver
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 
@@ -18,6 +20,7 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/argoproj/argo-cd/v2/common"
 	versionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/version"
@@ -44,6 +47,13 @@ type ArgoCDRepoServer struct {
 	initConstants repository.RepoServerInitConstants
 }
 
+const (
+	// repoServerServiceName is the name under which the health of the repository service is reported
+	repoServerServiceName = "repository.RepoServerService"
+	// repoServerHealthInterval is the interval in which the health of the repository service is updated
+	repoServerHealthInterval = 30 * time.Second
+)
+
 // The hostnames to generate self-signed issues with
 var tlsHostList []string = []string{"localhost", "reposerver"}
 
@@ -116,6 +126,17 @@ func NewServer(metricsServer *metrics.MetricsServer, cache *reposervercache.Cach
 	}, nil
 }
 
+// updateHealth reports the repository service as serving if it is able to report its load, which requires its
+// working directory to be accessible
+func (a *ArgoCDRepoServer) updateHealth(healthService *health.Server) {
+	if _, err := a.repoService.GetLoadReport(context.Background(), &emptypb.Empty{}); err != nil {
+		a.log.Warnf("Repository service is not serving: failed to get load report: %v", err)
+		healthService.SetServingStatus(repoServerServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		return
+	}
+	healthService.SetServingStatus(repoServerServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
 // CreateGRPC creates new configured grpc server
 func (a *ArgoCDRepoServer) CreateGRPC() *grpc.Server {
 	server := grpc.NewServer(a.opts...)
@@ -126,6 +147,15 @@ func (a *ArgoCDRepoServer) CreateGRPC() *grpc.Server {
 
 	healthService := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(server, healthService)
+	// Clients can check the health of the repository service by service name in addition to the overall server health.
+	a.updateHealth(healthService)
+	go func() {
+		ticker := time.NewTicker(repoServerHealthInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			a.updateHealth(healthService)
+		}
+	}()
 
 	// Register reflection service on gRPC server.
 	reflection.Register(server)
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,11 +15,13 @@ import (
 
 	kubecache "github.com/argoproj/gitops-engine/pkg/cache"
 	"github.com/argoproj/gitops-engine/pkg/diff"
+	"github.com/argoproj/gitops-engine/pkg/health"
 	"github.com/argoproj/gitops-engine/pkg/sync/common"
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	"github.com/argoproj/gitops-engine/pkg/utils/text"
 	"github.com/argoproj/pkg/sync"
 	jsonpatch "github.com/evanphx/json-patch"
+	gocache "github.com/patrickmn/go-cache"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -30,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -54,6 +58,7 @@ import (
 	ioutil "github.com/argoproj/argo-cd/v2/util/io"
 	"github.com/argoproj/argo-cd/v2/util/lua"
 	"github.com/argoproj/argo-cd/v2/util/manifeststream"
+	"github.com/argoproj/argo-cd/v2/util/provenance"
 	"github.com/argoproj/argo-cd/v2/util/rbac"
 	"github.com/argoproj/argo-cd/v2/util/security"
 	"github.com/argoproj/argo-cd/v2/util/session"
@@ -68,6 +73,16 @@ const (
 	maxPodLogsToRender                 = 10
 	backgroundPropagationPolicy string = "background"
 	foregroundPropagationPolicy string = "foreground"
+	defaultApplyFieldManager           = "argocd-server"
+	// operationPhaseQueued is the phase of operations which were not yet started by the application controller
+	operationPhaseQueued = "Queued"
+
+	orphanedResourceActionAdopt  = "adopt"
+	orphanedResourceActionDelete = "delete"
+
+	// deniedSyncEventInterval is the interval in which a denied sync of an application is recorded at most once per
+	// user and reason, so that retrying clients don't flood the events
+	deniedSyncEventInterval = time.Minute
 )
 
 var (
@@ -88,6 +103,8 @@ type Server struct {
 	db                db.ArgoDB
 	enf               *rbac.Enforcer
 	projectLock       sync.KeyLock
+	appsQuotaLock     sync.KeyLock
+	deniedSyncs       *gocache.Cache
 	auditLogger       *argo.AuditLogger
 	settingsMgr       *settings.SettingsManager
 	cache             *servercache.Cache
@@ -130,6 +147,8 @@ func NewServer(
 		kubectl:           kubectl,
 		enf:               enf,
 		projectLock:       projectLock,
+		appsQuotaLock:     sync.NewKeyLock(),
+		deniedSyncs:       gocache.New(deniedSyncEventInterval, 2*deniedSyncEventInterval),
 		auditLogger:       argo.NewAuditLogger(namespace, kubeclientset, "argocd-server"),
 		settingsMgr:       settingsMgr,
 		projInformer:      projInformer,
@@ -199,6 +218,19 @@ func (s *Server) List(ctx context.Context, q *application.ApplicationQuery) (*ap
 	if err != nil {
 		return nil, fmt.Errorf("error parsing the selector: %w", err)
 	}
+	fieldSelector, err := parseApplicationFieldSelector(q.GetFieldSelector())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "error parsing the field selector: %v", err)
+	}
+	if q.GetLimit() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not be negative")
+	}
+	var continueFrom *applicationListContinue
+	if q.GetContinue() != "" {
+		if continueFrom, err = decodeApplicationListContinue(q.GetContinue()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid continue token: %v", err)
+		}
+	}
 	var apps []*appv1.Application
 	if q.GetAppNamespace() == "" {
 		apps, err = s.appLister.List(selector)
@@ -228,14 +260,24 @@ func (s *Server) List(ctx context.Context, q *application.ApplicationQuery) (*ap
 		if a.Namespace != s.ns && !glob.MatchStringInList(s.enabledNamespaces, a.Namespace, false) {
 			continue
 		}
+		if !fieldSelector.Matches(applicationFields(a)) {
+			continue
+		}
+		if continueFrom != nil && !continueFrom.precedes(a) {
+			continue
+		}
 		if s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionGet, a.RBACName(s.ns)) {
 			newItems = append(newItems, *a)
 		}
 	}
 
-	// Sort found applications by name
+	// Sort found applications by name, and by namespace for applications with the same name, so that pages of a
+	// limited list are consistent
 	sort.Slice(newItems, func(i, j int) bool {
-		return newItems[i].Name < newItems[j].Name
+		if newItems[i].Name != newItems[j].Name {
+			return newItems[i].Name < newItems[j].Name
+		}
+		return newItems[i].Namespace < newItems[j].Namespace
 	})
 
 	appList := appv1.ApplicationList{
@@ -244,6 +286,12 @@ func (s *Server) List(ctx context.Context, q *application.ApplicationQuery) (*ap
 		},
 		Items: newItems,
 	}
+	if limit := q.GetLimit(); limit > 0 && int64(len(newItems)) > limit {
+		last := newItems[limit-1]
+		appList.Items = newItems[:limit]
+		appList.Continue = (&applicationListContinue{Name: last.Name, Namespace: last.Namespace}).encode()
+		appList.RemainingItemCount = pointer.Int64(int64(len(newItems)) - limit)
+	}
 	return &appList, nil
 }
 
@@ -260,6 +308,9 @@ func (s *Server) Create(ctx context.Context, q *application.ApplicationCreateReq
 
 	s.projectLock.RLock(a.Spec.GetProject())
 	defer s.projectLock.RUnlock(a.Spec.GetProject())
+	// adding applications to a project is serialized, so that concurrent requests can't exceed its applications quota
+	s.appsQuotaLock.Lock(a.Spec.GetProject())
+	defer s.appsQuotaLock.Unlock(a.Spec.GetProject())
 
 	validate := true
 	if q.Validate != nil {
@@ -276,6 +327,10 @@ func (s *Server) Create(ctx context.Context, q *application.ApplicationCreateReq
 		return nil, security.NamespaceNotPermittedError(appNs)
 	}
 
+	if q.GetServerSideApply() {
+		return s.applyApp(ctx, a, appNs, q.GetFieldManager(), q.GetUpsert(), validate)
+	}
+
 	created, err := s.appclientset.ArgoprojV1alpha1().Applications(appNs).Create(ctx, a, metav1.CreateOptions{})
 	if err == nil {
 		s.logAppEvent(created, ctx, argo.EventReasonResourceCreated, "created application")
@@ -312,6 +367,135 @@ func (s *Server) Create(ctx context.Context, q *application.ApplicationCreateReq
 	return updated, nil
 }
 
+// applyApp creates or updates an application using server-side apply. Only the fields present in the request are
+// owned by the given field manager, so fields set by other writers (e.g. an app-of-apps generator) are left untouched.
+// Conflicting ownership of a field is reported as an error unless force is set.
+func (s *Server) applyApp(ctx context.Context, a *appv1.Application, appNs string, fieldManager string, force bool, validate bool) (*appv1.Application, error) {
+	existing, err := s.appLister.Applications(appNs).Get(a.Name)
+	if err != nil && !apierr.IsNotFound(err) {
+		return nil, status.Errorf(codes.Internal, "unable to check existing application details (%s): %v", appNs, err)
+	}
+	if existing != nil {
+		if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionUpdate, a.RBACName(s.ns)); err != nil {
+			return nil, err
+		}
+	}
+	if fieldManager == "" {
+		fieldManager = defaultApplyFieldManager
+	}
+	patch, err := newApplicationApplyPatch(a, appNs)
+	if err != nil {
+		return nil, err
+	}
+	apply := func(dryRun []string) (*appv1.Application, error) {
+		applied, err := s.appclientset.ArgoprojV1alpha1().Applications(appNs).Patch(ctx, a.Name, types.ApplyPatchType, patch, metav1.PatchOptions{
+			FieldManager: fieldManager,
+			Force:        &force,
+			DryRun:       dryRun,
+		})
+		if err != nil {
+			if apierr.IsConflict(err) {
+				return nil, status.Errorf(codes.FailedPrecondition, "error applying application: %v; use upsert flag to force taking ownership of conflicting fields", err)
+			}
+			return nil, fmt.Errorf("error applying application: %w", err)
+		}
+		return applied, nil
+	}
+	// the applied spec is merged with the fields owned by other field managers, e.g. the sources of an app-of-apps
+	// generator, so the merged application is validated like the application of the request before it is applied
+	merged, err := apply([]string{metav1.DryRunAll})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateAndNormalizeApp(ctx, merged, validate); err != nil {
+		return nil, fmt.Errorf("error while validating and normalizing applied app: %w", err)
+	}
+	applied, err := apply(nil)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		s.logAppEvent(applied, ctx, argo.EventReasonResourceCreated, "created application")
+	} else {
+		s.logAppEvent(applied, ctx, argo.EventReasonResourceUpdated, "applied application spec")
+	}
+	s.waitSync(applied)
+	return applied, nil
+}
+
+// newApplicationApplyPatch returns the server-side apply configuration of an application, which only contains the
+// identifying metadata, labels, annotations, finalizers and the fields of the spec which are set. Status is deliberately
+// omitted so that the field manager does not take ownership of fields maintained by the application controller.
+func newApplicationApplyPatch(a *appv1.Application, appNs string) ([]byte, error) {
+	data, err := json.Marshal(a.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling application spec: %w", err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("error unmarshaling application spec: %w", err)
+	}
+	metadata := map[string]interface{}{
+		"name":      a.Name,
+		"namespace": appNs,
+	}
+	if len(a.Labels) > 0 {
+		metadata["labels"] = a.Labels
+	}
+	if len(a.Annotations) > 0 {
+		metadata["annotations"] = a.Annotations
+	}
+	if len(a.Finalizers) > 0 {
+		metadata["finalizers"] = a.Finalizers
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": appv1.SchemeGroupVersion.String(),
+		"kind":       appv1.ApplicationSchemaGroupVersionKind.Kind,
+		"metadata":   metadata,
+		"spec":       removeUnsetFields(spec),
+	})
+}
+
+// removeUnsetFields removes the fields with zero values from the given JSON object, including the objects which only
+// contain such fields. The typed spec marshals fields without omitempty even if they are not set, which would make the
+// field manager take ownership of them. Since a zero value can't be told apart from an unset field, zero values can't
+// be applied, but the fields are left to the field managers which set them.
+func removeUnsetFields(obj map[string]interface{}) map[string]interface{} {
+	for key, value := range obj {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if len(removeUnsetFields(v)) == 0 {
+				delete(obj, key)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				delete(obj, key)
+				continue
+			}
+			for _, item := range v {
+				if itemObj, ok := item.(map[string]interface{}); ok {
+					removeUnsetFields(itemObj)
+				}
+			}
+		case nil:
+			delete(obj, key)
+		case string:
+			if v == "" {
+				delete(obj, key)
+			}
+		case bool:
+			if !v {
+				delete(obj, key)
+			}
+		case float64:
+			if v == 0 {
+				delete(obj, key)
+			}
+		}
+	}
+	return obj
+}
+
 func (s *Server) queryRepoServer(ctx context.Context, a *appv1.Application, action func(
 	client apiclient.RepoServerServiceClient,
 	repo *appv1.Repository,
@@ -391,18 +575,97 @@ func (s *Server) GetManifests(ctx context.Context, q *application.ApplicationMan
 		return nil, security.NamespaceNotPermittedError(a.Namespace)
 	}
 
+	revision := source.TargetRevision
+	if q.GetRevision() != "" {
+		revision = q.GetRevision()
+	}
+	manifestInfo, err := s.generateManifests(ctx, a, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting app project: %w", err)
+	}
+
+	err = s.hideManifestsData(manifestInfo.Manifests, proj)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifestInfo, nil
+}
+
+// hideManifestsData hides the data of the Secrets and masks the values selected by the masking rules within the given
+// manifests
+func (s *Server) hideManifestsData(manifests []string, proj *appv1.AppProject) error {
+	maskingRules, err := s.settingsMgr.GetMaskingRules()
+	if err != nil {
+		return fmt.Errorf("error getting masking rules: %w", err)
+	}
+	for i, manifest := range manifests {
+		obj := &unstructured.Unstructured{}
+		err = json.Unmarshal([]byte(manifest), obj)
+		if err != nil {
+			return fmt.Errorf("error unmarshaling manifest into unstructured: %w", err)
+		}
+		isSecret := obj.GetKind() == kube.SecretKind && obj.GroupVersionKind().Group == ""
+		if !isSecret && len(argo.MaskingRulesFor(maskingRules, obj.GroupVersionKind().Group, obj.GetKind())) == 0 {
+			continue
+		}
+		if isSecret {
+			obj, _, err = diff.HideSecretData(obj, nil)
+			if err != nil {
+				return fmt.Errorf("error hiding secret data: %w", err)
+			}
+			if proj.Spec.RedactSecretData {
+				redactSecret(obj)
+			}
+		}
+		obj, _, err = argo.MaskResourceData(maskingRules, obj, nil)
+		if err != nil {
+			return fmt.Errorf("error masking manifest: %w", err)
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("error marshaling manifest: %w", err)
+		}
+		manifests[i] = string(data)
+	}
+	return nil
+}
+
+// generateManifests generates the manifests of the given single-source application at the given revision
+func (s *Server) generateManifests(ctx context.Context, a *appv1.Application, revision string) (*apiclient.ManifestResponse, error) {
+	// the manifests are generated from the spec as the application controller resolves it
+	a, err := s.resolveDestination(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	source := a.Spec.GetSource()
 	var manifestInfo *apiclient.ManifestResponse
 	err = s.queryRepoServer(ctx, a, func(
 		client apiclient.RepoServerServiceClient, repo *appv1.Repository, helmRepos []*appv1.Repository, helmCreds []*appv1.RepoCreds, helmOptions *appv1.HelmOptions, kustomizeOptions *appv1.KustomizeOptions, enableGenerateManifests map[string]bool) error {
-		revision := source.TargetRevision
-		if q.GetRevision() != "" {
-			revision = q.GetRevision()
-		}
 		appInstanceLabelKey, err := s.settingsMgr.GetAppInstanceLabelKey()
 		if err != nil {
 			return fmt.Errorf("error getting app instance label key from settings: %w", err)
 		}
 
+		if argo.UsesExternalManifests(a) {
+			// like the application controller, return the manifests which were submitted for the revision
+			res, err := client.ResolveRevision(ctx, &apiclient.ResolveRevisionRequest{
+				Repo:              repo,
+				App:               a,
+				AmbiguousRevision: revision,
+			})
+			if err != nil {
+				return fmt.Errorf("error resolving revision %s: %w", revision, err)
+			}
+			manifestInfo, err = argo.GetExternalManifestsResponse(ctx, s.db, s.settingsMgr, a, res.Revision, appInstanceLabelKey, a.InstanceName(s.ns))
+			return err
+		}
+
 		config, err := s.getApplicationClusterConfig(ctx, a)
 		if err != nil {
 			return fmt.Errorf("error getting application cluster config: %w", err)
@@ -423,54 +686,57 @@ func (s *Server) GetManifests(ctx context.Context, q *application.ApplicationMan
 			return fmt.Errorf("error getting app project: %w", err)
 		}
 
-		manifestInfo, err = client.GenerateManifest(ctx, &apiclient.ManifestRequest{
-			Repo:               repo,
-			Revision:           revision,
-			AppLabelKey:        appInstanceLabelKey,
-			AppName:            a.InstanceName(s.ns),
-			Namespace:          a.Spec.Destination.Namespace,
-			ApplicationSource:  &source,
-			Repos:              helmRepos,
-			KustomizeOptions:   kustomizeOptions,
-			KubeVersion:        serverVersion,
-			ApiVersions:        argo.APIResourcesToStrings(apiResources, true),
-			HelmRepoCreds:      helmCreds,
-			HelmOptions:        helmOptions,
-			TrackingMethod:     string(argoutil.GetTrackingMethod(s.settingsMgr)),
-			EnabledSourceTypes: enableGenerateManifests,
-			ProjectName:        proj.Name,
-			ProjectSourceRepos: proj.Spec.SourceRepos,
+		repoURLPolicy, err := s.settingsMgr.GetRepositoryURLPolicy()
+		if err != nil {
+			return fmt.Errorf("error getting repository policy: %w", err)
+		}
+
+		generateCtx, cancel, err := argo.WithManifestGenerateTimeout(ctx, a)
+		if err != nil {
+			return fmt.Errorf("error getting manifest generation timeout: %w", err)
+		}
+		defer cancel()
+
+		manifestInfo, err = client.GenerateManifest(generateCtx, &apiclient.ManifestRequest{
+			Repo:                repo,
+			Revision:            revision,
+			AppLabelKey:         appInstanceLabelKey,
+			AppName:             a.InstanceName(s.ns),
+			Namespace:           a.Spec.Destination.Namespace,
+			ApplicationSource:   &source,
+			Repos:               helmRepos,
+			KustomizeOptions:    kustomizeOptions,
+			KubeVersion:         serverVersion,
+			ApiVersions:         argo.APIResourcesToStrings(apiResources, true),
+			HelmRepoCreds:       helmCreds,
+			HelmOptions:         helmOptions,
+			TrackingMethod:      string(argoutil.GetTrackingMethod(s.settingsMgr)),
+			EnabledSourceTypes:  enableGenerateManifests,
+			ProjectName:         proj.Name,
+			ProjectSourceRepos:  proj.Spec.SourceRepos,
+			RepositoryAllowList: repoURLPolicy.Allow,
+			RepositoryDenyList:  repoURLPolicy.Deny,
 		})
 		if err != nil {
 			return fmt.Errorf("error generating manifests: %w", err)
 		}
 		return nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
+	return manifestInfo, nil
+}
 
-	for i, manifest := range manifestInfo.Manifests {
-		obj := &unstructured.Unstructured{}
-		err = json.Unmarshal([]byte(manifest), obj)
-		if err != nil {
-			return nil, fmt.Errorf("error unmarshaling manifest into unstructured: %w", err)
-		}
-		if obj.GetKind() == kube.SecretKind && obj.GroupVersionKind().Group == "" {
-			obj, _, err = diff.HideSecretData(obj, nil)
-			if err != nil {
-				return nil, fmt.Errorf("error hiding secret data: %w", err)
-			}
-			data, err := json.Marshal(obj)
-			if err != nil {
-				return nil, fmt.Errorf("error marshaling manifest: %w", err)
-			}
-			manifestInfo.Manifests[i] = string(data)
-		}
+// WarmManifestCache generates the manifests of the given application at the given revision, so that the
+// repo server caches them before the application controller refreshes the application.
+func (s *Server) WarmManifestCache(ctx context.Context, a *appv1.Application, revision string) error {
+	if a.Spec.HasMultipleSources() {
+		// manifests of multi-source applications are only generated by the application controller
+		return nil
 	}
-
-	return manifestInfo, nil
+	_, err := s.generateManifests(ctx, a, revision)
+	return err
 }
 
 func (s *Server) GetManifestsWithFiles(stream application.ApplicationService_GetManifestsWithFilesServer) error {
@@ -490,6 +756,12 @@ func (s *Server) GetManifestsWithFiles(stream application.ApplicationService_Get
 		return err
 	}
 
+	// the manifests are generated from the spec as the application controller resolves it
+	a, err = argo.ResolveAppSpecVariables(ctx, a, s.db)
+	if err != nil {
+		return fmt.Errorf("error resolving the variables of the application spec: %w", err)
+	}
+
 	var manifestInfo *apiclient.ManifestResponse
 	err = s.queryRepoServer(ctx, a, func(
 		client apiclient.RepoServerServiceClient, repo *appv1.Repository, helmRepos []*appv1.Repository, helmCreds []*appv1.RepoCreds, helmOptions *appv1.HelmOptions, kustomizeOptions *appv1.KustomizeOptions, enableGenerateManifests map[string]bool) error {
@@ -521,23 +793,30 @@ func (s *Server) GetManifestsWithFiles(stream application.ApplicationService_Get
 			return fmt.Errorf("error getting app project: %w", err)
 		}
 
+		repoURLPolicy, err := s.settingsMgr.GetRepositoryURLPolicy()
+		if err != nil {
+			return fmt.Errorf("error getting repository policy: %w", err)
+		}
+
 		req := &apiclient.ManifestRequest{
-			Repo:               repo,
-			Revision:           source.TargetRevision,
-			AppLabelKey:        appInstanceLabelKey,
-			AppName:            a.Name,
-			Namespace:          a.Spec.Destination.Namespace,
-			ApplicationSource:  &source,
-			Repos:              helmRepos,
-			KustomizeOptions:   kustomizeOptions,
-			KubeVersion:        serverVersion,
-			ApiVersions:        argo.APIResourcesToStrings(apiResources, true),
-			HelmRepoCreds:      helmCreds,
-			HelmOptions:        helmOptions,
-			TrackingMethod:     string(argoutil.GetTrackingMethod(s.settingsMgr)),
-			EnabledSourceTypes: enableGenerateManifests,
-			ProjectName:        proj.Name,
-			ProjectSourceRepos: proj.Spec.SourceRepos,
+			Repo:                repo,
+			Revision:            source.TargetRevision,
+			AppLabelKey:         appInstanceLabelKey,
+			AppName:             a.InstanceName(s.ns),
+			Namespace:           a.Spec.Destination.Namespace,
+			ApplicationSource:   &source,
+			Repos:               helmRepos,
+			KustomizeOptions:    kustomizeOptions,
+			KubeVersion:         serverVersion,
+			ApiVersions:         argo.APIResourcesToStrings(apiResources, true),
+			HelmRepoCreds:       helmCreds,
+			HelmOptions:         helmOptions,
+			TrackingMethod:      string(argoutil.GetTrackingMethod(s.settingsMgr)),
+			EnabledSourceTypes:  enableGenerateManifests,
+			ProjectName:         proj.Name,
+			ProjectSourceRepos:  proj.Spec.SourceRepos,
+			RepositoryAllowList: repoURLPolicy.Allow,
+			RepositoryDenyList:  repoURLPolicy.Deny,
 		}
 
 		repoStreamClient, err := client.GenerateManifestWithFiles(stream.Context())
@@ -563,23 +842,14 @@ func (s *Server) GetManifestsWithFiles(stream application.ApplicationService_Get
 		return err
 	}
 
-	for i, manifest := range manifestInfo.Manifests {
-		obj := &unstructured.Unstructured{}
-		err = json.Unmarshal([]byte(manifest), obj)
-		if err != nil {
-			return fmt.Errorf("error unmarshaling manifest into unstructured: %w", err)
-		}
-		if obj.GetKind() == kube.SecretKind && obj.GroupVersionKind().Group == "" {
-			obj, _, err = diff.HideSecretData(obj, nil)
-			if err != nil {
-				return fmt.Errorf("error hiding secret data: %w", err)
-			}
-			data, err := json.Marshal(obj)
-			if err != nil {
-				return fmt.Errorf("error marshaling manifest: %w", err)
-			}
-			manifestInfo.Manifests[i] = string(data)
-		}
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		return fmt.Errorf("error getting app project: %w", err)
+	}
+
+	err = s.hideManifestsData(manifestInfo.Manifests, proj)
+	if err != nil {
+		return err
 	}
 
 	stream.SendAndClose(manifestInfo)
@@ -635,16 +905,27 @@ func (s *Server) Get(ctx context.Context, q *application.ApplicationQuery) (*app
 			enabledSourceTypes map[string]bool,
 		) error {
 			source := app.Spec.GetSource()
-			_, err := client.GetAppDetails(ctx, &apiclient.RepoServerAppDetailsQuery{
-				Repo:               repo,
-				Source:             &source,
-				AppName:            appName,
-				KustomizeOptions:   kustomizeOptions,
-				Repos:              helmRepos,
-				NoCache:            true,
-				TrackingMethod:     string(argoutil.GetTrackingMethod(s.settingsMgr)),
-				EnabledSourceTypes: enabledSourceTypes,
-				HelmOptions:        helmOptions,
+			repoURLPolicy, err := s.settingsMgr.GetRepositoryURLPolicy()
+			if err != nil {
+				return err
+			}
+			detailsCtx, cancel, err := argo.WithManifestGenerateTimeout(ctx, app)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+			_, err = client.GetAppDetails(detailsCtx, &apiclient.RepoServerAppDetailsQuery{
+				Repo:                repo,
+				Source:              &source,
+				AppName:             app.InstanceName(s.ns),
+				KustomizeOptions:    kustomizeOptions,
+				Repos:               helmRepos,
+				NoCache:             true,
+				TrackingMethod:      string(argoutil.GetTrackingMethod(s.settingsMgr)),
+				EnabledSourceTypes:  enabledSourceTypes,
+				HelmOptions:         helmOptions,
+				RepositoryAllowList: repoURLPolicy.Allow,
+				RepositoryDenyList:  repoURLPolicy.Deny,
 			})
 			return err
 		}); err != nil {
@@ -681,67 +962,121 @@ func (s *Server) ListResourceEvents(ctx context.Context, q *application.Applicat
 	if err != nil {
 		return nil, err
 	}
+	kubeClientset, namespace, fieldSelector, err := s.getResourceEventsClient(ctx, a, q)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Querying for resource events with field selector: %s", fieldSelector)
+	opts := metav1.ListOptions{FieldSelector: fieldSelector}
+	list, err := kubeClientset.CoreV1().Events(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing resource events: %w", err)
+	}
+	return list, nil
+}
 
-	var (
-		kubeClientset kubernetes.Interface
-		fieldSelector string
-		namespace     string
-	)
+// WatchResourceEvents sends the events of an application, or of one of its resources if the query specifies a
+// resource, followed by the events which are created or updated afterwards
+func (s *Server) WatchResourceEvents(q *application.ApplicationResourceEventsQuery, ws application.ApplicationService_WatchResourceEventsServer) error {
+	ctx := ws.Context()
+	a, err := s.getApplicationEnforceRBACInformer(ctx, rbacpolicy.ActionGet, q.GetAppNamespace(), q.GetName())
+	if err != nil {
+		return err
+	}
+	kubeClientset, namespace, fieldSelector, err := s.getResourceEventsClient(ctx, a, q)
+	if err != nil {
+		return err
+	}
+	eventsIf := kubeClientset.CoreV1().Events(namespace)
+	list, err := eventsIf.List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return fmt.Errorf("error listing resource events: %w", err)
+	}
+	for i := range list.Items {
+		if err := ws.Send(&list.Items[i]); err != nil {
+			return err
+		}
+	}
+	w, err := eventsIf.Watch(ctx, metav1.ListOptions{FieldSelector: fieldSelector, ResourceVersion: list.ResourceVersion})
+	if err != nil {
+		return fmt.Errorf("error watching resource events: %w", err)
+	}
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case next, ok := <-w.ResultChan():
+			if !ok {
+				// the watch was closed by the API server, clients are expected to reconnect
+				return nil
+			}
+			switch next.Type {
+			case watch.Added, watch.Modified:
+				if event, ok := next.Object.(*v1.Event); ok {
+					if err := ws.Send(event); err != nil {
+						return err
+					}
+				}
+			case watch.Error:
+				return fmt.Errorf("error watching resource events: %w", apierr.FromObject(next.Object))
+			}
+		}
+	}
+}
+
+// getResourceEventsClient returns the client, the namespace and the field selector to query the events of the
+// application, or of one of its resources if the query specifies a resource
+func (s *Server) getResourceEventsClient(ctx context.Context, a *appv1.Application, q *application.ApplicationResourceEventsQuery) (kubernetes.Interface, string, string, error) {
 	// There are two places where we get events. If we are getting application events, we query
 	// our own cluster. If it is events on a resource on an external cluster, then we query the
 	// external cluster using its rest.Config
 	if q.GetResourceName() == "" && q.GetResourceUID() == "" {
-		kubeClientset = s.kubeclientset
-		namespace = a.Namespace
-		fieldSelector = fields.SelectorFromSet(map[string]string{
+		fieldSelector := fields.SelectorFromSet(map[string]string{
 			"involvedObject.name":      a.Name,
 			"involvedObject.uid":       string(a.UID),
 			"involvedObject.namespace": a.Namespace,
 		}).String()
-	} else {
-		tree, err := s.getAppResources(ctx, a)
-		if err != nil {
-			return nil, fmt.Errorf("error getting app resources: %w", err)
-		}
-		found := false
-		for _, n := range append(tree.Nodes, tree.OrphanedNodes...) {
-			if n.ResourceRef.UID == q.GetResourceUID() && n.ResourceRef.Name == q.GetResourceName() && n.ResourceRef.Namespace == q.GetResourceNamespace() {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return nil, status.Errorf(codes.InvalidArgument, "%s not found as part of application %s", q.GetResourceName(), q.GetName())
-		}
+		return s.kubeclientset, a.Namespace, fieldSelector, nil
+	}
 
-		namespace = q.GetResourceNamespace()
-		var config *rest.Config
-		config, err = s.getApplicationClusterConfig(ctx, a)
-		if err != nil {
-			return nil, fmt.Errorf("error getting application cluster config: %w", err)
-		}
-		kubeClientset, err = kubernetes.NewForConfig(config)
-		if err != nil {
-			return nil, fmt.Errorf("error creating kube client: %w", err)
+	tree, err := s.getAppResources(ctx, a)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("error getting app resources: %w", err)
+	}
+	found := false
+	for _, n := range append(tree.Nodes, tree.OrphanedNodes...) {
+		if n.ResourceRef.UID == q.GetResourceUID() && n.ResourceRef.Name == q.GetResourceName() && n.ResourceRef.Namespace == q.GetResourceNamespace() {
+			found = true
+			break
 		}
-		fieldSelector = fields.SelectorFromSet(map[string]string{
-			"involvedObject.name":      q.GetResourceName(),
-			"involvedObject.uid":       q.GetResourceUID(),
-			"involvedObject.namespace": namespace,
-		}).String()
 	}
-	log.Infof("Querying for resource events with field selector: %s", fieldSelector)
-	opts := metav1.ListOptions{FieldSelector: fieldSelector}
-	list, err := kubeClientset.CoreV1().Events(namespace).List(ctx, opts)
+	if !found {
+		return nil, "", "", status.Errorf(codes.InvalidArgument, "%s not found as part of application %s", q.GetResourceName(), q.GetName())
+	}
+
+	namespace := q.GetResourceNamespace()
+	config, err := s.getApplicationClusterConfig(ctx, a)
 	if err != nil {
-		return nil, fmt.Errorf("error listing resource events: %w", err)
+		return nil, "", "", fmt.Errorf("error getting application cluster config: %w", err)
 	}
-	return list, nil
+	kubeClientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("error creating kube client: %w", err)
+	}
+	fieldSelector := fields.SelectorFromSet(map[string]string{
+		"involvedObject.name":      q.GetResourceName(),
+		"involvedObject.uid":       q.GetResourceUID(),
+		"involvedObject.namespace": namespace,
+	}).String()
+	return kubeClientset, namespace, fieldSelector, nil
 }
 
 func (s *Server) validateAndUpdateApp(ctx context.Context, newApp *appv1.Application, merge bool, validate bool, action string) (*appv1.Application, error) {
 	s.projectLock.RLock(newApp.Spec.GetProject())
 	defer s.projectLock.RUnlock(newApp.Spec.GetProject())
+	s.appsQuotaLock.Lock(newApp.Spec.GetProject())
+	defer s.appsQuotaLock.Unlock(newApp.Spec.GetProject())
 
 	app, err := s.getApplicationEnforceRBACClient(ctx, action, newApp.Namespace, newApp.Name, "")
 	if err != nil {
@@ -888,6 +1223,55 @@ func (s *Server) Patch(ctx context.Context, q *application.ApplicationPatchReque
 		return nil, err
 	}
 
+	s.projectLock.RLock(app.Spec.GetProject())
+	defer s.projectLock.RUnlock(app.Spec.GetProject())
+	// the application is added to another project if the patch changes its project
+	if newApp, err := applyApplicationPatch(app, q.GetPatchType(), []byte(q.GetPatch())); err == nil && newApp.Spec.GetProject() != app.Spec.GetProject() {
+		s.appsQuotaLock.Lock(newApp.Spec.GetProject())
+		defer s.appsQuotaLock.Unlock(newApp.Spec.GetProject())
+	}
+
+	// The patch is re-applied on top of the latest version of the application whenever the update
+	// conflicts with another writer, so concurrent patches of different fields do not overwrite each other.
+	for i := 0; i < 10; i++ {
+		newApp, err := applyApplicationPatch(app, q.GetPatchType(), []byte(q.GetPatch()))
+		if err != nil {
+			return nil, err
+		}
+		if newApp.Spec.GetProject() != app.Spec.GetProject() {
+			if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionUpdate, newApp.RBACName(s.ns)); err != nil {
+				return nil, err
+			}
+		}
+		err = s.validateAndNormalizeApp(ctx, newApp, true)
+		if err != nil {
+			return nil, fmt.Errorf("error validating and normalizing app: %w", err)
+		}
+		app.Spec = newApp.Spec
+		app.Labels = newApp.Labels
+		app.Annotations = newApp.Annotations
+		app.Finalizers = newApp.Finalizers
+
+		res, err := s.appclientset.ArgoprojV1alpha1().Applications(app.Namespace).Update(ctx, app, metav1.UpdateOptions{})
+		if err == nil {
+			s.logAppEvent(app, ctx, argo.EventReasonResourceUpdated, "patched application spec")
+			s.waitSync(res)
+			return res, nil
+		}
+		if !apierr.IsConflict(err) {
+			return nil, fmt.Errorf("error updating application: %w", err)
+		}
+		app, err = s.appclientset.ArgoprojV1alpha1().Applications(app.Namespace).Get(ctx, app.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error getting application: %w", err)
+		}
+		s.inferResourcesStatusHealth(app)
+	}
+	return nil, status.Errorf(codes.Internal, "Failed to patch application. Too many conflicts")
+}
+
+// applyApplicationPatch applies a json, merge or strategic merge patch to a copy of the given application
+func applyApplicationPatch(app *appv1.Application, patchType string, patch []byte) (*appv1.Application, error) {
 	jsonApp, err := json.Marshal(app)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling application: %w", err)
@@ -895,23 +1279,32 @@ func (s *Server) Patch(ctx context.Context, q *application.ApplicationPatchReque
 
 	var patchApp []byte
 
-	switch q.GetPatchType() {
-	case "json", "":
-		patch, err := jsonpatch.DecodePatch([]byte(q.GetPatch()))
+	switch patchType {
+	case "json", "", string(types.JSONPatchType):
+		patch, err := jsonpatch.DecodePatch(patch)
 		if err != nil {
-			return nil, fmt.Errorf("error decoding json patch: %w", err)
+			return nil, status.Errorf(codes.InvalidArgument, "error decoding json patch: %v", err)
 		}
 		patchApp, err = patch.Apply(jsonApp)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			// a failed "test" operation means the application was changed since the client read it
+			return nil, status.Errorf(codes.FailedPrecondition, "error applying patch: %v", err)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("error applying patch: %w", err)
+			return nil, status.Errorf(codes.InvalidArgument, "error applying patch: %v", err)
 		}
-	case "merge":
-		patchApp, err = jsonpatch.MergePatch(jsonApp, []byte(q.GetPatch()))
+	case "merge", string(types.MergePatchType):
+		patchApp, err = jsonpatch.MergePatch(jsonApp, patch)
 		if err != nil {
-			return nil, fmt.Errorf("error calculating merge patch: %w", err)
+			return nil, status.Errorf(codes.InvalidArgument, "error calculating merge patch: %v", err)
+		}
+	case "strategic", string(types.StrategicMergePatchType):
+		patchApp, err = strategicpatch.StrategicMergePatch(jsonApp, patch, appv1.Application{})
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error calculating strategic merge patch: %v", err)
 		}
 	default:
-		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("Patch type '%s' is not supported", q.GetPatchType()))
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("Patch type '%s' is not supported", patchType))
 	}
 
 	newApp := &appv1.Application{}
@@ -919,7 +1312,10 @@ func (s *Server) Patch(ctx context.Context, q *application.ApplicationPatchReque
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling patched app: %w", err)
 	}
-	return s.validateAndUpdateApp(ctx, newApp, false, true, rbacpolicy.ActionUpdate)
+	if newApp.Name != app.Name || newApp.Namespace != app.Namespace {
+		return nil, status.Error(codes.InvalidArgument, "patch must not change the application name or namespace")
+	}
+	return newApp, nil
 }
 
 // Delete removes an application and all associated resources
@@ -1002,6 +1398,10 @@ func (s *Server) Watch(q *application.ApplicationQuery, ws application.Applicati
 	if err != nil {
 		return fmt.Errorf("error parsing labels with selectors: %w", err)
 	}
+	fieldSelector, err := parseApplicationFieldSelector(q.GetFieldSelector())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "error parsing the field selector: %v", err)
+	}
 	minVersion := 0
 	if q.GetResourceVersion() != "" {
 		if minVersion, err = strconv.Atoi(q.GetResourceVersion()); err != nil {
@@ -1015,6 +1415,12 @@ func (s *Server) Watch(q *application.ApplicationQuery, ws application.Applicati
 		if len(projects) > 0 && !projects[a.Spec.GetProject()] {
 			return
 		}
+		if q.GetRepo() != "" && a.Spec.GetSource().RepoURL != q.GetRepo() {
+			return
+		}
+		if !fieldSelector.Matches(applicationFields(&a)) {
+			return
+		}
 
 		if appVersion, err := strconv.Atoi(a.ResourceVersion); err == nil && appVersion < minVersion {
 			return
@@ -1068,6 +1474,74 @@ func (s *Server) Watch(q *application.ApplicationQuery, ws application.Applicati
 	}
 }
 
+// Wait sends the application, followed by its updates, until it reaches the requested states. The last update is
+// marked as done. An error is returned if the application becomes degraded while waiting for it to become healthy,
+// if it is deleted, or if the timeout is exceeded.
+func (s *Server) Wait(q *application.ApplicationWaitRequest, ws application.ApplicationService_WaitServer) error {
+	ctx := ws.Context()
+	if q.GetTimeoutSeconds() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(q.GetTimeoutSeconds())*time.Second)
+		defer cancel()
+	}
+	appName := q.GetName()
+	appNs := s.appNamespaceOrDefault(q.GetAppNamespace())
+
+	// subscribe before getting the application to ensure we don't miss events
+	events := make(chan *appv1.ApplicationWatchEvent, watchAPIBufferSize)
+	unsubscribe := s.appBroadcaster.Subscribe(events, func(event *appv1.ApplicationWatchEvent) bool {
+		return event.Application.Name == appName && event.Application.Namespace == appNs
+	})
+	defer unsubscribe()
+
+	a, err := s.getApplicationEnforceRBACInformer(ctx, rbacpolicy.ActionGet, q.GetAppNamespace(), appName)
+	if err != nil {
+		return err
+	}
+	opts := argo.WaitOptions{
+		Sync:      q.GetSync(),
+		Health:    q.GetHealth(),
+		Operation: q.GetOperation(),
+		Suspended: q.GetSuspended(),
+		Degraded:  q.GetDegraded(),
+	}
+	var prevHealth health.HealthStatusCode
+	// send returns true once the application reached the requested states
+	send := func(a *appv1.Application) (bool, error) {
+		s.inferResourcesStatusHealth(a)
+		done := opts.IsReached(string(a.Status.Health.Status), string(a.Status.Sync.Status), a.Operation) &&
+			(!opts.Operation || !argo.IsOperationInProgress(a))
+		if err := ws.Send(&application.ApplicationWaitResponse{Application: a, Done: pointer.Bool(done)}); err != nil {
+			return false, err
+		}
+		if !done && opts.Health && prevHealth != "" && prevHealth != health.HealthStatusUnknown && prevHealth != health.HealthStatusDegraded && a.Status.Health.Status == health.HealthStatusDegraded {
+			return false, status.Errorf(codes.Aborted, "application '%s' health state has transitioned from %s to %s", a.QualifiedName(), prevHealth, a.Status.Health.Status)
+		}
+		prevHealth = a.Status.Health.Status
+		return done, nil
+	}
+
+	if done, err := send(a.DeepCopy()); done || err != nil {
+		return err
+	}
+	for {
+		select {
+		case event := <-events:
+			if event.Type == watch.Deleted {
+				return status.Errorf(codes.NotFound, "application '%s' was deleted", event.Application.QualifiedName())
+			}
+			if done, err := send(&event.Application); done || err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			if ws.Context().Err() != nil {
+				return nil
+			}
+			return status.Errorf(codes.DeadlineExceeded, "timed out (%ds) waiting for application '%s' to reach the requested states", q.GetTimeoutSeconds(), a.QualifiedName())
+		}
+	}
+}
+
 func (s *Server) validateAndNormalizeApp(ctx context.Context, app *appv1.Application, validate bool) error {
 	proj, err := argo.GetAppProject(app, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
 	if err != nil {
@@ -1103,10 +1577,30 @@ func (s *Server) validateAndNormalizeApp(ctx context.Context, app *appv1.Applica
 		}
 	}
 
+	if currApp == nil || currApp.Spec.GetProject() != app.Spec.GetProject() {
+		// the application is added to the project, so it must not exceed the applications quota of the project
+		if err := s.validateApplicationsQuota(proj); err != nil {
+			return err
+		}
+	}
+
 	if err := argo.ValidateDestination(ctx, &app.Spec.Destination, s.db); err != nil {
 		return status.Errorf(codes.InvalidArgument, "application destination spec for %s is invalid: %s", app.Name, err.Error())
 	}
 
+	// only the changed settings of the sync policy are validated, so that applications which were created before the
+	// sync policy was validated can still be updated
+	var currSyncPolicy *appv1.SyncPolicy
+	if currApp != nil {
+		currSyncPolicy = &appv1.SyncPolicy{}
+		if currApp.Spec.SyncPolicy != nil {
+			currSyncPolicy = currApp.Spec.SyncPolicy
+		}
+	}
+	if err := app.Spec.SyncPolicy.ValidateChanges(currSyncPolicy); err != nil {
+		return status.Errorf(codes.InvalidArgument, "application sync policy for %s is invalid: %s", app.Name, err.Error())
+	}
+
 	var conditions []appv1.ApplicationCondition
 
 	if validate {
@@ -1133,6 +1627,30 @@ func (s *Server) validateAndNormalizeApp(ctx context.Context, app *appv1.Applica
 	return nil
 }
 
+// validateApplicationsQuota returns an error if the given project has as many applications as its quota permits. Only
+// the applications in the namespaces managed by Argo CD are counted. They are listed from the informer, which only
+// requires the permissions granted to namespace-scoped installations, but might not know very recently created
+// applications yet.
+func (s *Server) validateApplicationsQuota(proj *appv1.AppProject) error {
+	if proj.Spec.Quotas == nil || proj.Spec.Quotas.MaxApplications <= 0 {
+		return nil
+	}
+	apps, err := s.appLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing applications: %w", err)
+	}
+	count := 0
+	for _, a := range apps {
+		if a.Spec.GetProject() == proj.Name && s.isNamespaceEnabled(a.Namespace) {
+			count++
+		}
+	}
+	if err := proj.ValidateApplicationsQuota(count); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return nil
+}
+
 func (s *Server) getApplicationClusterConfig(ctx context.Context, a *appv1.Application) (*rest.Config, error) {
 	if err := argo.ValidateDestination(ctx, &a.Spec.Destination, s.db); err != nil {
 		return nil, fmt.Errorf("error validating destination: %w", err)
@@ -1141,8 +1659,24 @@ func (s *Server) getApplicationClusterConfig(ctx context.Context, a *appv1.Appli
 	if err != nil {
 		return nil, fmt.Errorf("error getting cluster: %w", err)
 	}
-	config := clst.RESTConfig()
-	return config, err
+	return clst.RESTConfig()
+}
+
+// impersonateServiceAccount makes the given cluster config impersonate the service account which the project of the
+// application configures for its destination, so that resources are changed with the same permissions as in a sync
+func (s *Server) impersonateServiceAccount(ctx context.Context, config *rest.Config, a *appv1.Application) error {
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		return fmt.Errorf("error getting app project: %w", err)
+	}
+	destinationNamespace, err := argo.GetDestinationNamespace(ctx, &a.Spec, s.db)
+	if err != nil {
+		return fmt.Errorf("error resolving destination namespace: %w", err)
+	}
+	if err := argo.ImpersonateServiceAccount(config, proj, a.Spec.Destination.Server, destinationNamespace); err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return nil
 }
 
 // getCachedAppState loads the cached state and trigger app refresh if cache is missing
@@ -1202,7 +1736,7 @@ func (s *Server) getAppLiveResource(ctx context.Context, action string, q *appli
 }
 
 func (s *Server) GetResource(ctx context.Context, q *application.ApplicationResourceRequest) (*application.ApplicationResourceResponse, error) {
-	res, config, _, err := s.getAppLiveResource(ctx, rbacpolicy.ActionGet, q)
+	res, config, a, err := s.getAppLiveResource(ctx, rbacpolicy.ActionGet, q)
 	if err != nil {
 		return nil, err
 	}
@@ -1215,10 +1749,17 @@ func (s *Server) GetResource(ctx context.Context, q *application.ApplicationReso
 	if err != nil {
 		return nil, fmt.Errorf("error getting resource: %w", err)
 	}
-	obj, err = replaceSecretValues(obj)
+	obj, err = s.replaceSecretValues(obj)
 	if err != nil {
 		return nil, fmt.Errorf("error replacing secret values: %w", err)
 	}
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting app project: %w", err)
+	}
+	if proj.Spec.RedactSecretData {
+		redactSecret(obj)
+	}
 	data, err := json.Marshal(obj.Object)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling object: %w", err)
@@ -1227,15 +1768,54 @@ func (s *Server) GetResource(ctx context.Context, q *application.ApplicationReso
 	return &application.ApplicationResourceResponse{Manifest: &manifest}, nil
 }
 
-func replaceSecretValues(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+// replaceSecretValues hides the data of the given live object if it is a Secret, and masks the values selected by the
+// masking rules
+func (s *Server) replaceSecretValues(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	if obj.GetKind() == kube.SecretKind && obj.GroupVersionKind().Group == "" {
-		_, obj, err := diff.HideSecretData(nil, obj)
+		var err error
+		_, obj, err = diff.HideSecretData(nil, obj)
 		if err != nil {
 			return nil, err
 		}
-		return obj, err
 	}
-	return obj, nil
+	maskingRules, err := s.settingsMgr.GetMaskingRules()
+	if err != nil {
+		return nil, fmt.Errorf("error getting masking rules: %w", err)
+	}
+	_, obj, err = argo.MaskResourceData(maskingRules, nil, obj)
+	return obj, err
+}
+
+// redactSecret removes the data of the given object entirely if it is a Secret
+func redactSecret(obj *unstructured.Unstructured) {
+	if obj != nil && obj.GetKind() == kube.SecretKind && obj.GroupVersionKind().Group == "" {
+		unstructured.RemoveNestedField(obj.Object, "data")
+		unstructured.RemoveNestedField(obj.Object, "stringData")
+	}
+}
+
+// redactSecretDiff removes the data of Secrets from the states of the given resource diff
+func redactSecretDiff(res *appv1.ResourceDiff) error {
+	if res.Kind != kube.SecretKind || res.Group != "" {
+		return nil
+	}
+	for _, state := range []*string{&res.TargetState, &res.LiveState, &res.NormalizedLiveState, &res.PredictedLiveState} {
+		if *state == "" || *state == "null" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal([]byte(*state), obj); err != nil {
+			return fmt.Errorf("error unmarshaling secret state: %w", err)
+		}
+		redactSecret(obj)
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("error marshaling secret state: %w", err)
+		}
+		*state = string(data)
+	}
+	res.Diff = ""
+	return nil
 }
 
 // PatchResource patches a resource
@@ -1253,6 +1833,9 @@ func (s *Server) PatchResource(ctx context.Context, q *application.ApplicationRe
 	if err != nil {
 		return nil, err
 	}
+	if err := s.impersonateServiceAccount(ctx, config, a); err != nil {
+		return nil, err
+	}
 
 	manifest, err := s.kubectl.PatchResource(ctx, config, res.GroupKindVersion(), res.Name, res.Namespace, types.PatchType(q.GetPatchType()), []byte(q.GetPatch()))
 	if err != nil {
@@ -1265,7 +1848,7 @@ func (s *Server) PatchResource(ctx context.Context, q *application.ApplicationRe
 	if manifest == nil {
 		return nil, fmt.Errorf("failed to patch resource: manifest was nil")
 	}
-	manifest, err = replaceSecretValues(manifest)
+	manifest, err = s.replaceSecretValues(manifest)
 	if err != nil {
 		return nil, fmt.Errorf("error replacing secret values: %w", err)
 	}
@@ -1295,6 +1878,9 @@ func (s *Server) DeleteResource(ctx context.Context, q *application.ApplicationR
 	if err != nil {
 		return nil, err
 	}
+	if err := s.impersonateServiceAccount(ctx, config, a); err != nil {
+		return nil, err
+	}
 	var deleteOption metav1.DeleteOptions
 	if q.GetOrphan() {
 		propagationPolicy := metav1.DeletePropagationOrphan
@@ -1340,6 +1926,86 @@ func (s *Server) WatchResourceTree(q *application.ResourcesQuery, ws application
 	})
 }
 
+const (
+	// resourceTreeEventSnapshot is the type of the event which contains the full resource tree
+	resourceTreeEventSnapshot = "SNAPSHOT"
+	// resourceTreeEventHosts is the type of the event which contains the hosts of the resource tree
+	resourceTreeEventHosts = "HOSTS"
+)
+
+// WatchResourceTreeDelta sends the resource tree of an application, followed by an event for every node which was
+// added, modified or deleted afterwards, so that clients don't have to receive the full tree on every change
+func (s *Server) WatchResourceTreeDelta(q *application.ResourcesQuery, ws application.ApplicationService_WatchResourceTreeDeltaServer) error {
+	a, err := s.getApplicationEnforceRBACInformer(ws.Context(), rbacpolicy.ActionGet, q.GetAppNamespace(), q.GetApplicationName())
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.getAppResources(ws.Context(), a)
+	if err != nil {
+		return err
+	}
+	err = ws.Send(&application.ResourceTreeEvent{Type: pointer.String(resourceTreeEventSnapshot), Tree: previous})
+	if err != nil {
+		return err
+	}
+	return s.cache.OnAppResourcesTreeChanged(ws.Context(), a.InstanceName(s.ns), func() error {
+		var tree appv1.ApplicationTree
+		err := s.cache.GetAppResourcesTree(a.InstanceName(s.ns), &tree)
+		if err != nil {
+			return fmt.Errorf("error getting app resource tree: %w", err)
+		}
+		for _, event := range resourceTreeEvents(previous, &tree) {
+			if err := ws.Send(event); err != nil {
+				return err
+			}
+		}
+		previous = &tree
+		return nil
+	})
+}
+
+// resourceTreeEvents returns the events which turn the previous resource tree into the current one
+func resourceTreeEvents(previous, current *appv1.ApplicationTree) []*application.ResourceTreeEvent {
+	events := resourceNodeEvents(previous.Nodes, current.Nodes, false)
+	events = append(events, resourceNodeEvents(previous.OrphanedNodes, current.OrphanedNodes, true)...)
+	if !reflect.DeepEqual(previous.Hosts, current.Hosts) {
+		events = append(events, &application.ResourceTreeEvent{
+			Type: pointer.String(resourceTreeEventHosts),
+			Tree: &appv1.ApplicationTree{Hosts: current.Hosts},
+		})
+	}
+	return events
+}
+
+func resourceNodeEvents(previous, current []appv1.ResourceNode, orphaned bool) []*application.ResourceTreeEvent {
+	previousByRef := make(map[appv1.ResourceRef]appv1.ResourceNode, len(previous))
+	for _, node := range previous {
+		previousByRef[node.ResourceRef] = node
+	}
+	var events []*application.ResourceTreeEvent
+	newEvent := func(eventType watch.EventType, node appv1.ResourceNode) *application.ResourceTreeEvent {
+		return &application.ResourceTreeEvent{Type: pointer.String(string(eventType)), Node: &node, Orphaned: pointer.Bool(orphaned)}
+	}
+	currentRefs := make(map[appv1.ResourceRef]bool, len(current))
+	for _, node := range current {
+		currentRefs[node.ResourceRef] = true
+		previousNode, ok := previousByRef[node.ResourceRef]
+		if !ok {
+			events = append(events, newEvent(watch.Added, node))
+		} else if !reflect.DeepEqual(previousNode, node) {
+			events = append(events, newEvent(watch.Modified, node))
+		}
+	}
+	for _, node := range previous {
+		if !currentRefs[node.ResourceRef] {
+			// deleted nodes are only identified by their reference
+			events = append(events, newEvent(watch.Deleted, appv1.ResourceNode{ResourceRef: node.ResourceRef}))
+		}
+	}
+	return events
+}
+
 func (s *Server) RevisionMetadata(ctx context.Context, q *application.RevisionMetadataQuery) (*appv1.RevisionMetadata, error) {
 	a, err := s.getApplicationEnforceRBACInformer(ctx, rbacpolicy.ActionGet, q.GetAppNamespace(), q.GetName())
 	if err != nil {
@@ -1357,15 +2023,21 @@ func (s *Server) RevisionMetadata(ctx context.Context, q *application.RevisionMe
 	if err != nil {
 		return nil, fmt.Errorf("error getting app project: %w", err)
 	}
+	repoURLPolicy, err := s.settingsMgr.GetRepositoryURLPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("error getting repository policy: %w", err)
+	}
 	conn, repoClient, err := s.repoClientset.NewRepoServerClient()
 	if err != nil {
 		return nil, fmt.Errorf("error creating repo server client: %w", err)
 	}
 	defer ioutil.Close(conn)
 	return repoClient.GetRevisionMetadata(ctx, &apiclient.RepoServerRevisionMetadataRequest{
-		Repo:           repo,
-		Revision:       q.GetRevision(),
-		CheckSignature: len(proj.Spec.SignatureKeys) > 0,
+		Repo:                repo,
+		Revision:            q.GetRevision(),
+		CheckSignature:      len(proj.Spec.SignatureKeys) > 0,
+		RepositoryAllowList: repoURLPolicy.Allow,
+		RepositoryDenyList:  repoURLPolicy.Deny,
 	})
 }
 
@@ -1399,6 +2071,24 @@ func (s *Server) RevisionChartDetails(ctx context.Context, q *application.Revisi
 	})
 }
 
+// ResolveRevision resolves a revision expression of an application source, such as a semver constraint of a Helm chart
+// or a tag glob of a Git repository, into the concrete revision a sync would deploy
+func (s *Server) ResolveRevision(ctx context.Context, q *application.ApplicationResolveRevisionRequest) (*apiclient.ResolveRevisionResponse, error) {
+	a, err := s.getApplicationEnforceRBACInformer(ctx, rbacpolicy.ActionGet, q.GetAppNamespace(), q.GetName())
+	if err != nil {
+		return nil, err
+	}
+	sources := a.Spec.GetSources()
+	if q.GetSourceIndex() < 0 || q.GetSourceIndex() >= int64(len(sources)) {
+		return nil, status.Errorf(codes.InvalidArgument, "source index %d is out of range", q.GetSourceIndex())
+	}
+	revision, ambiguousRevision, err := s.resolveSourceRevision(ctx, a, sources[q.GetSourceIndex()], q.GetSourceIndex(), q.GetRevision())
+	if err != nil {
+		return nil, err
+	}
+	return &apiclient.ResolveRevisionResponse{Revision: revision, AmbiguousRevision: ambiguousRevision}, nil
+}
+
 func isMatchingResource(q *application.ResourcesQuery, key kube.ResourceKey) bool {
 	return (q.GetName() == "" || q.GetName() == key.Name) &&
 		(q.GetNamespace() == "" || q.GetNamespace() == key.Namespace) &&
@@ -1419,10 +2109,19 @@ func (s *Server) ManagedResources(ctx context.Context, q *application.ResourcesQ
 	if err != nil {
 		return nil, fmt.Errorf("error getting cached app managed resources: %w", err)
 	}
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting app project: %w", err)
+	}
 	res := &application.ManagedResourcesResponse{}
 	for i := range items {
 		item := items[i]
 		if isMatchingResource(q, kube.ResourceKey{Name: item.Name, Namespace: item.Namespace, Kind: item.Kind, Group: item.Group}) {
+			if proj.Spec.RedactSecretData {
+				if err := redactSecretDiff(item); err != nil {
+					return nil, err
+				}
+			}
 			res.Items = append(res.Items, item)
 		}
 	}
@@ -1484,6 +2183,14 @@ func (s *Server) PodLogs(q *application.ApplicationPodLogsQuery, ws application.
 		}
 	}
 
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ws.Context())
+	if err != nil {
+		return fmt.Errorf("error getting app project: %w", err)
+	}
+	if proj.Spec.DisableLogs {
+		return status.Errorf(codes.PermissionDenied, "viewing logs is disabled for applications in project %s", proj.Name)
+	}
+
 	tree, err := s.getAppResources(ws.Context(), a)
 	if err != nil {
 		return fmt.Errorf("error getting app resource tree: %w", err)
@@ -1512,32 +2219,56 @@ func (s *Server) PodLogs(q *application.ApplicationPodLogsQuery, ws application.
 	var streams []chan logEntry
 
 	for _, pod := range pods {
-		stream, err := kubeClientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
-			Container:    q.GetContainer(),
-			Follow:       q.GetFollow(),
-			Timestamps:   true,
-			SinceSeconds: sinceSeconds,
-			SinceTime:    q.GetSinceTime(),
-			TailLines:    tailLines,
-			Previous:     q.GetPrevious(),
-		}).Stream(ws.Context())
 		podName := pod.Name
-		logStream := make(chan logEntry)
-		if err == nil {
-			defer ioutil.Close(stream)
+		containers := []string{q.GetContainer()}
+		var containersErr error
+		if isContainerPattern(q.GetContainer()) {
+			// stream the logs of every container of the pod whose name matches the pattern
+			var podObj *v1.Pod
+			podObj, containersErr = kubeClientset.CoreV1().Pods(pod.Namespace).Get(ws.Context(), pod.Name, metav1.GetOptions{})
+			containers = nil
+			if containersErr == nil {
+				containers = matchContainers(podObj, q.GetContainer())
+			}
+		}
+		if containersErr != nil {
+			logStream := make(chan logEntry)
+			streams = append(streams, logStream)
+			go func() {
+				logStream <- logEntry{line: containersErr.Error(), podName: podName}
+				close(logStream)
+			}()
+			continue
 		}
 
-		streams = append(streams, logStream)
-		go func() {
-			// if k8s failed to start steaming logs (typically because Pod is not ready yet)
-			// then the error should be shown in the UI so that user know the reason
-			if err != nil {
-				logStream <- logEntry{line: err.Error()}
-			} else {
-				parseLogsStream(podName, stream, logStream)
+		for _, container := range containers {
+			stream, err := kubeClientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+				Container:    container,
+				Follow:       q.GetFollow(),
+				Timestamps:   true,
+				SinceSeconds: sinceSeconds,
+				SinceTime:    q.GetSinceTime(),
+				TailLines:    tailLines,
+				Previous:     q.GetPrevious(),
+			}).Stream(ws.Context())
+			containerName := container
+			logStream := make(chan logEntry)
+			if err == nil {
+				defer ioutil.Close(stream)
 			}
-			close(logStream)
-		}()
+
+			streams = append(streams, logStream)
+			go func() {
+				// if k8s failed to start steaming logs (typically because Pod is not ready yet)
+				// then the error should be shown in the UI so that user know the reason
+				if err != nil {
+					logStream <- logEntry{line: err.Error()}
+				} else {
+					parseLogsStream(podName, containerName, stream, logStream)
+				}
+				close(logStream)
+			}()
+		}
 	}
 
 	logStream := mergeLogStreams(streams, time.Millisecond*100)
@@ -1558,21 +2289,23 @@ func (s *Server) PodLogs(q *application.ApplicationPodLogsQuery, ws application.
 				ts := metav1.NewTime(entry.timeStamp)
 				if untilTime != nil && entry.timeStamp.After(untilTime.Time) {
 					done <- ws.Send(&application.LogEntry{
-						Last:         pointer.Bool(true),
-						PodName:      &entry.podName,
-						Content:      &entry.line,
-						TimeStampStr: pointer.String(entry.timeStamp.Format(time.RFC3339Nano)),
-						TimeStamp:    &ts,
+						Last:          pointer.Bool(true),
+						PodName:       &entry.podName,
+						ContainerName: containerNamePtr(entry.containerName),
+						Content:       &entry.line,
+						TimeStampStr:  pointer.String(entry.timeStamp.Format(time.RFC3339Nano)),
+						TimeStamp:     &ts,
 					})
 					return
 				} else {
 					sentCount++
 					if err := ws.Send(&application.LogEntry{
-						PodName:      &entry.podName,
-						Content:      &entry.line,
-						TimeStampStr: pointer.String(entry.timeStamp.Format(time.RFC3339Nano)),
-						TimeStamp:    &ts,
-						Last:         pointer.Bool(false),
+						PodName:       &entry.podName,
+						ContainerName: containerNamePtr(entry.containerName),
+						Content:       &entry.line,
+						TimeStampStr:  pointer.String(entry.timeStamp.Format(time.RFC3339Nano)),
+						TimeStamp:     &ts,
+						Last:          pointer.Bool(false),
 					}); err != nil {
 						done <- err
 						break
@@ -1600,6 +2333,14 @@ func (s *Server) PodLogs(q *application.ApplicationPodLogsQuery, ws application.
 	}
 }
 
+// containerNamePtr returns a pointer to the container name of a log entry, or nil if the container was not selected explicitly
+func containerNamePtr(containerName string) *string {
+	if containerName == "" {
+		return nil
+	}
+	return &containerName
+}
+
 // from all of the treeNodes, get the pod who meets the criteria or whose parents meets the criteria
 func getSelectedPods(treeNodes []appv1.ResourceNode, q *application.ApplicationPodLogsQuery) []appv1.ResourceNode {
 	var pods []appv1.ResourceNode
@@ -1670,12 +2411,15 @@ func (s *Server) Sync(ctx context.Context, syncReq *application.ApplicationSyncR
 	}
 
 	s.inferResourcesStatusHealth(a)
+	syncPolicy := proj.GetSyncPolicy(a)
 
 	if !proj.Spec.SyncWindows.Matches(a).CanSync(true) {
+		s.logDeniedSync(a, ctx, "blocked by sync window")
 		return a, status.Errorf(codes.PermissionDenied, "cannot sync: blocked by sync window")
 	}
 
 	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionSync, a.RBACName(s.ns)); err != nil {
+		s.logDeniedSync(a, ctx, "permission denied")
 		return nil, err
 	}
 
@@ -1685,14 +2429,14 @@ func (s *Server) Sync(ctx context.Context, syncReq *application.ApplicationSyncR
 		if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionOverride, a.RBACName(s.ns)); err != nil {
 			return nil, err
 		}
-		if a.Spec.SyncPolicy != nil && a.Spec.SyncPolicy.Automated != nil && !syncReq.GetDryRun() {
+		if syncPolicy != nil && syncPolicy.Automated != nil && !syncReq.GetDryRun() {
 			return nil, status.Error(codes.FailedPrecondition, "cannot use local sync when Automatic Sync Policy is enabled unless for dry run")
 		}
 	}
 	if a.DeletionTimestamp != nil {
 		return nil, status.Errorf(codes.FailedPrecondition, "application is deleting")
 	}
-	if a.Spec.SyncPolicy != nil && a.Spec.SyncPolicy.Automated != nil {
+	if syncPolicy != nil && syncPolicy.Automated != nil {
 		if syncReq.GetRevision() != "" && syncReq.GetRevision() != text.FirstNonEmpty(source.TargetRevision, "HEAD") {
 			return nil, status.Errorf(codes.FailedPrecondition, "Cannot sync to %s: auto-sync currently set to %s", syncReq.GetRevision(), source.TargetRevision)
 		}
@@ -1704,9 +2448,9 @@ func (s *Server) Sync(ctx context.Context, syncReq *application.ApplicationSyncR
 
 	var retry *appv1.RetryStrategy
 	var syncOptions appv1.SyncOptions
-	if a.Spec.SyncPolicy != nil {
-		syncOptions = a.Spec.SyncPolicy.SyncOptions
-		retry = a.Spec.SyncPolicy.Retry
+	if syncPolicy != nil {
+		syncOptions = syncPolicy.SyncOptions
+		retry = syncPolicy.Retry
 	}
 	if syncReq.RetryStrategy != nil {
 		retry = syncReq.RetryStrategy
@@ -1714,6 +2458,19 @@ func (s *Server) Sync(ctx context.Context, syncReq *application.ApplicationSyncR
 	if syncReq.SyncOptions != nil {
 		syncOptions = syncReq.SyncOptions.Items
 	}
+	if err := syncOptions.Validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid sync options: %s", err.Error())
+	}
+	if retry != nil {
+		if err := retry.Validate(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid retry strategy: %s", err.Error())
+		}
+	}
+	if syncReq.GetLabelSelector() != "" {
+		if _, err := labels.Parse(syncReq.GetLabelSelector()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid label selector: %s", err.Error())
+		}
+	}
 
 	// We cannot use local manifests if we're only allowed to sync to signed commits
 	if syncReq.Manifests != nil && len(proj.Spec.SignatureKeys) > 0 {
@@ -1730,13 +2487,14 @@ func (s *Server) Sync(ctx context.Context, syncReq *application.ApplicationSyncR
 	}
 	op := appv1.Operation{
 		Sync: &appv1.SyncOperation{
-			Revision:     revision,
-			Prune:        syncReq.GetPrune(),
-			DryRun:       syncReq.GetDryRun(),
-			SyncOptions:  syncOptions,
-			SyncStrategy: syncReq.Strategy,
-			Resources:    resources,
-			Manifests:    syncReq.Manifests,
+			Revision:      revision,
+			Prune:         syncReq.GetPrune(),
+			DryRun:        syncReq.GetDryRun(),
+			SyncOptions:   syncOptions,
+			SyncStrategy:  syncReq.Strategy,
+			Resources:     resources,
+			LabelSelector: syncReq.GetLabelSelector(),
+			Manifests:     syncReq.Manifests,
 		},
 		InitiatedBy: appv1.OperationInitiator{Username: session.Username(ctx)},
 		Info:        syncReq.Infos,
@@ -1753,7 +2511,7 @@ func (s *Server) Sync(ctx context.Context, syncReq *application.ApplicationSyncR
 		return nil, fmt.Errorf("error setting app operation: %w", err)
 	}
 	partial := ""
-	if len(syncReq.Resources) > 0 {
+	if op.Sync.IsSelective() {
 		partial = "partial "
 	}
 	reason := fmt.Sprintf("initiated %ssync to %s", partial, displayRevision)
@@ -1775,43 +2533,67 @@ func (s *Server) Rollback(ctx context.Context, rollbackReq *application.Applicat
 	if a.DeletionTimestamp != nil {
 		return nil, status.Errorf(codes.FailedPrecondition, "application is deleting")
 	}
-	if a.Spec.SyncPolicy != nil && a.Spec.SyncPolicy.Automated != nil {
-		return nil, status.Errorf(codes.FailedPrecondition, "rollback cannot be initiated when auto-sync is enabled")
-	}
-
-	var deploymentInfo *appv1.RevisionHistory
-	for _, info := range a.Status.History {
-		if info.ID == rollbackReq.GetId() {
-			deploymentInfo = &info
-			break
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "application references project %s which does not exist", a.Spec.Project)
 		}
+		return nil, fmt.Errorf("error getting app project: %w", err)
 	}
-	if deploymentInfo == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "application %s does not have deployment with id %v", a.QualifiedName(), rollbackReq.GetId())
-	}
-	if deploymentInfo.Source.IsZero() {
-		// Since source type was introduced to history starting with v0.12, and is now required for
-		// rollback, we cannot support rollback to revisions deployed using Argo CD v0.11 or below
-		return nil, status.Errorf(codes.FailedPrecondition, "cannot rollback to revision deployed with Argo CD v0.11 or lower. sync to revision instead.")
+	syncPolicy := proj.GetSyncPolicy(a)
+	if syncPolicy != nil && syncPolicy.Automated != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "rollback cannot be initiated when auto-sync is enabled")
 	}
 
 	var syncOptions appv1.SyncOptions
-	if a.Spec.SyncPolicy != nil {
-		syncOptions = a.Spec.SyncPolicy.SyncOptions
+	if syncPolicy != nil {
+		syncOptions = syncPolicy.SyncOptions
 	}
 
 	// Rollback is just a convenience around Sync
 	op := appv1.Operation{
 		Sync: &appv1.SyncOperation{
-			Revision:     deploymentInfo.Revision,
 			DryRun:       rollbackReq.GetDryRun(),
 			Prune:        rollbackReq.GetPrune(),
 			SyncOptions:  syncOptions,
 			SyncStrategy: &appv1.SyncStrategy{Apply: &appv1.SyncStrategyApply{}},
-			Source:       &deploymentInfo.Source,
 		},
 		InitiatedBy: appv1.OperationInitiator{Username: session.Username(ctx)},
 	}
+	var target string
+	if rollbackReq.GetRevision() != "" {
+		if a.Spec.HasMultipleSources() {
+			return nil, status.Errorf(codes.FailedPrecondition, "rollback to a revision is not supported for applications with multiple sources")
+		}
+		source := a.Spec.GetSource()
+		revision, _, err := s.resolveSourceRevision(ctx, a, source, 0, rollbackReq.GetRevision())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to resolve revision %s: %v", rollbackReq.GetRevision(), err)
+		}
+		op.Sync.Revision = revision
+		op.Sync.Source = &source
+		op.Sync.ManualRevision = true
+		target = fmt.Sprintf("revision %s", rollbackReq.GetRevision())
+	} else {
+		var deploymentInfo *appv1.RevisionHistory
+		for _, info := range a.Status.History {
+			if info.ID == rollbackReq.GetId() {
+				deploymentInfo = &info
+				break
+			}
+		}
+		if deploymentInfo == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "application %s does not have deployment with id %v", a.QualifiedName(), rollbackReq.GetId())
+		}
+		if deploymentInfo.Source.IsZero() {
+			// Since source type was introduced to history starting with v0.12, and is now required for
+			// rollback, we cannot support rollback to revisions deployed using Argo CD v0.11 or below
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot rollback to revision deployed with Argo CD v0.11 or lower. sync to revision instead.")
+		}
+		op.Sync.Revision = deploymentInfo.Revision
+		op.Sync.Source = &deploymentInfo.Source
+		target = fmt.Sprintf("%d", rollbackReq.GetId())
+	}
 	appName := rollbackReq.GetName()
 	appNs := s.appNamespaceOrDefault(rollbackReq.GetAppNamespace())
 	appIf := s.appclientset.ArgoprojV1alpha1().Applications(appNs)
@@ -1819,10 +2601,89 @@ func (s *Server) Rollback(ctx context.Context, rollbackReq *application.Applicat
 	if err != nil {
 		return nil, fmt.Errorf("error setting app operation: %w", err)
 	}
-	s.logAppEvent(a, ctx, argo.EventReasonOperationStarted, fmt.Sprintf("initiated rollback to %d", rollbackReq.GetId()))
+	s.logAppEvent(a, ctx, argo.EventReasonOperationStarted, fmt.Sprintf("initiated rollback to %s", target))
 	return a, nil
 }
 
+// resolveDestination returns a copy of the given application whose destination server is inferred from the name or the
+// cluster selector of the destination, if needed, and whose spec variables are resolved, like the application controller
+// sees the application
+func (s *Server) resolveDestination(ctx context.Context, a *appv1.Application) (*appv1.Application, error) {
+	resolved, err := argo.ResolveAppSpecVariables(ctx, a, s.db)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving the variables of the application spec: %w", err)
+	}
+	if resolved == a {
+		resolved = a.DeepCopy()
+	}
+	if err := argo.ValidateDestination(ctx, &resolved.Spec.Destination, s.db); err != nil {
+		return nil, fmt.Errorf("error validating destination: %w", err)
+	}
+	return resolved, nil
+}
+
+// SubmitManifests stores manifests which were rendered outside of Argo CD for the given revision of an application. The
+// manifests must be signed by one of the keys trusted for externally rendered manifests. The application controller
+// uses them instead of generating the manifests itself if the application opted in using the external manifests annotation.
+func (s *Server) SubmitManifests(ctx context.Context, q *application.ApplicationSubmitManifestsRequest) (*application.ApplicationResponse, error) {
+	a, err := s.getApplicationEnforceRBACClient(ctx, rbacpolicy.ActionUpdate, q.GetAppNamespace(), q.GetName(), "")
+	if err != nil {
+		return nil, err
+	}
+	if q.GetProject() != "" && q.GetProject() != a.Spec.GetProject() {
+		return nil, status.Errorf(codes.InvalidArgument, "application %s does not belong to project %s", a.QualifiedName(), q.GetProject())
+	}
+	if enabled, _ := strconv.ParseBool(a.Annotations[argocommon.AnnotationKeyExternalManifests]); !enabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "application %s does not accept externally rendered manifests", a.QualifiedName())
+	}
+	if a.Spec.HasMultipleSources() {
+		return nil, status.Errorf(codes.FailedPrecondition, "externally rendered manifests are not supported for applications with multiple sources")
+	}
+	if q.GetRevision() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "revision is required")
+	}
+	for _, manifest := range q.GetManifests() {
+		if _, err := appv1.UnmarshalToUnstructured(manifest); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid manifest: %v", err)
+		}
+	}
+
+	keys, err := s.settingsMgr.GetManifestProvenanceKeys()
+	if err != nil {
+		return nil, fmt.Errorf("error getting manifest provenance keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "no keys are trusted for externally rendered manifests")
+	}
+	resolvedApp, err := s.resolveDestination(ctx, a)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	err = provenance.Verify(keys, provenance.Payload(argo.ExternalManifestsSubject(resolvedApp), q.GetRevision(), q.GetManifests()), q.GetSignature())
+	if errors.Is(err, provenance.ErrSignatureMismatch) {
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	err = s.db.SetRenderedManifests(ctx, a, &db.RenderedManifests{
+		Revision:  q.GetRevision(),
+		Manifests: q.GetManifests(),
+		Signature: q.GetSignature(),
+	})
+	if errors.Is(err, db.ErrRenderedManifestsTooLarge) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error storing rendered manifests: %w", err)
+	}
+	appIf := s.appclientset.ArgoprojV1alpha1().Applications(a.Namespace)
+	if _, err = argo.RefreshApp(appIf, a.Name, appv1.RefreshTypeNormal); err != nil {
+		return nil, fmt.Errorf("error refreshing application: %w", err)
+	}
+	s.logAppEvent(a, ctx, argo.EventReasonResourceUpdated, fmt.Sprintf("submitted rendered manifests for revision %s", q.GetRevision()))
+	return &application.ApplicationResponse{}, nil
+}
+
 func (s *Server) ListLinks(ctx context.Context, req *application.ListAppLinksRequest) (*application.LinksResponse, error) {
 	a, err := s.getApplicationEnforceRBACClient(ctx, rbacpolicy.ActionGet, req.GetNamespace(), req.GetName(), "")
 	if err != nil {
@@ -1912,7 +2773,7 @@ func (s *Server) ListResourceLinks(ctx context.Context, req *application.Applica
 		return nil, fmt.Errorf("failed to read application deep links from configmap: %w", err)
 	}
 
-	obj, err = replaceSecretValues(obj)
+	obj, err = s.replaceSecretValues(obj)
 	if err != nil {
 		return nil, fmt.Errorf("error replacing secret values: %w", err)
 	}
@@ -1942,11 +2803,16 @@ func (s *Server) resolveRevision(ctx context.Context, app *appv1.Application, sy
 	if syncReq.Manifests != nil {
 		return "", "", nil
 	}
-	ambiguousRevision := syncReq.GetRevision()
+	return s.resolveSourceRevision(ctx, app, app.Spec.GetSource(), 0, syncReq.GetRevision())
+}
+
+// resolveSourceRevision resolves the given revision of the app source with the given index into a concrete revision.
+// The target revision of the source is used if no revision is given.
+func (s *Server) resolveSourceRevision(ctx context.Context, app *appv1.Application, source appv1.ApplicationSource, sourceIndex int64, ambiguousRevision string) (string, string, error) {
 	if ambiguousRevision == "" {
-		ambiguousRevision = app.Spec.GetSource().TargetRevision
+		ambiguousRevision = source.TargetRevision
 	}
-	repo, err := s.db.GetRepository(ctx, app.Spec.GetSource().RepoURL)
+	repo, err := s.db.GetRepository(ctx, source.RepoURL)
 	if err != nil {
 		return "", "", fmt.Errorf("error getting repository by URL: %w", err)
 	}
@@ -1956,7 +2822,6 @@ func (s *Server) resolveRevision(ctx context.Context, app *appv1.Application, sy
 	}
 	defer ioutil.Close(conn)
 
-	source := app.Spec.GetSource()
 	if !source.IsHelm() {
 		if git.IsCommitSHA(ambiguousRevision) {
 			// If it's already a commit SHA, then no need to look it up
@@ -1968,6 +2833,7 @@ func (s *Server) resolveRevision(ctx context.Context, app *appv1.Application, sy
 		Repo:              repo,
 		App:               app,
 		AmbiguousRevision: ambiguousRevision,
+		SourceIndex:       sourceIndex,
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("error resolving repo revision: %w", err)
@@ -1984,14 +2850,21 @@ func (s *Server) TerminateOperation(ctx context.Context, termOpReq *application.
 	}
 
 	for i := 0; i < 10; i++ {
-		if a.Operation == nil || a.Status.OperationState == nil {
+		if a.Operation == nil {
 			return nil, status.Errorf(codes.InvalidArgument, "Unable to terminate operation. No operation is in progress")
 		}
-		a.Status.OperationState.Phase = common.OperationTerminating
+		action := "terminated running operation"
+		if isOperationQueued(a) {
+			// The operation was not picked up by the controller yet, so it is simply removed from the queue
+			a.Operation = nil
+			action = "cancelled queued operation"
+		} else {
+			a.Status.OperationState.Phase = common.OperationTerminating
+		}
 		updated, err := s.appclientset.ArgoprojV1alpha1().Applications(appNs).Update(ctx, a, metav1.UpdateOptions{})
 		if err == nil {
 			s.waitSync(updated)
-			s.logAppEvent(a, ctx, argo.EventReasonResourceUpdated, "terminated running operation")
+			s.logAppEvent(a, ctx, argo.EventReasonResourceUpdated, action)
 			return &application.OperationTerminateResponse{}, nil
 		}
 		if !apierr.IsConflict(err) {
@@ -2007,6 +2880,278 @@ func (s *Server) TerminateOperation(ctx context.Context, termOpReq *application.
 	return nil, status.Errorf(codes.Internal, "Failed to terminate app. Too many conflicts")
 }
 
+// ListOperations returns the operations which are in-flight or queued for all applications
+func (s *Server) ListOperations(ctx context.Context, q *application.OperationsQuery) (*application.ApplicationOperationList, error) {
+	var apps []*appv1.Application
+	var err error
+	if q.GetAppNamespace() == "" {
+		apps, err = s.appLister.List(labels.Everything())
+	} else {
+		apps, err = s.appLister.Applications(q.GetAppNamespace()).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+	apps = argoutil.FilterByProjectsP(apps, q.GetProjects())
+
+	now := time.Now()
+	items := make([]*application.ApplicationOperation, 0)
+	for _, a := range apps {
+		if a.Namespace != s.ns && !glob.MatchStringInList(s.enabledNamespaces, a.Namespace, false) {
+			continue
+		}
+		op := newApplicationOperation(a, now)
+		if op == nil {
+			continue
+		}
+		if s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionGet, a.RBACName(s.ns)) {
+			items = append(items, op)
+		}
+	}
+
+	// Sort the longest running operations first, queued operations are not running yet and come last
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].GetDurationSeconds() != items[j].GetDurationSeconds() {
+			return items[i].GetDurationSeconds() > items[j].GetDurationSeconds()
+		}
+		if items[i].GetAppNamespace() != items[j].GetAppNamespace() {
+			return items[i].GetAppNamespace() < items[j].GetAppNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+	return &application.ApplicationOperationList{Items: items}, nil
+}
+
+// isOperationQueued returns whether the requested operation of the given application was not yet started by the
+// application controller
+func isOperationQueued(a *appv1.Application) bool {
+	return a.Operation != nil && (a.Status.OperationState == nil || a.Status.OperationState.Phase.Completed())
+}
+
+// newApplicationOperation summarizes the in-flight or queued operation of the given application, or returns nil if
+// the application has no such operation
+func newApplicationOperation(a *appv1.Application, now time.Time) *application.ApplicationOperation {
+	op := &application.ApplicationOperation{
+		Name:         pointer.String(a.Name),
+		AppNamespace: pointer.String(a.Namespace),
+		Project:      pointer.String(a.Spec.GetProject()),
+	}
+	if isOperationQueued(a) {
+		op.Phase = pointer.String(operationPhaseQueued)
+		op.InitiatedBy = a.Operation.InitiatedBy.DeepCopy()
+		return op
+	}
+	state := a.Status.OperationState
+	if state == nil || state.Phase.Completed() {
+		return nil
+	}
+	op.Phase = pointer.String(string(state.Phase))
+	op.InitiatedBy = state.Operation.InitiatedBy.DeepCopy()
+	op.StartedAt = state.StartedAt.DeepCopy()
+	op.DurationSeconds = pointer.Int64(int64(now.Sub(state.StartedAt.Time).Seconds()))
+	op.Message = pointer.String(state.Message)
+	op.RetryCount = pointer.Int64(state.RetryCount)
+	op.SyncWave = operationSyncWave(a)
+	return op
+}
+
+// operationSyncWave returns the sync wave a running sync operation is at, which is the highest sync wave of the
+// resources synced so far, or nil if no resource was synced yet
+func operationSyncWave(a *appv1.Application) *int64 {
+	state := a.Status.OperationState
+	if state == nil || state.SyncResult == nil {
+		return nil
+	}
+	waves := make(map[kube.ResourceKey]int64)
+	for _, res := range a.Status.Resources {
+		waves[kube.NewResourceKey(res.Group, res.Kind, res.Namespace, res.Name)] = res.SyncWave
+	}
+	var wave *int64
+	for _, res := range state.SyncResult.Resources {
+		if w, ok := waves[kube.NewResourceKey(res.Group, res.Kind, res.Namespace, res.Name)]; ok && (wave == nil || w > *wave) {
+			wave = pointer.Int64(w)
+		}
+	}
+	return wave
+}
+
+// ListOrphanedResources returns the orphaned resources of the requested application, or of all applications of the
+// requested projects. Orphaned resources are only detected for applications whose project enables orphaned resources
+// monitoring.
+func (s *Server) ListOrphanedResources(ctx context.Context, q *application.OrphanedResourcesQuery) (*application.OrphanedResourceList, error) {
+	now := time.Now()
+	items := make([]*application.OrphanedResource, 0)
+	if q.GetName() != "" {
+		a, err := s.getApplicationEnforceRBACInformer(ctx, rbacpolicy.ActionGet, q.GetAppNamespace(), q.GetName())
+		if err != nil {
+			return nil, err
+		}
+		tree, err := s.getAppResources(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("error getting app resources: %w", err)
+		}
+		items = append(items, newOrphanedResources(a, tree, now)...)
+		return &application.OrphanedResourceList{Items: items}, nil
+	}
+
+	var apps []*appv1.Application
+	var err error
+	if q.GetAppNamespace() == "" {
+		apps, err = s.appLister.List(labels.Everything())
+	} else {
+		apps, err = s.appLister.Applications(q.GetAppNamespace()).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+	apps = argoutil.FilterByProjectsP(apps, q.GetProjects())
+
+	for _, a := range apps {
+		if a.Namespace != s.ns && !glob.MatchStringInList(s.enabledNamespaces, a.Namespace, false) {
+			continue
+		}
+		if !s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionGet, a.RBACName(s.ns)) {
+			continue
+		}
+		var tree appv1.ApplicationTree
+		err := s.cache.GetAppResourcesTree(a.InstanceName(s.ns), &tree)
+		if err == servercache.ErrCacheMiss {
+			// the application was not reconciled yet
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting app resource tree: %w", err)
+		}
+		items = append(items, newOrphanedResources(a, &tree, now)...)
+	}
+
+	// Sort the oldest orphaned resources first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].GetAgeSeconds() > items[j].GetAgeSeconds()
+	})
+	return &application.OrphanedResourceList{Items: items}, nil
+}
+
+// newOrphanedResources returns the orphaned resources of the given application tree
+func newOrphanedResources(a *appv1.Application, tree *appv1.ApplicationTree, now time.Time) []*application.OrphanedResource {
+	items := make([]*application.OrphanedResource, 0, len(tree.OrphanedNodes))
+	for _, node := range tree.OrphanedNodes {
+		item := &application.OrphanedResource{
+			Application:  pointer.String(a.Name),
+			AppNamespace: pointer.String(a.Namespace),
+			Project:      pointer.String(a.Spec.GetProject()),
+			Group:        pointer.String(node.Group),
+			Version:      pointer.String(node.Version),
+			Kind:         pointer.String(node.Kind),
+			Namespace:    pointer.String(node.Namespace),
+			Name:         pointer.String(node.Name),
+			UID:          pointer.String(node.UID),
+		}
+		if node.CreatedAt != nil {
+			item.CreatedAt = node.CreatedAt.DeepCopy()
+			item.AgeSeconds = pointer.Int64(int64(now.Sub(node.CreatedAt.Time).Seconds()))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// ResolveOrphanedResource either adopts an orphaned resource by adding the tracking information of the application to
+// it, or deletes it. An adopted resource becomes part of the application and is pruned during the next sync with
+// pruning enabled unless it is added to the source of the application.
+func (s *Server) ResolveOrphanedResource(ctx context.Context, q *application.OrphanedResourceResolveRequest) (*application.ApplicationResponse, error) {
+	var action string
+	switch q.GetAction() {
+	case orphanedResourceActionAdopt:
+		action = rbacpolicy.ActionUpdate
+	case orphanedResourceActionDelete:
+		action = rbacpolicy.ActionDelete
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown action %q, must be one of %s or %s", q.GetAction(), orphanedResourceActionAdopt, orphanedResourceActionDelete)
+	}
+	a, err := s.getApplicationEnforceRBACInformer(ctx, action, q.GetAppNamespace(), q.GetName())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := s.getAppResources(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("error getting app resources: %w", err)
+	}
+	var res *appv1.ResourceNode
+	for i, node := range tree.OrphanedNodes {
+		if node.Group == q.GetGroup() && node.Kind == q.GetKind() && node.Namespace == q.GetNamespace() && node.Name == q.GetResourceName() {
+			res = &tree.OrphanedNodes[i]
+			break
+		}
+	}
+	if res == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s %s %s not found as orphaned resource of application %s", q.GetKind(), q.GetGroup(), q.GetResourceName(), q.GetName())
+	}
+	if q.GetVersion() != "" {
+		res.Version = q.GetVersion()
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(res.GroupKindVersion())
+	obj.SetNamespace(res.Namespace)
+	obj.SetName(res.Name)
+	if err := s.verifyResourcePermitted(ctx, a, obj); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	config, err := s.getApplicationClusterConfig(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("error getting application cluster config: %w", err)
+	}
+
+	if q.GetAction() == orphanedResourceActionDelete {
+		propagationPolicy := metav1.DeletePropagationForeground
+		err = s.kubectl.DeleteResource(ctx, config, res.GroupKindVersion(), res.Name, res.Namespace, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		if err != nil {
+			return nil, fmt.Errorf("error deleting resource: %w", err)
+		}
+		s.logAppEvent(a, ctx, argo.EventReasonResourceDeleted, fmt.Sprintf("deleted orphaned resource %s/%s '%s'", q.GetGroup(), q.GetKind(), q.GetResourceName()))
+		return &application.ApplicationResponse{}, nil
+	}
+
+	live, err := s.kubectl.GetResource(ctx, config, res.GroupKindVersion(), res.Name, res.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resource: %w", err)
+	}
+	appLabelKey, err := s.settingsMgr.GetAppInstanceLabelKey()
+	if err != nil {
+		return nil, fmt.Errorf("error getting app instance label key: %w", err)
+	}
+	destinationNamespace, err := argo.GetDestinationNamespace(ctx, &a.Spec, s.db)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving destination namespace: %w", err)
+	}
+	adopted := live.DeepCopy()
+	err = argo.NewResourceTracking().SetAppInstance(adopted, appLabelKey, a.InstanceName(s.ns), destinationNamespace, argoutil.GetTrackingMethod(s.settingsMgr))
+	if err != nil {
+		return nil, fmt.Errorf("error setting app instance tracking info: %w", err)
+	}
+	liveBytes, err := json.Marshal(live)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling live object: %w", err)
+	}
+	adoptedBytes, err := json.Marshal(adopted)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling adopted object: %w", err)
+	}
+	patch, err := jsonpatch.CreateMergePatch(liveBytes, adoptedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating merge patch: %w", err)
+	}
+	_, err = s.kubectl.PatchResource(ctx, config, res.GroupKindVersion(), res.Name, res.Namespace, types.MergePatchType, patch)
+	if err != nil {
+		return nil, fmt.Errorf("error patching resource: %w", err)
+	}
+	appIf := s.appclientset.ArgoprojV1alpha1().Applications(a.Namespace)
+	if _, err = argo.RefreshApp(appIf, a.Name, appv1.RefreshTypeNormal); err != nil {
+		return nil, fmt.Errorf("error refreshing application: %w", err)
+	}
+	s.logAppEvent(a, ctx, argo.EventReasonResourceUpdated, fmt.Sprintf("adopted orphaned resource %s/%s '%s'", q.GetGroup(), q.GetKind(), q.GetResourceName()))
+	return &application.ApplicationResponse{}, nil
+}
+
 func (s *Server) logAppEvent(a *appv1.Application, ctx context.Context, reason string, action string) {
 	eventInfo := argo.EventInfo{Type: v1.EventTypeNormal, Reason: reason}
 	user := session.Username(ctx)
@@ -2017,6 +3162,22 @@ func (s *Server) logAppEvent(a *appv1.Application, ctx context.Context, reason s
 	s.auditLogger.LogAppEvent(a, eventInfo, message, user)
 }
 
+// logDeniedSync records an audit event of a sync of the application which was denied, so that denied syncs show up in
+// the activity of its project. The same denial of a user is recorded at most once per deniedSyncEventInterval.
+func (s *Server) logDeniedSync(a *appv1.Application, ctx context.Context, reason string) {
+	eventInfo := argo.EventInfo{Type: v1.EventTypeWarning, Reason: argo.EventReasonOperationDenied}
+	user := session.Username(ctx)
+	if user == "" {
+		user = "Unknown user"
+	}
+	if err := s.deniedSyncs.Add(strings.Join([]string{a.QualifiedName(), user, reason}, "|"), true, gocache.DefaultExpiration); err != nil {
+		// the denial was recorded recently
+		return
+	}
+	message := fmt.Sprintf("%s was denied to sync: %s", user, reason)
+	s.auditLogger.LogAppEvent(a, eventInfo, message, user)
+}
+
 func (s *Server) logResourceEvent(res *appv1.ResourceNode, ctx context.Context, reason string, action string) {
 	eventInfo := argo.EventInfo{Type: v1.EventTypeNormal, Reason: reason}
 	user := session.Username(ctx)
@@ -2081,20 +3242,7 @@ func (s *Server) getAvailableActions(resourceOverrides map[string]appv1.Resource
 	luaVM := lua.VM{
 		ResourceOverrides: resourceOverrides,
 	}
-
-	discoveryScript, err := luaVM.GetResourceActionDiscovery(obj)
-	if err != nil {
-		return nil, fmt.Errorf("error getting Lua discovery script: %w", err)
-	}
-	if discoveryScript == "" {
-		return []appv1.ResourceAction{}, nil
-	}
-	availableActions, err := luaVM.ExecuteResourceActionDiscovery(obj, discoveryScript)
-	if err != nil {
-		return nil, fmt.Errorf("error executing Lua discovery script: %w", err)
-	}
-	return availableActions, nil
-
+	return luaVM.GetAvailableResourceActions(obj)
 }
 
 func (s *Server) RunResourceAction(ctx context.Context, q *application.ResourceActionRunRequest) (*application.ApplicationResponse, error) {
@@ -2112,6 +3260,12 @@ func (s *Server) RunResourceAction(ctx context.Context, q *application.ResourceA
 	if err != nil {
 		return nil, err
 	}
+	// the resources of the application are changed with the permissions of its sync, unlike the application itself
+	if res != nil {
+		if err := s.impersonateServiceAccount(ctx, config, a); err != nil {
+			return nil, err
+		}
+	}
 
 	liveObjBytes, err := json.Marshal(liveObj)
 	if err != nil {
@@ -2131,7 +3285,12 @@ func (s *Server) RunResourceAction(ctx context.Context, q *application.ResourceA
 		return nil, fmt.Errorf("error getting Lua resource action: %w", err)
 	}
 
-	newObjects, err := luaVM.ExecuteResourceAction(liveObj, action.ActionLua)
+	params, err := lua.ResolveResourceActionParams(action.Params, q.GetParams())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	newObjects, err := luaVM.ExecuteResourceAction(liveObj, action.ActionLua, params)
 	if err != nil {
 		return nil, fmt.Errorf("error executing Lua resource action: %w", err)
 	}
@@ -2396,6 +3555,72 @@ func (s *Server) isNamespaceEnabled(namespace string) bool {
 	return security.IsNamespaceEnabled(namespace, s.ns, s.enabledNamespaces)
 }
 
+// applicationFieldGetters are the fields of applications which are supported in field selectors
+var applicationFieldGetters = map[string]func(a *appv1.Application) string{
+	"metadata.name":           func(a *appv1.Application) string { return a.Name },
+	"metadata.namespace":      func(a *appv1.Application) string { return a.Namespace },
+	"spec.project":            func(a *appv1.Application) string { return a.Spec.GetProject() },
+	"spec.destination.server": func(a *appv1.Application) string { return a.Spec.Destination.Server },
+	"spec.destination.name":   func(a *appv1.Application) string { return a.Spec.Destination.Name },
+	"status.sync.status":      func(a *appv1.Application) string { return string(a.Status.Sync.Status) },
+	"status.health.status":    func(a *appv1.Application) string { return string(a.Status.Health.Status) },
+}
+
+// parseApplicationFieldSelector parses a field selector of an application query, and fails for unsupported fields
+func parseApplicationFieldSelector(selector string) (fields.Selector, error) {
+	fieldSelector, err := fields.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range fieldSelector.Requirements() {
+		if _, ok := applicationFieldGetters[req.Field]; !ok {
+			return nil, fmt.Errorf("field %q is not supported", req.Field)
+		}
+	}
+	return fieldSelector, nil
+}
+
+// applicationFields returns the values of the fields of an application which are supported in field selectors
+func applicationFields(a *appv1.Application) fields.Set {
+	set := fields.Set{}
+	for field, get := range applicationFieldGetters {
+		set[field] = get(a)
+	}
+	return set
+}
+
+// applicationListContinue identifies the last application of a page of a limited application list
+type applicationListContinue struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+func decodeApplicationListContinue(token string) (*applicationListContinue, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var c applicationListContinue
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *applicationListContinue) encode() string {
+	// marshalling a struct of strings can't fail
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// precedes returns whether the last application of the previous page precedes the given application in the list
+func (c *applicationListContinue) precedes(a *appv1.Application) bool {
+	if a.Name != c.Name {
+		return c.Name < a.Name
+	}
+	return c.Namespace < a.Namespace
+}
+
 // getProjectFromApplicationQuery gets the project names from a query. If the legacy "project" field was specified, use
 // that. Otherwise, use the newer "projects" field.
 func getProjectsFromApplicationQuery(q application.ApplicationQuery) []string {
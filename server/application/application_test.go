@@ -2,6 +2,12 @@ package application
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	coreerrors "errors"
 	"fmt"
 	"io"
@@ -26,10 +32,12 @@ import (
 	k8sbatchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
@@ -57,6 +65,7 @@ import (
 	"github.com/argoproj/argo-cd/v2/util/db"
 	"github.com/argoproj/argo-cd/v2/util/errors"
 	"github.com/argoproj/argo-cd/v2/util/grpc"
+	"github.com/argoproj/argo-cd/v2/util/provenance"
 	"github.com/argoproj/argo-cd/v2/util/rbac"
 	"github.com/argoproj/argo-cd/v2/util/settings"
 )
@@ -643,6 +652,106 @@ func (t *TestResourceTreeServer) RecvMsg(m interface{}) error {
 	return nil
 }
 
+type TestResourceTreeDeltaServer struct {
+	ctx    context.Context
+	events []*application.ResourceTreeEvent
+}
+
+func (t *TestResourceTreeDeltaServer) Send(event *application.ResourceTreeEvent) error {
+	t.events = append(t.events, event)
+	return nil
+}
+
+func (t *TestResourceTreeDeltaServer) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (t *TestResourceTreeDeltaServer) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (t *TestResourceTreeDeltaServer) SetTrailer(metadata.MD) {}
+
+func (t *TestResourceTreeDeltaServer) Context() context.Context {
+	return t.ctx
+}
+
+func (t *TestResourceTreeDeltaServer) SendMsg(m interface{}) error {
+	return nil
+}
+
+func (t *TestResourceTreeDeltaServer) RecvMsg(m interface{}) error {
+	return nil
+}
+
+type TestResourceEventsServer struct {
+	ctx    context.Context
+	events chan *v1.Event
+}
+
+func (t *TestResourceEventsServer) Send(event *v1.Event) error {
+	t.events <- event
+	return nil
+}
+
+func (t *TestResourceEventsServer) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (t *TestResourceEventsServer) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (t *TestResourceEventsServer) SetTrailer(metadata.MD) {}
+
+func (t *TestResourceEventsServer) Context() context.Context {
+	return t.ctx
+}
+
+func (t *TestResourceEventsServer) SendMsg(m interface{}) error {
+	return nil
+}
+
+func (t *TestResourceEventsServer) RecvMsg(m interface{}) error {
+	return nil
+}
+
+type TestWaitServer struct {
+	ctx       context.Context
+	responses []*application.ApplicationWaitResponse
+	onSend    func(res *application.ApplicationWaitResponse)
+}
+
+func (t *TestWaitServer) Send(res *application.ApplicationWaitResponse) error {
+	t.responses = append(t.responses, res)
+	if t.onSend != nil {
+		t.onSend(res)
+	}
+	return nil
+}
+
+func (t *TestWaitServer) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (t *TestWaitServer) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (t *TestWaitServer) SetTrailer(metadata.MD) {}
+
+func (t *TestWaitServer) Context() context.Context {
+	return t.ctx
+}
+
+func (t *TestWaitServer) SendMsg(m interface{}) error {
+	return nil
+}
+
+func (t *TestWaitServer) RecvMsg(m interface{}) error {
+	return nil
+}
+
 type TestPodLogsServer struct {
 	ctx context.Context
 }
@@ -922,6 +1031,13 @@ func TestNoAppEnumeration(t *testing.T) {
 		assert.Equal(t, permissionDeniedErr.Error(), err.Error(), "error message must be _only_ the permission error, to avoid leaking information about app existence")
 	})
 
+	t.Run("WatchResourceTreeDelta", func(t *testing.T) {
+		err := appServer.WatchResourceTreeDelta(&application.ResourcesQuery{ApplicationName: pointer.String("test")}, &TestResourceTreeDeltaServer{ctx: noRoleCtx})
+		assert.Equal(t, permissionDeniedErr.Error(), err.Error(), "error message must be _only_ the permission error, to avoid leaking information about app existence")
+		err = appServer.WatchResourceTreeDelta(&application.ResourcesQuery{ApplicationName: pointer.String("does-not-exist")}, &TestResourceTreeDeltaServer{ctx: adminCtx})
+		assert.Equal(t, permissionDeniedErr.Error(), err.Error(), "error message must be _only_ the permission error, to avoid leaking information about app existence")
+	})
+
 	t.Run("PodLogs", func(t *testing.T) {
 		err := appServer.PodLogs(&application.ApplicationPodLogsQuery{Name: pointer.String("test")}, &TestPodLogsServer{ctx: adminCtx})
 		assert.NoError(t, err)
@@ -1151,6 +1267,60 @@ func TestListApps(t *testing.T) {
 	assert.Equal(t, []string{"abc", "bcd", "def"}, names)
 }
 
+func TestListAppsWithLimit(t *testing.T) {
+	appServer := newTestAppServer(t, newTestApp(func(app *appsv1.Application) {
+		app.Name = "bcd"
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "abc"
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "def"
+	}))
+
+	res, err := appServer.List(context.Background(), &application.ApplicationQuery{Limit: pointer.Int64(2)})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	assert.Equal(t, "abc", res.Items[0].Name)
+	assert.Equal(t, "bcd", res.Items[1].Name)
+	assert.Equal(t, int64(1), *res.RemainingItemCount)
+	require.NotEmpty(t, res.Continue)
+
+	res, err = appServer.List(context.Background(), &application.ApplicationQuery{Limit: pointer.Int64(2), Continue: pointer.String(res.Continue)})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, "def", res.Items[0].Name)
+	assert.Empty(t, res.Continue)
+	assert.Nil(t, res.RemainingItemCount)
+
+	_, err = appServer.List(context.Background(), &application.ApplicationQuery{Continue: pointer.String("invalid")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = appServer.List(context.Background(), &application.ApplicationQuery{Limit: pointer.Int64(-1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListAppsWithFieldSelector(t *testing.T) {
+	appServer := newTestAppServer(t, newTestApp(func(app *appsv1.Application) {
+		app.Name = "abc"
+		app.Status.Sync.Status = appsv1.SyncStatusCodeSynced
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "bcd"
+		app.Status.Sync.Status = appsv1.SyncStatusCodeOutOfSync
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "def"
+		app.Spec.Destination.Server = "https://other-cluster"
+		app.Status.Sync.Status = appsv1.SyncStatusCodeOutOfSync
+	}))
+
+	res, err := appServer.List(context.Background(), &application.ApplicationQuery{
+		FieldSelector: pointer.String("status.sync.status=OutOfSync,spec.destination.server!=https://other-cluster"),
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, "bcd", res.Items[0].Name)
+
+	_, err = appServer.List(context.Background(), &application.ApplicationQuery{FieldSelector: pointer.String("spec.source.path=foo")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestCoupleAppsListApps(t *testing.T) {
 	var objects []runtime.Object
 	ctx := context.Background()
@@ -1337,6 +1507,113 @@ func TestCreateApp(t *testing.T) {
 	assert.Equal(t, app.Spec.Project, "default")
 }
 
+func TestCreateAppServerSideApply(t *testing.T) {
+	newApplyServer := func(t *testing.T, merged *appsv1.Application) (*Server, *int) {
+		appServer := newTestAppServer(t)
+		patches := 0
+		appServer.appclientset.(*apps.Clientset).PrependReactor("patch", "applications", func(action kubetesting.Action) (handled bool, ret runtime.Object, err error) {
+			patches++
+			return true, merged.DeepCopy(), nil
+		})
+		return appServer, &patches
+	}
+	serverSideApply := true
+
+	t.Run("ValidMergedApplication", func(t *testing.T) {
+		merged := newTestApp()
+		merged.Spec.Source = nil
+		merged.Spec.Sources = appsv1.ApplicationSources{*newTestApp().Spec.Source}
+		appServer, patches := newApplyServer(t, merged)
+		app, err := appServer.Create(context.Background(), &application.ApplicationCreateRequest{Application: newTestApp(), ServerSideApply: &serverSideApply})
+		require.NoError(t, err)
+		assert.Len(t, app.Spec.Sources, 1)
+		// the application is applied in dry-run mode first, then for real
+		assert.Equal(t, 2, *patches)
+	})
+
+	t.Run("InvalidMergedApplication", func(t *testing.T) {
+		merged := newTestApp()
+		merged.Spec.Project = "does-not-exist"
+		appServer, patches := newApplyServer(t, merged)
+		_, err := appServer.Create(context.Background(), &application.ApplicationCreateRequest{Application: newTestApp(), ServerSideApply: &serverSideApply})
+		assert.Error(t, err)
+		assert.Equal(t, 1, *patches)
+	})
+}
+
+func TestCreateAppWithApplicationsQuota(t *testing.T) {
+	quotaProj := &appsv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota-proj", Namespace: "default"},
+		Spec: appsv1.AppProjectSpec{
+			SourceRepos:  []string{"*"},
+			Destinations: []appsv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+			Quotas:       &appsv1.ProjectQuotas{MaxApplications: 1},
+		},
+	}
+	existingApp := newTestApp(func(app *appsv1.Application) {
+		app.Name = "existing-app"
+		app.Spec.Project = "quota-proj"
+	})
+	appServer := newTestAppServer(t, quotaProj, existingApp)
+
+	testApp := newTestApp()
+	testApp.Spec.Project = "quota-proj"
+	_, err := appServer.Create(context.Background(), &application.ApplicationCreateRequest{Application: testApp})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(coreerrors.Unwrap(err)))
+	assert.ErrorContains(t, err, "project 'quota-proj' has reached its quota of 1 applications")
+
+	// applications which are already in the project can still be updated
+	existingApp.Spec.Destination.Namespace = "other-ns"
+	_, err = appServer.Update(context.Background(), &application.ApplicationUpdateRequest{Application: existingApp})
+	assert.NoError(t, err)
+
+	t.Run("Create existing application again", func(t *testing.T) {
+		app, err := appServer.Get(context.Background(), &application.ApplicationQuery{Name: pointer.String("existing-app")})
+		require.NoError(t, err)
+		_, err = appServer.Create(context.Background(), &application.ApplicationCreateRequest{Application: app.DeepCopy()})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Upsert existing application", func(t *testing.T) {
+		app, err := appServer.Get(context.Background(), &application.ApplicationQuery{Name: pointer.String("existing-app")})
+		require.NoError(t, err)
+		app = app.DeepCopy()
+		app.Spec.Destination.Namespace = "upserted-ns"
+		_, err = appServer.Create(context.Background(), &application.ApplicationCreateRequest{Application: app, Upsert: pointer.Bool(true)})
+		assert.NoError(t, err)
+	})
+}
+
+func TestCreateAppWithApplicationsQuota_NamespaceScoped(t *testing.T) {
+	quotaProj := &appsv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota-proj", Namespace: "default"},
+		Spec: appsv1.AppProjectSpec{
+			SourceRepos:  []string{"*"},
+			Destinations: []appsv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+			Quotas:       &appsv1.ProjectQuotas{MaxApplications: 1},
+		},
+	}
+	// applications in namespaces which aren't managed by Argo CD don't count against the quota
+	unmanagedApp := newTestApp(func(app *appsv1.Application) {
+		app.Name = "unmanaged-app"
+		app.Namespace = "unmanaged"
+		app.Spec.Project = "quota-proj"
+	})
+	appServer := newTestAppServer(t, quotaProj, unmanagedApp)
+	// namespace-scoped installations aren't allowed to list applications in all namespaces
+	appServer.appclientset.(*apps.Clientset).PrependReactor("list", "applications", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == metav1.NamespaceAll {
+			return true, nil, apierr.NewForbidden(schema.GroupResource{Group: "argoproj.io", Resource: "applications"}, "", fmt.Errorf("namespace-scoped"))
+		}
+		return false, nil, nil
+	})
+
+	testApp := newTestApp()
+	testApp.Spec.Project = "quota-proj"
+	_, err := appServer.Create(context.Background(), &application.ApplicationCreateRequest{Application: testApp})
+	assert.NoError(t, err)
+}
+
 func TestCreateAppWithDestName(t *testing.T) {
 	appServer := newTestAppServer(t)
 	testApp := newTestAppWithDestName()
@@ -1360,6 +1637,26 @@ func TestUpdateApp(t *testing.T) {
 	assert.Equal(t, app.Spec.Project, "default")
 }
 
+func TestUpdateAppWithInvalidSyncPolicy(t *testing.T) {
+	testApp := newTestApp()
+	testApp.Spec.SyncPolicy = &appsv1.SyncPolicy{SyncOptions: appsv1.SyncOptions{"PrunePropagationPolicy=sideways"}}
+	appServer := newTestAppServer(t, testApp)
+
+	t.Run("UnchangedSyncPolicy", func(t *testing.T) {
+		updated := testApp.DeepCopy()
+		updated.Labels = map[string]string{"team": "a"}
+		_, err := appServer.Update(context.Background(), &application.ApplicationUpdateRequest{Application: updated})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ChangedSyncPolicy", func(t *testing.T) {
+		updated := testApp.DeepCopy()
+		updated.Spec.SyncPolicy.SyncOptions = append(updated.Spec.SyncPolicy.SyncOptions, "CreateNamespace=true")
+		_, err := appServer.Update(context.Background(), &application.ApplicationUpdateRequest{Application: updated})
+		assert.ErrorContains(t, err, "PrunePropagationPolicy")
+	})
+}
+
 func TestUpdateAppSpec(t *testing.T) {
 	testApp := newTestApp()
 	appServer := newTestAppServer(t, testApp)
@@ -1505,6 +1802,198 @@ func TestSyncAndTerminate(t *testing.T) {
 	assert.Equal(t, synccommon.OperationTerminating, app.Status.OperationState.Phase)
 }
 
+func TestListOperations(t *testing.T) {
+	startedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+	appServer := newTestAppServer(t, newTestApp(func(app *appsv1.Application) {
+		app.Name = "idle"
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "queued"
+		app.Operation = &appsv1.Operation{Sync: &appsv1.SyncOperation{}, InitiatedBy: appsv1.OperationInitiator{Username: "admin"}}
+		app.Status.OperationState = &appsv1.OperationState{Phase: synccommon.OperationSucceeded}
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "running"
+		app.Operation = &appsv1.Operation{Sync: &appsv1.SyncOperation{}, InitiatedBy: appsv1.OperationInitiator{Automated: true}}
+		app.Status.Resources = []appsv1.ResourceStatus{
+			{Kind: "ConfigMap", Namespace: "default", Name: "config", SyncWave: -1},
+			{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "app", SyncWave: 2},
+			{Kind: "Service", Namespace: "default", Name: "app", SyncWave: 3},
+		}
+		app.Status.OperationState = &appsv1.OperationState{
+			Operation: *app.Operation,
+			Phase:     synccommon.OperationRunning,
+			Message:   "waiting for healthy state of apps/Deployment/app",
+			StartedAt: startedAt,
+			SyncResult: &appsv1.SyncOperationResult{Resources: appsv1.ResourceResults{
+				{Kind: "ConfigMap", Namespace: "default", Name: "config"},
+				{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "app"},
+			}},
+		}
+	}), newTestApp(func(app *appsv1.Application) {
+		app.Name = "other-project"
+		app.Spec.Project = "other"
+		app.Operation = &appsv1.Operation{Sync: &appsv1.SyncOperation{}}
+	}))
+
+	res, err := appServer.ListOperations(context.Background(), &application.OperationsQuery{Projects: []string{"default"}})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+
+	running := res.Items[0]
+	assert.Equal(t, "running", running.GetName())
+	assert.Equal(t, string(synccommon.OperationRunning), running.GetPhase())
+	assert.Equal(t, int64(2), running.GetSyncWave())
+	assert.True(t, running.GetInitiatedBy().Automated)
+	assert.GreaterOrEqual(t, running.GetDurationSeconds(), int64(60))
+	assert.Equal(t, "waiting for healthy state of apps/Deployment/app", running.GetMessage())
+
+	queued := res.Items[1]
+	assert.Equal(t, "queued", queued.GetName())
+	assert.Equal(t, operationPhaseQueued, queued.GetPhase())
+	assert.Nil(t, queued.SyncWave)
+	assert.Nil(t, queued.StartedAt)
+	assert.Equal(t, "admin", queued.GetInitiatedBy().Username)
+
+	res, err = appServer.ListOperations(context.Background(), &application.OperationsQuery{})
+	require.NoError(t, err)
+	assert.Len(t, res.Items, 3)
+}
+
+func TestTerminateQueuedOperation(t *testing.T) {
+	appServer := newTestAppServer(t, newTestApp(func(app *appsv1.Application) {
+		app.Operation = &appsv1.Operation{Sync: &appsv1.SyncOperation{}}
+	}))
+
+	_, err := appServer.TerminateOperation(context.Background(), &application.OperationTerminateRequest{Name: pointer.String("test-app")})
+	require.NoError(t, err)
+
+	app, err := appServer.Get(context.Background(), &application.ApplicationQuery{Name: pointer.String("test-app")})
+	require.NoError(t, err)
+	assert.Nil(t, app.Operation)
+	assert.Nil(t, app.Status.OperationState)
+
+	_, err = appServer.TerminateOperation(context.Background(), &application.OperationTerminateRequest{Name: pointer.String("test-app")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestResolveRevision(t *testing.T) {
+	appServer := newTestAppServer(t, newTestApp())
+
+	res, err := appServer.ResolveRevision(context.Background(), &application.ApplicationResolveRevisionRequest{Name: pointer.String("test-app")})
+	require.NoError(t, err)
+	assert.Equal(t, fakeResolveRevesionResponse(), res)
+
+	_, err = appServer.ResolveRevision(context.Background(), &application.ApplicationResolveRevisionRequest{Name: pointer.String("test-app"), SourceIndex: pointer.Int64(1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListOrphanedResources(t *testing.T) {
+	createdAt := metav1.NewTime(time.Now().Add(-time.Hour))
+	appServer := newTestAppServer(t, newTestApp(), newTestApp(func(app *appsv1.Application) {
+		app.Name = "other-project"
+		app.Spec.Project = "my-proj"
+	}))
+	appStateCache := appstate.NewCache(cacheutil.NewCache(cacheutil.NewInMemoryCache(time.Hour)), time.Hour)
+	require.NoError(t, appStateCache.SetAppResourcesTree("test-app", &appsv1.ApplicationTree{
+		Nodes: []appsv1.ResourceNode{{ResourceRef: appsv1.ResourceRef{Kind: "Service", Namespace: "default", Name: "managed"}}},
+		OrphanedNodes: []appsv1.ResourceNode{
+			{ResourceRef: appsv1.ResourceRef{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "new", UID: "1"}, CreatedAt: &metav1.Time{Time: time.Now()}},
+			{ResourceRef: appsv1.ResourceRef{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "old", UID: "2"}, CreatedAt: &createdAt},
+		},
+	}))
+	require.NoError(t, appStateCache.SetAppResourcesTree("other-project", &appsv1.ApplicationTree{
+		OrphanedNodes: []appsv1.ResourceNode{{ResourceRef: appsv1.ResourceRef{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "default", Name: "other"}}},
+	}))
+	appServer.cache = servercache.NewCache(appStateCache, time.Minute, time.Minute, time.Minute)
+
+	res, err := appServer.ListOrphanedResources(context.Background(), &application.OrphanedResourcesQuery{Projects: []string{"default"}})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	assert.Equal(t, "old", res.Items[0].GetName())
+	assert.Equal(t, "test-app", res.Items[0].GetApplication())
+	assert.Equal(t, "default", res.Items[0].GetProject())
+	assert.Equal(t, "ConfigMap", res.Items[0].GetKind())
+	assert.GreaterOrEqual(t, res.Items[0].GetAgeSeconds(), int64(3600))
+	assert.Equal(t, "new", res.Items[1].GetName())
+
+	res, err = appServer.ListOrphanedResources(context.Background(), &application.OrphanedResourcesQuery{})
+	require.NoError(t, err)
+	assert.Len(t, res.Items, 3)
+
+	res, err = appServer.ListOrphanedResources(context.Background(), &application.OrphanedResourcesQuery{Name: pointer.String("other-project")})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, "Deployment", res.Items[0].GetKind())
+	assert.Nil(t, res.Items[0].CreatedAt)
+}
+
+func TestResolveOrphanedResource(t *testing.T) {
+	configMap := kube.MustToUnstructured(&corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "orphaned", Namespace: "default"},
+	})
+	appServer := newTestAppServer(t, newTestApp(), configMap)
+	appStateCache := appstate.NewCache(cacheutil.NewCache(cacheutil.NewInMemoryCache(time.Hour)), time.Hour)
+	require.NoError(t, appStateCache.SetAppResourcesTree("test-app", &appsv1.ApplicationTree{
+		Nodes:         []appsv1.ResourceNode{{ResourceRef: appsv1.ResourceRef{Version: "v1", Kind: "Service", Namespace: "default", Name: "managed", UID: "1"}}},
+		OrphanedNodes: []appsv1.ResourceNode{{ResourceRef: appsv1.ResourceRef{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "orphaned", UID: "2"}}},
+	}))
+	appServer.cache = servercache.NewCache(appStateCache, time.Minute, time.Minute, time.Minute)
+
+	request := func(kind, name, action string) *application.OrphanedResourceResolveRequest {
+		return &application.OrphanedResourceResolveRequest{
+			Name:         pointer.String("test-app"),
+			Namespace:    pointer.String("default"),
+			ResourceName: pointer.String(name),
+			Version:      pointer.String("v1"),
+			Kind:         pointer.String(kind),
+			Action:       pointer.String(action),
+		}
+	}
+
+	t.Run("UnknownAction", func(t *testing.T) {
+		_, err := appServer.ResolveOrphanedResource(context.Background(), request("ConfigMap", "orphaned", "ignore"))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("ManagedResource", func(t *testing.T) {
+		_, err := appServer.ResolveOrphanedResource(context.Background(), request("Service", "managed", orphanedResourceActionDelete))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Adopt", func(t *testing.T) {
+		_, err := appServer.ResolveOrphanedResource(context.Background(), request("ConfigMap", "orphaned", orphanedResourceActionAdopt))
+		require.NoError(t, err)
+		app, err := appServer.appclientset.ArgoprojV1alpha1().Applications(testNamespace).Get(context.Background(), "test-app", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, string(appsv1.RefreshTypeNormal), app.Annotations[appsv1.AnnotationKeyRefresh])
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := appServer.ResolveOrphanedResource(context.Background(), request("ConfigMap", "orphaned", orphanedResourceActionDelete))
+		require.NoError(t, err)
+	})
+
+	t.Run("ResourceNotPermitted", func(t *testing.T) {
+		configMapDenyingProj := &appsv1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "configmap-denying-proj", Namespace: "default"},
+			Spec: appsv1.AppProjectSpec{
+				SourceRepos:                []string{"*"},
+				Destinations:               []appsv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+				NamespaceResourceBlacklist: []metav1.GroupKind{{Group: "", Kind: "ConfigMap"}},
+			},
+		}
+		testApp := newTestApp()
+		testApp.Spec.Project = configMapDenyingProj.Name
+		appServer := newTestAppServer(t, testApp, configMapDenyingProj, configMap)
+		appServer.cache = servercache.NewCache(appStateCache, time.Minute, time.Minute, time.Minute)
+
+		for _, action := range []string{orphanedResourceActionAdopt, orphanedResourceActionDelete} {
+			_, err := appServer.ResolveOrphanedResource(context.Background(), request("ConfigMap", "orphaned", action))
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		}
+	})
+}
+
 func TestSyncHelm(t *testing.T) {
 	ctx := context.Background()
 	appServer := newTestAppServer(t)
@@ -1557,6 +2046,67 @@ func TestSyncGit(t *testing.T) {
 	assert.Equal(t, "Unknown user initiated sync locally", events.Items[1].Message)
 }
 
+func TestSyncLabelSelector(t *testing.T) {
+	ctx := context.Background()
+	appServer := newTestAppServer(t)
+	testApp := newTestApp()
+	testApp.Spec.Source.RepoURL = "https://github.com/argoproj/argo-cd.git"
+	app, err := appServer.Create(ctx, &application.ApplicationCreateRequest{Application: testApp})
+	require.NoError(t, err)
+
+	_, err = appServer.Sync(ctx, &application.ApplicationSyncRequest{Name: &app.Name, LabelSelector: pointer.String("tier in (frontend")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	app, err = appServer.Sync(ctx, &application.ApplicationSyncRequest{Name: &app.Name, LabelSelector: pointer.String("tier=frontend")})
+	require.NoError(t, err)
+	assert.Equal(t, "tier=frontend", app.Operation.Sync.LabelSelector)
+	events, err := appServer.kubeclientset.CoreV1().Events(appServer.ns).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, events.Items[1].Message, "initiated partial sync to HEAD")
+}
+
+func TestSyncDenied(t *testing.T) {
+	t.Run("SyncWindow", func(t *testing.T) {
+		deniedProj := &appsv1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "proj-deny", Namespace: testNamespace},
+			Spec: appsv1.AppProjectSpec{
+				SourceRepos:  []string{"*"},
+				Destinations: []appsv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+				SyncWindows:  appsv1.SyncWindows{{Kind: "deny", Schedule: "* * * * *", Duration: "1h", Applications: []string{"*"}}},
+			},
+		}
+		testApp := newTestApp()
+		testApp.Spec.Project = deniedProj.Name
+		appServer := newTestAppServer(t, testApp, deniedProj)
+
+		_, err := appServer.Sync(context.Background(), &application.ApplicationSyncRequest{Name: &testApp.Name})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		events, err := appServer.kubeclientset.CoreV1().Events(appServer.ns).List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, events.Items, 1)
+		assert.Equal(t, argo.EventReasonOperationDenied, events.Items[0].Reason)
+		assert.Equal(t, "Unknown user was denied to sync: blocked by sync window", events.Items[0].Message)
+	})
+	t.Run("Permission", func(t *testing.T) {
+		testApp := newTestApp()
+		appServer := newTestAppServerWithEnforcerConfigure(func(enf *rbac.Enforcer) {
+			_ = enf.SetBuiltinPolicy(assets.BuiltinPolicyCSV)
+			enf.SetDefaultRole("role:readonly")
+		}, t, testApp)
+
+		_, err := appServer.Sync(context.Background(), &application.ApplicationSyncRequest{Name: &testApp.Name})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		// the same denial is recorded only once
+		_, err = appServer.Sync(context.Background(), &application.ApplicationSyncRequest{Name: &testApp.Name})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		events, err := appServer.kubeclientset.CoreV1().Events(appServer.ns).List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, events.Items, 1)
+		assert.Equal(t, argo.EventReasonOperationDenied, events.Items[0].Reason)
+		assert.Equal(t, "Unknown user was denied to sync: permission denied", events.Items[0].Message)
+	})
+}
+
 func TestRollbackApp(t *testing.T) {
 	testApp := newTestApp()
 	testApp.Status.History = []appsv1.RevisionHistory{{
@@ -1579,6 +2129,123 @@ func TestRollbackApp(t *testing.T) {
 	assert.Equal(t, "abc", updatedApp.Operation.Sync.Revision)
 }
 
+func TestRollbackAppToRevision(t *testing.T) {
+	testApp := newTestApp()
+	appServer := newTestAppServer(t, testApp)
+
+	updatedApp, err := appServer.Rollback(context.Background(), &application.ApplicationRollbackRequest{
+		Name:     &testApp.Name,
+		Id:       pointer.Int64(0),
+		Revision: pointer.String("v1.0.0"),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, updatedApp.Operation)
+	require.NotNil(t, updatedApp.Operation.Sync)
+	assert.Equal(t, "f9ba9e98119bf8c1176fbd65dbae26a71d044add", updatedApp.Operation.Sync.Revision)
+	assert.Equal(t, testApp.Spec.Source, updatedApp.Operation.Sync.Source)
+	assert.True(t, updatedApp.Operation.Sync.ManualRevision)
+
+	t.Run("MultipleSources", func(t *testing.T) {
+		testApp := newTestApp(func(app *appsv1.Application) {
+			app.Spec.Sources = appsv1.ApplicationSources{*app.Spec.Source, *app.Spec.Source}
+			app.Spec.Source = nil
+		})
+		appServer := newTestAppServer(t, testApp)
+		_, err := appServer.Rollback(context.Background(), &application.ApplicationRollbackRequest{
+			Name:     &testApp.Name,
+			Id:       pointer.Int64(0),
+			Revision: pointer.String("v1.0.0"),
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestSubmitManifests(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	testApp := newTestApp(func(app *appsv1.Application) {
+		app.Annotations = map[string]string{common.AnnotationKeyExternalManifests: "true"}
+	})
+	appServer := newTestAppServer(t, testApp)
+	ctx := context.Background()
+	cm, err := appServer.kubeclientset.CoreV1().ConfigMaps(testNamespace).Get(ctx, common.ArgoCDConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	cm.Data = map[string]string{"manifests.provenance.publicKeys": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))}
+	_, err = appServer.kubeclientset.CoreV1().ConfigMaps(testNamespace).Update(ctx, cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	appServer.settingsMgr = settings.NewSettingsManager(ctx, appServer.kubeclientset, testNamespace)
+
+	manifests := []string{`{"apiVersion":"v1","kind":"Service","metadata":{"name":"guestbook"}}`}
+	sign := func(app *appsv1.Application, revision string, manifests []string) *string {
+		return pointer.String(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, provenance.Payload(argo.ExternalManifestsSubject(app), revision, manifests))))
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		_, err := appServer.SubmitManifests(ctx, &application.ApplicationSubmitManifestsRequest{
+			Name:      &testApp.Name,
+			Revision:  pointer.String("abc"),
+			Manifests: manifests,
+			Signature: sign(testApp, "abc", manifests),
+		})
+		require.NoError(t, err)
+		rendered, err := appServer.db.GetRenderedManifests(ctx, testApp, "abc")
+		require.NoError(t, err)
+		require.NotNil(t, rendered)
+		assert.Equal(t, "abc", rendered.Revision)
+		assert.Equal(t, manifests, rendered.Manifests)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		_, err := appServer.SubmitManifests(ctx, &application.ApplicationSubmitManifestsRequest{
+			Name:      &testApp.Name,
+			Revision:  pointer.String("def"),
+			Manifests: manifests,
+			Signature: sign(testApp, "abc", manifests),
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("OtherDestination", func(t *testing.T) {
+		otherDestination := testApp.DeepCopy()
+		otherDestination.Spec.Destination.Namespace = "kube-system"
+		_, err := appServer.SubmitManifests(ctx, &application.ApplicationSubmitManifestsRequest{
+			Name:      &testApp.Name,
+			Revision:  pointer.String("abc"),
+			Manifests: manifests,
+			Signature: sign(otherDestination, "abc", manifests),
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("InvalidManifest", func(t *testing.T) {
+		_, err := appServer.SubmitManifests(ctx, &application.ApplicationSubmitManifestsRequest{
+			Name:      &testApp.Name,
+			Revision:  pointer.String("abc"),
+			Manifests: []string{"kind: Service"},
+			Signature: sign(testApp, "abc", []string{"kind: Service"}),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		otherApp := newTestApp(func(app *appsv1.Application) {
+			app.Name = "other-app"
+		})
+		appServer := newTestAppServer(t, otherApp)
+		_, err := appServer.SubmitManifests(ctx, &application.ApplicationSubmitManifestsRequest{
+			Name:      &otherApp.Name,
+			Revision:  pointer.String("abc"),
+			Manifests: manifests,
+			Signature: sign(otherApp, "abc", manifests),
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
 func TestUpdateAppProject(t *testing.T) {
 	testApp := newTestApp()
 	ctx := context.Background()
@@ -1666,6 +2333,18 @@ func TestAppJsonPatch(t *testing.T) {
 	app, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{Name: &testApp.Name, Patch: pointer.String(`[{"op": "remove", "path": "/metadata/annotations/test.annotation"}]`)})
 	assert.NoError(t, err)
 	assert.NotContains(t, app.Annotations, "test.annotation")
+
+	_, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{Name: &testApp.Name, Patch: pointer.String(`[{"op": "remove", "path": "/spec/unknown"}]`)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{Name: &testApp.Name, Patch: pointer.String(
+		`[{"op": "test", "path": "/spec/source/path", "value": "bar"}, {"op": "replace", "path": "/spec/source/path", "value": "baz"}]`)})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	app, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{Name: &testApp.Name, PatchType: pointer.String("application/json-patch+json"), Patch: pointer.String(
+		`[{"op": "test", "path": "/spec/source/path", "value": "foo"}, {"op": "replace", "path": "/spec/source/path", "value": "baz"}]`)})
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", app.Spec.Source.Path)
 }
 
 func TestAppMergePatch(t *testing.T) {
@@ -1680,6 +2359,36 @@ func TestAppMergePatch(t *testing.T) {
 		Name: &testApp.Name, Patch: pointer.String(`{"spec": { "source": { "path": "foo" } }}`), PatchType: pointer.String("merge")})
 	assert.NoError(t, err)
 	assert.Equal(t, "foo", app.Spec.Source.Path)
+
+	app, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{
+		Name: &testApp.Name, Patch: pointer.String(`{"spec": { "source": { "targetRevision": "v2" } }}`), PatchType: pointer.String("application/merge-patch+json")})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", app.Spec.Source.Path)
+	assert.Equal(t, "v2", app.Spec.Source.TargetRevision)
+}
+
+func TestAppStrategicMergePatch(t *testing.T) {
+	testApp := newTestApp()
+	testApp.Spec.Source.Helm = &appsv1.ApplicationSourceHelm{ReleaseName: "release", ValueFiles: []string{"values.yaml"}}
+	ctx := context.Background()
+	// nolint:staticcheck
+	ctx = context.WithValue(ctx, "claims", &jwt.StandardClaims{Subject: "admin"})
+	appServer := newTestAppServer(t, testApp)
+	appServer.enf.SetDefaultRole("")
+
+	app, err := appServer.Patch(ctx, &application.ApplicationPatchRequest{
+		Name: &testApp.Name, Patch: pointer.String(`{"spec": { "source": { "helm": { "releaseName": "other" } } }}`), PatchType: pointer.String("strategic")})
+	assert.NoError(t, err)
+	assert.Equal(t, "other", app.Spec.Source.Helm.ReleaseName)
+	assert.Equal(t, []string{"values.yaml"}, app.Spec.Source.Helm.ValueFiles)
+
+	_, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{
+		Name: &testApp.Name, Patch: pointer.String(`{"metadata": { "name": "renamed" }}`), PatchType: pointer.String("merge")})
+	assert.ErrorContains(t, err, "must not change the application name")
+
+	_, err = appServer.Patch(ctx, &application.ApplicationPatchRequest{
+		Name: &testApp.Name, Patch: pointer.String(`{}`), PatchType: pointer.String("unknown")})
+	assert.ErrorContains(t, err, "Patch type 'unknown' is not supported")
 }
 
 func TestServer_GetApplicationSyncWindowsState(t *testing.T) {
@@ -2003,6 +2712,142 @@ func TestInferResourcesStatusHealth(t *testing.T) {
 	assert.Nil(t, testApp.Status.Resources[1].Health)
 }
 
+func TestWatchResourceTreeDelta(t *testing.T) {
+	cacheClient := cacheutil.NewCache(cacheutil.NewInMemoryCache(1 * time.Hour))
+
+	testApp := newTestApp()
+	appServer := newTestAppServer(t, testApp)
+	appStateCache := appstate.NewCache(cacheClient, time.Minute)
+	tree := &appsv1.ApplicationTree{Nodes: []appsv1.ResourceNode{{
+		ResourceRef: appsv1.ResourceRef{Group: "apps", Kind: "Deployment", Name: "guestbook", Namespace: "default"},
+	}}}
+	require.NoError(t, appStateCache.SetAppResourcesTree(testApp.Name, tree))
+	appServer.cache = servercache.NewCache(appStateCache, time.Minute, time.Minute, time.Minute)
+
+	ws := &TestResourceTreeDeltaServer{ctx: context.Background()}
+	err := appServer.WatchResourceTreeDelta(&application.ResourcesQuery{ApplicationName: pointer.String(testApp.Name)}, ws)
+	require.NoError(t, err)
+	require.Len(t, ws.events, 1)
+	assert.Equal(t, resourceTreeEventSnapshot, ws.events[0].GetType())
+	assert.Equal(t, tree.Nodes, ws.events[0].GetTree().Nodes)
+}
+
+func TestWatchResourceEvents(t *testing.T) {
+	testApp := newTestApp()
+	appServer := newTestAppServer(t, testApp)
+	newEvent := func(name string) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: testApp.Namespace},
+			InvolvedObject: v1.ObjectReference{Name: testApp.Name, Namespace: testApp.Namespace, UID: testApp.UID},
+		}
+	}
+	_, err := appServer.kubeclientset.CoreV1().Events(testApp.Namespace).Create(context.Background(), newEvent("existing"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	fakeWatcher := watch.NewFake()
+	appServer.kubeclientset.(*fake.Clientset).PrependWatchReactor("events", kubetesting.DefaultWatchReactor(fakeWatcher, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ws := &TestResourceEventsServer{ctx: ctx, events: make(chan *v1.Event, 10)}
+	done := make(chan error)
+	go func() {
+		done <- appServer.WatchResourceEvents(&application.ApplicationResourceEventsQuery{Name: pointer.String(testApp.Name)}, ws)
+	}()
+
+	assert.Equal(t, "existing", (<-ws.events).Name)
+	fakeWatcher.Add(newEvent("created"))
+	assert.Equal(t, "created", (<-ws.events).Name)
+	fakeWatcher.Modify(newEvent("existing"))
+	assert.Equal(t, "existing", (<-ws.events).Name)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWait(t *testing.T) {
+	testApp := newTestApp(func(app *appsv1.Application) {
+		app.Status.Health.Status = health.HealthStatusProgressing
+		app.Status.Sync.Status = appsv1.SyncStatusCodeOutOfSync
+	})
+	appServer := newTestAppServer(t, testApp)
+	// the updates of the application are broadcast to the waiting subscriber
+	appServer.appBroadcaster = &broadcasterHandler{}
+
+	t.Run("AlreadyReached", func(t *testing.T) {
+		ws := &TestWaitServer{ctx: context.Background()}
+		err := appServer.Wait(&application.ApplicationWaitRequest{Name: pointer.String(testApp.Name)}, ws)
+		require.NoError(t, err)
+		require.Len(t, ws.responses, 1)
+		assert.True(t, ws.responses[0].GetDone())
+	})
+	t.Run("Healthy", func(t *testing.T) {
+		ws := &TestWaitServer{ctx: context.Background()}
+		ws.onSend = func(res *application.ApplicationWaitResponse) {
+			if len(ws.responses) == 1 {
+				updated := testApp.DeepCopy()
+				updated.Status.Health.Status = health.HealthStatusHealthy
+				appServer.appBroadcaster.OnUpdate(testApp, updated)
+			}
+		}
+		err := appServer.Wait(&application.ApplicationWaitRequest{Name: pointer.String(testApp.Name), Health: pointer.Bool(true)}, ws)
+		require.NoError(t, err)
+		require.Len(t, ws.responses, 2)
+		assert.False(t, ws.responses[0].GetDone())
+		assert.True(t, ws.responses[1].GetDone())
+		assert.Equal(t, health.HealthStatusHealthy, ws.responses[1].GetApplication().Status.Health.Status)
+	})
+	t.Run("Degraded", func(t *testing.T) {
+		ws := &TestWaitServer{ctx: context.Background()}
+		ws.onSend = func(res *application.ApplicationWaitResponse) {
+			if len(ws.responses) == 1 {
+				updated := testApp.DeepCopy()
+				updated.Status.Health.Status = health.HealthStatusDegraded
+				appServer.appBroadcaster.OnUpdate(testApp, updated)
+			}
+		}
+		err := appServer.Wait(&application.ApplicationWaitRequest{Name: pointer.String(testApp.Name), Health: pointer.Bool(true)}, ws)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		ws := &TestWaitServer{ctx: context.Background()}
+		err := appServer.Wait(&application.ApplicationWaitRequest{Name: pointer.String(testApp.Name), Sync: pointer.Bool(true), TimeoutSeconds: pointer.Int64(1)}, ws)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.Len(t, ws.responses, 1)
+		assert.False(t, ws.responses[0].GetDone())
+	})
+}
+
+func TestResourceTreeEvents(t *testing.T) {
+	deployment := appsv1.ResourceNode{ResourceRef: appsv1.ResourceRef{Group: "apps", Kind: "Deployment", Name: "guestbook", Namespace: "default"}}
+	replicaSet := appsv1.ResourceNode{ResourceRef: appsv1.ResourceRef{Group: "apps", Kind: "ReplicaSet", Name: "guestbook-1", Namespace: "default"}}
+	newReplicaSet := appsv1.ResourceNode{ResourceRef: appsv1.ResourceRef{Group: "apps", Kind: "ReplicaSet", Name: "guestbook-2", Namespace: "default"}}
+	orphan := appsv1.ResourceNode{ResourceRef: appsv1.ResourceRef{Kind: "ConfigMap", Name: "orphan", Namespace: "default"}}
+	healthyDeployment := deployment
+	healthyDeployment.Health = &appsv1.HealthStatus{Status: health.HealthStatusHealthy}
+
+	previous := &appsv1.ApplicationTree{Nodes: []appsv1.ResourceNode{deployment, replicaSet}}
+	current := &appsv1.ApplicationTree{
+		Nodes:         []appsv1.ResourceNode{healthyDeployment, newReplicaSet},
+		OrphanedNodes: []appsv1.ResourceNode{orphan},
+		Hosts:         []appsv1.HostInfo{{Name: "node-1"}},
+	}
+
+	events := resourceTreeEvents(previous, current)
+	require.Len(t, events, 5)
+	assert.Equal(t, string(watch.Modified), events[0].GetType())
+	assert.Equal(t, healthyDeployment, *events[0].GetNode())
+	assert.Equal(t, string(watch.Added), events[1].GetType())
+	assert.Equal(t, newReplicaSet, *events[1].GetNode())
+	assert.Equal(t, string(watch.Deleted), events[2].GetType())
+	assert.Equal(t, replicaSet.ResourceRef, events[2].GetNode().ResourceRef)
+	assert.False(t, events[2].GetOrphaned())
+	assert.Equal(t, string(watch.Added), events[3].GetType())
+	assert.True(t, events[3].GetOrphaned())
+	assert.Equal(t, resourceTreeEventHosts, events[4].GetType())
+	assert.Equal(t, current.Hosts, events[4].GetTree().Hosts)
+
+	assert.Empty(t, resourceTreeEvents(current, current))
+}
+
 func TestRunNewStyleResourceAction(t *testing.T) {
 	cacheClient := cacheutil.NewCache(cacheutil.NewInMemoryCache(1 * time.Hour))
 
@@ -2202,3 +3047,283 @@ func TestRunOldStyleResourceAction(t *testing.T) {
 		assert.NotNil(t, appResponse)
 	})
 }
+
+func TestRunResourceActionWithParams(t *testing.T) {
+	ctx := context.Background()
+	deployment := k8sappsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-deploy", Namespace: testNamespace},
+	}
+	testApp := newTestApp()
+	testApp.Status.Resources = []appsv1.ResourceStatus{{Group: "apps", Kind: "Deployment", Version: "v1", Name: "nginx-deploy", Namespace: testNamespace}}
+	appServer := newTestAppServer(t, testApp, kube.MustToUnstructured(&deployment))
+
+	cm, err := appServer.kubeclientset.CoreV1().ConfigMaps(testNamespace).Get(ctx, common.ArgoCDConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	cm.Data = map[string]string{"resource.customizations.actions.apps_Deployment": `definitions:
+- name: scale
+  params:
+  - name: replicas
+    type: number
+  - name: paused
+    type: boolean
+    default: "false"
+  action.lua: |
+    obj.spec.replicas = actionParams["replicas"]
+    obj.spec.paused = actionParams["paused"]
+    return obj
+`}
+	_, err = appServer.kubeclientset.CoreV1().ConfigMaps(testNamespace).Update(ctx, cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	appServer.settingsMgr = settings.NewSettingsManager(ctx, appServer.kubeclientset, testNamespace)
+
+	resourceRequest := &application.ApplicationResourceRequest{
+		Name:         pointer.String(testApp.Name),
+		Namespace:    pointer.String(testNamespace),
+		ResourceName: pointer.String("nginx-deploy"),
+		Version:      pointer.String("v1"),
+		Group:        pointer.String("apps"),
+		Kind:         pointer.String("Deployment"),
+	}
+	actions, err := appServer.ListResourceActions(ctx, resourceRequest)
+	require.NoError(t, err)
+	require.Len(t, actions.Actions, 1)
+	assert.Equal(t, "scale", actions.Actions[0].Name)
+	assert.Equal(t, []appsv1.ResourceActionParam{{Name: "replicas", Type: "number"}, {Name: "paused", Type: "boolean", Default: "false"}}, actions.Actions[0].Params)
+
+	run := func(params ...*appsv1.ResourceActionParam) error {
+		_, err := appServer.RunResourceAction(ctx, &application.ResourceActionRunRequest{
+			Name:         resourceRequest.Name,
+			Namespace:    resourceRequest.Namespace,
+			ResourceName: resourceRequest.ResourceName,
+			Version:      resourceRequest.Version,
+			Group:        resourceRequest.Group,
+			Kind:         resourceRequest.Kind,
+			Action:       pointer.String("scale"),
+			Params:       params,
+		})
+		return err
+	}
+	require.NoError(t, run(&appsv1.ResourceActionParam{Name: "replicas", Value: "3"}))
+	assert.Equal(t, codes.InvalidArgument, status.Code(run()))
+	assert.Equal(t, codes.InvalidArgument, status.Code(run(&appsv1.ResourceActionParam{Name: "replicas", Value: "three"})))
+	assert.Equal(t, codes.InvalidArgument, status.Code(run(&appsv1.ResourceActionParam{Name: "replicas", Value: "3"}, &appsv1.ResourceActionParam{Name: "unknown", Value: "x"})))
+}
+
+func TestResourceChangesImpersonateServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	proj := &appsv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "impersonated", Namespace: testNamespace},
+		Spec: appsv1.AppProjectSpec{
+			SourceRepos:  []string{"*"},
+			Destinations: []appsv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+			// no service account is configured for the destination namespace of the application
+			DestinationServiceAccounts: []appsv1.ApplicationDestinationServiceAccount{{Server: "*", Namespace: "other", DefaultServiceAccount: "deployer"}},
+		},
+	}
+	deployment := k8sappsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-deploy", Namespace: testNamespace},
+	}
+	testApp := newTestApp(func(app *appsv1.Application) {
+		app.Spec.Project = proj.Name
+		app.Status.Resources = []appsv1.ResourceStatus{{Group: "apps", Kind: "Deployment", Version: "v1", Name: "nginx-deploy", Namespace: testNamespace}}
+	})
+	appServer := newTestAppServer(t, proj, testApp, kube.MustToUnstructured(&deployment))
+
+	_, err := appServer.PatchResource(ctx, &application.ApplicationResourcePatchRequest{
+		Name:         pointer.String(testApp.Name),
+		Namespace:    pointer.String(testNamespace),
+		ResourceName: pointer.String("nginx-deploy"),
+		Version:      pointer.String("v1"),
+		Group:        pointer.String("apps"),
+		Kind:         pointer.String("Deployment"),
+		Patch:        pointer.String(`{"spec":{"replicas":3}}`),
+		PatchType:    pointer.String(string(types.MergePatchType)),
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = appServer.DeleteResource(ctx, &application.ApplicationResourceDeleteRequest{
+		Name:         pointer.String(testApp.Name),
+		Namespace:    pointer.String(testNamespace),
+		ResourceName: pointer.String("nginx-deploy"),
+		Version:      pointer.String("v1"),
+		Group:        pointer.String("apps"),
+		Kind:         pointer.String("Deployment"),
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = appServer.RunResourceAction(ctx, &application.ResourceActionRunRequest{
+		Name:         pointer.String(testApp.Name),
+		Namespace:    pointer.String(testNamespace),
+		ResourceName: pointer.String("nginx-deploy"),
+		Version:      pointer.String("v1"),
+		Group:        pointer.String("apps"),
+		Kind:         pointer.String("Deployment"),
+		Action:       pointer.String("restart"),
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func newLockedProjectAppServer(t *testing.T) (*Server, *appsv1.Application) {
+	lockedProj := &appsv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "locked", Namespace: testNamespace},
+		Spec: appsv1.AppProjectSpec{
+			SourceRepos:      []string{"*"},
+			Destinations:     []appsv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+			DisableLogs:      true,
+			DisableExec:      true,
+			RedactSecretData: true,
+		},
+	}
+	app := newTestApp(func(app *appsv1.Application) {
+		app.Name = "locked-app"
+		app.Spec.Project = "locked"
+	})
+	return newTestAppServer(t, lockedProj, app), app
+}
+
+func TestProjectDisableLogs(t *testing.T) {
+	appServer, app := newLockedProjectAppServer(t)
+	adminCtx := context.WithValue(context.Background(), "claims", &jwt.MapClaims{"groups": []string{"admin"}})
+
+	err := appServer.PodLogs(&application.ApplicationPodLogsQuery{Name: pointer.String(app.Name)}, &TestPodLogsServer{ctx: adminCtx})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.ErrorContains(t, err, "viewing logs is disabled for applications in project locked")
+}
+
+func TestProjectRedactSecretData(t *testing.T) {
+	appServer, app := newLockedProjectAppServer(t)
+	adminCtx := context.WithValue(context.Background(), "claims", &jwt.MapClaims{"groups": []string{"admin"}})
+
+	secretState := `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"},"data":{"password":"++++++++"}}`
+	configMapState := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"my-cm"},"data":{"key":"value"}}`
+	err := appstate.NewCache(appServer.cache.GetCache(), time.Hour).SetAppManagedResources(app.Name, []*appsv1.ResourceDiff{
+		{Kind: kube.SecretKind, Name: "my-secret", TargetState: secretState, LiveState: secretState, Diff: "diff"},
+		{Kind: "ConfigMap", Name: "my-cm", TargetState: configMapState, LiveState: configMapState},
+	})
+	require.NoError(t, err)
+
+	res, err := appServer.ManagedResources(adminCtx, &application.ResourcesQuery{ApplicationName: pointer.String(app.Name)})
+	require.NoError(t, err)
+	// the managed resources are sorted by their names
+	require.Len(t, res.Items, 2)
+	assert.Equal(t, configMapState, res.Items[0].TargetState)
+	assert.Equal(t, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"}}`, res.Items[1].TargetState)
+	assert.Equal(t, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"}}`, res.Items[1].LiveState)
+	assert.Empty(t, res.Items[1].Diff)
+}
+
+func TestRedactSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data":       map[string]interface{}{"password": "++++"},
+		"stringData": map[string]interface{}{"user": "++++"},
+	}}
+	redactSecret(secret)
+	assert.NotContains(t, secret.Object, "data")
+	assert.NotContains(t, secret.Object, "stringData")
+
+	// a custom resource of kind Secret is not redacted
+	custom := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Secret",
+		"data":       map[string]interface{}{"key": "value"},
+	}}
+	redactSecret(custom)
+	assert.Contains(t, custom.Object, "data")
+
+	redactSecret(nil)
+}
+
+func TestRedactSecretDiff(t *testing.T) {
+	t.Run("Secret", func(t *testing.T) {
+		res := &appsv1.ResourceDiff{
+			Kind:        kube.SecretKind,
+			Name:        "my-secret",
+			TargetState: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"},"data":{"password":"++++++++"}}`,
+			LiveState:   `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"},"data":{"password":"+++++++++"},"stringData":{"user":"++++"}}`,
+			Diff:        `[{"op":"replace","path":"/data/password"}]`,
+		}
+		require.NoError(t, redactSecretDiff(res))
+		assert.Equal(t, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"}}`, res.TargetState)
+		assert.Equal(t, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"}}`, res.LiveState)
+		assert.Empty(t, res.NormalizedLiveState)
+		assert.Empty(t, res.Diff)
+	})
+	t.Run("NotASecret", func(t *testing.T) {
+		state := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"my-cm"},"data":{"key":"value"}}`
+		res := &appsv1.ResourceDiff{Kind: "ConfigMap", Name: "my-cm", TargetState: state}
+		require.NoError(t, redactSecretDiff(res))
+		assert.Equal(t, state, res.TargetState)
+	})
+}
+
+func TestHideManifestsData(t *testing.T) {
+	kubeclientset := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "argocd-cm",
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of": "argocd",
+			},
+		},
+		Data: map[string]string{
+			"resource.maskingRules": "- kinds: [ConfigMap]\n  jsonPointers: [/data/password]\n",
+		},
+	})
+	s := &Server{settingsMgr: settings.NewSettingsManager(context.Background(), kubeclientset, testNamespace)}
+	manifests := []string{
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"my-cm"},"data":{"password":"foo","user":"admin"}}`,
+		`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"my-secret"},"data":{"password":"Zm9v"}}`,
+		`{"apiVersion":"v1","kind":"Service","metadata":{"name":"my-svc"}}`,
+	}
+
+	require.NoError(t, s.hideManifestsData(manifests, &appsv1.AppProject{}))
+	assert.Equal(t, `{"apiVersion":"v1","data":{"password":"++++++++","user":"admin"},"kind":"ConfigMap","metadata":{"name":"my-cm"}}`, manifests[0])
+	assert.Equal(t, `{"apiVersion":"v1","data":{"password":"++++++++"},"kind":"Secret","metadata":{"name":"my-secret"}}`, manifests[1])
+	assert.Equal(t, `{"apiVersion":"v1","kind":"Service","metadata":{"name":"my-svc"}}`, manifests[2])
+}
+
+func TestNewApplicationApplyPatch(t *testing.T) {
+	testApp := newTestApp()
+	testApp.Spec.Project = "default"
+	testApp.Labels = map[string]string{"team": "a"}
+	testApp.Status.Sync.Status = appsv1.SyncStatusCodeSynced
+
+	patch, err := newApplicationApplyPatch(testApp, "argocd")
+	require.NoError(t, err)
+
+	var obj map[string]interface{}
+	require.NoError(t, json.Unmarshal(patch, &obj))
+	assert.Equal(t, "argoproj.io/v1alpha1", obj["apiVersion"])
+	assert.Equal(t, "Application", obj["kind"])
+	assert.NotContains(t, obj, "status")
+	assert.Equal(t, map[string]interface{}{
+		"name":      testApp.Name,
+		"namespace": "argocd",
+		"labels":    map[string]interface{}{"team": "a"},
+	}, obj["metadata"])
+	spec := obj["spec"].(map[string]interface{})
+	assert.Equal(t, testApp.Spec.Project, spec["project"])
+
+	t.Run("UnsetFieldsAreOmitted", func(t *testing.T) {
+		testApp := newTestApp()
+		testApp.Spec.Project = "default"
+		testApp.Spec.Source = &appsv1.ApplicationSource{RepoURL: fakeRepoURL, Path: "some/path"}
+		testApp.Spec.SyncPolicy = &appsv1.SyncPolicy{Automated: &appsv1.SyncPolicyAutomated{Prune: true}}
+
+		patch, err := newApplicationApplyPatch(testApp, "argocd")
+		require.NoError(t, err)
+
+		var obj map[string]interface{}
+		require.NoError(t, json.Unmarshal(patch, &obj))
+		assert.Equal(t, map[string]interface{}{
+			"project":     testApp.Spec.Project,
+			"source":      map[string]interface{}{"repoURL": fakeRepoURL, "path": "some/path"},
+			"destination": map[string]interface{}{"server": testApp.Spec.Destination.Server, "namespace": testApp.Spec.Destination.Namespace},
+			"syncPolicy":  map[string]interface{}{"automated": map[string]interface{}{"prune": true}},
+		}, obj["spec"])
+	})
+}
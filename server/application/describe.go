@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/pointer"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/server/rbacpolicy"
+)
+
+// describeEmptyPathSegment is used in the path of describe requests for the group of core resources and the namespace
+// of cluster scoped resources, since path segments can't be empty
+const describeEmptyPathSegment = "-"
+
+// DescribeResource returns the description of a single application resource, aggregating its conditions, events and
+// owners similar to kubectl describe
+func (s *Server) DescribeResource(ctx context.Context, q *application.ApplicationResourceRequest) (*application.ResourceDescription, error) {
+	if q.GetGroup() == describeEmptyPathSegment {
+		q.Group = pointer.String("")
+	}
+	if q.GetNamespace() == describeEmptyPathSegment {
+		q.Namespace = pointer.String("")
+	}
+	res, config, a, err := s.getAppLiveResource(ctx, rbacpolicy.ActionGet, q)
+	if err != nil {
+		return nil, err
+	}
+	if q.GetVersion() != "" {
+		res.Version = q.GetVersion()
+	}
+	obj, err := s.kubectl.GetResource(ctx, config, res.GroupKindVersion(), res.Name, res.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resource: %w", err)
+	}
+	tree, err := s.getAppResources(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("error getting app resources: %w", err)
+	}
+	dynamicClient, err := s.kubectl.NewDynamicClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %w", err)
+	}
+	events, err := listResourceEvents(ctx, dynamicClient, res)
+	if err != nil {
+		return nil, err
+	}
+	return &application.ResourceDescription{
+		Resource:   res,
+		Conditions: resourceConditions(obj),
+		Events:     events,
+		Owners:     resourceOwners(tree, res),
+	}, nil
+}
+
+// resourceConditions returns the conditions of the status of the given live object. Conditions which don't follow the
+// Kubernetes API conventions are ignored.
+func resourceConditions(obj *unstructured.Unstructured) []*application.ResourceCondition {
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	var conditions []*application.ResourceCondition
+	for _, item := range items {
+		values, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, ok := values["type"].(string)
+		if !ok {
+			continue
+		}
+		condition := &application.ResourceCondition{Type: pointer.String(conditionType)}
+		if conditionStatus, ok := values["status"].(string); ok {
+			condition.Status = pointer.String(conditionStatus)
+		}
+		if reason, ok := values["reason"].(string); ok {
+			condition.Reason = pointer.String(reason)
+		}
+		if message, ok := values["message"].(string); ok {
+			condition.Message = pointer.String(message)
+		}
+		if lastTransitionTime, ok := values["lastTransitionTime"].(string); ok {
+			var t metav1.Time
+			if err := t.UnmarshalQueryParameter(lastTransitionTime); err == nil {
+				condition.LastTransitionTime = &t
+			}
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// resourceOwners returns the owner chain of the given node in the resource tree, starting with its direct owner. If a
+// resource has several owners, the first one is followed.
+func resourceOwners(tree *appv1.ApplicationTree, node *appv1.ResourceNode) []*appv1.ResourceRef {
+	var owners []*appv1.ResourceRef
+	visited := map[appv1.ResourceRef]bool{node.ResourceRef: true}
+	for len(node.ParentRefs) > 0 {
+		parentRef := node.ParentRefs[0]
+		parent := tree.FindNode(parentRef.Group, parentRef.Kind, parentRef.Namespace, parentRef.Name)
+		if parent == nil {
+			owners = append(owners, &parentRef)
+			break
+		}
+		if visited[parent.ResourceRef] {
+			break
+		}
+		visited[parent.ResourceRef] = true
+		ref := parent.ResourceRef
+		owners = append(owners, &ref)
+		node = parent
+	}
+	return owners
+}
+
+// listResourceEvents returns the events involving the given resource, sorted by their last occurrence
+func listResourceEvents(ctx context.Context, client dynamic.Interface, node *appv1.ResourceNode) ([]*v1.Event, error) {
+	fieldSelector := fields.SelectorFromSet(map[string]string{
+		"involvedObject.name":      node.Name,
+		"involvedObject.uid":       node.UID,
+		"involvedObject.namespace": node.Namespace,
+	}).String()
+	list, err := client.Resource(v1.SchemeGroupVersion.WithResource("events")).Namespace(node.Namespace).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing resource events: %w", err)
+	}
+	events := make([]*v1.Event, 0, len(list.Items))
+	for _, item := range list.Items {
+		var event v1.Event
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &event); err != nil {
+			return nil, fmt.Errorf("error converting resource event: %w", err)
+		}
+		events = append(events, &event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventLastOccurrence(events[i]).Before(eventLastOccurrence(events[j]))
+	})
+	return events, nil
+}
+
+// eventLastOccurrence returns the time the given event occurred last
+func eventLastOccurrence(event *v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+func TestResourceConditions(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True", "reason": "MinimumReplicasAvailable", "lastTransitionTime": "2023-06-01T10:00:00Z"},
+				map[string]interface{}{"status": "False"},
+				"invalid",
+			},
+		},
+	}}
+	conditions := resourceConditions(obj)
+	require.Len(t, conditions, 1)
+	assert.Equal(t, "Available", conditions[0].GetType())
+	assert.Equal(t, "True", conditions[0].GetStatus())
+	assert.Equal(t, "MinimumReplicasAvailable", conditions[0].GetReason())
+	require.NotNil(t, conditions[0].LastTransitionTime)
+	assert.Equal(t, time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC), conditions[0].LastTransitionTime.UTC())
+
+	assert.Empty(t, resourceConditions(&unstructured.Unstructured{Object: map[string]interface{}{}}))
+}
+
+func TestResourceOwners(t *testing.T) {
+	deploy := appv1.ResourceRef{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook"}
+	rs := appv1.ResourceRef{Group: "apps", Kind: "ReplicaSet", Namespace: "default", Name: "guestbook-123"}
+	pod := appv1.ResourceRef{Kind: "Pod", Namespace: "default", Name: "guestbook-123-abc"}
+	tree := &appv1.ApplicationTree{Nodes: []appv1.ResourceNode{
+		{ResourceRef: deploy},
+		{ResourceRef: rs, ParentRefs: []appv1.ResourceRef{deploy}},
+		{ResourceRef: pod, ParentRefs: []appv1.ResourceRef{rs}},
+	}}
+
+	assert.Equal(t, []*appv1.ResourceRef{&rs, &deploy}, resourceOwners(tree, &tree.Nodes[2]))
+	assert.Empty(t, resourceOwners(tree, &tree.Nodes[0]))
+
+	t.Run("OwnerNotInTree", func(t *testing.T) {
+		tree := &appv1.ApplicationTree{Nodes: []appv1.ResourceNode{{ResourceRef: pod, ParentRefs: []appv1.ResourceRef{rs}}}}
+		assert.Equal(t, []*appv1.ResourceRef{&rs}, resourceOwners(tree, &tree.Nodes[0]))
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		tree := &appv1.ApplicationTree{Nodes: []appv1.ResourceNode{
+			{ResourceRef: deploy, ParentRefs: []appv1.ResourceRef{rs}},
+			{ResourceRef: rs, ParentRefs: []appv1.ResourceRef{deploy}},
+		}}
+		assert.Equal(t, []*appv1.ResourceRef{&rs}, resourceOwners(tree, &tree.Nodes[0]))
+	})
+}
+
+func newTestEvent(name string, lastTimestamp time.Time) *v1.Event {
+	return &v1.Event{
+		TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "guestbook"},
+		Reason:         name,
+		LastTimestamp:  metav1.NewTime(lastTimestamp),
+	}
+}
+
+func TestListResourceEvents(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(scheme))
+	client := dynamicfake.NewSimpleDynamicClient(scheme,
+		newTestEvent("Started", now),
+		newTestEvent("Pulled", now.Add(-time.Minute)),
+	)
+	events, err := listResourceEvents(context.Background(), client, &appv1.ResourceNode{ResourceRef: appv1.ResourceRef{Kind: "Pod", Namespace: "default", Name: "guestbook"}})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Pulled", events[0].Reason)
+	assert.Equal(t, "Started", events[1].Reason)
+}
@@ -7,17 +7,40 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/argoproj/argo-cd/v2/util/glob"
 )
 
 type logEntry struct {
-	line      string
-	timeStamp time.Time
-	podName   string
-	err       error
+	line          string
+	timeStamp     time.Time
+	podName       string
+	containerName string
+	err           error
+}
+
+// isContainerPattern returns true if the container of a logs query is a glob pattern rather than a container name
+func isContainerPattern(container string) bool {
+	return strings.ContainsAny(container, "*?[")
+}
+
+// matchContainers returns the names of the init containers and containers of the pod which match the glob pattern
+func matchContainers(pod *v1.Pod, pattern string) []string {
+	var names []string
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if glob.Match(pattern, container.Name) {
+				names = append(names, container.Name)
+			}
+		}
+	}
+	return names
 }
 
 // parseLogsStream converts given ReadCloser into channel that emits log entries
-func parseLogsStream(podName string, stream io.ReadCloser, ch chan logEntry) {
+func parseLogsStream(podName string, containerName string, stream io.ReadCloser, ch chan logEntry) {
 	bufReader := bufio.NewReader(stream)
 	eof := false
 	for !eof {
@@ -44,7 +67,7 @@ func parseLogsStream(podName string, stream io.ReadCloser, ch chan logEntry) {
 
 		lines := strings.Join(parts[1:], " ")
 		for _, line := range strings.Split(lines, "\r") {
-			ch <- logEntry{line: line, timeStamp: logTime, podName: podName}
+			ch <- logEntry{line: line, timeStamp: logTime, podName: podName, containerName: containerName}
 		}
 
 	}
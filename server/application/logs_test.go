@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 )
 
 func TestParseLogsStream_Successful(t *testing.T) {
@@ -16,7 +17,7 @@ func TestParseLogsStream_Successful(t *testing.T) {
 
 	res := make(chan logEntry)
 	go func() {
-		parseLogsStream("test", r, res)
+		parseLogsStream("test", "", r, res)
 		close(res)
 	}()
 
@@ -39,7 +40,7 @@ func TestParseLogsStream_ParsingError(t *testing.T) {
 
 	res := make(chan logEntry)
 	go func() {
-		parseLogsStream("test", r, res)
+		parseLogsStream("test", "", r, res)
 		close(res)
 	}()
 
@@ -56,14 +57,14 @@ func TestMergeLogStreams(t *testing.T) {
 
 	first := make(chan logEntry)
 	go func() {
-		parseLogsStream("first", io.NopCloser(strings.NewReader(`2021-02-09T00:00:01Z 1
+		parseLogsStream("first", "", io.NopCloser(strings.NewReader(`2021-02-09T00:00:01Z 1
 2021-02-09T00:00:03Z 3`)), first)
 		close(first)
 	}()
 
 	second := make(chan logEntry)
 	go func() {
-		parseLogsStream("second", io.NopCloser(strings.NewReader(`2021-02-09T00:00:02Z 2
+		parseLogsStream("second", "", io.NopCloser(strings.NewReader(`2021-02-09T00:00:02Z 2
 2021-02-09T00:00:04Z 4`)), second)
 		close(second)
 	}()
@@ -76,3 +77,23 @@ func TestMergeLogStreams(t *testing.T) {
 
 	assert.Equal(t, []string{"1", "2", "3", "4"}, lines)
 }
+
+func TestMatchContainers(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "init"}},
+		Containers:     []v1.Container{{Name: "app"}, {Name: "app-sidecar"}, {Name: "proxy"}},
+	}}
+
+	assert.Equal(t, []string{"init", "app", "app-sidecar", "proxy"}, matchContainers(pod, "*"))
+	assert.Equal(t, []string{"app", "app-sidecar"}, matchContainers(pod, "app*"))
+	assert.Equal(t, []string{"proxy"}, matchContainers(pod, "pro?y"))
+	assert.Empty(t, matchContainers(pod, "db-*"))
+}
+
+func TestIsContainerPattern(t *testing.T) {
+	assert.False(t, isContainerPattern(""))
+	assert.False(t, isContainerPattern("app"))
+	assert.True(t, isContainerPattern("*"))
+	assert.True(t, isContainerPattern("app-?"))
+	assert.True(t, isContainerPattern("[ab]pp"))
+}
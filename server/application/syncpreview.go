@@ -0,0 +1,350 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	kubecache "github.com/argoproj/gitops-engine/pkg/cache"
+	"github.com/argoproj/gitops-engine/pkg/diff"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/argoproj/gitops-engine/pkg/sync/hook"
+	resourceutil "github.com/argoproj/gitops-engine/pkg/sync/resource"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	applisters "github.com/argoproj/argo-cd/v2/pkg/client/listers/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/server/rbacpolicy"
+	"github.com/argoproj/argo-cd/v2/util/argo"
+	"github.com/argoproj/argo-cd/v2/util/security"
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+const (
+	syncPreviewActionCreate       = "create"
+	syncPreviewActionUpdate       = "update"
+	syncPreviewActionNone         = "none"
+	syncPreviewActionPrune        = "prune"
+	syncPreviewActionPruneSkipped = "prune-skipped"
+)
+
+// syncPreviewSettings are the settings of the sync which is previewed
+type syncPreviewSettings struct {
+	// serverSideApply applies the resources using server-side apply instead of client-side apply
+	serverSideApply bool
+	// replace replaces the resources instead of applying them
+	replace bool
+	// force deletes and recreates resources which can't be applied or replaced
+	force bool
+	// fieldManager is the field manager of server-side apply
+	fieldManager string
+}
+
+// SyncPreview returns the changes a sync of an application would make. The target manifests are applied to the
+// destination cluster using a server-side dry-run, which runs the admission and validation of the cluster, and the
+// resources which are managed by the application but not part of the target manifests are reported as pruned. The
+// dry-run uses the sync options of the sync, i.e. server-side apply, replace and force, and impersonates the service
+// account of the destination like the sync.
+func (s *Server) SyncPreview(ctx context.Context, syncReq *application.ApplicationSyncRequest) (*application.ApplicationSyncPreviewResponse, error) {
+	a, err := s.getApplicationEnforceRBACInformer(ctx, rbacpolicy.ActionSync, syncReq.GetAppNamespace(), syncReq.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if !s.isNamespaceEnabled(a.Namespace) {
+		return nil, security.NamespaceNotPermittedError(a.Namespace)
+	}
+	if a.Spec.HasMultipleSources() && syncReq.Manifests == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "sync preview is not supported for applications with multiple sources")
+	}
+	proj, err := argo.GetAppProject(a, applisters.NewAppProjectLister(s.projInformer.GetIndexer()), s.ns, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting app project: %w", err)
+	}
+
+	// the preview is based on the spec as the application controller resolves it
+	a, err = argo.ResolveAppSpecVariables(ctx, a, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := syncReq.Manifests
+	if manifests == nil {
+		revision := a.Spec.GetSource().TargetRevision
+		if syncReq.GetRevision() != "" {
+			revision = syncReq.GetRevision()
+		}
+		manifestInfo, err := s.generateManifests(ctx, a, revision)
+		if err != nil {
+			return nil, err
+		}
+		manifests = manifestInfo.Manifests
+	} else if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionOverride, a.RBACName(s.ns)); err != nil {
+		return nil, err
+	}
+
+	config, err := s.getApplicationClusterConfig(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("error getting application cluster config: %w", err)
+	}
+	apiResources, err := s.kubectl.GetAPIResources(config, false, kubecache.NewNoopSettings())
+	if err != nil {
+		return nil, fmt.Errorf("error getting API resources: %w", err)
+	}
+	// the destination namespace may contain variables, which are resolved like in a sync
+	destinationNamespace, err := argo.GetDestinationNamespace(ctx, &a.Spec, s.db)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving destination namespace: %w", err)
+	}
+	if err := argo.ImpersonateServiceAccount(config, proj, a.Spec.Destination.Server, destinationNamespace); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	dynamicClient, err := s.kubectl.NewDynamicClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %w", err)
+	}
+	maskingRules, err := s.settingsMgr.GetMaskingRules()
+	if err != nil {
+		return nil, fmt.Errorf("error getting masking rules: %w", err)
+	}
+
+	// the sync options of the request take precedence over the ones of the application, like in a sync, but the field
+	// manager is always the one of the application
+	var syncOptions appv1.SyncOptions
+	if syncPolicy := proj.GetSyncPolicy(a); syncPolicy != nil {
+		syncOptions = syncPolicy.SyncOptions
+	}
+	fieldManager := syncOptions.ServerSideApplyManager()
+	if syncReq.SyncOptions != nil {
+		syncOptions = syncReq.SyncOptions.Items
+	}
+	serverSideApplySync, err := s.settingsMgr.IsFeatureEnabled(proj, settings.FeatureFlagServerSideApplySync)
+	if err != nil {
+		return nil, fmt.Errorf("error getting feature flags: %w", err)
+	}
+	previewSettings := syncPreviewSettings{
+		serverSideApply: syncOptions.SyncsWithServerSideApply(serverSideApplySync),
+		replace:         syncOptions.HasOption(synccommon.SyncOptionReplace),
+		force:           syncReq.GetStrategy().Force(),
+		fieldManager:    fieldManager,
+	}
+
+	var resources []appv1.SyncOperationResource
+	for _, r := range syncReq.GetResources() {
+		if r != nil {
+			resources = append(resources, *r)
+		}
+	}
+	isSelected := func(key kube.ResourceKey) bool {
+		return len(resources) == 0 || argo.ContainsSyncResource(key.Name, key.Namespace, schema.GroupVersionKind{Group: key.Group, Kind: key.Kind}, resources)
+	}
+
+	res := &application.ApplicationSyncPreviewResponse{}
+	targetKeys := map[kube.ResourceKey]bool{}
+	for _, manifest := range manifests {
+		target := &unstructured.Unstructured{}
+		if err := json.Unmarshal([]byte(manifest), target); err != nil {
+			return nil, fmt.Errorf("error unmarshaling manifest: %w", err)
+		}
+		// hooks are created by the sync itself and aren't part of the desired state
+		if hook.IsHook(target) {
+			continue
+		}
+		apiResource := findAPIResource(apiResources, target.GroupVersionKind())
+		if apiResource != nil && apiResource.Meta.Namespaced && target.GetNamespace() == "" {
+			target.SetNamespace(destinationNamespace)
+		}
+		key := kube.GetResourceKey(target)
+		targetKeys[key] = true
+		if !isSelected(key) {
+			continue
+		}
+		var preview *application.ResourceSyncPreview
+		if err := s.verifyResourcePermitted(ctx, a, target); err != nil {
+			preview = newResourceSyncPreview(key, "", err.Error())
+		} else {
+			live, err := s.kubectl.GetResource(ctx, config, target.GroupVersionKind(), target.GetName(), target.GetNamespace())
+			if err != nil && !apierr.IsNotFound(err) {
+				preview = newResourceSyncPreview(key, "", fmt.Sprintf("error getting live resource: %v", err))
+			} else {
+				if apierr.IsNotFound(err) {
+					live = nil
+				}
+				preview = previewResourceSync(ctx, dynamicClient, apiResource, target, live, previewSettings, maskingRules, proj.Spec.RedactSecretData)
+			}
+		}
+		res.Items = append(res.Items, preview)
+	}
+
+	managedResources := make([]*appv1.ResourceDiff, 0)
+	err = s.getCachedAppState(ctx, a, func() error {
+		return s.cache.GetAppManagedResources(a.InstanceName(s.ns), &managedResources)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting cached app managed resources: %w", err)
+	}
+	for _, item := range managedResources {
+		key := kube.ResourceKey{Group: item.Group, Kind: item.Kind, Namespace: item.Namespace, Name: item.Name}
+		if item.Hook || targetKeys[key] || !isSelected(key) || item.LiveState == "" || item.LiveState == "null" {
+			continue
+		}
+		action := syncPreviewActionPruneSkipped
+		if syncReq.GetPrune() {
+			action = syncPreviewActionPrune
+		}
+		preview := newResourceSyncPreview(key, action, "")
+		// the cached live state is masked already, but secrets must not be exposed at all if redacted
+		if !proj.Spec.RedactSecretData || item.Kind != kube.SecretKind || item.Group != "" {
+			preview.LiveState = pointer.String(item.LiveState)
+		}
+		res.Items = append(res.Items, preview)
+	}
+	return res, nil
+}
+
+func newResourceSyncPreview(key kube.ResourceKey, action string, message string) *application.ResourceSyncPreview {
+	preview := &application.ResourceSyncPreview{
+		Group:     pointer.String(key.Group),
+		Kind:      pointer.String(key.Kind),
+		Namespace: pointer.String(key.Namespace),
+		Name:      pointer.String(key.Name),
+		Action:    pointer.String(action),
+	}
+	if message != "" {
+		preview.Message = pointer.String(message)
+	}
+	return preview
+}
+
+// previewResourceSync applies the target resource like the sync would using a server-side dry-run and compares the
+// result with the live resource, which is nil if the resource doesn't exist yet
+func previewResourceSync(ctx context.Context, dynamicClient dynamic.Interface, apiResource *kube.APIResourceInfo, target, live *unstructured.Unstructured, previewSettings syncPreviewSettings, maskingRules []settings.MaskingRule, redactSecretData bool) *application.ResourceSyncPreview {
+	key := kube.GetResourceKey(target)
+	action := syncPreviewActionCreate
+	if live != nil {
+		action = syncPreviewActionUpdate
+	}
+	if apiResource == nil {
+		return newResourceSyncPreview(key, action, fmt.Sprintf("the server could not find the requested resource %s", target.GroupVersionKind()))
+	}
+	var client dynamic.ResourceInterface = dynamicClient.Resource(apiResource.GroupVersionResource)
+	if apiResource.Meta.Namespaced {
+		client = dynamicClient.Resource(apiResource.GroupVersionResource).Namespace(target.GetNamespace())
+	}
+	predicted, err := dryRunResourceSync(ctx, client, target, live, previewSettings)
+	if err != nil {
+		if live != nil && previewSettings.force {
+			// a forced sync deletes and recreates resources which can't be updated
+			return newResourceSyncPreview(key, action, fmt.Sprintf("the resource would be deleted and recreated, since the dry-run failed: %v", err))
+		}
+		return newResourceSyncPreview(key, action, fmt.Sprintf("dry-run failed: %v", err))
+	}
+	if live != nil && reflect.DeepEqual(withoutServerFields(live), withoutServerFields(predicted)) {
+		action = syncPreviewActionNone
+	}
+
+	if predicted.GetKind() == kube.SecretKind && predicted.GroupVersionKind().Group == "" {
+		predicted, live, err = diff.HideSecretData(predicted, live)
+		if err != nil {
+			return newResourceSyncPreview(key, action, fmt.Sprintf("error hiding secret data: %v", err))
+		}
+		if redactSecretData {
+			redactSecret(predicted)
+			redactSecret(live)
+		}
+	}
+	predicted, live, err = argo.MaskResourceData(maskingRules, predicted, live)
+	if err != nil {
+		return newResourceSyncPreview(key, action, fmt.Sprintf("error masking resource data: %v", err))
+	}
+
+	preview := newResourceSyncPreview(key, action, "")
+	if predictedState, err := json.Marshal(predicted); err == nil {
+		preview.PredictedState = pointer.String(string(predictedState))
+	}
+	if live != nil {
+		if liveState, err := json.Marshal(live); err == nil {
+			preview.LiveState = pointer.String(string(liveState))
+		}
+	}
+	return preview
+}
+
+// dryRunResourceSync applies the target resource using a server-side dry-run like the sync would: using server-side
+// apply with the field manager of the sync, by replacing the live resource, by creating the resource if it doesn't
+// exist, or by patching the live resource with the target resource, which approximates client-side apply
+func dryRunResourceSync(ctx context.Context, client dynamic.ResourceInterface, target, live *unstructured.Unstructured, previewSettings syncPreviewSettings) (*unstructured.Unstructured, error) {
+	serverSideApply := previewSettings.serverSideApply || resourceutil.HasAnnotationOption(target, synccommon.AnnotationSyncOptions, synccommon.SyncOptionServerSideApply)
+	replace := previewSettings.replace || resourceutil.HasAnnotationOption(target, synccommon.AnnotationSyncOptions, synccommon.SyncOptionReplace)
+	switch {
+	case replace && live != nil:
+		obj := target.DeepCopy()
+		obj.SetResourceVersion(live.GetResourceVersion())
+		return client.Update(ctx, obj, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	case serverSideApply:
+		data, err := json.Marshal(target)
+		if err != nil {
+			return nil, err
+		}
+		// server-side apply syncs always force conflicts
+		return client.Patch(ctx, target.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+			DryRun:       []string{metav1.DryRunAll},
+			FieldManager: previewSettings.fieldManager,
+			Force:        pointer.Bool(true),
+		})
+	case live == nil:
+		return client.Create(ctx, target, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	default:
+		data, err := json.Marshal(target)
+		if err != nil {
+			return nil, err
+		}
+		patchType := types.MergePatchType
+		if scheme.Scheme.Recognizes(target.GroupVersionKind()) {
+			patchType = types.StrategicMergePatchType
+		}
+		return client.Patch(ctx, target.GetName(), patchType, data, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}})
+	}
+}
+
+// withoutServerFields returns a copy of the resource without the fields which the API server changes on every apply
+func withoutServerFields(obj *unstructured.Unstructured) map[string]interface{} {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
+	return obj.Object
+}
+
+func findAPIResource(apiResources []kube.APIResourceInfo, gvk schema.GroupVersionKind) *kube.APIResourceInfo {
+	var match *kube.APIResourceInfo
+	for i := range apiResources {
+		if apiResources[i].GroupKind != gvk.GroupKind() {
+			continue
+		}
+		if apiResources[i].GroupVersionResource.Version == gvk.Version {
+			return &apiResources[i]
+		}
+		if match == nil {
+			match = &apiResources[i]
+		}
+	}
+	if match != nil {
+		// the resource is applied using the version of the manifest
+		resource := *match
+		resource.GroupVersionResource.Version = gvk.Version
+		return &resource
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+)
+
+var configMapResource = kube.APIResourceInfo{
+	GroupKind:            schema.GroupKind{Kind: "ConfigMap"},
+	Meta:                 metav1.APIResource{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+	GroupVersionResource: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+}
+
+func newConfigMap(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "my-config", "namespace": "default"},
+		"data":       data,
+	}}
+}
+
+// newDryRunClient returns a dynamic client which returns the given resource for every dry-run
+func newDryRunClient(result *unstructured.Unstructured) *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("patch", "configmaps", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, result, nil
+	})
+	return client
+}
+
+// serverSideApplySettings are the settings of a sync using server-side apply
+var serverSideApplySettings = syncPreviewSettings{serverSideApply: true, fieldManager: "argocd-controller"}
+
+func TestPreviewResourceSync(t *testing.T) {
+	target := newConfigMap(map[string]interface{}{"foo": "bar"})
+
+	t.Run("Create", func(t *testing.T) {
+		preview := previewResourceSync(context.Background(), newDryRunClient(target), &configMapResource, target, nil, serverSideApplySettings, nil, false)
+		assert.Equal(t, syncPreviewActionCreate, preview.GetAction())
+		assert.Empty(t, preview.GetLiveState())
+		assert.JSONEq(t, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"my-config","namespace":"default"},"data":{"foo":"bar"}}`, preview.GetPredictedState())
+	})
+	t.Run("Update", func(t *testing.T) {
+		live := newConfigMap(map[string]interface{}{"foo": "baz"})
+		preview := previewResourceSync(context.Background(), newDryRunClient(target), &configMapResource, target, live, serverSideApplySettings, nil, false)
+		assert.Equal(t, syncPreviewActionUpdate, preview.GetAction())
+		assert.Contains(t, preview.GetLiveState(), `"foo":"baz"`)
+		assert.Contains(t, preview.GetPredictedState(), `"foo":"bar"`)
+	})
+	t.Run("Unchanged", func(t *testing.T) {
+		live := newConfigMap(map[string]interface{}{"foo": "bar"})
+		live.SetResourceVersion("1")
+		predicted := live.DeepCopy()
+		predicted.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "argocd-controller"}})
+		preview := previewResourceSync(context.Background(), newDryRunClient(predicted), &configMapResource, target, live, serverSideApplySettings, nil, false)
+		assert.Equal(t, syncPreviewActionNone, preview.GetAction())
+	})
+	t.Run("UnknownResource", func(t *testing.T) {
+		preview := previewResourceSync(context.Background(), newDryRunClient(target), nil, target, nil, serverSideApplySettings, nil, false)
+		assert.Equal(t, syncPreviewActionCreate, preview.GetAction())
+		assert.Contains(t, preview.GetMessage(), "could not find the requested resource")
+	})
+}
+
+func TestDryRunResourceSync(t *testing.T) {
+	target := newConfigMap(map[string]interface{}{"foo": "bar"})
+	live := newConfigMap(map[string]interface{}{"foo": "baz"})
+	live.SetResourceVersion("1")
+
+	// dryRun returns the action and the patch type of the dry-run of the sync with the given settings
+	dryRun := func(live *unstructured.Unstructured, previewSettings syncPreviewSettings) (string, types.PatchType) {
+		client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+		var verb string
+		var patchType types.PatchType
+		client.PrependReactor("*", "configmaps", func(action kubetesting.Action) (bool, runtime.Object, error) {
+			verb = action.GetVerb()
+			if patch, ok := action.(kubetesting.PatchAction); ok {
+				patchType = patch.GetPatchType()
+			}
+			return true, target, nil
+		})
+		resource := client.Resource(configMapResource.GroupVersionResource).Namespace("default")
+		_, err := dryRunResourceSync(context.Background(), resource, target, live, previewSettings)
+		require.NoError(t, err)
+		return verb, patchType
+	}
+
+	verb, patchType := dryRun(live, serverSideApplySettings)
+	assert.Equal(t, "patch", verb)
+	assert.Equal(t, types.ApplyPatchType, patchType)
+
+	verb, _ = dryRun(nil, syncPreviewSettings{})
+	assert.Equal(t, "create", verb)
+
+	verb, patchType = dryRun(live, syncPreviewSettings{})
+	assert.Equal(t, "patch", verb)
+	assert.Equal(t, types.StrategicMergePatchType, patchType)
+
+	verb, _ = dryRun(live, syncPreviewSettings{replace: true, serverSideApply: true})
+	assert.Equal(t, "update", verb)
+
+	// the sync options of the resource are applied as well
+	target.SetAnnotations(map[string]string{"argocd.argoproj.io/sync-options": "ServerSideApply=true"})
+	verb, patchType = dryRun(live, syncPreviewSettings{})
+	assert.Equal(t, "patch", verb)
+	assert.Equal(t, types.ApplyPatchType, patchType)
+}
+
+func TestPreviewResourceSync_Force(t *testing.T) {
+	target := newConfigMap(map[string]interface{}{"foo": "bar"})
+	live := newConfigMap(map[string]interface{}{"foo": "baz"})
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("patch", "configmaps", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("field is immutable")
+	})
+
+	preview := previewResourceSync(context.Background(), client, &configMapResource, target, live, syncPreviewSettings{}, nil, false)
+	assert.Equal(t, "dry-run failed: field is immutable", preview.GetMessage())
+
+	preview = previewResourceSync(context.Background(), client, &configMapResource, target, live, syncPreviewSettings{force: true}, nil, false)
+	assert.Equal(t, syncPreviewActionUpdate, preview.GetAction())
+	assert.Contains(t, preview.GetMessage(), "the resource would be deleted and recreated")
+}
+
+func TestFindAPIResource(t *testing.T) {
+	resources := []kube.APIResourceInfo{configMapResource}
+	assert.Equal(t, &configMapResource, findAPIResource(resources, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+	assert.Nil(t, findAPIResource(resources, schema.GroupVersionKind{Version: "v1", Kind: "Secret"}))
+	// the version of the manifest is used
+	resource := findAPIResource(resources, schema.GroupVersionKind{Version: "v2", Kind: "ConfigMap"})
+	require.NotNil(t, resource)
+	assert.Equal(t, "v2", resource.GroupVersionResource.Version)
+}
+
+func TestSyncPreview_RBAC(t *testing.T) {
+	ctx := context.Background()
+	// nolint:staticcheck
+	ctx = context.WithValue(ctx, "claims", &jwt.StandardClaims{Subject: "admin"})
+	appServer := newTestAppServer(t, newTestApp())
+	appServer.enf.SetDefaultRole("")
+	_ = appServer.enf.SetBuiltinPolicy(`p, admin, applications, get, default/test-app, allow`)
+
+	_, err := appServer.SyncPreview(ctx, &application.ApplicationSyncRequest{Name: pointer.String("test-app")})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
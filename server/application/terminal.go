@@ -29,6 +29,8 @@ import (
 
 type terminalHandler struct {
 	appLister         applisters.ApplicationLister
+	projLister        applisters.AppProjectLister
+	settingsMgr       *settings.SettingsManager
 	db                db.ArgoDB
 	enf               *rbac.Enforcer
 	cache             *servercache.Cache
@@ -39,10 +41,12 @@ type terminalHandler struct {
 }
 
 // NewHandler returns a new terminal handler.
-func NewHandler(appLister applisters.ApplicationLister, namespace string, enabledNamespaces []string, db db.ArgoDB, enf *rbac.Enforcer, cache *servercache.Cache,
+func NewHandler(appLister applisters.ApplicationLister, projLister applisters.AppProjectLister, settingsMgr *settings.SettingsManager, namespace string, enabledNamespaces []string, db db.ArgoDB, enf *rbac.Enforcer, cache *servercache.Cache,
 	appResourceTree AppResourceTreeFn, allowedShells []string) *terminalHandler {
 	return &terminalHandler{
 		appLister:         appLister,
+		projLister:        projLister,
+		settingsMgr:       settingsMgr,
 		db:                db,
 		enf:               enf,
 		cache:             cache,
@@ -61,7 +65,7 @@ func (s *terminalHandler) getApplicationClusterRawConfig(ctx context.Context, a
 	if err != nil {
 		return nil, err
 	}
-	return clst.RawRestConfig(), nil
+	return clst.RawRestConfig()
 }
 
 type GetSettingsFunc func() (*settings.ArgoCDSettings, error)
@@ -170,6 +174,18 @@ func (s *terminalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the project is merged with the global projects, like for all other requests of the application
+	proj, err := argo.GetAppProject(a, s.projLister, s.namespace, s.settingsMgr, s.db, ctx)
+	if err != nil {
+		fieldLog.Errorf("Error when getting project %q when launching a terminal: %s", project, err)
+		http.Error(w, "Cannot get project", http.StatusInternalServerError)
+		return
+	}
+	if proj.Spec.DisableExec {
+		http.Error(w, "Exec is disabled for applications in this project", http.StatusForbidden)
+		return
+	}
+
 	config, err := s.getApplicationClusterRawConfig(ctx, a)
 	if err != nil {
 		http.Error(w, "Cannot get raw cluster config", http.StatusBadRequest)
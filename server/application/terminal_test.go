@@ -1,15 +1,18 @@
 package application
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	applisters "github.com/argoproj/argo-cd/v2/pkg/client/listers/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v2/util/argo"
 	"github.com/argoproj/argo-cd/v2/util/security"
 )
@@ -233,3 +236,15 @@ func TestTerminalHandler_ServeHTTP_disallowed_namespace(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, response.StatusCode)
 	assert.Equal(t, security.NamespaceNotPermittedError("disallowed").Error()+"\n", recorder.Body.String())
 }
+
+func TestTerminalHandler_ServeHTTP_exec_disabled(t *testing.T) {
+	appServer, app := newLockedProjectAppServer(t)
+	handler := NewHandler(appServer.appLister, applisters.NewAppProjectLister(appServer.projInformer.GetIndexer()), appServer.settingsMgr,
+		testNamespace, nil, appServer.db, appServer.enf, appServer.cache, appServer.getAppResources, nil)
+	request := httptest.NewRequest(http.MethodGet, "https://argocd.example.com/api/v1/terminal?pod=valid&container=valid&appName="+app.Name+"&projectName=locked&namespace=test&appNamespace="+testNamespace, nil)
+	request = request.WithContext(context.WithValue(request.Context(), "claims", &jwt.MapClaims{"groups": []string{"admin"}}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusForbidden, recorder.Result().StatusCode)
+	assert.Equal(t, "Exec is disabled for applications in this project\n", recorder.Body.String())
+}
@@ -15,19 +15,21 @@ import (
 	"github.com/argoproj/argo-cd/v2/pkg/client/clientset/versioned"
 	"github.com/argoproj/argo-cd/v2/util/argo"
 	"github.com/argoproj/argo-cd/v2/util/assets"
+	"github.com/argoproj/argo-cd/v2/util/security"
 	"github.com/argoproj/argo-cd/v2/util/settings"
 )
 
 //NewHandler creates handler serving to do api/badge endpoint
-func NewHandler(appClientset versioned.Interface, settingsMrg *settings.SettingsManager, namespace string) http.Handler {
-	return &Handler{appClientset: appClientset, namespace: namespace, settingsMgr: settingsMrg}
+func NewHandler(appClientset versioned.Interface, settingsMrg *settings.SettingsManager, namespace string, enabledNamespaces []string) http.Handler {
+	return &Handler{appClientset: appClientset, namespace: namespace, settingsMgr: settingsMrg, enabledNamespaces: enabledNamespaces}
 }
 
 //Handler used to get application in order to access health/sync
 type Handler struct {
-	namespace    string
-	appClientset versioned.Interface
-	settingsMgr  *settings.SettingsManager
+	namespace         string
+	appClientset      versioned.Interface
+	settingsMgr       *settings.SettingsManager
+	enabledNamespaces []string
 }
 
 var (
@@ -76,8 +78,17 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//Sample url: http://localhost:8080/api/badge?name=123
+	//Applications outside of the Argo CD namespace are selected by their namespace,
+	//e.g. http://localhost:8080/api/badge?name=123&namespace=team-a
+	appNamespace := h.namespace
+	if namespace, ok := r.URL.Query()["namespace"]; ok && namespace[0] != "" {
+		appNamespace = namespace[0]
+	}
 	if name, ok := r.URL.Query()["name"]; ok && enabled {
-		if app, err := h.appClientset.ArgoprojV1alpha1().Applications(h.namespace).Get(context.Background(), name[0], v1.GetOptions{}); err == nil {
+		if !security.IsNamespaceEnabled(appNamespace, h.namespace, h.enabledNamespaces) {
+			// applications in namespaces which aren't enabled are reported as missing
+			notFound = true
+		} else if app, err := h.appClientset.ArgoprojV1alpha1().Applications(appNamespace).Get(context.Background(), name[0], v1.GetOptions{}); err == nil {
 			health = app.Status.Health.Status
 			status = app.Status.Sync.Status
 			if app.Status.OperationState != nil && app.Status.OperationState.SyncResult != nil {
@@ -80,6 +80,20 @@ func (c *Cache) GetRepoConnectionState(repo string) (appv1.ConnectionState, erro
 	return res, err
 }
 
+func (c *Cache) SetRepoCredentialState(repo string, state *appv1.ConnectionState, expiration time.Duration) error {
+	return c.cache.SetItem(repoCredentialStateKey(repo), &state, expiration, state == nil)
+}
+
+func repoCredentialStateKey(repo string) string {
+	return fmt.Sprintf("repo|%s|credential-state", repo)
+}
+
+func (c *Cache) GetRepoCredentialState(repo string) (appv1.ConnectionState, error) {
+	res := appv1.ConnectionState{}
+	err := c.cache.GetItem(repoCredentialStateKey(repo), &res)
+	return res, err
+}
+
 func (c *Cache) GetClusterInfo(server string, res *appv1.ClusterInfo) error {
 	return c.cache.GetClusterInfo(server, res)
 }
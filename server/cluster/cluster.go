@@ -2,7 +2,11 @@ package cluster
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
@@ -10,8 +14,10 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
 
 	"github.com/argoproj/argo-cd/v2/common"
 	"github.com/argoproj/argo-cd/v2/pkg/apiclient/cluster"
@@ -51,6 +57,19 @@ func CreateClusterRBACObject(project string, server string) string {
 
 // List returns list of clusters
 func (s *Server) List(ctx context.Context, q *cluster.ClusterQuery) (*appv1.ClusterList, error) {
+	selector, err := labels.Parse(q.GetSelector())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "error parsing the selector: %v", err)
+	}
+	if q.GetLimit() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not be negative")
+	}
+	continueFrom := ""
+	if q.GetContinue() != "" {
+		if continueFrom, err = decodeClusterListContinue(q.GetContinue()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid continue token: %v", err)
+		}
+	}
 	clusterList, err := s.db.ListClusters(ctx)
 	if err != nil {
 		return nil, err
@@ -71,10 +90,31 @@ func (s *Server) List(ctx context.Context, q *cluster.ClusterQuery) (*appv1.Clus
 
 	items := make([]appv1.Cluster, 0)
 	for _, clust := range filteredItems {
+		if !selector.Matches(labels.Set(clust.Labels)) {
+			continue
+		}
+		if continueFrom != "" && clust.Server <= continueFrom {
+			continue
+		}
 		if s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceClusters, rbacpolicy.ActionGet, CreateClusterRBACObject(clust.Project, clust.Server)) {
 			items = append(items, clust)
 		}
 	}
+	cl := *clusterList
+	if q.GetLimit() > 0 || continueFrom != "" {
+		// Sort the clusters by server URL, which is unique, so that pages of a limited list are consistent
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].Server < items[j].Server
+		})
+		if limit := q.GetLimit(); limit > 0 && int64(len(items)) > limit {
+			cl.Continue = encodeClusterListContinue(items[limit-1].Server)
+			cl.RemainingItemCount = pointer.Int64(int64(len(items)) - limit)
+			items = items[:limit]
+		}
+	}
+
+	// the cluster info, including the connection state, is served from the cache populated by the application
+	// controller, so only the clusters of the requested page are looked up
 	err = kube.RunAllAsync(len(items), func(i int) error {
 		items[i] = *s.toAPIResponse(&items[i])
 		return nil
@@ -83,12 +123,38 @@ func (s *Server) List(ctx context.Context, q *cluster.ClusterQuery) (*appv1.Clus
 		return nil, err
 	}
 
-	cl := *clusterList
 	cl.Items = items
 
 	return &cl, nil
 }
 
+// clusterListContinue is the decoded continue token of a limited cluster list
+type clusterListContinue struct {
+	// Server is the server URL of the last cluster of the previous page
+	Server string `json:"server"`
+}
+
+func decodeClusterListContinue(token string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+	var c clusterListContinue
+	if err := json.Unmarshal(data, &c); err != nil {
+		return "", err
+	}
+	if c.Server == "" {
+		return "", fmt.Errorf("server must not be empty")
+	}
+	return c.Server, nil
+}
+
+func encodeClusterListContinue(server string) string {
+	// marshalling a struct of strings can't fail
+	data, _ := json.Marshal(&clusterListContinue{Server: server})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
 func filterClustersById(clusters []appv1.Cluster, id *cluster.ClusterID) ([]appv1.Cluster, error) {
 	if id == nil {
 		return clusters, nil
@@ -146,7 +212,11 @@ func (s *Server) Create(ctx context.Context, q *cluster.ClusterCreateRequest) (*
 		return nil, err
 	}
 	c := q.Cluster
-	serverVersion, err := s.kubectl.GetServerVersion(c.RESTConfig())
+	clusterRESTConfig, err := c.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	serverVersion, err := s.kubectl.GetServerVersion(clusterRESTConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -274,6 +344,9 @@ var clusterFieldsByPath = map[string]func(updated *appv1.Cluster, existing *appv
 	"project": func(updated *appv1.Cluster, existing *appv1.Cluster) {
 		updated.Project = existing.Project
 	},
+	"repoServer": func(updated *appv1.Cluster, existing *appv1.Cluster) {
+		updated.RepoServer = existing.RepoServer
+	},
 }
 
 // Update updates a cluster
@@ -309,7 +382,11 @@ func (s *Server) Update(ctx context.Context, q *cluster.ClusterUpdateRequest) (*
 	}
 
 	// Test the token we just created before persisting it
-	serverVersion, err := s.kubectl.GetServerVersion(q.Cluster.RESTConfig())
+	clusterRESTConfig, err := q.Cluster.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	serverVersion, err := s.kubectl.GetServerVersion(clusterRESTConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -395,7 +472,10 @@ func (s *Server) RotateAuth(ctx context.Context, q *cluster.ClusterQuery) (*clus
 	for _, server := range servers {
 		logCtx := log.WithField("cluster", server)
 		logCtx.Info("Rotating auth")
-		restCfg := clust.RESTConfig()
+		restCfg, err := clust.RESTConfig()
+		if err != nil {
+			return nil, err
+		}
 		if restCfg.BearerToken == "" {
 			return nil, status.Errorf(codes.InvalidArgument, "Cluster '%s' does not use bearer token authentication", server)
 		}
@@ -412,19 +492,30 @@ func (s *Server) RotateAuth(ctx context.Context, q *cluster.ClusterQuery) (*clus
 		if err != nil {
 			return nil, err
 		}
+		// the new token must not outlive a failed rotation, the cluster keeps using the old token in this case
+		discardNewSecret := func(cause error) error {
+			if err := clusterauth.DiscardClusterManagerSecret(kubeclientset, claims, newSecret); err != nil {
+				logCtx.Warnf("Failed to delete secret %s of failed auth rotation: %v", newSecret.Name, err)
+			}
+			return cause
+		}
 		// we are using token auth, make sure we don't store client-cert information
 		clust.Config.KeyData = nil
 		clust.Config.CertData = nil
 		clust.Config.BearerToken = string(newSecret.Data["token"])
 
 		// Test the token we just created before persisting it
-		serverVersion, err := s.kubectl.GetServerVersion(clust.RESTConfig())
+		clusterRESTConfig, err := clust.RESTConfig()
 		if err != nil {
-			return nil, err
+			return nil, discardNewSecret(err)
+		}
+		serverVersion, err := s.kubectl.GetServerVersion(clusterRESTConfig)
+		if err != nil {
+			return nil, discardNewSecret(err)
 		}
 		_, err = s.db.UpdateCluster(ctx, clust)
 		if err != nil {
-			return nil, err
+			return nil, discardNewSecret(err)
 		}
 		err = s.cache.SetClusterInfo(clust.Server, &appv1.ClusterInfo{
 			ServerVersion: serverVersion,
@@ -459,6 +550,16 @@ func (s *Server) toAPIResponse(clust *appv1.Cluster) *appv1.Cluster {
 		clust.Config.ExecProviderConfig.Env = make(map[string]string)
 		clust.Config.ExecProviderConfig.Args = nil
 	}
+	if clust.Config.ProxyUrl != "" {
+		// the proxy URL may contain credentials, e.g. the token authenticating to the agent proxy
+		proxyURL, err := url.Parse(clust.Config.ProxyUrl)
+		if err != nil {
+			clust.Config.ProxyUrl = ""
+		} else if proxyURL.User != nil {
+			proxyURL.User = nil
+			clust.Config.ProxyUrl = proxyURL.String()
+		}
+	}
 	// populate deprecated fields for backward compatibility
 	clust.ServerVersion = clust.Info.ServerVersion
 	clust.ConnectionState = clust.Info.ConnectionState
@@ -471,7 +572,7 @@ func (s *Server) InvalidateCache(ctx context.Context, q *cluster.ClusterQuery) (
 	if err != nil {
 		return nil, err
 	}
-	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceClusters, rbacpolicy.ActionUpdate, CreateClusterRBACObject(cls.Project, q.Server)); err != nil {
+	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceClusters, rbacpolicy.ActionUpdate, CreateClusterRBACObject(cls.Project, cls.Server)); err != nil {
 		return nil, err
 	}
 	now := v1.Now()
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/argoproj/argo-cd/v2/util/clusterauth"
 	"github.com/argoproj/argo-cd/v2/util/profile"
 )
 
@@ -16,8 +19,17 @@ type MetricsServer struct {
 	*http.Server
 	redisRequestCounter   *prometheus.CounterVec
 	redisRequestHistogram *prometheus.HistogramVec
+	repoServerLoadGauge   *prometheus.GaugeVec
+	repoCredentialGauge   *prometheus.GaugeVec
+	repoCredentialExpiry  *prometheus.GaugeVec
+	// repoServerReplicas holds the time of the last load report of each repo-server replica
+	repoServerReplicas     map[string]time.Time
+	repoServerReplicasLock sync.Mutex
 }
 
+// repoServerLoadExpiry is the time after which the load of a repo-server replica which stopped reporting is removed
+const repoServerLoadExpiry = 10 * time.Minute
+
 var (
 	redisRequestCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -34,6 +46,27 @@ var (
 		},
 		[]string{"initiator"},
 	)
+	repoServerLoadGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_repo_server_load",
+			Help: "Load of the repo-server replicas as reported by their load reports.",
+		},
+		[]string{"replica", "metric"},
+	)
+	repoCredentialGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_repo_credential_valid",
+			Help: "Whether the credentials of the repository were valid when they were last revalidated.",
+		},
+		[]string{"repo"},
+	)
+	repoCredentialExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_repo_credential_expiry_timestamp_seconds",
+			Help: "Expiry time of the credentials of the repository, for credentials which expose it.",
+		},
+		[]string{"repo"},
+	)
 )
 
 // NewMetricsServer returns a new prometheus server which collects api server metrics
@@ -48,6 +81,10 @@ func NewMetricsServer(host string, port int) *MetricsServer {
 
 	registry.MustRegister(redisRequestCounter)
 	registry.MustRegister(redisRequestHistogram)
+	registry.MustRegister(repoServerLoadGauge)
+	registry.MustRegister(repoCredentialGauge)
+	registry.MustRegister(repoCredentialExpiry)
+	registry.MustRegister(clusterauth.ExecCredentialCollectors()...)
 
 	return &MetricsServer{
 		Server: &http.Server{
@@ -56,6 +93,10 @@ func NewMetricsServer(host string, port int) *MetricsServer {
 		},
 		redisRequestCounter:   redisRequestCounter,
 		redisRequestHistogram: redisRequestHistogram,
+		repoServerLoadGauge:   repoServerLoadGauge,
+		repoCredentialGauge:   repoCredentialGauge,
+		repoCredentialExpiry:  repoCredentialExpiry,
+		repoServerReplicas:    map[string]time.Time{},
 	}
 }
 
@@ -67,3 +108,36 @@ func (m *MetricsServer) IncRedisRequest(failed bool) {
 func (m *MetricsServer) ObserveRedisRequestDuration(duration time.Duration) {
 	m.redisRequestHistogram.WithLabelValues("argocd-server").Observe(duration.Seconds())
 }
+
+// ObserveRepoServerLoad records the load report of a repo-server replica, and removes the load of the replicas which
+// didn't report their load within the expiry, e.g. because they were scaled down
+func (m *MetricsServer) ObserveRepoServerLoad(report *apiclient.LoadReport, now time.Time) {
+	m.repoServerReplicasLock.Lock()
+	defer m.repoServerReplicasLock.Unlock()
+	replica := report.Replica
+	m.repoServerLoadGauge.WithLabelValues(replica, "active_generations").Set(float64(report.ActiveGenerations))
+	m.repoServerLoadGauge.WithLabelValues(replica, "queue_depth").Set(float64(report.QueueDepth))
+	m.repoServerLoadGauge.WithLabelValues(replica, "parallelism_limit").Set(float64(report.ParallelismLimit))
+	m.repoServerLoadGauge.WithLabelValues(replica, "disk_usage_bytes").Set(float64(report.DiskUsageBytes))
+	m.repoServerReplicas[replica] = now
+	for r, reportedAt := range m.repoServerReplicas {
+		if now.Sub(reportedAt) > repoServerLoadExpiry {
+			m.repoServerLoadGauge.DeletePartialMatch(prometheus.Labels{"replica": r})
+			delete(m.repoServerReplicas, r)
+		}
+	}
+}
+
+// ObserveRepoCredentialState records the result of the revalidation of the credentials of a repository
+func (m *MetricsServer) ObserveRepoCredentialState(repo string, valid bool, expiry *time.Time) {
+	if valid {
+		m.repoCredentialGauge.WithLabelValues(repo).Set(1)
+	} else {
+		m.repoCredentialGauge.WithLabelValues(repo).Set(0)
+	}
+	if expiry != nil {
+		m.repoCredentialExpiry.WithLabelValues(repo).Set(float64(expiry.Unix()))
+	} else {
+		m.repoCredentialExpiry.DeleteLabelValues(repo)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	"github.com/argoproj/pkg/sync"
+	"github.com/gobwas/glob"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -17,12 +20,14 @@ import (
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	"github.com/argoproj/argo-cd/v2/pkg/apiclient/project"
+	appspkg "github.com/argoproj/argo-cd/v2/pkg/apis/application"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	appclientset "github.com/argoproj/argo-cd/v2/pkg/client/clientset/versioned"
 	listersv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/client/listers/application/v1alpha1"
@@ -32,6 +37,7 @@ import (
 	"github.com/argoproj/argo-cd/v2/util/db"
 	jwtutil "github.com/argoproj/argo-cd/v2/util/jwt"
 	"github.com/argoproj/argo-cd/v2/util/rbac"
+	"github.com/argoproj/argo-cd/v2/util/security"
 	"github.com/argoproj/argo-cd/v2/util/session"
 	"github.com/argoproj/argo-cd/v2/util/settings"
 )
@@ -39,6 +45,18 @@ import (
 const (
 	// JWTTokenSubFormat format of the JWT token subject that Argo CD vends out.
 	JWTTokenSubFormat = "proj:%s:%s"
+
+	// defaultActivityLimit is the number of events returned by ListActivity if no limit is requested
+	defaultActivityLimit = 100
+
+	// SignatureVerificationNotRequired is reported for apps whose project does not require signed revisions
+	SignatureVerificationNotRequired = "NotRequired"
+	// SignatureVerificationVerified is reported for apps whose revisions were successfully verified
+	SignatureVerificationVerified = string(v1alpha1.SignatureVerificationVerified)
+	// SignatureVerificationFailed is reported for apps whose revisions failed the signature verification
+	SignatureVerificationFailed = string(v1alpha1.SignatureVerificationFailed)
+	// SignatureVerificationUnknown is reported for apps which were not compared yet
+	SignatureVerificationUnknown = "Unknown"
 )
 
 // Server provides a Project service
@@ -54,14 +72,16 @@ type Server struct {
 	projInformer  cache.SharedIndexInformer
 	settingsMgr   *settings.SettingsManager
 	db            db.ArgoDB
+	// enabledNamespaces are the namespaces in which applications may be created besides the namespace of Argo CD
+	enabledNamespaces []string
 }
 
 // NewServer returns a new instance of the Project service
 func NewServer(ns string, kubeclientset kubernetes.Interface, appclientset appclientset.Interface, enf *rbac.Enforcer, projectLock sync.KeyLock, sessionMgr *session.SessionManager, policyEnf *rbacpolicy.RBACPolicyEnforcer,
-	projInformer cache.SharedIndexInformer, settingsMgr *settings.SettingsManager, db db.ArgoDB) *Server {
+	projInformer cache.SharedIndexInformer, settingsMgr *settings.SettingsManager, db db.ArgoDB, enabledNamespaces []string) *Server {
 	auditLogger := argo.NewAuditLogger(ns, kubeclientset, "argocd-server")
 	return &Server{enf: enf, policyEnf: policyEnf, appclientset: appclientset, kubeclientset: kubeclientset, ns: ns, projectLock: projectLock, auditLogger: auditLogger, sessionMgr: sessionMgr,
-		projInformer: projInformer, settingsMgr: settingsMgr, db: db}
+		projInformer: projInformer, settingsMgr: settingsMgr, db: db, enabledNamespaces: enabledNamespaces}
 }
 
 func validateProject(proj *v1alpha1.AppProject) error {
@@ -117,8 +137,16 @@ func (s *Server) createToken(ctx context.Context, q *project.ProjectTokenCreateR
 		uniqueId, _ := uuid.NewRandom()
 		id = uniqueId.String()
 	}
+	expiresIn := q.ExpiresIn
+	if expiresIn == 0 {
+		defaultExpiration, err := role.GetDefaultTokenExpiration()
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid default token expiration of role '%s': %v", q.Role, err)
+		}
+		expiresIn = int64(defaultExpiration.Seconds())
+	}
 	subject := fmt.Sprintf(JWTTokenSubFormat, q.Project, q.Role)
-	jwtToken, err := s.sessionMgr.Create(subject, q.ExpiresIn, id)
+	jwtToken, err := s.sessionMgr.Create(subject, expiresIn, id)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
@@ -157,6 +185,23 @@ func (s *Server) createToken(ctx context.Context, q *project.ProjectTokenCreateR
 
 }
 
+// ListTokens returns the tokens issued for a project role
+func (s *Server) ListTokens(ctx context.Context, q *project.ProjectTokenListRequest) (*v1alpha1.JWTTokens, error) {
+	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceProjects, rbacpolicy.ActionGet, q.Project); err != nil {
+		return nil, err
+	}
+	prj, err := s.appclientset.ArgoprojV1alpha1().AppProjects(s.ns).Get(ctx, q.Project, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	prj.NormalizeJWTTokens()
+	role, _, err := prj.GetRoleByName(q.Role)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "project '%s' does not have role '%s'", q.Project, q.Role)
+	}
+	return &v1alpha1.JWTTokens{Items: role.JWTTokens}, nil
+}
+
 func (s *Server) ListLinks(ctx context.Context, q *project.ListProjectLinksRequest) (*application.LinksResponse, error) {
 	projName := q.GetName()
 
@@ -307,12 +352,21 @@ func (s *Server) GetDetailedProject(ctx context.Context, q *project.ProjectQuery
 	}
 	proj.NormalizeJWTTokens()
 	globalProjects := argo.GetGlobalProjects(proj, listersv1alpha1.NewAppProjectLister(s.projInformer.GetIndexer()), s.settingsMgr)
+	featureFlags, err := s.settingsMgr.GetProjectFeatureFlags(proj)
+	if err != nil {
+		return nil, fmt.Errorf("error getting feature flags: %w", err)
+	}
+	flags := make([]*v1alpha1.FeatureFlag, 0, len(featureFlags))
+	for i := range featureFlags {
+		flags = append(flags, &featureFlags[i])
+	}
 
 	return &project.DetailedProjectsResponse{
 		GlobalProjects: globalProjects,
 		Project:        proj,
 		Repositories:   repositories,
 		Clusters:       clusters,
+		FeatureFlags:   flags,
 	}, err
 }
 
@@ -447,11 +501,186 @@ func (s *Server) Update(ctx context.Context, q *project.ProjectUpdateRequest) (*
 
 	res, err := s.appclientset.ArgoprojV1alpha1().AppProjects(s.ns).Update(ctx, q.Project, metav1.UpdateOptions{})
 	if err == nil {
-		s.logEvent(res, ctx, argo.EventReasonResourceUpdated, "updated project")
+		s.logEvent(res, ctx, argo.EventReasonResourceUpdated, describeProjectUpdate(oldProj, res))
 	}
 	return res, err
 }
 
+// describeProjectUpdate returns the audit message of an update of a project, which names the settings that changed
+func describeProjectUpdate(oldProj *v1alpha1.AppProject, newProj *v1alpha1.AppProject) string {
+	var changes []string
+	if !reflect.DeepEqual(oldProj.Spec.Roles, newProj.Spec.Roles) {
+		changes = append(changes, "roles")
+	}
+	if !reflect.DeepEqual(oldProj.Spec.SyncWindows, newProj.Spec.SyncWindows) {
+		changes = append(changes, "sync windows")
+	}
+	if !reflect.DeepEqual(oldProj.Spec.SourceRepos, newProj.Spec.SourceRepos) {
+		changes = append(changes, "sources")
+	}
+	if !reflect.DeepEqual(oldProj.Spec.Destinations, newProj.Spec.Destinations) {
+		changes = append(changes, "destinations")
+	}
+	if !reflect.DeepEqual(oldProj.Spec.ClusterResourceWhitelist, newProj.Spec.ClusterResourceWhitelist) ||
+		!reflect.DeepEqual(oldProj.Spec.ClusterResourceBlacklist, newProj.Spec.ClusterResourceBlacklist) ||
+		!reflect.DeepEqual(oldProj.Spec.NamespaceResourceWhitelist, newProj.Spec.NamespaceResourceWhitelist) ||
+		!reflect.DeepEqual(oldProj.Spec.NamespaceResourceBlacklist, newProj.Spec.NamespaceResourceBlacklist) {
+		changes = append(changes, "resource restrictions")
+	}
+	if len(changes) == 0 {
+		return "updated project"
+	}
+	return fmt.Sprintf("updated project %s", strings.Join(changes, ", "))
+}
+
+// ValidateSpec validates a proposed project spec without saving it. It returns the errors which would prevent the spec
+// from being saved, and the applications which conform to the current spec of the project, but would not conform to the
+// proposed one. The applications are checked against both specs merged with the global projects they inherit.
+func (s *Server) ValidateSpec(ctx context.Context, q *project.ProjectUpdateRequest) (*project.ProjectValidationResponse, error) {
+	if q.Project == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "missing payload 'project' in request")
+	}
+	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceProjects, rbacpolicy.ActionUpdate, q.Project.Name); err != nil {
+		return nil, err
+	}
+	proj := q.Project.DeepCopy()
+	proj.NormalizePolicies()
+	proj.NormalizeJWTTokens()
+
+	res := &project.ProjectValidationResponse{Errors: specErrors(proj), Applications: []*project.NonConformantApplication{}}
+
+	oldProj, err := s.appclientset.ArgoprojV1alpha1().AppProjects(s.ns).Get(ctx, proj.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	projLister := listersv1alpha1.NewAppProjectLister(s.projInformer.GetIndexer())
+	oldProj, err = argo.GetAppVirtualProject(oldProj, projLister, s.settingsMgr)
+	if err != nil {
+		return nil, err
+	}
+	proj, err = argo.GetAppVirtualProject(proj, projLister, s.settingsMgr)
+	if err != nil {
+		return nil, err
+	}
+	allApps, err := s.listApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	getProjectClusters := func(project string) ([]*v1alpha1.Cluster, error) {
+		return s.db.GetProjectClusters(ctx, project)
+	}
+
+	apps := argo.FilterByProjects(allApps, []string{proj.Name})
+	for i := range apps {
+		if !s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionGet, apps[i].RBACName(s.ns)) {
+			continue
+		}
+		oldReasons, err := nonConformanceReasons(oldProj, &apps[i], s.ns, getProjectClusters)
+		if err != nil {
+			return nil, err
+		}
+		reasons, err := nonConformanceReasons(proj, &apps[i], s.ns, getProjectClusters)
+		if err != nil {
+			return nil, err
+		}
+		// applications which already don't conform to the current spec are only reported for new reasons
+		existing := make(map[string]bool)
+		for _, reason := range oldReasons {
+			existing[reason] = true
+		}
+		var newReasons []string
+		for _, reason := range reasons {
+			if !existing[reason] {
+				newReasons = append(newReasons, reason)
+			}
+		}
+		if len(newReasons) > 0 {
+			res.Applications = append(res.Applications, &project.NonConformantApplication{Name: apps[i].Name, Namespace: apps[i].Namespace, Reasons: newReasons})
+		}
+	}
+	sort.Slice(res.Applications, func(i, j int) bool {
+		return res.Applications[i].Namespace+"/"+res.Applications[i].Name < res.Applications[j].Namespace+"/"+res.Applications[j].Name
+	})
+	return res, nil
+}
+
+// specErrors returns the errors of a project spec, which would prevent it from being saved. Invalid role policies, sync
+// windows and destination patterns are all reported, not only the first one.
+func specErrors(proj *v1alpha1.AppProject) []string {
+	var errs []string
+	seen := make(map[string]bool)
+	addError := func(msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			errs = append(errs, msg)
+		}
+	}
+	if err := proj.ValidateProject(); err != nil {
+		addError(status.Convert(err).Message())
+	}
+	for _, err := range proj.ValidateRolePolicies() {
+		addError(status.Convert(err).Message())
+	}
+	if err := rbac.ValidatePolicy(proj.ProjectPoliciesString()); err != nil {
+		addError(fmt.Sprintf("policy syntax error: %s", err.Error()))
+	}
+	for _, window := range proj.Spec.SyncWindows {
+		if window == nil {
+			continue
+		}
+		if err := window.Validate(); err != nil {
+			addError(err.Error())
+		}
+	}
+	for _, dest := range proj.Spec.Destinations {
+		for _, pattern := range []string{dest.Server, dest.Name, dest.Namespace} {
+			if _, err := glob.Compile(strings.TrimPrefix(pattern, "!")); err != nil {
+				addError(fmt.Sprintf("destination pattern '%s' is invalid: %v", pattern, err))
+			}
+		}
+	}
+	return errs
+}
+
+// nonConformanceReasons returns the reasons why an application doesn't conform to the spec of a project. The resources
+// of the application are taken from its status.
+func nonConformanceReasons(proj *v1alpha1.AppProject, app *v1alpha1.Application, controllerNs string, getProjectClusters func(project string) ([]*v1alpha1.Cluster, error)) ([]string, error) {
+	var reasons []string
+	if !proj.IsAppNamespacePermitted(app, controllerNs) {
+		reasons = append(reasons, fmt.Sprintf("application namespace '%s' is not permitted", app.Namespace))
+	}
+	for _, source := range app.Spec.GetSources() {
+		if !proj.IsSourcePermitted(source) {
+			reasons = append(reasons, fmt.Sprintf("source repository '%s' is not permitted", source.RepoURL))
+		}
+	}
+	dstPermitted, err := proj.IsDestinationPermitted(app.Spec.Destination, getProjectClusters)
+	if err != nil {
+		return nil, err
+	}
+	if !dstPermitted {
+		reasons = append(reasons, fmt.Sprintf("destination server '%s' and namespace '%s' are not permitted", app.Spec.Destination.Server, app.Spec.Destination.Namespace))
+	}
+	deniedKinds := make(map[schema.GroupKind]bool)
+	for _, res := range app.Status.Resources {
+		gk := schema.GroupKind{Group: res.Group, Kind: res.Kind}
+		if deniedKinds[gk] {
+			continue
+		}
+		if err := proj.ValidateGroupKind(gk, res.Namespace != ""); err != nil {
+			deniedKinds[gk] = true
+			reasons = append(reasons, err.Error())
+		}
+	}
+	if err := proj.ValidateResourcesQuota(len(app.Status.Resources)); err != nil {
+		reasons = append(reasons, err.Error())
+	}
+	return reasons, nil
+}
+
 // Delete deletes a project
 func (s *Server) Delete(ctx context.Context, q *project.ProjectQuery) (*project.EmptyResponse, error) {
 	if q.Name == v1alpha1.DefaultAppProjectName {
@@ -500,6 +729,130 @@ func (s *Server) ListEvents(ctx context.Context, q *project.ProjectQuery) (*v1.E
 	return s.kubeclientset.CoreV1().Events(s.ns).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
 }
 
+// ListActivity returns the recent audit events of the project and of its applications, most recent first, so that
+// owners of a project can review its activity without access to the cluster. Events of applications are only returned
+// if the user may get the application.
+func (s *Server) ListActivity(ctx context.Context, q *project.ProjectActivityRequest) (*v1.EventList, error) {
+	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceProjects, rbacpolicy.ActionGet, q.Name); err != nil {
+		return nil, err
+	}
+	proj, err := s.appclientset.ArgoprojV1alpha1().AppProjects(s.ns).Get(ctx, q.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	apps, err := s.listApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projApps := make(map[string]bool)
+	for _, a := range argo.FilterByProjects(apps, []string{proj.Name}) {
+		projApps[a.Namespace+"/"+a.Name] = true
+	}
+
+	projEvents, err := s.kubeclientset.CoreV1().Events(s.ns).List(ctx, metav1.ListOptions{FieldSelector: fields.SelectorFromSet(map[string]string{
+		"involvedObject.kind": appspkg.AppProjectKind,
+		"involvedObject.name": proj.Name,
+	}).String()})
+	if err != nil {
+		return nil, err
+	}
+	// the events of applications are recorded in the namespaces of the applications
+	appEventsNamespace := s.ns
+	if len(s.enabledNamespaces) > 0 {
+		appEventsNamespace = ""
+	}
+	appEvents, err := s.kubeclientset.CoreV1().Events(appEventsNamespace).List(ctx, metav1.ListOptions{FieldSelector: fields.SelectorFromSet(map[string]string{
+		"involvedObject.kind": appspkg.ApplicationKind,
+	}).String()})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &v1.EventList{}
+	for _, e := range projEvents.Items {
+		if e.InvolvedObject.Kind == appspkg.AppProjectKind && isProjectActivity(e, proj.Name, projApps) {
+			activity.Items = append(activity.Items, e)
+		}
+	}
+	for _, e := range appEvents.Items {
+		if e.InvolvedObject.Kind != appspkg.ApplicationKind || !isProjectActivity(e, proj.Name, projApps) {
+			continue
+		}
+		appNamespace := eventObjectNamespace(e)
+		if !security.IsNamespaceEnabled(appNamespace, s.ns, s.enabledNamespaces) ||
+			!s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionGet, security.AppRBACName(s.ns, proj.Name, appNamespace, e.InvolvedObject.Name)) {
+			continue
+		}
+		activity.Items = append(activity.Items, e)
+	}
+	sort.SliceStable(activity.Items, func(i, j int) bool {
+		return eventTime(activity.Items[i]).After(eventTime(activity.Items[j]))
+	})
+	limit := int(q.Limit)
+	if limit <= 0 {
+		limit = defaultActivityLimit
+	}
+	if len(activity.Items) > limit {
+		activity.Items = activity.Items[:limit]
+	}
+	return activity, nil
+}
+
+// listApps returns the applications in the namespace of Argo CD and in the namespaces enabled for applications
+func (s *Server) listApps(ctx context.Context) ([]v1alpha1.Application, error) {
+	namespace := s.ns
+	if len(s.enabledNamespaces) > 0 {
+		namespace = ""
+	}
+	appsList, err := s.appclientset.ArgoprojV1alpha1().Applications(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var apps []v1alpha1.Application
+	for _, a := range appsList.Items {
+		if security.IsNamespaceEnabled(a.Namespace, s.ns, s.enabledNamespaces) {
+			apps = append(apps, a)
+		}
+	}
+	return apps, nil
+}
+
+// isProjectActivity returns whether the event is an event of the project, or of one of its applications, which are
+// given by their namespace and name. Events of applications are attributed to the project which the application
+// belonged to when the event was recorded, so that events of deleted applications are included.
+func isProjectActivity(e v1.Event, projName string, apps map[string]bool) bool {
+	switch e.InvolvedObject.Kind {
+	case appspkg.AppProjectKind:
+		return e.InvolvedObject.Name == projName
+	case appspkg.ApplicationKind:
+		if appProj, ok := e.Annotations[argo.EventAnnotationProject]; ok {
+			return appProj == projName
+		}
+		return apps[eventObjectNamespace(e)+"/"+e.InvolvedObject.Name]
+	}
+	return false
+}
+
+// eventObjectNamespace returns the namespace of the object of the event, which is the namespace of the event unless
+// it is set explicitly
+func eventObjectNamespace(e v1.Event) string {
+	if e.InvolvedObject.Namespace != "" {
+		return e.InvolvedObject.Namespace
+	}
+	return e.Namespace
+}
+
+// eventTime returns the time at which the event was last observed
+func eventTime(e v1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
 func (s *Server) logEvent(a *v1alpha1.AppProject, ctx context.Context, reason string, action string) {
 	eventInfo := argo.EventInfo{Type: v1.EventTypeNormal, Reason: reason}
 	user := session.Username(ctx)
@@ -520,9 +873,15 @@ func (s *Server) GetSyncWindowsState(ctx context.Context, q *project.SyncWindows
 		return nil, err
 	}
 
+	// the sync windows of the global projects apply to the applications of the project as well
+	virtualProj, err := argo.GetAppVirtualProject(proj, listersv1alpha1.NewAppProjectLister(s.projInformer.GetIndexer()), s.settingsMgr)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &project.SyncWindowsResponse{}
 
-	windows := proj.Spec.SyncWindows.Active()
+	windows := virtualProj.Spec.SyncWindows.Active()
 	if windows.HasWindows() {
 		res.Windows = *windows
 	} else {
@@ -532,6 +891,81 @@ func (s *Server) GetSyncWindowsState(ctx context.Context, q *project.SyncWindows
 	return res, nil
 }
 
+// GetDriftReport returns the drift report of the applications of a project
+func (s *Server) GetDriftReport(ctx context.Context, q *project.ProjectQuery) (*project.DriftReport, error) {
+	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceProjects, rbacpolicy.ActionGet, q.Name); err != nil {
+		return nil, err
+	}
+	proj, err := s.appclientset.ArgoprojV1alpha1().AppProjects(s.ns).Get(ctx, q.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// the signature keys of the global projects are required for the applications of the project as well
+	virtualProj, err := argo.GetAppVirtualProject(proj, listersv1alpha1.NewAppProjectLister(s.projInformer.GetIndexer()), s.settingsMgr)
+	if err != nil {
+		return nil, err
+	}
+	appsList, err := s.listApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := metav1.Now()
+	res := &project.DriftReport{Project: proj.Name, GeneratedAt: &now, Applications: []*project.ApplicationDrift{}}
+	apps := argo.FilterByProjects(appsList, []string{proj.Name})
+	for i := range apps {
+		if !s.enf.Enforce(ctx.Value("claims"), rbacpolicy.ResourceApplications, rbacpolicy.ActionGet, apps[i].RBACName(s.ns)) {
+			continue
+		}
+		res.Applications = append(res.Applications, newApplicationDrift(&apps[i], virtualProj))
+	}
+	return res, nil
+}
+
+func newApplicationDrift(a *v1alpha1.Application, proj *v1alpha1.AppProject) *project.ApplicationDrift {
+	drift := &project.ApplicationDrift{
+		Name:                  a.Name,
+		Namespace:             a.Namespace,
+		SyncStatus:            string(a.Status.Sync.Status),
+		HealthStatus:          string(a.Status.Health.Status),
+		SignatureVerification: signatureVerificationState(a, proj),
+	}
+	for _, res := range a.Status.Resources {
+		if res.Status == v1alpha1.SyncStatusCodeOutOfSync {
+			key := kube.NewResourceKey(res.Group, res.Kind, res.Namespace, res.Name)
+			drift.ModifiedResources = append(drift.ModifiedResources, key.String())
+		}
+	}
+	if len(a.Status.History) > 0 {
+		history := a.Status.History.LastRevisionHistory()
+		drift.LastSyncedAt = history.DeployedAt.DeepCopy()
+		drift.LastSyncedRevision = history.Revision
+		if len(history.Revisions) > 0 {
+			drift.LastSyncedRevision = strings.Join(history.Revisions, ",")
+		}
+	}
+	return drift
+}
+
+// signatureVerificationState returns the state of the signature verification of an app reported in its sync status.
+// If the sync status doesn't report it, e.g. because the app wasn't compared since, a failed verification is derived
+// from its conditions, since the application controller reports failed verifications as comparison errors. Otherwise,
+// the state is unknown, since an app is only reported as verified if the controller recorded it.
+func signatureVerificationState(a *v1alpha1.Application, proj *v1alpha1.AppProject) string {
+	if len(proj.Spec.SignatureKeys) == 0 {
+		return SignatureVerificationNotRequired
+	}
+	if a.Status.Sync.SignatureVerification != "" {
+		return string(a.Status.Sync.SignatureVerification)
+	}
+	for _, condition := range a.Status.Conditions {
+		if condition.Type == v1alpha1.ApplicationConditionComparisonError && strings.Contains(condition.Message, "signature") {
+			return SignatureVerificationFailed
+		}
+	}
+	return SignatureVerificationUnknown
+}
+
 func (s *Server) NormalizeProjs() error {
 	projList, err := s.appclientset.ArgoprojV1alpha1().AppProjects(s.ns).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
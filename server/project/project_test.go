@@ -3,8 +3,10 @@ package project
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/argoproj/argo-cd/v2/util/db"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
@@ -27,6 +30,7 @@ import (
 	informer "github.com/argoproj/argo-cd/v2/pkg/client/informers/externalversions"
 	"github.com/argoproj/argo-cd/v2/server/rbacpolicy"
 	"github.com/argoproj/argo-cd/v2/test"
+	"github.com/argoproj/argo-cd/v2/util/argo"
 	"github.com/argoproj/argo-cd/v2/util/assets"
 	jwtutil "github.com/argoproj/argo-cd/v2/util/jwt"
 	"github.com/argoproj/argo-cd/v2/util/rbac"
@@ -90,7 +94,7 @@ func TestProjectServer(t *testing.T) {
 		role1 := v1alpha1.ProjectRole{Name: roleName, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: 1}}}
 		projectWithRole.Spec.Roles = append(projectWithRole.Spec.Roles, role1)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithRole), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithRole), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, nil)
 		err := projectServer.NormalizeProjs()
 		assert.NoError(t, err)
 
@@ -106,7 +110,7 @@ func TestProjectServer(t *testing.T) {
 		enforcer.SetDefaultRole("role:projects")
 		_ = enforcer.SetBuiltinPolicy("p, role:projects, projects, update, *, allow")
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.Destinations = nil
@@ -121,7 +125,7 @@ func TestProjectServer(t *testing.T) {
 		enforcer.SetDefaultRole("role:projects")
 		_ = enforcer.SetBuiltinPolicy("p, role:projects, projects, update, *, allow")
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.SourceRepos = nil
@@ -136,7 +140,7 @@ func TestProjectServer(t *testing.T) {
 		enforcer.SetDefaultRole("role:projects")
 		_ = enforcer.SetBuiltinPolicy("p, role:projects, projects, update, *, allow")
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.ClusterResourceWhitelist = []metav1.GroupKind{{}}
@@ -151,7 +155,7 @@ func TestProjectServer(t *testing.T) {
 		enforcer.SetDefaultRole("role:projects")
 		_ = enforcer.SetBuiltinPolicy("p, role:projects, projects, update, *, allow")
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.NamespaceResourceBlacklist = []metav1.GroupKind{{}}
@@ -170,7 +174,7 @@ func TestProjectServer(t *testing.T) {
 		}
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.Destinations = updatedProj.Spec.Destinations[1:]
@@ -187,7 +191,7 @@ func TestProjectServer(t *testing.T) {
 		}
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.Destinations = updatedProj.Spec.Destinations[1:]
@@ -206,7 +210,7 @@ func TestProjectServer(t *testing.T) {
 		}
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.SourceRepos = []string{}
@@ -223,7 +227,7 @@ func TestProjectServer(t *testing.T) {
 		}
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := existingProj.DeepCopy()
 		updatedProj.Spec.SourceRepos = []string{}
@@ -243,7 +247,7 @@ func TestProjectServer(t *testing.T) {
 			Spec:       v1alpha1.ApplicationSpec{Project: "test", Source: &v1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argo-cd.git"}},
 		}
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := proj.DeepCopy()
 		updatedProj.Spec.SourceRepos = []string{"https://github.com/argoproj/*"}
@@ -270,7 +274,7 @@ func TestProjectServer(t *testing.T) {
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
 
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		updatedProj := proj.DeepCopy()
 		updatedProj.Spec.Destinations = []v1alpha1.ApplicationDestination{
@@ -285,7 +289,7 @@ func TestProjectServer(t *testing.T) {
 
 	t.Run("TestDeleteProjectSuccessful", func(t *testing.T) {
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		_, err := projectServer.Delete(context.Background(), &project.ProjectQuery{Name: "test"})
 
@@ -298,7 +302,7 @@ func TestProjectServer(t *testing.T) {
 			Spec:       v1alpha1.AppProjectSpec{},
 		}
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&defaultProj), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&defaultProj), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		_, err := projectServer.Delete(context.Background(), &project.ProjectQuery{Name: defaultProj.Name})
 		statusCode, _ := status.FromError(err)
@@ -312,7 +316,7 @@ func TestProjectServer(t *testing.T) {
 		}
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(&existingProj, &existingApp), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 
 		_, err := projectServer.Delete(context.Background(), &project.ProjectQuery{Name: "test"})
 
@@ -339,7 +343,7 @@ func TestProjectServer(t *testing.T) {
 		projectWithRole.Spec.Roles = []v1alpha1.ProjectRole{{Name: tokenName}}
 
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithRole), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithRole), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.CreateToken(ctx, &project.ProjectTokenCreateRequest{Project: projectWithRole.Name, Role: tokenName, ExpiresIn: 1})
 		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied: projects, update, test")
 	})
@@ -349,7 +353,7 @@ func TestProjectServer(t *testing.T) {
 		projectWithRole := existingProj.DeepCopy()
 		projectWithRole.Spec.Roles = []v1alpha1.ProjectRole{{Name: tokenName, Groups: []string{"my-group"}}}
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithRole), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithRole), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.CreateToken(ctx, &project.ProjectTokenCreateRequest{Project: projectWithRole.Name, Role: tokenName, ExpiresIn: 1})
 		assert.NoError(t, err)
 	})
@@ -363,7 +367,7 @@ func TestProjectServer(t *testing.T) {
 
 		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjListerFromInterface(clientset.ArgoprojV1alpha1().AppProjects("default")), "", nil, session.NewUserStateStorage(nil))
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), clientset, enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), clientset, enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		tokenResponse, err := projectServer.CreateToken(context.Background(), &project.ProjectTokenCreateRequest{Project: projectWithRole.Name, Role: tokenName, ExpiresIn: 100})
 		assert.NoError(t, err)
 		claims, _, err := sessionMgr.Parse(tokenResponse.Token)
@@ -384,7 +388,7 @@ func TestProjectServer(t *testing.T) {
 
 		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjListerFromInterface(clientset.ArgoprojV1alpha1().AppProjects("default")), "", nil, session.NewUserStateStorage(nil))
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), clientset, enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), clientset, enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		tokenResponse, err := projectServer.CreateToken(context.Background(), &project.ProjectTokenCreateRequest{Project: projectWithRole.Name, Role: tokenName, ExpiresIn: 1, Id: id})
 		assert.NoError(t, err)
 		claims, _, err := sessionMgr.Parse(tokenResponse.Token)
@@ -405,7 +409,7 @@ func TestProjectServer(t *testing.T) {
 
 		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjListerFromInterface(clientset.ArgoprojV1alpha1().AppProjects("default")), "", nil, session.NewUserStateStorage(nil))
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), clientset, enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), clientset, enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		tokenResponse, err := projectServer.CreateToken(context.Background(), &project.ProjectTokenCreateRequest{Project: projectWithRole.Name, Role: tokenName, ExpiresIn: 1, Id: id})
 
 		assert.NoError(t, err)
@@ -434,7 +438,7 @@ func TestProjectServer(t *testing.T) {
 		token := v1alpha1.ProjectRole{Name: tokenName, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: issuedAt}, {IssuedAt: secondIssuedAt}}}
 		projWithToken.Spec.Roles = append(projWithToken.Spec.Roles, token)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.DeleteToken(ctx, &project.ProjectTokenDeleteRequest{Project: projWithToken.Name, Role: tokenName, Iat: issuedAt})
 		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied: projects, update, test")
 	})
@@ -447,7 +451,7 @@ func TestProjectServer(t *testing.T) {
 		token := v1alpha1.ProjectRole{Name: tokenName, Groups: []string{"my-group"}, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: issuedAt}, {IssuedAt: secondIssuedAt}}}
 		projWithToken.Spec.Roles = append(projWithToken.Spec.Roles, token)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.DeleteToken(ctx, &project.ProjectTokenDeleteRequest{Project: projWithToken.Name, Role: tokenName, Iat: issuedAt})
 		assert.NoError(t, err)
 	})
@@ -463,7 +467,7 @@ p, role:admin, projects, update, *, allow`)
 		token := v1alpha1.ProjectRole{Name: tokenName, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: issuedAt}, {IssuedAt: secondIssuedAt}}}
 		projWithToken.Spec.Roles = append(projWithToken.Spec.Roles, token)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.DeleteToken(ctx, &project.ProjectTokenDeleteRequest{Project: projWithToken.Name, Role: tokenName, Iat: issuedAt})
 		assert.NoError(t, err)
 		projWithoutToken, err := projectServer.Get(context.Background(), &project.ProjectQuery{Name: projWithToken.Name})
@@ -487,7 +491,7 @@ p, role:admin, projects, update, *, allow`)
 		token := v1alpha1.ProjectRole{Name: tokenName, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: issuedAt, ID: id}, {IssuedAt: secondIssuedAt, ID: secondId}}}
 		projWithToken.Spec.Roles = append(projWithToken.Spec.Roles, token)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.DeleteToken(ctx, &project.ProjectTokenDeleteRequest{Project: projWithToken.Name, Role: tokenName, Iat: secondIssuedAt, Id: id})
 		assert.NoError(t, err)
 		projWithoutToken, err := projectServer.Get(context.Background(), &project.ProjectQuery{Name: projWithToken.Name})
@@ -506,7 +510,7 @@ p, role:admin, projects, update, *, allow`)
 		token := v1alpha1.ProjectRole{Name: tokenName, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: 1}}}
 		projWithToken.Spec.Roles = append(projWithToken.Spec.Roles, token)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.CreateToken(context.Background(), &project.ProjectTokenCreateRequest{Project: projWithToken.Name, Role: tokenName})
 		assert.Nil(t, err)
 		projWithTwoTokens, err := projectServer.Get(context.Background(), &project.ProjectQuery{Name: projWithToken.Name})
@@ -515,13 +519,48 @@ p, role:admin, projects, update, *, allow`)
 		assert.Len(t, projWithTwoTokens.Spec.Roles[0].JWTTokens, 2)
 	})
 
+	t.Run("TestCreateTokenWithDefaultExpiration", func(t *testing.T) {
+		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjLister(), "", nil, session.NewUserStateStorage(nil))
+		projWithRole := existingProj.DeepCopy()
+		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, v1alpha1.ProjectRole{Name: tokenName, DefaultTokenExpiration: "1h"})
+		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), sessionMgr, policyEnf, projInformer, settingsMgr, argoDB, nil)
+		_, err := projectServer.CreateToken(context.Background(), &project.ProjectTokenCreateRequest{Project: projWithRole.Name, Role: tokenName})
+		assert.NoError(t, err)
+		_, err = projectServer.CreateToken(context.Background(), &project.ProjectTokenCreateRequest{Project: projWithRole.Name, Role: tokenName, ExpiresIn: 60})
+		assert.NoError(t, err)
+		tokens, err := projectServer.ListTokens(context.Background(), &project.ProjectTokenListRequest{Project: projWithRole.Name, Role: tokenName})
+		assert.NoError(t, err)
+		var expirations []int64
+		for _, token := range tokens.Items {
+			expirations = append(expirations, token.ExpiresAt-token.IssuedAt)
+		}
+		// both tokens may be issued within the same second, so their order is undefined
+		assert.ElementsMatch(t, []int64{3600, 60}, expirations)
+	})
+
+	t.Run("TestListTokens", func(t *testing.T) {
+		projWithToken := existingProj.DeepCopy()
+		token := v1alpha1.ProjectRole{Name: tokenName, JWTTokens: []v1alpha1.JWTToken{{IssuedAt: 1, ExpiresAt: 2, ID: "first"}, {IssuedAt: 3}}}
+		projWithToken.Spec.Roles = append(projWithToken.Spec.Roles, token)
+		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithToken), enforcer, sync.NewKeyLock(), nil, policyEnf, projInformer, settingsMgr, argoDB, nil)
+		tokens, err := projectServer.ListTokens(context.Background(), &project.ProjectTokenListRequest{Project: projWithToken.Name, Role: tokenName})
+		assert.NoError(t, err)
+		// the tokens are listed newest first
+		assert.Equal(t, []v1alpha1.JWTToken{{IssuedAt: 3, ID: "3"}, {IssuedAt: 1, ExpiresAt: 2, ID: "first"}}, tokens.Items)
+
+		_, err = projectServer.ListTokens(context.Background(), &project.ProjectTokenListRequest{Project: projWithToken.Name, Role: "missing"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
 	t.Run("TestAddWildcardSource", func(t *testing.T) {
 
 		proj := existingProj.DeepCopy()
 		wildSourceRepo := "*"
 		proj.Spec.SourceRepos = append(proj.Spec.SourceRepos, wildSourceRepo)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj), enforcer, sync.NewKeyLock(), nil, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj), enforcer, sync.NewKeyLock(), nil, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: proj}
 		updatedProj, err := projectServer.Update(context.Background(), request)
 		assert.Nil(t, err)
@@ -540,7 +579,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, policy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, policyEnf, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, policyEnf, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		_, err := projectServer.Update(context.Background(), request)
 		assert.Nil(t, err)
@@ -562,7 +601,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, policy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		_, err := projectServer.Update(context.Background(), request)
 		expectedErr := fmt.Sprintf("rpc error: code = AlreadyExists desc = policy '%s' already exists for role '%s'", policy, roleName)
@@ -582,7 +621,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, policy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		_, err := projectServer.Update(context.Background(), request)
 		assert.Contains(t, err.Error(), "object must be of form 'test/*' or 'test/<APPNAME>'")
@@ -601,7 +640,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, invalidPolicy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		_, err := projectServer.Update(context.Background(), request)
 		assert.Contains(t, err.Error(), "policy subject must be: 'proj:test:testRole'")
@@ -620,7 +659,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, invalidPolicy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		_, err := projectServer.Update(context.Background(), request)
 		assert.Contains(t, err.Error(), "policy subject must be: 'proj:test:testRole'")
@@ -638,7 +677,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, invalidPolicy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		_, err := projectServer.Update(context.Background(), request)
 		assert.Contains(t, err.Error(), "effect must be: 'allow' or 'deny'")
@@ -657,7 +696,7 @@ p, role:admin, projects, update, *, allow`)
 		role.Policies = append(role.Policies, invalidPolicy)
 		projWithRole.Spec.Roles = append(projWithRole.Spec.Roles, role)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projWithRole), enforcer, sync.NewKeyLock(), nil, nil, projInformer, settingsMgr, argoDB, nil)
 		request := &project.ProjectUpdateRequest{Project: projWithRole}
 		updateProj, err := projectServer.Update(context.Background(), request)
 		assert.Nil(t, err)
@@ -672,7 +711,7 @@ p, role:admin, projects, update, *, allow`)
 		win := &v1alpha1.SyncWindow{Kind: "allow", Schedule: "* * * * *", Duration: "1h"}
 		projectWithSyncWindows.Spec.SyncWindows = append(projectWithSyncWindows.Spec.SyncWindows, win)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithSyncWindows), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithSyncWindows), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, nil)
 		res, err := projectServer.GetSyncWindowsState(ctx, &project.SyncWindowsQuery{Name: projectWithSyncWindows.Name})
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(res.Windows))
@@ -685,12 +724,156 @@ p, role:admin, projects, update, *, allow`)
 		win := &v1alpha1.SyncWindow{Kind: "allow", Schedule: "* * * * *", Duration: "1h"}
 		projectWithSyncWindows.Spec.SyncWindows = append(projectWithSyncWindows.Spec.SyncWindows, win)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithSyncWindows), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithSyncWindows), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, nil)
 		res, err := projectServer.GetSyncWindowsState(ctx, &project.SyncWindowsQuery{Name: "incorrect"})
 		assert.Contains(t, err.Error(), "not found")
 		assert.Nil(t, res)
 	})
 
+	t.Run("TestGetDriftReport", func(t *testing.T) {
+		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjLister(), "", nil, session.NewUserStateStorage(nil))
+		signedProj := existingProj.DeepCopy()
+		signedProj.Spec.SignatureKeys = []v1alpha1.SignatureKey{{KeyID: "4AEE18F83AFDEB23"}}
+		driftedApp := existingApp.DeepCopy()
+		driftedApp.Status.Sync.Status = v1alpha1.SyncStatusCodeOutOfSync
+		driftedApp.Status.Resources = []v1alpha1.ResourceStatus{
+			{Kind: "Deployment", Group: "apps", Namespace: "ns3", Name: "guestbook", Status: v1alpha1.SyncStatusCodeOutOfSync},
+			{Kind: "Service", Namespace: "ns3", Name: "guestbook", Status: v1alpha1.SyncStatusCodeSynced},
+		}
+		driftedApp.Status.History = v1alpha1.RevisionHistories{{ID: 1, Revision: "abc123", DeployedAt: v1.Now()}}
+		reconciledAt := v1.Now()
+		driftedApp.Status.ReconciledAt = &reconciledAt
+		driftedApp.Status.Conditions = []v1alpha1.ApplicationCondition{
+			{Type: v1alpha1.ApplicationConditionComparisonError, Message: "Target revision abc123 in Git is not signed, but a signature is required"},
+		}
+		otherApp := existingApp.DeepCopy()
+		otherApp.Name = "other"
+		otherApp.Spec.Project = "default"
+		teamApp := existingApp.DeepCopy()
+		teamApp.Namespace = "team-a"
+		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(signedProj, driftedApp, otherApp, teamApp), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, []string{"team-a"})
+		res, err := projectServer.GetDriftReport(ctx, &project.ProjectQuery{Name: signedProj.Name})
+		require.NoError(t, err)
+		assert.Equal(t, signedProj.Name, res.Project)
+		require.Len(t, res.Applications, 2)
+		sort.Slice(res.Applications, func(i, j int) bool {
+			return res.Applications[i].Namespace < res.Applications[j].Namespace
+		})
+		// the app in another namespace was never compared
+		assert.Equal(t, "team-a", res.Applications[1].Namespace)
+		assert.Equal(t, SignatureVerificationUnknown, res.Applications[1].SignatureVerification)
+		drift := res.Applications[0]
+		assert.Equal(t, driftedApp.Name, drift.Name)
+		assert.Equal(t, string(v1alpha1.SyncStatusCodeOutOfSync), drift.SyncStatus)
+		assert.Equal(t, []string{"apps/Deployment/ns3/guestbook"}, drift.ModifiedResources)
+		assert.Equal(t, "abc123", drift.LastSyncedRevision)
+		assert.NotNil(t, drift.LastSyncedAt)
+		assert.Equal(t, SignatureVerificationFailed, drift.SignatureVerification)
+	})
+
+	t.Run("TestListActivity", func(t *testing.T) {
+		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjLister(), "", nil, session.NewUserStateStorage(nil))
+		newEvent := func(name string, namespace string, kind string, objName string, proj string, minutes int) *corev1.Event {
+			e := &corev1.Event{
+				ObjectMeta:     v1.ObjectMeta{Name: name, Namespace: namespace},
+				InvolvedObject: corev1.ObjectReference{Kind: kind, Name: objName},
+				LastTimestamp:  v1.NewTime(time.Date(2022, 1, 1, 0, minutes, 0, 0, time.UTC)),
+			}
+			if proj != "" {
+				e.Annotations = map[string]string{argo.EventAnnotationProject: proj}
+			}
+			return e
+		}
+		kubeclientset := fake.NewSimpleClientset(
+			newEvent("project-updated", "default", "AppProject", "test", "", 1),
+			newEvent("app-synced", "default", "Application", "test", "test", 3),
+			newEvent("deleted-app-synced", "default", "Application", "deleted", "test", 2),
+			newEvent("legacy-app-synced", "default", "Application", "test", "", 0),
+			newEvent("other-app-synced", "default", "Application", "other", "default", 4),
+			newEvent("other-project-updated", "default", "AppProject", "default", "", 5),
+			newEvent("team-app-synced", "team-a", "Application", "test", "", 6),
+			newEvent("disabled-app-synced", "team-b", "Application", "test", "test", 7),
+		)
+		teamApp := existingApp.DeepCopy()
+		teamApp.Namespace = "team-a"
+		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
+		projectServer := NewServer("default", kubeclientset, apps.NewSimpleClientset(existingProj.DeepCopy(), existingApp.DeepCopy(), teamApp), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, []string{"team-a"})
+
+		res, err := projectServer.ListActivity(ctx, &project.ProjectActivityRequest{Name: existingProj.Name})
+		require.NoError(t, err)
+		var names []string
+		for _, e := range res.Items {
+			names = append(names, e.Name)
+		}
+		assert.Equal(t, []string{"team-app-synced", "app-synced", "deleted-app-synced", "project-updated", "legacy-app-synced"}, names)
+
+		res, err = projectServer.ListActivity(ctx, &project.ProjectActivityRequest{Name: existingProj.Name, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "team-app-synced", res.Items[0].Name)
+	})
+
+	t.Run("TestListActivityWithoutApplicationPermission", func(t *testing.T) {
+		enforcer := newEnforcer(kubeclientset)
+		enforcer.SetDefaultRole("role:projects")
+		_ = enforcer.SetBuiltinPolicy("p, role:projects, projects, get, *, allow")
+		enforcer.SetClaimsEnforcerFunc(nil)
+		// nolint:staticcheck
+		ctx := context.WithValue(context.Background(), "claims", &jwt.MapClaims{"groups": []string{"my-group"}})
+
+		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjLister(), "", nil, session.NewUserStateStorage(nil))
+		kubeclientset := fake.NewSimpleClientset(
+			&corev1.Event{ObjectMeta: v1.ObjectMeta{Name: "project-updated", Namespace: "default"}, InvolvedObject: corev1.ObjectReference{Kind: "AppProject", Name: "test"}},
+			&corev1.Event{ObjectMeta: v1.ObjectMeta{Name: "app-synced", Namespace: "default"}, InvolvedObject: corev1.ObjectReference{Kind: "Application", Name: "test"}},
+		)
+		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
+		projectServer := NewServer("default", kubeclientset, apps.NewSimpleClientset(existingProj.DeepCopy(), existingApp.DeepCopy()), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, nil)
+
+		res, err := projectServer.ListActivity(ctx, &project.ProjectActivityRequest{Name: existingProj.Name})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "project-updated", res.Items[0].Name)
+	})
+
+	t.Run("TestValidateSpec", func(t *testing.T) {
+		sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjLister(), "", nil, session.NewUserStateStorage(nil))
+		proj := existingProj.DeepCopy()
+		proj.Spec.Destinations = append(proj.Spec.Destinations, v1alpha1.ApplicationDestination{Namespace: "ns3", Server: "https://server3"})
+		app := existingApp.DeepCopy()
+		app.Spec.Source.RepoURL = "https://github.com/argoproj/argo-cd.git"
+		app.Status.Resources = []v1alpha1.ResourceStatus{{Kind: "Deployment", Group: "apps", Namespace: "ns3", Name: "guestbook"}}
+		nonConformantApp := existingApp.DeepCopy()
+		nonConformantApp.Name = "non-conformant"
+		nonConformantApp.Spec.Source.RepoURL = "https://github.com/argoproj/argocd-example-apps.git"
+		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(proj, app, nonConformantApp), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, nil)
+
+		updatedProj := proj.DeepCopy()
+		updatedProj.Spec.Destinations = []v1alpha1.ApplicationDestination{{Namespace: "ns[", Server: "https://server3"}}
+		updatedProj.Spec.NamespaceResourceBlacklist = []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}}
+		updatedProj.Spec.SyncWindows = v1alpha1.SyncWindows{{Kind: "allow", Schedule: "* * *", Duration: "1h", Applications: []string{"*"}}}
+		updatedProj.Spec.Roles = []v1alpha1.ProjectRole{{Name: "admin", Policies: []string{"p, proj:test:admin, applications"}}}
+		res, err := projectServer.ValidateSpec(ctx, &project.ProjectUpdateRequest{Project: updatedProj})
+		require.NoError(t, err)
+		assert.Contains(t, res.Errors, "invalid policy rule 'p, proj:test:admin, applications': must be of the form: 'p, sub, res, act, obj, eft'")
+		assert.Contains(t, strings.Join(res.Errors, "\n"), "cannot parse schedule '* * *'")
+		assert.Contains(t, strings.Join(res.Errors, "\n"), "destination pattern 'ns[' is invalid")
+		require.Len(t, res.Applications, 2)
+		// the source of the application isn't permitted by the current spec either, so it isn't reported
+		assert.Equal(t, nonConformantApp.Name, res.Applications[0].Name)
+		assert.Equal(t, []string{"destination server 'https://server3' and namespace 'ns3' are not permitted"}, res.Applications[0].Reasons)
+		assert.Equal(t, app.Name, res.Applications[1].Name)
+		assert.Equal(t, []string{
+			"destination server 'https://server3' and namespace 'ns3' are not permitted",
+			"namespaced resource apps:Deployment is not permitted in project 'test': it is in the namespace resource blacklist",
+		}, res.Applications[1].Reasons)
+
+		stored, err := projectServer.Get(ctx, &project.ProjectQuery{Name: proj.Name})
+		require.NoError(t, err)
+		assert.Equal(t, proj.Spec.Destinations, stored.Spec.Destinations)
+	})
+
 	t.Run("TestGetSyncWindowsStateDenied", func(t *testing.T) {
 		enforcer = newEnforcer(kubeclientset)
 		_ = enforcer.SetBuiltinPolicy(`p, *, *, *, *, deny`)
@@ -703,7 +886,7 @@ p, role:admin, projects, update, *, allow`)
 		win := &v1alpha1.SyncWindow{Kind: "allow", Schedule: "* * * * *", Duration: "1h"}
 		projectWithSyncWindows.Spec.SyncWindows = append(projectWithSyncWindows.Spec.SyncWindows, win)
 		argoDB := db.NewDB("default", settingsMgr, kubeclientset)
-		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithSyncWindows), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB)
+		projectServer := NewServer("default", fake.NewSimpleClientset(), apps.NewSimpleClientset(projectWithSyncWindows), enforcer, sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, nil)
 		_, err := projectServer.GetSyncWindowsState(ctx, &project.SyncWindowsQuery{Name: projectWithSyncWindows.Name})
 		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied: projects, get, test")
 	})
@@ -719,3 +902,128 @@ func newEnforcer(kubeclientset *fake.Clientset) *rbac.Enforcer {
 	})
 	return enforcer
 }
+
+func TestDescribeProjectUpdate(t *testing.T) {
+	oldProj := &v1alpha1.AppProject{Spec: v1alpha1.AppProjectSpec{SourceRepos: []string{"*"}}}
+	assert.Equal(t, "updated project", describeProjectUpdate(oldProj, oldProj.DeepCopy()))
+
+	newProj := oldProj.DeepCopy()
+	newProj.Spec.Roles = []v1alpha1.ProjectRole{{Name: "admin"}}
+	newProj.Spec.SyncWindows = v1alpha1.SyncWindows{{Kind: "deny", Schedule: "* * * * *", Duration: "1h"}}
+	assert.Equal(t, "updated project roles, sync windows", describeProjectUpdate(oldProj, newProj))
+}
+
+func TestSignatureVerificationState(t *testing.T) {
+	proj := &v1alpha1.AppProject{Spec: v1alpha1.AppProjectSpec{SignatureKeys: []v1alpha1.SignatureKey{{KeyID: "4AEE18F83AFDEB23"}}}}
+	app := &v1alpha1.Application{}
+	assert.Equal(t, SignatureVerificationNotRequired, signatureVerificationState(app, &v1alpha1.AppProject{}))
+	// the app was never compared
+	assert.Equal(t, SignatureVerificationUnknown, signatureVerificationState(app, proj))
+
+	// the app was compared, but the controller didn't record the verification
+	reconciledAt := metav1.Now()
+	app.Status.ReconciledAt = &reconciledAt
+	assert.Equal(t, SignatureVerificationUnknown, signatureVerificationState(app, proj))
+
+	app.Status.Sync.SignatureVerification = v1alpha1.SignatureVerificationVerified
+	assert.Equal(t, SignatureVerificationVerified, signatureVerificationState(app, proj))
+	app.Status.Sync.SignatureVerification = ""
+
+	app.Status.Conditions = []v1alpha1.ApplicationCondition{{Type: v1alpha1.ApplicationConditionComparisonError, Message: "Target revision abc123 in Git is not signed, but a signature is required"}}
+	assert.Equal(t, SignatureVerificationFailed, signatureVerificationState(app, proj))
+
+	app.Status.Conditions = nil
+	app.Status.Sync.SignatureVerification = v1alpha1.SignatureVerificationFailed
+	assert.Equal(t, SignatureVerificationFailed, signatureVerificationState(app, proj))
+}
+
+func TestValidateSpecGlobalProjectsAndAppNamespaces(t *testing.T) {
+	kubeclientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "argocd-cm",
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of": "argocd",
+			},
+		},
+		Data: map[string]string{
+			"globalProjects": `
+- projectName: global
+  labelSelector:
+    matchLabels:
+      global: "true"`,
+		},
+	}, &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "argocd-secret",
+			Namespace: testNamespace,
+		},
+		Data: map[string][]byte{
+			"admin.password":   []byte("test"),
+			"server.secretkey": []byte("test"),
+		},
+	})
+	settingsMgr := settings.NewSettingsManager(context.Background(), kubeclientset, testNamespace)
+	proj := &v1alpha1.AppProject{
+		ObjectMeta: v1.ObjectMeta{Name: "test", Namespace: testNamespace, Labels: map[string]string{"global": "true"}},
+		Spec: v1alpha1.AppProjectSpec{
+			Destinations:     []v1alpha1.ApplicationDestination{{Namespace: "ns1", Server: "https://server1"}},
+			SourceRepos:      []string{"https://github.com/argoproj/argo-cd.git", "https://github.com/argoproj/argocd-example-apps.git"},
+			SourceNamespaces: []string{"apps"},
+		},
+	}
+	globalProj := &v1alpha1.AppProject{
+		ObjectMeta: v1.ObjectMeta{Name: "global", Namespace: testNamespace},
+		Spec: v1alpha1.AppProjectSpec{
+			Destinations: []v1alpha1.ApplicationDestination{{Namespace: "ns3", Server: "https://server3"}},
+		},
+	}
+	app := &v1alpha1.Application{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: testNamespace},
+		Spec: v1alpha1.ApplicationSpec{
+			Source:      &v1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argo-cd.git"},
+			Project:     "test",
+			Destination: v1alpha1.ApplicationDestination{Namespace: "ns3", Server: "https://server3"},
+		},
+	}
+	otherNamespaceApp := app.DeepCopy()
+	otherNamespaceApp.Namespace = "apps"
+	otherNamespaceApp.Spec.Source.RepoURL = "https://github.com/argoproj/argocd-example-apps.git"
+
+	ctx := context.Background()
+	appClientset := apps.NewSimpleClientset(proj, globalProj, app, otherNamespaceApp)
+	factory := informer.NewSharedInformerFactoryWithOptions(appClientset, 0, informer.WithNamespace(""), informer.WithTweakListOptions(func(options *metav1.ListOptions) {}))
+	projInformer := factory.Argoproj().V1alpha1().AppProjects().Informer()
+	go projInformer.Run(ctx.Done())
+	if !k8scache.WaitForCacheSync(ctx.Done(), projInformer.HasSynced) {
+		panic("Timed out waiting for caches to sync")
+	}
+	sessionMgr := session.NewSessionManager(settingsMgr, test.NewFakeProjLister(), "", nil, session.NewUserStateStorage(nil))
+	argoDB := db.NewDB(testNamespace, settingsMgr, kubeclientset)
+	projectServer := NewServer(testNamespace, kubeclientset, appClientset, newEnforcer(kubeclientset), sync.NewKeyLock(), sessionMgr, nil, projInformer, settingsMgr, argoDB, []string{"apps"})
+
+	// the destination of the applications is still permitted by the global project, but the source of the application
+	// in the other namespace is not
+	updatedProj := proj.DeepCopy()
+	updatedProj.Spec.Destinations = nil
+	updatedProj.Spec.SourceRepos = []string{"https://github.com/argoproj/argo-cd.git"}
+	res, err := projectServer.ValidateSpec(ctx, &project.ProjectUpdateRequest{Project: updatedProj})
+	require.NoError(t, err)
+	assert.Empty(t, res.Errors)
+	assert.Equal(t, []*project.NonConformantApplication{{
+		Name:      otherNamespaceApp.Name,
+		Namespace: otherNamespaceApp.Namespace,
+		Reasons:   []string{"source repository 'https://github.com/argoproj/argocd-example-apps.git' is not permitted"},
+	}}, res.Applications)
+
+	// applications in the installation namespace are always permitted
+	updatedProj = proj.DeepCopy()
+	updatedProj.Spec.SourceNamespaces = nil
+	res, err = projectServer.ValidateSpec(ctx, &project.ProjectUpdateRequest{Project: updatedProj})
+	require.NoError(t, err)
+	assert.Equal(t, []*project.NonConformantApplication{{
+		Name:      otherNamespaceApp.Name,
+		Namespace: otherNamespaceApp.Namespace,
+		Reasons:   []string{"application namespace 'apps' is not permitted"},
+	}}, res.Applications)
+}
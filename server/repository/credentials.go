@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	appsv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	servercache "github.com/argoproj/argo-cd/v2/server/cache"
+	"github.com/argoproj/argo-cd/v2/server/metrics"
+	"github.com/argoproj/argo-cd/v2/util/argo"
+	"github.com/argoproj/argo-cd/v2/util/db"
+	"github.com/argoproj/argo-cd/v2/util/env"
+	"github.com/argoproj/argo-cd/v2/util/errors"
+	"github.com/argoproj/argo-cd/v2/util/git"
+	"github.com/argoproj/argo-cd/v2/util/io"
+	jwtutil "github.com/argoproj/argo-cd/v2/util/jwt"
+)
+
+const (
+	// credentialsRevalidatorLeaseName is the name of the lease which elects the API server replica revalidating the
+	// credentials of the repositories
+	credentialsRevalidatorLeaseName = "argocd-server-repo-credentials-revalidator"
+	// EventReasonCredentialsInvalid is the reason of the events of repositories whose credentials are invalid or expire soon
+	EventReasonCredentialsInvalid = "CredentialsInvalid"
+	// EventReasonCredentialsValid is the reason of the events of repositories whose credentials are valid again
+	EventReasonCredentialsValid = "CredentialsValid"
+)
+
+var (
+	// credentialsRevalidationInterval is the interval in which the credentials of all repositories are revalidated.
+	// Disabled if 0
+	credentialsRevalidationInterval = env.ParseDurationFromEnv(common.EnvRepoCredentialsRevalidationInterval, time.Hour, 0, math.MaxInt64)
+	// credentialsRevalidationHostQPS is the maximum number of revalidations per second against a single host
+	credentialsRevalidationHostQPS = env.ParseFloatFromEnv(common.EnvRepoCredentialsRevalidationHostQPS, 1, 0.001, math.MaxFloat32)
+	// credentialsExpiryWarning is how long before the expiry of credentials warnings are emitted
+	credentialsExpiryWarning = env.ParseDurationFromEnv(common.EnvRepoCredentialsExpiryWarning, 7*24*time.Hour, 0, math.MaxInt64)
+)
+
+// CredentialsRevalidator periodically revalidates the credentials of all repositories, so that credentials which were
+// revoked or expired are noticed before the next sync fails. Revalidations against the same host are rate limited, and
+// the results are exposed as the credential state of the repositories and as metrics. Changes of the credential state
+// are recorded as events of the secrets of the repositories.
+type CredentialsRevalidator struct {
+	db            db.ArgoDB
+	repoClientset apiclient.Clientset
+	cache         *servercache.Cache
+	metricsServer *metrics.MetricsServer
+	kubeclientset kubernetes.Interface
+	namespace     string
+	auditLogger   *argo.AuditLogger
+	interval      time.Duration
+	hostLimit     rate.Limit
+	now           func() time.Time
+}
+
+// NewCredentialsRevalidator returns a new CredentialsRevalidator. The metrics server is optional.
+func NewCredentialsRevalidator(db db.ArgoDB, repoClientset apiclient.Clientset, cache *servercache.Cache, metricsServer *metrics.MetricsServer, kubeclientset kubernetes.Interface, namespace string) *CredentialsRevalidator {
+	return &CredentialsRevalidator{
+		db:            db,
+		repoClientset: repoClientset,
+		cache:         cache,
+		metricsServer: metricsServer,
+		kubeclientset: kubeclientset,
+		namespace:     namespace,
+		auditLogger:   argo.NewAuditLogger(namespace, kubeclientset, "argocd-server"),
+		interval:      credentialsRevalidationInterval,
+		hostLimit:     rate.Limit(credentialsRevalidationHostQPS),
+		now:           time.Now,
+	}
+}
+
+// RunWithLeaderElection runs the revalidation only in the API server replica which holds the lease of the revalidator,
+// so that the credentials are revalidated once per interval no matter how many replicas are running. Another replica
+// takes over if the leader stops.
+func (r *CredentialsRevalidator) RunWithLeaderElection(ctx context.Context) {
+	if r.interval <= 0 || r.repoClientset == nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: credentialsRevalidatorLeaseName, Namespace: r.namespace},
+		Client:     r.kubeclientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: hostname + "_" + uuid.New().String()},
+	}
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			ReleaseOnCancel: true,
+			LeaseDuration:   60 * time.Second,
+			RenewDeadline:   30 * time.Second,
+			RetryPeriod:     5 * time.Second,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					log.Info("Started revalidating repository credentials")
+					r.Run(ctx)
+				},
+				OnStoppedLeading: func() {
+					log.Info("Stopped revalidating repository credentials")
+				},
+			},
+		})
+	}
+}
+
+// Run revalidates the credentials of all repositories in the configured interval until the context is done
+func (r *CredentialsRevalidator) Run(ctx context.Context) {
+	if r.interval <= 0 || r.repoClientset == nil {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.Revalidate(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Revalidate revalidates the credentials of all repositories once. Repositories of different hosts are revalidated
+// concurrently, repositories of the same host one after another within the host rate limit.
+func (r *CredentialsRevalidator) Revalidate(ctx context.Context) {
+	repos, err := r.db.ListRepositories(ctx)
+	if err != nil {
+		log.Warnf("Failed to list repositories for credentials revalidation: %v", err)
+		return
+	}
+	var hosts []string
+	reposByHost := map[string][]string{}
+	for _, repo := range repos {
+		host := repoHost(repo.Repo)
+		if _, ok := reposByHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		reposByHost[host] = append(reposByHost[host], repo.Repo)
+	}
+
+	sem := make(chan struct{}, connectionTestParallelism)
+	_ = kube.RunAllAsync(len(hosts), func(i int) error {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+		limiter := rate.NewLimiter(r.hostLimit, 1)
+		for _, repoURL := range reposByHost[hosts[i]] {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil
+			}
+			r.revalidateRepo(ctx, repoURL)
+		}
+		return nil
+	})
+}
+
+func (r *CredentialsRevalidator) revalidateRepo(ctx context.Context, repoURL string) {
+	repo, err := r.db.GetRepository(ctx, repoURL)
+	if err != nil {
+		log.Warnf("Failed to get repository %s for credentials revalidation: %v", repoURL, err)
+		return
+	}
+	if !repo.HasCredentials() {
+		return
+	}
+	previous, previousErr := r.cache.GetRepoCredentialState(repoURL)
+	state, expiry := r.validate(ctx, repo)
+	if r.metricsServer != nil {
+		r.metricsServer.ObserveRepoCredentialState(repoURL, state.Status == appsv1.ConnectionStatusSuccessful, expiry)
+	}
+	if previousErr != nil {
+		// the first revalidation only reports problems
+		previous = appsv1.ConnectionState{Status: appsv1.ConnectionStatusSuccessful}
+	}
+	if previous.Status != state.Status || previous.Message != state.Message {
+		r.recordStateChange(ctx, repoURL, state)
+	}
+	// the state is kept until the revalidation after next, so that it doesn't disappear while a revalidation is running
+	if err := r.cache.SetRepoCredentialState(repoURL, state, 2*r.interval); err != nil {
+		log.Warnf("Failed to store credential state of repository %s: %v", repoURL, err)
+	}
+}
+
+// recordStateChange records a change of the credential state of the repository as an event of the secret of the
+// repository. Repositories which are not stored in a secret are only logged.
+func (r *CredentialsRevalidator) recordStateChange(ctx context.Context, repoURL string, state *appsv1.ConnectionState) {
+	info := argo.EventInfo{Type: corev1.EventTypeWarning, Reason: EventReasonCredentialsInvalid}
+	message := fmt.Sprintf("Credentials of repository %s: %s", repoURL, state.Message)
+	if state.Status == appsv1.ConnectionStatusSuccessful && state.Message == "" {
+		info = argo.EventInfo{Type: corev1.EventTypeNormal, Reason: EventReasonCredentialsValid}
+		message = fmt.Sprintf("Credentials of repository %s are valid", repoURL)
+	}
+	secrets, err := r.kubeclientset.CoreV1().Secrets(r.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", common.LabelKeySecretType, common.LabelValueSecretTypeRepository),
+	})
+	if err != nil {
+		log.Warnf("Failed to list repository secrets to record the credential state of repository %s: %v", repoURL, err)
+		return
+	}
+	for i := range secrets.Items {
+		if string(secrets.Items[i].Data["url"]) == repoURL {
+			r.auditLogger.LogRepoEvent(&secrets.Items[i], repoURL, info, message)
+			return
+		}
+	}
+}
+
+// validate tests the connection to the repository with its credentials and checks the expiry of the credentials
+// which expose it, i.e. TLS client certificates and JWT tokens
+func (r *CredentialsRevalidator) validate(ctx context.Context, repo *appsv1.Repository) (*appsv1.ConnectionState, *time.Time) {
+	now := metav1.NewTime(r.now())
+	state := &appsv1.ConnectionState{Status: appsv1.ConnectionStatusSuccessful, ModifiedAt: &now}
+
+	credential, expiry, err := credentialsExpiry(repo)
+	if err != nil {
+		state.Status = appsv1.ConnectionStatusFailed
+		state.Message = fmt.Sprintf("Invalid %s: %v", credential, err)
+		return state, nil
+	}
+	if expiry != nil {
+		remaining := expiry.Sub(now.Time)
+		if remaining <= 0 {
+			state.Status = appsv1.ConnectionStatusFailed
+			state.Message = fmt.Sprintf("%s expired at %s", credential, expiry.UTC().Format(time.RFC3339))
+			log.Warnf("The %s of repository %s expired at %s", credential, repo.Repo, expiry.UTC().Format(time.RFC3339))
+			return state, expiry
+		}
+		if remaining < credentialsExpiryWarning {
+			state.Message = fmt.Sprintf("%s expires at %s", credential, expiry.UTC().Format(time.RFC3339))
+			log.Warnf("The %s of repository %s expires at %s", credential, repo.Repo, expiry.UTC().Format(time.RFC3339))
+		}
+	}
+
+	testCtx, cancel := ctx, func() {}
+	if connectionTestTimeout > 0 {
+		testCtx, cancel = context.WithTimeout(ctx, connectionTestTimeout)
+	}
+	defer cancel()
+	conn, repoClient, err := r.repoClientset.NewRepoServerClient()
+	if err == nil {
+		defer io.Close(conn)
+		_, err = repoClient.TestRepository(testCtx, &apiclient.TestRepositoryRequest{Repo: repo})
+	}
+	if err != nil {
+		state.Status = appsv1.ConnectionStatusFailed
+		if errors.IsCredentialsConfigurationError(err) {
+			state.Message = "Configuration error - please check the server logs"
+		} else {
+			state.Message = fmt.Sprintf("Unable to authenticate to repository: %v", err)
+		}
+		log.Warnf("Revalidation of the credentials of repository %s failed: %v", repo.Repo, err)
+	}
+	return state, expiry
+}
+
+// credentialsExpiry returns the credential of the repository which expires first, and its expiry, or nil if none of
+// its credentials expose their expiry
+func credentialsExpiry(repo *appsv1.Repository) (string, *time.Time, error) {
+	certExpiry, err := clientCertificateExpiry(repo)
+	if err != nil {
+		return "TLS client certificate", nil, err
+	}
+	tokenExpiry := passwordTokenExpiry(repo)
+	if tokenExpiry != nil && (certExpiry == nil || tokenExpiry.Before(*certExpiry)) {
+		return "token", tokenExpiry, nil
+	}
+	return "TLS client certificate", certExpiry, nil
+}
+
+// passwordTokenExpiry returns the expiry of the password of the repository if it is a JWT token with an expiry, e.g. an
+// access token of a registry, or nil otherwise
+func passwordTokenExpiry(repo *appsv1.Repository) *time.Time {
+	if strings.Count(repo.Password, ".") != 2 {
+		return nil
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser(jwt.WithoutClaimsValidation()).ParseUnverified(repo.Password, &claims); err != nil {
+		return nil
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil
+	}
+	expiry, err := jwtutil.ExpirationTime(claims)
+	if err != nil {
+		return nil
+	}
+	return &expiry
+}
+
+// clientCertificateExpiry returns the expiry of the TLS client certificate of the repository, or nil if it has none
+func clientCertificateExpiry(repo *appsv1.Repository) (*time.Time, error) {
+	if repo.TLSClientCertData == "" {
+		return nil, nil
+	}
+	block, _ := pem.Decode([]byte(repo.TLSClientCertData))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &cert.NotAfter, nil
+}
+
+// repoHost returns the host of a repository URL, which is the key of the revalidation rate limit
+func repoHost(repoURL string) string {
+	normalized := git.NormalizeGitURL(repoURL)
+	if u, err := url.Parse(normalized); err == nil && u.Host != "" {
+		return u.Host
+	}
+	// SCP-like SSH URLs and OCI registries have no scheme, e.g. git@github.com/org/repo
+	host := strings.SplitN(normalized, "/", 2)[0]
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return host
+}
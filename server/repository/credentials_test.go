@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	appsv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient/mocks"
+	dbmocks "github.com/argoproj/argo-cd/v2/util/db/mocks"
+	tlsutil "github.com/argoproj/argo-cd/v2/util/tls"
+)
+
+func TestRepoHost(t *testing.T) {
+	assert.Equal(t, "github.com", repoHost("https://github.com/argoproj/argo-cd.git"))
+	assert.Equal(t, "github.com:8443", repoHost("https://user@github.com:8443/argoproj/argo-cd"))
+	assert.Equal(t, "github.com", repoHost("git@github.com:argoproj/argo-cd.git"))
+	assert.Equal(t, "github.com", repoHost("ssh://git@github.com/argoproj/argo-cd.git"))
+	assert.Equal(t, "registry.example.com", repoHost("registry.example.com/charts"))
+}
+
+func TestCredentialsRevalidator_Revalidate(t *testing.T) {
+	cert, err := tlsutil.GenerateX509KeyPair(tlsutil.CertOptions{Hosts: []string{"localhost"}, Organization: "Acme", ValidFor: 24 * time.Hour, ECDSACurve: "P256"})
+	require.NoError(t, err)
+	certData, keyData := tlsutil.EncodeX509KeyPairString(*cert)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	repos := []*appsv1.Repository{
+		{Repo: "https://git.example.com/valid", Username: "user", Password: "valid"},
+		{Repo: "https://git.example.com/revoked", Username: "user", Password: "revoked"},
+		{Repo: "https://git.example.com/cert", TLSClientCertData: certData, TLSClientCertKey: keyData},
+		{Repo: "https://registry.example.com/token", Username: "user", Password: token},
+		{Repo: "https://git.example.com/public"},
+	}
+	kubeclientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "revoked", Namespace: testNamespace, Labels: map[string]string{common.LabelKeySecretType: common.LabelValueSecretTypeRepository}},
+		Data:       map[string][]byte{"url": []byte("https://git.example.com/revoked")},
+	})
+	db := &dbmocks.ArgoDB{}
+	db.On("ListRepositories", mock.Anything).Return(repos, nil)
+	for _, repo := range repos {
+		db.On("GetRepository", mock.Anything, repo.Repo).Return(repo, nil)
+	}
+	repoServerClient := mocks.RepoServerServiceClient{}
+	repoServerClient.On("TestRepository", mock.Anything, mock.MatchedBy(func(req *apiclient.TestRepositoryRequest) bool {
+		return req.Repo.Password == "revoked"
+	})).Return(nil, errors.New("authentication required"))
+	repoServerClient.On("TestRepository", mock.Anything, mock.Anything).Return(&apiclient.TestRepositoryResponse{}, nil)
+
+	cache := newFixtures().Cache
+	revalidator := NewCredentialsRevalidator(db, &mocks.Clientset{RepoServerServiceClient: &repoServerClient}, cache, nil, kubeclientset, testNamespace)
+	revalidator.interval = time.Hour
+	revalidator.hostLimit = rate.Inf
+	revalidator.Revalidate(context.Background())
+
+	state, err := cache.GetRepoCredentialState("https://git.example.com/valid")
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.ConnectionStatusSuccessful, state.Status)
+	assert.Empty(t, state.Message)
+
+	state, err = cache.GetRepoCredentialState("https://git.example.com/revoked")
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.ConnectionStatusFailed, state.Status)
+	assert.Contains(t, state.Message, "authentication required")
+
+	state, err = cache.GetRepoCredentialState("https://git.example.com/cert")
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.ConnectionStatusSuccessful, state.Status)
+	assert.Contains(t, state.Message, "TLS client certificate expires at")
+
+	state, err = cache.GetRepoCredentialState("https://registry.example.com/token")
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.ConnectionStatusSuccessful, state.Status)
+	assert.Contains(t, state.Message, "token expires at")
+
+	// the failure is recorded as an event of the secret of the repository
+	events, err := kubeclientset.CoreV1().Events(testNamespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, "revoked", events.Items[0].InvolvedObject.Name)
+	assert.Equal(t, EventReasonCredentialsInvalid, events.Items[0].Reason)
+
+	// unchanged states are not recorded again
+	revalidator.Revalidate(context.Background())
+	events, err = kubeclientset.CoreV1().Events(testNamespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, events.Items, 1)
+
+	// repositories without credentials are not revalidated
+	_, err = cache.GetRepoCredentialState("https://git.example.com/public")
+	assert.Error(t, err)
+
+	// expired certificates fail without connecting to the repository
+	revalidator.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	revalidator.Revalidate(context.Background())
+	state, err = cache.GetRepoCredentialState("https://git.example.com/cert")
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.ConnectionStatusFailed, state.Status)
+	assert.Contains(t, state.Message, "TLS client certificate expired at")
+}
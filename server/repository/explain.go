@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	repositorypkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/repository"
+	appsv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/server/rbacpolicy"
+	"github.com/argoproj/argo-cd/v2/util/db"
+	"github.com/argoproj/argo-cd/v2/util/git"
+)
+
+const (
+	credentialSourceInline   = "inline"
+	credentialSourceTemplate = "template"
+	proxySourceRepository    = "repository"
+	proxySourceEnvironment   = "api-server-environment"
+	sourceNone               = "none"
+)
+
+// ExplainAccess explains which credentials, certificates and proxy are used to access a repository. Secret values are
+// never part of the explanation.
+func (s *Server) ExplainAccess(ctx context.Context, q *repositorypkg.RepoQuery) (*repositorypkg.RepoAccessExplanation, error) {
+	if err := s.checkRepoURLPolicy(q.Repo); err != nil {
+		return nil, err
+	}
+	repo, err := s.getRepo(ctx, q.Repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionGet, repo); err != nil {
+		return nil, err
+	}
+	configured, err := s.db.RepositoryExists(ctx, q.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &repositorypkg.RepoAccessExplanation{
+		Repo:             repo.Repo,
+		Configured:       configured,
+		CredentialSource: sourceNone,
+		AuthMethods:      authMethods(repo),
+		Username:         repo.Username,
+		Insecure:         repo.IsInsecure(),
+	}
+	if repo.InheritedCreds {
+		explanation.CredentialSource = credentialSourceTemplate
+		creds, err := s.db.GetRepositoryCredentials(ctx, repo.Repo)
+		if err != nil {
+			return nil, err
+		}
+		if creds != nil {
+			explanation.CredentialTemplate = creds.URL
+		}
+	} else if repo.HasCredentials() || repo.GCPServiceAccountKey != "" {
+		explanation.CredentialSource = credentialSourceInline
+	}
+
+	host, isSSH := git.RepoHostName(repo.Repo)
+	if host != "" {
+		certType := "https"
+		if isSSH {
+			certType = "ssh"
+		}
+		certs, err := s.db.ListRepoCertificates(ctx, &db.CertificateListSelector{HostNamePattern: host, CertType: certType})
+		if err != nil {
+			return nil, err
+		}
+		for _, cert := range certs.Items {
+			info := fmt.Sprintf("%s %s", cert.CertSubType, cert.CertInfo)
+			if isSSH {
+				explanation.SshKnownHosts = append(explanation.SshKnownHosts, info)
+			} else {
+				explanation.TlsCertificates = append(explanation.TlsCertificates, info)
+			}
+		}
+	}
+
+	explanation.Proxy, explanation.ProxySource = explainProxy(repo, isSSH)
+	return explanation, nil
+}
+
+// authMethods returns the names of the authentication methods configured for a repository, in the order in which they
+// are considered when connecting to it
+func authMethods(repo *appsv1.Repository) []string {
+	var methods []string
+	if repo.Password != "" {
+		methods = append(methods, "basic-auth")
+	}
+	if repo.SSHPrivateKey != "" {
+		methods = append(methods, "ssh-private-key")
+	}
+	if repo.GithubAppPrivateKey != "" {
+		methods = append(methods, "github-app")
+	}
+	if repo.GCPServiceAccountKey != "" {
+		methods = append(methods, "gcp-service-account")
+	}
+	if repo.TLSClientCertData != "" {
+		methods = append(methods, "tls-client-cert")
+	}
+	return methods
+}
+
+// explainProxy returns the proxy used to access a repository, without credentials, and where it is configured. SSH
+// connections don't use a proxy. A proxy configured by environment variables is the one of the API server, which may
+// differ from the one of the repo-server.
+func explainProxy(repo *appsv1.Repository, isSSH bool) (string, string) {
+	if isSSH {
+		return "", sourceNone
+	}
+	if repo.Proxy != "" {
+		return stripURLCredentials(repo.Proxy), proxySourceRepository
+	}
+	repoURL := repo.Repo
+	if !strings.Contains(repoURL, "://") {
+		repoURL = "https://" + repoURL
+	}
+	req, err := http.NewRequest(http.MethodGet, repoURL, nil)
+	if err != nil {
+		return "", sourceNone
+	}
+	proxyURL, err := http.ProxyFromEnvironment(req)
+	if err != nil || proxyURL == nil {
+		return "", sourceNone
+	}
+	return stripURLCredentials(proxyURL.String()), proxySourceEnvironment
+}
+
+// stripURLCredentials removes the user info from a URL. URLs which cannot be parsed are omitted entirely, since they
+// might contain credentials.
+func stripURLCredentials(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	parsed.User = nil
+	return parsed.String()
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
+	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	"github.com/argoproj/gitops-engine/pkg/utils/text"
@@ -24,6 +26,7 @@ import (
 	"github.com/argoproj/argo-cd/v2/server/rbacpolicy"
 	"github.com/argoproj/argo-cd/v2/util/argo"
 	"github.com/argoproj/argo-cd/v2/util/db"
+	"github.com/argoproj/argo-cd/v2/util/env"
 	"github.com/argoproj/argo-cd/v2/util/errors"
 	"github.com/argoproj/argo-cd/v2/util/io"
 	"github.com/argoproj/argo-cd/v2/util/rbac"
@@ -67,6 +70,12 @@ func NewServer(
 
 var (
 	errPermissionDenied = status.Error(codes.PermissionDenied, "permission denied")
+
+	// connectionTestTimeout bounds a single repository connection test, so that unresponsive repositories don't
+	// block the listing of all repositories. Unlimited if 0
+	connectionTestTimeout = env.ParseDurationFromEnv(common.EnvRepoConnectionTestTimeout, 30*time.Second, 0, math.MaxInt64)
+	// connectionTestParallelism is the maximum number of connection tests run concurrently by ListRepositories
+	connectionTestParallelism = env.ParseNumFromEnv(common.EnvRepoConnectionTestParallelism, 10, 1, math.MaxInt32)
 )
 
 func (s *Server) getRepo(ctx context.Context, url string) (*appsv1.Repository, error) {
@@ -77,6 +86,53 @@ func (s *Server) getRepo(ctx context.Context, url string) (*appsv1.Repository, e
 	return repo, nil
 }
 
+func repoNotFoundError(url string) error {
+	return status.Errorf(codes.NotFound, "repo '%s' not found", url)
+}
+
+// enforceRepo checks whether the user is permitted to perform the given action on the given repository. If the
+// existence of repositories is hidden, unauthorized requests fail with the same error as requests for unknown ones.
+func (s *Server) enforceRepo(ctx context.Context, action string, repo *appsv1.Repository) error {
+	err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceRepositories, action, createRBACObject(repo.Project, repo.Repo))
+	if err == nil {
+		return nil
+	}
+	hideExistence, settingsErr := s.settings.GetServerRepositoryHideExistence()
+	if settingsErr != nil {
+		log.Warnf("failed to get repository existence setting: %v", settingsErr)
+	}
+	if hideExistence {
+		return repoNotFoundError(repo.Repo)
+	}
+	return err
+}
+
+// checkRepoURLPolicy returns a PermissionDenied error if the repository URL is not permitted by the instance-wide
+// repository allow and deny lists
+func (s *Server) checkRepoURLPolicy(url string) error {
+	policy, err := s.settings.GetRepositoryURLPolicy()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get repository policy: %v", err)
+	}
+	if !policy.IsPermitted(url) {
+		return status.Errorf(codes.PermissionDenied, "repository '%s' is not permitted by the repository policy", url)
+	}
+	return nil
+}
+
+// ensureRepoExists returns a NotFound error if the given repository is not configured. getRepo doesn't return an error
+// for unconfigured repositories, so this needs to be checked explicitly.
+func (s *Server) ensureRepoExists(ctx context.Context, url string) error {
+	exists, err := s.db.RepositoryExists(ctx, url)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return repoNotFoundError(url)
+	}
+	return nil
+}
+
 func createRBACObject(project string, repo string) string {
 	if project != "" {
 		return project + "/" + repo
@@ -99,15 +155,24 @@ func (s *Server) getConnectionState(ctx context.Context, url string, forceRefres
 		ModifiedAt: &now,
 	}
 	var err error
+	var timedOut bool
 	repo, err := s.db.GetRepository(ctx, url)
 	if err == nil {
-		err = s.testRepo(ctx, repo)
+		testCtx, cancel := ctx, func() {}
+		if connectionTestTimeout > 0 {
+			testCtx, cancel = context.WithTimeout(ctx, connectionTestTimeout)
+		}
+		err = s.testRepo(testCtx, repo)
+		timedOut = testCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
 	}
 	if err != nil {
 		connectionState.Status = appsv1.ConnectionStatusFailed
 		if errors.IsCredentialsConfigurationError(err) {
 			connectionState.Message = "Configuration error - please check the server logs"
 			log.Warnf("could not retrieve repo: %s", err.Error())
+		} else if timedOut {
+			connectionState.Message = fmt.Sprintf("Unable to connect to repository: connection test timed out after %v", connectionTestTimeout)
 		} else {
 			connectionState.Message = fmt.Sprintf("Unable to connect to repository: %v", err)
 		}
@@ -119,6 +184,16 @@ func (s *Server) getConnectionState(ctx context.Context, url string, forceRefres
 	return connectionState
 }
 
+// getCredentialState returns the result of the last revalidation of the credentials of the repository, or nil if the
+// credentials weren't revalidated yet
+func (s *Server) getCredentialState(url string) *appsv1.ConnectionState {
+	state, err := s.cache.GetRepoCredentialState(url)
+	if err != nil {
+		return nil
+	}
+	return &state
+}
+
 // List returns list of repositories
 // Deprecated: Use ListRepositories instead
 func (s *Server) List(ctx context.Context, q *repositorypkg.RepoQuery) (*appsv1.RepositoryList, error) {
@@ -132,18 +207,13 @@ func (s *Server) Get(ctx context.Context, q *repositorypkg.RepoQuery) (*appsv1.R
 		return nil, err
 	}
 
-	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceRepositories, rbacpolicy.ActionGet, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionGet, repo); err != nil {
 		return nil, err
 	}
 
-	// getRepo does not return an error for unconfigured repositories, so we are checking here
-	exists, err := s.db.RepositoryExists(ctx, q.Repo)
-	if err != nil {
+	if err := s.ensureRepoExists(ctx, q.Repo); err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, status.Errorf(codes.NotFound, "repo '%s' not found", q.Repo)
-	}
 
 	// For backwards compatibility, if we have no repo type set assume a default
 	rType := repo.Type
@@ -164,9 +234,14 @@ func (s *Server) Get(ctx context.Context, q *repositorypkg.RepoQuery) (*appsv1.R
 		Proxy:                      repo.Proxy,
 		Project:                    repo.Project,
 		InheritedCreds:             repo.InheritedCreds,
+		CloneDepth:                 repo.CloneDepth,
+		CloneFilter:                repo.CloneFilter,
+		EnableSubmodules:           repo.EnableSubmodules,
+		SkipSubmoduleCreds:         repo.SkipSubmoduleCreds,
 	}
 
 	item.ConnectionState = s.getConnectionState(ctx, item.Repo, q.ForceRefresh)
+	item.CredentialState = s.getCredentialState(item.Repo)
 
 	return &item, nil
 }
@@ -198,10 +273,21 @@ func (s *Server) ListRepositories(ctx context.Context, q *repositorypkg.RepoQuer
 				Project:            repo.Project,
 				ForceHttpBasicAuth: repo.ForceHttpBasicAuth,
 				InheritedCreds:     repo.InheritedCreds,
+				CloneDepth:         repo.CloneDepth,
+				CloneFilter:        repo.CloneFilter,
+				EnableSubmodules:   repo.EnableSubmodules,
+				SkipSubmoduleCreds: repo.SkipSubmoduleCreds,
+				CredentialState:    s.getCredentialState(repo.Repo),
 			})
 		}
 	}
+	if q.SkipConnectionState {
+		return &appsv1.RepositoryList{Items: items}, nil
+	}
+	sem := make(chan struct{}, connectionTestParallelism)
 	err = kube.RunAllAsync(len(items), func(i int) error {
+		sem <- struct{}{}
+		defer func() { <-sem }()
 		items[i].ConnectionState = s.getConnectionState(ctx, items[i].Repo, q.ForceRefresh)
 		return nil
 	})
@@ -217,7 +303,7 @@ func (s *Server) ListRefs(ctx context.Context, q *repositorypkg.RepoQuery) (*api
 		return nil, err
 	}
 
-	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceRepositories, rbacpolicy.ActionGet, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionGet, repo); err != nil {
 		return nil, err
 	}
 
@@ -241,7 +327,7 @@ func (s *Server) ListApps(ctx context.Context, q *repositorypkg.RepoAppsQuery) (
 	}
 
 	claims := ctx.Value("claims")
-	if err := s.enf.EnforceErr(claims, rbacpolicy.ResourceRepositories, rbacpolicy.ActionGet, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionGet, repo); err != nil {
 		return nil, err
 	}
 
@@ -291,7 +377,7 @@ func (s *Server) GetAppDetails(ctx context.Context, q *repositorypkg.RepoAppDeta
 		return nil, err
 	}
 	claims := ctx.Value("claims")
-	if err := s.enf.EnforceErr(claims, rbacpolicy.ResourceRepositories, rbacpolicy.ActionGet, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionGet, repo); err != nil {
 		return nil, err
 	}
 	appName, appNs := argo.ParseAppQualifiedName(q.AppName, s.settings.GetNamespace())
@@ -322,6 +408,15 @@ func (s *Server) GetAppDetails(ctx context.Context, q *repositorypkg.RepoAppDeta
 		return nil, err
 	}
 
+	if app != nil {
+		var cancel context.CancelFunc
+		ctx, cancel, err = argo.WithManifestGenerateTimeout(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+	}
+
 	conn, repoClient, err := s.repoClientset.NewRepoServerClient()
 	if err != nil {
 		return nil, err
@@ -343,13 +438,19 @@ func (s *Server) GetAppDetails(ctx context.Context, q *repositorypkg.RepoAppDeta
 	if err != nil {
 		return nil, err
 	}
+	repoURLPolicy, err := s.settings.GetRepositoryURLPolicy()
+	if err != nil {
+		return nil, err
+	}
 	return repoClient.GetAppDetails(ctx, &apiclient.RepoServerAppDetailsQuery{
-		Repo:             repo,
-		Source:           q.Source,
-		Repos:            helmRepos,
-		KustomizeOptions: kustomizeOptions,
-		HelmOptions:      helmOptions,
-		AppName:          q.AppName,
+		Repo:                repo,
+		Source:              q.Source,
+		Repos:               helmRepos,
+		KustomizeOptions:    kustomizeOptions,
+		HelmOptions:         helmOptions,
+		AppName:             q.AppName,
+		RepositoryAllowList: repoURLPolicy.Allow,
+		RepositoryDenyList:  repoURLPolicy.Deny,
 	})
 }
 
@@ -359,7 +460,7 @@ func (s *Server) GetHelmCharts(ctx context.Context, q *repositorypkg.RepoQuery)
 	if err != nil {
 		return nil, err
 	}
-	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceRepositories, rbacpolicy.ActionGet, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionGet, repo); err != nil {
 		return nil, err
 	}
 	conn, repoClient, err := s.repoClientset.NewRepoServerClient()
@@ -386,6 +487,10 @@ func (s *Server) CreateRepository(ctx context.Context, q *repositorypkg.RepoCrea
 		return nil, err
 	}
 
+	if err := s.checkRepoURLPolicy(q.Repo.Repo); err != nil {
+		return nil, err
+	}
+
 	var repo *appsv1.Repository
 	var err error
 
@@ -452,7 +557,7 @@ func (s *Server) UpdateRepository(ctx context.Context, q *repositorypkg.RepoUpda
 	}
 
 	// verify that user can do update inside project where repository is located
-	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceRepositories, rbacpolicy.ActionUpdate, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionUpdate, repo); err != nil {
 		return nil, err
 	}
 	// verify that user can do update inside project where repository will be located
@@ -476,7 +581,11 @@ func (s *Server) DeleteRepository(ctx context.Context, q *repositorypkg.RepoQuer
 		return nil, err
 	}
 
-	if err := s.enf.EnforceErr(ctx.Value("claims"), rbacpolicy.ResourceRepositories, rbacpolicy.ActionDelete, createRBACObject(repo.Project, repo.Repo)); err != nil {
+	if err := s.enforceRepo(ctx, rbacpolicy.ActionDelete, repo); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureRepoExists(ctx, q.Repo); err != nil {
 		return nil, err
 	}
 
@@ -496,6 +605,10 @@ func (s *Server) ValidateAccess(ctx context.Context, q *repositorypkg.RepoAccess
 		return nil, err
 	}
 
+	if err := s.checkRepoURLPolicy(q.Repo); err != nil {
+		return nil, err
+	}
+
 	repo := &appsv1.Repository{
 		Repo:                       q.Repo,
 		Type:                       q.Type,
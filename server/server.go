@@ -49,6 +49,7 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -99,6 +100,7 @@ import (
 	"github.com/argoproj/argo-cd/v2/server/settings"
 	"github.com/argoproj/argo-cd/v2/server/version"
 	"github.com/argoproj/argo-cd/v2/ui"
+	"github.com/argoproj/argo-cd/v2/util/agent"
 	"github.com/argoproj/argo-cd/v2/util/assets"
 	cacheutil "github.com/argoproj/argo-cd/v2/util/cache"
 	"github.com/argoproj/argo-cd/v2/util/db"
@@ -129,6 +131,9 @@ const maxConcurrentLoginRequestsCountEnv = "ARGOCD_MAX_CONCURRENT_LOGIN_REQUESTS
 const replicasCountEnv = "ARGOCD_API_SERVER_REPLICAS"
 const renewTokenKey = "renew-token"
 
+// repoServerLoadReportInterval is the interval in which the load report of the repo-server is retrieved
+const repoServerLoadReportInterval = 30 * time.Second
+
 // ErrNoSession indicates no auth token was supplied as part of a request
 var ErrNoSession = status.Errorf(codes.Unauthenticated, "no session information")
 
@@ -194,6 +199,8 @@ type ArgoCDServer struct {
 	secretInformer    cache.SharedIndexInformer
 	configMapInformer cache.SharedIndexInformer
 	serviceSet        *ArgoCDServiceSet
+	agentTunnel       *agent.Server
+	agentProxyInit    gosync.Once
 }
 
 type ArgoCDServerOpts struct {
@@ -220,6 +227,10 @@ type ArgoCDServerOpts struct {
 	ContentSecurityPolicy string
 	ApplicationNamespaces []string
 	EnableProxyExtension  bool
+	WebhookCacheWarming   bool
+	EnableAgentTunnel     bool
+	AgentProxyListenHost  string
+	AgentProxyPort        int
 }
 
 // initializeDefaultProject creates the default project if it does not already exist
@@ -291,7 +302,7 @@ func NewServer(ctx context.Context, opts ArgoCDServerOpts) *ArgoCDServer {
 
 	apiFactory := api.NewFactory(settings_notif.GetFactorySettings(argocdService, "argocd-notifications-secret", "argocd-notifications-cm"), opts.Namespace, secretInformer, configMapInformer)
 
-	return &ArgoCDServer{
+	a := &ArgoCDServer{
 		ArgoCDServerOpts:  opts,
 		log:               log.NewEntry(log.StandardLogger()),
 		settings:          settings,
@@ -312,6 +323,10 @@ func NewServer(ctx context.Context, opts ArgoCDServerOpts) *ArgoCDServer {
 		secretInformer:    secretInformer,
 		configMapInformer: configMapInformer,
 	}
+	if opts.EnableAgentTunnel {
+		a.agentTunnel = agent.NewServer(a.authorizeAgent)
+	}
+	return a
 }
 
 const (
@@ -333,6 +348,7 @@ func (a *ArgoCDServer) healthCheck(r *http.Request) error {
 type Listeners struct {
 	Main        net.Listener
 	Metrics     net.Listener
+	AgentProxy  net.Listener
 	GatewayConn *grpc.ClientConn
 }
 
@@ -349,6 +365,12 @@ func (l *Listeners) Close() error {
 		}
 		l.Metrics = nil
 	}
+	if l.AgentProxy != nil {
+		if err := l.AgentProxy.Close(); err != nil {
+			return err
+		}
+		l.AgentProxy = nil
+	}
 	if l.GatewayConn != nil {
 		if err := l.GatewayConn.Close(); err != nil {
 			return err
@@ -401,13 +423,25 @@ func (a *ArgoCDServer) Listen() (*Listeners, error) {
 	} else {
 		dOpts = append(dOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
+	var agentProxyLn net.Listener
+	if a.agentTunnel != nil {
+		agentProxyLn, err = startListener(a.AgentProxyListenHost, a.AgentProxyPort)
+		if err != nil {
+			io.Close(mainLn)
+			io.Close(metricsLn)
+			return nil, err
+		}
+	}
 	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", a.ListenPort), dOpts...)
 	if err != nil {
 		io.Close(mainLn)
 		io.Close(metricsLn)
+		if agentProxyLn != nil {
+			io.Close(agentProxyLn)
+		}
 		return nil, err
 	}
-	return &Listeners{Main: mainLn, Metrics: metricsLn, GatewayConn: conn}, nil
+	return &Listeners{Main: mainLn, Metrics: metricsLn, AgentProxy: agentProxyLn, GatewayConn: conn}, nil
 }
 
 // Init starts informers used by the API server
@@ -497,8 +531,19 @@ func (a *ArgoCDServer) Run(ctx context.Context, listeners *Listeners) {
 	}
 	go a.watchSettings()
 	go a.rbacPolicyLoader(ctx)
+	go a.watchRepoServerLoad(ctx, metricsServ)
+	go repository.NewCredentialsRevalidator(a.db, a.RepoClientset, a.Cache, metricsServ, a.KubeClientset, a.Namespace).RunWithLeaderElection(ctx)
 	go func() { a.checkServeErr("tcpm", tcpm.Serve()) }()
 	go func() { a.checkServeErr("metrics", metricsServ.Serve(listeners.Metrics)) }()
+	if listeners.AgentProxy != nil {
+		// agent connections outlive restarts of the server, so the proxy is only started once
+		a.agentProxyInit.Do(func() {
+			log.Infof("argocd agent proxy serving on %s:%d", a.AgentProxyListenHost, a.AgentProxyPort)
+			go func() {
+				a.checkServeErr("agentProxy", (&http.Server{Handler: a.agentTunnel.ProxyHandler(a.authorizeAgentProxy)}).Serve(listeners.AgentProxy))
+			}()
+		})
+	}
 	if !cache.WaitForCacheSync(ctx.Done(), a.projInformer.HasSynced, a.appInformer.HasSynced) {
 		log.Fatal("Timed out waiting for project cache to sync")
 	}
@@ -551,6 +596,85 @@ func checkOIDCConfigChange(currentOIDCConfig *settings_util.OIDCConfig, newArgoC
 	return false
 }
 
+// authorizeAgent allows an agent to register for the cluster with the given name if the caller may update the cluster
+func (a *ArgoCDServer) authorizeAgent(r *http.Request, name string) error {
+	return a.enforceAgentCluster(r.Context(), r.Context().Value("claims"), name, rbacpolicy.ActionUpdate)
+}
+
+// authorizeAgentProxy allows connections to the cluster with the given name through the agent proxy if the token
+// of the proxy authorization may update the cluster. Getting the cluster isn't sufficient, since read-only roles may
+// get all clusters, but the proxy grants raw access to the Kubernetes API server of the cluster.
+func (a *ArgoCDServer) authorizeAgentProxy(r *http.Request, name string) error {
+	var claims jwt.Claims
+	if !a.DisableAuth {
+		token := agent.ProxyAuthToken(r)
+		if token == "" {
+			return fmt.Errorf("proxy authorization is required")
+		}
+		var err error
+		claims, _, err = a.sessionMgr.VerifyToken(token)
+		if err != nil {
+			return fmt.Errorf("invalid token: %w", err)
+		}
+	}
+	return a.enforceAgentCluster(r.Context(), claims, name, rbacpolicy.ActionUpdate)
+}
+
+// enforceAgentCluster returns an error if the claims don't allow the action on the cluster with the given name
+func (a *ArgoCDServer) enforceAgentCluster(ctx context.Context, claims interface{}, name string, action string) error {
+	servers, err := a.db.GetClusterServersByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("error getting cluster %s: %w", name, err)
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("cluster %s does not exist", name)
+	}
+	clust, err := a.db.GetCluster(ctx, servers[0])
+	if err != nil {
+		return fmt.Errorf("error getting cluster %s: %w", name, err)
+	}
+	if !a.enf.Enforce(claims, rbacpolicy.ResourceClusters, action, cluster.CreateClusterRBACObject(clust.Project, clust.Server)) {
+		return fmt.Errorf("%w: %s, %s, %s", agent.ErrPermissionDenied, rbacpolicy.ResourceClusters, action, clust.Server)
+	}
+	return nil
+}
+
+// watchRepoServerLoad periodically retrieves the load report of the repo-server and exposes it as metrics.
+func (a *ArgoCDServer) watchRepoServerLoad(ctx context.Context, metricsServ *metrics.MetricsServer) {
+	if a.RepoClientset == nil {
+		return
+	}
+	ticker := time.NewTicker(repoServerLoadReportInterval)
+	defer ticker.Stop()
+	for {
+		a.reportRepoServerLoad(ctx, metricsServ)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *ArgoCDServer) reportRepoServerLoad(ctx context.Context, metricsServ *metrics.MetricsServer) {
+	conn, repoClient, err := a.RepoClientset.NewRepoServerClient()
+	if err != nil {
+		log.Warnf("Failed to create repo-server client: %v", err)
+		return
+	}
+	defer io.Close(conn)
+	report, err := repoClient.GetLoadReport(ctx, &emptypb.Empty{})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			log.Debugf("Repo-server does not support load reports: %v", err)
+		} else {
+			log.Warnf("Failed to retrieve repo-server load report: %v", err)
+		}
+		return
+	}
+	metricsServ.ObserveRepoServerLoad(report, time.Now())
+}
+
 // watchSettings watches the configmap and secret for any setting updates that would warrant a
 // restart of the API server.
 func (a *ArgoCDServer) watchSettings() {
@@ -781,7 +905,7 @@ func newArgoCDServiceSet(a *ArgoCDServer) *ArgoCDServiceSet {
 		a.ApplicationNamespaces)
 
 	applicationSetService := applicationset.NewServer(a.db, a.KubeClientset, a.enf, a.Cache, a.AppClientset, a.appLister, a.appsetInformer, a.appsetLister, a.projLister, a.settingsMgr, a.Namespace, projectLock)
-	projectService := project.NewServer(a.Namespace, a.KubeClientset, a.AppClientset, a.enf, projectLock, a.sessionMgr, a.policyEnforcer, a.projInformer, a.settingsMgr, a.db)
+	projectService := project.NewServer(a.Namespace, a.KubeClientset, a.AppClientset, a.enf, projectLock, a.sessionMgr, a.policyEnforcer, a.projInformer, a.settingsMgr, a.db, a.ApplicationNamespaces)
 	appsInAnyNamespaceEnabled := len(a.ArgoCDServerOpts.ApplicationNamespaces) > 0
 	settingsService := settings.NewServer(a.settingsMgr, a.RepoClientset, a, a.DisableAuth, appsInAnyNamespaceEnabled)
 	accountService := account.NewServer(a.sessionMgr, a.settingsMgr, a.enf)
@@ -885,7 +1009,7 @@ func (a *ArgoCDServer) newHTTPServer(ctx context.Context, port int, grpcWebHandl
 		Handler: &handlerSwitcher{
 			handler: mux,
 			urlToHandler: map[string]http.Handler{
-				"/api/badge":          badge.NewHandler(a.AppClientset, a.settingsMgr, a.Namespace),
+				"/api/badge":          badge.NewHandler(a.AppClientset, a.settingsMgr, a.Namespace, a.ApplicationNamespaces),
 				common.LogoutEndpoint: logout.NewHandler(a.AppClientset, a.settingsMgr, a.sessionMgr, a.ArgoCDServerOpts.RootPath, a.ArgoCDServerOpts.BaseHRef, a.Namespace),
 			},
 			contentTypeToHandler: map[string]http.Handler{
@@ -910,11 +1034,17 @@ func (a *ArgoCDServer) newHTTPServer(ctx context.Context, port int, grpcWebHandl
 	}
 	mux.Handle("/api/", handler)
 
-	terminal := application.NewHandler(a.appLister, a.Namespace, a.ApplicationNamespaces, a.db, a.enf, a.Cache, appResourceTreeFn, a.settings.ExecShells).
+	terminal := application.NewHandler(a.appLister, applisters.NewAppProjectLister(a.projInformer.GetIndexer()), a.settingsMgr, a.Namespace, a.ApplicationNamespaces, a.db, a.enf, a.Cache, appResourceTreeFn, a.settings.ExecShells).
 		WithFeatureFlagMiddleware(a.settingsMgr.GetSettings)
 	th := util_session.WithAuthMiddleware(a.DisableAuth, a.sessionMgr, terminal)
 	mux.Handle("/terminal", th)
 
+	if a.agentTunnel != nil {
+		agentHandler := util_session.WithAuthMiddleware(a.DisableAuth, a.sessionMgr, a.agentTunnel)
+		mux.Handle(agent.ConnectPath, agentHandler)
+		mux.Handle(agent.TunnelPath, agentHandler)
+	}
+
 	// Proxy extension is currently an alpha feature and is disabled
 	// by default.
 	if a.EnableProxyExtension {
@@ -947,6 +1077,9 @@ func (a *ArgoCDServer) newHTTPServer(ctx context.Context, port int, grpcWebHandl
 	// Webhook handler for git events (Note: cache timeouts are hardcoded because API server does not write to cache and not really using them)
 	argoDB := db.NewDB(a.Namespace, a.settingsMgr, a.KubeClientset)
 	acdWebhookHandler := webhook.NewHandler(a.Namespace, a.ArgoCDServerOpts.ApplicationNamespaces, a.AppClientset, a.settings, a.settingsMgr, repocache.NewCache(a.Cache.GetCache(), 24*time.Hour, 3*time.Minute), a.Cache, argoDB)
+	if warmer, ok := a.serviceSet.ApplicationService.(webhook.ManifestCacheWarmer); ok && a.WebhookCacheWarming {
+		acdWebhookHandler = acdWebhookHandler.WithManifestCacheWarmer(ctx, warmer)
+	}
 
 	mux.HandleFunc("/api/webhook", acdWebhookHandler.Handler)
 
@@ -14,8 +14,11 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	"sigs.k8s.io/yaml"
@@ -29,6 +32,7 @@ import (
 	servercache "github.com/argoproj/argo-cd/v2/server/cache"
 	"github.com/argoproj/argo-cd/v2/server/rbacpolicy"
 	"github.com/argoproj/argo-cd/v2/test"
+	"github.com/argoproj/argo-cd/v2/util/agent"
 	"github.com/argoproj/argo-cd/v2/util/assets"
 	cacheutil "github.com/argoproj/argo-cd/v2/util/cache"
 	appstatecache "github.com/argoproj/argo-cd/v2/util/cache/appstate"
@@ -44,7 +48,9 @@ func fakeServer() (*ArgoCDServer, func()) {
 	appClientSet := apps.NewSimpleClientset()
 	redis, closer := test.NewInMemoryRedis()
 	port, err := test.GetFreePort()
-	mockRepoClient := &mocks.Clientset{RepoServerServiceClient: &mocks.RepoServerServiceClient{}}
+	repoServerClient := &mocks.RepoServerServiceClient{}
+	repoServerClient.On("GetLoadReport", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unimplemented, "not implemented"))
+	mockRepoClient := &mocks.Clientset{RepoServerServiceClient: repoServerClient}
 
 	if err != nil {
 		panic(err)
@@ -1349,3 +1355,41 @@ func TestReplaceBaseHRef(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizeAgentProxy(t *testing.T) {
+	cm := test.NewFakeConfigMap()
+	cm.Data["accounts.reader"] = "login"
+	kubeclientset := fake.NewSimpleClientset(cm, test.NewFakeSecret())
+	mockRepoClient := &mocks.Clientset{RepoServerServiceClient: &mocks.RepoServerServiceClient{}}
+	s := NewServer(context.Background(), ArgoCDServerOpts{Namespace: test.FakeArgoCDNamespace, KubeClientset: kubeclientset, AppClientset: apps.NewSimpleClientset(), RepoClientset: mockRepoClient})
+	require.NoError(t, s.enf.SetUserPolicy("g, reader, role:readonly"))
+	proxy := httptest.NewServer(agent.NewServer(nil).ProxyHandler(s.authorizeAgentProxy))
+	defer proxy.Close()
+
+	connect := func(t *testing.T, subject string) int {
+		req, err := http.NewRequest(http.MethodConnect, proxy.URL, nil)
+		require.NoError(t, err)
+		req.Host = "in-cluster:443"
+		if subject != "" {
+			token, err := s.sessionMgr.Create(subject+":login", 0, "token-id")
+			require.NoError(t, err)
+			req.Header.Set("Proxy-Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("NoToken", func(t *testing.T) {
+		assert.Equal(t, http.StatusProxyAuthRequired, connect(t, ""))
+	})
+	t.Run("ReadOnly", func(t *testing.T) {
+		// read-only roles may get all clusters, which must not allow raw access to them
+		assert.Equal(t, http.StatusForbidden, connect(t, "reader"))
+	})
+	t.Run("Admin", func(t *testing.T) {
+		// the request is authorized, but fails since no agent is connected for the cluster
+		assert.Equal(t, http.StatusBadGateway, connect(t, "admin"))
+	})
+}
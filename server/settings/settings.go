@@ -88,6 +88,15 @@ func (s *Server) Get(ctx context.Context, q *settingspkg.SettingsQuery) (*settin
 		return nil, err
 	}
 
+	featureFlags, err := s.mgr.GetFeatureFlags()
+	if err != nil {
+		return nil, err
+	}
+	flags := make([]*v1alpha1.FeatureFlag, 0, len(featureFlags))
+	for i := range featureFlags {
+		flags = append(flags, &featureFlags[i])
+	}
+
 	set := settingspkg.Settings{
 		URL:                argoCDSettings.URL,
 		AppLabelKey:        appInstanceLabelKey,
@@ -113,6 +122,7 @@ func (s *Server) Get(ctx context.Context, q *settingspkg.SettingsQuery) (*settin
 		TrackingMethod:            trackingMethod,
 		ExecEnabled:               argoCDSettings.ExecEnabled,
 		AppsInAnyNamespaceEnabled: s.appsInAnyNamespaceEnabled,
+		FeatureFlags:              flags,
 	}
 
 	if sessionmgr.LoggedIn(ctx) || s.disableAuth {
@@ -54,6 +54,7 @@ export const ApplicationDeploymentHistory = ({
                             <div className='columns small-3'>Revision:</div>
                             <div className='columns small-9'>
                                 <Revision repoUrl={info.source.repoURL} revision={info.revision} />
+                                {info.manualRevision && <span title='Deployed by a rollback to an explicitly chosen revision'> (manual revision)</span>}
                                 <div className='application-deployment-history__item-menu'>
                                     <DropDownMenu
                                         anchor={() => (
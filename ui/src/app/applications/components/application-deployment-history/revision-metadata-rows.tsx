@@ -32,7 +32,25 @@ export const RevisionMetadataRows = (props: {applicationName: string; applicatio
                                 <div className='columns small-9'>{m.description}</div>
                             </div>
                         )}
-                        {m.maintainers.length > 0 && (
+                        {m.appVersion && (
+                            <div className='row'>
+                                <div className='columns small-3'>App Version:</div>
+                                <div className='columns small-9'>{m.appVersion}</div>
+                            </div>
+                        )}
+                        {m.repoURL && (
+                            <div className='row'>
+                                <div className='columns small-3'>Repository:</div>
+                                <div className='columns small-9'>{m.repoURL}</div>
+                            </div>
+                        )}
+                        {m.digest && (
+                            <div className='row'>
+                                <div className='columns small-3'>Digest:</div>
+                                <div className='columns small-9'>{m.digest}</div>
+                            </div>
+                        )}
+                        {m.maintainers?.length > 0 && (
                             <div className='row'>
                                 <div className='columns small-3'>Maintainers:</div>
                                 <div className='columns small-9'>{m.maintainers.join(', ')}</div>
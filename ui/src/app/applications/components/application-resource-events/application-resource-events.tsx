@@ -7,11 +7,7 @@ import {services} from '../../../shared/services';
 export const ApplicationResourceEvents = (props: {applicationName: string; applicationNamespace: string; resource?: {namespace: string; name: string; uid: string}}) => (
     <div className='application-resource-events'>
         <DataLoader
-            load={() =>
-                props.resource
-                    ? services.applications.resourceEvents(props.applicationName, props.applicationNamespace, props.resource)
-                    : services.applications.events(props.applicationName, props.applicationNamespace)
-            }
+            load={() => services.applications.watchEvents(props.applicationName, props.applicationNamespace, props.resource)}
             loadingRenderer={() => <MockupList height={50} marginTop={10} />}>
             {events => <EventsList events={events} />}
         </DataLoader>
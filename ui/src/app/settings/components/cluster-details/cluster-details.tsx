@@ -165,6 +165,12 @@ export const ClusterDetails = (props: RouteComponentProps<{server: string}>) =>
                                     <div className='columns small-3'>APPLICATIONS COUNT:</div>
                                     <div className='columns small-9'> {cluster.info.applicationsCount} </div>
                                 </div>
+                                {cluster.info.shard !== undefined && (
+                                    <div className='row white-box__details-row'>
+                                        <div className='columns small-3'>CONTROLLER SHARD:</div>
+                                        <div className='columns small-9'> {cluster.info.shard} </div>
+                                    </div>
+                                )}
                             </div>
                         </div>
                     </div>
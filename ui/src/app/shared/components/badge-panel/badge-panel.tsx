@@ -6,7 +6,7 @@ import {Context} from '../../context';
 
 require('./badge-panel.scss');
 
-export const BadgePanel = ({app, project}: {app?: string; project?: string}) => {
+export const BadgePanel = ({app, appNamespace, project}: {app?: string; appNamespace?: string; project?: string}) => {
     const [badgeType, setBadgeType] = React.useState('URL');
     const context = React.useContext(Context);
     if (!app && !project) {
@@ -21,6 +21,10 @@ export const BadgePanel = ({app, project}: {app?: string; project?: string}) =>
         if (app) {
             badgeURL = `${root}api/badge?name=${app}&revision=true`;
             entityURL = `${root}applications/${app}`;
+            if (appNamespace) {
+                badgeURL = `${root}api/badge?name=${app}&namespace=${appNamespace}&revision=true`;
+                entityURL = `${root}applications/${appNamespace}/${app}`;
+            }
             alt = 'App Status';
         } else if (project) {
             badgeURL = `${root}api/badge?project=${project}&revision=true`;
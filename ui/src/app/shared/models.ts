@@ -98,6 +98,9 @@ export interface ChartDetails {
     description?: string;
     maintainers?: string[];
     home?: string;
+    appVersion?: string;
+    digest?: string;
+    repoURL?: string;
 }
 
 export interface SyncOperationResult {
@@ -168,6 +171,10 @@ export interface ApplicationDestination {
      * Name of the destination cluster which can be used instead of server (url) field
      */
     name: string;
+    /**
+     * Label selector matching exactly one cluster which can be used instead of server (url) and name fields
+     */
+    clusterSelector?: string;
 }
 
 export interface OrphanedResource {
@@ -296,6 +303,8 @@ export interface RevisionHistory {
     sources: ApplicationSource[];
     deployStartedAt: models.Time;
     deployedAt: models.Time;
+    charts?: ChartDetails[];
+    manualRevision?: boolean;
 }
 
 export type SyncStatusCode = 'Unknown' | 'Synced' | 'OutOfSync';
@@ -558,6 +567,7 @@ export interface Cluster {
     info?: {
         applicationsCount: number;
         serverVersion: string;
+        shard?: number;
         connectionState: ConnectionState;
         cacheInfo: ClusterCacheInfo;
     };
@@ -614,6 +624,7 @@ export interface HelmAppSpec {
     path: string;
     valueFiles: string[];
     values?: string;
+    valuesSchema?: string;
     parameters: HelmParameter[];
     fileParameters: HelmFileParameter[];
 }
@@ -1,6 +1,6 @@
 import * as deepMerge from 'deepmerge';
-import {Observable} from 'rxjs';
-import {map, repeat, retry} from 'rxjs/operators';
+import {from, Observable} from 'rxjs';
+import {map, mergeMap, repeat, retry, scan, startWith} from 'rxjs/operators';
 
 import * as models from '../models';
 import {isValidURL} from '../utils';
@@ -397,6 +397,39 @@ export class ApplicationsService {
             .then(res => (res.body as models.EventList).items || []);
     }
 
+    public watchEvents(
+        applicationName: string,
+        appNamespace: string,
+        resource?: {
+            namespace: string;
+            name: string;
+            uid: string;
+        }
+    ): Observable<models.Event[]> {
+        const search = new URLSearchParams();
+        search.set('appNamespace', appNamespace);
+        if (resource) {
+            search.set('resourceUID', resource.uid);
+            search.set('resourceNamespace', resource.namespace);
+            search.set('resourceName', resource.name);
+        }
+        const initial = resource ? this.resourceEvents(applicationName, appNamespace, resource) : this.events(applicationName, appNamespace);
+        return from(initial).pipe(
+            mergeMap(events =>
+                requests
+                    .loadEventSource(`/stream/applications/${applicationName}/events?${search.toString()}`)
+                    .pipe(repeat())
+                    .pipe(retry())
+                    .pipe(map(data => JSON.parse(data).result as models.Event))
+                    .pipe(
+                        // updated events replace the previous version of the event
+                        scan((current, event) => [...current.filter(item => item.metadata.uid !== event.metadata.uid), event], events),
+                        startWith(events)
+                    )
+            )
+        );
+    }
+
     public terminateOperation(applicationName: string, appNamespace: string): Promise<boolean> {
         return requests
             .delete(`/applications/${applicationName}/operation`)
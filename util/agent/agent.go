@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/argoproj/argo-cd/v2/common"
+)
+
+// Agent runs in a remote cluster and maintains an outbound connection to the Argo CD API server. Connections
+// requested by the API server are tunneled to the target address, usually the Kubernetes API server of the cluster.
+type Agent struct {
+	// ServerURL is the URL of the Argo CD API server, e.g. https://argocd.example.com
+	ServerURL string
+	// Name is the name of the cluster in Argo CD which is managed by this agent
+	Name string
+	// Token is the Argo CD token used to authenticate the agent
+	Token string
+	// Target is the address connections are tunneled to
+	Target string
+	// Insecure skips verification of the Argo CD API server TLS certificate
+	Insecure bool
+	// RetryInterval is the time to wait before reconnecting after the control connection was lost
+	RetryInterval time.Duration
+}
+
+func (a *Agent) endpoint(path string, query url.Values) (string, error) {
+	u, err := url.Parse(a.ServerURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %s: %w", a.ServerURL, err)
+	}
+	switch u.Scheme {
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	query.Set("name", a.Name)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (a *Agent) dial(ctx context.Context, path string, query url.Values) (*websocket.Conn, error) {
+	endpoint, err := a.endpoint(path, query)
+	if err != nil {
+		return nil, err
+	}
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: dialTimeout,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: a.Insecure},
+	}
+	header := http.Header{}
+	header.Set("Cookie", fmt.Sprintf("%s=%s", common.AuthCookieName, a.Token))
+	ws, resp, err := dialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %s", endpoint, resp.Status)
+		}
+		return nil, fmt.Errorf("failed to connect to %s: %w", endpoint, err)
+	}
+	return ws, nil
+}
+
+// Run connects the agent to the API server and serves tunnel requests until the context is done
+func (a *Agent) Run(ctx context.Context) {
+	retryInterval := a.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = 5 * time.Second
+	}
+	for {
+		if err := a.serve(ctx); err != nil {
+			log.Warnf("Agent connection failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func (a *Agent) serve(ctx context.Context) error {
+	ws, err := a.dial(ctx, ConnectPath, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	log.Infof("Agent %s connected to %s", a.Name, a.ServerURL)
+
+	// closes the connection once the agent stops, the goroutine exits with the connection
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = ws.Close()
+		case <-done:
+		}
+	}()
+
+	var writeLock sync.Mutex
+	reject := func(id string, err error) {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		if err := ws.WriteJSON(dialRejection{ID: id, Error: err.Error()}); err != nil {
+			log.Warnf("Failed to reject tunnel %s: %v", id, err)
+		}
+	}
+	for {
+		var req dialRequest
+		if err := ws.ReadJSON(&req); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("control connection closed: %w", err)
+		}
+		go a.tunnel(ctx, req.ID, reject)
+	}
+}
+
+// tunnel opens the tunnel with the given id to the target, or rejects it, so that the server doesn't wait for it
+func (a *Agent) tunnel(ctx context.Context, id string, reject func(id string, err error)) {
+	target, err := (&net.Dialer{Timeout: dialTimeout}).DialContext(ctx, "tcp", a.Target)
+	if err != nil {
+		log.Warnf("Failed to connect to %s: %v", a.Target, err)
+		reject(id, fmt.Errorf("failed to connect to %s: %w", a.Target, err))
+		return
+	}
+	ws, err := a.dial(ctx, TunnelPath, url.Values{"id": []string{id}})
+	if err != nil {
+		_ = target.Close()
+		log.Warnf("Failed to open tunnel %s: %v", id, err)
+		reject(id, err)
+		return
+	}
+	pipe(newWSConn(ws), target)
+}
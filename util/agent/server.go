@@ -0,0 +1,335 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ConnectPath is the path of the endpoint agents use to establish their control connection
+	ConnectPath = "/api/agent/connect"
+	// TunnelPath is the path of the endpoint agents use to open a tunnel requested by the control plane
+	TunnelPath = "/api/agent/tunnel"
+
+	// dialTimeout is the time the agent has to open a requested tunnel
+	dialTimeout = 30 * time.Second
+	// pingInterval is the interval of keep-alive messages sent over the control connection
+	pingInterval = 30 * time.Second
+)
+
+// dialRequest is sent over the control connection to ask the agent to open a new tunnel
+type dialRequest struct {
+	ID string `json:"id"`
+}
+
+// dialRejection is sent over the control connection by the agent if it failed to open a requested tunnel
+type dialRejection struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// AuthorizeFunc returns an error if the request is not allowed to use the agent with the given name
+type AuthorizeFunc func(r *http.Request, name string) error
+
+// ErrPermissionDenied is returned by an AuthorizeFunc if the request is authenticated, but not allowed to use the agent
+var ErrPermissionDenied = errors.New("permission denied")
+
+// pendingTunnel is a tunnel which was requested from an agent but not opened yet
+type pendingTunnel struct {
+	conns chan net.Conn
+	// rejections receives the error of the agent if it failed to open the tunnel
+	rejections chan error
+	// abandoned is closed once the requester stopped waiting for the tunnel
+	abandoned chan struct{}
+}
+
+type agentSession struct {
+	ws        *websocket.Conn
+	writeLock sync.Mutex
+}
+
+func (s *agentSession) send(msg interface{}) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return s.ws.WriteJSON(msg)
+}
+
+func (s *agentSession) ping() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return s.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingInterval))
+}
+
+// Server accepts connections of agents running in remote clusters and opens connections to those
+// clusters through tunnels established by the agents, so that no inbound access to the clusters is required.
+// Agent sessions are kept in memory, so connections can only be tunneled by the server the agent is connected to.
+type Server struct {
+	authorize AuthorizeFunc
+	upgrader  websocket.Upgrader
+
+	lock     sync.Mutex
+	sessions map[string]*agentSession
+	pending  map[string]*pendingTunnel
+}
+
+// NewServer returns a new agent tunnel server
+func NewServer(authorize AuthorizeFunc) *Server {
+	return &Server{
+		authorize: authorize,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: 10 * time.Second,
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+		sessions: map[string]*agentSession{},
+		pending:  map[string]*pendingTunnel{},
+	}
+}
+
+// ServeHTTP serves the control and tunnel endpoints used by agents
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "agent name is required", http.StatusBadRequest)
+		return
+	}
+	if s.authorize != nil {
+		if err := s.authorize(r, name); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	switch r.URL.Path {
+	case ConnectPath:
+		s.serveControl(w, r, name)
+	case TunnelPath:
+		s.serveTunnel(w, r, name)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request, name string) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warnf("Failed to upgrade control connection of agent %s: %v", name, err)
+		return
+	}
+	session := &agentSession{ws: ws}
+	s.lock.Lock()
+	if prev, ok := s.sessions[name]; ok {
+		_ = prev.ws.Close()
+	}
+	s.sessions[name] = session
+	s.lock.Unlock()
+	log.Infof("Agent %s connected from %s", name, r.RemoteAddr)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := session.ping(); err != nil {
+					_ = ws.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	// agents only send the rejections of tunnels they failed to open over the control connection, reading is
+	// otherwise used to detect disconnects
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		var rejection dialRejection
+		if err := json.Unmarshal(data, &rejection); err == nil && rejection.ID != "" {
+			s.reject(name, rejection.ID, errors.New(rejection.Error))
+		}
+	}
+	close(done)
+	_ = ws.Close()
+
+	s.lock.Lock()
+	if s.sessions[name] == session {
+		delete(s.sessions, name)
+	}
+	s.lock.Unlock()
+	log.Infof("Agent %s disconnected", name)
+}
+
+func (s *Server) serveTunnel(w http.ResponseWriter, r *http.Request, name string) {
+	id := r.URL.Query().Get("id")
+	s.lock.Lock()
+	tunnel, ok := s.pending[name+"/"+id]
+	delete(s.pending, name+"/"+id)
+	s.lock.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("tunnel %s was not requested", id), http.StatusNotFound)
+		return
+	}
+	var conn net.Conn
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warnf("Failed to upgrade tunnel connection of agent %s: %v", name, err)
+	} else {
+		conn = newWSConn(ws)
+	}
+	select {
+	case tunnel.conns <- conn:
+	case <-tunnel.abandoned:
+		// the requester gave up waiting, nobody else is going to close the connection
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+}
+
+// reject fails the pending tunnel with the given id, which the agent failed to open
+func (s *Server) reject(name string, id string, err error) {
+	s.lock.Lock()
+	tunnel, ok := s.pending[name+"/"+id]
+	delete(s.pending, name+"/"+id)
+	s.lock.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case tunnel.rejections <- err:
+	case <-tunnel.abandoned:
+	}
+}
+
+// Connected returns true if the agent with the given name is connected
+func (s *Server) Connected(name string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.sessions[name]
+	return ok
+}
+
+// DialContext opens a connection to the target of the agent with the given name
+func (s *Server) DialContext(ctx context.Context, name string) (net.Conn, error) {
+	id := uuid.New().String()
+	tunnel := &pendingTunnel{conns: make(chan net.Conn), rejections: make(chan error), abandoned: make(chan struct{})}
+	s.lock.Lock()
+	session, ok := s.sessions[name]
+	if ok {
+		s.pending[name+"/"+id] = tunnel
+	}
+	s.lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("agent %s is not connected", name)
+	}
+	defer func() {
+		s.lock.Lock()
+		delete(s.pending, name+"/"+id)
+		s.lock.Unlock()
+		close(tunnel.abandoned)
+	}()
+
+	if err := session.send(dialRequest{ID: id}); err != nil {
+		return nil, fmt.Errorf("failed to send dial request to agent %s: %w", name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	select {
+	case conn := <-tunnel.conns:
+		if conn == nil {
+			return nil, fmt.Errorf("agent %s failed to open tunnel", name)
+		}
+		return conn, nil
+	case err := <-tunnel.rejections:
+		return nil, fmt.Errorf("agent %s failed to open tunnel: %w", name, err)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for agent %s to open tunnel: %w", name, ctx.Err())
+	}
+}
+
+// ProxyAuthToken returns the token of the Proxy-Authorization header of the request. The token is either
+// given as bearer token or as password of basic authentication, which is what HTTP clients send for proxy
+// URLs with user info, e.g. http://argocd:<token>@argocd-server-agent-proxy:8085.
+func ProxyAuthToken(r *http.Request) string {
+	auth := r.Header.Get("Proxy-Authorization")
+	switch {
+	case strings.HasPrefix(auth, "Bearer "):
+		return strings.TrimPrefix(auth, "Bearer ")
+	case strings.HasPrefix(auth, "Basic "):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+		if err != nil {
+			return ""
+		}
+		_, password, _ := strings.Cut(string(decoded), ":")
+		return password
+	}
+	return ""
+}
+
+// ProxyHandler returns an HTTP handler which accepts CONNECT requests and connects them to the agent whose
+// name is the host of the request. It allows clients such as the application controller to reach
+// agent-managed clusters by using the handler as HTTP proxy. Requests are only accepted if the given
+// function authorizes them for the agent.
+func (s *Server) ProxyHandler(authorize AuthorizeFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT requests are supported", http.StatusMethodNotAllowed)
+			return
+		}
+		name, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			name = r.Host
+		}
+		if err := authorize(r, name); err != nil {
+			if errors.Is(err, ErrPermissionDenied) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Proxy-Authenticate", `Basic realm="argocd-agent-proxy"`)
+			http.Error(w, err.Error(), http.StatusProxyAuthRequired)
+			return
+		}
+		conn, err := s.DialContext(r.Context(), name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			_ = conn.Close()
+			http.Error(w, "hijacking is not supported", http.StatusInternalServerError)
+			return
+		}
+		clientConn, _, err := hijacker.Hijack()
+		if err != nil {
+			_ = conn.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+			_ = conn.Close()
+			_ = clientConn.Close()
+			return
+		}
+		pipe(clientConn, conn)
+	})
+}
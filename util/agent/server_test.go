@@ -0,0 +1,193 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startEchoServer starts a TCP server which writes back everything it receives
+func startEchoServer(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func startAgent(t *testing.T, server *httptest.Server, name string, target string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	agent := &Agent{ServerURL: server.URL, Name: name, Token: "token", Target: target, RetryInterval: 100 * time.Millisecond}
+	go agent.Run(ctx)
+}
+
+func waitForAgent(t *testing.T, s *Server, name string) {
+	require.Eventually(t, func() bool {
+		return s.Connected(name)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func assertEcho(t *testing.T, conn net.Conn) {
+	_, err := conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+}
+
+func TestServer_DialContext(t *testing.T) {
+	s := NewServer(nil)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	startAgent(t, server, "edge", startEchoServer(t))
+	waitForAgent(t, s, "edge")
+
+	conn, err := s.DialContext(context.Background(), "edge")
+	require.NoError(t, err)
+	defer conn.Close()
+	assertEcho(t, conn)
+
+	_, err = s.DialContext(context.Background(), "unknown")
+	assert.EqualError(t, err, "agent unknown is not connected")
+}
+
+func TestServer_Authorize(t *testing.T) {
+	s := NewServer(func(r *http.Request, name string) error {
+		cookie, err := r.Cookie("argocd.token")
+		if err != nil || cookie.Value != "token" {
+			return errors.New("invalid token")
+		}
+		if name != "edge" {
+			return fmt.Errorf("agent %s is not allowed", name)
+		}
+		return nil
+	})
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	startAgent(t, server, "edge", startEchoServer(t))
+	startAgent(t, server, "other", startEchoServer(t))
+	waitForAgent(t, s, "edge")
+	assert.False(t, s.Connected("other"))
+}
+
+func TestServer_ProxyHandler(t *testing.T) {
+	s := NewServer(nil)
+	server := httptest.NewServer(s)
+	defer server.Close()
+	proxy := httptest.NewServer(s.ProxyHandler(func(r *http.Request, name string) error {
+		if ProxyAuthToken(r) != "token" {
+			return errors.New("invalid token")
+		}
+		return nil
+	}))
+	defer proxy.Close()
+
+	startAgent(t, server, "edge", startEchoServer(t))
+	waitForAgent(t, s, "edge")
+
+	connect := func(auth string) (net.Conn, *http.Response) {
+		conn, err := net.Dial("tcp", proxy.Listener.Addr().String())
+		require.NoError(t, err)
+		_, err = fmt.Fprintf(conn, "CONNECT edge:443 HTTP/1.1\r\nHost: edge:443\r\n%s\r\n", auth)
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+		return conn, resp
+	}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		conn, resp := connect("")
+		defer conn.Close()
+		assert.Equal(t, http.StatusProxyAuthRequired, resp.StatusCode)
+	})
+
+	t.Run("BasicAuth", func(t *testing.T) {
+		conn, resp := connect("Proxy-Authorization: Basic YXJnb2NkOnRva2Vu\r\n")
+		defer conn.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assertEcho(t, conn)
+	})
+
+	t.Run("BearerAuth", func(t *testing.T) {
+		conn, resp := connect("Proxy-Authorization: Bearer token\r\n")
+		defer conn.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assertEcho(t, conn)
+	})
+}
+
+func TestServer_ProxyHandler_TargetUnreachable(t *testing.T) {
+	s := NewServer(nil)
+	server := httptest.NewServer(s)
+	defer server.Close()
+	proxy := httptest.NewServer(s.ProxyHandler(func(r *http.Request, name string) error {
+		return nil
+	}))
+	defer proxy.Close()
+
+	// nothing listens on the target anymore
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	target := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	startAgent(t, server, "edge", target)
+	waitForAgent(t, s, "edge")
+
+	conn, err := net.Dial("tcp", proxy.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = fmt.Fprint(conn, "CONNECT edge:443 HTTP/1.1\r\nHost: edge:443\r\n\r\n")
+	require.NoError(t, err)
+	start := time.Now()
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	require.NoError(t, err)
+	// the agent rejects the tunnel, so the proxy fails without waiting for the dial timeout
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Less(t, time.Since(start), dialTimeout)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "failed to connect to "+target)
+}
+
+func TestServer_TunnelAbandoned(t *testing.T) {
+	s := NewServer(nil)
+	tunnel := &pendingTunnel{conns: make(chan net.Conn), abandoned: make(chan struct{})}
+	s.pending["edge/1"] = tunnel
+	close(tunnel.abandoned)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+TunnelPath+"?name=edge&id=1", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	// the server closes the tunnel nobody waits for anymore
+	_, _, err = ws.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+}
@@ -19,6 +19,7 @@ import (
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -175,14 +176,26 @@ func FilterByRepoP(apps []*argoappv1.Application, repo string) []*argoappv1.Appl
 	return items
 }
 
-// FilterByCluster returns an application
-func FilterByCluster(apps []argoappv1.Application, cluster string) []argoappv1.Application {
+// FilterByCluster returns the applications targeting the given cluster, which is given by its server URL or name. The
+// cluster is looked up in the given clusters, so that applications which target it by the other one of server URL and
+// name, or which select it by labels, are returned as well.
+func FilterByCluster(apps []argoappv1.Application, cluster string, clusters []argoappv1.Cluster) []argoappv1.Application {
 	if cluster == "" {
 		return apps
 	}
+	server, name := cluster, cluster
+	var clusterLabels map[string]string
+	for _, c := range clusters {
+		if c.Server == cluster || c.Name == cluster {
+			server, name, clusterLabels = c.Server, c.Name, c.Labels
+			break
+		}
+	}
 	items := make([]argoappv1.Application, 0)
 	for i := 0; i < len(apps); i++ {
-		if apps[i].Spec.Destination.Server == cluster || apps[i].Spec.Destination.Name == cluster {
+		dest := apps[i].Spec.Destination
+		if (dest.Server != "" && dest.Server == server) || (dest.Name != "" && dest.Name == name) ||
+			(dest.ClusterSelector != "" && clusterLabels != nil && ClusterSelectorMatches(dest.ClusterSelector, clusterLabels)) {
 			items = append(items, apps[i])
 		}
 	}
@@ -324,7 +337,10 @@ func ValidateRepo(
 		})
 		return conditions, nil
 	}
-	config := cluster.RESTConfig()
+	config, err := cluster.RESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster REST config: %w", err)
+	}
 	cluster.ServerVersion, err = kubectl.GetServerVersion(config)
 	if err != nil {
 		return nil, fmt.Errorf("error getting k8s server version: %w", err)
@@ -417,7 +433,7 @@ func validateRepo(ctx context.Context,
 		db,
 		permittedHelmRepos,
 		helmOptions,
-		app.Name,
+		app.InstanceName(settingsMgr.GetNamespace()),
 		app.Spec.Destination,
 		proj,
 		sources,
@@ -483,6 +499,26 @@ func GetRefSources(ctx context.Context, spec argoappv1.ApplicationSpec, db db.Ar
 // It also checks:
 // - If we used both name and server then we return an invalid spec error
 func ValidateDestination(ctx context.Context, dest *argoappv1.ApplicationDestination, db db.ArgoDB) error {
+	if dest.ClusterSelector != "" {
+		if dest.Name != "" {
+			return fmt.Errorf("application destination can't have both name and cluster selector defined: %s %s", dest.Name, dest.ClusterSelector)
+		}
+		if dest.Server != "" && !dest.IsServerInferred() {
+			return fmt.Errorf("application destination can't have both cluster selector and server defined: %s %s", dest.ClusterSelector, dest.Server)
+		}
+		// The selector is resolved again even if the server was already inferred, so that changes of the cluster
+		// labels are picked up.
+		server, err := getDestinationServerBySelector(ctx, db, dest.ClusterSelector)
+		if err != nil {
+			if dest.IsServerInferred() {
+				// don't keep targeting a cluster which no longer matches the selector
+				dest.SetInferredServer("")
+			}
+			return fmt.Errorf("unable to find destination server: %v", err)
+		}
+		dest.SetInferredServer(server)
+		return nil
+	}
 	if dest.Name != "" {
 		if dest.Server == "" {
 			server, err := getDestinationServer(ctx, db, dest.Name)
@@ -576,20 +612,40 @@ func ValidatePermissions(ctx context.Context, spec *argoappv1.ApplicationSpec, p
 	}
 
 	if spec.Destination.Server != "" {
-		permitted, err := proj.IsDestinationPermitted(spec.Destination, func(project string) ([]*argoappv1.Cluster, error) {
-			return db.GetProjectClusters(ctx, project)
-		})
-		if err != nil {
-			return nil, err
+		// the destination namespace may use variables, which are resolved at reconcile time
+		destination := spec.Destination
+		variablesResolved := true
+		if HasSpecVariables(spec) {
+			variables, err := GetSpecVariables(ctx, spec, db)
+			if err != nil {
+				variablesResolved = false
+				// a missing cluster is reported below
+				if status.Code(err) != codes.NotFound {
+					conditions = append(conditions, argoappv1.ApplicationCondition{
+						Type:    argoappv1.ApplicationConditionInvalidSpecError,
+						Message: fmt.Sprintf("unable to resolve the variables of the application spec: %v", err),
+					})
+				}
+			} else {
+				destination.Namespace = resolveVariables(destination.Namespace, variables)
+			}
 		}
-		if !permitted {
-			conditions = append(conditions, argoappv1.ApplicationCondition{
-				Type:    argoappv1.ApplicationConditionInvalidSpecError,
-				Message: fmt.Sprintf("application destination {%s %s} is not permitted in project '%s'", spec.Destination.Server, spec.Destination.Namespace, spec.Project),
+		if variablesResolved {
+			permitted, err := proj.IsDestinationPermitted(destination, func(project string) ([]*argoappv1.Cluster, error) {
+				return db.GetProjectClusters(ctx, project)
 			})
+			if err != nil {
+				return nil, err
+			}
+			if !permitted {
+				conditions = append(conditions, argoappv1.ApplicationCondition{
+					Type:    argoappv1.ApplicationConditionInvalidSpecError,
+					Message: fmt.Sprintf("application destination {%s %s} is not permitted in project '%s'", destination.Server, destination.Namespace, spec.Project),
+				})
+			}
 		}
 		// Ensure the k8s cluster the app is referencing, is configured in Argo CD
-		_, err = db.GetCluster(ctx, spec.Destination.Server)
+		cluster, err := db.GetCluster(ctx, spec.Destination.Server)
 
 		if err != nil {
 			if errStatus, ok := status.FromError(err); ok && errStatus.Code() == codes.NotFound {
@@ -600,6 +656,13 @@ func ValidatePermissions(ctx context.Context, spec *argoappv1.ApplicationSpec, p
 			} else {
 				return nil, fmt.Errorf("error getting cluster: %w", err)
 			}
+		} else if cluster.Project != "" && cluster.Project != spec.Project {
+			// like project-scoped repositories, project-scoped clusters are registered by the admins of a project and
+			// can only be used by the applications of that project
+			conditions = append(conditions, argoappv1.ApplicationCondition{
+				Type:    argoappv1.ApplicationConditionInvalidSpecError,
+				Message: fmt.Sprintf("cluster '%s' is scoped to project '%s' and is not permitted in project '%s'", spec.Destination.Server, cluster.Project, spec.Project),
+			})
 		}
 	} else if spec.Destination.Server == "" {
 		conditions = append(conditions, argoappv1.ApplicationCondition{Type: argoappv1.ApplicationConditionInvalidSpecError, Message: errDestinationMissing})
@@ -731,6 +794,14 @@ func verifyGenerateManifests(
 		})
 		return conditions // Can't perform the next check without settings.
 	}
+	repoURLPolicy, err := settingsMgr.GetRepositoryURLPolicy()
+	if err != nil {
+		conditions = append(conditions, argoappv1.ApplicationCondition{
+			Type:    argoappv1.ApplicationConditionInvalidSpecError,
+			Message: fmt.Sprintf("Error getting repository policy: %v", err),
+		})
+		return conditions
+	}
 
 	for _, source := range sources {
 		repoRes, err := db.GetRepository(ctx, source.RepoURL)
@@ -756,23 +827,25 @@ func verifyGenerateManifests(
 				Name:  repoRes.Name,
 				Proxy: repoRes.Proxy,
 			},
-			Repos:              helmRepos,
-			Revision:           source.TargetRevision,
-			AppName:            name,
-			Namespace:          dest.Namespace,
-			ApplicationSource:  &source,
-			KustomizeOptions:   kustomizeOptions,
-			KubeVersion:        kubeVersion,
-			ApiVersions:        apiVersions,
-			HelmOptions:        helmOptions,
-			HelmRepoCreds:      repositoryCredentials,
-			TrackingMethod:     string(GetTrackingMethod(settingsMgr)),
-			EnabledSourceTypes: enableGenerateManifests,
-			NoRevisionCache:    true,
-			HasMultipleSources: hasMultipleSources,
-			RefSources:         refSources,
-			ProjectName:        proj.Name,
-			ProjectSourceRepos: proj.Spec.SourceRepos,
+			Repos:               helmRepos,
+			Revision:            source.TargetRevision,
+			AppName:             name,
+			Namespace:           dest.Namespace,
+			ApplicationSource:   &source,
+			KustomizeOptions:    kustomizeOptions,
+			KubeVersion:         kubeVersion,
+			ApiVersions:         apiVersions,
+			HelmOptions:         helmOptions,
+			HelmRepoCreds:       repositoryCredentials,
+			TrackingMethod:      string(GetTrackingMethod(settingsMgr)),
+			EnabledSourceTypes:  enableGenerateManifests,
+			NoRevisionCache:     true,
+			HasMultipleSources:  hasMultipleSources,
+			RefSources:          refSources,
+			ProjectName:         proj.Name,
+			ProjectSourceRepos:  proj.Spec.SourceRepos,
+			RepositoryAllowList: repoURLPolicy.Allow,
+			RepositoryDenyList:  repoURLPolicy.Deny,
 		}
 		req.Repo.CopyCredentialsFromRepo(repoRes)
 		req.Repo.CopySettingsFrom(repoRes)
@@ -792,6 +865,22 @@ func verifyGenerateManifests(
 	return conditions
 }
 
+// WithManifestGenerateTimeout returns a copy of the given context whose deadline is the manifest generation timeout of
+// the given application, if the application sets one. The deadline is propagated to the repo server, which kills config
+// management tools that run longer.
+func WithManifestGenerateTimeout(ctx context.Context, app *argoappv1.Application) (context.Context, context.CancelFunc, error) {
+	timeout, err := app.GetManifestGenerateTimeout()
+	if err != nil {
+		return nil, nil, err
+	}
+	if timeout == 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
 // SetAppOperation updates an application with the specified operation, retrying conflict errors
 func SetAppOperation(appIf v1alpha1.ApplicationInterface, appName string, op *argoappv1.Operation) (*argoappv1.Application, error) {
 	for {
@@ -919,6 +1008,47 @@ func getDestinationServer(ctx context.Context, db db.ArgoDB, clusterName string)
 	return servers[0], nil
 }
 
+// getDestinationServerBySelector returns the server URL of the cluster whose labels match the given label selector. It
+// fails unless exactly one cluster matches.
+func getDestinationServerBySelector(ctx context.Context, db db.ArgoDB, clusterSelector string) (string, error) {
+	clusters, err := db.ListClusters(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error listing clusters: %w", err)
+	}
+	return GetClusterServerBySelector(clusters.Items, clusterSelector)
+}
+
+// GetClusterServerBySelector returns the server URL of the only cluster of the given list whose labels match the given
+// label selector.
+func GetClusterServerBySelector(clusters []argoappv1.Cluster, clusterSelector string) (string, error) {
+	selector, err := labels.Parse(clusterSelector)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the cluster selector %q: %w", clusterSelector, err)
+	}
+	var servers []string
+	for _, c := range clusters {
+		if selector.Matches(labels.Set(c.Labels)) {
+			servers = append(servers, c.Server)
+		}
+	}
+	if len(servers) > 1 {
+		return "", fmt.Errorf("there are %d clusters matching the selector %q: %v", len(servers), clusterSelector, servers)
+	} else if len(servers) == 0 {
+		return "", fmt.Errorf("there are no clusters matching the selector %q", clusterSelector)
+	}
+	return servers[0], nil
+}
+
+// ClusterSelectorMatches returns whether the given cluster selector matches the given cluster labels. An invalid
+// selector matches nothing.
+func ClusterSelectorMatches(clusterSelector string, clusterLabels map[string]string) bool {
+	selector, err := labels.Parse(clusterSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(clusterLabels))
+}
+
 func GetGlobalProjects(proj *argoappv1.AppProject, projLister applicationsv1.AppProjectLister, settingsManager *settings.SettingsManager) []*argoappv1.AppProject {
 	gps, err := settingsManager.GetGlobalProjectsSettings()
 	globalProjects := make([]*argoappv1.AppProject, 0)
@@ -936,12 +1066,14 @@ func GetGlobalProjects(proj *argoappv1.AppProject, projLister applicationsv1.App
 
 		selector, err := metav1.LabelSelectorAsSelector(&gp.LabelSelector)
 		if err != nil {
-			break
+			log.Warnf("Invalid label selector of global project %s: %v", gp.ProjectName, err)
+			continue
 		}
 		//Get projects which match the label selector, then see if proj is a match
 		projList, err := projLister.AppProjects(proj.Namespace).List(selector)
 		if err != nil {
-			break
+			log.Warnf("Failed to list projects matching global project %s: %v", gp.ProjectName, err)
+			continue
 		}
 		var matchMe bool
 		for _, item := range projList {
@@ -956,7 +1088,8 @@ func GetGlobalProjects(proj *argoappv1.AppProject, projLister applicationsv1.App
 		//If proj is a match for this global project setting, then it is its global project
 		globalProj, err := projLister.AppProjects(proj.Namespace).Get(gp.ProjectName)
 		if err != nil {
-			break
+			log.Warnf("Failed to get global project %s: %v", gp.ProjectName, err)
+			continue
 		}
 		globalProjects = append(globalProjects, globalProj)
 
@@ -990,9 +1123,36 @@ func mergeVirtualProject(proj *argoappv1.AppProject, globalProj *argoappv1.AppPr
 
 	proj.Spec.Destinations = append(proj.Spec.Destinations, globalProj.Spec.Destinations...)
 
+	proj.Spec.SignatureKeys = append(proj.Spec.SignatureKeys, globalProj.Spec.SignatureKeys...)
+
+	// the restrictions of the global project apply to all the projects which inherit it
+	proj.Spec.PermitOnlyProjectScopedClusters = proj.Spec.PermitOnlyProjectScopedClusters || globalProj.Spec.PermitOnlyProjectScopedClusters
+	proj.Spec.DisableLogs = proj.Spec.DisableLogs || globalProj.Spec.DisableLogs
+	proj.Spec.DisableExec = proj.Spec.DisableExec || globalProj.Spec.DisableExec
+	proj.Spec.RedactSecretData = proj.Spec.RedactSecretData || globalProj.Spec.RedactSecretData
+
+	// the quotas of the global project limit each of the projects which inherit it, the stricter quota applies
+	if globalProj.Spec.Quotas != nil {
+		quotas := argoappv1.ProjectQuotas{}
+		if proj.Spec.Quotas != nil {
+			quotas = *proj.Spec.Quotas
+		}
+		quotas.MaxApplications = stricterQuota(quotas.MaxApplications, globalProj.Spec.Quotas.MaxApplications)
+		quotas.MaxResourcesPerApplication = stricterQuota(quotas.MaxResourcesPerApplication, globalProj.Spec.Quotas.MaxResourcesPerApplication)
+		proj.Spec.Quotas = &quotas
+	}
+
 	return proj
 }
 
+// stricterQuota returns the stricter of the given quotas, of which a quota of 0 is unlimited
+func stricterQuota(a int64, b int64) int64 {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
 func GenerateSpecIsDifferentErrorMessage(entity string, a, b interface{}) string {
 	basicMsg := fmt.Sprintf("existing %s spec is different; use upsert flag to force update", entity)
 	difference, _ := GetDifferentPathsBetweenStructs(a, b)
@@ -423,6 +423,15 @@ func TestValidateRepo(t *testing.T) {
 	assert.Equal(t, app.Spec.Destination.Namespace, receivedRequest.Namespace)
 	assert.Equal(t, &source, receivedRequest.ApplicationSource)
 	assert.Equal(t, kustomizeOptions, receivedRequest.KustomizeOptions)
+
+	// the manifests are requested by the instance name of the app, which selects its app specific source override file
+	for namespace, appName := range map[string]string{test.FakeArgoCDNamespace: "guestbook", "team-a": "team-a_guestbook"} {
+		app.Name = "guestbook"
+		app.Namespace = namespace
+		_, err = ValidateRepo(context.Background(), app, repoClientSet, db, &kubetest.MockKubectlCmd{Version: kubeVersion, APIResources: apiResources}, proj, settingsMgr)
+		assert.NoError(t, err)
+		assert.Equal(t, appName, receivedRequest.AppName)
+	}
 }
 
 func TestFormatAppConditions(t *testing.T) {
@@ -559,6 +568,39 @@ func TestFilterByRepo(t *testing.T) {
 	})
 }
 
+func TestFilterByCluster(t *testing.T) {
+	apps := []argoappv1.Application{
+		{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Server: "https://dev.example.com"}}},
+		{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Name: "dev"}}},
+		{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{ClusterSelector: "env=dev"}}},
+		{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{ClusterSelector: "env=prod"}}},
+	}
+	clusters := []argoappv1.Cluster{
+		{Name: "dev", Server: "https://dev.example.com", Labels: map[string]string{"env": "dev"}},
+		{Name: "prod", Server: "https://prod.example.com", Labels: map[string]string{"env": "prod"}},
+	}
+
+	t.Run("Empty filter", func(t *testing.T) {
+		res := FilterByCluster(apps, "", clusters)
+		assert.Len(t, res, 4)
+	})
+
+	t.Run("Match by server", func(t *testing.T) {
+		res := FilterByCluster(apps, "https://dev.example.com", clusters)
+		assert.Equal(t, apps[:3], res)
+	})
+
+	t.Run("Match by name", func(t *testing.T) {
+		res := FilterByCluster(apps, "prod", clusters)
+		assert.Equal(t, apps[3:], res)
+	})
+
+	t.Run("Unknown cluster", func(t *testing.T) {
+		res := FilterByCluster(apps, "https://dev.example.com", nil)
+		assert.Equal(t, apps[:1], res)
+	})
+}
+
 func TestFilterByRepoP(t *testing.T) {
 	apps := []*argoappv1.Application{
 		{
@@ -676,6 +718,34 @@ func TestValidatePermissions(t *testing.T) {
 		assert.Contains(t, conditions[0].Message, "application repo http://some/where is not permitted")
 	})
 
+	t.Run("Failure to resolve the variables of the spec result in condition", func(t *testing.T) {
+		spec := argoappv1.ApplicationSpec{
+			Source: &argoappv1.ApplicationSource{
+				RepoURL:        "http://some/where",
+				Chart:          "somechart",
+				TargetRevision: "1.4.1",
+			},
+			Destination: argoappv1.ApplicationDestination{
+				Server:    "https://127.0.0.1:6443",
+				Namespace: "{{cluster.name}}",
+			},
+		}
+		proj := argoappv1.AppProject{
+			Spec: argoappv1.AppProjectSpec{
+				Destinations: []argoappv1.ApplicationDestination{{Server: "*", Namespace: "*"}},
+				SourceRepos:  []string{"http://some/where"},
+			},
+		}
+		cluster := &argoappv1.Cluster{Server: "https://127.0.0.1:6443"}
+		db := &dbmocks.ArgoDB{}
+		db.On("GetCluster", context.Background(), spec.Destination.Server).Return(nil, fmt.Errorf("connection refused")).Once()
+		db.On("GetCluster", context.Background(), spec.Destination.Server).Return(cluster, nil)
+		conditions, err := ValidatePermissions(context.Background(), &spec, &proj, db)
+		assert.NoError(t, err)
+		assert.Len(t, conditions, 1)
+		assert.Contains(t, conditions[0].Message, "unable to resolve the variables of the application spec")
+	})
+
 	t.Run("Application destination is not permitted in project", func(t *testing.T) {
 		spec := argoappv1.ApplicationSpec{
 			Source: &argoappv1.ApplicationSource{
@@ -741,6 +811,107 @@ func TestValidatePermissions(t *testing.T) {
 		assert.Contains(t, conditions[0].Message, "has not been configured")
 	})
 
+	t.Run("Destination cluster is scoped to another project", func(t *testing.T) {
+		spec := argoappv1.ApplicationSpec{
+			Project: "team-b",
+			Source: &argoappv1.ApplicationSource{
+				RepoURL:        "http://some/where",
+				Path:           "",
+				Chart:          "somechart",
+				TargetRevision: "1.4.1",
+			},
+			Destination: argoappv1.ApplicationDestination{
+				Server:    "https://127.0.0.1:6443",
+				Namespace: "default",
+			},
+		}
+		proj := argoappv1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-b"},
+			Spec: argoappv1.AppProjectSpec{
+				Destinations: []argoappv1.ApplicationDestination{
+					{
+						Server:    "*",
+						Namespace: "default",
+					},
+				},
+				SourceRepos: []string{"http://some/where"},
+			},
+		}
+		cluster := &argoappv1.Cluster{Server: "https://127.0.0.1:6443", Project: "team-a"}
+		db := &dbmocks.ArgoDB{}
+		db.On("GetCluster", context.Background(), spec.Destination.Server).Return(cluster, nil)
+		conditions, err := ValidatePermissions(context.Background(), &spec, &proj, db)
+		assert.NoError(t, err)
+		assert.Len(t, conditions, 1)
+		assert.Equal(t, "cluster 'https://127.0.0.1:6443' is scoped to project 'team-a' and is not permitted in project 'team-b'", conditions[0].Message)
+
+		spec.Project = "team-a"
+		proj.Name = "team-a"
+		conditions, err = ValidatePermissions(context.Background(), &spec, &proj, db)
+		assert.NoError(t, err)
+		assert.Len(t, conditions, 0)
+	})
+
+	t.Run("Destination cluster given by name is scoped to another project", func(t *testing.T) {
+		spec := argoappv1.ApplicationSpec{
+			Project: "team-b",
+			Source: &argoappv1.ApplicationSource{
+				RepoURL:        "http://some/where",
+				Chart:          "somechart",
+				TargetRevision: "1.4.1",
+			},
+			Destination: argoappv1.ApplicationDestination{
+				Name:      "team-a-cluster",
+				Namespace: "default",
+			},
+		}
+		proj := argoappv1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-b"},
+			Spec: argoappv1.AppProjectSpec{
+				Destinations: []argoappv1.ApplicationDestination{{Name: "*", Server: "*", Namespace: "default"}},
+				SourceRepos:  []string{"http://some/where"},
+			},
+		}
+		cluster := &argoappv1.Cluster{Name: "team-a-cluster", Server: "https://127.0.0.1:6443", Project: "team-a"}
+		db := &dbmocks.ArgoDB{}
+		db.On("GetClusterServersByName", context.Background(), "team-a-cluster").Return([]string{cluster.Server}, nil)
+		db.On("GetCluster", context.Background(), cluster.Server).Return(cluster, nil)
+		conditions, err := ValidatePermissions(context.Background(), &spec, &proj, db)
+		assert.NoError(t, err)
+		assert.Len(t, conditions, 1)
+		assert.Equal(t, "cluster 'https://127.0.0.1:6443' is scoped to project 'team-a' and is not permitted in project 'team-b'", conditions[0].Message)
+	})
+
+	t.Run("Destination cluster given by selector is scoped to another project", func(t *testing.T) {
+		spec := argoappv1.ApplicationSpec{
+			Project: "team-b",
+			Source: &argoappv1.ApplicationSource{
+				RepoURL:        "http://some/where",
+				Chart:          "somechart",
+				TargetRevision: "1.4.1",
+			},
+			Destination: argoappv1.ApplicationDestination{
+				ClusterSelector: "env=prod",
+				Namespace:       "default",
+			},
+		}
+		proj := argoappv1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-b"},
+			Spec: argoappv1.AppProjectSpec{
+				Destinations: []argoappv1.ApplicationDestination{{Server: "*", Namespace: "default"}},
+				SourceRepos:  []string{"http://some/where"},
+			},
+		}
+		cluster := argoappv1.Cluster{Name: "team-a-cluster", Server: "https://127.0.0.1:6443", Project: "team-a", Labels: map[string]string{"env": "prod"}}
+		db := &dbmocks.ArgoDB{}
+		db.On("ListClusters", context.Background()).Return(&argoappv1.ClusterList{Items: []argoappv1.Cluster{cluster}}, nil)
+		db.On("GetCluster", context.Background(), cluster.Server).Return(&cluster, nil)
+		conditions, err := ValidatePermissions(context.Background(), &spec, &proj, db)
+		assert.NoError(t, err)
+		assert.Len(t, conditions, 1)
+		assert.Equal(t, "cluster 'https://127.0.0.1:6443' is scoped to project 'team-a' and is not permitted in project 'team-b'", conditions[0].Message)
+	})
+
 	t.Run("Destination cluster name does not exist", func(t *testing.T) {
 		spec := argoappv1.ApplicationSpec{
 			Source: &argoappv1.ApplicationSource{
@@ -969,6 +1140,123 @@ func TestValidateDestination(t *testing.T) {
 		assert.False(t, dest.IsServerInferred())
 	})
 
+	clusters := &argoappv1.ClusterList{Items: []argoappv1.Cluster{
+		{Name: "staging", Server: "https://127.0.0.1:2443", Labels: map[string]string{"env": "staging", "region": "eu"}},
+		{Name: "prod-eu", Server: "https://127.0.0.1:8443", Labels: map[string]string{"env": "prod", "region": "eu"}},
+		{Name: "prod-us", Server: "https://127.0.0.1:9443", Labels: map[string]string{"env": "prod", "region": "us"}},
+	}}
+
+	t.Run("Validate destination with cluster selector", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=staging",
+		}
+
+		db := &dbmocks.ArgoDB{}
+		db.On("ListClusters", context.Background()).Return(clusters, nil)
+
+		err := ValidateDestination(context.Background(), &dest, db)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://127.0.0.1:2443", dest.Server)
+		assert.True(t, dest.IsServerInferred())
+	})
+
+	t.Run("Validate too many clusters matching the cluster selector", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=prod",
+		}
+
+		db := &dbmocks.ArgoDB{}
+		db.On("ListClusters", context.Background()).Return(clusters, nil)
+
+		err := ValidateDestination(context.Background(), &dest, db)
+		assert.Equal(t, `unable to find destination server: there are 2 clusters matching the selector "env=prod": [https://127.0.0.1:8443 https://127.0.0.1:9443]`, err.Error())
+		assert.False(t, dest.IsServerInferred())
+	})
+
+	t.Run("No cluster matching the cluster selector", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=dev",
+		}
+
+		db := &dbmocks.ArgoDB{}
+		db.On("ListClusters", context.Background()).Return(clusters, nil)
+
+		err := ValidateDestination(context.Background(), &dest, db)
+		assert.Equal(t, `unable to find destination server: there are no clusters matching the selector "env=dev"`, err.Error())
+		assert.False(t, dest.IsServerInferred())
+	})
+
+	t.Run("Cluster selector is resolved again after the cluster labels changed", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=staging",
+		}
+		dest.SetInferredServer("https://127.0.0.1:8443")
+
+		db := &dbmocks.ArgoDB{}
+		db.On("ListClusters", context.Background()).Return(clusters, nil)
+
+		err := ValidateDestination(context.Background(), &dest, db)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://127.0.0.1:2443", dest.Server)
+	})
+
+	t.Run("Inferred server is cleared when no cluster matches the cluster selector anymore", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			ClusterSelector: "env=dev",
+		}
+		dest.SetInferredServer("https://127.0.0.1:2443")
+
+		db := &dbmocks.ArgoDB{}
+		db.On("ListClusters", context.Background()).Return(clusters, nil)
+
+		err := ValidateDestination(context.Background(), &dest, db)
+		assert.Error(t, err)
+		assert.Empty(t, dest.Server)
+	})
+
+	t.Run("Error when having both cluster selector and name", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			Name:            "staging",
+			ClusterSelector: "env=staging",
+		}
+
+		err := ValidateDestination(context.Background(), &dest, nil)
+		assert.Equal(t, "application destination can't have both name and cluster selector defined: staging env=staging", err.Error())
+	})
+
+	t.Run("Error when having both cluster selector and server url", func(t *testing.T) {
+		dest := argoappv1.ApplicationDestination{
+			Server:          "https://127.0.0.1:2443",
+			ClusterSelector: "env=staging",
+		}
+
+		err := ValidateDestination(context.Background(), &dest, nil)
+		assert.Equal(t, "application destination can't have both cluster selector and server defined: env=staging https://127.0.0.1:2443", err.Error())
+		assert.False(t, dest.IsServerInferred())
+	})
+}
+
+func TestGetClusterServerBySelector(t *testing.T) {
+	clusters := []argoappv1.Cluster{
+		{Name: "staging", Server: "https://127.0.0.1:2443", Labels: map[string]string{"env": "staging"}},
+		{Name: "prod", Server: "https://127.0.0.1:8443", Labels: map[string]string{"env": "prod"}},
+	}
+
+	server, err := GetClusterServerBySelector(clusters, "env=prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:8443", server)
+
+	_, err = GetClusterServerBySelector(clusters, "env in (staging,prod)")
+	assert.EqualError(t, err, `there are 2 clusters matching the selector "env in (staging,prod)": [https://127.0.0.1:2443 https://127.0.0.1:8443]`)
+
+	_, err = GetClusterServerBySelector(clusters, "env in (prod")
+	assert.Error(t, err)
+}
+
+func TestClusterSelectorMatches(t *testing.T) {
+	assert.True(t, ClusterSelectorMatches("env=prod", map[string]string{"env": "prod", "region": "eu"}))
+	assert.False(t, ClusterSelectorMatches("env=prod", map[string]string{"env": "staging"}))
+	assert.False(t, ClusterSelectorMatches("env in (prod", map[string]string{"env": "prod"}))
 }
 
 func TestFilterByName(t *testing.T) {
@@ -1132,6 +1420,49 @@ func TestGetGlobalProjects(t *testing.T) {
 	})
 }
 
+func TestMergeVirtualProject(t *testing.T) {
+	proj := &argoappv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+		Spec: argoappv1.AppProjectSpec{
+			SourceRepos:  []string{"https://github.com/team-a/*"},
+			Destinations: []argoappv1.ApplicationDestination{{Server: "*", Namespace: "team-a-*"}},
+			Quotas:       &argoappv1.ProjectQuotas{MaxApplications: 10},
+		},
+	}
+	globalProj := &argoappv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "org"},
+		Spec: argoappv1.AppProjectSpec{
+			SourceRepos:                     []string{"!https://github.com/forbidden/*"},
+			Destinations:                    []argoappv1.ApplicationDestination{{Server: "!https://prod-cluster", Namespace: "*"}},
+			NamespaceResourceBlacklist:      []metav1.GroupKind{{Group: "", Kind: "ResourceQuota"}},
+			SignatureKeys:                   []argoappv1.SignatureKey{{KeyID: "4AEE18F83AFDEB23"}},
+			PermitOnlyProjectScopedClusters: true,
+			RedactSecretData:                true,
+			DisableExec:                     true,
+			Quotas:                          &argoappv1.ProjectQuotas{MaxApplications: 50, MaxResourcesPerApplication: 500},
+		},
+	}
+
+	virtualProj := mergeVirtualProject(proj.DeepCopy(), globalProj)
+
+	assert.Equal(t, []string{"https://github.com/team-a/*", "!https://github.com/forbidden/*"}, virtualProj.Spec.SourceRepos)
+	assert.Len(t, virtualProj.Spec.Destinations, 2)
+	assert.Equal(t, []metav1.GroupKind{{Group: "", Kind: "ResourceQuota"}}, virtualProj.Spec.NamespaceResourceBlacklist)
+	assert.Equal(t, []argoappv1.SignatureKey{{KeyID: "4AEE18F83AFDEB23"}}, virtualProj.Spec.SignatureKeys)
+	assert.True(t, virtualProj.Spec.PermitOnlyProjectScopedClusters)
+	assert.True(t, virtualProj.Spec.RedactSecretData)
+	assert.False(t, virtualProj.Spec.DisableLogs)
+	// exec is disabled only by the global project
+	assert.True(t, virtualProj.Spec.DisableExec)
+	assert.False(t, virtualProj.IsSourcePermitted(argoappv1.ApplicationSource{RepoURL: "https://github.com/forbidden/repo"}))
+	assert.True(t, virtualProj.IsSourcePermitted(argoappv1.ApplicationSource{RepoURL: "https://github.com/team-a/repo"}))
+	// the stricter quota applies
+	assert.Equal(t, &argoappv1.ProjectQuotas{MaxApplications: 10, MaxResourcesPerApplication: 500}, virtualProj.Spec.Quotas)
+	assert.Equal(t, &argoappv1.ProjectQuotas{MaxApplications: 10}, proj.Spec.Quotas)
+
+	assert.Same(t, proj, mergeVirtualProject(proj, nil))
+}
+
 func Test_GetDifferentPathsBetweenStructs(t *testing.T) {
 
 	r1 := argoappv1.Repository{}
@@ -43,8 +43,12 @@ const (
 	EventReasonResourceActionRan  = "ResourceActionRan"
 	EventReasonOperationStarted   = "OperationStarted"
 	EventReasonOperationCompleted = "OperationCompleted"
+	EventReasonOperationDenied    = "OperationDenied"
 )
 
+// EventAnnotationProject is the annotation of the events of an application which holds the project of the application
+const EventAnnotationProject = "project"
+
 func (l *AuditLogger) logEvent(objMeta ObjectRef, gvk schema.GroupVersionKind, info EventInfo, message string, logFields map[string]string) {
 	logCtx := log.WithFields(log.Fields{
 		"type":   info.Type,
@@ -102,8 +106,9 @@ func (l *AuditLogger) LogAppEvent(app *v1alpha1.Application, info EventInfo, mes
 		UID:             app.ObjectMeta.UID,
 	}
 	fields := map[string]string{
-		"dest-server":    app.Spec.Destination.Server,
-		"dest-namespace": app.Spec.Destination.Namespace,
+		"dest-server":          app.Spec.Destination.Server,
+		"dest-namespace":       app.Spec.Destination.Namespace,
+		EventAnnotationProject: app.Spec.GetProject(),
 	}
 	if user != "" {
 		fields["user"] = user
@@ -154,7 +159,19 @@ func (l *AuditLogger) LogAppProjEvent(proj *v1alpha1.AppProject, info EventInfo,
 	if user != "" {
 		fields["user"] = user
 	}
-	l.logEvent(objectMeta, v1alpha1.AppProjectSchemaGroupVersionKind, info, message, nil)
+	l.logEvent(objectMeta, v1alpha1.AppProjectSchemaGroupVersionKind, info, message, fields)
+}
+
+// LogRepoEvent records an event of the repository with the given URL, which is stored in the given secret
+func (l *AuditLogger) LogRepoEvent(secret *v1.Secret, repoURL string, info EventInfo, message string) {
+	objectMeta := ObjectRef{
+		Name:            secret.Name,
+		Namespace:       secret.Namespace,
+		ResourceVersion: secret.ResourceVersion,
+		UID:             secret.UID,
+	}
+	fields := map[string]string{"repository": repoURL}
+	l.logEvent(objectMeta, v1.SchemeGroupVersion.WithKind("Secret"), info, message, fields)
 }
 
 func NewAuditLogger(ns string, kIf kubernetes.Interface, component string) *AuditLogger {
@@ -98,6 +98,7 @@ func TestLogAppEvent(t *testing.T) {
 	assert.Contains(t, output, "application=testapp")
 	assert.Contains(t, output, "dest-namespace=testns")
 	assert.Contains(t, output, "dest-server=\"https://127.0.0.1:6443\"")
+	assert.Contains(t, output, "project=default")
 	assert.Contains(t, output, "reason=test")
 	assert.Contains(t, output, "type=info")
 	assert.Contains(t, output, "msg=\"This is a test message\"")
@@ -0,0 +1,92 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/argoproj/argo-cd/v2/util/db"
+	"github.com/argoproj/argo-cd/v2/util/provenance"
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+// UsesExternalManifests returns whether the manifests of the application are rendered outside of Argo CD and submitted
+// using the API instead of being generated by the repo server
+func UsesExternalManifests(app *argoappv1.Application) bool {
+	enabled, _ := strconv.ParseBool(app.Annotations[common.AnnotationKeyExternalManifests])
+	return enabled && !app.Spec.HasMultipleSources()
+}
+
+// ExternalManifestsSubject returns the subject which the externally rendered manifests of the given application are
+// signed for. The destination server of the application must be resolved already.
+func ExternalManifestsSubject(app *argoappv1.Application) provenance.Subject {
+	return provenance.Subject{
+		Application: app.QualifiedName(),
+		Project:     app.Spec.GetProject(),
+		Server:      app.Spec.Destination.Server,
+		Namespace:   app.Spec.Destination.Namespace,
+	}
+}
+
+// GetExternalManifests returns the externally rendered manifests which were submitted for the given resolved revision of
+// the application. The signature of the manifests is verified again, so that manifests which were tampered with after
+// their submission or became untrusted are never used.
+func GetExternalManifests(ctx context.Context, argoDB db.ArgoDB, settingsMgr *settings.SettingsManager, app *argoappv1.Application, revision string) ([]string, error) {
+	rendered, err := argoDB.GetRenderedManifests(ctx, app, revision)
+	if err != nil {
+		return nil, fmt.Errorf("error getting rendered manifests: %w", err)
+	}
+	if rendered == nil {
+		return nil, fmt.Errorf("no rendered manifests were submitted for revision %s", revision)
+	}
+	keys, err := settingsMgr.GetManifestProvenanceKeys()
+	if err != nil {
+		return nil, fmt.Errorf("error getting manifest provenance keys: %w", err)
+	}
+	err = provenance.Verify(keys, provenance.Payload(ExternalManifestsSubject(app), rendered.Revision, rendered.Manifests), rendered.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify rendered manifests: %w", err)
+	}
+	return rendered.Manifests, nil
+}
+
+// GetExternalManifestsResponse returns the verified externally rendered manifests which were submitted for the given
+// resolved revision of the application, with the tracking information of the application set, like the repo server
+// would have generated them
+func GetExternalManifestsResponse(ctx context.Context, argoDB db.ArgoDB, settingsMgr *settings.SettingsManager, app *argoappv1.Application, revision string, appLabelKey string, instanceName string) (*apiclient.ManifestResponse, error) {
+	rendered, err := GetExternalManifests(ctx, argoDB, settingsMgr, app, revision)
+	if err != nil {
+		return nil, err
+	}
+	trackingMethod := GetTrackingMethod(settingsMgr)
+	resourceTracking := NewResourceTracking()
+	manifests := make([]string, 0, len(rendered))
+	for _, manifest := range rendered {
+		obj, err := argoappv1.UnmarshalToUnstructured(manifest)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			continue
+		}
+		err = resourceTracking.SetAppInstance(obj, appLabelKey, instanceName, app.Spec.Destination.Namespace, trackingMethod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set app instance tracking info on manifest: %w", err)
+		}
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, string(data))
+	}
+	return &apiclient.ManifestResponse{
+		Manifests: manifests,
+		Namespace: app.Spec.Destination.Namespace,
+		Server:    app.Spec.Destination.Server,
+		Revision:  revision,
+	}, nil
+}
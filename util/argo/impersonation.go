@@ -0,0 +1,42 @@
+package argo
+
+import (
+	"fmt"
+
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/rest"
+
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// ServiceAccountToImpersonate returns the user name of the service account which is impersonated when an application is
+// synced to the given destination server and namespace, or an empty string if its project doesn't configure destination
+// service accounts. The application can't be synced if the project configures destination service accounts, but none of
+// them matches the destination. The namespace must have its variables resolved.
+func ServiceAccountToImpersonate(proj *argoappv1.AppProject, server string, destinationNamespace string) (string, error) {
+	if len(proj.Spec.DestinationServiceAccounts) == 0 {
+		return "", nil
+	}
+	sa, ok := proj.GetDestinationServiceAccount(server, destinationNamespace)
+	if !ok {
+		return "", fmt.Errorf("no service account to impersonate is configured in project '%s' for destination server '%s' and namespace '%s'", proj.Name, server, destinationNamespace)
+	}
+	namespace, name := sa.GetServiceAccount(destinationNamespace)
+	if namespace == "" {
+		return "", fmt.Errorf("service account '%s' to impersonate has no namespace, since application has no destination namespace", sa.DefaultServiceAccount)
+	}
+	return serviceaccount.MakeUsername(namespace, name), nil
+}
+
+// ImpersonateServiceAccount makes the given config of a cluster impersonate the service account which the project
+// configures for the given destination server and namespace, if any
+func ImpersonateServiceAccount(config *rest.Config, proj *argoappv1.AppProject, server string, destinationNamespace string) error {
+	user, err := ServiceAccountToImpersonate(proj, server, destinationNamespace)
+	if err != nil {
+		return err
+	}
+	if user != "" {
+		config.Impersonate = rest.ImpersonationConfig{UserName: user}
+	}
+	return nil
+}
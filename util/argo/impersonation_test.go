@@ -0,0 +1,53 @@
+package argo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+func TestServiceAccountToImpersonate(t *testing.T) {
+	proj := &argoappv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+	}
+	app := &argoappv1.Application{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Server: "https://tenant-cluster", Namespace: "team-a"}}}
+
+	user, err := ServiceAccountToImpersonate(proj, app.Spec.Destination.Server, app.Spec.Destination.Namespace)
+	require.NoError(t, err)
+	assert.Empty(t, user)
+
+	proj.Spec.DestinationServiceAccounts = []argoappv1.ApplicationDestinationServiceAccount{
+		{Server: "https://tenant-cluster", Namespace: "team-*", DefaultServiceAccount: "deployer"},
+		{Server: "*", Namespace: "*", DefaultServiceAccount: "argocd:restricted"},
+	}
+	user, err = ServiceAccountToImpersonate(proj, app.Spec.Destination.Server, app.Spec.Destination.Namespace)
+	require.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:team-a:deployer", user)
+
+	app.Spec.Destination.Namespace = "other"
+	user, err = ServiceAccountToImpersonate(proj, app.Spec.Destination.Server, app.Spec.Destination.Namespace)
+	require.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:argocd:restricted", user)
+
+	proj.Spec.DestinationServiceAccounts = proj.Spec.DestinationServiceAccounts[:1]
+	_, err = ServiceAccountToImpersonate(proj, app.Spec.Destination.Server, app.Spec.Destination.Namespace)
+	assert.EqualError(t, err, "no service account to impersonate is configured in project 'tenant' for destination server 'https://tenant-cluster' and namespace 'other'")
+}
+
+func TestImpersonateServiceAccount(t *testing.T) {
+	proj := &argoappv1.AppProject{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}
+	config := &rest.Config{}
+	require.NoError(t, ImpersonateServiceAccount(config, proj, "https://tenant-cluster", "team-a"))
+	assert.Empty(t, config.Impersonate.UserName)
+
+	proj.Spec.DestinationServiceAccounts = []argoappv1.ApplicationDestinationServiceAccount{{Server: "*", Namespace: "team-*", DefaultServiceAccount: "deployer"}}
+	require.NoError(t, ImpersonateServiceAccount(config, proj, "https://tenant-cluster", "team-a"))
+	assert.Equal(t, "system:serviceaccount:team-a:deployer", config.Impersonate.UserName)
+
+	assert.Error(t, ImpersonateServiceAccount(&rest.Config{}, proj, "https://tenant-cluster", "other"))
+}
@@ -0,0 +1,194 @@
+package argo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/argoproj/gitops-engine/pkg/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+// MaskingRulesFor returns the masking rules which apply to resources of the given API group and kind
+func MaskingRulesFor(rules []settings.MaskingRule, apiGroup, kind string) []settings.MaskingRule {
+	var matched []settings.MaskingRule
+	for _, rule := range rules {
+		if rule.Match(apiGroup, kind) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// masker replaces values by masks. Like diff.HideSecretData, it replaces equal values by equal masks and different
+// values by masks of different length, so that the diff of masked states still shows which values changed.
+type masker struct {
+	nextMask      string
+	valueToMasked map[string]string
+}
+
+func newMasker() *masker {
+	// we use "+" rather than the more common "*", like diff.HideSecretData
+	return &masker{nextMask: "++++++++", valueToMasked: map[string]string{}}
+}
+
+func (m *masker) mask(value string) string {
+	masked, ok := m.valueToMasked[value]
+	if !ok {
+		masked = m.nextMask
+		m.nextMask = m.nextMask + "++++"
+		m.valueToMasked[value] = masked
+	}
+	return masked
+}
+
+func (m *masker) maskValue(value interface{}) interface{} {
+	if str, ok := value.(string); ok {
+		return m.mask(str)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return m.mask(fmt.Sprintf("%v", value))
+	}
+	return m.mask(string(data))
+}
+
+// maskJSONPointer replaces the value referenced by the given JSON pointer tokens within the given object by a mask, if
+// the value exists
+func maskJSONPointer(obj map[string]interface{}, tokens []string, m *masker) {
+	var parent interface{} = obj
+	for i, token := range tokens {
+		last := i == len(tokens)-1
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return
+			}
+			if last {
+				node[token] = m.maskValue(value)
+				return
+			}
+			parent = value
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return
+			}
+			if last {
+				node[index] = m.maskValue(node[index])
+				return
+			}
+			parent = node[index]
+		default:
+			return
+		}
+	}
+}
+
+// maskMatches replaces the parts of the string values within the given value which match the given regex by masks
+func maskMatches(value interface{}, re *regexp.Regexp, m *masker) interface{} {
+	switch node := value.(type) {
+	case string:
+		return re.ReplaceAllStringFunc(node, m.mask)
+	case map[string]interface{}:
+		for k, v := range node {
+			node[k] = maskMatches(v, re, m)
+		}
+	case []interface{}:
+		for i, v := range node {
+			node[i] = maskMatches(v, re, m)
+		}
+	}
+	return value
+}
+
+// maskObjectMatches masks the matches of the given regex within the given object, except for the fields identifying it
+func maskObjectMatches(obj map[string]interface{}, re *regexp.Regexp, m *masker) {
+	for k, v := range obj {
+		switch k {
+		case "apiVersion", "kind":
+		case "metadata":
+			if metadata, ok := v.(map[string]interface{}); ok {
+				for _, field := range []string{"annotations", "labels"} {
+					if values, ok := metadata[field]; ok {
+						metadata[field] = maskMatches(values, re, m)
+					}
+				}
+			}
+		default:
+			obj[k] = maskMatches(v, re, m)
+		}
+	}
+}
+
+// MaskResourceData masks the values of the given target and live states of a resource which are selected by the given
+// masking rules. The last applied configuration of the live state is masked as well. The given states are not modified.
+func MaskResourceData(rules []settings.MaskingRule, target, live *unstructured.Unstructured) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	obj := target
+	if obj == nil {
+		obj = live
+	}
+	if obj == nil {
+		return target, live, nil
+	}
+	gvk := obj.GroupVersionKind()
+	rules = MaskingRulesFor(rules, gvk.Group, gvk.Kind)
+	if len(rules) == 0 {
+		return target, live, nil
+	}
+
+	var orig *unstructured.Unstructured
+	if live != nil {
+		orig, _ = diff.GetLastAppliedConfigAnnotation(live)
+		live = live.DeepCopy()
+	}
+	if target != nil {
+		target = target.DeepCopy()
+	}
+
+	for _, rule := range rules {
+		for _, pointer := range rule.JSONPointers {
+			tokens, err := settings.ParseJSONPointer(pointer)
+			if err != nil {
+				return nil, nil, err
+			}
+			m := newMasker()
+			for _, obj := range []*unstructured.Unstructured{target, live, orig} {
+				if obj != nil {
+					maskJSONPointer(obj.Object, tokens, m)
+				}
+			}
+		}
+		for _, expr := range rule.Regexes {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+			}
+			m := newMasker()
+			for _, obj := range []*unstructured.Unstructured{target, live, orig} {
+				if obj != nil {
+					maskObjectMatches(obj.Object, re, m)
+				}
+			}
+		}
+	}
+
+	if live != nil && orig != nil {
+		annotations := live.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		lastAppliedData, err := json.Marshal(orig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshaling json: %w", err)
+		}
+		annotations[corev1.LastAppliedConfigAnnotation] = string(lastAppliedData)
+		live.SetAnnotations(annotations)
+	}
+	return target, live, nil
+}
@@ -0,0 +1,74 @@
+package argo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+func newConfigMap(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "my-config", "namespace": "default"},
+		"data":       data,
+	}}
+}
+
+func TestMaskResourceData(t *testing.T) {
+	rules := []settings.MaskingRule{{
+		Kinds:        []string{"ConfigMap"},
+		JSONPointers: []string{"/data/password", "/data/missing"},
+		Regexes:      []string{`token=\S+`},
+	}}
+
+	t.Run("JSONPointers", func(t *testing.T) {
+		target := newConfigMap(map[string]interface{}{"password": "foo", "user": "admin"})
+		live := newConfigMap(map[string]interface{}{"password": "bar", "user": "admin"})
+
+		maskedTarget, maskedLive, err := MaskResourceData(rules, target, live)
+		require.NoError(t, err)
+		assert.Equal(t, "++++++++", maskedTarget.Object["data"].(map[string]interface{})["password"])
+		assert.Equal(t, "++++++++++++", maskedLive.Object["data"].(map[string]interface{})["password"])
+		assert.Equal(t, "admin", maskedLive.Object["data"].(map[string]interface{})["user"])
+		// the given states are not modified
+		assert.Equal(t, "foo", target.Object["data"].(map[string]interface{})["password"])
+	})
+
+	t.Run("EqualValues", func(t *testing.T) {
+		maskedTarget, maskedLive, err := MaskResourceData(rules, newConfigMap(map[string]interface{}{"password": "foo"}), newConfigMap(map[string]interface{}{"password": "foo"}))
+		require.NoError(t, err)
+		assert.Equal(t, maskedTarget.Object["data"], maskedLive.Object["data"])
+	})
+
+	t.Run("Regexes", func(t *testing.T) {
+		target := newConfigMap(map[string]interface{}{"config": "url=https://example.com token=secret"})
+		target.SetName("token=name")
+		maskedTarget, _, err := MaskResourceData(rules, target, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "url=https://example.com ++++++++", maskedTarget.Object["data"].(map[string]interface{})["config"])
+		assert.Equal(t, "token=name", maskedTarget.GetName())
+	})
+
+	t.Run("LastAppliedConfiguration", func(t *testing.T) {
+		live := newConfigMap(map[string]interface{}{"password": "foo"})
+		live.SetAnnotations(map[string]string{corev1.LastAppliedConfigAnnotation: `{"apiVersion":"v1","kind":"ConfigMap","data":{"password":"foo"}}`})
+		_, maskedLive, err := MaskResourceData(rules, nil, live)
+		require.NoError(t, err)
+		assert.NotContains(t, maskedLive.GetAnnotations()[corev1.LastAppliedConfigAnnotation], "foo")
+		assert.Contains(t, maskedLive.GetAnnotations()[corev1.LastAppliedConfigAnnotation], "++++++++")
+	})
+
+	t.Run("NotMatched", func(t *testing.T) {
+		target := newConfigMap(map[string]interface{}{"password": "foo"})
+		target.SetKind("Other")
+		maskedTarget, _, err := MaskResourceData(rules, target, nil)
+		require.NoError(t, err)
+		assert.Same(t, target, maskedTarget)
+	})
+}
@@ -1,9 +1,12 @@
 package normalizers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/diff"
 	jsonpatch "github.com/evanphx/json-patch"
@@ -16,6 +19,10 @@ import (
 	"github.com/argoproj/argo-cd/v2/util/glob"
 )
 
+// jqExecutionTimeout bounds the time a single jqPathExpressions evaluation may take, so that an expensive
+// expression cannot stall the comparison of the whole application
+var jqExecutionTimeout = 1 * time.Second
+
 type normalizerPatch interface {
 	GetGroupKind() schema.GroupKind
 	GetNamespace() string
@@ -67,11 +74,20 @@ func (np *jqNormalizerPatch) Apply(data []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	iter := np.code.Run(dataJson)
+	ctx, cancel := context.WithTimeout(context.Background(), jqExecutionTimeout)
+	defer cancel()
+
+	iter := np.code.RunWithContext(ctx, dataJson)
 	first, ok := iter.Next()
 	if !ok {
 		return nil, fmt.Errorf("JQ patch did not return any data")
 	}
+	if err, ok = first.(error); ok {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("JQ patch execution timed out (%v)", jqExecutionTimeout.String())
+		}
+		return nil, fmt.Errorf("JQ patch returned error: %w", err)
+	}
 	_, ok = iter.Next()
 	if ok {
 		return nil, fmt.Errorf("JQ patch returned multiple objects")
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -222,6 +223,35 @@ func TestNormalizeJQPathExpressionWithError(t *testing.T) {
 	assert.Equal(t, originalDeployment, normalizedDeployment)
 }
 
+func TestNormalizeJQPathExpressionTimeout(t *testing.T) {
+	normalizer, err := NewIgnoreNormalizer([]v1alpha1.ResourceIgnoreDifferences{{
+		Group:             "apps",
+		Kind:              "Deployment",
+		JQPathExpressions: []string{".spec.template.spec.containers[]"},
+	}}, make(map[string]v1alpha1.ResourceOverride))
+	assert.Nil(t, err)
+
+	defaultTimeout := jqExecutionTimeout
+	jqExecutionTimeout = time.Nanosecond
+	defer func() { jqExecutionTimeout = defaultTimeout }()
+
+	deployment := test.NewDeployment()
+	deploymentData, err := json.Marshal(deployment)
+	assert.Nil(t, err)
+
+	_, err = normalizer.(*ignoreNormalizer).patches[0].Apply(deploymentData)
+	assert.ErrorContains(t, err, "JQ patch execution timed out")
+
+	// a timed out expression leaves the resource untouched
+	originalDeployment, err := deployment.MarshalJSON()
+	assert.Nil(t, err)
+	err = normalizer.Normalize(deployment)
+	assert.Nil(t, err)
+	normalizedDeployment, err := deployment.MarshalJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, originalDeployment, normalizedDeployment)
+}
+
 func TestNormalizeExpectedErrorAreSilenced(t *testing.T) {
 	normalizer, err := NewIgnoreNormalizer([]v1alpha1.ResourceIgnoreDifferences{}, map[string]v1alpha1.ResourceOverride{
 		"*/*": {
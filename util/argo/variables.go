@@ -0,0 +1,211 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/db"
+)
+
+const (
+	// SpecVariableClusterName is the name of the destination cluster
+	SpecVariableClusterName = "cluster.name"
+	// SpecVariableClusterServer is the API server URL of the destination cluster
+	SpecVariableClusterServer = "cluster.server"
+	// SpecVariableProject is the project of the application
+	SpecVariableProject = "project"
+)
+
+// specVariableRegex matches the variables of application specs, e.g. {{cluster.name}} or {{ project }}
+var specVariableRegex = regexp.MustCompile(`{{\s*(cluster\.name|cluster\.server|project)\s*}}`)
+
+// SpecVariables returns the values of the variables of an application of the given project deployed to the given cluster
+func SpecVariables(project string, cluster *argoappv1.Cluster) map[string]string {
+	return map[string]string{
+		SpecVariableClusterName:   cluster.Name,
+		SpecVariableClusterServer: cluster.Server,
+		SpecVariableProject:       project,
+	}
+}
+
+// GetSpecVariables returns the values of the variables of the given application spec. The destination server is
+// looked up by its name or cluster selector if it has not been resolved yet.
+func GetSpecVariables(ctx context.Context, spec *argoappv1.ApplicationSpec, db db.ArgoDB) (map[string]string, error) {
+	server := spec.Destination.Server
+	if server == "" {
+		var err error
+		if spec.Destination.ClusterSelector != "" {
+			server, err = getDestinationServerBySelector(ctx, db, spec.Destination.ClusterSelector)
+		} else {
+			server, err = getDestinationServer(ctx, db, spec.Destination.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	cluster, err := db.GetCluster(ctx, server)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster %s to resolve the variables of the application spec: %w", server, err)
+	}
+	return SpecVariables(spec.GetProject(), cluster), nil
+}
+
+// ResolveAppSpecVariables returns the given application if its spec uses no variables, or a copy of it in which the
+// variables of the spec are resolved
+func ResolveAppSpecVariables(ctx context.Context, app *argoappv1.Application, db db.ArgoDB) (*argoappv1.Application, error) {
+	if !HasSpecVariables(&app.Spec) {
+		return app, nil
+	}
+	variables, err := GetSpecVariables(ctx, &app.Spec, db)
+	if err != nil {
+		return nil, err
+	}
+	resolved := app.DeepCopy()
+	resolved.Spec = *ResolveSpecVariables(&app.Spec, variables)
+	return resolved, nil
+}
+
+// GetDestinationNamespace returns the destination namespace of the given application spec, in which the variables are
+// resolved
+func GetDestinationNamespace(ctx context.Context, spec *argoappv1.ApplicationSpec, db db.ArgoDB) (string, error) {
+	if !specVariableRegex.MatchString(spec.Destination.Namespace) {
+		return spec.Destination.Namespace, nil
+	}
+	variables, err := GetSpecVariables(ctx, spec, db)
+	if err != nil {
+		return "", err
+	}
+	return resolveVariables(spec.Destination.Namespace, variables), nil
+}
+
+// HasSpecVariables returns true if the destination namespace or the values of any source of the spec use variables
+func HasSpecVariables(spec *argoappv1.ApplicationSpec) bool {
+	found := false
+	detect := func(s string) string {
+		if !found && specVariableRegex.MatchString(s) {
+			found = true
+		}
+		return s
+	}
+	detect(spec.Destination.Namespace)
+	if spec.Source != nil {
+		source := spec.Source.DeepCopy()
+		visitSourceValues(source, detect)
+	}
+	for i := range spec.Sources {
+		source := spec.Sources[i].DeepCopy()
+		visitSourceValues(source, detect)
+	}
+	return found
+}
+
+// ResolveSpecVariables returns a copy of the spec in which the variables of the destination namespace and of the
+// source values are replaced by the given values. Unknown variables are kept as they are.
+func ResolveSpecVariables(spec *argoappv1.ApplicationSpec, variables map[string]string) *argoappv1.ApplicationSpec {
+	resolved := spec.DeepCopy()
+	resolved.Destination.Namespace = resolveVariables(spec.Destination.Namespace, variables)
+	if resolved.Source != nil {
+		visitSourceValues(resolved.Source, func(s string) string { return resolveVariables(s, variables) })
+	}
+	for i := range resolved.Sources {
+		visitSourceValues(&resolved.Sources[i], func(s string) string { return resolveVariables(s, variables) })
+	}
+	return resolved
+}
+
+// ResolveSourceVariables returns a copy of the source in which the variables of the values are replaced by the given values
+func ResolveSourceVariables(source argoappv1.ApplicationSource, variables map[string]string) argoappv1.ApplicationSource {
+	resolved := source.DeepCopy()
+	visitSourceValues(resolved, func(s string) string { return resolveVariables(s, variables) })
+	return *resolved
+}
+
+func resolveVariables(s string, variables map[string]string) string {
+	return specVariableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := variables[specVariableRegex.FindStringSubmatch(match)[1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// visitSourceValues replaces the values passed to the config management tool of the source by the result of fn
+func visitSourceValues(source *argoappv1.ApplicationSource, fn func(string) string) {
+	if helm := source.Helm; helm != nil {
+		helm.Values = fn(helm.Values)
+		for i := range helm.Parameters {
+			helm.Parameters[i].Value = fn(helm.Parameters[i].Value)
+		}
+		if helm.ValuesObject != nil && len(helm.ValuesObject.Raw) > 0 {
+			var values interface{}
+			if err := json.Unmarshal(helm.ValuesObject.Raw, &values); err == nil {
+				if raw, err := json.Marshal(visitJSONStrings(values, fn)); err == nil {
+					helm.ValuesObject = &runtime.RawExtension{Raw: raw}
+				}
+			}
+		}
+	}
+	if kustomize := source.Kustomize; kustomize != nil {
+		kustomize.NamePrefix = fn(kustomize.NamePrefix)
+		kustomize.NameSuffix = fn(kustomize.NameSuffix)
+		kustomize.Namespace = fn(kustomize.Namespace)
+		for k, v := range kustomize.CommonLabels {
+			kustomize.CommonLabels[k] = fn(v)
+		}
+		for k, v := range kustomize.CommonAnnotations {
+			kustomize.CommonAnnotations[k] = fn(v)
+		}
+	}
+	if directory := source.Directory; directory != nil {
+		for i := range directory.Jsonnet.ExtVars {
+			directory.Jsonnet.ExtVars[i].Value = fn(directory.Jsonnet.ExtVars[i].Value)
+		}
+		for i := range directory.Jsonnet.TLAs {
+			directory.Jsonnet.TLAs[i].Value = fn(directory.Jsonnet.TLAs[i].Value)
+		}
+	}
+	if plugin := source.Plugin; plugin != nil {
+		for _, entry := range plugin.Env {
+			entry.Value = fn(entry.Value)
+		}
+		for i := range plugin.Parameters {
+			param := &plugin.Parameters[i]
+			if param.String_ != nil {
+				value := fn(*param.String_)
+				param.String_ = &value
+			}
+			if param.OptionalMap != nil {
+				for k, v := range param.OptionalMap.Map {
+					param.OptionalMap.Map[k] = fn(v)
+				}
+			}
+			if param.OptionalArray != nil {
+				for j, v := range param.OptionalArray.Array {
+					param.OptionalArray.Array[j] = fn(v)
+				}
+			}
+		}
+	}
+}
+
+// visitJSONStrings replaces all strings of a decoded JSON value by the result of fn
+func visitJSONStrings(value interface{}, fn func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = visitJSONStrings(item, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = visitJSONStrings(item, fn)
+		}
+	}
+	return value
+}
@@ -0,0 +1,105 @@
+package argo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	dbmocks "github.com/argoproj/argo-cd/v2/util/db/mocks"
+)
+
+func TestResolveSpecVariables(t *testing.T) {
+	spec := &argoappv1.ApplicationSpec{
+		Project:     "team-a",
+		Destination: argoappv1.ApplicationDestination{Server: "https://cluster-1.example.com", Namespace: "{{project}}-{{ cluster.name }}"},
+		Source: &argoappv1.ApplicationSource{
+			RepoURL: "https://github.com/argoproj/argocd-example-apps.git",
+			Path:    "{{cluster.name}}",
+			Helm: &argoappv1.ApplicationSourceHelm{
+				Values:       "cluster: {{cluster.name}}\nserver: {{cluster.server}}\nunknown: {{cluster.region}}",
+				Parameters:   []argoappv1.HelmParameter{{Name: "project", Value: "{{project}}"}},
+				ValuesObject: &runtime.RawExtension{Raw: []byte(`{"clusters":["{{cluster.name}}"]}`)},
+			},
+		},
+	}
+	assert.True(t, HasSpecVariables(spec))
+
+	resolved := ResolveSpecVariables(spec, SpecVariables(spec.Project, &argoappv1.Cluster{Name: "cluster-1", Server: "https://cluster-1.example.com"}))
+	assert.Equal(t, "team-a-cluster-1", resolved.Destination.Namespace)
+	assert.Equal(t, "cluster: cluster-1\nserver: https://cluster-1.example.com\nunknown: {{cluster.region}}", resolved.Source.Helm.Values)
+	assert.Equal(t, "team-a", resolved.Source.Helm.Parameters[0].Value)
+	assert.JSONEq(t, `{"clusters":["cluster-1"]}`, string(resolved.Source.Helm.ValuesObject.Raw))
+	// only values are resolved
+	assert.Equal(t, "{{cluster.name}}", resolved.Source.Path)
+
+	// the spec itself is not modified
+	assert.Equal(t, "{{project}}-{{ cluster.name }}", spec.Destination.Namespace)
+	assert.Equal(t, "{{project}}", spec.Source.Helm.Parameters[0].Value)
+}
+
+func TestResolveSourceVariables(t *testing.T) {
+	source := argoappv1.ApplicationSource{
+		Kustomize: &argoappv1.ApplicationSourceKustomize{NamePrefix: "{{cluster.name}}-", CommonLabels: map[string]string{"cluster": "{{cluster.name}}"}},
+		Plugin:    &argoappv1.ApplicationSourcePlugin{Env: argoappv1.Env{{Name: "CLUSTER", Value: "{{cluster.name}}"}}},
+	}
+	resolved := ResolveSourceVariables(source, map[string]string{SpecVariableClusterName: "cluster-1"})
+	assert.Equal(t, "cluster-1-", resolved.Kustomize.NamePrefix)
+	assert.Equal(t, "cluster-1", resolved.Kustomize.CommonLabels["cluster"])
+	assert.Equal(t, "cluster-1", resolved.Plugin.Env[0].Value)
+	assert.Equal(t, "{{cluster.name}}", source.Plugin.Env[0].Value)
+}
+
+func TestHasSpecVariables(t *testing.T) {
+	assert.False(t, HasSpecVariables(&argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Namespace: "default"}}))
+	assert.False(t, HasSpecVariables(&argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Namespace: "{{app.name}}"}}))
+	assert.True(t, HasSpecVariables(&argoappv1.ApplicationSpec{Sources: argoappv1.ApplicationSources{
+		{Directory: &argoappv1.ApplicationSourceDirectory{Jsonnet: argoappv1.ApplicationSourceJsonnet{ExtVars: []argoappv1.JsonnetVar{{Name: "cluster", Value: "{{cluster.server}}"}}}}},
+	}}))
+}
+
+func TestGetSpecVariables(t *testing.T) {
+	db := &dbmocks.ArgoDB{}
+	db.On("GetClusterServersByName", context.Background(), "cluster-1").Return([]string{"https://cluster-1.example.com"}, nil)
+	db.On("GetCluster", context.Background(), "https://cluster-1.example.com").Return(&argoappv1.Cluster{Name: "cluster-1", Server: "https://cluster-1.example.com"}, nil)
+
+	variables, err := GetSpecVariables(context.Background(), &argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Name: "cluster-1"}}, db)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		SpecVariableClusterName:   "cluster-1",
+		SpecVariableClusterServer: "https://cluster-1.example.com",
+		SpecVariableProject:       "default",
+	}, variables)
+}
+
+func TestGetDestinationNamespace(t *testing.T) {
+	db := &dbmocks.ArgoDB{}
+	db.On("GetCluster", context.Background(), "https://cluster-1.example.com").Return(&argoappv1.Cluster{Name: "cluster-1", Server: "https://cluster-1.example.com"}, nil)
+
+	namespace, err := GetDestinationNamespace(context.Background(), &argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Server: "https://cluster-1.example.com", Namespace: "{{cluster.name}}-apps"}}, db)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-1-apps", namespace)
+
+	namespace, err = GetDestinationNamespace(context.Background(), &argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Server: "https://unknown.example.com", Namespace: "apps"}}, db)
+	require.NoError(t, err)
+	assert.Equal(t, "apps", namespace)
+}
+
+func TestResolveAppSpecVariables(t *testing.T) {
+	db := &dbmocks.ArgoDB{}
+	db.On("GetCluster", context.Background(), "https://cluster-1.example.com").Return(&argoappv1.Cluster{Name: "cluster-1", Server: "https://cluster-1.example.com"}, nil)
+
+	app := &argoappv1.Application{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Server: "https://cluster-1.example.com", Namespace: "{{cluster.name}}"}}}
+	resolved, err := ResolveAppSpecVariables(context.Background(), app, db)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-1", resolved.Spec.Destination.Namespace)
+	assert.Equal(t, "{{cluster.name}}", app.Spec.Destination.Namespace)
+
+	app = &argoappv1.Application{Spec: argoappv1.ApplicationSpec{Destination: argoappv1.ApplicationDestination{Server: "https://cluster-1.example.com", Namespace: "default"}}}
+	resolved, err = ResolveAppSpecVariables(context.Background(), app, db)
+	require.NoError(t, err)
+	assert.Same(t, app, resolved)
+}
@@ -0,0 +1,45 @@
+package argo
+
+import (
+	"github.com/argoproj/gitops-engine/pkg/health"
+
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// WaitOptions are the states an application, or its resources, are waited for to reach
+type WaitOptions struct {
+	Sync      bool
+	Health    bool
+	Operation bool
+	Suspended bool
+	Degraded  bool
+}
+
+// IsReached returns whether the given health and sync status and operation match the states which are waited for.
+// If several health states are waited for, reaching any of them is sufficient.
+func (o WaitOptions) IsReached(healthStatus string, syncStatus string, operation *argoappv1.Operation) bool {
+	healthCheckPassed := true
+	if o.Health || o.Suspended || o.Degraded {
+		healthCheckPassed = (o.Health && healthStatus == string(health.HealthStatusHealthy)) ||
+			(o.Suspended && healthStatus == string(health.HealthStatusSuspended)) ||
+			(o.Degraded && healthStatus == string(health.HealthStatusDegraded))
+	}
+	synced := !o.Sync || syncStatus == string(argoappv1.SyncStatusCodeSynced)
+	operational := !o.Operation || operation == nil
+	return synced && healthCheckPassed && operational
+}
+
+// IsOperationInProgress returns whether an operation of the application was requested or is running, or whether it
+// finished but the application wasn't reconciled since, so that its status doesn't reflect the operation yet
+func IsOperationInProgress(app *argoappv1.Application) bool {
+	if app.Operation != nil {
+		return true
+	}
+	if app.Status.OperationState == nil {
+		return false
+	}
+	if app.Status.OperationState.FinishedAt == nil {
+		return true
+	}
+	return !app.Status.OperationState.Operation.DryRun() && (app.Status.ReconciledAt == nil || app.Status.ReconciledAt.Before(app.Status.OperationState.FinishedAt))
+}
@@ -0,0 +1,43 @@
+package argo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoappv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+func TestWaitOptions_IsReached(t *testing.T) {
+	healthy := string(health.HealthStatusHealthy)
+	synced := string(argoappv1.SyncStatusCodeSynced)
+	assert.True(t, WaitOptions{}.IsReached(string(health.HealthStatusProgressing), "", &argoappv1.Operation{}))
+	assert.True(t, WaitOptions{Sync: true, Health: true, Operation: true}.IsReached(healthy, synced, nil))
+	assert.False(t, WaitOptions{Operation: true}.IsReached(healthy, synced, &argoappv1.Operation{}))
+	assert.False(t, WaitOptions{Sync: true}.IsReached(healthy, string(argoappv1.SyncStatusCodeOutOfSync), nil))
+	assert.True(t, WaitOptions{Health: true, Degraded: true}.IsReached(string(health.HealthStatusDegraded), synced, nil))
+	assert.False(t, WaitOptions{Health: true, Suspended: true}.IsReached(string(health.HealthStatusDegraded), synced, nil))
+}
+
+func TestIsOperationInProgress(t *testing.T) {
+	finishedAt := metav1.NewTime(time.Now())
+	reconciledBefore := metav1.NewTime(finishedAt.Add(-time.Minute))
+	reconciledAfter := metav1.NewTime(finishedAt.Add(time.Minute))
+
+	assert.False(t, IsOperationInProgress(&argoappv1.Application{}))
+	assert.True(t, IsOperationInProgress(&argoappv1.Application{Operation: &argoappv1.Operation{}}))
+	assert.True(t, IsOperationInProgress(&argoappv1.Application{Status: argoappv1.ApplicationStatus{
+		OperationState: &argoappv1.OperationState{},
+	}}))
+	assert.True(t, IsOperationInProgress(&argoappv1.Application{Status: argoappv1.ApplicationStatus{
+		OperationState: &argoappv1.OperationState{FinishedAt: &finishedAt},
+		ReconciledAt:   &reconciledBefore,
+	}}))
+	assert.False(t, IsOperationInProgress(&argoappv1.Application{Status: argoappv1.ApplicationStatus{
+		OperationState: &argoappv1.OperationState{FinishedAt: &finishedAt},
+		ReconciledAt:   &reconciledAfter,
+	}}))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/argoproj/argo-cd/v2/common"
 	certutil "github.com/argoproj/argo-cd/v2/util/cert"
 	"github.com/argoproj/argo-cd/v2/util/env"
+	"github.com/argoproj/argo-cd/v2/util/faultinjection"
 )
 
 const (
@@ -136,7 +137,7 @@ func AddCacheFlagsToCmd(cmd *cobra.Command, opts ...func(client *redis.Client))
 			for i := range opts {
 				opts[i](client)
 			}
-			return NewCache(NewRedisCache(client, defaultCacheExpiration, compression)), nil
+			return newCacheWithFaultInjection(NewRedisCache(client, defaultCacheExpiration, compression)), nil
 		}
 		if redisAddress == "" {
 			redisAddress = common.DefaultRedisAddr
@@ -146,10 +147,21 @@ func AddCacheFlagsToCmd(cmd *cobra.Command, opts ...func(client *redis.Client))
 		for i := range opts {
 			opts[i](client)
 		}
-		return NewCache(NewRedisCache(client, defaultCacheExpiration, compression)), nil
+		return newCacheWithFaultInjection(NewRedisCache(client, defaultCacheExpiration, compression)), nil
 	}
 }
 
+// newCacheWithFaultInjection creates a cache which injects the faults configured in the environment, if any, into the
+// operations of the given client
+func newCacheWithFaultInjection(client CacheClient) *Cache {
+	faults := faultinjection.ConfigFromEnv(common.EnvFaultInjectionCacheDelay, common.EnvFaultInjectionCacheFailureRate)
+	if faults.Enabled() {
+		faults.WarnIfEnabled("cache operations")
+		client = NewFaultInjectionClient(client, faults)
+	}
+	return NewCache(client)
+}
+
 // Cache provides strongly types methods to store and retrieve values from shared cache
 type Cache struct {
 	client CacheClient
@@ -6,6 +6,10 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/util/faultinjection"
 )
 
 func TestAddCacheFlagsToCmd(t *testing.T) {
@@ -14,6 +18,33 @@ func TestAddCacheFlagsToCmd(t *testing.T) {
 	assert.Equal(t, 24*time.Hour, cache.client.(*redisCache).expiration)
 }
 
+func TestAddCacheFlagsToCmd_FaultInjection(t *testing.T) {
+	t.Setenv(common.EnvFaultInjectionCacheFailureRate, "1")
+	cache, err := AddCacheFlagsToCmd(&cobra.Command{})()
+	require.NoError(t, err)
+	assert.IsType(t, &faultInjectionClient{}, cache.client)
+}
+
+func TestFaultInjectionClient(t *testing.T) {
+	client := NewInMemoryCache(60 * time.Second)
+	t.Run("NoFaults", func(t *testing.T) {
+		cache := NewCache(NewFaultInjectionClient(client, faultinjection.Config{}))
+		require.NoError(t, cache.SetItem("foo", "bar", 60*time.Second, false))
+		var val string
+		require.NoError(t, cache.GetItem("foo", &val))
+		assert.Equal(t, "bar", val)
+	})
+	t.Run("Failures", func(t *testing.T) {
+		cache := NewCache(NewFaultInjectionClient(client, faultinjection.Config{FailureRate: 1}))
+		assert.ErrorIs(t, cache.SetItem("foo", "baz", 60*time.Second, false), faultinjection.ErrInjected)
+		var val string
+		assert.ErrorIs(t, cache.GetItem("foo", &val), faultinjection.ErrInjected)
+		// the failed set didn't change the value
+		require.NoError(t, NewCache(client).GetItem("foo", &val))
+		assert.Equal(t, "bar", val)
+	})
+}
+
 func TestCacheClient(t *testing.T) {
 	client := NewInMemoryCache(60 * time.Second)
 	cache := NewCache(client)
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/util/faultinjection"
+)
+
+// NewFaultInjectionClient creates a cache client which delays and fails the operations of the given client as configured.
+// It is meant to validate HA and retry configurations in test environments only.
+func NewFaultInjectionClient(client CacheClient, faults faultinjection.Config) CacheClient {
+	return &faultInjectionClient{client: client, faults: faults}
+}
+
+// compile-time validation of adherance of the CacheClient contract
+var _ CacheClient = &faultInjectionClient{}
+
+type faultInjectionClient struct {
+	client CacheClient
+	faults faultinjection.Config
+}
+
+func (c *faultInjectionClient) inject(operation, key string) error {
+	if err := c.faults.Inject(context.Background()); err != nil {
+		return fmt.Errorf("cache %s of key '%s' failed: %w", operation, key, err)
+	}
+	return nil
+}
+
+func (c *faultInjectionClient) Set(item *Item) error {
+	if err := c.inject("set", item.Key); err != nil {
+		return err
+	}
+	return c.client.Set(item)
+}
+
+func (c *faultInjectionClient) Get(key string, obj interface{}) error {
+	if err := c.inject("get", key); err != nil {
+		return err
+	}
+	return c.client.Get(key, obj)
+}
+
+func (c *faultInjectionClient) Delete(key string) error {
+	if err := c.inject("delete", key); err != nil {
+		return err
+	}
+	return c.client.Delete(key)
+}
+
+func (c *faultInjectionClient) OnUpdated(ctx context.Context, key string, callback func() error) error {
+	return c.client.OnUpdated(ctx, key, callback)
+}
+
+func (c *faultInjectionClient) NotifyUpdated(key string) error {
+	if err := c.inject("notification", key); err != nil {
+		return err
+	}
+	return c.client.NotifyUpdated(key)
+}
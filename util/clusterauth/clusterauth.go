@@ -26,6 +26,10 @@ const (
 	ArgoCDManagerClusterRoleBinding = "argocd-manager-role-binding"
 )
 
+// newTokenSecretTimeout is the maximum duration Kubernetes may take to populate the token of a new secret. Make it
+// overridable for testing.
+var newTokenSecretTimeout = 30 * time.Second
+
 // ArgoCDManagerPolicyRules are the policies to give argocd-manager
 var ArgoCDManagerClusterPolicyRules = []rbacv1.PolicyRule{
 	{
@@ -418,9 +422,10 @@ func GenerateNewClusterManagerSecret(clientset kubernetes.Interface, claims *Ser
 	if err != nil {
 		return nil, err
 	}
+	createdName := created.Name
 
-	err = wait.Poll(500*time.Millisecond, 30*time.Second, func() (bool, error) {
-		created, err = secretsClient.Get(context.Background(), created.Name, metav1.GetOptions{})
+	err = wait.Poll(500*time.Millisecond, newTokenSecretTimeout, func() (bool, error) {
+		created, err = secretsClient.Get(context.Background(), createdName, metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -430,11 +435,25 @@ func GenerateNewClusterManagerSecret(clientset kubernetes.Interface, claims *Ser
 		return true, nil
 	})
 	if err != nil {
+		// don't leave a secret behind which might still get a valid token
+		if err := secretsClient.Delete(context.Background(), createdName, metav1.DeleteOptions{}); err != nil && !apierr.IsNotFound(err) {
+			log.Warnf("Failed to delete secret %s/%s which timed out generating a new token: %v", claims.Namespace, createdName, err)
+		}
 		return nil, fmt.Errorf("Timed out waiting for secret to generate new token")
 	}
 	return created, nil
 }
 
+// DiscardClusterManagerSecret deletes a secret created by GenerateNewClusterManagerSecret, e.g. because its token
+// could not be stored, so that a failed rotation doesn't leave additional valid tokens behind
+func DiscardClusterManagerSecret(clientset kubernetes.Interface, claims *ServiceAccountClaims, newSecret *corev1.Secret) error {
+	err := clientset.CoreV1().Secrets(claims.Namespace).Delete(context.Background(), newSecret.Name, metav1.DeleteOptions{})
+	if err != nil && !apierr.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // RotateServiceAccountSecrets rotates the entries in the service accounts secrets list
 func RotateServiceAccountSecrets(clientset kubernetes.Interface, claims *ServiceAccountClaims, newSecret *corev1.Secret) error {
 	// 1. update service account secrets list with new secret name while also removing the old name
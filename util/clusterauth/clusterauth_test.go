@@ -208,6 +208,30 @@ func TestGenerateNewClusterManagerSecret(t *testing.T) {
 	assert.Equal(t, "fake-token", string(created.Data["token"]))
 }
 
+func TestGenerateNewClusterManagerSecret_Timeout(t *testing.T) {
+	defer func(timeout time.Duration) { newTokenSecretTimeout = timeout }(newTokenSecretTimeout)
+	newTokenSecretTimeout = time.Second
+
+	kubeclientset := fake.NewSimpleClientset(newServiceAccountSecret())
+	// the token of the generated secret is never populated
+	kubeclientset.PrependReactor("create", "secrets", func(action kubetesting.Action) (handled bool, ret runtime.Object, err error) {
+		generatedSecret := action.(kubetesting.CreateAction).GetObject().(*corev1.Secret).DeepCopy()
+		generatedSecret.Name = generatedSecret.GenerateName + "abc123"
+		generatedSecret.Namespace = action.GetNamespace()
+		return true, generatedSecret, kubeclientset.Tracker().Add(generatedSecret)
+	})
+
+	_, err := GenerateNewClusterManagerSecret(kubeclientset, &testClaims)
+	assert.EqualError(t, err, "Timed out waiting for secret to generate new token")
+
+	// the generated secret is deleted, the existing one is kept
+	secretsClient := kubeclientset.CoreV1().Secrets(testClaims.Namespace)
+	_, err = secretsClient.Get(context.Background(), "argocd-manager-token-abc123", metav1.GetOptions{})
+	assert.True(t, apierr.IsNotFound(err))
+	_, err = secretsClient.Get(context.Background(), testClaims.SecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
 func TestRotateServiceAccountSecrets(t *testing.T) {
 	generatedSecret := newServiceAccountSecret()
 	generatedSecret.Name = "argocd-manager-token-abc123"
@@ -234,6 +258,27 @@ func TestRotateServiceAccountSecrets(t *testing.T) {
 	assert.True(t, apierr.IsNotFound(err))
 }
 
+func TestDiscardClusterManagerSecret(t *testing.T) {
+	generatedSecret := newServiceAccountSecret()
+	generatedSecret.Name = "argocd-manager-token-abc123"
+
+	kubeclientset := fake.NewSimpleClientset(newServiceAccount(), newServiceAccountSecret(), generatedSecret)
+
+	err := DiscardClusterManagerSecret(kubeclientset, &testClaims, generatedSecret)
+	assert.NoError(t, err)
+
+	// Verify only the generated secret is deleted
+	secretsClient := kubeclientset.CoreV1().Secrets(testClaims.Namespace)
+	_, err = secretsClient.Get(context.Background(), generatedSecret.Name, metav1.GetOptions{})
+	assert.True(t, apierr.IsNotFound(err))
+	_, err = secretsClient.Get(context.Background(), testClaims.SecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// Deleting a secret which doesn't exist anymore succeeds
+	err = DiscardClusterManagerSecret(kubeclientset, &testClaims, generatedSecret)
+	assert.NoError(t, err)
+}
+
 func TestGetServiceAccountBearerToken(t *testing.T) {
 	sa := newServiceAccount()
 	tokenSecret := newServiceAccountSecret()
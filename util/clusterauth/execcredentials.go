@@ -0,0 +1,305 @@
+package clusterauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// execCredentialTimeout is the maximum duration an exec credential plugin may run
+	execCredentialTimeout = 1 * time.Minute
+	// execCredentialRefreshRatio is the share of the credential lifetime after which credentials are refreshed proactively
+	execCredentialRefreshRatio = 0.8
+	// execCredentialRetryInterval is the interval in which failed proactive refreshes are retried
+	execCredentialRetryInterval = 30 * time.Second
+	// execCredentialIdleTimeout is the duration after which credentials which are not used anymore are not refreshed anymore
+	execCredentialIdleTimeout = 24 * time.Hour
+	// execCredentialExpiryDelta is the duration before the expiry in which credentials are not used anymore
+	execCredentialExpiryDelta = 10 * time.Second
+)
+
+var (
+	// execCredentialSources holds the shared credential source of every cluster and exec configuration
+	execCredentialSources sync.Map
+
+	clusterCredentialExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_cluster_credential_expiry_timestamp_seconds",
+			Help: "Expiry of the cluster credentials obtained from exec credential plugins in seconds since the epoch.",
+		},
+		[]string{"server"},
+	)
+	clusterCredentialRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "argocd_cluster_credential_refresh_total",
+			Help: "Number of cluster credential refreshes using exec credential plugins.",
+		},
+		[]string{"server", "failed"},
+	)
+)
+
+// ExecCredentialCollectors returns the metrics of the cluster credentials obtained from exec credential plugins
+func ExecCredentialCollectors() []prometheus.Collector {
+	return []prometheus.Collector{clusterCredentialExpiry, clusterCredentialRefreshes}
+}
+
+// execCredentials are the credentials returned by an exec credential plugin: a bearer token, a client certificate or both
+type execCredentials struct {
+	token       string
+	certificate *tls.Certificate
+	expiry      time.Time
+}
+
+// ExecCredentialSource provides the credentials of an exec credential plugin, i.e. bearer tokens and client
+// certificates. Credentials are cached until shortly before they expire and are refreshed in the background while they
+// are used, so that requests never have to wait for the plugin and never use credentials which are about to expire.
+type ExecCredentialSource struct {
+	server string
+	config *clientcmdapi.ExecConfig
+	now    func() time.Time
+
+	lock        sync.Mutex
+	credentials *execCredentials
+	issuedAt    time.Time
+	lastUsed    time.Time
+	timer       *time.Timer
+	// idle is true if the proactive refresh was stopped because the credentials were not used for a long time
+	idle bool
+}
+
+// GetExecCredentialSource returns the credential source shared by all clients of the given server and exec configuration
+func GetExecCredentialSource(server string, config *clientcmdapi.ExecConfig) *ExecCredentialSource {
+	key := execCredentialKey(server, config)
+	if s, ok := execCredentialSources.Load(key); ok {
+		return s.(*ExecCredentialSource)
+	}
+	s, _ := execCredentialSources.LoadOrStore(key, newExecCredentialSource(server, config))
+	return s.(*ExecCredentialSource)
+}
+
+func newExecCredentialSource(server string, config *clientcmdapi.ExecConfig) *ExecCredentialSource {
+	return &ExecCredentialSource{server: server, config: config.DeepCopy(), now: time.Now}
+}
+
+func execCredentialKey(server string, config *clientcmdapi.ExecConfig) string {
+	env := make([]string, len(config.Env))
+	for i, e := range config.Env {
+		env[i] = e.Name + "=" + e.Value
+	}
+	return strings.Join([]string{server, config.APIVersion, config.Command, strings.Join(config.Args, " "), strings.Join(env, " ")}, "|")
+}
+
+// getCredentials returns the cached credentials, or runs the exec credential plugin if there are no valid credentials
+func (s *ExecCredentialSource) getCredentials() (*execCredentials, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	s.lastUsed = now
+	if s.credentials != nil && (s.credentials.expiry.IsZero() || now.Add(execCredentialExpiryDelta).Before(s.credentials.expiry)) {
+		if s.idle {
+			// resume the proactive refresh of a cluster which was idle
+			s.scheduleLocked(s.refreshDelay(now))
+		}
+		return s.credentials, nil
+	}
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	return s.credentials, nil
+}
+
+// GetClientCertificate returns the client certificate of the exec credential plugin, or an empty certificate if the
+// plugin only returns bearer tokens. It is meant to be used as GetClientCertificate function of a TLS configuration.
+func (s *ExecCredentialSource) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	credentials, err := s.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if credentials.certificate == nil {
+		return &tls.Certificate{}, nil
+	}
+	return credentials.certificate, nil
+}
+
+// WrapTransport returns a round tripper which authenticates the requests with the bearer token of the exec credential
+// plugin, if it returns one. Credentials which are rejected by the cluster are dropped, so that the next request runs
+// the plugin again.
+func (s *ExecCredentialSource) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &execCredentialRoundTripper{source: s, rt: rt}
+}
+
+// resetCredentialsOlderThan drops the cached credentials if they were issued before the given time
+func (s *ExecCredentialSource) resetCredentialsOlderThan(t time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.credentials != nil && s.issuedAt.Before(t) {
+		s.credentials = nil
+	}
+}
+
+// refreshLocked runs the exec credential plugin and schedules the next proactive refresh. Must be called with the lock held.
+func (s *ExecCredentialSource) refreshLocked() error {
+	credentials, err := runExecCredentialPlugin(s.config, s.server)
+	clusterCredentialRefreshes.WithLabelValues(s.server, fmt.Sprintf("%t", err != nil)).Inc()
+	if err != nil {
+		return fmt.Errorf("failed to get credentials of cluster %s from exec plugin %s: %w", s.server, s.config.Command, err)
+	}
+	s.credentials = credentials
+	s.issuedAt = s.now()
+	if credentials.expiry.IsZero() {
+		clusterCredentialExpiry.DeleteLabelValues(s.server)
+		return nil
+	}
+	clusterCredentialExpiry.WithLabelValues(s.server).Set(float64(credentials.expiry.Unix()))
+	s.scheduleLocked(s.refreshDelay(s.issuedAt))
+	return nil
+}
+
+// refreshDelay returns the duration from now until the credentials should be refreshed
+func (s *ExecCredentialSource) refreshDelay(now time.Time) time.Duration {
+	lifetime := s.credentials.expiry.Sub(s.issuedAt)
+	refreshAt := s.issuedAt.Add(time.Duration(float64(lifetime) * execCredentialRefreshRatio))
+	if d := refreshAt.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func (s *ExecCredentialSource) scheduleLocked(d time.Duration) {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.idle = false
+	s.timer = time.AfterFunc(d, s.refreshInBackground)
+}
+
+// refreshInBackground refreshes the credentials before they expire, unless they have not been used for a long time
+func (s *ExecCredentialSource) refreshInBackground() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	if now.Sub(s.lastUsed) > execCredentialIdleTimeout {
+		log.Debugf("Stopping proactive refresh of the credentials of idle cluster %s", s.server)
+		s.idle = true
+		return
+	}
+	err := s.refreshLocked()
+	if err == nil {
+		return
+	}
+	if s.credentials != nil && !s.credentials.expiry.IsZero() && now.Before(s.credentials.expiry) {
+		log.Warnf("Failed to refresh credentials of cluster %s which expire at %s, retrying in %s: %v", s.server, s.credentials.expiry.UTC().Format(time.RFC3339), execCredentialRetryInterval, err)
+		s.scheduleLocked(execCredentialRetryInterval)
+		return
+	}
+	log.Warnf("Failed to refresh expired credentials of cluster %s: %v", s.server, err)
+}
+
+type execCredentialRoundTripper struct {
+	source *ExecCredentialSource
+	rt     http.RoundTripper
+}
+
+func (rt *execCredentialRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a request which already has credentials, e.g. an impersonated one, is sent unchanged
+	if req.Header.Get("Authorization") != "" {
+		return rt.rt.RoundTrip(req)
+	}
+	credentials, err := rt.source.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if credentials.token != "" {
+		req = utilnet.CloneRequest(req)
+		req.Header.Set("Authorization", "Bearer "+credentials.token)
+	}
+	sent := rt.source.now()
+	res, err := rt.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		rt.source.resetCredentialsOlderThan(sent)
+	}
+	return res, nil
+}
+
+func (rt *execCredentialRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.rt
+}
+
+// execCredential is the subset of the client.authentication.k8s.io ExecCredential used by Argo CD
+type execCredential struct {
+	Status *struct {
+		Token                 string     `json:"token"`
+		ClientCertificateData string     `json:"clientCertificateData"`
+		ClientKeyData         string     `json:"clientKeyData"`
+		ExpirationTimestamp   *time.Time `json:"expirationTimestamp,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+func runExecCredentialPlugin(config *clientcmdapi.ExecConfig, server string) (*execCredentials, error) {
+	apiVersion := config.APIVersion
+	if apiVersion == "" {
+		apiVersion = "client.authentication.k8s.io/v1beta1"
+	}
+	execInfo, err := json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), execCredentialTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, config.Command, config.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(execInfo))
+	for _, e := range config.Env {
+		cmd.Env = append(cmd.Env, e.Name+"="+e.Value)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cred execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return nil, fmt.Errorf("failed to decode exec credential: %w", err)
+	}
+	if cred.Status == nil {
+		return nil, fmt.Errorf("exec credential of cluster %s has no status", server)
+	}
+	credentials := &execCredentials{token: cred.Status.Token}
+	if cred.Status.ClientCertificateData != "" || cred.Status.ClientKeyData != "" {
+		certificate, err := tls.X509KeyPair([]byte(cred.Status.ClientCertificateData), []byte(cred.Status.ClientKeyData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate of exec credential of cluster %s: %w", server, err)
+		}
+		credentials.certificate = &certificate
+	}
+	if credentials.token == "" && credentials.certificate == nil {
+		return nil, fmt.Errorf("exec credential of cluster %s contains neither a token nor a client certificate", server)
+	}
+	if cred.Status.ExpirationTimestamp != nil {
+		credentials.expiry = *cred.Status.ExpirationTimestamp
+	}
+	return credentials, nil
+}
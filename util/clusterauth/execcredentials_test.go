@@ -0,0 +1,171 @@
+package clusterauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// fakeExecPlugin writes a plugin which returns a new token, valid for the given duration, on every invocation
+func fakeExecPlugin(t *testing.T, validFor time.Duration) (*clientcmdapi.ExecConfig, string) {
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "counter")
+	script := filepath.Join(dir, "plugin.sh")
+	err := os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/sh
+echo x >> %s
+count=$(wc -l < %s | tr -d ' ')
+expiry=$(date -u -d "@$(( $(date +%%s) + %d ))" +%%Y-%%m-%%dT%%H:%%M:%%SZ)
+echo "{\"apiVersion\":\"client.authentication.k8s.io/v1beta1\",\"kind\":\"ExecCredential\",\"status\":{\"token\":\"token-$count\",\"expirationTimestamp\":\"$expiry\"}}"
+`, counter, counter, int(validFor.Seconds()))), 0700)
+	require.NoError(t, err)
+	return &clientcmdapi.ExecConfig{Command: script, APIVersion: "client.authentication.k8s.io/v1beta1"}, counter
+}
+
+// staticExecPlugin writes a plugin which returns the given status
+func staticExecPlugin(t *testing.T, status map[string]string) *clientcmdapi.ExecConfig {
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "client.authentication.k8s.io/v1beta1",
+		"kind":       "ExecCredential",
+		"status":     status,
+	})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "credential.json"), data, 0600))
+	script := filepath.Join(dir, "plugin.sh")
+	require.NoError(t, os.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\ncat %s\n", filepath.Join(dir, "credential.json"))), 0700))
+	return &clientcmdapi.ExecConfig{Command: script}
+}
+
+func invocations(t *testing.T, counter string) int {
+	data, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+func TestExecCredentialSource_Token(t *testing.T) {
+	config, counter := fakeExecPlugin(t, time.Hour)
+	s := newExecCredentialSource("https://cluster.example.com", config)
+
+	credentials, err := s.getCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", credentials.token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), credentials.expiry, time.Minute)
+
+	// the token is cached
+	credentials, err = s.getCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", credentials.token)
+	assert.Equal(t, 1, invocations(t, counter))
+
+	// rejected tokens are replaced
+	s.resetCredentialsOlderThan(time.Now().Add(time.Second))
+	credentials, err = s.getCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", credentials.token)
+}
+
+func TestExecCredentialSource_WrapTransport(t *testing.T) {
+	config, counter := fakeExecPlugin(t, time.Hour)
+	s := newExecCredentialSource("https://cluster.example.com", config)
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if len(authorizations) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: s.WrapTransport(http.DefaultTransport)}
+
+	for i := 0; i < 2; i++ {
+		res, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+	// the rejected token is replaced
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authorizations)
+	assert.Equal(t, 2, invocations(t, counter))
+}
+
+func TestExecCredentialSource_ProactiveRefresh(t *testing.T) {
+	config, counter := fakeExecPlugin(t, 2*time.Second)
+	s := newExecCredentialSource("https://cluster.example.com", config)
+
+	credentials, err := s.getCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", credentials.token)
+
+	// the token is refreshed in the background before it expires
+	assert.Eventually(t, func() bool {
+		return invocations(t, counter) >= 2
+	}, 5*time.Second, 100*time.Millisecond)
+	credentials, err = s.getCredentials()
+	require.NoError(t, err)
+	assert.NotEqual(t, "token-1", credentials.token)
+}
+
+func TestExecCredentialSource_IdleCluster(t *testing.T) {
+	config, counter := fakeExecPlugin(t, time.Hour)
+	s := newExecCredentialSource("https://cluster.example.com", config)
+	_, err := s.getCredentials()
+	require.NoError(t, err)
+
+	// credentials of clusters which are not used anymore are not refreshed
+	s.now = func() time.Time { return time.Now().Add(execCredentialIdleTimeout + time.Hour) }
+	s.refreshInBackground()
+	assert.Equal(t, 1, invocations(t, counter))
+	assert.True(t, s.idle)
+}
+
+func TestExecCredentialSource_ClientCertificate(t *testing.T) {
+	cert, err := os.ReadFile("../../test/fixture/certs/argocd-test-client.crt")
+	require.NoError(t, err)
+	key, err := os.ReadFile("../../test/fixture/certs/argocd-test-client.key")
+	require.NoError(t, err)
+
+	t.Run("Certificate", func(t *testing.T) {
+		s := newExecCredentialSource("https://cluster.example.com", staticExecPlugin(t, map[string]string{
+			"clientCertificateData": string(cert),
+			"clientKeyData":         string(key),
+		}))
+		certificate, err := s.GetClientCertificate(nil)
+		require.NoError(t, err)
+		assert.NotEmpty(t, certificate.Certificate)
+	})
+	t.Run("TokenOnly", func(t *testing.T) {
+		s := newExecCredentialSource("https://cluster.example.com", staticExecPlugin(t, map[string]string{"token": "token"}))
+		certificate, err := s.GetClientCertificate(nil)
+		require.NoError(t, err)
+		assert.Empty(t, certificate.Certificate)
+	})
+	t.Run("InvalidCertificate", func(t *testing.T) {
+		s := newExecCredentialSource("https://cluster.example.com", staticExecPlugin(t, map[string]string{
+			"clientCertificateData": "cert",
+			"clientKeyData":         "key",
+		}))
+		_, err := s.GetClientCertificate(nil)
+		assert.ErrorContains(t, err, "failed to parse client certificate")
+	})
+	t.Run("NoCredentials", func(t *testing.T) {
+		s := newExecCredentialSource("https://cluster.example.com", staticExecPlugin(t, map[string]string{}))
+		_, err := s.getCredentials()
+		assert.ErrorContains(t, err, "contains neither a token nor a client certificate")
+	})
+}
+
+func TestGetExecCredentialSource(t *testing.T) {
+	config := &clientcmdapi.ExecConfig{Command: "argocd-k8s-auth", Args: []string{"aws", "--cluster-name", "a"}}
+	s := GetExecCredentialSource("https://a.example.com", config)
+	assert.Same(t, s, GetExecCredentialSource("https://a.example.com", config.DeepCopy()))
+	assert.NotSame(t, s, GetExecCredentialSource("https://b.example.com", config))
+}
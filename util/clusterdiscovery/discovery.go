@@ -0,0 +1,143 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/db"
+)
+
+// Provider enumerates the clusters of a cloud provider
+type Provider interface {
+	// Name returns the name of the provider, which is stored in the discovered-by label of the cluster secrets
+	Name() string
+	// Scope returns the scope of the clusters listed by the provider, e.g. the region and the filters, which is stored in
+	// the discovery-scope annotation of the cluster secrets
+	Scope() string
+	// ListClusters returns the clusters of the provider, including the configuration to connect to them
+	ListClusters(ctx context.Context) ([]DiscoveredCluster, error)
+}
+
+// DiscoveredCluster is a cluster of a cloud provider
+type DiscoveredCluster struct {
+	appv1.Cluster
+	// Unavailable is set if the cluster exists, but can't be connected to at the moment, e.g. because it is being created,
+	// updated or has failed. The cluster secret of an unavailable cluster is neither created, updated nor deleted.
+	Unavailable bool
+}
+
+// Result contains the servers of the clusters which were changed by the reconciliation
+type Result struct {
+	Created []string
+	Updated []string
+	Deleted []string
+}
+
+// Reconcile creates or updates the cluster secrets of the available clusters discovered by the provider. When prune is
+// set, the cluster secrets which were discovered by the provider before, but whose clusters don't exist anymore, are
+// deleted, if they were discovered in the same scope, e.g. in the same region. The cluster secrets of clusters which
+// still exist, but are unavailable, are kept unchanged. Cluster secrets which weren't created by the discovery are never
+// changed.
+func Reconcile(ctx context.Context, argoDB db.ArgoDB, provider Provider, prune bool, dryRun bool) (*Result, error) {
+	discovered, err := provider.ListClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing clusters of %s: %w", provider.Name(), err)
+	}
+	existing, err := argoDB.ListClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing clusters: %w", err)
+	}
+	existingByServer := map[string]appv1.Cluster{}
+	for _, c := range existing.Items {
+		existingByServer[c.Server] = c
+	}
+
+	result := &Result{}
+	discoveredServers := map[string]bool{}
+	discoveredNames := map[string]bool{}
+	for i := range discovered {
+		cluster := discovered[i].Cluster
+		discoveredNames[cluster.Name] = true
+		if cluster.Server != "" {
+			discoveredServers[cluster.Server] = true
+		}
+		if discovered[i].Unavailable {
+			log.Infof("Skipping cluster %s discovered by %s, since it is unavailable", cluster.Name, provider.Name())
+			continue
+		}
+		if cluster.Labels == nil {
+			cluster.Labels = map[string]string{}
+		}
+		cluster.Labels[common.LabelKeyClusterDiscoveredBy] = provider.Name()
+		if cluster.Annotations == nil {
+			cluster.Annotations = map[string]string{}
+		}
+		cluster.Annotations[common.AnnotationKeyClusterDiscoveryScope] = provider.Scope()
+
+		current, ok := existingByServer[cluster.Server]
+		if !ok {
+			log.Infof("Creating cluster %s discovered by %s", cluster.Server, provider.Name())
+			if !dryRun {
+				if _, err := argoDB.CreateCluster(ctx, &cluster); err != nil {
+					return nil, fmt.Errorf("error creating cluster %s: %w", cluster.Server, err)
+				}
+			}
+			result.Created = append(result.Created, cluster.Server)
+			continue
+		}
+		if current.Labels[common.LabelKeyClusterDiscoveredBy] != provider.Name() {
+			log.Warnf("Skipping cluster %s discovered by %s, since it wasn't created by the discovery", cluster.Server, provider.Name())
+			continue
+		}
+		// settings of the cluster which aren't discovered, e.g. the project or the namespaces, are kept
+		updated := current.DeepCopy()
+		updated.Name = cluster.Name
+		updated.Config = cluster.Config
+		updated.Labels = cluster.Labels
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[common.AnnotationKeyClusterDiscoveryScope] = provider.Scope()
+		if reflect.DeepEqual(updated.Name, current.Name) && reflect.DeepEqual(updated.Config, current.Config) && reflect.DeepEqual(updated.Labels, current.Labels) && reflect.DeepEqual(updated.Annotations, current.Annotations) {
+			continue
+		}
+		log.Infof("Updating cluster %s discovered by %s", cluster.Server, provider.Name())
+		if !dryRun {
+			if _, err := argoDB.UpdateCluster(ctx, updated); err != nil {
+				return nil, fmt.Errorf("error updating cluster %s: %w", cluster.Server, err)
+			}
+		}
+		result.Updated = append(result.Updated, cluster.Server)
+	}
+
+	if prune {
+		for _, c := range existing.Items {
+			// the clusters discovered in another scope, e.g. another region, aren't listed by the provider, so their secrets
+			// are kept
+			if c.Labels[common.LabelKeyClusterDiscoveredBy] != provider.Name() || c.Annotations[common.AnnotationKeyClusterDiscoveryScope] != provider.Scope() {
+				continue
+			}
+			// the endpoint of an unavailable cluster might be unknown, so its secret is matched by name as well
+			if discoveredServers[c.Server] || discoveredNames[c.Name] {
+				continue
+			}
+			log.Infof("Deleting cluster %s which was discovered by %s and doesn't exist anymore", c.Server, provider.Name())
+			if !dryRun {
+				if err := argoDB.DeleteCluster(ctx, c.Server); err != nil {
+					return nil, fmt.Errorf("error deleting cluster %s: %w", c.Server, err)
+				}
+			}
+			result.Deleted = append(result.Deleted, c.Server)
+		}
+	}
+	sort.Strings(result.Created)
+	sort.Strings(result.Updated)
+	sort.Strings(result.Deleted)
+	return result, nil
+}
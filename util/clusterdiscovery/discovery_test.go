@@ -0,0 +1,112 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	dbmocks "github.com/argoproj/argo-cd/v2/util/db/mocks"
+)
+
+type fakeProvider struct {
+	clusters []DiscoveredCluster
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) Scope() string {
+	return "region=a"
+}
+
+func (p *fakeProvider) ListClusters(_ context.Context) ([]DiscoveredCluster, error) {
+	return p.clusters, nil
+}
+
+func discoveredLabels() map[string]string {
+	return map[string]string{common.LabelKeyClusterDiscoveredBy: "fake"}
+}
+
+func discoveryAnnotations(scope string) map[string]string {
+	return map[string]string{common.AnnotationKeyClusterDiscoveryScope: scope}
+}
+
+func TestReconcile(t *testing.T) {
+	existing := &appv1.ClusterList{Items: []appv1.Cluster{
+		{Name: "manual", Server: "https://manual"},
+		{Name: "unchanged", Server: "https://unchanged", Labels: discoveredLabels(), Annotations: discoveryAnnotations("region=a")},
+		{Name: "changed", Server: "https://changed", Labels: discoveredLabels(), Annotations: discoveryAnnotations("region=a"), Project: "my-project"},
+		{Name: "removed", Server: "https://removed", Labels: discoveredLabels(), Annotations: discoveryAnnotations("region=a")},
+		{Name: "updating", Server: "https://updating", Labels: discoveredLabels(), Annotations: discoveryAnnotations("region=a")},
+		{Name: "failed", Server: "https://failed", Labels: discoveredLabels(), Annotations: discoveryAnnotations("region=a")},
+		// clusters discovered in another scope aren't listed by the provider
+		{Name: "other-region", Server: "https://other-region", Labels: discoveredLabels(), Annotations: discoveryAnnotations("region=b")},
+	}}
+	provider := &fakeProvider{clusters: []DiscoveredCluster{
+		{Cluster: appv1.Cluster{Name: "manual", Server: "https://manual"}},
+		{Cluster: appv1.Cluster{Name: "unchanged", Server: "https://unchanged"}},
+		{Cluster: appv1.Cluster{Name: "changed", Server: "https://changed", Config: appv1.ClusterConfig{AWSAuthConfig: &appv1.AWSAuthConfig{ClusterName: "changed"}}}},
+		{Cluster: appv1.Cluster{Name: "new", Server: "https://new"}},
+		{Cluster: appv1.Cluster{Name: "updating", Server: "https://updating"}, Unavailable: true},
+		// the endpoint of a failed cluster might be unknown
+		{Cluster: appv1.Cluster{Name: "failed"}, Unavailable: true},
+		{Cluster: appv1.Cluster{Name: "creating"}, Unavailable: true},
+	}}
+
+	t.Run("Prune", func(t *testing.T) {
+		argoDB := &dbmocks.ArgoDB{}
+		argoDB.On("ListClusters", mock.Anything).Return(existing, nil)
+		argoDB.On("CreateCluster", mock.Anything, mock.Anything).Return(nil, nil)
+		argoDB.On("UpdateCluster", mock.Anything, mock.Anything).Return(nil, nil)
+		argoDB.On("DeleteCluster", mock.Anything, mock.Anything).Return(nil)
+
+		result, err := Reconcile(context.Background(), argoDB, provider, true, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://new"}, result.Created)
+		assert.Equal(t, []string{"https://changed"}, result.Updated)
+		assert.Equal(t, []string{"https://removed"}, result.Deleted)
+
+		argoDB.AssertCalled(t, "CreateCluster", mock.Anything, mock.MatchedBy(func(c *appv1.Cluster) bool {
+			return c.Server == "https://new" && c.Labels[common.LabelKeyClusterDiscoveredBy] == "fake" && c.Annotations[common.AnnotationKeyClusterDiscoveryScope] == "region=a"
+		}))
+		// settings which aren't discovered are kept
+		argoDB.AssertCalled(t, "UpdateCluster", mock.Anything, mock.MatchedBy(func(c *appv1.Cluster) bool {
+			return c.Server == "https://changed" && c.Project == "my-project" && c.Config.AWSAuthConfig != nil
+		}))
+		argoDB.AssertCalled(t, "DeleteCluster", mock.Anything, "https://removed")
+		argoDB.AssertNumberOfCalls(t, "UpdateCluster", 1)
+		argoDB.AssertNumberOfCalls(t, "DeleteCluster", 1)
+	})
+
+	t.Run("NoPrune", func(t *testing.T) {
+		argoDB := &dbmocks.ArgoDB{}
+		argoDB.On("ListClusters", mock.Anything).Return(existing, nil)
+		argoDB.On("CreateCluster", mock.Anything, mock.Anything).Return(nil, nil)
+		argoDB.On("UpdateCluster", mock.Anything, mock.Anything).Return(nil, nil)
+
+		result, err := Reconcile(context.Background(), argoDB, provider, false, false)
+		require.NoError(t, err)
+		assert.Empty(t, result.Deleted)
+		argoDB.AssertNotCalled(t, "DeleteCluster", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		argoDB := &dbmocks.ArgoDB{}
+		argoDB.On("ListClusters", mock.Anything).Return(existing, nil)
+
+		result, err := Reconcile(context.Background(), argoDB, provider, true, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://new"}, result.Created)
+		assert.Equal(t, []string{"https://changed"}, result.Updated)
+		assert.Equal(t, []string{"https://removed"}, result.Deleted)
+		argoDB.AssertNotCalled(t, "CreateCluster", mock.Anything, mock.Anything)
+		argoDB.AssertNotCalled(t, "UpdateCluster", mock.Anything, mock.Anything)
+		argoDB.AssertNotCalled(t, "DeleteCluster", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,101 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+type eksProvider struct {
+	client  eksiface.EKSAPI
+	region  string
+	tags    map[string]string
+	roleARN string
+}
+
+// NewEKSProvider returns a provider of the EKS clusters of the region of the client which have all the given tags.
+// Clusters which aren't active are reported as unavailable. The discovered clusters are accessed with the AWS
+// credentials of Argo CD, or by assuming the given role if it is set.
+func NewEKSProvider(client eksiface.EKSAPI, region string, tags map[string]string, roleARN string) Provider {
+	return &eksProvider{client: client, region: region, tags: tags, roleARN: roleARN}
+}
+
+func (p *eksProvider) Name() string {
+	return "eks"
+}
+
+// Scope returns the region and the sorted tags of the provider, e.g. 'region=eu-west-1,tag:argocd=true'
+func (p *eksProvider) Scope() string {
+	scope := []string{"region=" + p.region}
+	var tags []string
+	for k, v := range p.tags {
+		tags = append(tags, fmt.Sprintf("tag:%s=%s", k, v))
+	}
+	sort.Strings(tags)
+	return strings.Join(append(scope, tags...), ",")
+}
+
+func (p *eksProvider) ListClusters(ctx context.Context) ([]DiscoveredCluster, error) {
+	var names []string
+	err := p.client.ListClustersPagesWithContext(ctx, &eks.ListClustersInput{}, func(out *eks.ListClustersOutput, _ bool) bool {
+		names = append(names, aws.StringValueSlice(out.Clusters)...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing EKS clusters: %w", err)
+	}
+	var clusters []DiscoveredCluster
+	for _, name := range names {
+		out, err := p.client.DescribeClusterWithContext(ctx, &eks.DescribeClusterInput{Name: aws.String(name)})
+		if err != nil {
+			return nil, fmt.Errorf("error describing EKS cluster %s: %w", name, err)
+		}
+		c := out.Cluster
+		if c == nil || !p.matchesTags(c.Tags) {
+			continue
+		}
+		if aws.StringValue(c.Status) != eks.ClusterStatusActive || aws.StringValue(c.Endpoint) == "" {
+			clusters = append(clusters, DiscoveredCluster{
+				Cluster:     appv1.Cluster{Name: name, Server: aws.StringValue(c.Endpoint)},
+				Unavailable: true,
+			})
+			continue
+		}
+		cluster := appv1.Cluster{
+			Name:   name,
+			Server: aws.StringValue(c.Endpoint),
+			Config: appv1.ClusterConfig{
+				AWSAuthConfig: &appv1.AWSAuthConfig{
+					ClusterName: name,
+					RoleARN:     p.roleARN,
+				},
+			},
+		}
+		if c.CertificateAuthority != nil && c.CertificateAuthority.Data != nil {
+			caData, err := base64.StdEncoding.DecodeString(aws.StringValue(c.CertificateAuthority.Data))
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the certificate authority of EKS cluster %s: %w", name, err)
+			}
+			cluster.Config.TLSClientConfig.CAData = caData
+		}
+		clusters = append(clusters, DiscoveredCluster{Cluster: cluster})
+	}
+	return clusters, nil
+}
+
+func (p *eksProvider) matchesTags(tags map[string]*string) bool {
+	for k, v := range p.tags {
+		if value, ok := tags[k]; !ok || aws.StringValue(value) != v {
+			return false
+		}
+	}
+	return true
+}
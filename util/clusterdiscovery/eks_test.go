@@ -0,0 +1,83 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEKSClient struct {
+	eksiface.EKSAPI
+	clusters map[string]*eks.Cluster
+}
+
+func (c *fakeEKSClient) ListClustersPagesWithContext(_ aws.Context, _ *eks.ListClustersInput, fn func(*eks.ListClustersOutput, bool) bool, _ ...request.Option) error {
+	var names []*string
+	for name := range c.clusters {
+		names = append(names, aws.String(name))
+	}
+	fn(&eks.ListClustersOutput{Clusters: names}, true)
+	return nil
+}
+
+func (c *fakeEKSClient) DescribeClusterWithContext(_ aws.Context, in *eks.DescribeClusterInput, _ ...request.Option) (*eks.DescribeClusterOutput, error) {
+	return &eks.DescribeClusterOutput{Cluster: c.clusters[aws.StringValue(in.Name)]}, nil
+}
+
+func TestEKSProvider(t *testing.T) {
+	client := &fakeEKSClient{clusters: map[string]*eks.Cluster{
+		"staging": {
+			Status:               aws.String(eks.ClusterStatusActive),
+			Endpoint:             aws.String("https://staging.eks.amazonaws.com"),
+			CertificateAuthority: &eks.Certificate{Data: aws.String(base64.StdEncoding.EncodeToString([]byte("ca")))},
+			Tags:                 map[string]*string{"argocd": aws.String("true")},
+		},
+		"creating": {
+			Status: aws.String(eks.ClusterStatusCreating),
+			Tags:   map[string]*string{"argocd": aws.String("true")},
+		},
+		"updating": {
+			Status:   aws.String(eks.ClusterStatusUpdating),
+			Endpoint: aws.String("https://updating.eks.amazonaws.com"),
+			Tags:     map[string]*string{"argocd": aws.String("true")},
+		},
+		"untagged": {
+			Status:   aws.String(eks.ClusterStatusActive),
+			Endpoint: aws.String("https://untagged.eks.amazonaws.com"),
+		},
+	}}
+
+	provider := NewEKSProvider(client, "eu-west-1", map[string]string{"argocd": "true"}, "arn:aws:iam::123456789012:role/argocd")
+	clusters, err := provider.ListClusters(context.Background())
+	require.NoError(t, err)
+	require.Len(t, clusters, 3)
+	byName := map[string]DiscoveredCluster{}
+	for _, c := range clusters {
+		byName[c.Name] = c
+	}
+
+	staging := byName["staging"]
+	assert.False(t, staging.Unavailable)
+	assert.Equal(t, "https://staging.eks.amazonaws.com", staging.Server)
+	assert.Equal(t, []byte("ca"), staging.Config.TLSClientConfig.CAData)
+	if assert.NotNil(t, staging.Config.AWSAuthConfig) {
+		assert.Equal(t, "staging", staging.Config.AWSAuthConfig.ClusterName)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/argocd", staging.Config.AWSAuthConfig.RoleARN)
+	}
+	// clusters which aren't active are reported, so that their secrets aren't pruned
+	assert.True(t, byName["creating"].Unavailable)
+	assert.True(t, byName["updating"].Unavailable)
+	assert.Equal(t, "https://updating.eks.amazonaws.com", byName["updating"].Server)
+}
+
+func TestEKSProvider_Scope(t *testing.T) {
+	provider := NewEKSProvider(&fakeEKSClient{}, "eu-west-1", map[string]string{"env": "dev", "argocd": "true"}, "")
+	assert.Equal(t, "region=eu-west-1,tag:argocd=true,tag:env=dev", provider.Scope())
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -63,6 +64,18 @@ func Unmarshal(data []byte, obj interface{}) error {
 	return unmarshalObject(data, obj)
 }
 
+// UnmarshalStrict behaves like Unmarshal, but returns an error if the data contains fields
+// which are not known to the provided type and would therefore be silently dropped.
+func UnmarshalStrict(data []byte, obj interface{}) error {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(obj)
+}
+
 // UnmarshalRemoteFile retrieves JSON or YAML through a GET request.
 // The caller is responsible for checking error return values.
 func UnmarshalRemoteFile(url string, obj interface{}) error {
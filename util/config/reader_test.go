@@ -66,6 +66,18 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestUnmarshalStrict(t *testing.T) {
+	var testStruct struct {
+		Field1 string `json:"field1"`
+	}
+	err := UnmarshalStrict([]byte("field1: foo"), &testStruct)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", testStruct.Field1)
+
+	err = UnmarshalStrict([]byte("field1: foo\nfield2: bar"), &testStruct)
+	assert.ErrorContains(t, err, `unknown field "field2"`)
+}
+
 func TestUnmarshalRemoteFile(t *testing.T) {
 	const (
 		field1 = "Hello, world!"
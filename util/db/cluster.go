@@ -344,6 +344,15 @@ func clusterToSecret(c *appv1.Cluster, secret *apiv1.Secret) error {
 	if c.Project != "" {
 		data["project"] = []byte(c.Project)
 	}
+	if c.RepoServer != "" {
+		data["repoServer"] = []byte(c.RepoServer)
+	}
+	if c.ResourceInclusions != "" {
+		data["resourceInclusions"] = []byte(c.ResourceInclusions)
+	}
+	if c.ResourceExclusions != "" {
+		data["resourceExclusions"] = []byte(c.ResourceExclusions)
+	}
 	secret.Data = data
 
 	secret.Labels = c.Labels
@@ -418,6 +427,9 @@ func secretToCluster(s *apiv1.Secret) (*appv1.Cluster, error) {
 		RefreshRequestedAt: refreshRequestedAt,
 		Shard:              shard,
 		Project:            string(s.Data["project"]),
+		RepoServer:         string(s.Data["repoServer"]),
+		ResourceInclusions: string(s.Data["resourceInclusions"]),
+		ResourceExclusions: string(s.Data["resourceExclusions"]),
 		Labels:             labels,
 		Annotations:        annotations,
 	}
@@ -58,13 +58,14 @@ func Test_secretToCluster(t *testing.T) {
 
 func TestClusterToSecret(t *testing.T) {
 	cluster := &appv1.Cluster{
-		Server:      "server",
-		Labels:      map[string]string{"test": "label"},
-		Annotations: map[string]string{"test": "annotation"},
-		Name:        "test",
-		Config:      v1alpha1.ClusterConfig{},
-		Project:     "project",
-		Namespaces:  []string{"default"},
+		Server:             "server",
+		Labels:             map[string]string{"test": "label"},
+		Annotations:        map[string]string{"test": "annotation"},
+		Name:               "test",
+		Config:             v1alpha1.ClusterConfig{},
+		Project:            "project",
+		Namespaces:         []string{"default"},
+		ResourceExclusions: "- apiGroups: [metrics.k8s.io]",
 	}
 	s := &v1.Secret{}
 	err := clusterToSecret(cluster, s)
@@ -74,6 +75,8 @@ func TestClusterToSecret(t *testing.T) {
 	assert.Equal(t, []byte(cluster.Name), s.Data["name"])
 	assert.Equal(t, []byte(cluster.Project), s.Data["project"])
 	assert.Equal(t, []byte("default"), s.Data["namespaces"])
+	assert.Equal(t, []byte(cluster.ResourceExclusions), s.Data["resourceExclusions"])
+	assert.NotContains(t, s.Data, "resourceInclusions")
 	assert.Equal(t, cluster.Annotations, s.Annotations)
 	assert.Equal(t, cluster.Labels, s.Labels)
 }
@@ -83,6 +83,12 @@ type ArgoDB interface {
 	AddGPGPublicKey(ctx context.Context, keyData string) (map[string]*appv1.GnuPGPublicKey, []string, error)
 	// DeleteGPGPublicKey removes a GPG public key from the configuration
 	DeleteGPGPublicKey(ctx context.Context, keyID string) error
+
+	// GetRenderedManifests returns the manifests rendered outside of Argo CD which were submitted for a revision of an
+	// application, or nil if none were submitted
+	GetRenderedManifests(ctx context.Context, app *appv1.Application, revision string) (*RenderedManifests, error)
+	// SetRenderedManifests stores the manifests rendered outside of Argo CD for an application
+	SetRenderedManifests(ctx context.Context, app *appv1.Application, manifests *RenderedManifests) error
 }
 
 type db struct {
@@ -633,6 +633,40 @@ func TestListHelmRepositories(t *testing.T) {
 	assert.Equal(t, "test-key", repo.TLSClientCertKey)
 }
 
+func TestListHelmRepositories_UnnamedSecret(t *testing.T) {
+	clientset := getClientset(nil, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "private-charts",
+			Namespace: testNamespace,
+			Labels:    map[string]string{common.LabelKeySecretType: common.LabelValueSecretTypeRepository},
+		},
+		Data: map[string][]byte{
+			"type":     []byte("helm"),
+			"url":      []byte("https://charts.example.com"),
+			"username": []byte("test-username"),
+			"password": []byte("test-password"),
+		},
+	}, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "git-repo",
+			Namespace: testNamespace,
+			Labels:    map[string]string{common.LabelKeySecretType: common.LabelValueSecretTypeRepository},
+		},
+		Data: map[string][]byte{
+			"url": []byte("https://github.com/argoproj/argocd-example-apps"),
+		},
+	})
+	db := NewDB(testNamespace, settings.NewSettingsManager(context.Background(), clientset, testNamespace), clientset)
+
+	repos, err := db.ListHelmRepositories(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, repos, 1)
+	assert.Equal(t, "https://charts.example.com", repos[0].Repo)
+	assert.Equal(t, "", repos[0].Name)
+	assert.Equal(t, "test-username", repos[0].Username)
+	assert.Equal(t, "test-password", repos[0].Password)
+}
+
 func TestHelmRepositorySecretsTrim(t *testing.T) {
 	config := map[string]string{
 		"repositories": `
@@ -62,8 +62,9 @@ func (db *db) ListHelmRepositories(ctx context.Context) ([]*v1alpha1.Repository,
 	if err != nil {
 		return nil, err
 	}
+	// repositories without a name are included as well, since chart dependencies are matched by URL, too
 	result = append(result, v1alpha1.Repositories(repos).Filter(func(r *v1alpha1.Repository) bool {
-		return r.Type == "helm" && r.Name != ""
+		return r.Type == "helm"
 	})...)
 	return result, nil
 }
@@ -311,6 +311,29 @@ func (_m *ArgoDB) GetProjectRepositories(ctx context.Context, project string) ([
 	return r0, r1
 }
 
+// GetRenderedManifests provides a mock function with given fields: ctx, app, revision
+func (_m *ArgoDB) GetRenderedManifests(ctx context.Context, app *v1alpha1.Application, revision string) (*db.RenderedManifests, error) {
+	ret := _m.Called(ctx, app, revision)
+
+	var r0 *db.RenderedManifests
+	if rf, ok := ret.Get(0).(func(context.Context, *v1alpha1.Application, string) *db.RenderedManifests); ok {
+		r0 = rf(ctx, app, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.RenderedManifests)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *v1alpha1.Application, string) error); ok {
+		r1 = rf(ctx, app, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRepository provides a mock function with given fields: ctx, url
 func (_m *ArgoDB) GetRepository(ctx context.Context, url string) (*v1alpha1.Repository, error) {
 	ret := _m.Called(ctx, url)
@@ -539,6 +562,20 @@ func (_m *ArgoDB) RepositoryExists(ctx context.Context, repoURL string) (bool, e
 	return r0, r1
 }
 
+// SetRenderedManifests provides a mock function with given fields: ctx, app, manifests
+func (_m *ArgoDB) SetRenderedManifests(ctx context.Context, app *v1alpha1.Application, manifests *db.RenderedManifests) error {
+	ret := _m.Called(ctx, app, manifests)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *v1alpha1.Application, *db.RenderedManifests) error); ok {
+		r0 = rf(ctx, app, manifests)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateCluster provides a mock function with given fields: ctx, c
 func (_m *ArgoDB) UpdateCluster(ctx context.Context, c *v1alpha1.Cluster) (*v1alpha1.Cluster, error) {
 	ret := _m.Called(ctx, c)
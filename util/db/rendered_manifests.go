@@ -0,0 +1,170 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application"
+	appsv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const (
+	renderedManifestsApplicationKey = "application"
+	renderedManifestsRevisionKey    = "revision"
+	renderedManifestsManifestsKey   = "manifests"
+	renderedManifestsSignatureKey   = "signature"
+
+	// maxRenderedManifestsSize is the maximum size of the compressed manifests and of their signature, which leaves room
+	// for the metadata of the secret within the 1MiB limit of Kubernetes
+	maxRenderedManifestsSize = 1000 * 1024
+)
+
+// ErrRenderedManifestsTooLarge is returned if the rendered manifests don't fit into a secret, even when compressed
+var ErrRenderedManifestsTooLarge = errors.New("rendered manifests are too large")
+
+// RenderedManifests are the manifests of an application at a revision which were rendered outside of Argo CD, together
+// with the signature proving their provenance
+type RenderedManifests struct {
+	Revision  string
+	Manifests []string
+	Signature string
+}
+
+// renderedManifestsSecretName returns the name of the secret holding the rendered manifests of the application at the
+// given revision
+func renderedManifestsSecretName(app *appsv1.Application, revision string) string {
+	return RepoURLToSecretName("rendered-manifests", app.QualifiedName()+"@"+revision)
+}
+
+// GetRenderedManifests returns the manifests submitted for the given revision of the application, or nil if none were
+// submitted. The secrets are read from the informer cache, and only looked up using the API if they are missing from it.
+func (db *db) GetRenderedManifests(ctx context.Context, app *appsv1.Application, revision string) (*RenderedManifests, error) {
+	name := renderedManifestsSecretName(app, revision)
+	secretsLister, err := db.settingsMgr.GetSecretsLister()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := secretsLister.Secrets(db.ns).Get(name)
+	if apierr.IsNotFound(err) {
+		// the secret might have been submitted too recently to be in the cache
+		secret, err = db.kubeclientset.CoreV1().Secrets(db.ns).Get(ctx, name, metav1.GetOptions{})
+	}
+	if apierr.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if string(secret.Data[renderedManifestsApplicationKey]) != app.QualifiedName() || string(secret.Data[renderedManifestsRevisionKey]) != revision {
+		return nil, nil
+	}
+	manifests := &RenderedManifests{
+		Revision:  revision,
+		Signature: string(secret.Data[renderedManifestsSignatureKey]),
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(secret.Data[renderedManifestsManifestsKey]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress rendered manifests of application %s: %w", app.QualifiedName(), err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress rendered manifests of application %s: %w", app.QualifiedName(), err)
+	}
+	if err := json.Unmarshal(data, &manifests.Manifests); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rendered manifests of application %s: %w", app.QualifiedName(), err)
+	}
+	return manifests, nil
+}
+
+// SetRenderedManifests stores the manifests submitted for a revision of the given application. The manifests of the
+// revisions which the application neither is synced to nor has in its history are deleted, so that the manifests remain
+// available for rollbacks as long as the history does. If the application is in the namespace of Argo CD, the secrets
+// are garbage collected together with the application.
+func (db *db) SetRenderedManifests(ctx context.Context, app *appsv1.Application, manifests *RenderedManifests) error {
+	data, err := json.Marshal(manifests.Manifests)
+	if err != nil {
+		return err
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if size := compressed.Len() + len(manifests.Signature); size > maxRenderedManifestsSize {
+		return fmt.Errorf("%w: %d bytes compressed, at most %d bytes are supported", ErrRenderedManifestsTooLarge, size, maxRenderedManifestsSize)
+	}
+
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: renderedManifestsSecretName(app, manifests.Revision),
+		},
+		Data: map[string][]byte{
+			renderedManifestsApplicationKey: []byte(app.QualifiedName()),
+			renderedManifestsRevisionKey:    []byte(manifests.Revision),
+			renderedManifestsManifestsKey:   compressed.Bytes(),
+			renderedManifestsSignatureKey:   []byte(manifests.Signature),
+		},
+	}
+	addSecretMetadata(secret, common.LabelValueSecretTypeRenderedManifests)
+	if app.Namespace == db.ns && app.UID != "" {
+		secret.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       application.ApplicationKind,
+			Name:       app.Name,
+			UID:        app.UID,
+		}}
+	}
+
+	existing, err := db.kubeclientset.CoreV1().Secrets(db.ns).Get(ctx, secret.Name, metav1.GetOptions{})
+	if apierr.IsNotFound(err) {
+		_, err = db.createSecret(ctx, secret)
+	} else if err == nil {
+		existing.Labels = secret.Labels
+		existing.Annotations = secret.Annotations
+		existing.OwnerReferences = secret.OwnerReferences
+		existing.Data = secret.Data
+		_, err = db.kubeclientset.CoreV1().Secrets(db.ns).Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return db.pruneRenderedManifests(ctx, app, manifests.Revision)
+}
+
+// pruneRenderedManifests deletes the manifests submitted for the revisions of the application which are neither the
+// given one, nor the one it is synced or being synced to, nor one of its history
+func (db *db) pruneRenderedManifests(ctx context.Context, app *appsv1.Application, revision string) error {
+	keep := map[string]bool{revision: true, app.Status.Sync.Revision: true}
+	for _, item := range app.Status.History {
+		keep[item.Revision] = true
+	}
+	if app.Status.OperationState != nil && app.Status.OperationState.SyncResult != nil {
+		keep[app.Status.OperationState.SyncResult.Revision] = true
+	}
+	secrets, err := db.listSecretsByType(common.LabelValueSecretTypeRenderedManifests)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if string(secret.Data[renderedManifestsApplicationKey]) != app.QualifiedName() || keep[string(secret.Data[renderedManifestsRevisionKey])] {
+			continue
+		}
+		err := db.kubeclientset.CoreV1().Secrets(db.ns).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !apierr.IsNotFound(err) {
+			return fmt.Errorf("failed to delete rendered manifests of revision %s: %w", secret.Data[renderedManifestsRevisionKey], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	appsv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v2/util/settings"
+)
+
+func TestRenderedManifests(t *testing.T) {
+	clientset := getClientset(nil)
+	settingsMgr := settings.NewSettingsManager(context.Background(), clientset, testNamespace)
+	db := NewDB(testNamespace, settingsMgr, clientset)
+	app := &appsv1.Application{ObjectMeta: metav1.ObjectMeta{Name: "guestbook", Namespace: testNamespace, UID: "1234"}}
+
+	manifests, err := db.GetRenderedManifests(context.Background(), app, "abc")
+	require.NoError(t, err)
+	assert.Nil(t, manifests)
+
+	err = db.SetRenderedManifests(context.Background(), app, &RenderedManifests{Revision: "abc", Manifests: []string{`{"kind":"Service"}`}, Signature: "c2ln"})
+	require.NoError(t, err)
+
+	t.Run("PerRevision", func(t *testing.T) {
+		app := app.DeepCopy()
+		app.Status.History = appsv1.RevisionHistories{{Revision: "abc"}}
+		err := db.SetRenderedManifests(context.Background(), app, &RenderedManifests{Revision: "def", Manifests: []string{`{"kind":"Deployment"}`}, Signature: "c2ln"})
+		require.NoError(t, err)
+
+		manifests, err := db.GetRenderedManifests(context.Background(), app, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, &RenderedManifests{Revision: "abc", Manifests: []string{`{"kind":"Service"}`}, Signature: "c2ln"}, manifests)
+		manifests, err = db.GetRenderedManifests(context.Background(), app, "def")
+		require.NoError(t, err)
+		assert.Equal(t, &RenderedManifests{Revision: "def", Manifests: []string{`{"kind":"Deployment"}`}, Signature: "c2ln"}, manifests)
+
+		secret, err := clientset.CoreV1().Secrets(testNamespace).Get(context.Background(), renderedManifestsSecretName(app, "def"), metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, common.LabelValueSecretTypeRenderedManifests, secret.Labels[common.LabelKeySecretType])
+		require.Len(t, secret.OwnerReferences, 1)
+		assert.Equal(t, "guestbook", secret.OwnerReferences[0].Name)
+	})
+
+	t.Run("PruneRevisionsNotInHistory", func(t *testing.T) {
+		app := app.DeepCopy()
+		app.Status.History = appsv1.RevisionHistories{{Revision: "def"}}
+		// the secrets to prune are listed from the informer cache, which has to catch up with the previous revisions
+		require.Eventually(t, func() bool {
+			secretsLister, err := settingsMgr.GetSecretsLister()
+			require.NoError(t, err)
+			secrets, err := secretsLister.Secrets(testNamespace).List(labels.SelectorFromSet(labels.Set{common.LabelKeySecretType: common.LabelValueSecretTypeRenderedManifests}))
+			require.NoError(t, err)
+			return len(secrets) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		err := db.SetRenderedManifests(context.Background(), app, &RenderedManifests{Revision: "ghi", Manifests: []string{`{"kind":"Deployment"}`}, Signature: "c2ln"})
+		require.NoError(t, err)
+
+		_, err = clientset.CoreV1().Secrets(testNamespace).Get(context.Background(), renderedManifestsSecretName(app, "abc"), metav1.GetOptions{})
+		assert.Error(t, err)
+		_, err = clientset.CoreV1().Secrets(testNamespace).Get(context.Background(), renderedManifestsSecretName(app, "def"), metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		err := db.SetRenderedManifests(context.Background(), app, &RenderedManifests{Revision: "jkl", Manifests: []string{`{"kind":"Service"}`}, Signature: strings.Repeat("a", maxRenderedManifestsSize)})
+		assert.ErrorIs(t, err, ErrRenderedManifestsTooLarge)
+	})
+
+	t.Run("OtherApplication", func(t *testing.T) {
+		otherApp := &appsv1.Application{ObjectMeta: metav1.ObjectMeta{Name: "guestbook", Namespace: "other"}}
+		manifests, err := db.GetRenderedManifests(context.Background(), otherApp, "abc")
+		require.NoError(t, err)
+		assert.Nil(t, manifests)
+	})
+}
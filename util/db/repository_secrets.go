@@ -314,6 +314,7 @@ func secretToRepository(secret *corev1.Secret) (*appsv1.Repository, error) {
 		Proxy:                      string(secret.Data["proxy"]),
 		Project:                    string(secret.Data["project"]),
 		GCPServiceAccountKey:       string(secret.Data["gcpServiceAccountKey"]),
+		CloneFilter:                string(secret.Data["cloneFilter"]),
 	}
 
 	insecureIgnoreHostKey, err := boolOrFalse(secret, "insecureIgnoreHostKey")
@@ -358,6 +359,24 @@ func secretToRepository(secret *corev1.Secret) (*appsv1.Repository, error) {
 	}
 	repository.ForceHttpBasicAuth = forceBasicAuth
 
+	cloneDepth, err := intOrZero(secret, "cloneDepth")
+	if err != nil {
+		return repository, err
+	}
+	repository.CloneDepth = cloneDepth
+
+	enableSubmodules, err := boolOrNil(secret, "enableSubmodules")
+	if err != nil {
+		return repository, err
+	}
+	repository.EnableSubmodules = enableSubmodules
+
+	skipSubmoduleCreds, err := boolOrFalse(secret, "skipSubmoduleCreds")
+	if err != nil {
+		return repository, err
+	}
+	repository.SkipSubmoduleCreds = skipSubmoduleCreds
+
 	return repository, nil
 }
 
@@ -386,6 +405,10 @@ func repositoryToSecret(repository *appsv1.Repository, secret *corev1.Secret) {
 	updateSecretString(secret, "proxy", repository.Proxy)
 	updateSecretString(secret, "gcpServiceAccountKey", repository.GCPServiceAccountKey)
 	updateSecretBool(secret, "forceHttpBasicAuth", repository.ForceHttpBasicAuth)
+	updateSecretInt(secret, "cloneDepth", repository.CloneDepth)
+	updateSecretString(secret, "cloneFilter", repository.CloneFilter)
+	updateSecretBoolPtr(secret, "enableSubmodules", repository.EnableSubmodules)
+	updateSecretBool(secret, "skipSubmoduleCreds", repository.SkipSubmoduleCreds)
 	addSecretMetadata(secret, common.LabelValueSecretTypeRepository)
 }
 
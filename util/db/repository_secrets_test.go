@@ -793,3 +793,26 @@ func TestRepoCredsToSecret(t *testing.T) {
 	assert.Equal(t, map[string]string{common.AnnotationKeyManagedBy: common.AnnotationValueManagedByArgoCD}, s.Annotations)
 	assert.Equal(t, map[string]string{common.LabelKeySecretType: common.LabelValueSecretTypeRepoCreds}, s.Labels)
 }
+
+func TestRepositoryToSecret_Submodules(t *testing.T) {
+	enabled := false
+	repo := &appsv1.Repository{Repo: "URL", EnableSubmodules: &enabled, SkipSubmoduleCreds: true}
+	s := &corev1.Secret{}
+	repositoryToSecret(repo, s)
+	assert.Equal(t, []byte("false"), s.Data["enableSubmodules"])
+	assert.Equal(t, []byte("true"), s.Data["skipSubmoduleCreds"])
+
+	converted, err := secretToRepository(s)
+	assert.NoError(t, err)
+	if assert.NotNil(t, converted.EnableSubmodules) {
+		assert.False(t, *converted.EnableSubmodules)
+	}
+	assert.True(t, converted.SkipSubmoduleCreds)
+
+	repo.EnableSubmodules = nil
+	repositoryToSecret(repo, s)
+	assert.NotContains(t, s.Data, "enableSubmodules")
+	converted, err = secretToRepository(s)
+	assert.NoError(t, err)
+	assert.Nil(t, converted.EnableSubmodules)
+}
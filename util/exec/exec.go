@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -19,6 +20,8 @@ type ExecRunOpts struct {
 	Redactor func(text string) string
 	// TimeoutBehavior configures what to do in case of timeout
 	TimeoutBehavior argoexec.TimeoutBehavior
+	// Timeout overrides the default timeout, which is configured by the ARGOCD_EXEC_TIMEOUT env variable, if set
+	Timeout time.Duration
 }
 
 func init() {
@@ -33,6 +36,31 @@ func initTimeout() {
 	}
 }
 
+// TimeoutFromContext returns the time remaining until the deadline of the given context, or the default timeout if the
+// context has no deadline. An earlier deadline of the caller takes precedence so that commands are not left running
+// after the caller gave up waiting for their result, but a later one never extends the default timeout, which is the
+// maximum configured for the instance.
+func TimeoutFromContext(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	return TimeoutUntil(deadline)
+}
+
+// TimeoutUntil returns the time remaining until the given deadline, bounded by the default timeout
+func TimeoutUntil(deadline time.Time) time.Duration {
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		// a zero timeout disables the timeout altogether
+		return time.Nanosecond
+	}
+	if timeout > 0 && remaining > timeout {
+		return timeout
+	}
+	return remaining
+}
+
 func Run(cmd *exec.Cmd) (string, error) {
 	return RunWithRedactor(cmd, nil)
 }
@@ -44,6 +72,9 @@ func RunWithRedactor(cmd *exec.Cmd, redactor func(text string) string) (string,
 
 func RunWithExecRunOpts(cmd *exec.Cmd, opts ExecRunOpts) (string, error) {
 	cmdOpts := argoexec.CmdOpts{Timeout: timeout, Redactor: opts.Redactor, TimeoutBehavior: opts.TimeoutBehavior}
+	if opts.Timeout > 0 {
+		cmdOpts.Timeout = opts.Timeout
+	}
 	span := tracing.NewLoggingTracer(log.NewLogrusLogger(log.NewWithCurrentConfig())).StartSpan(fmt.Sprintf("exec %v", cmd.Args[0]))
 	span.SetBaggageItem("dir", fmt.Sprintf("%v", cmd.Dir))
 	if cmdOpts.Redactor != nil {
@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"regexp"
@@ -55,3 +56,37 @@ func TestRunWithExecRunOpts(t *testing.T) {
 	_, err := RunWithExecRunOpts(exec.Command("sh", "-c", "trap 'trap - 15 && echo captured && exit' 15 && sleep 2"), opts)
 	assert.Contains(t, err.Error(), "failed timeout after 200ms")
 }
+
+func TestRunWithExecRunOpts_Timeout(t *testing.T) {
+	t.Setenv("ARGOCD_EXEC_TIMEOUT", "10s")
+	initTimeout()
+
+	_, err := RunWithExecRunOpts(exec.Command("sleep", "2"), ExecRunOpts{Timeout: 100 * time.Millisecond})
+	assert.Contains(t, err.Error(), "failed timeout after 100ms")
+}
+
+func TestTimeoutFromContext(t *testing.T) {
+	t.Setenv("ARGOCD_EXEC_TIMEOUT", "10s")
+	initTimeout()
+
+	t.Run("NoDeadline", func(t *testing.T) {
+		assert.Equal(t, 10*time.Second, TimeoutFromContext(context.Background()))
+	})
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		timeout := TimeoutFromContext(ctx)
+		assert.Greater(t, timeout, 4*time.Second)
+		assert.LessOrEqual(t, timeout, 5*time.Second)
+	})
+	t.Run("DeadlineAfterDefaultTimeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		assert.Equal(t, 10*time.Second, TimeoutFromContext(ctx))
+	})
+	t.Run("DeadlineExceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		assert.Equal(t, time.Nanosecond, TimeoutFromContext(ctx))
+	})
+}
@@ -0,0 +1,64 @@
+package faultinjection
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/argoproj/argo-cd/v2/util/env"
+)
+
+// ErrInjected is returned by operations which failed because of an injected fault
+var ErrInjected = errors.New("injected fault")
+
+// Config configures the faults injected into an operation. Faults are meant to validate HA and retry configurations in
+// test environments and must never be enabled in production.
+type Config struct {
+	// Delay is added to every operation
+	Delay time.Duration
+	// FailureRate is the share of operations, between 0 and 1, which fail with ErrInjected
+	FailureRate float64
+}
+
+// ConfigFromEnv reads the delay and the failure rate from the given environment variables. Fault injection is disabled
+// if neither is set.
+func ConfigFromEnv(delayEnv, failureRateEnv string) Config {
+	return Config{
+		Delay:       env.ParseDurationFromEnv(delayEnv, 0, 0, math.MaxInt64),
+		FailureRate: float64(env.ParseFloatFromEnv(failureRateEnv, 0, 0, 1)),
+	}
+}
+
+// Enabled returns true if any fault is injected
+func (c Config) Enabled() bool {
+	return c.Delay > 0 || c.FailureRate > 0
+}
+
+// WarnIfEnabled logs a warning if faults are injected into the given target
+func (c Config) WarnIfEnabled(target string) {
+	if c.Enabled() {
+		log.Warnf("Fault injection into %s is enabled (delay: %s, failure rate: %.2f). This must never be used in production.", target, c.Delay, c.FailureRate)
+	}
+}
+
+// Inject waits for the configured delay, or until the context is done, and then randomly fails with the configured
+// failure rate
+func (c Config) Inject(ctx context.Context) error {
+	if c.Delay > 0 {
+		timer := time.NewTimer(c.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if c.FailureRate > 0 && rand.Float64() < c.FailureRate {
+		return ErrInjected
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package faultinjection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	assert.False(t, ConfigFromEnv("TEST_FAULT_DELAY", "TEST_FAULT_RATE").Enabled())
+
+	t.Setenv("TEST_FAULT_DELAY", "2s")
+	t.Setenv("TEST_FAULT_RATE", "0.5")
+	config := ConfigFromEnv("TEST_FAULT_DELAY", "TEST_FAULT_RATE")
+	assert.True(t, config.Enabled())
+	assert.Equal(t, 2*time.Second, config.Delay)
+	assert.Equal(t, 0.5, config.FailureRate)
+
+	// invalid rates are ignored
+	t.Setenv("TEST_FAULT_RATE", "2")
+	assert.Equal(t, float64(0), ConfigFromEnv("TEST_FAULT_DELAY", "TEST_FAULT_RATE").FailureRate)
+}
+
+func TestConfig_Inject(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		assert.NoError(t, Config{}.Inject(context.Background()))
+	})
+	t.Run("Failure", func(t *testing.T) {
+		assert.ErrorIs(t, Config{FailureRate: 1}.Inject(context.Background()), ErrInjected)
+	})
+	t.Run("Delay", func(t *testing.T) {
+		start := time.Now()
+		assert.NoError(t, Config{Delay: 50 * time.Millisecond}.Inject(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, Config{Delay: time.Hour}.Inject(ctx), context.Canceled)
+	})
+}
@@ -13,10 +13,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	argoexec "github.com/argoproj/pkg/exec"
+	argosync "github.com/argoproj/pkg/sync"
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
@@ -34,6 +37,7 @@ import (
 	certutil "github.com/argoproj/argo-cd/v2/util/cert"
 	"github.com/argoproj/argo-cd/v2/util/env"
 	executil "github.com/argoproj/argo-cd/v2/util/exec"
+	"github.com/argoproj/argo-cd/v2/util/glob"
 	"github.com/argoproj/argo-cd/v2/util/proxy"
 )
 
@@ -77,6 +81,7 @@ type Client interface {
 type EventHandlers struct {
 	OnLsRemote func(repo string) func()
 	OnFetch    func(repo string) func()
+	OnCheckout func(repo string) func()
 }
 
 // nativeGitClient implements Client interface using git CLI
@@ -99,9 +104,30 @@ type nativeGitClient struct {
 	loadRefFromCache bool
 	// HTTP/HTTPS proxy used to access repository
 	proxy string
+	// number of commits to fetch, 0 fetches the full history
+	depth int64
+	// partial clone filter used when fetching, e.g. blob:none
+	filter string
+	// paths the working tree is restricted to on checkout, empty checks out the complete tree
+	sparsePaths []string
+	// returns the paths outside the sparse paths which the checked out files depend on
+	sparsePathDependencies SparsePathDependencies
+	// whether submodules are fetched without the credentials of the repository
+	skipSubmoduleCreds bool
+	// path of a bare mirror of the repository, whose objects are shared with the working copy using git alternates
+	referenceRepo string
+	// deadline of the git commands run by the client, zero applies the default exec timeout to each command
+	deadline time.Time
 }
 
+// maxSparseCheckoutDependencyLevels is the maximum number of times the dependencies of the files of a sparse checkout
+// are added to it, before the complete working tree is checked out instead
+const maxSparseCheckoutDependencyLevels = 10
+
 var (
+	// referenceRepoLocks serializes the fetches of each reference repository, which may be shared by several clients
+	referenceRepoLocks sync.Map
+
 	maxAttemptsCount = 1
 	maxRetryDuration time.Duration
 	retryDuration    time.Duration
@@ -133,6 +159,68 @@ func WithCache(cache gitRefCache, loadRefFromCache bool) ClientOpts {
 	}
 }
 
+// WithDepth limits the fetched history to the given number of commits
+func WithDepth(depth int64) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.depth = depth
+	}
+}
+
+// WithFilter sets the partial clone filter used when fetching, e.g. blob:none
+func WithFilter(filter string) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.filter = filter
+	}
+}
+
+// WithSparsePaths restricts the working tree to the given paths on checkout using sparse checkout
+func WithSparsePaths(paths []string) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.sparsePaths = paths
+	}
+}
+
+// SparsePathDependencies returns the paths of the working tree at root which the files within the given paths depend
+// on, e.g. the bases of kustomizations, or complete set to true if the files depend on the complete working tree
+type SparsePathDependencies func(root string, paths []string) (dependencies []string, complete bool, err error)
+
+// WithSparsePathDependencies adds the paths which the checked out files depend on to the sparse paths on checkout,
+// until all dependencies are checked out
+func WithSparsePathDependencies(dependencies SparsePathDependencies) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.sparsePathDependencies = dependencies
+	}
+}
+
+// WithSkipSubmoduleCreds specifies whether submodules are fetched without the credentials of the repository
+func WithSkipSubmoduleCreds(skip bool) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.skipSubmoduleCreds = skip
+	}
+}
+
+// WithReferenceRepo shares the objects of the bare mirror at the given path with the working copy using git alternates.
+// The mirror is fetched before the working copy, which then only fetches the objects missing in the mirror. It is
+// ignored for shallow and partial clones.
+func WithReferenceRepo(path string) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.referenceRepo = path
+	}
+}
+
+// ReferenceRepoPath returns the path of the reference repository of the given repository URL in the given directory
+func ReferenceRepoPath(dir string, rawRepoURL string) string {
+	r := regexp.MustCompile("(/|:)")
+	return filepath.Join(dir, r.ReplaceAllString(NormalizeGitURL(rawRepoURL), "_"))
+}
+
+// WithDeadline bounds the git commands run by the client, e.g. fetch and checkout, by the given deadline
+func WithDeadline(deadline time.Time) ClientOpts {
+	return func(c *nativeGitClient) {
+		c.deadline = deadline
+	}
+}
+
 // WithEventHandlers sets the git client event handlers
 func WithEventHandlers(handlers EventHandlers) ClientOpts {
 	return func(c *nativeGitClient) {
@@ -302,7 +390,7 @@ func (m *nativeGitClient) Root() string {
 func (m *nativeGitClient) Init() error {
 	_, err := git.PlainOpen(m.root)
 	if err == nil {
-		return nil
+		return m.initReferenceRepo()
 	}
 	if err != git.ErrRepositoryNotExists {
 		return err
@@ -324,6 +412,91 @@ func (m *nativeGitClient) Init() error {
 		Name: git.DefaultRemoteName,
 		URLs: []string{m.repoURL},
 	})
+	if err != nil {
+		return err
+	}
+	return m.initReferenceRepo()
+}
+
+func (m *nativeGitClient) isReferenceRepoEnabled() bool {
+	return m.referenceRepo != "" && m.depth == 0 && m.filter == ""
+}
+
+// initReferenceRepo creates the bare mirror of the repository if it doesn't exist yet, and adds its objects to the
+// alternates of the working copy
+func (m *nativeGitClient) initReferenceRepo() error {
+	if !m.isReferenceRepoEnabled() {
+		return nil
+	}
+	_, err := git.PlainOpen(m.referenceRepo)
+	if err == git.ErrRepositoryNotExists {
+		log.Infof("Initializing reference repository of %s at %s", m.repoURL, m.referenceRepo)
+		if err = os.MkdirAll(m.referenceRepo, 0755); err != nil {
+			return err
+		}
+		repo, err := git.PlainInit(m.referenceRepo, true)
+		if err != nil {
+			return fmt.Errorf("unable to initialize reference repository at %s: %w", m.referenceRepo, err)
+		}
+		_, err = repo.CreateRemote(&config.RemoteConfig{
+			Name:  git.DefaultRemoteName,
+			URLs:  []string{m.repoURL},
+			Fetch: []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"},
+		})
+		if err != nil {
+			return err
+		}
+		cfg, err := repo.Config()
+		if err != nil {
+			return err
+		}
+		// objects of the mirror may only be referenced by the working copies, so they must never be pruned
+		cfg.Raw.Section("gc").SetOption("auto", "0")
+		cfg.Raw.Section("gc").SetOption("pruneExpire", "never")
+		if err = repo.SetConfig(cfg); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	objectsDir, err := filepath.Abs(filepath.Join(m.referenceRepo, "objects"))
+	if err != nil {
+		return err
+	}
+	alternates := filepath.Join(m.root, ".git", "objects", "info", "alternates")
+	if err = os.MkdirAll(filepath.Dir(alternates), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(alternates, []byte(objectsDir+"\n"), 0644)
+}
+
+// fetchReferenceRepo fetches all branches and tags of origin into the reference repository
+func (m *nativeGitClient) fetchReferenceRepo() error {
+	lock, _ := referenceRepoLocks.LoadOrStore(m.referenceRepo, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+	// git commands run in the working copy, so relative paths would be resolved against it
+	referenceRepo, err := filepath.Abs(m.referenceRepo)
+	if err != nil {
+		return err
+	}
+	return m.runCredentialedCmd("-C", referenceRepo, "fetch", "origin", "--tags", "--force", "--prune")
+}
+
+// fetchFromReferenceRepo fetches the given revision and all branches and tags from the reference repository, which
+// already contains all objects, into the working copy, so that origin is only fetched once
+func (m *nativeGitClient) fetchFromReferenceRepo(revision string) error {
+	referenceRepo, err := filepath.Abs(m.referenceRepo)
+	if err != nil {
+		return err
+	}
+	args := []string{"fetch", referenceRepo}
+	if revision != "" {
+		args = append(args, revision)
+	}
+	// the branches of the reference repository are the branches of origin
+	args = append(args, "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*", "--force", "--prune")
+	_, err = m.runCmd(args...)
 	return err
 }
 
@@ -333,13 +506,28 @@ func (m *nativeGitClient) IsLFSEnabled() bool {
 }
 
 func (m *nativeGitClient) fetch(revision string) error {
-	var err error
+	args := []string{"fetch", "origin"}
 	if revision != "" {
-		err = m.runCredentialedCmd("fetch", "origin", revision, "--tags", "--force", "--prune")
-	} else {
-		err = m.runCredentialedCmd("fetch", "origin", "--tags", "--force", "--prune")
+		args = append(args, revision)
 	}
-	return err
+	args = append(args, "--tags", "--force", "--prune")
+	args = append(args, m.fetchHistoryArgs()...)
+	return m.runCredentialedCmd(args...)
+}
+
+// fetchHistoryArgs returns the arguments limiting the history and the objects fetched from origin
+func (m *nativeGitClient) fetchHistoryArgs() []string {
+	var args []string
+	if m.depth > 0 {
+		args = append(args, "--depth", strconv.FormatInt(m.depth, 10))
+	} else if _, err := os.Stat(filepath.Join(m.root, ".git", "shallow")); err == nil {
+		// the repository was previously fetched with a limited depth, fetch the remaining history
+		args = append(args, "--unshallow")
+	}
+	if m.filter != "" {
+		args = append(args, "--filter="+m.filter)
+	}
+	return args
 }
 
 // Fetch fetches latest updates from origin
@@ -349,7 +537,19 @@ func (m *nativeGitClient) Fetch(revision string) error {
 		defer done()
 	}
 
-	err := m.fetch(revision)
+	var err error
+	if m.isReferenceRepoEnabled() {
+		// the working copy fetches from origin if the reference repository can't be fetched or doesn't contain the
+		// revision, e.g. a pull request ref, so the reference repository is optional
+		if err = m.fetchReferenceRepo(); err != nil {
+			log.Warnf("Failed to fetch reference repository of %s at %s: %v", m.repoURL, m.referenceRepo, err)
+		} else if err = m.fetchFromReferenceRepo(revision); err != nil {
+			log.Debugf("Failed to fetch revision %s of %s from reference repository at %s: %v", revision, m.repoURL, m.referenceRepo, err)
+		}
+	}
+	if !m.isReferenceRepoEnabled() || err != nil {
+		err = m.fetch(revision)
+	}
 
 	// When we have LFS support enabled, check for large files and fetch them too.
 	if err == nil && m.IsLFSEnabled() {
@@ -418,10 +618,18 @@ func (m *nativeGitClient) LsLargeFiles() ([]string, error) {
 
 // Submodule embed other repositories into this repository
 func (m *nativeGitClient) Submodule() error {
-	if err := m.runCredentialedCmd("submodule", "sync", "--recursive"); err != nil {
+	run := m.runCredentialedCmd
+	if m.skipSubmoduleCreds {
+		// submodules hosted elsewhere must not receive the credentials of this repository
+		run = func(args ...string) error {
+			_, err := m.runCmd(args...)
+			return err
+		}
+	}
+	if err := run("submodule", "sync", "--recursive"); err != nil {
 		return err
 	}
-	if err := m.runCredentialedCmd("submodule", "update", "--init", "--recursive"); err != nil {
+	if err := run("submodule", "update", "--init", "--recursive"); err != nil {
 		return err
 	}
 	return nil
@@ -429,10 +637,25 @@ func (m *nativeGitClient) Submodule() error {
 
 // Checkout checkout specified revision
 func (m *nativeGitClient) Checkout(revision string, submoduleEnabled bool) error {
+	if m.OnCheckout != nil {
+		done := m.OnCheckout(m.repoURL)
+		defer done()
+	}
 	if revision == "" || revision == "HEAD" {
 		revision = "origin/HEAD"
 	}
-	if _, err := m.runCmd("checkout", "--force", revision); err != nil {
+	if err := m.sparseCheckout(); err != nil {
+		return err
+	}
+	if m.isPartialClone() {
+		// objects excluded by the partial clone filter are fetched from origin on checkout, which requires credentials
+		if err := m.runCredentialedCmd("checkout", "--force", revision); err != nil {
+			return err
+		}
+	} else if _, err := m.runCmd("checkout", "--force", revision); err != nil {
+		return err
+	}
+	if err := m.checkoutSparsePathDependencies(); err != nil {
 		return err
 	}
 	// We must populate LFS content by using lfs checkout, if we have at least
@@ -466,12 +689,121 @@ func (m *nativeGitClient) Checkout(revision string, submoduleEnabled bool) error
 	return nil
 }
 
+// sparseCheckout restricts the working tree to the sparse paths, or restores the complete tree if the working
+// tree was restricted by a previous checkout
+func (m *nativeGitClient) sparseCheckout() error {
+	if len(m.sparsePaths) == 0 {
+		if out, err := m.runCmd("config", "--get", "--bool", "core.sparseCheckout"); err != nil || strings.TrimSpace(out) != "true" {
+			return nil
+		}
+		_, err := m.runCmd("sparse-checkout", "disable")
+		return err
+	}
+	// git ignores the worktree config, in which the sparse checkout settings are stored, of repositories without a
+	// format version, which is the case for repositories initialized by go-git
+	if _, err := m.runCmd("config", "--get", "core.repositoryformatversion"); err != nil {
+		if _, err := m.runCmd("config", "core.repositoryformatversion", "0"); err != nil {
+			return err
+		}
+	}
+	return m.setSparsePaths(m.sparsePaths)
+}
+
+// isPartialClone returns whether objects of the repository may be missing locally, since it was fetched with a partial
+// clone filter, either by this client or a previous one using the same root. Missing objects are fetched from origin
+// on demand, e.g. when they are checked out.
+func (m *nativeGitClient) isPartialClone() bool {
+	if m.filter != "" {
+		return true
+	}
+	out, err := m.runCmd("config", "--get", "--bool", "remote.origin.promisor")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+// setSparsePaths restricts the working tree to the given paths
+func (m *nativeGitClient) setSparsePaths(paths []string) error {
+	args := []string{"sparse-checkout", "set", "--no-cone"}
+	for _, p := range paths {
+		args = append(args, "/"+strings.TrimPrefix(filepath.ToSlash(p), "/"))
+	}
+	if m.isPartialClone() {
+		// objects excluded by the partial clone filter are fetched from origin when the working tree is extended
+		return m.runCredentialedCmd(args...)
+	}
+	_, err := m.runCmd(args...)
+	return err
+}
+
+// checkoutSparsePathDependencies adds the paths which the checked out files depend on to the sparse paths, until the
+// working tree contains all dependencies
+func (m *nativeGitClient) checkoutSparsePathDependencies() error {
+	if len(m.sparsePaths) == 0 || m.sparsePathDependencies == nil {
+		return nil
+	}
+	paths := append([]string{}, m.sparsePaths...)
+	for i := 0; ; i++ {
+		dependencies, complete, err := m.sparsePathDependencies(m.root, paths)
+		if err != nil {
+			return fmt.Errorf("failed to determine the dependencies of the sparse checkout paths: %w", err)
+		}
+		missing := missingSparsePaths(paths, dependencies)
+		if len(missing) == 0 && !complete {
+			return nil
+		}
+		if complete || i == maxSparseCheckoutDependencyLevels {
+			// the dependencies cannot be restricted to a set of paths, or are nested too deeply
+			if m.isPartialClone() {
+				return m.runCredentialedCmd("sparse-checkout", "disable")
+			}
+			_, err := m.runCmd("sparse-checkout", "disable")
+			return err
+		}
+		paths = append(paths, missing...)
+		if err := m.setSparsePaths(paths); err != nil {
+			return err
+		}
+	}
+}
+
+// missingSparsePaths returns the given dependencies which are not within one of the given sparse paths
+func missingSparsePaths(paths []string, dependencies []string) []string {
+	var missing []string
+	for _, dependency := range dependencies {
+		if !withinSparsePaths(dependency, paths) && !withinSparsePaths(dependency, missing) {
+			missing = append(missing, dependency)
+		}
+	}
+	return missing
+}
+
+func withinSparsePaths(p string, paths []string) bool {
+	p = strings.Trim(filepath.ToSlash(filepath.Clean(p)), "/")
+	for _, sparsePath := range paths {
+		sparsePath = strings.Trim(filepath.ToSlash(filepath.Clean(sparsePath)), "/")
+		if p == sparsePath || strings.HasPrefix(p, sparsePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// repoRefsLock coalesces the concurrent ls-remote calls to the same repository of the clients which may load the
+// references from the cache
+var repoRefsLock = argosync.NewKeyLock()
+
 func (m *nativeGitClient) getRefs() ([]*plumbing.Reference, error) {
 	if m.gitRefCache != nil && m.loadRefFromCache {
 		var res []*plumbing.Reference
 		if m.gitRefCache.GetGitReferences(m.repoURL, &res) == nil {
 			return res, nil
 		}
+		// When many applications using the same repository are refreshed at once, only the first one lists the
+		// references of the remote, the others wait for it and use the cached result.
+		repoRefsLock.Lock(m.repoURL)
+		defer repoRefsLock.Unlock(m.repoURL)
+		if m.gitRefCache.GetGitReferences(m.repoURL, &res) == nil {
+			return res, nil
+		}
 	}
 
 	if m.OnLsRemote != nil {
@@ -571,6 +903,9 @@ func (m *nativeGitClient) lsRemote(revision string) (string, error) {
 	if revision == "" {
 		revision = "HEAD"
 	}
+	if isTagGlob(revision) {
+		return resolveTagGlob(revision, refs)
+	}
 	// refToHash keeps a maps of remote refs to their hash
 	// (e.g. refs/heads/master -> a67038ae2e9cb9b9b16423702f98b41e36601001)
 	refToHash := make(map[string]string)
@@ -612,6 +947,50 @@ func (m *nativeGitClient) lsRemote(revision string) (string, error) {
 	return "", fmt.Errorf("Unable to resolve '%s' to a commit SHA", revision)
 }
 
+// isTagGlob returns whether the given revision is a glob pattern matching tags, e.g. v1.2.*
+func isTagGlob(revision string) bool {
+	return strings.ContainsAny(revision, "*?[")
+}
+
+// resolveTagGlob resolves the given glob pattern to the commit SHA of the highest matching tag. Tags which are semantic
+// versions are compared by their precedence and rank above all other tags, which are compared lexically.
+func resolveTagGlob(pattern string, refs []*plumbing.Reference) (string, error) {
+	var bestTag, bestHash string
+	var bestVersion *semver.Version
+	for _, ref := range refs {
+		if !ref.Name().IsTag() || ref.Type() != plumbing.HashReference || strings.HasSuffix(ref.Name().String(), "^{}") {
+			continue
+		}
+		tag := ref.Name().Short()
+		if !glob.Match(pattern, tag) {
+			continue
+		}
+		version, err := semver.NewVersion(tag)
+		if err != nil {
+			version = nil
+		}
+		var higher bool
+		switch {
+		case bestTag == "":
+			higher = true
+		case version != nil && bestVersion != nil:
+			higher = version.GreaterThan(bestVersion)
+		case version != nil || bestVersion != nil:
+			higher = version != nil
+		default:
+			higher = tag > bestTag
+		}
+		if higher {
+			bestTag, bestHash, bestVersion = tag, ref.Hash().String(), version
+		}
+	}
+	if bestTag == "" {
+		return "", fmt.Errorf("Unable to resolve '%s' to a commit SHA: no tag matches the pattern", pattern)
+	}
+	log.Debugf("revision '%s' resolved to tag '%s' (%s)", pattern, bestTag, bestHash)
+	return bestHash, nil
+}
+
 // CommitSHA returns current commit sha from `git rev-parse HEAD`
 func (m *nativeGitClient) CommitSHA() (string, error) {
 	out, err := m.runCmd("rev-parse", "HEAD")
@@ -728,5 +1107,8 @@ func (m *nativeGitClient) runCmdOutput(cmd *exec.Cmd) (string, error) {
 			ShouldWait: true,
 		},
 	}
+	if !m.deadline.IsZero() {
+		opts.Timeout = executil.TimeoutUntil(m.deadline)
+	}
 	return executil.RunWithExecRunOpts(cmd, opts)
 }
@@ -1,12 +1,19 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +46,26 @@ func Test_nativeGitClient_Fetch(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_nativeGitClient_Fetch_DeadlineExceeded(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "commit", "-m", "Initial commit", "--allow-empty")
+	require.NoError(t, err)
+
+	client, err := NewClient(fmt.Sprintf("file://%s", tempDir), NopCreds{}, true, false, "", WithDeadline(time.Now().Add(-time.Second)))
+	require.NoError(t, err)
+
+	err = client.Init()
+	require.NoError(t, err)
+
+	err = client.Fetch("")
+	assert.ErrorContains(t, err, "timeout")
+}
+
 func Test_nativeGitClient_Fetch_Prune(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "")
 	require.NoError(t, err)
@@ -70,6 +97,389 @@ func Test_nativeGitClient_Fetch_Prune(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_nativeGitClient_Fetch_Depth(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "config", "uploadpack.allowFilter", "true")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		err = runCmd(tempDir, "git", "commit", "-m", fmt.Sprintf("Commit %d", i), "--allow-empty")
+		require.NoError(t, err)
+	}
+
+	root, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	isShallow := func() string {
+		cmd := exec.Command("git", "rev-parse", "--is-shallow-repository")
+		cmd.Dir = root
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	client, err := NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "", WithDepth(1), WithFilter("blob:none"))
+	require.NoError(t, err)
+
+	err = client.Init()
+	require.NoError(t, err)
+
+	err = client.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, "true\n", isShallow())
+
+	// Fetching without a depth converts the shallow repository into a complete one
+	client, err = NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "")
+	require.NoError(t, err)
+
+	err = client.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, "false\n", isShallow())
+}
+
+func Test_nativeGitClient_Checkout_PartialClone(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+	err = runCmd(tempDir, "git", "config", "uploadpack.allowFilter", "true")
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "deployment.yaml"), []byte("kind: Deployment"), 0644)
+	require.NoError(t, err)
+	err = runCmd(tempDir, "git", "add", ".")
+	require.NoError(t, err)
+	err = runCmd(tempDir, "git", "commit", "-m", "Initial commit")
+	require.NoError(t, err)
+
+	root, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	client, err := NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "", WithFilter("blob:none"))
+	require.NoError(t, err)
+	err = client.Init()
+	require.NoError(t, err)
+	err = client.Fetch("")
+	require.NoError(t, err)
+
+	// a client without a filter still checks out the partial clone fetched by the previous one from origin
+	client, err = NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "")
+	require.NoError(t, err)
+	assert.True(t, client.(*nativeGitClient).isPartialClone())
+
+	commitSHA, err := client.LsRemote("HEAD")
+	require.NoError(t, err)
+	err = client.Checkout(commitSHA, false)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "deployment.yaml"))
+	assert.NoError(t, err)
+}
+
+func Test_nativeGitClient_Checkout_SparsePaths(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+
+	for _, p := range []string{"apps/foo/deployment.yaml", "apps/bar/deployment.yaml", "values/foo.yaml"} {
+		err = os.MkdirAll(filepath.Join(tempDir, filepath.Dir(p)), 0755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(tempDir, p), []byte(p), 0644)
+		require.NoError(t, err)
+	}
+	err = runCmd(tempDir, "git", "add", ".")
+	require.NoError(t, err)
+	err = runCmd(tempDir, "git", "commit", "-m", "Initial commit")
+	require.NoError(t, err)
+
+	root, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	exists := func(p string) bool {
+		_, err := os.Stat(filepath.Join(root, p))
+		return err == nil
+	}
+
+	client, err := NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "", WithSparsePaths([]string{"apps/foo", "values/foo.yaml"}))
+	require.NoError(t, err)
+
+	err = client.Init()
+	require.NoError(t, err)
+	err = client.Fetch("")
+	require.NoError(t, err)
+	commitSHA, err := client.LsRemote("HEAD")
+	require.NoError(t, err)
+	err = client.Checkout(commitSHA, false)
+	require.NoError(t, err)
+
+	assert.True(t, exists("apps/foo/deployment.yaml"))
+	assert.True(t, exists("values/foo.yaml"))
+	assert.False(t, exists("apps/bar"))
+
+	// Checking out without sparse paths restores the complete working tree
+	client, err = NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "")
+	require.NoError(t, err)
+
+	err = client.Checkout(commitSHA, false)
+	require.NoError(t, err)
+
+	assert.True(t, exists("apps/bar/deployment.yaml"))
+}
+
+func Test_nativeGitClient_Checkout_SparsePathDependencies(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"overlays/prod/kustomization.yaml":         "resources: [../../base]",
+		"base/kustomization.yaml":                  "components: [../components/monitoring]",
+		"components/monitoring/kustomization.yaml": "kind: Component",
+		"unrelated/deployment.yaml":                "kind: Deployment",
+	}
+	for p, content := range files {
+		err = os.MkdirAll(filepath.Join(tempDir, filepath.Dir(p)), 0755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(tempDir, p), []byte(content), 0644)
+		require.NoError(t, err)
+	}
+	err = runCmd(tempDir, "git", "add", ".")
+	require.NoError(t, err)
+	err = runCmd(tempDir, "git", "commit", "-m", "Initial commit")
+	require.NoError(t, err)
+
+	exists := func(root string, p string) bool {
+		_, err := os.Stat(filepath.Join(root, p))
+		return err == nil
+	}
+	// the dependencies of each kustomization are the directories of the checked out kustomizations referencing them
+	dependencies := map[string][]string{
+		"overlays/prod":         {"base"},
+		"base":                  {"components/monitoring"},
+		"components/monitoring": nil,
+	}
+	checkout := func(t *testing.T, sparsePathDependencies SparsePathDependencies) string {
+		root, err := os.MkdirTemp("", "")
+		require.NoError(t, err)
+		client, err := NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "",
+			WithSparsePaths([]string{"overlays/prod"}), WithSparsePathDependencies(sparsePathDependencies))
+		require.NoError(t, err)
+		err = client.Init()
+		require.NoError(t, err)
+		err = client.Fetch("")
+		require.NoError(t, err)
+		commitSHA, err := client.LsRemote("HEAD")
+		require.NoError(t, err)
+		err = client.Checkout(commitSHA, false)
+		require.NoError(t, err)
+		return root
+	}
+
+	t.Run("Dependencies", func(t *testing.T) {
+		root := checkout(t, func(root string, paths []string) ([]string, bool, error) {
+			var deps []string
+			for _, p := range paths {
+				if exists(root, filepath.Join(p, "kustomization.yaml")) {
+					deps = append(deps, dependencies[p]...)
+				}
+			}
+			return deps, false, nil
+		})
+		assert.True(t, exists(root, "overlays/prod/kustomization.yaml"))
+		assert.True(t, exists(root, "base/kustomization.yaml"))
+		assert.True(t, exists(root, "components/monitoring/kustomization.yaml"))
+		assert.False(t, exists(root, "unrelated"))
+	})
+
+	t.Run("CompleteWorkingTree", func(t *testing.T) {
+		root := checkout(t, func(root string, paths []string) ([]string, bool, error) {
+			return nil, true, nil
+		})
+		assert.True(t, exists(root, "unrelated/deployment.yaml"))
+	})
+}
+
+func Test_missingSparsePaths(t *testing.T) {
+	assert.Equal(t, []string{"base", "components/monitoring"},
+		missingSparsePaths([]string{"overlays/prod", "values/foo.yaml"}, []string{"overlays/prod/patches", "base", "base/", "components/monitoring", "values/foo.yaml"}))
+	assert.Nil(t, missingSparsePaths([]string{"overlays"}, []string{"overlays/prod"}))
+}
+
+func Test_nativeGitClient_LsRemote_TagGlob(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+
+	tagSHAs := map[string]string{}
+	for _, tag := range []string{"v1.2.0", "v1.10.0", "v1.9.0", "v2.0.0", "release-a", "release-b"} {
+		err = runCmd(tempDir, "git", "commit", "-m", tag, "--allow-empty")
+		require.NoError(t, err)
+		err = runCmd(tempDir, "git", "tag", tag)
+		require.NoError(t, err)
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = tempDir
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		tagSHAs[tag] = strings.TrimSpace(string(out))
+	}
+
+	client, err := NewClient(fmt.Sprintf("file://%s", tempDir), NopCreds{}, true, false, "")
+	require.NoError(t, err)
+
+	for pattern, tag := range map[string]string{"v1.*": "v1.10.0", "v*": "v2.0.0", "release-?": "release-b", "*": "v2.0.0"} {
+		commitSHA, err := client.LsRemote(pattern)
+		require.NoError(t, err)
+		assert.Equal(t, tagSHAs[tag], commitSHA, pattern)
+	}
+
+	_, err = client.LsRemote("v3.*")
+	assert.ErrorContains(t, err, "no tag matches the pattern")
+}
+
+// fakeGitRefCache is an in-memory cache of git references
+type fakeGitRefCache struct {
+	lock sync.Mutex
+	refs map[string][]*plumbing.Reference
+}
+
+func (c *fakeGitRefCache) SetGitReferences(repo string, references []*plumbing.Reference) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.refs[repo] = references
+	return nil
+}
+
+func (c *fakeGitRefCache) GetGitReferences(repo string, references *[]*plumbing.Reference) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	refs, ok := c.refs[repo]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	*references = refs
+	return nil
+}
+
+func Test_nativeGitClient_LsRefs_Coalesced(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, runCmd(tempDir, "git", "init"))
+	require.NoError(t, runCmd(tempDir, "git", "commit", "-m", "first commit", "--allow-empty"))
+	repoURL := fmt.Sprintf("file://%s", tempDir)
+
+	lsRefsConcurrently := func(t *testing.T, cache *fakeGitRefCache, loadRefFromCache bool) int32 {
+		var lsRemotes int32
+		var started sync.WaitGroup
+		started.Add(5)
+		handlers := EventHandlers{OnLsRemote: func(repo string) func() {
+			atomic.AddInt32(&lsRemotes, 1)
+			// keep listing the references until all the calls were started, so that they overlap
+			started.Wait()
+			return func() {}
+		}}
+		var wg sync.WaitGroup
+		errs := make([]error, 5)
+		branches := make([][]string, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				client, err := NewClient(repoURL, NopCreds{}, true, false, "", WithCache(cache, loadRefFromCache), WithEventHandlers(handlers))
+				if err != nil {
+					started.Done()
+					errs[i] = err
+					return
+				}
+				started.Done()
+				refs, err := client.LsRefs()
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				branches[i] = refs.Branches
+			}(i)
+		}
+		wg.Wait()
+		for i := range errs {
+			require.NoError(t, errs[i])
+			assert.NotEmpty(t, branches[i])
+		}
+		return atomic.LoadInt32(&lsRemotes)
+	}
+
+	t.Run("Cached", func(t *testing.T) {
+		// the concurrent calls wait for the first one and use the references which it cached
+		cache := &fakeGitRefCache{refs: map[string][]*plumbing.Reference{}}
+		assert.Equal(t, int32(1), lsRefsConcurrently(t, cache, true))
+		assert.NotEmpty(t, cache.refs[repoURL])
+	})
+
+	t.Run("NotLoadedFromCache", func(t *testing.T) {
+		// calls which must not use cached references, e.g. hard refreshes, all list the references of the remote
+		assert.Equal(t, int32(5), lsRefsConcurrently(t, &fakeGitRefCache{refs: map[string][]*plumbing.Reference{}}, false))
+	})
+}
+
+func Test_nativeGitClient_ReferenceRepo(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+
+	err = runCmd(tempDir, "git", "init")
+	require.NoError(t, err)
+	err = runCmd(tempDir, "git", "commit", "-m", "Initial commit", "--allow-empty")
+	require.NoError(t, err)
+
+	referenceRepo := ReferenceRepoPath(t.TempDir(), fmt.Sprintf("file://%s", tempDir))
+
+	newClient := func() Client {
+		root, err := os.MkdirTemp("", "")
+		require.NoError(t, err)
+		client, err := NewClientExt(fmt.Sprintf("file://%s", tempDir), root, NopCreds{}, true, false, "", WithReferenceRepo(referenceRepo))
+		require.NoError(t, err)
+		err = client.Init()
+		require.NoError(t, err)
+		return client
+	}
+
+	client := newClient()
+	err = client.Fetch("")
+	require.NoError(t, err)
+	commitSHA, err := client.LsRemote("HEAD")
+	require.NoError(t, err)
+	err = client.Checkout(commitSHA, false)
+	require.NoError(t, err)
+
+	// the objects are stored in the reference repository and shared with the working copies
+	alternates, err := os.ReadFile(filepath.Join(client.Root(), ".git", "objects", "info", "alternates"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(referenceRepo, "objects")+"\n", string(alternates))
+	err = runCmd(referenceRepo, "git", "cat-file", "-e", commitSHA)
+	require.NoError(t, err)
+	// origin is only fetched into the reference repository, the working copy is fetched from it
+	fetchHead, err := os.ReadFile(filepath.Join(client.Root(), ".git", "FETCH_HEAD"))
+	require.NoError(t, err)
+	assert.Contains(t, string(fetchHead), referenceRepo)
+	assert.NotContains(t, string(fetchHead), tempDir)
+
+	client = newClient()
+	err = client.Fetch(commitSHA)
+	require.NoError(t, err)
+	err = client.Checkout("FETCH_HEAD", false)
+	require.NoError(t, err)
+	sha, err := client.CommitSHA()
+	require.NoError(t, err)
+	assert.Equal(t, commitSHA, sha)
+}
+
 func Test_nativeGitClient_Submodule(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "")
 	require.NoError(t, err)
@@ -151,6 +561,25 @@ func Test_nativeGitClient_Submodule(t *testing.T) {
 	result, err = cmd.Output()
 	assert.NoError(t, err)
 	assert.Equal(t, bar+"baz\n", string(result))
+
+	// Submodules are fetched with the credentials of the repository unless skipped
+	for _, skip := range []bool{false, true} {
+		creds := &countingCreds{}
+		client, err := NewClientExt(fmt.Sprintf("file://%s", foo), client.Root(), creds, true, false, "", WithSkipSubmoduleCreds(skip))
+		require.NoError(t, err)
+		err = client.Submodule()
+		assert.NoError(t, err)
+		assert.Equal(t, !skip, creds.count > 0)
+	}
+}
+
+type countingCreds struct {
+	count int
+}
+
+func (c *countingCreds) Environ() (io.Closer, []string, error) {
+	c.count++
+	return NopCloser{}, nil, nil
 }
 
 func TestNewClient_invalidSSHURL(t *testing.T) {
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
@@ -90,6 +91,37 @@ func IsHTTPURL(url string) bool {
 	return httpURLRegex.MatchString(url)
 }
 
+// RepoHostName returns the host name under which the TLS certificates or SSH known hosts of a repository are stored,
+// and whether the repository is accessed via SSH
+func RepoHostName(repoURL string) (string, bool) {
+	if ok, _ := IsSSHURL(repoURL); ok {
+		if strings.HasPrefix(repoURL, "ssh://") {
+			parsed, err := url.Parse(repoURL)
+			if err != nil {
+				return "", true
+			}
+			if parsed.Port() != "" {
+				return fmt.Sprintf("[%s]:%s", parsed.Hostname(), parsed.Port()), true
+			}
+			return parsed.Hostname(), true
+		}
+		host := repoURL[strings.Index(repoURL, "@")+1:]
+		if i := strings.IndexAny(host, ":/"); i >= 0 {
+			host = host[:i]
+		}
+		return host, true
+	}
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return "", false
+	}
+	if parsed.Host != "" {
+		return parsed.Hostname(), false
+	}
+	// OCI repositories are often specified without protocol
+	return strings.SplitN(parsed.Path, "/", 2)[0], false
+}
+
 // TestRepo tests if a repo exists and is accessible with the given credentials
 func TestRepo(repo string, creds Creds, insecure bool, enableLfs bool, proxy string) error {
 	clnt, err := NewClient(repo, creds, insecure, enableLfs, proxy)
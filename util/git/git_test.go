@@ -462,3 +462,20 @@ func TestLsFiles(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, lsResult, nilResult)
 }
+
+func TestRepoHostName(t *testing.T) {
+	for url, expected := range map[string]struct {
+		host  string
+		isSSH bool
+	}{
+		"https://github.com/argoproj/argo-cd":        {"github.com", false},
+		"https://github.com:8443/argoproj/argo-cd":   {"github.com", false},
+		"git@github.com:argoproj/argo-cd.git":        {"github.com", true},
+		"ssh://git@github.com:2222/argoproj/argo-cd": {"[github.com]:2222", true},
+		"registry.example.com/charts":                {"registry.example.com", false},
+	} {
+		host, isSSH := RepoHostName(url)
+		assert.Equal(t, expected.host, host, url)
+		assert.Equal(t, expected.isSSH, isSSH, url)
+	}
+}
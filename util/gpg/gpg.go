@@ -648,11 +648,15 @@ func ParseGitCommitVerification(signature string) PGPVerifyResult {
 					return unknownResult("Could not parse result of verify operation, check logs for more information.")
 				}
 
+				result.Message = "Success verifying the commit signature."
 				switch strings.ToLower(sigState[1]) {
 				case "good":
 					result.Result = VerifyResultGood
 				case "bad":
 					result.Result = VerifyResultBad
+				case "expired":
+					result.Result = VerifyResultInvalid
+					result.Message = "The commit signature has expired."
 				default:
 					result.Result = VerifyResultInvalid
 				}
@@ -664,7 +668,12 @@ func ParseGitCommitVerification(signature string) PGPVerifyResult {
 				} else {
 					result.Trust = TrustUnknown
 				}
-				result.Message = "Success verifying the commit signature."
+
+				// A good signature made with a key which has expired or was revoked since must not be trusted
+				if validity := strings.ToLower(sigState[3]); result.Result == VerifyResultGood && (validity == "expired" || validity == "revoked") {
+					result.Result = VerifyResultInvalid
+					result.Message = fmt.Sprintf("The key the commit was signed with has %s.", validity)
+				}
 			}
 
 			// No more data to parse here
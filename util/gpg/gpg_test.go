@@ -356,6 +356,31 @@ func Test_GPG_ParseGitCommitVerification(t *testing.T) {
 		assert.Equal(t, VerifyResultBad, res.Result)
 	}
 
+	// Expired signature with known key
+	{
+		c, err := os.ReadFile("testdata/expired_signature.txt")
+		if err != nil {
+			panic(err.Error())
+		}
+		res := ParseGitCommitVerification(string(c))
+		assert.Equal(t, "4AEE18F83AFDEB23", res.KeyID)
+		assert.Equal(t, VerifyResultInvalid, res.Result)
+		assert.Equal(t, "The commit signature has expired.", res.Message)
+	}
+
+	// Good signature made with an expired key
+	{
+		c, err := os.ReadFile("testdata/good_signature_expired_key.txt")
+		if err != nil {
+			panic(err.Error())
+		}
+		res := ParseGitCommitVerification(string(c))
+		assert.Equal(t, "4AEE18F83AFDEB23", res.KeyID)
+		assert.Equal(t, TrustUnknown, res.Trust)
+		assert.Equal(t, VerifyResultInvalid, res.Result)
+		assert.Equal(t, "The key the commit was signed with has expired.", res.Message)
+	}
+
 	// Bad case: Manipulated/invalid clear text signature
 	{
 		c, err := os.ReadFile("testdata/bad_signature_manipulated.txt")
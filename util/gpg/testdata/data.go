@@ -30,6 +30,9 @@ var (
 	//go:embed bad_signature_preeof2.txt
 	Bad_signature_preeof2_txt string
 
+	//go:embed expired_signature.txt
+	Expired_signature_txt string
+
 	//go:embed garbage.asc
 	Garbage_asc string
 
@@ -39,6 +42,9 @@ var (
 	//go:embed good_signature.txt
 	Good_signature_txt string
 
+	//go:embed good_signature_expired_key.txt
+	Good_signature_expired_key_txt string
+
 	//go:embed janedoe.asc
 	Janedoe_asc string
 
@@ -163,8 +163,13 @@ func TestTLS(address string, dialTime time.Duration) (*TLSTestResult, error) {
 	return nil, err
 }
 
+// WithTimeout returns an interceptor which sets the given timeout on calls whose context has no deadline yet. A deadline
+// set by the caller takes precedence, so that callers are able to grant individual calls more or less time.
 func WithTimeout(duration time.Duration) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
 		clientDeadline := time.Now().Add(duration)
 		ctx, cancel := context.WithDeadline(ctx, clientDeadline)
 		defer cancel()
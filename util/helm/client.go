@@ -319,6 +319,47 @@ func (c *nativeHelmChart) loadRepoIndex() ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// GetFile downloads the file at the given URL, authenticating with the given repository credentials. The download is
+// bound by the given context and fails if the file is larger than maxSize bytes.
+func GetFile(ctx context.Context, fileURL string, creds Creds, proxyURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if creds.Username != "" || creds.Password != "" {
+		// only basic supported
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	tlsConf, err := newTLSConfig(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	client := http.Client{Transport: &http.Transport{
+		Proxy:             proxy.GetCallback(proxyURL),
+		TLSClientConfig:   tlsConf,
+		DisableKeepAlives: true,
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %s: %s", fileURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file %s exceeds the maximum size of %d bytes", fileURL, maxSize)
+	}
+	return data, nil
+}
+
 func newTLSConfig(creds Creds) (*tls.Config, error) {
 	tlsConfig := &tls.Config{InsecureSkipVerify: creds.InsecureSkipVerify}
 
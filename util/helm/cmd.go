@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -24,15 +25,19 @@ type Cmd struct {
 	WorkDir   string
 	IsLocal   bool
 	IsHelmOci bool
-	proxy     string
+	// Timeout overrides the default timeout of the helm commands if set
+	Timeout time.Duration
+	proxy   string
 }
 
 func NewCmd(workDir string, version string, proxy string) (*Cmd, error) {
 
 	switch version {
 	// If v3 is specified (or by default, if no value is specified) then use v3
-	case "", "v3":
+	case "", "v3", "3":
 		return NewCmdWithVersion(workDir, HelmV3, false, proxy)
+	case "v2", "2":
+		return NewCmdWithVersion(workDir, HelmV2, false, proxy)
 	}
 	return nil, fmt.Errorf("helm chart version '%s' is not supported", version)
 }
@@ -59,6 +64,10 @@ func (c Cmd) run(args ...string) (string, error) {
 			fmt.Sprintf("XDG_CONFIG_HOME=%s/config", c.helmHome),
 			fmt.Sprintf("XDG_DATA_HOME=%s/data", c.helmHome),
 			fmt.Sprintf("HELM_CONFIG_HOME=%s/config", c.helmHome))
+		if c.initSupported {
+			// Helm 2 keeps its repositories and plugins in HELM_HOME rather than the XDG directories
+			cmd.Env = append(cmd.Env, fmt.Sprintf("HELM_HOME=%s", c.helmHome))
+		}
 	}
 
 	if c.IsHelmOci {
@@ -67,7 +76,7 @@ func (c Cmd) run(args ...string) (string, error) {
 
 	cmd.Env = proxy.UpsertEnv(cmd, c.proxy)
 
-	return executil.RunWithRedactor(cmd, redactor)
+	return executil.RunWithExecRunOpts(cmd, executil.ExecRunOpts{Redactor: redactor, Timeout: c.Timeout})
 }
 
 func (c *Cmd) Init() (string, error) {
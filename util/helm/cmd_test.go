@@ -29,6 +29,16 @@ func TestNewCmd_helmV3(t *testing.T) {
 	assert.Equal(t, "helm", cmd.HelmVer.binaryName)
 }
 
+func TestNewCmd_helmV2(t *testing.T) {
+	for _, version := range []string{"v2", "2"} {
+		cmd, err := NewCmd(".", version, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "helm2", cmd.HelmVer.binaryName)
+		assert.Equal(t, "--name", cmd.HelmVer.templateNameArg)
+		assert.True(t, cmd.HelmVer.initSupported)
+	}
+}
+
 func TestNewCmd_helmDefaultVersion(t *testing.T) {
 	cmd, err := NewCmd(".", "", "")
 	assert.NoError(t, err)
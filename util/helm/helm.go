@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"sigs.k8s.io/yaml"
@@ -42,13 +43,15 @@ type Helm interface {
 	Dispose()
 }
 
-// NewHelmApp create a new wrapper to run commands on the `helm` command-line tool.
-func NewHelmApp(workDir string, repos []HelmRepository, isLocal bool, version string, proxy string, passCredentials bool) (Helm, error) {
+// NewHelmApp create a new wrapper to run commands on the `helm` command-line tool. A zero timeout means that the default
+// timeout applies to the helm commands.
+func NewHelmApp(workDir string, repos []HelmRepository, isLocal bool, version string, proxy string, passCredentials bool, timeout time.Duration) (Helm, error) {
 	cmd, err := NewCmd(workDir, version, proxy)
 	if err != nil {
 		return nil, err
 	}
 	cmd.IsLocal = isLocal
+	cmd.Timeout = timeout
 
 	return &helm{repos: repos, cmd: *cmd, passCredentials: passCredentials}, nil
 }
@@ -118,6 +121,8 @@ func (h *helm) Dispose() {
 	h.cmd.Close()
 }
 
+// Version returns the version of the default Helm 3 binary, which is the Helm version reported by Argo CD. The
+// binaries configured for templating with Helm 2 or Helm 3 on the repo server aren't taken into account.
 func Version(shortForm bool) (string, error) {
 	executable := "helm"
 	cmdArgs := []string{"version", "--client"}
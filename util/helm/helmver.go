@@ -3,12 +3,28 @@ package helm
 import (
 	"os"
 	"path"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/argoproj/argo-cd/v2/util/env"
 )
 
 var (
+	// HelmV2 represents helm V2 specific settings
+	HelmV2 = HelmVer{
+		binaryName:                   env.StringFromEnv(common.EnvHelmV2BinaryPath, "helm2"),
+		templateNameArg:              "--name",
+		kubeVersionSupported:         false,
+		showCommand:                  "inspect",
+		pullCommand:                  "fetch",
+		initSupported:                true,
+		getPostTemplateCallback:      nil,
+		includeCrds:                  false,
+		insecureSkipVerifySupported:  false,
+		helmPassCredentialsSupported: false,
+	}
 	// HelmV3 represents helm V3 specific settings
 	HelmV3 = HelmVer{
-		binaryName:                   "helm",
+		binaryName:                   env.StringFromEnv(common.EnvHelmV3BinaryPath, "helm"),
 		templateNameArg:              "--name-template",
 		kubeVersionSupported:         true,
 		showCommand:                  "show",
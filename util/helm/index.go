@@ -12,6 +12,7 @@ import (
 type Entry struct {
 	Version string
 	Created time.Time
+	Digest  string
 }
 
 type Index struct {
@@ -28,6 +29,16 @@ func (i *Index) GetEntries(chart string) (Entries, error) {
 
 type Entries []Entry
 
+// Find returns the entry of the given version
+func (e Entries) Find(version string) (*Entry, bool) {
+	for i := range e {
+		if e[i].Version == version {
+			return &e[i], true
+		}
+	}
+	return nil, false
+}
+
 func (e Entries) MaxVersion(constraints *semver.Constraints) (*semver.Version, error) {
 	versions := semver.Collection{}
 	for _, entry := range e {
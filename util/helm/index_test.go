@@ -82,3 +82,16 @@ func TestEntries_MaxVersion(t *testing.T) {
 		assert.Equal(t, semver.MustParse("0.7.2"), version)
 	})
 }
+
+func TestEntries_Find(t *testing.T) {
+	entries, _ := index.GetEntries("argo-cd")
+	t.Run("NotFound", func(t *testing.T) {
+		_, ok := entries.Find("0.8.1")
+		assert.False(t, ok)
+	})
+	t.Run("Found", func(t *testing.T) {
+		entry, ok := entries.Find("0.5.3")
+		assert.True(t, ok)
+		assert.Equal(t, "0.5.3", entry.Version)
+	})
+}
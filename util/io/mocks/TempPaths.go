@@ -49,6 +49,22 @@ func (_m *TempPaths) GetPathIfExists(key string) string {
 	return r0
 }
 
+// GetPaths provides a mock function with given fields:
+func (_m *TempPaths) GetPaths() map[string]string {
+	ret := _m.Called()
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewTempPaths interface {
 	mock.TestingT
 	Cleanup(func())
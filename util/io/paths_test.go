@@ -49,3 +49,13 @@ func TestGetPathIfExists(t *testing.T) {
 		assert.NotEmpty(t, path)
 	})
 }
+
+func TestGetPaths(t *testing.T) {
+	paths := NewRandomizedTempPaths(os.TempDir())
+	path, err := paths.GetPath("https://localhost/test.txt")
+	require.NoError(t, err)
+	allPaths := paths.GetPaths()
+	assert.Equal(t, map[string]string{"https://localhost/test.txt": path}, allPaths)
+	allPaths["https://localhost/other.txt"] = "other"
+	assert.Empty(t, paths.GetPathIfExists("https://localhost/other.txt"))
+}
@@ -1,6 +1,7 @@
 package kustomize
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -11,12 +12,14 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	certutil "github.com/argoproj/argo-cd/v2/util/cert"
@@ -33,13 +36,15 @@ type Kustomize interface {
 	Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOptions *v1alpha1.KustomizeOptions, envVars *v1alpha1.Env) ([]*unstructured.Unstructured, []Image, error)
 }
 
-// NewKustomizeApp create a new wrapper to run commands on the `kustomize` command-line tool.
-func NewKustomizeApp(path string, creds git.Creds, fromRepo string, binaryPath string) Kustomize {
+// NewKustomizeApp create a new wrapper to run commands on the `kustomize` command-line tool. A zero timeout means that
+// the default timeout applies to the kustomize commands.
+func NewKustomizeApp(path string, creds git.Creds, fromRepo string, binaryPath string, timeout time.Duration) Kustomize {
 	return &kustomize{
 		path:       path,
 		creds:      creds,
 		repo:       fromRepo,
 		binaryPath: binaryPath,
+		timeout:    timeout,
 	}
 }
 
@@ -52,6 +57,8 @@ type kustomize struct {
 	repo string
 	// optional kustomize binary path
 	binaryPath string
+	// optional timeout of the kustomize commands
+	timeout time.Duration
 }
 
 var _ Kustomize = &kustomize{}
@@ -63,6 +70,10 @@ func (k *kustomize) getBinaryPath() string {
 	return "kustomize"
 }
 
+func (k *kustomize) run(cmd *exec.Cmd) (string, error) {
+	return executil.RunWithExecRunOpts(cmd, executil.ExecRunOpts{Timeout: k.timeout})
+}
+
 // kustomize v3.8.5 patch release introduced a breaking change in "edit add <label/annotation>" commands:
 // https://github.com/kubernetes-sigs/kustomize/commit/b214fa7d5aa51d7c2ae306ec15115bf1c044fed8#diff-0328c59bcd29799e365ff0647653b886f17c8853df008cd54e7981db882c1b36
 func mapToEditAddArgs(val map[string]string) []string {
@@ -90,7 +101,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 		if opts.NamePrefix != "" {
 			cmd := exec.Command(k.getBinaryPath(), "edit", "set", "nameprefix", "--", opts.NamePrefix)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -98,7 +109,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 		if opts.NameSuffix != "" {
 			cmd := exec.Command(k.getBinaryPath(), "edit", "set", "namesuffix", "--", opts.NameSuffix)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -114,7 +125,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 			}
 			cmd := exec.Command(k.getBinaryPath(), args...)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -134,7 +145,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 
 			cmd := exec.Command(k.getBinaryPath(), args...)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -152,7 +163,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 			}
 			cmd := exec.Command(k.getBinaryPath(), append(args, mapToEditAddArgs(commonLabels)...)...)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -175,7 +186,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 			}
 			cmd := exec.Command(k.getBinaryPath(), append(args, mapToEditAddArgs(commonAnnotations)...)...)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -184,7 +195,24 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 		if opts.Namespace != "" {
 			cmd := exec.Command(k.getBinaryPath(), "edit", "set", "namespace", "--", opts.Namespace)
 			cmd.Dir = k.path
-			_, err := executil.Run(cmd)
+			_, err := k.run(cmd)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if len(opts.Patches) > 0 {
+			if err := k.addPatches(opts.Patches); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if len(opts.Components) > 0 {
+			// edit add component ../components/foo ../components/bar
+			args := []string{"edit", "add", "component"}
+			cmd := exec.Command(k.getBinaryPath(), append(args, opts.Components...)...)
+			cmd.Dir = k.path
+			_, err := k.run(cmd)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -232,7 +260,7 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 	}
 
 	cmd.Env = append(cmd.Env, environ...)
-	out, err := executil.Run(cmd)
+	out, err := k.run(cmd)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -245,6 +273,50 @@ func (k *kustomize) Build(opts *v1alpha1.ApplicationSourceKustomize, kustomizeOp
 	return objs, getImageParameters(objs), nil
 }
 
+// addPatches appends the given patches to the `patches` list of the kustomization. The kustomization is edited
+// directly since `kustomize edit add patch` does not support all patch fields across kustomize versions.
+func (k *kustomize) addPatches(patches v1alpha1.KustomizePatches) error {
+	kustomizationPath, err := k.findKustomization()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(kustomizationPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(kustomizationPath)
+	if err != nil {
+		return fmt.Errorf("failed to read kustomization %s: %w", kustomizationPath, err)
+	}
+	var kustomization map[string]interface{}
+	if err := yaml.Unmarshal(data, &kustomization); err != nil {
+		return fmt.Errorf("failed to parse kustomization %s: %w", kustomizationPath, err)
+	}
+	if kustomization == nil {
+		kustomization = map[string]interface{}{}
+	}
+
+	patchesData, err := json.Marshal(patches)
+	if err != nil {
+		return err
+	}
+	var newPatches []interface{}
+	if err := json.Unmarshal(patchesData, &newPatches); err != nil {
+		return err
+	}
+	existing, ok := kustomization["patches"].([]interface{})
+	if !ok && kustomization["patches"] != nil {
+		return fmt.Errorf("failed to add patches to kustomization %s: patches is not a list", kustomizationPath)
+	}
+	kustomization["patches"] = append(existing, newPatches...)
+
+	data, err = yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(kustomizationPath, data, info.Mode())
+}
+
 func parseKustomizeBuildOptions(path, buildOptions string) []string {
 	return append([]string{"build", path}, strings.Split(buildOptions, " ")...)
 }
@@ -271,6 +343,99 @@ func IsKustomization(path string) bool {
 	return false
 }
 
+// remoteReferences is the subset of a kustomization which may reference remote repositories
+type remoteReferences struct {
+	Resources  []string `json:"resources"`
+	Bases      []string `json:"bases"`
+	Components []string `json:"components"`
+	HelmCharts []struct {
+		Repo string `json:"repo"`
+	} `json:"helmCharts"`
+}
+
+// RemoteRepositories returns the URLs of the remote repositories which the kustomization in the given path or one of its
+// local bases references, i.e. the repositories of remote resources, bases and components and of Helm charts. Like
+// kustomize, references which don't exist locally are treated as remote.
+func RemoteRepositories(path string) ([]string, error) {
+	var repos []string
+	visited := map[string]bool{}
+	var visit func(dir string) error
+	visit = func(dir string) error {
+		if visited[dir] {
+			return nil
+		}
+		visited[dir] = true
+		k := &kustomize{path: dir}
+		kustomizationPath, err := k.findKustomization()
+		if err != nil {
+			// directories without kustomization are plain resources
+			return nil
+		}
+		data, err := os.ReadFile(kustomizationPath)
+		if err != nil {
+			return fmt.Errorf("failed to read kustomization %s: %w", kustomizationPath, err)
+		}
+		var refs remoteReferences
+		if err := yaml.Unmarshal(data, &refs); err != nil {
+			return fmt.Errorf("failed to parse kustomization %s: %w", kustomizationPath, err)
+		}
+		for _, ref := range append(append(refs.Resources, refs.Bases...), refs.Components...) {
+			local := filepath.Join(dir, ref)
+			info, err := os.Stat(local)
+			if err != nil {
+				repos = append(repos, remoteRepoURL(ref))
+				continue
+			}
+			if info.IsDir() {
+				if err := visit(local); err != nil {
+					return err
+				}
+			}
+		}
+		for _, chart := range refs.HelmCharts {
+			if chart.Repo != "" {
+				repos = append(repos, chart.Repo)
+			}
+		}
+		return nil
+	}
+	if err := visit(filepath.Clean(path)); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// remoteRepoURL returns the URL of the repository of a remote kustomize reference, e.g. https://github.com/org/repo for
+// https://github.com/org/repo//path?ref=v1.0.0
+func remoteRepoURL(ref string) string {
+	u := strings.TrimPrefix(ref, "git::")
+	u, _, _ = strings.Cut(u, "?")
+	scheme := ""
+	if i := strings.Index(u, "://"); i >= 0 {
+		scheme, u = u[:i+3], u[i+3:]
+	} else if !strings.HasPrefix(u, "git@") {
+		scheme = "https://"
+	}
+	// the repository and the path within it are separated by a double slash or the .git suffix, if present
+	if repo, _, ok := strings.Cut(u, "//"); ok {
+		return scheme + repo
+	}
+	if i := strings.Index(u, ".git/"); i >= 0 {
+		return scheme + u[:i+len(".git")]
+	}
+	// otherwise the repository is identified by the host and the organization and name of the repository
+	segments := 3
+	if scheme == "" {
+		// the host and organization of scp-like URLs, e.g. git@github.com:org/repo, are a single segment
+		segments = 2
+	}
+	parts := strings.SplitN(u, "/", segments+1)
+	if len(parts) > segments {
+		parts = parts[:segments]
+	}
+	return scheme + strings.Join(parts, "/")
+}
+
 // semver/v3 doesn't export the regexp anymore, so shamelessly copied it over to
 // here.
 // https://github.com/Masterminds/semver/blob/49c09bfed6adcffa16482ddc5e5588cffff9883a/version.go#L42
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v2/util/git"
@@ -38,7 +39,7 @@ func TestKustomizeBuild(t *testing.T) {
 	namePrefix := "namePrefix-"
 	nameSuffix := "-nameSuffix"
 	namespace := "custom-namespace"
-	kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "")
+	kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "", 0)
 	env := &v1alpha1.Env{
 		&v1alpha1.EnvEntry{Name: "ARGOCD_APP_NAME", Value: "argo-cd-tests"},
 	}
@@ -121,7 +122,7 @@ func TestKustomizeBuild(t *testing.T) {
 func TestFailKustomizeBuild(t *testing.T) {
 	appPath, err := testDataDir(t, kustomization1)
 	assert.Nil(t, err)
-	kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "")
+	kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "", 0)
 	kustomizeSource := v1alpha1.ApplicationSourceKustomize{
 		Replicas: []v1alpha1.KustomizeReplica{
 			{
@@ -146,6 +147,38 @@ func testFindKustomization(t *testing.T, set string, expected string) {
 	assert.Equal(t, "testdata/"+set+"/"+expected, kustomization)
 }
 
+func TestKustomizeAddPatches(t *testing.T) {
+	appPath, err := testDataDir(t, kustomization1)
+	require.NoError(t, err)
+	k := &kustomize{path: appPath}
+
+	err = k.addPatches(v1alpha1.KustomizePatches{
+		{
+			Patch: `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`,
+			Target: &v1alpha1.KustomizeSelector{
+				Kind: "Deployment",
+				Name: "nginx-deployment",
+			},
+		},
+	})
+	require.NoError(t, err)
+	err = k.addPatches(v1alpha1.KustomizePatches{{Path: "patch.yaml"}})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(appPath, "kustomization.yaml"))
+	require.NoError(t, err)
+	var kustomization struct {
+		Resources []string                  `json:"resources"`
+		Patches   []v1alpha1.KustomizePatch `json:"patches"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &kustomization))
+	assert.Equal(t, []string{"./deployment.yaml", "./statefulset.yaml"}, kustomization.Resources)
+	require.Len(t, kustomization.Patches, 2)
+	assert.Equal(t, "Deployment", kustomization.Patches[0].Target.Kind)
+	assert.Equal(t, "nginx-deployment", kustomization.Patches[0].Target.Name)
+	assert.Equal(t, "patch.yaml", kustomization.Patches[1].Path)
+}
+
 func TestIsKustomization(t *testing.T) {
 	assert.True(t, IsKustomization("kustomization.yaml"))
 	assert.True(t, IsKustomization("kustomization.yml"))
@@ -220,7 +253,7 @@ func TestKustomizeBuildForceCommonLabels(t *testing.T) {
 	for _, tc := range testCases {
 		appPath, err := testDataDir(t, tc.TestData)
 		assert.Nil(t, err)
-		kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "")
+		kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "", 0)
 		objs, _, err := kustomize.Build(&tc.KustomizeSource, nil, tc.Env)
 		switch tc.ExpectErr {
 		case true:
@@ -312,7 +345,7 @@ func TestKustomizeBuildForceCommonAnnotations(t *testing.T) {
 	for _, tc := range testCases {
 		appPath, err := testDataDir(t, tc.TestData)
 		assert.Nil(t, err)
-		kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "")
+		kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", "", 0)
 		objs, _, err := kustomize.Build(&tc.KustomizeSource, nil, tc.Env)
 		switch tc.ExpectErr {
 		case true:
@@ -332,7 +365,7 @@ func TestKustomizeCustomVersion(t *testing.T) {
 	kustomizePath, err := testDataDir(t, kustomization4)
 	assert.Nil(t, err)
 	envOutputFile := kustomizePath + "/env_output"
-	kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", kustomizePath+"/kustomize.special")
+	kustomize := NewKustomizeApp(appPath, git.NopCreds{}, "", kustomizePath+"/kustomize.special", 0)
 	kustomizeSource := v1alpha1.ApplicationSourceKustomize{
 		Version: "special",
 	}
@@ -350,3 +383,55 @@ func TestKustomizeCustomVersion(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "ARGOCD_APP_NAME=argo-cd-tests\n", string(content))
 }
+
+func TestRemoteRepositories(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+	}
+	writeFile("base/kustomization.yaml", `
+resources:
+- deployment.yaml
+- https://github.com/org/base//deploy?ref=v1.0.0
+helmCharts:
+- name: chart
+  repo: https://charts.example.com
+`)
+	writeFile("base/deployment.yaml", "")
+	writeFile("overlay/kustomization.yaml", `
+resources:
+- ../base
+- ../overlay
+components:
+- git@github.com:org/components.git/common?ref=main
+bases:
+- github.com/org/legacy/base
+`)
+
+	repos, err := RemoteRepositories(filepath.Join(root, "overlay"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"https://github.com/org/base",
+		"https://charts.example.com",
+		"git@github.com:org/components.git",
+		"https://github.com/org/legacy",
+	}, repos)
+
+	repos, err = RemoteRepositories(filepath.Join(root, "base", "deployment.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestRemoteRepoURL(t *testing.T) {
+	for ref, repo := range map[string]string{
+		"https://github.com/org/repo//path?ref=v1":       "https://github.com/org/repo",
+		"https://github.com/org/repo/path/to/base":       "https://github.com/org/repo",
+		"git::https://git.example.com/repo.git/path":     "https://git.example.com/repo.git",
+		"ssh://git@github.com/org/repo.git//path?ref=v1": "ssh://git@github.com/org/repo.git",
+		"git@github.com:org/repo/path":                   "git@github.com:org/repo",
+		"github.com/org/repo?ref=v1":                     "https://github.com/org/repo",
+	} {
+		assert.Equal(t, repo, remoteRepoURL(ref), ref)
+	}
+}
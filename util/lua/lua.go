@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/argoproj/gitops-engine/pkg/health"
@@ -27,6 +28,14 @@ const (
 	healthScriptFile          = "health.lua"
 	actionScriptFile          = "action.lua"
 	actionDiscoveryScriptFile = "discovery.lua"
+	actionParamsGlobal        = "actionParams"
+
+	// ResourceActionParamTypeString is the type of action parameters which are passed to the script as string
+	ResourceActionParamTypeString = "string"
+	// ResourceActionParamTypeNumber is the type of action parameters which are passed to the script as number
+	ResourceActionParamTypeNumber = "number"
+	// ResourceActionParamTypeBoolean is the type of action parameters which are passed to the script as boolean
+	ResourceActionParamTypeBoolean = "boolean"
 )
 
 type ResourceHealthOverrides map[string]appv1.ResourceOverride
@@ -58,7 +67,9 @@ type VM struct {
 	UseOpenLibs bool
 }
 
-func (vm VM) runLua(obj *unstructured.Unstructured, script string) (*lua.LState, error) {
+// runLua runs the given script with the object in the obj global. If params is not nil, it is passed to the script in
+// the actionParams global.
+func (vm VM) runLua(obj *unstructured.Unstructured, script string, params map[string]interface{}) (*lua.LState, error) {
 	l := lua.NewState(lua.Options{
 		SkipOpenLibs: !vm.UseOpenLibs,
 	})
@@ -90,13 +101,16 @@ func (vm VM) runLua(obj *unstructured.Unstructured, script string) (*lua.LState,
 	l.SetContext(ctx)
 	objectValue := decodeValue(l, obj.Object)
 	l.SetGlobal("obj", objectValue)
+	if params != nil {
+		l.SetGlobal(actionParamsGlobal, decodeValue(l, params))
+	}
 	err := l.DoString(script)
 	return l, err
 }
 
 // ExecuteHealthLua runs the lua script to generate the health status of a resource
 func (vm VM) ExecuteHealthLua(obj *unstructured.Unstructured, script string) (*health.HealthStatus, error) {
-	l, err := vm.runLua(obj, script)
+	l, err := vm.runLua(obj, script, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -149,8 +163,12 @@ func (vm VM) GetHealthScript(obj *unstructured.Unstructured) (string, bool, erro
 	return builtInScript, true, err
 }
 
-func (vm VM) ExecuteResourceAction(obj *unstructured.Unstructured, script string) ([]ImpactedResource, error) {
-	l, err := vm.runLua(obj, script)
+// ExecuteResourceAction runs the lua script of an action with the given parameter values, see ResolveResourceActionParams
+func (vm VM) ExecuteResourceAction(obj *unstructured.Unstructured, script string, params map[string]interface{}) ([]ImpactedResource, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	l, err := vm.runLua(obj, script, params)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +288,7 @@ func cleanReturnedArray(newObj, obj []interface{}) []interface{} {
 }
 
 func (vm VM) ExecuteResourceActionDiscovery(obj *unstructured.Unstructured, script string) ([]appv1.ResourceAction, error) {
-	l, err := vm.runLua(obj, script)
+	l, err := vm.runLua(obj, script, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -359,6 +377,116 @@ func (vm VM) GetResourceActionDiscovery(obj *unstructured.Unstructured) (string,
 	return discoveryScript, nil
 }
 
+// GetAvailableResourceActions returns the actions available for the resource. Actions defined in the resource
+// overrides are all available if no discovery script is configured, and list the parameters declared by their
+// definition unless the discovery script returns parameters.
+func (vm VM) GetAvailableResourceActions(obj *unstructured.Unstructured) ([]appv1.ResourceAction, error) {
+	discoveryScript, err := vm.GetResourceActionDiscovery(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Lua discovery script: %w", err)
+	}
+	definitions, err := vm.getResourceActionDefinitions(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resource action definitions: %w", err)
+	}
+	var availableActions []appv1.ResourceAction
+	if discoveryScript == "" {
+		availableActions = make([]appv1.ResourceAction, 0, len(definitions))
+		for _, definition := range definitions {
+			availableActions = append(availableActions, appv1.ResourceAction{Name: definition.Name})
+		}
+	} else {
+		availableActions, err = vm.ExecuteResourceActionDiscovery(obj, discoveryScript)
+		if err != nil {
+			return nil, fmt.Errorf("error executing Lua discovery script: %w", err)
+		}
+	}
+	for i := range availableActions {
+		if len(availableActions[i].Params) > 0 {
+			continue
+		}
+		for _, definition := range definitions {
+			if definition.Name == availableActions[i].Name {
+				availableActions[i].Params = definition.Params
+			}
+		}
+	}
+	return availableActions, nil
+}
+
+// getResourceActionDefinitions returns the actions defined for the resource in the resource overrides
+func (vm VM) getResourceActionDefinitions(obj *unstructured.Unstructured) ([]appv1.ResourceActionDefinition, error) {
+	override, ok := vm.ResourceOverrides[GetConfigMapKey(obj.GroupVersionKind())]
+	if !ok || override.Actions == "" {
+		return nil, nil
+	}
+	actions, err := override.GetActions()
+	if err != nil {
+		return nil, err
+	}
+	return actions.Definitions, nil
+}
+
+// ResolveResourceActionParams validates the given parameter values against the parameters declared by an action and
+// returns the values to pass to the action script, converted to their declared type. Declared parameters which are
+// not given are set to their default value, or are reported as missing if they have none.
+func ResolveResourceActionParams(declared []appv1.ResourceActionParam, given []*appv1.ResourceActionParam) (map[string]interface{}, error) {
+	declaredByName := make(map[string]appv1.ResourceActionParam, len(declared))
+	for _, param := range declared {
+		declaredByName[param.Name] = param
+	}
+	values := make(map[string]interface{}, len(declared))
+	for _, param := range given {
+		declaredParam, ok := declaredByName[param.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q", param.Name)
+		}
+		if _, ok := values[param.Name]; ok {
+			return nil, fmt.Errorf("parameter %q is given more than once", param.Name)
+		}
+		value, err := parseResourceActionParamValue(declaredParam.Type, param.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of parameter %q: %w", param.Name, err)
+		}
+		values[param.Name] = value
+	}
+	for _, param := range declared {
+		if _, ok := values[param.Name]; ok {
+			continue
+		}
+		if param.Default == "" {
+			return nil, fmt.Errorf("parameter %q is required", param.Name)
+		}
+		value, err := parseResourceActionParamValue(param.Type, param.Default)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default value of parameter %q: %w", param.Name, err)
+		}
+		values[param.Name] = value
+	}
+	return values, nil
+}
+
+func parseResourceActionParamValue(paramType string, value string) (interface{}, error) {
+	switch paramType {
+	case "", ResourceActionParamTypeString:
+		return value, nil
+	case ResourceActionParamTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return number, nil
+	case ResourceActionParamTypeBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return boolean, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %q", paramType)
+	}
+}
+
 // GetResourceAction attempts to read lua script from config and then filesystem for that resource
 func (vm VM) GetResourceAction(obj *unstructured.Unstructured, actionName string) (appv1.ResourceActionDefinition, error) {
 	key := GetConfigMapKey(obj.GroupVersionKind())
@@ -387,6 +515,16 @@ func (vm VM) GetResourceAction(obj *unstructured.Unstructured, actionName string
 	}, nil
 }
 
+// ValidateScript verifies that the given Lua script compiles, without executing it
+func ValidateScript(script string) error {
+	l := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer l.Close()
+	if _, err := l.LoadString(script); err != nil {
+		return err
+	}
+	return nil
+}
+
 func GetConfigMapKey(gvk schema.GroupVersionKind) string {
 	if gvk.Group == "" {
 		return gvk.Kind
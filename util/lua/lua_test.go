@@ -112,6 +112,11 @@ func TestFailExternalLibCall(t *testing.T) {
 	assert.IsType(t, &lua.ApiError{}, err)
 }
 
+func TestValidateScript(t *testing.T) {
+	assert.NoError(t, ValidateScript(osLuaScript))
+	assert.Error(t, ValidateScript(`hs = {`))
+}
+
 const returnInt = `return 1`
 
 func TestFailLuaReturnNonTable(t *testing.T) {
@@ -340,6 +345,91 @@ func TestExecuteResourceActionDiscovery(t *testing.T) {
 	}
 }
 
+func TestGetAvailableResourceActions(t *testing.T) {
+	testObj := StrToUnstructured(objWithNoScriptJSON)
+	scale := appv1.ResourceActionDefinition{
+		Name:      "scale",
+		ActionLua: "return obj",
+		Params:    []appv1.ResourceActionParam{{Name: "replicas", Type: ResourceActionParamTypeNumber}},
+	}
+	restart := appv1.ResourceActionDefinition{Name: "restart", ActionLua: "return obj"}
+
+	t.Run("WithoutDiscovery", func(t *testing.T) {
+		vm := VM{
+			ResourceOverrides: map[string]appv1.ResourceOverride{
+				"not-an-endpoint.io/Test": {
+					Actions: string(grpc.MustMarshal(appv1.ResourceActions{
+						Definitions: []appv1.ResourceActionDefinition{scale, restart},
+					})),
+				},
+			},
+		}
+		actions, err := vm.GetAvailableResourceActions(testObj)
+		assert.Nil(t, err)
+		assert.Equal(t, []appv1.ResourceAction{{Name: "scale", Params: scale.Params}, {Name: "restart"}}, actions)
+	})
+
+	t.Run("WithDiscovery", func(t *testing.T) {
+		vm := VM{
+			ResourceOverrides: map[string]appv1.ResourceOverride{
+				"not-an-endpoint.io/Test": {
+					Actions: string(grpc.MustMarshal(appv1.ResourceActions{
+						ActionDiscoveryLua: `return {scale = {disabled = true}}`,
+						Definitions:        []appv1.ResourceActionDefinition{scale, restart},
+					})),
+				},
+			},
+		}
+		actions, err := vm.GetAvailableResourceActions(testObj)
+		assert.Nil(t, err)
+		assert.Equal(t, []appv1.ResourceAction{{Name: "scale", Params: scale.Params, Disabled: true}}, actions)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		actions, err := VM{}.GetAvailableResourceActions(testObj)
+		assert.Nil(t, err)
+		assert.Empty(t, actions)
+	})
+}
+
+func TestResolveResourceActionParams(t *testing.T) {
+	declared := []appv1.ResourceActionParam{
+		{Name: "replicas", Type: ResourceActionParamTypeNumber},
+		{Name: "paused", Type: ResourceActionParamTypeBoolean, Default: "false"},
+		{Name: "reason", Default: "manual"},
+	}
+
+	params, err := ResolveResourceActionParams(declared, []*appv1.ResourceActionParam{{Name: "replicas", Value: "2"}, {Name: "reason", Value: "scaling"}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(2), "paused": false, "reason": "scaling"}, params)
+
+	_, err = ResolveResourceActionParams(declared, nil)
+	assert.EqualError(t, err, `parameter "replicas" is required`)
+
+	_, err = ResolveResourceActionParams(declared, []*appv1.ResourceActionParam{{Name: "replicas", Value: "two"}})
+	assert.EqualError(t, err, `invalid value of parameter "replicas": "two" is not a number`)
+
+	_, err = ResolveResourceActionParams(declared, []*appv1.ResourceActionParam{{Name: "replicas", Value: "2"}, {Name: "replicas", Value: "3"}})
+	assert.EqualError(t, err, `parameter "replicas" is given more than once`)
+
+	_, err = ResolveResourceActionParams(declared, []*appv1.ResourceActionParam{{Name: "replicas", Value: "2"}, {Name: "force", Value: "true"}})
+	assert.EqualError(t, err, `unknown parameter "force"`)
+
+	_, err = ResolveResourceActionParams([]appv1.ResourceActionParam{{Name: "at", Type: "date"}}, []*appv1.ResourceActionParam{{Name: "at", Value: "today"}})
+	assert.EqualError(t, err, `invalid value of parameter "at": unsupported parameter type "date"`)
+}
+
+func TestExecuteResourceActionWithParams(t *testing.T) {
+	testObj := StrToUnstructured(objJSON)
+	vm := VM{}
+	newObjects, err := vm.ExecuteResourceAction(testObj, `
+obj.spec = {replicas = actionParams["replicas"], paused = actionParams["paused"]}
+return obj`, map[string]interface{}{"replicas": float64(2), "paused": true})
+	assert.Nil(t, err)
+	assert.Equal(t, len(newObjects), 1)
+	assert.Equal(t, map[string]interface{}{"replicas": int64(2), "paused": true}, newObjects[0].UnstructuredObj.Object["spec"])
+}
+
 const discoveryLuaWithInvalidResourceAction = `
 resume = {name = 'resume', invalidField: "test""}
 a = {resume = resume}
@@ -389,7 +479,7 @@ func TestExecuteOldStyleResourceAction(t *testing.T) {
 	testObj := StrToUnstructured(objJSON)
 	expectedLuaUpdatedObj := StrToUnstructured(expectedLuaUpdatedResult)
 	vm := VM{}
-	newObjects, err := vm.ExecuteResourceAction(testObj, validActionLua)
+	newObjects, err := vm.ExecuteResourceAction(testObj, validActionLua, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, len(newObjects), 1)
 	assert.Equal(t, newObjects[0].K8SOperation, K8SOperation("patch"))
@@ -561,7 +651,7 @@ func TestExecuteNewStyleCreateActionSingleResource(t *testing.T) {
 	expectedObjects, err := UnmarshalToImpactedResources(bytes.NewBuffer(jsonBytes).String())
 	assert.Nil(t, err)
 	vm := VM{}
-	newObjects, err := vm.ExecuteResourceAction(testObj, createJobActionLua)
+	newObjects, err := vm.ExecuteResourceAction(testObj, createJobActionLua, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, expectedObjects, newObjects)
 }
@@ -574,7 +664,7 @@ func TestExecuteNewStyleCreateActionMultipleResources(t *testing.T) {
 	expectedObjects, err := UnmarshalToImpactedResources(bytes.NewBuffer(jsonBytes).String())
 	assert.Nil(t, err)
 	vm := VM{}
-	newObjects, err := vm.ExecuteResourceAction(testObj, createMultipleJobsActionLua)
+	newObjects, err := vm.ExecuteResourceAction(testObj, createMultipleJobsActionLua, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, expectedObjects, newObjects)
 }
@@ -587,7 +677,7 @@ func TestExecuteNewStyleActionMixedOperationsOk(t *testing.T) {
 	expectedObjects, err := UnmarshalToImpactedResources(bytes.NewBuffer(jsonBytes).String())
 	assert.Nil(t, err)
 	vm := VM{}
-	newObjects, err := vm.ExecuteResourceAction(testObj, mixedOperationActionLuaOk)
+	newObjects, err := vm.ExecuteResourceAction(testObj, mixedOperationActionLuaOk, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, expectedObjects, newObjects)
 }
@@ -595,7 +685,7 @@ func TestExecuteNewStyleActionMixedOperationsOk(t *testing.T) {
 func TestExecuteNewStyleActionMixedOperationsFailure(t *testing.T) {
 	testObj := StrToUnstructured(cronJobObjYaml)
 	vm := VM{}
-	_, err := vm.ExecuteResourceAction(testObj, createMixedOperationActionLuaFailing)
+	_, err := vm.ExecuteResourceAction(testObj, createMixedOperationActionLuaFailing, nil)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "unsupported operation")
 }
@@ -603,7 +693,7 @@ func TestExecuteNewStyleActionMixedOperationsFailure(t *testing.T) {
 func TestExecuteResourceActionNonTableReturn(t *testing.T) {
 	testObj := StrToUnstructured(objJSON)
 	vm := VM{}
-	_, err := vm.ExecuteResourceAction(testObj, returnInt)
+	_, err := vm.ExecuteResourceAction(testObj, returnInt, nil)
 	assert.Errorf(t, err, incorrectReturnType, "table", "number")
 }
 
@@ -615,7 +705,7 @@ return newObj
 func TestExecuteResourceActionInvalidUnstructured(t *testing.T) {
 	testObj := StrToUnstructured(objJSON)
 	vm := VM{}
-	_, err := vm.ExecuteResourceAction(testObj, invalidTableReturn)
+	_, err := vm.ExecuteResourceAction(testObj, invalidTableReturn, nil)
 	assert.Error(t, err)
 }
 
@@ -670,7 +760,7 @@ func TestCleanPatch(t *testing.T) {
 	testObj := StrToUnstructured(objWithEmptyStruct)
 	expectedObj := StrToUnstructured(expectedUpdatedObjWithEmptyStruct)
 	vm := VM{}
-	newObjects, err := vm.ExecuteResourceAction(testObj, pausedToFalseLua)
+	newObjects, err := vm.ExecuteResourceAction(testObj, pausedToFalseLua, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, len(newObjects), 1)
 	assert.Equal(t, newObjects[0].K8SOperation, K8SOperation("patch"))
@@ -53,9 +53,15 @@ func (svc *argoCDService) GetCommitMetadata(ctx context.Context, repoURL string,
 	if err != nil {
 		return nil, err
 	}
+	repoURLPolicy, err := svc.settingsMgr.GetRepositoryURLPolicy()
+	if err != nil {
+		return nil, err
+	}
 	metadata, err := svc.repoServerClient.GetRevisionMetadata(ctx, &apiclient.RepoServerRevisionMetadataRequest{
-		Repo:     repo,
-		Revision: commitSHA,
+		Repo:                repo,
+		Revision:            commitSHA,
+		RepositoryAllowList: repoURLPolicy.Allow,
+		RepositoryDenyList:  repoURLPolicy.Deny,
 	})
 	if err != nil {
 		return nil, err
@@ -94,12 +100,18 @@ func (svc *argoCDService) GetAppDetails(ctx context.Context, appSource *v1alpha1
 	if err != nil {
 		return nil, err
 	}
+	repoURLPolicy, err := svc.settingsMgr.GetRepositoryURLPolicy()
+	if err != nil {
+		return nil, err
+	}
 	appDetail, err := svc.repoServerClient.GetAppDetails(ctx, &apiclient.RepoServerAppDetailsQuery{
-		Repo:             repo,
-		Source:           appSource,
-		Repos:            helmRepos,
-		KustomizeOptions: kustomizeOptions,
-		HelmOptions:      helmOptions,
+		Repo:                repo,
+		Source:              appSource,
+		Repos:               helmRepos,
+		KustomizeOptions:    kustomizeOptions,
+		HelmOptions:         helmOptions,
+		RepositoryAllowList: repoURLPolicy.Allow,
+		RepositoryDenyList:  repoURLPolicy.Deny,
 	})
 	if err != nil {
 		return nil, err
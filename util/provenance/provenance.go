@@ -0,0 +1,108 @@
+// Package provenance verifies the signatures of manifests which are rendered outside of Argo CD, e.g. by a CI
+// pipeline, and submitted together with a signature proving their provenance.
+package provenance
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrSignatureMismatch is returned if a signature was not made by any of the trusted keys
+var ErrSignatureMismatch = errors.New("signature does not match any of the trusted keys")
+
+// Subject identifies what manifests are rendered for, so that manifests signed for an application can't be deployed by
+// another one, nor to another destination, nor after the application was moved to another project
+type Subject struct {
+	// Application is the qualified name of the application, i.e. <namespace>/<name>
+	Application string
+	// Project is the project of the application
+	Project string
+	// Server is the URL of the destination cluster
+	Server string
+	// Namespace is the destination namespace
+	Namespace string
+}
+
+// Payload returns the data which is signed to prove the provenance of the manifests of an application: the qualified
+// name of the application, its project, its destination server and namespace and the revision on a line each, followed
+// by the manifests separated by "\n---\n"
+func Payload(subject Subject, revision string, manifests []string) []byte {
+	var buf bytes.Buffer
+	for _, line := range []string{subject.Application, subject.Project, subject.Server, subject.Namespace, revision} {
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	for i, manifest := range manifests {
+		if i > 0 {
+			buf.WriteString("\n---\n")
+		}
+		buf.WriteString(manifest)
+	}
+	return buf.Bytes()
+}
+
+// ParsePublicKeys parses PEM encoded Ed25519, ECDSA and RSA public keys
+func ParsePublicKeys(data string) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block of type %s", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		switch key.(type) {
+		case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		keys = append(keys, key)
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, errors.New("public keys must be PEM encoded")
+	}
+	return keys, nil
+}
+
+// Verify checks that the given base64 encoded signature of the payload was made by one of the given keys. Ed25519 keys
+// sign the payload itself, ECDSA (ASN.1 encoded signatures) and RSA (PKCS #1 v1.5) keys its SHA-256 digest.
+func Verify(keys []crypto.PublicKey, payload []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64 encoded: %w", err)
+	}
+	digest := sha256.Sum256(payload)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, payload, sig) {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureMismatch
+}
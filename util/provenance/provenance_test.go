@@ -0,0 +1,104 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+var testSubject = Subject{Application: "argocd/guestbook", Project: "default", Server: "https://kubernetes.default.svc", Namespace: "guestbook"}
+
+func TestPayload(t *testing.T) {
+	assert.Equal(t, "argocd/guestbook\ndefault\nhttps://kubernetes.default.svc\nguestbook\nabc123\nkind: Service\n---\nkind: Deployment", string(Payload(testSubject, "abc123", []string{"kind: Service", "kind: Deployment"})))
+	assert.Equal(t, "argocd/guestbook\ndefault\nhttps://kubernetes.default.svc\nguestbook\nabc123\n", string(Payload(testSubject, "abc123", nil)))
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keys, err := ParsePublicKeys(encodePublicKey(t, edKey) + encodePublicKey(t, &ecKey.PublicKey))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	keys, err = ParsePublicKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParsePublicKeys("not a key")
+	assert.EqualError(t, err, "public keys must be PEM encoded")
+
+	_, err = ParsePublicKeys(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("foo")})))
+	assert.EqualError(t, err, "unexpected PEM block of type PRIVATE KEY")
+}
+
+func TestVerify(t *testing.T) {
+	payload := Payload(testSubject, "abc123", []string{"kind: Service"})
+	digest := sha256.Sum256(payload)
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys := []crypto.PublicKey{edPub, &ecKey.PublicKey, &rsaKey.PublicKey}
+
+	t.Run("Ed25519", func(t *testing.T) {
+		sig := ed25519.Sign(edPriv, payload)
+		assert.NoError(t, Verify(keys, payload, base64.StdEncoding.EncodeToString(sig)))
+	})
+
+	t.Run("ECDSA", func(t *testing.T) {
+		sig, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+		require.NoError(t, err)
+		assert.NoError(t, Verify(keys, payload, base64.StdEncoding.EncodeToString(sig)))
+	})
+
+	t.Run("RSA", func(t *testing.T) {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		assert.NoError(t, Verify(keys, payload, base64.StdEncoding.EncodeToString(sig)))
+	})
+
+	t.Run("TamperedPayload", func(t *testing.T) {
+		sig := ed25519.Sign(edPriv, payload)
+		tampered := Payload(testSubject, "abc123", []string{"kind: Secret"})
+		assert.Equal(t, ErrSignatureMismatch, Verify(keys, tampered, base64.StdEncoding.EncodeToString(sig)))
+	})
+
+	t.Run("OtherDestination", func(t *testing.T) {
+		sig := ed25519.Sign(edPriv, payload)
+		subject := testSubject
+		subject.Namespace = "kube-system"
+		assert.Equal(t, ErrSignatureMismatch, Verify(keys, Payload(subject, "abc123", []string{"kind: Service"}), base64.StdEncoding.EncodeToString(sig)))
+	})
+
+	t.Run("UntrustedKey", func(t *testing.T) {
+		sig := ed25519.Sign(edPriv, payload)
+		assert.Equal(t, ErrSignatureMismatch, Verify([]crypto.PublicKey{&ecKey.PublicKey}, payload, base64.StdEncoding.EncodeToString(sig)))
+	})
+
+	t.Run("InvalidEncoding", func(t *testing.T) {
+		assert.Error(t, Verify(keys, payload, "not base64!"))
+	})
+}
@@ -0,0 +1,257 @@
+package rbac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	gocache "github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+
+	jwtutil "github.com/argoproj/argo-cd/v2/util/jwt"
+)
+
+const (
+	ConfigMapExternalURLKey      = "policy.external.url"
+	ConfigMapExternalTimeoutKey  = "policy.external.timeout"
+	ConfigMapExternalCacheTTLKey = "policy.external.cacheTTL"
+
+	defaultExternalAuthzTimeout  = 5 * time.Second
+	defaultExternalAuthzCacheTTL = 30 * time.Second
+)
+
+// projectScopedResources are the resources whose objects are prefixed by their project, i.e. <project>/<name>. They
+// mirror the project-scoped resources of server/rbacpolicy.
+var projectScopedResources = map[string]bool{
+	"applications":    true,
+	"applicationsets": true,
+	"logs":            true,
+	"exec":            true,
+}
+
+// ExternalAuthzRequest is the request context sent to an external authorizer. It is wrapped in an
+// "input" document so that it can be posted as-is to the OPA data API.
+type ExternalAuthzRequest struct {
+	Subject  string   `json:"subject"`
+	Groups   []string `json:"groups,omitempty"`
+	Resource string   `json:"resource"`
+	Action   string   `json:"action"`
+	Object   string   `json:"object"`
+	Project  string   `json:"project,omitempty"`
+}
+
+// ExternalAuthorizer is consulted after Casbin allowed a request and has the final say on it
+type ExternalAuthorizer interface {
+	Authorize(ctx context.Context, req ExternalAuthzRequest) (bool, error)
+}
+
+// HTTPExternalAuthorizer posts the request context to an OPA-compatible HTTP endpoint, e.g.
+// http://opa:8181/v1/data/argocd/allow
+type HTTPExternalAuthorizer struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPExternalAuthorizer returns an external authorizer which queries the given URL
+func NewHTTPExternalAuthorizer(url string, timeout time.Duration) *HTTPExternalAuthorizer {
+	if timeout <= 0 {
+		timeout = defaultExternalAuthzTimeout
+	}
+	return &HTTPExternalAuthorizer{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type externalAuthzInput struct {
+	Input ExternalAuthzRequest `json:"input"`
+}
+
+type externalAuthzResponse struct {
+	// Result is either a boolean, or an object with an "allow" boolean field
+	Result json.RawMessage `json:"result"`
+}
+
+// Authorize implements ExternalAuthorizer. The endpoint must respond with {"result": true} or
+// {"result": {"allow": true}}; anything else, including an undefined result, denies the request.
+func (a *HTTPExternalAuthorizer) Authorize(ctx context.Context, req ExternalAuthzRequest) (bool, error) {
+	body, err := json.Marshal(externalAuthzInput{Input: req})
+	if err != nil {
+		return false, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return false, fmt.Errorf("error querying external authorizer: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("external authorizer responded with status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	var res externalAuthzResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return false, fmt.Errorf("error decoding external authorizer response: %w", err)
+	}
+	if len(res.Result) == 0 {
+		return false, nil
+	}
+	var allowed bool
+	if err := json.Unmarshal(res.Result, &allowed); err == nil {
+		return allowed, nil
+	}
+	var decision struct {
+		Allow bool `json:"allow"`
+	}
+	if err := json.Unmarshal(res.Result, &decision); err != nil {
+		return false, fmt.Errorf("unexpected external authorizer result: %s", string(res.Result))
+	}
+	return decision.Allow, nil
+}
+
+// cachedExternalAuthorizer remembers the decisions of an external authorizer for a while, so that the endpoint is not
+// queried for every single permission check of a request, e.g. when listing applications
+type cachedExternalAuthorizer struct {
+	authorizer ExternalAuthorizer
+	cache      *gocache.Cache
+}
+
+// NewCachedExternalAuthorizer returns an external authorizer which caches the decisions of the given one for the
+// given duration. Errors are not cached.
+func NewCachedExternalAuthorizer(authorizer ExternalAuthorizer, ttl time.Duration) ExternalAuthorizer {
+	return &cachedExternalAuthorizer{authorizer: authorizer, cache: gocache.New(ttl, 2*ttl)}
+}
+
+// externalAuthzCacheKey returns the key of the decision on the given request, which consists of the subject with its
+// groups, the action and the resource
+func externalAuthzCacheKey(req ExternalAuthzRequest) string {
+	return strings.Join([]string{req.Subject, strings.Join(req.Groups, ","), req.Action, req.Resource, req.Object}, "|")
+}
+
+// Authorize implements ExternalAuthorizer
+func (a *cachedExternalAuthorizer) Authorize(ctx context.Context, req ExternalAuthzRequest) (bool, error) {
+	key := externalAuthzCacheKey(req)
+	if allowed, ok := a.cache.Get(key); ok {
+		return allowed.(bool), nil
+	}
+	allowed, err := a.authorizer.Authorize(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	a.cache.SetDefault(key, allowed)
+	return allowed, nil
+}
+
+// newExternalAuthzRequest builds the external authorizer request from the Casbin request values,
+// which are in the form: subject, resource, action, object
+func newExternalAuthzRequest(rvals ...interface{}) (ExternalAuthzRequest, bool) {
+	var req ExternalAuthzRequest
+	if len(rvals) < 4 {
+		return req, false
+	}
+	switch s := rvals[0].(type) {
+	case string:
+		req.Subject = s
+	case jwt.Claims:
+		claims, err := jwtutil.MapClaims(s)
+		if err != nil {
+			return req, false
+		}
+		req.Subject = jwtutil.StringField(claims, "sub")
+		req.Groups = jwtutil.GetGroups(claims, []string{"groups"})
+	default:
+		return req, false
+	}
+	req.Resource, _ = rvals[1].(string)
+	req.Action, _ = rvals[2].(string)
+	req.Object, _ = rvals[3].(string)
+	if projectScopedResources[req.Resource] {
+		if parts := strings.Split(req.Object, "/"); len(parts) >= 2 {
+			req.Project = parts[0]
+		}
+	}
+	return req, true
+}
+
+// SetExternalAuthorizer sets an authorizer which must additionally allow every request allowed by
+// Casbin within the given timeout. Passing nil disables external authorization.
+func (e *Enforcer) SetExternalAuthorizer(authorizer ExternalAuthorizer, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultExternalAuthzTimeout
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.externalAuthorizer = authorizer
+	e.externalTimeout = timeout
+}
+
+func (e *Enforcer) getExternalAuthorizer() (ExternalAuthorizer, time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.externalAuthorizer, e.externalTimeout
+}
+
+// enforceExternal consults the external authorizer (if configured). Errors deny the request.
+func (e *Enforcer) enforceExternal(rvals ...interface{}) bool {
+	authorizer, timeout := e.getExternalAuthorizer()
+	if authorizer == nil {
+		return true
+	}
+	req, ok := newExternalAuthzRequest(rvals...)
+	if !ok {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	allowed, err := authorizer.Authorize(ctx, req)
+	if err != nil {
+		log.Warnf("External authorization failed, denying request: %v", err)
+		return false
+	}
+	return allowed
+}
+
+// syncExternalAuthorizer configures the external authorizer from the RBAC config map data
+func (e *Enforcer) syncExternalAuthorizer(data map[string]string) {
+	url := strings.TrimSpace(data[ConfigMapExternalURLKey])
+	if url == "" {
+		e.SetExternalAuthorizer(nil, 0)
+		return
+	}
+	timeout := defaultExternalAuthzTimeout
+	if val, ok := data[ConfigMapExternalTimeoutKey]; ok && val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			log.Warnf("Invalid %s '%s', using default of %s", ConfigMapExternalTimeoutKey, val, defaultExternalAuthzTimeout)
+		} else {
+			timeout = d
+		}
+	}
+	cacheTTL := defaultExternalAuthzCacheTTL
+	if val, ok := data[ConfigMapExternalCacheTTLKey]; ok && val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			log.Warnf("Invalid %s '%s', using default of %s", ConfigMapExternalCacheTTLKey, val, defaultExternalAuthzCacheTTL)
+		} else {
+			cacheTTL = d
+		}
+	}
+	var authorizer ExternalAuthorizer = NewHTTPExternalAuthorizer(url, timeout)
+	if cacheTTL > 0 {
+		authorizer = NewCachedExternalAuthorizer(authorizer, cacheTTL)
+	}
+	e.SetExternalAuthorizer(authorizer, timeout)
+}
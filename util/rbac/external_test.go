@@ -0,0 +1,195 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/argoproj/argo-cd/v2/util/assets"
+)
+
+func newOPAServer(t *testing.T, result string, requests *[]ExternalAuthzRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input externalAuthzInput
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		if requests != nil {
+			*requests = append(*requests, input.Input)
+		}
+		_, _ = w.Write([]byte(`{"result": ` + result + `}`))
+	}))
+}
+
+func TestHTTPExternalAuthorizer(t *testing.T) {
+	for _, tc := range []struct {
+		result  string
+		allowed bool
+	}{
+		{result: "true", allowed: true},
+		{result: "false", allowed: false},
+		{result: `{"allow": true}`, allowed: true},
+		{result: `{"allow": false}`, allowed: false},
+		{result: "null", allowed: false},
+	} {
+		t.Run(tc.result, func(t *testing.T) {
+			srv := newOPAServer(t, tc.result, nil)
+			defer srv.Close()
+			allowed, err := NewHTTPExternalAuthorizer(srv.URL, 0).Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.allowed, allowed)
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+		allowed, err := NewHTTPExternalAuthorizer(srv.URL, 0).Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice"})
+		assert.Error(t, err)
+		assert.False(t, allowed)
+	})
+}
+
+func TestEnforceExternal(t *testing.T) {
+	kubeclientset := fake.NewSimpleClientset()
+	enf := NewEnforcer(kubeclientset, fakeNamespace, fakeConfigMapName, nil)
+	_ = enf.SetBuiltinPolicy(assets.BuiltinPolicyCSV)
+	enf.SetClaimsEnforcerFunc(func(claims jwt.Claims, rvals ...interface{}) bool {
+		return true
+	})
+	claims := jwt.MapClaims{"sub": "alice", "groups": []string{"team-a"}}
+
+	var requests []ExternalAuthzRequest
+	deny := newOPAServer(t, "false", &requests)
+	defer deny.Close()
+	enf.syncExternalAuthorizer(map[string]string{ConfigMapExternalURLKey: deny.URL})
+	assert.False(t, enf.Enforce(claims, "applications", "get", "my-proj/my-app"))
+	require.Len(t, requests, 1)
+	assert.Equal(t, ExternalAuthzRequest{
+		Subject:  "alice",
+		Groups:   []string{"team-a"},
+		Resource: "applications",
+		Action:   "get",
+		Object:   "my-proj/my-app",
+		Project:  "my-proj",
+	}, requests[0])
+
+	// the decision is cached
+	assert.False(t, enf.Enforce(claims, "applications", "get", "my-proj/my-app"))
+	assert.Len(t, requests, 1)
+
+	allow := newOPAServer(t, "true", nil)
+	defer allow.Close()
+	enf.syncExternalAuthorizer(map[string]string{ConfigMapExternalURLKey: allow.URL})
+	assert.True(t, enf.Enforce(claims, "applications", "get", "my-proj/my-app"))
+
+	// external authorizer cannot grant what Casbin denies
+	assert.False(t, enf.Enforce("bob", "applications", "get", "my-proj/my-app"))
+
+	enf.syncExternalAuthorizer(map[string]string{})
+	authorizer, _ := enf.getExternalAuthorizer()
+	assert.Nil(t, authorizer)
+}
+
+func TestNewExternalAuthzRequest(t *testing.T) {
+	req, ok := newExternalAuthzRequest("alice", "logs", "get", "my-proj/my-app")
+	require.True(t, ok)
+	assert.Equal(t, "my-proj", req.Project)
+
+	// the objects of other resources aren't prefixed by a project
+	req, ok = newExternalAuthzRequest("alice", "gpgkeys", "get", "ABCDEF/1234")
+	require.True(t, ok)
+	assert.Empty(t, req.Project)
+	req, ok = newExternalAuthzRequest("alice", "clusters", "get", "https://kubernetes.default.svc")
+	require.True(t, ok)
+	assert.Empty(t, req.Project)
+}
+
+type deadlineExternalAuthorizer struct {
+	deadline time.Time
+}
+
+func (d *deadlineExternalAuthorizer) Authorize(ctx context.Context, _ ExternalAuthzRequest) (bool, error) {
+	d.deadline, _ = ctx.Deadline()
+	return true, nil
+}
+
+func TestEnforceExternalTimeout(t *testing.T) {
+	enf := NewEnforcer(fake.NewSimpleClientset(), fakeNamespace, fakeConfigMapName, nil)
+	authorizer := &deadlineExternalAuthorizer{}
+	enf.SetExternalAuthorizer(authorizer, time.Minute)
+	start := time.Now()
+	assert.True(t, enf.enforceExternal("alice", "applications", "get", "my-proj/my-app"))
+	assert.WithinDuration(t, start.Add(time.Minute), authorizer.deadline, 10*time.Second)
+}
+
+type fakeExternalAuthorizer struct {
+	requests []ExternalAuthzRequest
+	err      error
+}
+
+func (f *fakeExternalAuthorizer) Authorize(_ context.Context, req ExternalAuthzRequest) (bool, error) {
+	f.requests = append(f.requests, req)
+	return req.Subject == "alice", f.err
+}
+
+func TestCachedExternalAuthorizer(t *testing.T) {
+	t.Run("CachesDecisions", func(t *testing.T) {
+		external := &fakeExternalAuthorizer{}
+		authorizer := NewCachedExternalAuthorizer(external, time.Minute)
+		for i := 0; i < 2; i++ {
+			allowed, err := authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice", Resource: "applications", Action: "get", Object: "my-proj/my-app"})
+			require.NoError(t, err)
+			assert.True(t, allowed)
+			allowed, err = authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "bob", Resource: "applications", Action: "get", Object: "my-proj/my-app"})
+			require.NoError(t, err)
+			assert.False(t, allowed)
+		}
+		assert.Len(t, external.requests, 2)
+
+		_, err := authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice", Resource: "applications", Action: "sync", Object: "my-proj/my-app"})
+		require.NoError(t, err)
+		_, err = authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice", Resource: "applications", Action: "get", Object: "my-proj/other-app"})
+		require.NoError(t, err)
+		assert.Len(t, external.requests, 4)
+	})
+
+	t.Run("DoesNotCacheErrors", func(t *testing.T) {
+		external := &fakeExternalAuthorizer{err: errors.New("unavailable")}
+		authorizer := NewCachedExternalAuthorizer(external, time.Minute)
+		for i := 0; i < 2; i++ {
+			allowed, err := authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice"})
+			assert.Error(t, err)
+			assert.False(t, allowed)
+		}
+		assert.Len(t, external.requests, 2)
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		external := &fakeExternalAuthorizer{}
+		authorizer := NewCachedExternalAuthorizer(external, 10*time.Millisecond)
+		_, _ = authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice"})
+		time.Sleep(20 * time.Millisecond)
+		_, _ = authorizer.Authorize(context.Background(), ExternalAuthzRequest{Subject: "alice"})
+		assert.Len(t, external.requests, 2)
+	})
+}
+
+func TestSyncExternalAuthorizerCacheTTL(t *testing.T) {
+	enf := NewEnforcer(fake.NewSimpleClientset(), fakeNamespace, fakeConfigMapName, nil)
+	enf.syncExternalAuthorizer(map[string]string{ConfigMapExternalURLKey: "http://opa"})
+	authorizer, _ := enf.getExternalAuthorizer()
+	assert.IsType(t, &cachedExternalAuthorizer{}, authorizer)
+	enf.syncExternalAuthorizer(map[string]string{ConfigMapExternalURLKey: "http://opa", ConfigMapExternalCacheTTLKey: "0s"})
+	authorizer, _ = enf.getExternalAuthorizer()
+	assert.IsType(t, &HTTPExternalAuthorizer{}, authorizer)
+}
@@ -72,6 +72,8 @@ type Enforcer struct {
 	model              model.Model
 	defaultRole        string
 	matchMode          string
+	externalAuthorizer ExternalAuthorizer
+	externalTimeout    time.Duration
 }
 
 // cachedEnforcer holds the Casbin enforcer instances and optional custom project policy
@@ -235,9 +237,12 @@ func (e *Enforcer) SetClaimsEnforcerFunc(claimsEnforcer ClaimsEnforcerFunc) {
 }
 
 // Enforce is a wrapper around casbin.Enforce to additionally enforce a default role and a custom
-// claims function
+// claims function. If an external authorizer is configured, it must also allow the request.
 func (e *Enforcer) Enforce(rvals ...interface{}) bool {
-	return enforce(e.getCabinEnforcer("", ""), e.defaultRole, e.claimsEnforcerFunc, rvals...)
+	if !enforce(e.getCabinEnforcer("", ""), e.defaultRole, e.claimsEnforcerFunc, rvals...) {
+		return false
+	}
+	return e.enforceExternal(rvals...)
 }
 
 // EnforceErr is a convenience helper to wrap a failed enforcement with a detailed error about the request
@@ -431,6 +436,7 @@ func PolicyCSV(data map[string]string) string {
 func (e *Enforcer) syncUpdate(cm *apiv1.ConfigMap, onUpdated func(cm *apiv1.ConfigMap) error) error {
 	e.SetDefaultRole(cm.Data[ConfigMapPolicyDefaultKey])
 	e.SetMatchMode(cm.Data[ConfigMapMatchModeKey])
+	e.syncExternalAuthorizer(cm.Data)
 	policyCSV := PolicyCSV(cm.Data)
 	if err := onUpdated(cm); err != nil {
 		return err
@@ -27,6 +27,8 @@ type userStateStorage struct {
 
 var _ UserStateStorage = &userStateStorage{}
 
+// NewUserStateStorage returns a user state storage which shares revoked tokens with other API servers using the given
+// Redis client. If the client is nil, revoked tokens are only kept in memory until they expire.
 func NewUserStateStorage(redis *redis.Client) *userStateStorage {
 	return &userStateStorage{
 		attempts:       map[string]LoginAttempts{},
@@ -37,6 +39,9 @@ func NewUserStateStorage(redis *redis.Client) *userStateStorage {
 }
 
 func (storage *userStateStorage) Init(ctx context.Context) {
+	if storage.redis == nil {
+		return
+	}
 	go storage.watchRevokedTokens(ctx)
 	ticker := time.NewTicker(storage.resyncDuration)
 	go func() {
@@ -113,6 +118,15 @@ func (storage *userStateStorage) RevokeToken(ctx context.Context, id string, exp
 	storage.lock.Lock()
 	storage.revokedTokens[id] = true
 	storage.lock.Unlock()
+	if storage.redis == nil {
+		// the token can't be used anymore once it expired, so it doesn't need to be remembered afterwards
+		time.AfterFunc(expiringAt, func() {
+			storage.lock.Lock()
+			defer storage.lock.Unlock()
+			delete(storage.revokedTokens, id)
+		})
+		return nil
+	}
 	if err := storage.redis.Set(ctx, revokedTokenPrefix+id, "", expiringAt).Err(); err != nil {
 		return err
 	}
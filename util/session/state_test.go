@@ -27,3 +27,18 @@ func TestUserStateStorage_LoadRevokedTokens(t *testing.T) {
 
 	assert.True(t, storage.IsTokenRevoked("abc"))
 }
+
+func TestUserStateStorage_RevokeTokenWithoutRedis(t *testing.T) {
+	storage := NewUserStateStorage(nil)
+	storage.Init(context.Background())
+
+	err := storage.RevokeToken(context.Background(), "abc", 100*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, storage.IsTokenRevoked("abc"))
+	assert.False(t, storage.IsTokenRevoked("def"))
+
+	// the token expired, so it is not remembered anymore
+	assert.Eventually(t, func() bool {
+		return !storage.IsTokenRevoked("abc")
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,116 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// featureFlagKeyPrefix is the prefix of the keys in argocd-cm which enable or disable feature flags for the Argo CD
+// instance, e.g. features.serverSideApplySync: "true"
+const featureFlagKeyPrefix = "features."
+
+const (
+	// FeatureFlagServerSideApplySync syncs the resources of applications using server-side apply, unless the sync option
+	// ServerSideApply=false is set
+	FeatureFlagServerSideApplySync = "serverSideApplySync"
+)
+
+// FeatureFlagDefinition describes a flag guarding a large behavioral change which is rolled out gradually
+type FeatureFlagDefinition struct {
+	// Name is the name of the flag
+	Name string
+	// Description describes the behavior enabled by the flag
+	Description string
+	// Default is the state of the flag if it is neither configured for the Argo CD instance nor for a project
+	Default bool
+}
+
+// FeatureFlagDefinitions are the feature flags known to Argo CD
+var FeatureFlagDefinitions = []FeatureFlagDefinition{
+	{
+		Name:        FeatureFlagServerSideApplySync,
+		Description: "Sync resources using server-side apply, unless the sync option ServerSideApply=false is set",
+	},
+}
+
+func getFeatureFlagDefinition(name string) *FeatureFlagDefinition {
+	for i := range FeatureFlagDefinitions {
+		if FeatureFlagDefinitions[i].Name == name {
+			return &FeatureFlagDefinitions[i]
+		}
+	}
+	return nil
+}
+
+// GetFeatureFlags returns the state of all known feature flags for the Argo CD instance. Flags which aren't configured
+// in argocd-cm, or whose value isn't a boolean, have their default state, unknown flags are ignored.
+func (mgr *SettingsManager) GetFeatureFlags() ([]v1alpha1.FeatureFlag, error) {
+	argoCDCM, err := mgr.getConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	configured := make(map[string]bool)
+	for key, value := range argoCDCM.Data {
+		if !strings.HasPrefix(key, featureFlagKeyPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, featureFlagKeyPrefix)
+		if getFeatureFlagDefinition(name) == nil {
+			log.Warnf("Ignoring unknown feature flag %s", name)
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Warnf("Ignoring invalid value of feature flag %s, using its default state: %v", name, err)
+			continue
+		}
+		configured[name] = enabled
+	}
+	flags := make([]v1alpha1.FeatureFlag, 0, len(FeatureFlagDefinitions))
+	for _, definition := range FeatureFlagDefinitions {
+		enabled, ok := configured[definition.Name]
+		if !ok {
+			enabled = definition.Default
+		}
+		flags = append(flags, v1alpha1.FeatureFlag{Name: definition.Name, Enabled: enabled})
+	}
+	return flags, nil
+}
+
+// GetProjectFeatureFlags returns the state of all known feature flags for the applications of the given project. The
+// flags configured for the project override the ones configured for the Argo CD instance.
+func (mgr *SettingsManager) GetProjectFeatureFlags(proj *v1alpha1.AppProject) ([]v1alpha1.FeatureFlag, error) {
+	flags, err := mgr.GetFeatureFlags()
+	if err != nil {
+		return nil, err
+	}
+	for i := range flags {
+		if enabled, ok := proj.GetFeatureFlag(flags[i].Name); ok {
+			flags[i].Enabled = enabled
+		}
+	}
+	return flags, nil
+}
+
+// IsFeatureEnabled returns whether the feature guarded by the given flag is enabled for the applications of the given
+// project
+func (mgr *SettingsManager) IsFeatureEnabled(proj *v1alpha1.AppProject, name string) (bool, error) {
+	if getFeatureFlagDefinition(name) == nil {
+		return false, fmt.Errorf("unknown feature flag %s", name)
+	}
+	flags, err := mgr.GetProjectFeatureFlags(proj)
+	if err != nil {
+		return false, err
+	}
+	for _, flag := range flags {
+		if flag.Name == name {
+			return flag.Enabled, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,52 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+func TestGetFeatureFlags(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		_, settingsManager := fixtures(nil)
+		flags, err := settingsManager.GetFeatureFlags()
+		require.NoError(t, err)
+		assert.Contains(t, flags, v1alpha1.FeatureFlag{Name: FeatureFlagServerSideApplySync, Enabled: false})
+	})
+	t.Run("Configured", func(t *testing.T) {
+		_, settingsManager := fixtures(map[string]string{
+			"features.serverSideApplySync": "true",
+			"features.unknown":             "true",
+		})
+		flags, err := settingsManager.GetFeatureFlags()
+		require.NoError(t, err)
+		assert.Len(t, flags, len(FeatureFlagDefinitions))
+		assert.Contains(t, flags, v1alpha1.FeatureFlag{Name: FeatureFlagServerSideApplySync, Enabled: true})
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, settingsManager := fixtures(map[string]string{"features.serverSideApplySync": "yes please"})
+		flags, err := settingsManager.GetFeatureFlags()
+		require.NoError(t, err)
+		assert.Contains(t, flags, v1alpha1.FeatureFlag{Name: FeatureFlagServerSideApplySync, Enabled: false})
+	})
+}
+
+func TestIsFeatureEnabled(t *testing.T) {
+	_, settingsManager := fixtures(map[string]string{"features.serverSideApplySync": "true"})
+	proj := &v1alpha1.AppProject{}
+
+	enabled, err := settingsManager.IsFeatureEnabled(proj, FeatureFlagServerSideApplySync)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	proj.Spec.FeatureFlags = []v1alpha1.FeatureFlag{{Name: FeatureFlagServerSideApplySync, Enabled: false}}
+	enabled, err = settingsManager.IsFeatureEnabled(proj, FeatureFlagServerSideApplySync)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = settingsManager.IsFeatureEnabled(proj, "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaskingRule describes values of resources which are masked when the manifests and diffs of applications are returned
+// by the API, in addition to the data of Secrets
+type MaskingRule struct {
+	APIGroups []string `json:"apiGroups,omitempty"`
+	Kinds     []string `json:"kinds,omitempty"`
+	// JSONPointers are the fields whose values are masked entirely
+	JSONPointers []string `json:"jsonPointers,omitempty"`
+	// Regexes match the parts of the string values of the resources which are masked
+	Regexes []string `json:"regexes,omitempty"`
+}
+
+// Match returns whether the rule applies to resources of the given API group and kind
+func (r MaskingRule) Match(apiGroup, kind string) bool {
+	return FilteredResource{APIGroups: r.APIGroups, Kinds: r.Kinds}.Match(apiGroup, kind, "")
+}
+
+// Validate returns an error if the JSON pointers or the regexes of the rule are invalid
+func (r MaskingRule) Validate() error {
+	for _, pointer := range r.JSONPointers {
+		if _, err := ParseJSONPointer(pointer); err != nil {
+			return err
+		}
+	}
+	for _, expr := range r.Regexes {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+	}
+	return nil
+}
+
+// ParseJSONPointer splits the given RFC 6901 JSON pointer into its unescaped reference tokens
+func ParseJSONPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
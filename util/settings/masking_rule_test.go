@@ -0,0 +1,23 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingRule_Match(t *testing.T) {
+	assert.True(t, MaskingRule{Kinds: []string{"ConfigMap"}}.Match("", "ConfigMap"))
+	assert.True(t, MaskingRule{APIGroups: []string{"*.example.com"}}.Match("foo.example.com", "Bar"))
+	assert.False(t, MaskingRule{Kinds: []string{"ConfigMap"}}.Match("", "Secret"))
+	assert.False(t, MaskingRule{APIGroups: []string{""}, Kinds: []string{"ConfigMap"}}.Match("example.com", "ConfigMap"))
+}
+
+func TestParseJSONPointer(t *testing.T) {
+	tokens, err := ParseJSONPointer("/data/a~1b~0c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data", "a/b~c"}, tokens)
+
+	_, err = ParseJSONPointer("data")
+	assert.Error(t, err)
+}
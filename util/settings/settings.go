@@ -2,6 +2,7 @@ package settings
 
 import (
 	"context"
+	gocrypto "crypto"
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/tls"
@@ -36,8 +37,11 @@ import (
 	"github.com/argoproj/argo-cd/v2/server/settings/oidc"
 	"github.com/argoproj/argo-cd/v2/util"
 	"github.com/argoproj/argo-cd/v2/util/crypto"
+	"github.com/argoproj/argo-cd/v2/util/git"
+	"github.com/argoproj/argo-cd/v2/util/glob"
 	"github.com/argoproj/argo-cd/v2/util/kube"
 	"github.com/argoproj/argo-cd/v2/util/password"
+	"github.com/argoproj/argo-cd/v2/util/provenance"
 	tlsutil "github.com/argoproj/argo-cd/v2/util/tls"
 )
 
@@ -421,6 +425,8 @@ const (
 	resourceExclusionsKey = "resource.exclusions"
 	// resourceInclusions is the key to the list of explicitly watched resources
 	resourceInclusionsKey = "resource.inclusions"
+	// resourceMaskingRulesKey is the key to the list of rules masking values of resources returned by the API
+	resourceMaskingRulesKey = "resource.maskingRules"
 	// resourceIgnoreResourceUpdatesEnabledKey is the key to a boolean determining whether the resourceIgnoreUpdates feature is enabled
 	resourceIgnoreResourceUpdatesEnabledKey = "resource.ignoreResourceUpdatesEnabled"
 	// resourceCustomLabelKey is the key to a custom label to show in node info, if present
@@ -475,6 +481,16 @@ const (
 	execShellsKey = "exec.shells"
 	// oidcTLSInsecureSkipVerifyKey is the key to configure whether TLS cert verification is skipped for OIDC connections
 	oidcTLSInsecureSkipVerifyKey = "oidc.tls.insecure.skip.verify"
+	// settingsServerRepositoryHideExistenceKey is the key to configure whether requests for repositories the user is not
+	// permitted to access fail as if the repositories did not exist
+	settingsServerRepositoryHideExistenceKey = "server.repository.hide.existence"
+	// repositoryAllowListKey is the key to configure the URL patterns of the only repositories which may be used
+	repositoryAllowListKey = "repository.allowList"
+	// repositoryDenyListKey is the key to configure the URL patterns of repositories which must not be used
+	repositoryDenyListKey = "repository.denyList"
+	// manifestProvenanceKeysKey is the key to configure the PEM encoded public keys trusted to sign manifests which are
+	// rendered outside of Argo CD
+	manifestProvenanceKeysKey = "manifests.provenance.publicKeys"
 	// ApplicationDeepLinks is the application deep link key
 	ApplicationDeepLinks = "application.links"
 	// ProjectDeepLinks is the project deep link key
@@ -684,8 +700,7 @@ func (mgr *SettingsManager) GetResourcesFilter() (*ResourcesFilter, error) {
 	}
 	rf := &ResourcesFilter{}
 	if value, ok := argoCDCM.Data[resourceInclusionsKey]; ok {
-		includedResources := make([]FilteredResource, 0)
-		err := yaml.Unmarshal([]byte(value), &includedResources)
+		includedResources, err := parseFilteredResources(value)
 		if err != nil {
 			return nil, err
 		}
@@ -693,8 +708,7 @@ func (mgr *SettingsManager) GetResourcesFilter() (*ResourcesFilter, error) {
 	}
 
 	if value, ok := argoCDCM.Data[resourceExclusionsKey]; ok {
-		excludedResources := make([]FilteredResource, 0)
-		err := yaml.Unmarshal([]byte(value), &excludedResources)
+		excludedResources, err := parseFilteredResources(value)
 		if err != nil {
 			return nil, err
 		}
@@ -703,6 +717,39 @@ func (mgr *SettingsManager) GetResourcesFilter() (*ResourcesFilter, error) {
 	return rf, nil
 }
 
+// GetClusterResourcesFilter returns the resources filter of the given cluster. The resource inclusions and exclusions
+// of the cluster secret replace the ones of the given filter if they are set.
+func GetClusterResourcesFilter(rf *ResourcesFilter, cluster *v1alpha1.Cluster) (*ResourcesFilter, error) {
+	if cluster.ResourceInclusions == "" && cluster.ResourceExclusions == "" {
+		return rf, nil
+	}
+	clusterFilter := &ResourcesFilter{ResourceInclusions: rf.ResourceInclusions, ResourceExclusions: rf.ResourceExclusions}
+	if cluster.ResourceInclusions != "" {
+		includedResources, err := parseFilteredResources(cluster.ResourceInclusions)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing resource inclusions of cluster %s: %w", cluster.Server, err)
+		}
+		clusterFilter.ResourceInclusions = includedResources
+	}
+	if cluster.ResourceExclusions != "" {
+		excludedResources, err := parseFilteredResources(cluster.ResourceExclusions)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing resource exclusions of cluster %s: %w", cluster.Server, err)
+		}
+		clusterFilter.ResourceExclusions = excludedResources
+	}
+	return clusterFilter, nil
+}
+
+func parseFilteredResources(value string) ([]FilteredResource, error) {
+	resources := make([]FilteredResource, 0)
+	err := yaml.Unmarshal([]byte(value), &resources)
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
 func (mgr *SettingsManager) GetAppInstanceLabelKey() (string, error) {
 	argoCDCM, err := mgr.getConfigMap()
 	if err != nil {
@@ -748,6 +795,81 @@ func (mgr *SettingsManager) GetServerRBACLogEnforceEnable() (bool, error) {
 	return strconv.ParseBool(argoCDCM.Data[settingsServerRBACLogEnforceEnableKey])
 }
 
+// GetServerRepositoryHideExistence returns whether requests for repositories the user is not permitted to access fail
+// with the same NotFound error as requests for unknown repositories
+func (mgr *SettingsManager) GetServerRepositoryHideExistence() (bool, error) {
+	argoCDCM, err := mgr.getConfigMap()
+	if err != nil {
+		return false, err
+	}
+
+	if argoCDCM.Data[settingsServerRepositoryHideExistenceKey] == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(argoCDCM.Data[settingsServerRepositoryHideExistenceKey])
+}
+
+// RepositoryURLPolicy restricts, independently of project settings, the repositories which may be used in the instance
+type RepositoryURLPolicy struct {
+	// Allow holds the URL patterns of the only repositories which are permitted. An empty list permits any repository.
+	Allow []string `json:"allow,omitempty"`
+	// Deny holds the URL patterns of repositories which are never permitted, even if they match an allow pattern
+	Deny []string `json:"deny,omitempty"`
+}
+
+// IsPermitted returns whether the given repository URL is permitted by the policy
+func (p *RepositoryURLPolicy) IsPermitted(repoURL string) bool {
+	normalized := git.NormalizeGitURL(repoURL)
+	for _, pattern := range p.Deny {
+		if glob.Match(git.NormalizeGitURL(pattern), normalized, '/') {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if glob.Match(git.NormalizeGitURL(pattern), normalized, '/') {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRepositoryURLPolicy returns the instance-wide allow and deny lists of repository URL patterns
+func (mgr *SettingsManager) GetRepositoryURLPolicy() (*RepositoryURLPolicy, error) {
+	argoCDCM, err := mgr.getConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	policy := &RepositoryURLPolicy{}
+	if value, ok := argoCDCM.Data[repositoryAllowListKey]; ok {
+		if err := yaml.Unmarshal([]byte(value), &policy.Allow); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", repositoryAllowListKey, err)
+		}
+	}
+	if value, ok := argoCDCM.Data[repositoryDenyListKey]; ok {
+		if err := yaml.Unmarshal([]byte(value), &policy.Deny); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", repositoryDenyListKey, err)
+		}
+	}
+	return policy, nil
+}
+
+// GetManifestProvenanceKeys returns the public keys trusted to sign manifests which are rendered outside of Argo CD
+func (mgr *SettingsManager) GetManifestProvenanceKeys() ([]gocrypto.PublicKey, error) {
+	argoCDCM, err := mgr.getConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := provenance.ParsePublicKeys(argoCDCM.Data[manifestProvenanceKeysKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestProvenanceKeysKey, err)
+	}
+	return keys, nil
+}
+
 func (mgr *SettingsManager) GetDeepLinks(deeplinkType string) ([]DeepLink, error) {
 	argoCDCM, err := mgr.getConfigMap()
 	if err != nil {
@@ -830,6 +952,27 @@ func (mgr *SettingsManager) GetIsIgnoreResourceUpdatesEnabled() (bool, error) {
 	return strconv.ParseBool(argoCDCM.Data[resourceIgnoreResourceUpdatesEnabledKey])
 }
 
+// GetMaskingRules loads the rules masking values of resources returned by the API from argocd-cm ConfigMap
+func (mgr *SettingsManager) GetMaskingRules() ([]MaskingRule, error) {
+	argoCDCM, err := mgr.getConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]MaskingRule, 0)
+	if value, ok := argoCDCM.Data[resourceMaskingRulesKey]; ok && value != "" {
+		err := yaml.Unmarshal([]byte(value), &rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", resourceMaskingRulesKey, err)
+		}
+	}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", resourceMaskingRulesKey, err)
+		}
+	}
+	return rules, nil
+}
+
 // GetResourceOverrides loads Resource Overrides from argocd-cm ConfigMap
 func (mgr *SettingsManager) GetResourceOverrides() (map[string]v1alpha1.ResourceOverride, error) {
 	argoCDCM, err := mgr.getConfigMap()
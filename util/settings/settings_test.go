@@ -121,6 +121,66 @@ func TestGetResourceFilter(t *testing.T) {
 	}, filter)
 }
 
+func TestGetClusterResourcesFilter(t *testing.T) {
+	rf := &ResourcesFilter{
+		ResourceExclusions: []FilteredResource{{APIGroups: []string{"group1"}}},
+		ResourceInclusions: []FilteredResource{{APIGroups: []string{"group1", "group2"}}},
+	}
+
+	t.Run("NoOverrides", func(t *testing.T) {
+		filter, err := GetClusterResourcesFilter(rf, &v1alpha1.Cluster{Server: "https://cluster1"})
+		require.NoError(t, err)
+		assert.Same(t, rf, filter)
+	})
+
+	t.Run("ExclusionsOverride", func(t *testing.T) {
+		filter, err := GetClusterResourcesFilter(rf, &v1alpha1.Cluster{
+			Server:             "https://cluster1",
+			ResourceExclusions: "\n  - apiGroups: [\"metrics.k8s.io\"]\n    kinds: [\"*\"]\n",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ResourcesFilter{
+			ResourceExclusions: []FilteredResource{{APIGroups: []string{"metrics.k8s.io"}, Kinds: []string{"*"}}},
+			ResourceInclusions: []FilteredResource{{APIGroups: []string{"group1", "group2"}}},
+		}, filter)
+		assert.True(t, filter.IsExcludedResource("metrics.k8s.io", "PodMetrics", "https://cluster1"))
+		assert.False(t, filter.IsExcludedResource("group1", "Kind1", "https://cluster1"))
+		// the global filter is left unchanged
+		assert.True(t, rf.IsExcludedResource("group1", "Kind1", "https://cluster1"))
+	})
+
+	t.Run("InvalidOverride", func(t *testing.T) {
+		_, err := GetClusterResourcesFilter(rf, &v1alpha1.Cluster{Server: "https://cluster1", ResourceInclusions: "apiGroups: group1"})
+		assert.ErrorContains(t, err, "error parsing resource inclusions of cluster https://cluster1")
+	})
+}
+
+func TestGetMaskingRules(t *testing.T) {
+	_, settingsManager := fixtures(map[string]string{
+		"resource.maskingRules": "\n  - kinds: [\"ConfigMap\"]\n    jsonPointers: [\"/data/password\"]\n    regexes: [\"token=\\\\S+\"]\n",
+	})
+	rules, err := settingsManager.GetMaskingRules()
+	assert.NoError(t, err)
+	assert.Equal(t, []MaskingRule{{Kinds: []string{"ConfigMap"}, JSONPointers: []string{"/data/password"}, Regexes: []string{"token=\\S+"}}}, rules)
+
+	_, settingsManager = fixtures(map[string]string{})
+	rules, err = settingsManager.GetMaskingRules()
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	_, settingsManager = fixtures(map[string]string{
+		"resource.maskingRules": "\n  - kinds: [\"ConfigMap\"]\n    jsonPointers: [\"data/password\"]\n",
+	})
+	_, err = settingsManager.GetMaskingRules()
+	assert.Error(t, err)
+
+	_, settingsManager = fixtures(map[string]string{
+		"resource.maskingRules": "\n  - kinds: [\"ConfigMap\"]\n    regexes: [\"(\"]\n",
+	})
+	_, err = settingsManager.GetMaskingRules()
+	assert.Error(t, err)
+}
+
 func TestInClusterServerAddressEnabled(t *testing.T) {
 	_, settingsManager := fixtures(map[string]string{
 		"cluster.inClusterEnabled": "true",
@@ -210,6 +270,35 @@ func TestGetServerRBACLogEnforceEnableKey(t *testing.T) {
 	assert.Equal(t, true, serverRBACLogEnforceEnable)
 }
 
+func TestGetRepositoryURLPolicy(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		_, settingsManager := fixtures(nil)
+		policy, err := settingsManager.GetRepositoryURLPolicy()
+		require.NoError(t, err)
+		assert.True(t, policy.IsPermitted("https://github.com/argoproj/argo-cd"))
+	})
+	t.Run("AllowAndDeny", func(t *testing.T) {
+		_, settingsManager := fixtures(map[string]string{
+			"repository.allowList": "- https://git.example.com/*\n- git@git.example.com:*",
+			"repository.denyList":  "- https://git.example.com/private-*",
+		})
+		policy, err := settingsManager.GetRepositoryURLPolicy()
+		require.NoError(t, err)
+		assert.True(t, policy.IsPermitted("https://git.example.com/team-a.git"))
+		assert.True(t, policy.IsPermitted("git@git.example.com:team-a.git"))
+		assert.False(t, policy.IsPermitted("https://git.example.com/private-repo"))
+		assert.False(t, policy.IsPermitted("https://github.com/argoproj/argo-cd"))
+		assert.False(t, policy.IsPermitted("https://git.example.com/team-a/nested"))
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, settingsManager := fixtures(map[string]string{
+			"repository.denyList": "not a list",
+		})
+		_, err := settingsManager.GetRepositoryURLPolicy()
+		assert.Error(t, err)
+	})
+}
+
 func TestGetResourceOverrides(t *testing.T) {
 	ignoreStatus := v1alpha1.ResourceOverride{IgnoreDifferences: v1alpha1.OverrideIgnoreDiff{
 		JSONPointers: []string{"/status"},
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	gogsclient "github.com/gogits/go-gogs-client"
 	log "github.com/sirupsen/logrus"
@@ -37,6 +38,27 @@ type settingsSource interface {
 	GetTrackingMethod() (string, error)
 }
 
+// ManifestCacheWarmer generates the manifests of an application at a given revision, so that they are
+// already cached by the repo server when the application is refreshed
+type ManifestCacheWarmer interface {
+	WarmManifestCache(ctx context.Context, app *v1alpha1.Application, revision string) error
+}
+
+const (
+	// maxConcurrentManifestCacheWarmings limits the number of manifest generations triggered by webhook events
+	maxConcurrentManifestCacheWarmings = 10
+	// manifestCacheWarmingQueueSize is the number of pending warmings, beyond which further warmings are skipped
+	manifestCacheWarmingQueueSize = 100
+	// manifestCacheWarmingTimeout is the maximum duration of warming the manifest cache of an application
+	manifestCacheWarmingTimeout = 5 * time.Minute
+)
+
+// manifestCacheWarming is a pending warming of the manifest cache of an application at a revision
+type manifestCacheWarming struct {
+	app      v1alpha1.Application
+	revision string
+}
+
 // https://www.rfc-editor.org/rfc/rfc3986#section-3.2.1
 // https://github.com/shadow-maint/shadow/blob/master/libmisc/chkname.c#L36
 const usernameRegex = `[a-zA-Z0-9_\.][a-zA-Z0-9_\.-]{0,30}[a-zA-Z0-9_\.\$-]?`
@@ -56,6 +78,10 @@ type ArgoCDWebhookHandler struct {
 	bitbucketserver *bitbucketserver.Webhook
 	gogs            *gogs.Webhook
 	settingsSrc     settingsSource
+	warmer          ManifestCacheWarmer
+	warmingQueue    chan manifestCacheWarming
+	// warmingCtx is cancelled when the server shuts down, which cancels pending and running warmings
+	warmingCtx context.Context
 }
 
 func NewHandler(namespace string, applicationNamespaces []string, appClientset appclientset.Interface, set *settings.ArgoCDSettings, settingsSrc settingsSource, repoCache *cache.Cache, serverCache *servercache.Cache, argoDB db.ArgoDB) *ArgoCDWebhookHandler {
@@ -98,6 +124,53 @@ func NewHandler(namespace string, applicationNamespaces []string, appClientset a
 	return &acdWebhook
 }
 
+// WithManifestCacheWarmer enables warming the manifest cache for the new revision of every application
+// which is refreshed because of a webhook event, until the given context is done
+func (a *ArgoCDWebhookHandler) WithManifestCacheWarmer(ctx context.Context, warmer ManifestCacheWarmer) *ArgoCDWebhookHandler {
+	a.warmer = warmer
+	a.warmingCtx = ctx
+	a.warmingQueue = make(chan manifestCacheWarming, manifestCacheWarmingQueueSize)
+	for i := 0; i < maxConcurrentManifestCacheWarmings; i++ {
+		go a.manifestCacheWarmingWorker()
+	}
+	return a
+}
+
+// manifestCacheWarmingWorker warms the manifest cache of the queued applications until the warming context is done
+func (a *ArgoCDWebhookHandler) manifestCacheWarmingWorker() {
+	for {
+		select {
+		case <-a.warmingCtx.Done():
+			return
+		case warming := <-a.warmingQueue:
+			if a.warmingCtx.Err() != nil {
+				return
+			}
+			ctx, cancel := context.WithTimeout(a.warmingCtx, manifestCacheWarmingTimeout)
+			if err := a.warmer.WarmManifestCache(ctx, &warming.app, warming.revision); err != nil {
+				log.Warnf("Failed to warm manifest cache of app '%s' for revision %s: %v", warming.app.Name, warming.revision, err)
+			} else {
+				log.Debugf("Warmed manifest cache of app '%s' for revision %s", warming.app.Name, warming.revision)
+			}
+			cancel()
+		}
+	}
+}
+
+// warmManifestCache queues generating the manifests of the given application at the given revision. The warming is
+// skipped if too many warmings are pending already.
+func (a *ArgoCDWebhookHandler) warmManifestCache(app v1alpha1.Application, revision string) {
+	// the revision is all zeros if the ref has been deleted
+	if a.warmer == nil || strings.Trim(revision, "0") == "" || a.warmingCtx.Err() != nil {
+		return
+	}
+	select {
+	case a.warmingQueue <- manifestCacheWarming{app: app, revision: revision}:
+	default:
+		log.Warnf("Skipped warming manifest cache of app '%s' for revision %s: too many pending warmings", app.Name, revision)
+	}
+}
+
 func parseRevision(ref string) string {
 	refParts := strings.SplitN(ref, "/", 3)
 	return refParts[len(refParts)-1]
@@ -264,6 +337,7 @@ func (a *ArgoCDWebhookHandler) HandleEvent(payload interface{}) {
 			for _, source := range app.Spec.GetSources() {
 				if sourceRevisionHasChanged(source, revision, touchedHead) && sourceUsesURL(source, webURL, repoRegexp) {
 					if appFilesHaveChanged(&app, changedFiles) {
+						a.warmManifestCache(app, change.shaAfter)
 						_, err = argo.RefreshApp(appIf, app.ObjectMeta.Name, v1alpha1.RefreshTypeNormal)
 						if err != nil {
 							log.Warnf("Failed to refresh app '%s' for controller reprocessing: %v", app.ObjectMeta.Name, err)
@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -144,6 +145,120 @@ func TestGitHubCommitEvent_MultiSource_Refresh(t *testing.T) {
 	hook.Reset()
 }
 
+type fakeManifestCacheWarmer struct {
+	warmed chan string
+}
+
+func (f *fakeManifestCacheWarmer) WarmManifestCache(_ context.Context, app *v1alpha1.Application, revision string) error {
+	f.warmed <- app.Name + "@" + revision
+	return nil
+}
+
+func TestGitHubCommitEvent_ManifestCacheWarming(t *testing.T) {
+	reaction := func(action kubetesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, nil, nil
+	}
+	h := NewMockHandler(&reactorDef{"patch", "applications", reaction}, []string{}, &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-to-refresh",
+			Namespace: "argocd",
+		},
+		Spec: v1alpha1.ApplicationSpec{
+			Source: &v1alpha1.ApplicationSource{
+				RepoURL: "https://github.com/jessesuen/test-repo",
+				Path:    ".",
+			},
+		},
+	})
+	warmer := &fakeManifestCacheWarmer{warmed: make(chan string, 1)}
+	h = h.WithManifestCacheWarmer(context.Background(), warmer)
+	req := httptest.NewRequest(http.MethodPost, "/api/webhook", nil)
+	req.Header.Set("X-GitHub-Event", "push")
+	eventJSON, err := os.ReadFile("testdata/github-commit-event.json")
+	assert.NoError(t, err)
+	req.Body = io.NopCloser(bytes.NewReader(eventJSON))
+	w := httptest.NewRecorder()
+	h.Handler(w, req)
+	assert.Equal(t, w.Code, http.StatusOK)
+	select {
+	case warmed := <-warmer.warmed:
+		assert.Equal(t, "app-to-refresh@63738bb582c8b540af7bcfc18f87c575c3ed66e0", warmed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("manifest cache was not warmed")
+	}
+}
+
+func TestGitHubCommitEvent_ManifestCacheWarmingCancelled(t *testing.T) {
+	reaction := func(action kubetesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, nil, nil
+	}
+	h := NewMockHandler(&reactorDef{"patch", "applications", reaction}, []string{}, &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-to-refresh",
+			Namespace: "argocd",
+		},
+		Spec: v1alpha1.ApplicationSpec{
+			Source: &v1alpha1.ApplicationSource{
+				RepoURL: "https://github.com/jessesuen/test-repo",
+				Path:    ".",
+			},
+		},
+	})
+	warmer := &fakeManifestCacheWarmer{warmed: make(chan string, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	h = h.WithManifestCacheWarmer(ctx, warmer)
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/api/webhook", nil)
+	req.Header.Set("X-GitHub-Event", "push")
+	eventJSON, err := os.ReadFile("testdata/github-commit-event.json")
+	assert.NoError(t, err)
+	req.Body = io.NopCloser(bytes.NewReader(eventJSON))
+	w := httptest.NewRecorder()
+	h.Handler(w, req)
+	assert.Equal(t, w.Code, http.StatusOK)
+	select {
+	case warmed := <-warmer.warmed:
+		t.Fatalf("manifest cache was warmed after the context was cancelled: %s", warmed)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// blockingManifestCacheWarmer blocks every warming until its context is done
+type blockingManifestCacheWarmer struct {
+	started chan string
+}
+
+func (f *blockingManifestCacheWarmer) WarmManifestCache(ctx context.Context, app *v1alpha1.Application, revision string) error {
+	f.started <- app.Name + "@" + revision
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWarmManifestCache_QueueFull(t *testing.T) {
+	h := NewMockHandler(nil, []string{})
+	warmer := &blockingManifestCacheWarmer{started: make(chan string, maxConcurrentManifestCacheWarmings)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h = h.WithManifestCacheWarmer(ctx, warmer)
+	app := v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "argocd"}}
+	// occupy all workers
+	for i := 0; i < maxConcurrentManifestCacheWarmings; i++ {
+		h.warmManifestCache(app, fmt.Sprintf("%040d", i+1))
+	}
+	for i := 0; i < maxConcurrentManifestCacheWarmings; i++ {
+		select {
+		case <-warmer.started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("manifest cache warming was not started")
+		}
+	}
+	// fill the queue, beyond which warmings are skipped instead of being queued
+	for i := 0; i < manifestCacheWarmingQueueSize+1; i++ {
+		h.warmManifestCache(app, fmt.Sprintf("%040d", maxConcurrentManifestCacheWarmings+i+1))
+	}
+	assert.Len(t, h.warmingQueue, manifestCacheWarmingQueueSize)
+}
+
 // TestGitHubCommitEvent_AppsInOtherNamespaces makes sure that webhooks properly find apps in the configured set of
 // allowed namespaces when Apps are allowed in any namespace
 func TestGitHubCommitEvent_AppsInOtherNamespaces(t *testing.T) {